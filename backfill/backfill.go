@@ -0,0 +1,248 @@
+package backfill
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/mysteriumnetwork/payments/bindings"
+)
+
+// Kind is a kind of backfilled event.
+type Kind string
+
+const (
+	KindRegistration Kind = "registration"
+	KindSettlement   Kind = "settlement"
+	KindTransfer     Kind = "transfer"
+)
+
+// Event signatures of the backfilled events.
+var (
+	TopicRegisteredIdentity = mustEventID(bindings.RegistryMetaData, "RegisteredIdentity")
+	TopicPromiseSettled     = mustEventID(bindings.HermesImplementationMetaData, "PromiseSettled")
+	TopicTransfer           = mustEventID(bindings.MystTokenMetaData, "Transfer")
+)
+
+// Indexer returns logs and the chain head for every chain.
+type Indexer interface {
+	FilterLogs(chainID int64, q ethereum.FilterQuery) ([]types.Log, error)
+	BlockNumber(chainID int64) (uint64, error)
+}
+
+// BCClient is a single chain blockchain client, `client.Blockchain` satisfies it.
+type BCClient interface {
+	FilterLogs(q ethereum.FilterQuery) ([]types.Log, error)
+	BlockNumber() (uint64, error)
+}
+
+// Clients is an `Indexer` backed by log queries of a blockchain client per chain.
+type Clients map[int64]BCClient
+
+// FilterLogs queries the logs from the client of the chain.
+func (c Clients) FilterLogs(chainID int64, q ethereum.FilterQuery) ([]types.Log, error) {
+	bc, ok := c[chainID]
+	if !ok {
+		return nil, fmt.Errorf("no client for chain %d", chainID)
+	}
+	return bc.FilterLogs(q)
+}
+
+// BlockNumber returns the head of the chain.
+func (c Clients) BlockNumber(chainID int64) (uint64, error) {
+	bc, ok := c[chainID]
+	if !ok {
+		return 0, fmt.Errorf("no client for chain %d", chainID)
+	}
+	return bc.BlockNumber()
+}
+
+// Chain describes which events are relevant on a single chain.
+type Chain struct {
+	ChainID int64
+	// Registry is the registry address, its identity registrations are backfilled.
+	Registry common.Address
+	// Hermeses are the hermes addresses, their promise settlements are backfilled.
+	Hermeses []common.Address
+	// Tokens are the token addresses, their transfers to watched addresses are backfilled.
+	Tokens []common.Address
+	// Watched are the recipients whose incoming transfers are backfilled.
+	Watched []common.Address
+}
+
+// Config configures the backfiller.
+type Config struct {
+	Chains []Chain
+	// Confirmations is how many blocks behind the head are backfilled to avoid reorgs.
+	Confirmations uint64
+	// MaxBlockRange limits the block range of a single log query. Zero means no limit.
+	MaxBlockRange uint64
+}
+
+// Event is a backfilled event.
+type Event struct {
+	Kind    Kind
+	ChainID int64
+	Log     types.Log
+}
+
+// Handler handles a replayed event. Events can be replayed more than once
+// if a backfill is interrupted, so handlers should be idempotent.
+type Handler func(Event) error
+
+// Backfiller replays events missed during downtime into registered handlers.
+type Backfiller struct {
+	indexer  Indexer
+	cfg      Config
+	handlers map[Kind][]Handler
+}
+
+// New returns a new backfiller.
+func New(indexer Indexer, cfg Config) *Backfiller {
+	return &Backfiller{
+		indexer:  indexer,
+		cfg:      cfg,
+		handlers: make(map[Kind][]Handler),
+	}
+}
+
+// Handle registers a handler for the events of the given kind.
+//
+// This method is not thread safe and should be called before `Backfill`.
+func (b *Backfiller) Handle(kind Kind, h Handler) {
+	b.handlers[kind] = append(b.handlers[kind], h)
+}
+
+// Backfill replays all of the relevant events after the last processed block of every configured chain
+// up to the confirmed head, in block and log order. It returns the last processed block of every chain,
+// which should be persisted and passed to the next call. If replaying fails the returned block of that
+// chain is the last one which was fully processed and the error is returned.
+func (b *Backfiller) Backfill(lastProcessed map[int64]uint64) (map[int64]uint64, error) {
+	res := make(map[int64]uint64, len(lastProcessed))
+	for k, v := range lastProcessed {
+		res[k] = v
+	}
+
+	for _, ch := range b.cfg.Chains {
+		last, err := b.backfillChain(ch, res[ch.ChainID])
+		res[ch.ChainID] = last
+		if err != nil {
+			return res, fmt.Errorf("failed to backfill chain %d: %w", ch.ChainID, err)
+		}
+	}
+	return res, nil
+}
+
+func (b *Backfiller) backfillChain(ch Chain, last uint64) (uint64, error) {
+	head, err := b.indexer.BlockNumber(ch.ChainID)
+	if err != nil {
+		return last, fmt.Errorf("failed to get block number: %w", err)
+	}
+	if head < b.cfg.Confirmations {
+		return last, nil
+	}
+	head -= b.cfg.Confirmations
+
+	for from := last + 1; from <= head; {
+		to := head
+		if b.cfg.MaxBlockRange > 0 && to-from+1 > b.cfg.MaxBlockRange {
+			to = from + b.cfg.MaxBlockRange - 1
+		}
+
+		events, err := b.events(ch, from, to)
+		if err != nil {
+			return last, err
+		}
+		for _, ev := range events {
+			if err := b.replay(ev); err != nil {
+				if ev.Log.BlockNumber > 0 {
+					last = ev.Log.BlockNumber - 1
+				}
+				return last, fmt.Errorf("failed to replay %s event %s/%d: %w", ev.Kind, ev.Log.TxHash.Hex(), ev.Log.Index, err)
+			}
+		}
+
+		last = to
+		from = to + 1
+	}
+	return last, nil
+}
+
+func (b *Backfiller) events(ch Chain, from, to uint64) ([]Event, error) {
+	type kindQuery struct {
+		kind Kind
+		q    ethereum.FilterQuery
+	}
+	queries := make([]kindQuery, 0, 3)
+	if ch.Registry != (common.Address{}) && len(b.handlers[KindRegistration]) > 0 {
+		queries = append(queries, kindQuery{KindRegistration, query(from, to, []common.Address{ch.Registry}, [][]common.Hash{{TopicRegisteredIdentity}})})
+	}
+	if len(ch.Hermeses) > 0 && len(b.handlers[KindSettlement]) > 0 {
+		queries = append(queries, kindQuery{KindSettlement, query(from, to, ch.Hermeses, [][]common.Hash{{TopicPromiseSettled}})})
+	}
+	if len(ch.Tokens) > 0 && len(ch.Watched) > 0 && len(b.handlers[KindTransfer]) > 0 {
+		recipients := make([]common.Hash, 0, len(ch.Watched))
+		for _, w := range ch.Watched {
+			recipients = append(recipients, common.BytesToHash(w.Bytes()))
+		}
+		queries = append(queries, kindQuery{KindTransfer, query(from, to, ch.Tokens, [][]common.Hash{{TopicTransfer}, nil, recipients})})
+	}
+
+	events := make([]Event, 0)
+	for _, kq := range queries {
+		logs, err := b.indexer.FilterLogs(ch.ChainID, kq.q)
+		if err != nil {
+			return nil, fmt.Errorf("failed to filter %s logs from %d to %d: %w", kq.kind, from, to, err)
+		}
+		for _, l := range logs {
+			if l.Removed {
+				continue
+			}
+			events = append(events, Event{Kind: kq.kind, ChainID: ch.ChainID, Log: l})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		li, lj := events[i].Log, events[j].Log
+		if li.BlockNumber != lj.BlockNumber {
+			return li.BlockNumber < lj.BlockNumber
+		}
+		return li.Index < lj.Index
+	})
+	return events, nil
+}
+
+func (b *Backfiller) replay(ev Event) error {
+	for _, h := range b.handlers[ev.Kind] {
+		if err := h(ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func query(from, to uint64, addresses []common.Address, topics [][]common.Hash) ethereum.FilterQuery {
+	return ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: addresses,
+		Topics:    topics,
+	}
+}
+
+func mustEventID(md *bind.MetaData, name string) common.Hash {
+	parsed, err := md.GetAbi()
+	if err != nil {
+		panic(err)
+	}
+	ev, ok := parsed.Events[name]
+	if !ok {
+		panic("unknown event " + name)
+	}
+	return ev.ID
+}
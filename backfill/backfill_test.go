@@ -0,0 +1,97 @@
+package backfill
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/refund"
+)
+
+type bcMock struct {
+	head    uint64
+	logs    []types.Log
+	queries []ethereum.FilterQuery
+}
+
+func (b *bcMock) BlockNumber() (uint64, error) {
+	return b.head, nil
+}
+
+func (b *bcMock) FilterLogs(q ethereum.FilterQuery) ([]types.Log, error) {
+	b.queries = append(b.queries, q)
+	res := make([]types.Log, 0)
+	for _, l := range b.logs {
+		if l.BlockNumber < q.FromBlock.Uint64() || l.BlockNumber > q.ToBlock.Uint64() || l.Topics[0] != q.Topics[0][0] {
+			continue
+		}
+		res = append(res, l)
+	}
+	return res, nil
+}
+
+func TestBackfill(t *testing.T) {
+	assert.Equal(t, refund.TransferTopic, TopicTransfer)
+
+	registry := common.HexToAddress("0x1")
+	hermes := common.HexToAddress("0x2")
+	token := common.HexToAddress("0x3")
+	watched := common.HexToAddress("0x4")
+
+	bc := &bcMock{
+		head: 110,
+		logs: []types.Log{
+			{Address: hermes, Topics: []common.Hash{TopicPromiseSettled}, BlockNumber: 95, Index: 2},
+			{Address: registry, Topics: []common.Hash{TopicRegisteredIdentity}, BlockNumber: 95, Index: 1},
+			{Address: token, Topics: []common.Hash{TopicTransfer}, BlockNumber: 102},
+			{Address: registry, Topics: []common.Hash{TopicRegisteredIdentity}, BlockNumber: 108},
+			{Address: registry, Topics: []common.Hash{TopicRegisteredIdentity}, BlockNumber: 90},
+		},
+	}
+	cfg := Config{
+		Chains:        []Chain{{ChainID: 137, Registry: registry, Hermeses: []common.Address{hermes}, Tokens: []common.Address{token}, Watched: []common.Address{watched}}},
+		Confirmations: 5,
+		MaxBlockRange: 5,
+	}
+
+	var replayed []Kind
+	b := New(Clients{137: bc}, cfg)
+	record := func(ev Event) error {
+		replayed = append(replayed, ev.Kind)
+		return nil
+	}
+	b.Handle(KindRegistration, record)
+	b.Handle(KindSettlement, record)
+	b.Handle(KindTransfer, record)
+
+	t.Run("replays in order up to confirmed head", func(t *testing.T) {
+		last, err := b.Backfill(map[int64]uint64{137: 90})
+		assert.NoError(t, err)
+		assert.Equal(t, map[int64]uint64{137: 105}, last)
+		assert.Equal(t, []Kind{KindRegistration, KindSettlement, KindTransfer}, replayed)
+
+		transfer := bc.queries[2]
+		assert.Equal(t, []common.Hash{common.BytesToHash(watched.Bytes())}, transfer.Topics[2])
+		assert.Equal(t, uint64(91), bc.queries[0].FromBlock.Uint64())
+		assert.Equal(t, uint64(95), bc.queries[0].ToBlock.Uint64())
+	})
+
+	t.Run("stops at failing block", func(t *testing.T) {
+		bc.head = 120
+		failing := New(Clients{137: bc}, cfg)
+		failing.Handle(KindRegistration, func(Event) error { return errors.New("db down") })
+
+		last, err := failing.Backfill(map[int64]uint64{137: 105})
+		assert.Error(t, err)
+		assert.Equal(t, uint64(107), last[137])
+	})
+
+	t.Run("unknown chain", func(t *testing.T) {
+		_, err := New(Clients{}, cfg).Backfill(nil)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,356 @@
+package beneficiary
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/mysteriumnetwork/payments/transaction"
+)
+
+// DeliverableSetBeneficiary is the delivery type of on-chain beneficiary changes enqueued by the manager.
+const DeliverableSetBeneficiary transaction.DeliverableType = "set_beneficiary"
+
+// Status is the convergence status of an identity beneficiary.
+type Status string
+
+const (
+	// StatusPending means the change was not yet submitted.
+	StatusPending Status = "pending"
+	// StatusSubmitted means the change was submitted and we wait for it to show up on chain.
+	StatusSubmitted Status = "submitted"
+	// StatusConverged means the on-chain beneficiary matches the desired one.
+	StatusConverged Status = "converged"
+	// StatusFailed means the change could not be submitted, it is retried on the next sync.
+	StatusFailed Status = "failed"
+)
+
+// Path is the way the beneficiary change is delivered.
+type Path string
+
+const (
+	// PathHermes asks hermes to change the beneficiary while settling, which does not cost the identity gas.
+	PathHermes Path = "hermes"
+	// PathOnChain sends the registry transaction through the queue.
+	PathOnChain Path = "on_chain"
+)
+
+var (
+	// ErrNotTracked is returned for identities the manager does not track.
+	ErrNotTracked = errors.New("beneficiary not tracked")
+	// ErrOnChainTimeout is set as the last error of on-chain changes which did not show up on chain in time.
+	ErrOnChainTimeout = errors.New("beneficiary change did not show up on chain in time")
+)
+
+// Reader reads the current beneficiary, implemented by `client.MultichainBlockchainClient`.
+type Reader interface {
+	GetBeneficiary(chainID int64, registryAddress, identity common.Address) (common.Address, error)
+}
+
+// Queue enqueues transactions to be sent, implemented by `transaction.Depot`.
+type Queue interface {
+	EnqueueDelivery(req transaction.DeliveryRequest, force bool) (string, error)
+}
+
+// HermesAssist requests hermes to set the beneficiary using the signed request.
+type HermesAssist interface {
+	RequestBeneficiaryChange(req crypto.SetBeneficiaryRequest) error
+}
+
+// RequestSigner creates a signed beneficiary change request for the identity.
+type RequestSigner func(chainID int64, identity, beneficiary common.Address) (*crypto.SetBeneficiaryRequest, error)
+
+// Config configures the beneficiary manager.
+type Config struct {
+	// Registries holds the registry address for every chain.
+	Registries map[int64]common.Address
+	// Senders holds the account sending on-chain changes for every chain.
+	Senders map[int64]common.Address
+	// HermesTimeout is how long to wait for the hermes path to converge before falling back to the on-chain path.
+	HermesTimeout time.Duration
+	// OnChainTimeout is how long to wait for an on-chain change to show up on chain, e.g. if its
+	// transaction was dropped, before it is marked as failed and submitted again. Zero waits forever.
+	OnChainTimeout time.Duration
+	// RecheckInterval is how often converged beneficiaries are checked again, so changes made
+	// elsewhere are reverted to the desired beneficiary. Zero never checks them again.
+	RecheckInterval time.Duration
+	// Interval between syncs once `Run` is called.
+	Interval time.Duration
+}
+
+// State is the tracked state of a single identity beneficiary.
+type State struct {
+	ChainID  int64
+	Identity common.Address
+
+	Desired common.Address
+	OnChain common.Address
+
+	Status     Status
+	Path       Path
+	TrackingID string
+	LastErr    error
+
+	SubmittedAt time.Time
+	UpdatedAt   time.Time
+}
+
+type key struct {
+	chainID  int64
+	identity common.Address
+}
+
+// Manager tracks desired and on-chain beneficiaries and drives them to converge.
+type Manager struct {
+	reader Reader
+	queue  Queue
+	sign   RequestSigner
+	hermes HermesAssist
+	cfg    Config
+
+	states map[key]*State
+	mu     sync.Mutex
+
+	listeners []func(State)
+	logFn     func(error)
+	now       func() time.Time
+
+	once sync.Once
+	stop chan struct{}
+}
+
+// NewManager returns a new beneficiary manager.
+func NewManager(reader Reader, queue Queue, sign RequestSigner, cfg Config) *Manager {
+	return &Manager{
+		reader: reader,
+		queue:  queue,
+		sign:   sign,
+		cfg:    cfg,
+		states: make(map[key]*State),
+		logFn:  func(error) {},
+		now:    time.Now,
+		stop:   make(chan struct{}),
+	}
+}
+
+// AttachHermesAssist enables the hermes assisted path which is tried before the on-chain path.
+func (m *Manager) AttachHermesAssist(h HermesAssist) {
+	m.hermes = h
+}
+
+// AttachLogger allows the caller to attach an optional logger
+// which will receive errors that happen during syncs.
+func (m *Manager) AttachLogger(fn func(err error)) {
+	m.logFn = fn
+}
+
+// OnChange registers a listener which is called every time a tracked state changes status.
+//
+// This method is not thread safe and should be called before `Run`.
+func (m *Manager) OnChange(fn func(State)) {
+	m.listeners = append(m.listeners, fn)
+}
+
+// SetDesired sets the beneficiary the identity should have on the given chain.
+func (m *Manager) SetDesired(chainID int64, identity, beneficiary common.Address) error {
+	if _, ok := m.cfg.Registries[chainID]; !ok {
+		return fmt.Errorf("no registry configured for chain %d", chainID)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	k := key{chainID: chainID, identity: identity}
+	st, ok := m.states[k]
+	if ok && st.Desired == beneficiary {
+		return nil
+	}
+	if !ok {
+		st = &State{ChainID: chainID, Identity: identity}
+		m.states[k] = st
+	}
+	st.Desired = beneficiary
+	st.Status = StatusPending
+	st.Path = ""
+	st.TrackingID = ""
+	st.UpdatedAt = m.now()
+	return nil
+}
+
+// Forget stops tracking the identity beneficiary. A change already submitted is not cancelled.
+func (m *Manager) Forget(chainID int64, identity common.Address) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.states, key{chainID: chainID, identity: identity})
+}
+
+// Status returns the tracked state of the identity beneficiary.
+func (m *Manager) Status(chainID int64, identity common.Address) (State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	st, ok := m.states[key{chainID: chainID, identity: identity}]
+	if !ok {
+		return State{}, ErrNotTracked
+	}
+	return *st, nil
+}
+
+// States returns all of the tracked states.
+func (m *Manager) States() []State {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	res := make([]State, 0, len(m.states))
+	for _, st := range m.states {
+		res = append(res, *st)
+	}
+	return res
+}
+
+// Run will spawn a goroutine which syncs the beneficiaries every interval.
+func (m *Manager) Run() {
+	go func() {
+		for {
+			select {
+			case <-m.stop:
+				return
+			case <-time.After(m.cfg.Interval):
+				m.Sync()
+			}
+		}
+	}()
+}
+
+// Stop stops the sync loop.
+func (m *Manager) Stop() {
+	m.once.Do(func() {
+		close(m.stop)
+	})
+}
+
+// Sync checks every tracked identity which has not converged yet, and the converged ones
+// not checked for the recheck interval, submitting changes where needed.
+func (m *Manager) Sync() {
+	m.mu.Lock()
+	pending := make([]State, 0)
+	for _, st := range m.states {
+		recheck := m.cfg.RecheckInterval > 0 && m.now().Sub(st.UpdatedAt) >= m.cfg.RecheckInterval
+		if st.Status != StatusConverged || recheck {
+			pending = append(pending, *st)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, st := range pending {
+		m.sync(st)
+	}
+}
+
+func (m *Manager) sync(st State) {
+	onChain, err := m.reader.GetBeneficiary(st.ChainID, m.cfg.Registries[st.ChainID], st.Identity)
+	if err != nil {
+		m.logFn(fmt.Errorf("failed to get beneficiary of %s on chain %d: %w", st.Identity.Hex(), st.ChainID, err))
+		return
+	}
+	st.OnChain = onChain
+
+	switch {
+	case onChain == st.Desired:
+		st.Status = StatusConverged
+		st.LastErr = nil
+	case st.Status == StatusSubmitted && st.Path == PathHermes && m.now().Sub(st.SubmittedAt) >= m.cfg.HermesTimeout:
+		st = m.submit(st, PathOnChain)
+	case st.Status == StatusSubmitted && st.Path == PathOnChain && m.cfg.OnChainTimeout > 0 && m.now().Sub(st.SubmittedAt) >= m.cfg.OnChainTimeout:
+		st = m.failed(st, PathOnChain, fmt.Errorf("%w: delivery %q submitted at %s", ErrOnChainTimeout, st.TrackingID, st.SubmittedAt.Format(time.RFC3339)))
+	case st.Status == StatusConverged:
+		// The beneficiary was changed elsewhere since it converged.
+		m.logFn(fmt.Errorf("beneficiary of %s on chain %d changed to %s, restoring %s", st.Identity.Hex(), st.ChainID, onChain.Hex(), st.Desired.Hex()))
+		st = m.submit(st, m.firstPath())
+	case st.Status == StatusPending || st.Status == StatusFailed:
+		path := m.firstPath()
+		if st.Path == PathOnChain {
+			path = PathOnChain
+		}
+		st = m.submit(st, path)
+	}
+
+	m.update(st)
+}
+
+// firstPath returns the path changes are tried with first.
+func (m *Manager) firstPath() Path {
+	if m.hermes != nil {
+		return PathHermes
+	}
+	return PathOnChain
+}
+
+func (m *Manager) submit(st State, path Path) State {
+	req, err := m.sign(st.ChainID, st.Identity, st.Desired)
+	if err != nil {
+		return m.failed(st, path, fmt.Errorf("failed to sign beneficiary change: %w", err))
+	}
+
+	switch path {
+	case PathHermes:
+		if err := m.hermes.RequestBeneficiaryChange(*req); err != nil {
+			m.logFn(fmt.Errorf("hermes beneficiary change failed, falling back to on chain: %w", err))
+			return m.submit(st, PathOnChain)
+		}
+	default:
+		sender, ok := m.cfg.Senders[st.ChainID]
+		if !ok {
+			return m.failed(st, path, fmt.Errorf("no sender configured for chain %d", st.ChainID))
+		}
+		id, err := m.queue.EnqueueDelivery(transaction.DeliveryRequest{
+			ChainID: st.ChainID,
+			Sender:  sender,
+			Type:    DeliverableSetBeneficiary,
+			Data:    req,
+		}, false)
+		if err != nil {
+			return m.failed(st, path, fmt.Errorf("failed to enqueue beneficiary change: %w", err))
+		}
+		st.TrackingID = id
+	}
+
+	st.Status = StatusSubmitted
+	st.Path = path
+	st.SubmittedAt = m.now()
+	st.LastErr = nil
+	return st
+}
+
+func (m *Manager) failed(st State, path Path, err error) State {
+	m.logFn(err)
+	st.Status = StatusFailed
+	st.Path = path
+	st.LastErr = err
+	return st
+}
+
+func (m *Manager) update(st State) {
+	m.mu.Lock()
+	cur, ok := m.states[key{chainID: st.ChainID, identity: st.Identity}]
+	// The desired beneficiary changed during the sync, the next sync will pick it up.
+	if !ok || cur.Desired != st.Desired {
+		m.mu.Unlock()
+		return
+	}
+	changed := cur.Status != st.Status
+	st.UpdatedAt = m.now()
+	*cur = st
+	m.mu.Unlock()
+
+	if changed {
+		for _, fn := range m.listeners {
+			fn(st)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package beneficiary
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/mysteriumnetwork/payments/transaction"
+)
+
+type readerMock struct {
+	beneficiary common.Address
+}
+
+func (r *readerMock) GetBeneficiary(int64, common.Address, common.Address) (common.Address, error) {
+	return r.beneficiary, nil
+}
+
+type queueMock struct {
+	requests []transaction.DeliveryRequest
+}
+
+func (q *queueMock) EnqueueDelivery(req transaction.DeliveryRequest, _ bool) (string, error) {
+	q.requests = append(q.requests, req)
+	return "id", nil
+}
+
+type hermesMock struct {
+	calls int
+	err   error
+}
+
+func (h *hermesMock) RequestBeneficiaryChange(crypto.SetBeneficiaryRequest) error {
+	h.calls++
+	return h.err
+}
+
+func TestManager(t *testing.T) {
+	identity := common.HexToAddress("0x1")
+	desired := common.HexToAddress("0x2")
+	sign := func(chainID int64, identity, beneficiary common.Address) (*crypto.SetBeneficiaryRequest, error) {
+		return &crypto.SetBeneficiaryRequest{ChainID: chainID, Identity: identity.Hex(), Beneficiary: beneficiary.Hex()}, nil
+	}
+	cfg := Config{
+		Registries:    map[int64]common.Address{137: common.HexToAddress("0x3")},
+		Senders:       map[int64]common.Address{137: common.HexToAddress("0x4")},
+		HermesTimeout: time.Minute,
+	}
+
+	t.Run("on chain path", func(t *testing.T) {
+		reader, q := &readerMock{}, &queueMock{}
+		m := NewManager(reader, q, sign, cfg)
+		var statuses []Status
+		m.OnChange(func(s State) { statuses = append(statuses, s.Status) })
+
+		assert.Error(t, m.SetDesired(1, identity, desired))
+		assert.NoError(t, m.SetDesired(137, identity, desired))
+
+		m.Sync()
+		st, err := m.Status(137, identity)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSubmitted, st.Status)
+		assert.Equal(t, PathOnChain, st.Path)
+		assert.Len(t, q.requests, 1)
+		assert.Equal(t, DeliverableSetBeneficiary, q.requests[0].Type)
+
+		m.Sync()
+		assert.Len(t, q.requests, 1)
+
+		reader.beneficiary = desired
+		m.Sync()
+		st, _ = m.Status(137, identity)
+		assert.Equal(t, StatusConverged, st.Status)
+		assert.Equal(t, []Status{StatusSubmitted, StatusConverged}, statuses)
+	})
+
+	t.Run("hermes path falls back after timeout", func(t *testing.T) {
+		now := time.Now()
+		q, h := &queueMock{}, &hermesMock{}
+		m := NewManager(&readerMock{}, q, sign, cfg)
+		m.now = func() time.Time { return now }
+		m.AttachHermesAssist(h)
+		assert.NoError(t, m.SetDesired(137, identity, desired))
+
+		m.Sync()
+		st, _ := m.Status(137, identity)
+		assert.Equal(t, PathHermes, st.Path)
+		assert.Equal(t, 1, h.calls)
+		assert.Empty(t, q.requests)
+
+		now = now.Add(time.Minute)
+		m.Sync()
+		st, _ = m.Status(137, identity)
+		assert.Equal(t, PathOnChain, st.Path)
+		assert.Len(t, q.requests, 1)
+	})
+
+	t.Run("hermes failure uses on chain path", func(t *testing.T) {
+		q := &queueMock{}
+		m := NewManager(&readerMock{}, q, sign, cfg)
+		m.AttachHermesAssist(&hermesMock{err: errors.New("down")})
+		assert.NoError(t, m.SetDesired(137, identity, desired))
+
+		m.Sync()
+		st, _ := m.Status(137, identity)
+		assert.Equal(t, StatusSubmitted, st.Status)
+		assert.Equal(t, PathOnChain, st.Path)
+		assert.Len(t, q.requests, 1)
+	})
+
+	t.Run("resubmits on chain change which does not show up", func(t *testing.T) {
+		now := time.Now()
+		q := &queueMock{}
+		cfg := cfg
+		cfg.OnChainTimeout = time.Hour
+		m := NewManager(&readerMock{}, q, sign, cfg)
+		m.now = func() time.Time { return now }
+		assert.NoError(t, m.SetDesired(137, identity, desired))
+
+		m.Sync()
+		now = now.Add(30 * time.Minute)
+		m.Sync()
+		st, _ := m.Status(137, identity)
+		assert.Equal(t, StatusSubmitted, st.Status)
+
+		now = now.Add(30 * time.Minute)
+		m.Sync()
+		st, _ = m.Status(137, identity)
+		assert.Equal(t, StatusFailed, st.Status)
+		assert.ErrorIs(t, st.LastErr, ErrOnChainTimeout)
+		assert.Len(t, q.requests, 1)
+
+		m.Sync()
+		st, _ = m.Status(137, identity)
+		assert.Equal(t, StatusSubmitted, st.Status)
+		assert.Equal(t, PathOnChain, st.Path)
+		assert.Len(t, q.requests, 2)
+	})
+
+	t.Run("rechecks converged beneficiaries", func(t *testing.T) {
+		now := time.Now()
+		reader, q := &readerMock{beneficiary: desired}, &queueMock{}
+		cfg := cfg
+		cfg.RecheckInterval = time.Hour
+		m := NewManager(reader, q, sign, cfg)
+		m.now = func() time.Time { return now }
+		assert.NoError(t, m.SetDesired(137, identity, desired))
+
+		m.Sync()
+		st, _ := m.Status(137, identity)
+		assert.Equal(t, StatusConverged, st.Status)
+
+		reader.beneficiary = common.HexToAddress("0x5")
+		m.Sync()
+		assert.Empty(t, q.requests)
+
+		now = now.Add(time.Hour)
+		m.Sync()
+		st, _ = m.Status(137, identity)
+		assert.Equal(t, StatusSubmitted, st.Status)
+		assert.Len(t, q.requests, 1)
+	})
+
+	t.Run("untracked", func(t *testing.T) {
+		_, err := NewManager(&readerMock{}, &queueMock{}, sign, cfg).Status(137, identity)
+		assert.ErrorIs(t, err, ErrNotTracked)
+	})
+}
@@ -0,0 +1,165 @@
+package chains
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/mysteriumnetwork/payments/client"
+	"github.com/mysteriumnetwork/payments/transaction/gas"
+)
+
+// Well known chain IDs used by the payments packages.
+const (
+	EthereumMainnet int64 = 1
+	EthereumGoerli  int64 = 5
+	PolygonMainnet  int64 = 137
+	PolygonMumbai   int64 = 80001
+
+	OptimismMainnet int64 = 10
+	OptimismSepolia int64 = 11155420
+	BaseMainnet     int64 = 8453
+	BaseSepolia     int64 = 84532
+	ArbitrumOne     int64 = 42161
+	ArbitrumSepolia int64 = 421614
+)
+
+// ErrUnknownChain is returned when a chain is not registered.
+var ErrUnknownChain = errors.New("unknown chain")
+
+// Chain describes a single EVM chain and everything needed to work with it.
+type Chain struct {
+	ID   int64
+	Name string
+
+	// RPC is a list of endpoints ordered by preference.
+	RPC []string
+
+	// Addresses are the mysterium smart contract addresses deployed on the chain.
+	Addresses client.SmartContractAddresses
+
+	// MaxGasPrice is the upper bound for gas prices on the chain. Can be nil.
+	MaxGasPrice *big.Int
+	// MinGasPrice is the lower bound for gas prices on the chain. Can be nil.
+	MinGasPrice *big.Int
+
+	// APIKeys maps a third party provider name (e.g. etherscan) to its API key.
+	APIKeys map[string]string
+
+	// Explorer is the block explorer of the chain. If not set `DefaultExplorers` are used.
+	Explorer Explorer
+
+	// FeeModel is the fee model of the chain. If not set it is derived from the chain ID.
+	FeeModel gas.FeeModel
+}
+
+// GasFeeModel returns the fee model of the chain.
+func (c Chain) GasFeeModel() gas.FeeModel {
+	if c.FeeModel != "" {
+		return c.FeeModel
+	}
+	return gas.FeeModelForChain(c.ID)
+}
+
+// HasL1Fee returns true if transactions on the chain are additionally charged for L1 data.
+func (c Chain) HasL1Fee() bool {
+	m := c.GasFeeModel()
+	return m == gas.FeeModelOPStack || m == gas.FeeModelArbitrum
+}
+
+// APIKey returns an API key for the given provider or an empty string if none is set.
+func (c Chain) APIKey(provider string) string {
+	return c.APIKeys[provider]
+}
+
+// Registry holds chain definitions keyed by chain ID.
+// It is safe for concurrent use and can be updated at runtime.
+type Registry struct {
+	chains map[int64]Chain
+	mu     sync.RWMutex
+}
+
+// NewRegistry returns a new registry populated with the given chains.
+func NewRegistry(chains ...Chain) *Registry {
+	r := &Registry{
+		chains: make(map[int64]Chain),
+	}
+	r.Set(chains...)
+	return r
+}
+
+// Set adds the given chains to the registry replacing any chains with the same ID.
+func (r *Registry) Set(chains ...Chain) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, c := range chains {
+		r.chains[c.ID] = c
+	}
+}
+
+// Replace replaces all of the registry contents with the given chains.
+func (r *Registry) Replace(chains ...Chain) {
+	fresh := make(map[int64]Chain, len(chains))
+	for _, c := range chains {
+		fresh[c.ID] = c
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.chains = fresh
+}
+
+// Get returns a chain by its ID.
+func (r *Registry) Get(chainID int64) (Chain, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.chains[chainID]
+	if !ok {
+		return Chain{}, ErrUnknownChain
+	}
+	return c, nil
+}
+
+// IDs returns all of the registered chain IDs in ascending order.
+func (r *Registry) IDs() []int64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res := make([]int64, 0, len(r.chains))
+	for id := range r.chains {
+		res = append(res, id)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res
+}
+
+// All returns all of the registered chains ordered by chain ID.
+func (r *Registry) All() []Chain {
+	ids := r.IDs()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res := make([]Chain, 0, len(ids))
+	for _, id := range ids {
+		if c, ok := r.chains[id]; ok {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// AddressKeeper returns an address keeper for all of the currently registered chains.
+func (r *Registry) AddressKeeper() *client.MultiChainAddressKeeper {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	addresses := make(map[int64]client.SmartContractAddresses, len(r.chains))
+	for id, c := range r.chains {
+		addresses[id] = c.Addresses
+	}
+	return client.NewMultiChainAddressKeeper(addresses)
+}
@@ -0,0 +1,57 @@
+package chains
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/payments/client"
+	"github.com/mysteriumnetwork/payments/transaction/gas"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	reg := NewRegistry(
+		Chain{ID: PolygonMainnet, Name: "polygon", APIKeys: map[string]string{"polygonscan": "key"}},
+		Chain{ID: EthereumMainnet, Name: "ethereum"},
+	)
+
+	t.Run("get", func(t *testing.T) {
+		c, err := reg.Get(PolygonMainnet)
+		assert.NoError(t, err)
+		assert.Equal(t, "polygon", c.Name)
+		assert.Equal(t, "key", c.APIKey("polygonscan"))
+		assert.Equal(t, "", c.APIKey("etherscan"))
+
+		_, err = reg.Get(42)
+		assert.ErrorIs(t, err, ErrUnknownChain)
+	})
+
+	t.Run("ordered", func(t *testing.T) {
+		assert.Equal(t, []int64{EthereumMainnet, PolygonMainnet}, reg.IDs())
+		all := reg.All()
+		assert.Len(t, all, 2)
+		assert.Equal(t, "ethereum", all[0].Name)
+	})
+
+	t.Run("set and replace", func(t *testing.T) {
+		reg.Set(Chain{ID: PolygonMumbai, Name: "mumbai"})
+		assert.Equal(t, []int64{EthereumMainnet, PolygonMainnet, PolygonMumbai}, reg.IDs())
+
+		reg.Replace(Chain{ID: EthereumGoerli, Addresses: client.SmartContractAddresses{Myst: common.HexToAddress("0x1")}})
+		assert.Equal(t, []int64{EthereumGoerli}, reg.IDs())
+
+		myst, err := reg.AddressKeeper().GetMystAddress(EthereumGoerli)
+		assert.NoError(t, err)
+		assert.Equal(t, common.HexToAddress("0x1"), myst)
+	})
+}
+
+func TestFeeModel(t *testing.T) {
+	assert.Equal(t, gas.FeeModelEthereum, Chain{ID: PolygonMainnet}.GasFeeModel())
+	assert.Equal(t, gas.FeeModelOPStack, Chain{ID: BaseMainnet}.GasFeeModel())
+	assert.Equal(t, gas.FeeModelArbitrum, Chain{ID: ArbitrumOne}.GasFeeModel())
+	assert.Equal(t, gas.FeeModelOPStack, Chain{ID: 999, FeeModel: gas.FeeModelOPStack}.GasFeeModel())
+
+	assert.True(t, Chain{ID: OptimismMainnet}.HasL1Fee())
+	assert.False(t, Chain{ID: EthereumMainnet}.HasL1Fee())
+}
@@ -0,0 +1,121 @@
+package chains
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ExplorerKind identifies the block explorer software, which determines the URL layout.
+type ExplorerKind string
+
+const (
+	ExplorerEtherscan   ExplorerKind = "etherscan"
+	ExplorerPolygonscan ExplorerKind = "polygonscan"
+	ExplorerBlockscout  ExplorerKind = "blockscout"
+)
+
+// ErrNoExplorer is returned when a chain has no block explorer configured.
+var ErrNoExplorer = errors.New("no block explorer configured")
+
+// DefaultExplorers are the block explorers used for well known chains if none is configured.
+var DefaultExplorers = map[int64]Explorer{
+	EthereumMainnet: {Kind: ExplorerEtherscan, URL: "https://etherscan.io"},
+	EthereumGoerli:  {Kind: ExplorerEtherscan, URL: "https://goerli.etherscan.io"},
+	PolygonMainnet:  {Kind: ExplorerPolygonscan, URL: "https://polygonscan.com"},
+	PolygonMumbai:   {Kind: ExplorerPolygonscan, URL: "https://mumbai.polygonscan.com"},
+	OptimismMainnet: {Kind: ExplorerEtherscan, URL: "https://optimistic.etherscan.io"},
+	OptimismSepolia: {Kind: ExplorerBlockscout, URL: "https://optimism-sepolia.blockscout.com"},
+	BaseMainnet:     {Kind: ExplorerEtherscan, URL: "https://basescan.org"},
+	BaseSepolia:     {Kind: ExplorerEtherscan, URL: "https://sepolia.basescan.org"},
+	ArbitrumOne:     {Kind: ExplorerEtherscan, URL: "https://arbiscan.io"},
+	ArbitrumSepolia: {Kind: ExplorerEtherscan, URL: "https://sepolia.arbiscan.io"},
+}
+
+// Explorer builds links to a block explorer.
+type Explorer struct {
+	Kind ExplorerKind
+	// URL is the base URL of the explorer, e.g. https://polygonscan.com.
+	URL string
+}
+
+// IsZero returns true if the explorer is not set.
+func (e Explorer) IsZero() bool {
+	return e.URL == ""
+}
+
+// TxURL returns a link to the given transaction.
+func (e Explorer) TxURL(hash common.Hash) string {
+	return e.link("tx", hash.Hex())
+}
+
+// AddressURL returns a link to the given address.
+func (e Explorer) AddressURL(address common.Address) string {
+	return e.link("address", address.Hex())
+}
+
+// TokenURL returns a link to the given token.
+func (e Explorer) TokenURL(token common.Address) string {
+	return e.link("token", token.Hex())
+}
+
+// TokenHolderURL returns a link to the balance and transfers of the given token for the given holder.
+func (e Explorer) TokenHolderURL(token, holder common.Address) string {
+	if e.Kind == ExplorerBlockscout {
+		return e.link("address", holder.Hex(), "tokens", token.Hex(), "token-transfers")
+	}
+	return e.TokenURL(token) + "?a=" + holder.Hex()
+}
+
+func (e Explorer) link(parts ...string) string {
+	return strings.TrimSuffix(e.URL, "/") + "/" + strings.Join(parts, "/")
+}
+
+// BlockExplorer returns the block explorer of the chain falling back to `DefaultExplorers`.
+func (c Chain) BlockExplorer() (Explorer, error) {
+	if !c.Explorer.IsZero() {
+		return c.Explorer, nil
+	}
+	if e, ok := DefaultExplorers[c.ID]; ok {
+		return e, nil
+	}
+	return Explorer{}, fmt.Errorf("chain %d: %w", c.ID, ErrNoExplorer)
+}
+
+// Explorer returns a block explorer for the given chain.
+func (r *Registry) Explorer(chainID int64) (Explorer, error) {
+	c, err := r.Get(chainID)
+	if err != nil {
+		return Explorer{}, err
+	}
+	return c.BlockExplorer()
+}
+
+// TxURL returns a link to the given transaction on the given chain.
+func (r *Registry) TxURL(chainID int64, hash common.Hash) (string, error) {
+	e, err := r.Explorer(chainID)
+	if err != nil {
+		return "", err
+	}
+	return e.TxURL(hash), nil
+}
+
+// AddressURL returns a link to the given address on the given chain.
+func (r *Registry) AddressURL(chainID int64, address common.Address) (string, error) {
+	e, err := r.Explorer(chainID)
+	if err != nil {
+		return "", err
+	}
+	return e.AddressURL(address), nil
+}
+
+// TokenURL returns a link to the given token on the given chain.
+func (r *Registry) TokenURL(chainID int64, token common.Address) (string, error) {
+	e, err := r.Explorer(chainID)
+	if err != nil {
+		return "", err
+	}
+	return e.TokenURL(token), nil
+}
@@ -0,0 +1,52 @@
+package chains
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplorer(t *testing.T) {
+	hash := common.HexToHash("0x01")
+	addr := common.HexToAddress("0x1379E8886A944d2D9d440b3d88DF536Aea08d9F3")
+	holder := common.HexToAddress("0x02")
+
+	reg := NewRegistry(
+		Chain{ID: PolygonMainnet},
+		Chain{ID: 100, Explorer: Explorer{Kind: ExplorerBlockscout, URL: "https://gnosis.blockscout.com/"}},
+		Chain{ID: 42},
+	)
+
+	t.Run("default explorer", func(t *testing.T) {
+		link, err := reg.TxURL(PolygonMainnet, hash)
+		assert.NoError(t, err)
+		assert.Equal(t, "https://polygonscan.com/tx/"+hash.Hex(), link)
+
+		link, err = reg.TokenURL(PolygonMainnet, addr)
+		assert.NoError(t, err)
+		assert.Equal(t, "https://polygonscan.com/token/0x1379E8886A944d2D9d440b3d88DF536Aea08d9F3", link)
+
+		e, err := reg.Explorer(PolygonMainnet)
+		assert.NoError(t, err)
+		assert.Equal(t, "https://polygonscan.com/token/"+addr.Hex()+"?a="+holder.Hex(), e.TokenHolderURL(addr, holder))
+	})
+
+	t.Run("configured explorer", func(t *testing.T) {
+		link, err := reg.AddressURL(100, addr)
+		assert.NoError(t, err)
+		assert.Equal(t, "https://gnosis.blockscout.com/address/"+addr.Hex(), link)
+
+		e, err := reg.Explorer(100)
+		assert.NoError(t, err)
+		assert.Equal(t, "https://gnosis.blockscout.com/address/"+holder.Hex()+"/tokens/"+addr.Hex()+"/token-transfers", e.TokenHolderURL(addr, holder))
+	})
+
+	t.Run("missing explorer", func(t *testing.T) {
+		_, err := reg.TxURL(42, hash)
+		assert.ErrorIs(t, err, ErrNoExplorer)
+
+		_, err = reg.TxURL(1337, hash)
+		assert.ErrorIs(t, err, ErrUnknownChain)
+	})
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// AccessListResult is an access list created by the node for a call.
+type AccessListResult struct {
+	AccessList types.AccessList
+	// GasUsed is the gas used by the call with the access list attached.
+	GasUsed uint64
+}
+
+// CreateAccessList creates the access list of the storage slots and accounts the call touches
+// using eth_createAccessList at the pending state. Attaching it to the transaction with
+// `WriteRequest.AccessList` makes those accesses cheaper.
+func CreateAccessList(ctx context.Context, client RPCCaller, msg ethereum.CallMsg) (*AccessListResult, error) {
+	var res struct {
+		AccessList types.AccessList `json:"accessList"`
+		Error      string           `json:"error,omitempty"`
+		GasUsed    hexutil.Uint64   `json:"gasUsed"`
+	}
+	if err := client.CallContext(ctx, &res, "eth_createAccessList", toCallArg(msg), "pending"); err != nil {
+		return nil, fmt.Errorf("could not create access list: %w", err)
+	}
+	if res.Error != "" {
+		return nil, ClassifyError(fmt.Errorf("could not create access list: %s", res.Error))
+	}
+	return &AccessListResult{AccessList: res.AccessList, GasUsed: uint64(res.GasUsed)}, nil
+}
+
+// accessListSigner returns a signer which attaches the access list to every transaction before signing it.
+// Legacy transactions become access list transactions keeping their gas price.
+func accessListSigner(list types.AccessList, signer bind.SignerFn) bind.SignerFn {
+	return func(from common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		return signer(from, withAccessList(tx, list))
+	}
+}
+
+func withAccessList(tx *types.Transaction, list types.AccessList) *types.Transaction {
+	if tx.Type() == types.DynamicFeeTxType {
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:    tx.ChainId(),
+			Nonce:      tx.Nonce(),
+			GasTipCap:  tx.GasTipCap(),
+			GasFeeCap:  tx.GasFeeCap(),
+			Gas:        tx.Gas(),
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: list,
+		})
+	}
+	return types.NewTx(&types.AccessListTx{
+		Nonce:      tx.Nonce(),
+		GasPrice:   tx.GasPrice(),
+		Gas:        tx.Gas(),
+		To:         tx.To(),
+		Value:      tx.Value(),
+		Data:       tx.Data(),
+		AccessList: list,
+	})
+}
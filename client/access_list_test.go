@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/client/mocks"
+)
+
+func TestCreateAccessList(t *testing.T) {
+	m := &rpcCallerMock{result: `{"accessList":[{"address":"0x0000000000000000000000000000000000000001","storageKeys":["0x0000000000000000000000000000000000000000000000000000000000000002"]}],"gasUsed":"0x5208"}`}
+	res, err := CreateAccessList(context.Background(), m, ethereum.CallMsg{})
+	assert.NoError(t, err)
+	assert.Equal(t, "eth_createAccessList", m.method)
+	assert.Equal(t, uint64(21000), res.GasUsed)
+	assert.Equal(t, types.AccessList{{Address: common.HexToAddress("0x1"), StorageKeys: []common.Hash{common.HexToHash("0x2")}}}, res.AccessList)
+
+	m.result = `{"accessList":[],"gasUsed":"0x0","error":"execution reverted"}`
+	_, err = CreateAccessList(context.Background(), m, ethereum.CallMsg{})
+	assert.ErrorIs(t, err, ErrExecutionReverted)
+}
+
+func TestWriteRequestAccessList(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+
+	cl := &mocks.EtherClientMock{
+		SendTransactionFunc: func(_ context.Context, _ *types.Transaction) error {
+			return nil
+		},
+	}
+	nonceFunc := func(_ context.Context, _ common.Address) (uint64, error) {
+		return 0, nil
+	}
+	bc := NewBlockchainWithCustomNonceTracker(NewDefaultEthClientGetter(cl), time.Second, nonceFunc)
+
+	list := types.AccessList{{Address: common.HexToAddress("0x1"), StorageKeys: []common.Hash{common.HexToHash("0x2")}}}
+	wr := WriteRequest{
+		Identity:   from,
+		GasLimit:   50000,
+		GasTip:     big.NewInt(1),
+		BaseFee:    big.NewInt(2),
+		AccessList: list,
+		Signer: func(_ common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			return types.SignTx(tx, signer, key)
+		},
+	}
+	tx, err := bc.TransferMyst(TransferRequest{WriteRequest: wr, MystAddress: common.HexToAddress("0x1"), Recipient: common.HexToAddress("0x3"), Amount: big.NewInt(1)})
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(types.DynamicFeeTxType), tx.Type())
+	assert.Equal(t, list, tx.AccessList())
+	assert.Equal(t, big.NewInt(3), tx.GasFeeCap())
+	sender, err := types.Sender(signer, tx)
+	assert.NoError(t, err)
+	assert.Equal(t, from, sender)
+
+	wr.GasTip, wr.BaseFee, wr.GasPrice = nil, nil, big.NewInt(5)
+	tx, err = bc.TransferEth(EthTransferRequest{WriteRequest: wr, To: common.HexToAddress("0x3"), Amount: big.NewInt(1)})
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(types.AccessListTxType), tx.Type())
+	assert.Equal(t, list, tx.AccessList())
+	assert.Equal(t, big.NewInt(5), tx.GasPrice())
+}
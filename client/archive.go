@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ArchiveFallbackClient wraps the eth client used by the `Blockchain` and routes state
+// queries at historical blocks to an archive endpoint once the wrapped endpoint fails them
+// with `ErrMissingState`, as pruned nodes only keep the state of the recent blocks.
+// Queries at the latest block are never routed to the archive.
+type ArchiveFallbackClient struct {
+	next    EthClientGetter
+	archive EthClientGetter
+}
+
+// NewArchiveFallbackClient returns a new client querying historical state the wrapped client doesn't have from the archive client.
+func NewArchiveFallbackClient(next, archive EthClientGetter) *ArchiveFallbackClient {
+	return &ArchiveFallbackClient{
+		next:    next,
+		archive: archive,
+	}
+}
+
+// Client returns the wrapped client which falls back to the archive for missing historical state.
+func (a *ArchiveFallbackClient) Client() EtherClient {
+	return &archiveFallbackClient{EtherClient: a.next.Client(), archive: a.archive}
+}
+
+// Address returns the address of the wrapped client, if it has one.
+func (a *ArchiveFallbackClient) Address() string {
+	if g, ok := a.next.(AddressableEthClientGetter); ok {
+		return g.Address()
+	}
+	return ""
+}
+
+type archiveFallbackClient struct {
+	EtherClient
+	archive EthClientGetter
+}
+
+// useArchive returns true if a failed query at the block should be repeated on the archive.
+func useArchive(blockNumber *big.Int, err error) bool {
+	return err != nil && blockNumber != nil && blockNumber.Sign() >= 0 && errors.Is(ClassifyError(err), ErrMissingState)
+}
+
+func (c *archiveFallbackClient) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	res, err := c.EtherClient.BalanceAt(ctx, account, blockNumber)
+	if useArchive(blockNumber, err) {
+		return c.archive.Client().BalanceAt(ctx, account, blockNumber)
+	}
+	return res, err
+}
+
+func (c *archiveFallbackClient) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	res, err := c.EtherClient.NonceAt(ctx, account, blockNumber)
+	if useArchive(blockNumber, err) {
+		return c.archive.Client().NonceAt(ctx, account, blockNumber)
+	}
+	return res, err
+}
+
+func (c *archiveFallbackClient) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	res, err := c.EtherClient.StorageAt(ctx, account, key, blockNumber)
+	if useArchive(blockNumber, err) {
+		return c.archive.Client().StorageAt(ctx, account, key, blockNumber)
+	}
+	return res, err
+}
+
+func (c *archiveFallbackClient) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	res, err := c.EtherClient.CodeAt(ctx, account, blockNumber)
+	if useArchive(blockNumber, err) {
+		return c.archive.Client().CodeAt(ctx, account, blockNumber)
+	}
+	return res, err
+}
+
+func (c *archiveFallbackClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	res, err := c.EtherClient.CallContract(ctx, msg, blockNumber)
+	if useArchive(blockNumber, err) {
+		return c.archive.Client().CallContract(ctx, msg, blockNumber)
+	}
+	return res, err
+}
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/client/mocks"
+)
+
+func TestArchiveFallbackClient(t *testing.T) {
+	pruned := &mocks.EtherClientMock{
+		BalanceAtFunc: func(_ context.Context, _ common.Address, blockNumber *big.Int) (*big.Int, error) {
+			if blockNumber == nil {
+				return big.NewInt(1), nil
+			}
+			return nil, errors.New("missing trie node 0x5f3f (path )")
+		},
+		StorageAtFunc: func(_ context.Context, _ common.Address, _ common.Hash, _ *big.Int) ([]byte, error) {
+			return nil, errors.New("connection refused")
+		},
+	}
+	archive := &mocks.EtherClientMock{
+		BalanceAtFunc: func(_ context.Context, _ common.Address, _ *big.Int) (*big.Int, error) {
+			return big.NewInt(2), nil
+		},
+	}
+	cl := NewArchiveFallbackClient(NewDefaultAddressableEthClientGetter("http://pruned", pruned), NewDefaultEthClientGetter(archive))
+	assert.Equal(t, "http://pruned", cl.Address())
+	bc := NewBlockchain(cl, time.Second)
+
+	balance, err := bc.BalanceAt(common.Address{}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(1), balance)
+
+	balance, err = bc.BalanceAt(common.Address{}, big.NewInt(100))
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(2), balance)
+	assert.Len(t, archive.BalanceAtCalls(), 1)
+
+	_, err = bc.StorageAt(common.Address{}, common.Hash{}, big.NewInt(100))
+	assert.EqualError(t, err, "connection refused", "other errors are not routed to the archive")
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// DefaultMaxBatchSize is the default max number of calls sent in a single batch request.
+// Providers limit the size of batches, Infura for example allows up to 100 calls.
+const DefaultMaxBatchSize = 100
+
+// BatchRPCClient sends JSON-RPC batch requests, `*rpc.Client` satisfies it.
+type BatchRPCClient interface {
+	BatchCallContext(ctx context.Context, b []rpc.BatchElem) error
+}
+
+// BatchResult holds the result of a single call of a batch once it was executed.
+type BatchResult[T any] struct {
+	Value T
+	Err   error
+}
+
+// BatchCall groups balance, nonce and receipt lookups into JSON-RPC batch requests,
+// so looking up hundreds of accounts takes a few round trips instead of hundreds.
+type BatchCall struct {
+	client  BatchRPCClient
+	maxSize int
+
+	elems  []rpc.BatchElem
+	decode []func(err error)
+}
+
+// NewBatchCall returns a new empty batch.
+func NewBatchCall(client BatchRPCClient) *BatchCall {
+	return &BatchCall{
+		client:  client,
+		maxSize: DefaultMaxBatchSize,
+	}
+}
+
+// SetMaxBatchSize sets the max number of calls sent in a single batch request,
+// larger batches are split into several requests.
+//
+// This method is not thread safe and should be called before `Execute`.
+func (b *BatchCall) SetMaxBatchSize(size int) {
+	b.maxSize = size
+}
+
+// Len returns the number of calls in the batch.
+func (b *BatchCall) Len() int {
+	return len(b.elems)
+}
+
+// BalanceAt adds a lookup of the balance of the account at the given block, nil being the latest one.
+func (b *BatchCall) BalanceAt(account common.Address, blockNumber *big.Int) *BatchResult[*big.Int] {
+	res := &BatchResult[*big.Int]{}
+	var balance hexutil.Big
+	b.add(func(err error) {
+		if err == nil {
+			res.Value = (*big.Int)(&balance)
+		}
+		res.Err = err
+	}, &balance, "eth_getBalance", account, toBlockNumArg(blockNumber))
+	return res
+}
+
+// NonceAt adds a lookup of the nonce of the account at the given block, nil being the latest one.
+func (b *BatchCall) NonceAt(account common.Address, blockNumber *big.Int) *BatchResult[uint64] {
+	return b.nonce(account, toBlockNumArg(blockNumber))
+}
+
+// PendingNonceAt adds a lookup of the pending nonce of the account.
+func (b *BatchCall) PendingNonceAt(account common.Address) *BatchResult[uint64] {
+	return b.nonce(account, "pending")
+}
+
+func (b *BatchCall) nonce(account common.Address, block string) *BatchResult[uint64] {
+	res := &BatchResult[uint64]{}
+	var nonce hexutil.Uint64
+	b.add(func(err error) {
+		res.Value, res.Err = uint64(nonce), err
+	}, &nonce, "eth_getTransactionCount", account, block)
+	return res
+}
+
+// TransactionReceipt adds a lookup of the receipt of the transaction.
+// The result error is `ethereum.NotFound` if the transaction is not mined yet.
+func (b *BatchCall) TransactionReceipt(hash common.Hash) *BatchResult[*types.Receipt] {
+	res := &BatchResult[*types.Receipt]{}
+	var receipt *types.Receipt
+	b.add(func(err error) {
+		if err == nil && receipt == nil {
+			err = ethereum.NotFound
+		}
+		res.Value, res.Err = receipt, err
+	}, &receipt, "eth_getTransactionReceipt", hash)
+	return res
+}
+
+func (b *BatchCall) add(decode func(err error), result interface{}, method string, args ...interface{}) {
+	b.elems = append(b.elems, rpc.BatchElem{
+		Method: method,
+		Args:   args,
+		Result: result,
+	})
+	b.decode = append(b.decode, decode)
+}
+
+// Execute sends the batch and sets the results of every call.
+// An error is only returned if a batch request failed as a whole,
+// in which case the results of its calls hold the same error.
+func (b *BatchCall) Execute(ctx context.Context) error {
+	size := b.maxSize
+	if size <= 0 {
+		size = len(b.elems)
+	}
+
+	var lastErr error
+	for start := 0; start < len(b.elems); start += size {
+		end := start + size
+		if end > len(b.elems) {
+			end = len(b.elems)
+		}
+
+		chunk := b.elems[start:end]
+		err := b.client.BatchCallContext(ctx, chunk)
+		if err != nil {
+			lastErr = fmt.Errorf("failed to send batch of %d calls: %w", len(chunk), err)
+		}
+		for i, el := range chunk {
+			if err != nil {
+				b.decode[start+i](err)
+				continue
+			}
+			b.decode[start+i](el.Error)
+		}
+	}
+	return lastErr
+}
+
+func toBlockNumArg(number *big.Int) string {
+	if number == nil {
+		return "latest"
+	}
+	if number.Sign() >= 0 {
+		return hexutil.EncodeBig(number)
+	}
+	if number.IsInt64() {
+		return rpc.BlockNumber(number.Int64()).String()
+	}
+	return fmt.Sprintf("<invalid %d>", number)
+}
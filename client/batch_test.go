@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ BatchRPCClient = (*rpc.Client)(nil)
+
+type batchRPCClientMock struct {
+	results map[string]string
+	batches [][]rpc.BatchElem
+	err     error
+}
+
+func (m *batchRPCClientMock) BatchCallContext(_ context.Context, b []rpc.BatchElem) error {
+	m.batches = append(m.batches, b)
+	if m.err != nil {
+		return m.err
+	}
+	for i := range b {
+		res, ok := m.results[b[i].Method]
+		if !ok {
+			b[i].Error = errors.New("method not found")
+			continue
+		}
+		b[i].Error = json.Unmarshal([]byte(res), b[i].Result)
+	}
+	return nil
+}
+
+func Test_BatchCall(t *testing.T) {
+	bc := &batchRPCClientMock{results: map[string]string{
+		"eth_getBalance":            `"0x64"`,
+		"eth_getTransactionCount":   `"0x7"`,
+		"eth_getTransactionReceipt": `null`,
+	}}
+	account := common.HexToAddress("0x1")
+
+	batch := NewBatchCall(bc)
+	batch.SetMaxBatchSize(2)
+	balance := batch.BalanceAt(account, nil)
+	nonce := batch.NonceAt(account, big.NewInt(10))
+	pending := batch.PendingNonceAt(account)
+	receipt := batch.TransactionReceipt(common.HexToHash("0x2"))
+	assert.Equal(t, 4, batch.Len())
+
+	assert.NoError(t, batch.Execute(context.Background()))
+	assert.Len(t, bc.batches, 2)
+	assert.Equal(t, []interface{}{account, "0xa"}, bc.batches[0][1].Args)
+	assert.Equal(t, []interface{}{account, "pending"}, bc.batches[1][0].Args)
+
+	assert.NoError(t, balance.Err)
+	assert.Equal(t, big.NewInt(100), balance.Value)
+	assert.NoError(t, nonce.Err)
+	assert.Equal(t, uint64(7), nonce.Value)
+	assert.Equal(t, uint64(7), pending.Value)
+	assert.ErrorIs(t, receipt.Err, ethereum.NotFound)
+
+	bc.err = errors.New("too many requests")
+	batch = NewBatchCall(bc)
+	balance = batch.BalanceAt(account, nil)
+	assert.ErrorIs(t, batch.Execute(context.Background()), bc.err)
+	assert.ErrorIs(t, balance.Err, bc.err)
+	assert.Nil(t, balance.Value)
+}
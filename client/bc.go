@@ -77,6 +77,7 @@ type BC interface {
 	SubscribeToPromiseSettledEventByChannelID(hermesID common.Address, providerAddresses [][32]byte) (sink chan *bindings.HermesImplementationPromiseSettled, cancel func(), err error)
 	SubscribeToMystTokenTransfers(mystSCAddress common.Address) (chan *bindings.MystTokenTransfer, func(), error)
 	FilterLogs(q ethereum.FilterQuery) ([]types.Log, error)
+	FilterLogsPaged(q ethereum.FilterQuery, maxRange uint64) ([]types.Log, error)
 	FilterHermesRegistered(from uint64, to *uint64, registryID common.Address) ([]bindings.RegistryRegisteredHermes, error)
 	FilterHermesURLUpdated(from uint64, to *uint64, registryID common.Address) ([]bindings.RegistryHermesURLUpdated, error)
 
@@ -84,8 +85,11 @@ type BC interface {
 	BlockNumber() (uint64, error)
 	SuggestGasPrice() (*big.Int, error)
 	HeaderByNumber(number *big.Int) (*types.Header, error)
+	FeeHistory(blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
 	PendingNonceAt(account common.Address) (uint64, error)
 	NonceAt(account common.Address, blockNum *big.Int) (uint64, error)
+	BalanceAt(account common.Address, blockNum *big.Int) (*big.Int, error)
+	StorageAt(account common.Address, key common.Hash, blockNum *big.Int) ([]byte, error)
 	EstimateGas(msg ethereum.CallMsg) (uint64, error)
 
 	TransferMyst(req TransferRequest) (tx *types.Transaction, err error)
@@ -117,6 +121,11 @@ type BC interface {
 
 	MystTokenApprove(req MystApproveReq) (*types.Transaction, error)
 	MystAllowance(mystTokenAddress, holder, spender common.Address) (*big.Int, error)
+	TokenDecimals(tokenAddress common.Address) (uint8, error)
+	TokenBalanceOf(tokenAddress, holder common.Address) (*big.Int, error)
+	TokenAllowance(tokenAddress, holder, spender common.Address) (*big.Int, error)
+	TokenApprove(req TokenApproveReq) (*types.Transaction, error)
+	TokenTransferFrom(req TokenTransferFromReq) (*types.Transaction, error)
 	UniswapV3ExactInputSingle(req UniswapExactInputSingleReq) (*types.Transaction, error)
 	UniswapV3TokenPair(poolAddress common.Address) (*SwapTokenPair, error)
 	UniswapV3PoolFee(poolAddress common.Address) (*big.Int, error)
@@ -215,6 +224,9 @@ type EtherClient interface {
 	// SuggestGasTipCap retrieves the currently suggested 1559 priority fee to allow
 	// a timely execution of a transaction.
 	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	// FeeHistory retrieves the fee market history of the given number of blocks up to the last block,
+	// with the priority fees paid at the given percentiles of each block.
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
 	// EstimateGas tries to estimate the gas needed to execute a specific transaction based on
 	// the current pending state of the backend blockchain. There is no guarantee that this is
 	// the true gas limit requirement as other transactions may be added or removed by miners,
@@ -0,0 +1,252 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DefaultCachedBlocks is the default number of blocks and headers kept by the caching client.
+const DefaultCachedBlocks = 1024
+
+var (
+	decimalsSelector = common.FromHex("0x313ce567")
+	symbolSelector   = common.FromHex("0x95d89b41")
+)
+
+// CachingClient wraps the eth client used by the `Blockchain` and memoizes chain data
+// which never changes: the chain and network IDs, deployed contract code, token decimals
+// and symbols, blocks and headers by hash and by number once they are at least the
+// finality depth below the latest block seen. The cached values are shared, so callers
+// must not modify them.
+type CachingClient struct {
+	next          EthClientGetter
+	finalityDepth uint64
+
+	mu        sync.Mutex
+	chainID   *big.Int
+	networkID *big.Int
+	code      map[common.Address][]byte
+	calls     map[tokenCall][]byte
+
+	headers      *lru.Cache[common.Hash, *types.Header]
+	blocks       *lru.Cache[common.Hash, *types.Block]
+	headersByNum *lru.Cache[uint64, *types.Header]
+	blocksByNum  *lru.Cache[uint64, *types.Block]
+	latest       atomic.Uint64
+}
+
+type tokenCall struct {
+	token    common.Address
+	selector string
+}
+
+// NewCachingClient returns a new caching client keeping up to size blocks and headers,
+// blocks are cached by number once they are finalityDepth blocks deep.
+func NewCachingClient(next EthClientGetter, finalityDepth uint64, size int) *CachingClient {
+	if size <= 0 {
+		size = DefaultCachedBlocks
+	}
+	return &CachingClient{
+		next:          next,
+		finalityDepth: finalityDepth,
+		code:          make(map[common.Address][]byte),
+		calls:         make(map[tokenCall][]byte),
+		headers:       lru.NewCache[common.Hash, *types.Header](size),
+		blocks:        lru.NewCache[common.Hash, *types.Block](size),
+		headersByNum:  lru.NewCache[uint64, *types.Header](size),
+		blocksByNum:   lru.NewCache[uint64, *types.Block](size),
+	}
+}
+
+// Client returns the wrapped client which serves immutable data from the cache.
+func (c *CachingClient) Client() EtherClient {
+	return &cachingClient{EtherClient: c.next.Client(), c: c}
+}
+
+// Address returns the address of the wrapped client, if it has one.
+func (c *CachingClient) Address() string {
+	if a, ok := c.next.(AddressableEthClientGetter); ok {
+		return a.Address()
+	}
+	return ""
+}
+
+// finalized returns true if the block is deep enough below the latest block seen to be cached.
+func (c *CachingClient) finalized(number *big.Int) bool {
+	if number == nil || number.Sign() < 0 || !number.IsUint64() {
+		return false
+	}
+	latest := c.latest.Load()
+	return latest >= c.finalityDepth && number.Uint64() <= latest-c.finalityDepth
+}
+
+func (c *CachingClient) seen(number uint64) {
+	for {
+		latest := c.latest.Load()
+		if number <= latest || c.latest.CompareAndSwap(latest, number) {
+			return
+		}
+	}
+}
+
+type cachingClient struct {
+	EtherClient
+	c *CachingClient
+}
+
+func (cc *cachingClient) ChainID(ctx context.Context) (*big.Int, error) {
+	return cc.constant(ctx, &cc.c.chainID, cc.EtherClient.ChainID)
+}
+
+func (cc *cachingClient) NetworkID(ctx context.Context) (*big.Int, error) {
+	return cc.constant(ctx, &cc.c.networkID, cc.EtherClient.NetworkID)
+}
+
+func (cc *cachingClient) constant(ctx context.Context, cached **big.Int, get func(context.Context) (*big.Int, error)) (*big.Int, error) {
+	cc.c.mu.Lock()
+	v := *cached
+	cc.c.mu.Unlock()
+	if v != nil {
+		return v, nil
+	}
+
+	v, err := get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cc.c.mu.Lock()
+	*cached = v
+	cc.c.mu.Unlock()
+	return v, nil
+}
+
+// CodeAt caches the code of deployed contracts at the latest block.
+func (cc *cachingClient) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	if blockNumber != nil {
+		return cc.EtherClient.CodeAt(ctx, account, blockNumber)
+	}
+
+	cc.c.mu.Lock()
+	code, ok := cc.c.code[account]
+	cc.c.mu.Unlock()
+	if ok {
+		return code, nil
+	}
+
+	code, err := cc.EtherClient.CodeAt(ctx, account, nil)
+	if err != nil || len(code) == 0 {
+		return code, err
+	}
+	cc.c.mu.Lock()
+	cc.c.code[account] = code
+	cc.c.mu.Unlock()
+	return code, nil
+}
+
+// CallContract caches the results of the token `decimals` and `symbol` calls.
+func (cc *cachingClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if msg.To == nil || !(bytes.Equal(msg.Data, decimalsSelector) || bytes.Equal(msg.Data, symbolSelector)) {
+		return cc.EtherClient.CallContract(ctx, msg, blockNumber)
+	}
+
+	key := tokenCall{token: *msg.To, selector: string(msg.Data)}
+	cc.c.mu.Lock()
+	res, ok := cc.c.calls[key]
+	cc.c.mu.Unlock()
+	if ok {
+		return res, nil
+	}
+
+	res, err := cc.EtherClient.CallContract(ctx, msg, blockNumber)
+	if err != nil || len(res) == 0 {
+		return res, err
+	}
+	cc.c.mu.Lock()
+	cc.c.calls[key] = res
+	cc.c.mu.Unlock()
+	return res, nil
+}
+
+func (cc *cachingClient) BlockNumber(ctx context.Context) (uint64, error) {
+	n, err := cc.EtherClient.BlockNumber(ctx)
+	if err == nil {
+		cc.c.seen(n)
+	}
+	return n, err
+}
+
+func (cc *cachingClient) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	if h, ok := cc.c.headers.Get(hash); ok {
+		return h, nil
+	}
+
+	h, err := cc.EtherClient.HeaderByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	cc.c.headers.Add(hash, h)
+	return h, nil
+}
+
+func (cc *cachingClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	finalized := cc.c.finalized(number)
+	if finalized {
+		if h, ok := cc.c.headersByNum.Get(number.Uint64()); ok {
+			return h, nil
+		}
+	}
+
+	h, err := cc.EtherClient.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	if number == nil {
+		cc.c.seen(h.Number.Uint64())
+	}
+	if finalized {
+		cc.c.headersByNum.Add(number.Uint64(), h)
+	}
+	return h, nil
+}
+
+func (cc *cachingClient) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	if b, ok := cc.c.blocks.Get(hash); ok {
+		return b, nil
+	}
+
+	b, err := cc.EtherClient.BlockByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	cc.c.blocks.Add(hash, b)
+	return b, nil
+}
+
+func (cc *cachingClient) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	finalized := cc.c.finalized(number)
+	if finalized {
+		if b, ok := cc.c.blocksByNum.Get(number.Uint64()); ok {
+			return b, nil
+		}
+	}
+
+	b, err := cc.EtherClient.BlockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	if number == nil {
+		cc.c.seen(b.NumberU64())
+	}
+	if finalized {
+		cc.c.blocksByNum.Add(number.Uint64(), b)
+	}
+	return b, nil
+}
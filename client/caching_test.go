@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/client/mocks"
+)
+
+func TestCachingClient(t *testing.T) {
+	token := common.HexToAddress("0x1")
+	var chainIDs, codes, calls, headers int
+	latest := uint64(100)
+	cl := &mocks.EtherClientMock{
+		ChainIDFunc: func(_ context.Context) (*big.Int, error) {
+			chainIDs++
+			return big.NewInt(137), nil
+		},
+		CodeAtFunc: func(_ context.Context, account common.Address, _ *big.Int) ([]byte, error) {
+			codes++
+			if account == token {
+				return []byte{1}, nil
+			}
+			return nil, nil
+		},
+		CallContractFunc: func(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+			calls++
+			return []byte{18}, nil
+		},
+		BlockNumberFunc: func(_ context.Context) (uint64, error) {
+			return latest, nil
+		},
+		HeaderByNumberFunc: func(_ context.Context, number *big.Int) (*types.Header, error) {
+			headers++
+			return &types.Header{Number: number}, nil
+		},
+	}
+	cc := NewCachingClient(NewDefaultAddressableEthClientGetter("http://node", cl), 10, 0)
+	assert.Equal(t, "http://node", cc.Address())
+	c := cc.Client()
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		id, err := c.ChainID(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(137), id)
+	}
+	assert.Equal(t, 1, chainIDs)
+
+	_, _ = c.CodeAt(ctx, token, nil)
+	_, _ = c.CodeAt(ctx, token, nil)
+	_, _ = c.CodeAt(ctx, common.HexToAddress("0x2"), nil)
+	_, _ = c.CodeAt(ctx, common.HexToAddress("0x2"), nil)
+	assert.Equal(t, 3, codes, "empty code is not cached")
+
+	_, _ = c.CallContract(ctx, ethereum.CallMsg{To: &token, Data: decimalsSelector}, nil)
+	_, _ = c.CallContract(ctx, ethereum.CallMsg{To: &token, Data: decimalsSelector}, nil)
+	_, _ = c.CallContract(ctx, ethereum.CallMsg{To: &token, Data: symbolSelector}, nil)
+	_, _ = c.CallContract(ctx, ethereum.CallMsg{To: &token, Data: []byte{1, 2, 3, 4}}, nil)
+	_, _ = c.CallContract(ctx, ethereum.CallMsg{To: &token, Data: []byte{1, 2, 3, 4}}, nil)
+	assert.Equal(t, 4, calls)
+
+	_, _ = c.HeaderByNumber(ctx, big.NewInt(90))
+	_, _ = c.HeaderByNumber(ctx, big.NewInt(90))
+	assert.Equal(t, 2, headers, "nothing is finalized before the latest block is seen")
+
+	_, err := c.BlockNumber(ctx)
+	assert.NoError(t, err)
+	h, err := c.HeaderByNumber(ctx, big.NewInt(90))
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(90), h.Number)
+	_, _ = c.HeaderByNumber(ctx, big.NewInt(90))
+	assert.Equal(t, 3, headers)
+
+	_, _ = c.HeaderByNumber(ctx, big.NewInt(91))
+	_, _ = c.HeaderByNumber(ctx, big.NewInt(91))
+	assert.Equal(t, 5, headers, "blocks within the finality depth are not cached")
+}
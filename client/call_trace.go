@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ErrTracingNotSupported is returned when the endpoint does not serve the debug namespace.
+var ErrTracingNotSupported = errors.New("transaction tracing not supported")
+
+// CallFrame is a call made by a transaction as returned by the call tracer of debug_traceTransaction.
+type CallFrame struct {
+	Type         string          `json:"type"`
+	From         common.Address  `json:"from"`
+	To           *common.Address `json:"to,omitempty"`
+	Value        *hexutil.Big    `json:"value,omitempty"`
+	Gas          hexutil.Uint64  `json:"gas"`
+	GasUsed      hexutil.Uint64  `json:"gasUsed"`
+	Input        hexutil.Bytes   `json:"input"`
+	Output       hexutil.Bytes   `json:"output,omitempty"`
+	Error        string          `json:"error,omitempty"`
+	RevertReason string          `json:"revertReason,omitempty"`
+	Calls        []CallFrame     `json:"calls,omitempty"`
+}
+
+// TraceTransaction returns the call tree of a mined transaction using debug_traceTransaction
+// with the call tracer. Endpoints which don't serve the debug namespace, like most public
+// providers, fail with `ErrTracingNotSupported`.
+func TraceTransaction(ctx context.Context, client RPCCaller, hash common.Hash) (*CallFrame, error) {
+	var res CallFrame
+	err := client.CallContext(ctx, &res, "debug_traceTransaction", hash, map[string]string{"tracer": "callTracer"})
+	if isMethodNotFound(err) {
+		return nil, fmt.Errorf("%w: %w", ErrTracingNotSupported, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not trace transaction %v: %w", hash.Hex(), err)
+	}
+	return &res, nil
+}
+
+// FailingFrame returns the frame where the failure of the call started, or nil if the call did not fail.
+// The last call made by a failed frame is taken as the cause of its failure if that call failed too,
+// earlier failed calls were caught by the frame.
+func (f *CallFrame) FailingFrame() *CallFrame {
+	path := f.failurePath()
+	if len(path) == 0 {
+		return nil
+	}
+	return path[len(path)-1]
+}
+
+// failurePath returns the frames from this one down to the one where its failure started.
+func (f *CallFrame) failurePath() []*CallFrame {
+	if f.Error == "" {
+		return nil
+	}
+	path := []*CallFrame{f}
+	for frame := f; len(frame.Calls) > 0; {
+		last := &frame.Calls[len(frame.Calls)-1]
+		if last.Error == "" {
+			break
+		}
+		path = append(path, last)
+		frame = last
+	}
+	return path
+}
+
+// Reason returns the revert reason of the frame if it was given one, its error otherwise.
+func (f *CallFrame) Reason() string {
+	if f.RevertReason != "" {
+		return f.RevertReason
+	}
+	if reason, ok := DecodeRevert(f.Output); ok {
+		return reason
+	}
+	return f.Error
+}
+
+// TransactionFailure describes the frame at which a transaction failed.
+type TransactionFailure struct {
+	Hash common.Hash
+	// Frame is the call where the failure started.
+	Frame *CallFrame
+	// Depth is the depth of the frame in the call tree, zero for the transaction itself.
+	Depth int
+	// Path holds the addresses called on the way down to the frame.
+	Path []common.Address
+}
+
+// String summarizes the failure, e.g. "call to 0x.. (0xa9059cbb) at depth 1: execution reverted: balance too low".
+func (f *TransactionFailure) String() string {
+	var sb strings.Builder
+	sb.WriteString(strings.ToLower(f.Frame.Type))
+	if f.Frame.To != nil {
+		sb.WriteString(" to " + f.Frame.To.Hex())
+	}
+	if len(f.Frame.Input) >= 4 {
+		sb.WriteString(" (" + hexutil.Encode(f.Frame.Input[:4]) + ")")
+	}
+	fmt.Fprintf(&sb, " at depth %d: %s", f.Depth, f.Frame.Error)
+	if reason := f.Frame.Reason(); reason != f.Frame.Error {
+		sb.WriteString(": " + reason)
+	}
+	return sb.String()
+}
+
+// DiagnoseTransaction traces a mined transaction and returns the frame it failed at,
+// or nil if it did not fail, see `TraceTransaction`.
+func DiagnoseTransaction(ctx context.Context, client RPCCaller, hash common.Hash) (*TransactionFailure, error) {
+	root, err := TraceTransaction(ctx, client, hash)
+	if err != nil {
+		return nil, err
+	}
+	if root.Error == "" {
+		return nil, nil
+	}
+
+	path := root.failurePath()
+	failure := &TransactionFailure{Hash: hash, Frame: path[len(path)-1], Depth: len(path) - 1}
+	for _, frame := range path {
+		if frame.To != nil {
+			failure.Path = append(failure.Path, *frame.To)
+		}
+	}
+	return failure, nil
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const failedSettlementTrace = `{
+	"type": "CALL", "from": "0x0000000000000000000000000000000000000001", "to": "0x0000000000000000000000000000000000000002",
+	"gas": "0x30d40", "gasUsed": "0x1d4c0", "input": "0x12345678", "error": "execution reverted",
+	"calls": [
+		{"type": "STATICCALL", "from": "0x0000000000000000000000000000000000000002", "to": "0x0000000000000000000000000000000000000004",
+			"gas": "0x100", "gasUsed": "0x100", "input": "0x", "error": "out of gas"},
+		{"type": "CALL", "from": "0x0000000000000000000000000000000000000002", "to": "0x0000000000000000000000000000000000000003",
+			"gas": "0x1000", "gasUsed": "0x500", "input": "0xa9059cbb", "error": "execution reverted",
+			"output": "0x08c379a0000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000036c6f770000000000000000000000000000000000000000000000000000000000"}
+	]
+}`
+
+type rpcErrorCaller struct {
+	err error
+}
+
+func (c *rpcErrorCaller) CallContext(context.Context, interface{}, string, ...interface{}) error {
+	return c.err
+}
+
+type methodNotFoundError struct{}
+
+func (methodNotFoundError) Error() string {
+	return "the method debug_traceTransaction does not exist/is not available"
+}
+func (methodNotFoundError) ErrorCode() int { return methodNotFoundCode }
+
+var _ rpc.Error = methodNotFoundError{}
+
+func TestDiagnoseTransaction(t *testing.T) {
+	hash := common.HexToHash("0xabc")
+	m := &rpcCallerMock{result: failedSettlementTrace}
+
+	failure, err := DiagnoseTransaction(context.Background(), m, hash)
+	require.NoError(t, err)
+	assert.Equal(t, "debug_traceTransaction", m.method)
+	assert.Equal(t, hash, m.args[0])
+	assert.Equal(t, map[string]string{"tracer": "callTracer"}, m.args[1])
+
+	assert.Equal(t, 1, failure.Depth)
+	assert.Equal(t, []common.Address{common.HexToAddress("0x2"), common.HexToAddress("0x3")}, failure.Path)
+	assert.Equal(t, "low", failure.Frame.Reason())
+	assert.Equal(t, "call to 0x0000000000000000000000000000000000000003 (0xa9059cbb) at depth 1: execution reverted: low", failure.String())
+
+	m.result = `{"type": "CALL", "from": "0x0000000000000000000000000000000000000001", "gas": "0x1", "gasUsed": "0x1", "input": "0x"}`
+	failure, err = DiagnoseTransaction(context.Background(), m, hash)
+	assert.NoError(t, err)
+	assert.Nil(t, failure)
+}
+
+func TestTraceTransaction_NotSupported(t *testing.T) {
+	_, err := TraceTransaction(context.Background(), &rpcErrorCaller{err: methodNotFoundError{}}, common.Hash{})
+	assert.ErrorIs(t, err, ErrTracingNotSupported)
+}
+
+func TestCallFrame_FailingFrame(t *testing.T) {
+	root := &CallFrame{Error: "execution reverted", Calls: []CallFrame{
+		{Error: "execution reverted", RevertReason: "caught"},
+		{},
+	}}
+	assert.Equal(t, root, root.FailingFrame(), "failed calls which were caught are not the cause")
+	assert.Equal(t, "execution reverted", root.Reason())
+
+	assert.Nil(t, (&CallFrame{}).FailingFrame())
+}
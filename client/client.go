@@ -34,8 +34,8 @@ import (
 	"github.com/mysteriumnetwork/payments/bindings/uniswapv2"
 	"github.com/mysteriumnetwork/payments/bindings/uniswapv3"
 	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/mysteriumnetwork/payments/logging"
 	"github.com/pkg/errors"
-	"github.com/rs/zerolog/log"
 )
 
 // DefaultBackoff is the default backoff for the client
@@ -56,6 +56,12 @@ type Blockchain struct {
 	nonceFunc nonceFunc
 	hir       *hermesImplementationRegistry
 	rr        *registry
+
+	// gasPriceFunc prices write requests without gas prices, see SetGasPriceFunc.
+	gasPriceFunc GasPriceFunc
+
+	// ctx is the parent of every call context, bc.context() if nil.
+	ctx context.Context
 }
 
 type nonceFunc func(ctx context.Context, account common.Address) (uint64, error)
@@ -89,6 +95,22 @@ func (bc *Blockchain) Client() EtherClient {
 	return bc.ethClient.Client()
 }
 
+// WithContext returns a copy of the blockchain which derives every call context from the given one.
+// This allows the caller to cancel calls and propagate values, such as traces, to the eth client.
+// The call timeout still applies.
+func (bc *Blockchain) WithContext(ctx context.Context) *Blockchain {
+	cp := *bc
+	cp.ctx = ctx
+	return &cp
+}
+
+func (bc *Blockchain) context() context.Context {
+	if bc.ctx == nil {
+		return context.Background()
+	}
+	return bc.ctx
+}
+
 // makeTransactOpts creates a new transact opts from the given request
 func (bc *Blockchain) makeTransactOpts(ctx context.Context, rr *WriteRequest) (*bind.TransactOpts, error) {
 	if rr.Nonce == nil {
@@ -98,8 +120,18 @@ func (bc *Blockchain) makeTransactOpts(ctx context.Context, rr *WriteRequest) (*
 		}
 		rr.Nonce = big.NewInt(0).SetUint64(nonceUint)
 	}
+	if err := bc.fillGasPrices(ctx, rr); err != nil {
+		return nil, err
+	}
 
-	return rr.toTransactOpts(ctx), nil
+	to := rr.toTransactOpts(ctx)
+	if len(rr.AccessList) > 0 && to.Signer != nil {
+		to.Signer = accessListSigner(rr.AccessList, to.Signer)
+	}
+	if rr.Simulate && to.Signer != nil {
+		to.Signer = bc.simulatingSigner(ctx, to.Signer)
+	}
+	return to, nil
 }
 
 // GetHermesFee fetches the hermes fee from blockchain
@@ -109,7 +141,7 @@ func (bc *Blockchain) GetHermesFee(hermesAddress common.Address) (uint16, error)
 		return 0, errors.Wrap(err, "could not create hermes implementation caller")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	res, err := caller.LastFee(&bind.CallOpts{
@@ -129,7 +161,7 @@ func (bc *Blockchain) CalculateHermesFee(hermesAddress common.Address, value *bi
 		return nil, errors.Wrap(err, "could not create hermes implementation caller")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	return caller.CalculateHermesFee(&bind.CallOpts{
@@ -171,7 +203,7 @@ func (bc *Blockchain) SubscribeToMystTokenTransfers(mystSCAddress common.Address
 	go func() {
 		subErr := <-sub.Err()
 		if subErr != nil {
-			log.Error().Err(err).Msg("subscription error")
+			logging.Default().Error("subscription error", "error", subErr)
 		}
 		close(sink)
 	}()
@@ -200,7 +232,7 @@ func (bc *Blockchain) SubscribeToConsumerBalanceEvent(channel, mystSCAddress com
 	go func() {
 		subErr := <-sub.Err()
 		if subErr != nil {
-			log.Error().Err(err).Msg("subscription error")
+			logging.Default().Error("subscription error", "error", subErr)
 		}
 		close(sink)
 	}()
@@ -218,7 +250,7 @@ func (bc *Blockchain) GetProviderChannel(hermesAddress common.Address, addressTo
 		return ProviderChannel{}, errors.Wrap(err, "could not create hermes caller")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	ch, err := caller.Channels(&bind.CallOpts{
@@ -239,7 +271,7 @@ func (bc *Blockchain) GetProvidersWithdrawalChannel(hermesAddress common.Address
 		return ProviderChannel{}, errors.Wrap(err, "could not create hermes caller")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	ch, err := caller.Channels(&bind.CallOpts{
@@ -255,29 +287,44 @@ func (bc *Blockchain) getProviderChannelStake(hermesAddress common.Address, addr
 }
 
 func (bc *Blockchain) TransactionByHash(hash common.Hash) (*types.Transaction, bool, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 	return bc.ethClient.Client().TransactionByHash(ctx, hash)
 }
 
 func (bc *Blockchain) TransactionReceipt(hash common.Hash) (*types.Receipt, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 	return bc.ethClient.Client().TransactionReceipt(ctx, hash)
 }
 
 func (bc *Blockchain) PendingNonceAt(account common.Address) (uint64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 	return bc.ethClient.Client().PendingNonceAt(ctx, account)
 }
 
 func (bc *Blockchain) NonceAt(account common.Address, blockNum *big.Int) (uint64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 	return bc.ethClient.Client().NonceAt(ctx, account, blockNum)
 }
 
+// BalanceAt returns the wei balance of the account at the given block, the latest block if it is nil.
+func (bc *Blockchain) BalanceAt(account common.Address, blockNum *big.Int) (*big.Int, error) {
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
+	defer cancel()
+	return bc.ethClient.Client().BalanceAt(ctx, account, blockNum)
+}
+
+// StorageAt returns the value of the key in the contract storage of the account at the given block,
+// the latest block if it is nil.
+func (bc *Blockchain) StorageAt(account common.Address, key common.Hash, blockNum *big.Int) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
+	defer cancel()
+	return bc.ethClient.Client().StorageAt(ctx, account, key, blockNum)
+}
+
 func (bc *Blockchain) getProviderChannelAddressBytes(hermesAddress, addressToCheck common.Address) ([32]byte, error) {
 	addressBytes := [32]byte{}
 
@@ -329,7 +376,7 @@ func (bc *Blockchain) IsRegistered(registryAddress, addressToCheck common.Addres
 		return false, errors.Wrap(err, "could not create registry caller")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	res, err := caller.IsRegistered(&bind.CallOpts{
@@ -344,7 +391,7 @@ func (bc *Blockchain) GetMystBalance(mystAddress, identity common.Address) (*big
 	if err != nil {
 		return nil, err
 	}
-	parent := context.Background()
+	parent := bc.context()
 	ctx, cancel := context.WithTimeout(parent, bc.bcTimeout)
 	defer cancel()
 	return c.BalanceOf(&bind.CallOpts{
@@ -391,6 +438,13 @@ type WriteRequest struct {
 
 	// GasPrice is the legacy gas price pre london hardfork.
 	GasPrice *big.Int
+
+	// Simulate executes the transaction with eth_call before sending it
+	// and fails with the revert reason instead of sending a transaction which would revert.
+	Simulate bool
+
+	// AccessList is attached to the transaction if set, see `CreateAccessList`.
+	AccessList types.AccessList
 }
 
 func (wr *WriteRequest) toTransactOpts(ctx context.Context) *bind.TransactOpts {
@@ -432,7 +486,7 @@ func (bc *Blockchain) RegisterIdentity(rr RegistrationRequest) (*types.Transacti
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	to, err := bc.makeTransactOpts(ctx, &rr.WriteRequest)
@@ -479,7 +533,7 @@ func (bc *Blockchain) OpenConsumerChannel(req OpenConsumerChannelRequest) (*type
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	to, err := bc.makeTransactOpts(ctx, &req.WriteRequest)
@@ -518,7 +572,7 @@ func (bc *Blockchain) PayAndSettle(psr PayAndSettleRequest) (*types.Transaction,
 	if err != nil {
 		return nil, err
 	}
-	parent := context.Background()
+	parent := bc.context()
 	ctx, cancel := context.WithTimeout(parent, bc.bcTimeout)
 	defer cancel()
 
@@ -567,7 +621,7 @@ func (bc *Blockchain) TransferMyst(req TransferRequest) (tx *types.Transaction,
 		return tx, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	to, err := bc.makeTransactOpts(ctx, &req.WriteRequest)
@@ -584,7 +638,7 @@ func (bc *Blockchain) IsHermesRegistered(registryAddress, acccountantID common.A
 	if err != nil {
 		return false, err
 	}
-	parent := context.Background()
+	parent := bc.context()
 	ctx, cancel := context.WithTimeout(parent, bc.bcTimeout)
 	defer cancel()
 	return caller.IsHermes(&bind.CallOpts{
@@ -620,7 +674,7 @@ func (bc *Blockchain) IncreaseProviderStake(req ProviderStakeIncreaseRequest) (*
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	to, err := bc.makeTransactOpts(ctx, &req.WriteRequest)
@@ -672,7 +726,7 @@ func (bc *Blockchain) SettleIntoStake(req SettleIntoStakeRequest) (*types.Transa
 	channelID := [32]byte{}
 	copy(channelID[:], req.Promise.ChannelID)
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	to, err := bc.makeTransactOpts(ctx, &req.WriteRequest)
@@ -708,7 +762,7 @@ func (bc *Blockchain) GetBeneficiary(registryAddress, identity common.Address) (
 		return common.Address{}, err
 	}
 
-	parent := context.Background()
+	parent := bc.context()
 	ctx, cancel := context.WithTimeout(parent, bc.bcTimeout)
 	defer cancel()
 	return caller.GetBeneficiary(&bind.CallOpts{
@@ -724,7 +778,7 @@ func (bc *Blockchain) DecreaseProviderStake(req DecreaseProviderStakeRequest) (*
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	transactor, err := bc.makeTransactOpts(ctx, &req.WriteRequest)
@@ -742,7 +796,7 @@ func (bc *Blockchain) GetHermesOperator(hermesID common.Address) (common.Address
 		return common.Address{}, err
 	}
 
-	parent := context.Background()
+	parent := bc.context()
 	ctx, cancel := context.WithTimeout(parent, bc.bcTimeout)
 	defer cancel()
 	return caller.GetOperator(&bind.CallOpts{
@@ -758,7 +812,7 @@ func (bc *Blockchain) IsHermesActive(hermesID common.Address) (bool, error) {
 		return false, err
 	}
 
-	parent := context.Background()
+	parent := bc.context()
 	ctx, cancel := context.WithTimeout(parent, bc.bcTimeout)
 	defer cancel()
 	return caller.IsHermesActive(&bind.CallOpts{
@@ -794,7 +848,7 @@ func (bc *Blockchain) SettleAndRebalance(req SettleAndRebalanceRequest) (*types.
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	to, err := bc.makeTransactOpts(ctx, &req.WriteRequest)
@@ -824,7 +878,7 @@ func (bc *Blockchain) GetLastRegistryNonce(registry common.Address) (*big.Int, e
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	return caller.LastNonce(&bind.CallOpts{
@@ -840,7 +894,7 @@ func (bc *Blockchain) GetProviderChannelByID(acc common.Address, chID []byte) (P
 		return ProviderChannel{}, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	return caller.Channels(&bind.CallOpts{
@@ -863,7 +917,7 @@ func (bc *Blockchain) GetConsumerChannelsHermes(channelAddress common.Address) (
 		return ConsumersHermes{}, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	return c.Hermes(&bind.CallOpts{Context: ctx})
@@ -876,7 +930,7 @@ func (bc *Blockchain) GetConsumerChannelOperator(channelAddress common.Address)
 		return common.Address{}, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	return c.Operator(&bind.CallOpts{Context: ctx})
@@ -897,7 +951,7 @@ func (bc *Blockchain) SubscribeToIdentityRegistrationEvents(registryAddress comm
 	go func() {
 		subErr := <-sub.Err()
 		if subErr != nil {
-			log.Error().Err(err).Msg("subscription error")
+			logging.Default().Error("subscription error", "error", subErr)
 		}
 		close(sink)
 	}()
@@ -920,7 +974,7 @@ func (bc *Blockchain) SubscribeToConsumerChannelBalanceUpdate(mystSCAddress comm
 	go func() {
 		subErr := <-sub.Err()
 		if subErr != nil {
-			log.Error().Err(err).Msg("subscription error")
+			logging.Default().Error("subscription error", "error", subErr)
 		}
 		close(sink)
 	}()
@@ -955,7 +1009,7 @@ func (bc *Blockchain) SettlePromise(req SettleRequest) (*types.Transaction, erro
 	if err != nil {
 		return nil, err
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	amount := req.Promise.Amount
@@ -982,7 +1036,7 @@ func (bc *Blockchain) SettlePromise(req SettleRequest) (*types.Transaction, erro
 }
 
 func (bc *Blockchain) getNonce(identity common.Address) (uint64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 	return bc.nonceFunc(ctx, identity)
 }
@@ -994,7 +1048,7 @@ func (bc *Blockchain) GetHermesURL(registryID, hermesID common.Address) (string,
 		return "", fmt.Errorf("could not create new registry caller %w", err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	urlBytes, err := caller.GetHermesURL(
@@ -1021,7 +1075,7 @@ func (bc *Blockchain) GetHermes(registryID, hermesID common.Address) (Hermes, er
 		return Hermes{}, fmt.Errorf("could not create new registry caller %w", err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	status, err := caller.GetHermes(&bind.CallOpts{
@@ -1046,7 +1100,7 @@ func (bc *Blockchain) GetHermesRegistry(hermesID common.Address) (common.Address
 		return common.Address{}, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	return caller.GetRegistry(&bind.CallOpts{
@@ -1061,7 +1115,7 @@ func (bc *Blockchain) GetChannelImplementationByVersion(registryID common.Addres
 		return common.Address{}, fmt.Errorf("could not create new registry caller %w", err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	return caller.GetChannelImplementation(&bind.CallOpts{
@@ -1075,7 +1129,7 @@ func (bc *Blockchain) IsChannelOpened(registryID, identity, hermesID common.Addr
 		return false, fmt.Errorf("could not create new registry caller %w", err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	return caller.IsChannelOpened(&bind.CallOpts{
@@ -1099,7 +1153,7 @@ func (bc *Blockchain) SubscribeToPromiseSettledEventByChannelID(hermesID common.
 	go func() {
 		subErr := <-sub.Err()
 		if subErr != nil {
-			log.Error().Err(err).Msg("subscription error")
+			logging.Default().Error("subscription error", "error", subErr)
 		}
 		cancel()
 		close(sink)
@@ -1114,7 +1168,7 @@ func (bc *Blockchain) FilterPromiseSettledEventByChannelID(from uint64, to *uint
 	if err != nil {
 		return nil, errors.Wrap(err, "could not create hermes caller")
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 	iter, err := caller.FilterPromiseSettled(&bind.FilterOpts{
 		Start:   from,
@@ -1134,7 +1188,7 @@ func (bc *Blockchain) FilterPromiseSettledEventByChannelID(from uint64, to *uint
 
 // GetEthBalance gets the current ethereum balance for the address.
 func (bc *Blockchain) GetEthBalance(address common.Address) (*big.Int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 	return bc.ethClient.Client().BalanceAt(ctx, address, nil)
 }
@@ -1148,7 +1202,7 @@ type EthTransferRequest struct {
 
 // TransferEth transfers ethereum to the given address.
 func (bc *Blockchain) TransferEth(etr EthTransferRequest) (*types.Transaction, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	to, err := bc.makeTransactOpts(ctx, &etr.WriteRequest)
@@ -1171,7 +1225,7 @@ func (bc *Blockchain) TransferEth(etr EthTransferRequest) (*types.Transaction, e
 		})
 	}
 
-	signedTx, err := etr.Signer(etr.Identity, tx)
+	signedTx, err := to.Signer(etr.Identity, tx)
 	if err != nil {
 		return nil, fmt.Errorf("could not sign tx: %w", err)
 	}
@@ -1186,7 +1240,7 @@ func (bc *Blockchain) TransferEth(etr EthTransferRequest) (*types.Transaction, e
 
 // FilterLogs executes a filter query.
 func (bc *Blockchain) FilterLogs(q ethereum.FilterQuery) ([]types.Log, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 	return bc.ethClient.Client().FilterLogs(ctx, q)
 }
@@ -1194,27 +1248,35 @@ func (bc *Blockchain) FilterLogs(q ethereum.FilterQuery) ([]types.Log, error) {
 // HeaderByNumber returns a block header from the current canonical chain. If number is
 // nil, the latest known header is returned.
 func (bc *Blockchain) HeaderByNumber(number *big.Int) (*types.Header, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 	return bc.ethClient.Client().HeaderByNumber(ctx, number)
 }
 
+// FeeHistory returns the fee market history of the given number of blocks up to the last block.
+// If the last block is nil, the history ends with the latest known block.
+func (bc *Blockchain) FeeHistory(blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
+	defer cancel()
+	return bc.ethClient.Client().FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+}
+
 func (bc *Blockchain) SuggestGasPrice() (*big.Int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 	return bc.ethClient.Client().SuggestGasPrice(ctx)
 }
 
 // BlockNumber returns the last known block number
 func (bc *Blockchain) BlockNumber() (uint64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 	return bc.ethClient.Client().BlockNumber(ctx)
 }
 
 // NetworkID returns the network id
 func (bc *Blockchain) NetworkID() (*big.Int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 	return bc.ethClient.Client().NetworkID(ctx)
 }
@@ -1277,7 +1339,7 @@ func (bc *Blockchain) GetHermessAvailableBalance(hermesAddress common.Address) (
 		return nil, errors.Wrap(err, "could not create hermes implementation caller")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	res, err := caller.AvailableBalance(&bind.CallOpts{
@@ -1297,7 +1359,7 @@ func (bc *Blockchain) SettleWithBeneficiary(req SettleWithBeneficiaryRequest) (*
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	to, err := bc.makeTransactOpts(ctx, &req.WriteRequest)
@@ -1324,7 +1386,7 @@ func (bc *Blockchain) GetStakeThresholds(hermesID common.Address) (min, max *big
 		return nil, nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	return caller.GetStakeThresholds(&bind.CallOpts{
@@ -1334,7 +1396,7 @@ func (bc *Blockchain) GetStakeThresholds(hermesID common.Address) (min, max *big
 
 // SendTransaction sends a transaction to the blockchain.
 func (bc *Blockchain) SendTransaction(tx *types.Transaction) error {
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	return bc.ethClient.Client().SendTransaction(ctx, tx)
@@ -1354,7 +1416,7 @@ func (bc *Blockchain) RewarderUpdateRoot(req RewarderUpdateRoot) (*types.Transac
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	to, err := bc.makeTransactOpts(ctx, &req.WriteRequest)
@@ -1383,7 +1445,7 @@ func (bc *Blockchain) RewarderAirDrop(req RewarderAirDrop) (*types.Transaction,
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	to, err := bc.makeTransactOpts(ctx, &req.WriteRequest)
@@ -1404,7 +1466,7 @@ func (bc *Blockchain) RewarderTotalPayoutsFor(rewarderAddress common.Address, pa
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	return caller.TotalPayoutsFor(&bind.CallOpts{
@@ -1419,7 +1481,7 @@ func (bc *Blockchain) RewarderTotalClaimed(rewarderAddress common.Address) (*big
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	return caller.TotalClaimed(&bind.CallOpts{
@@ -1433,7 +1495,7 @@ func (bc *Blockchain) RewarderLastRootBlock(rewarderAddress common.Address) (*bi
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	return caller.LastRootBlock(&bind.CallOpts{
@@ -1447,7 +1509,7 @@ func (bc *Blockchain) RewarderClaimRoots(rewarderAddress common.Address, blockNu
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	root, err := caller.ClaimRoots(&bind.CallOpts{
@@ -1472,7 +1534,7 @@ func (bc *Blockchain) CustodyTransferTokens(req CustodyTokensTransfer) (*types.T
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	to, err := bc.makeTransactOpts(ctx, &req.WriteRequest)
@@ -1499,7 +1561,7 @@ func (bc *Blockchain) TopperupperApprovedAddress(topperupperAddress common.Addre
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	res, err := caller.ApprovedAddresses(&bind.CallOpts{
@@ -1527,7 +1589,7 @@ func (bc *Blockchain) TopperupperNativeLimits(topperupperAddress common.Address,
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	res, err := caller.NativeLimits(&bind.CallOpts{
@@ -1549,7 +1611,7 @@ func (bc *Blockchain) TopperupperTokenLimits(topperupperAddress common.Address,
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	res, err := caller.TokenLimits(&bind.CallOpts{
@@ -1580,7 +1642,7 @@ func (bc *Blockchain) TopperupperApproveAddresses(req TopperupperApproveAddresse
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	to, err := bc.makeTransactOpts(ctx, &req.WriteRequest)
@@ -1609,7 +1671,7 @@ func (bc *Blockchain) TopperupperSetManagers(req TopperupperModeratorsReq) (*typ
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	to, err := bc.makeTransactOpts(ctx, &req.WriteRequest)
@@ -1636,7 +1698,7 @@ func (bc *Blockchain) TopperupperTopupNative(req TopperupperTopupNativeReq) (*ty
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	to, err := bc.makeTransactOpts(ctx, &req.WriteRequest)
@@ -1664,7 +1726,7 @@ func (bc *Blockchain) TopperupperTopupToken(req TopperupperTopupTokenReq) (*type
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	to, err := bc.makeTransactOpts(ctx, &req.WriteRequest)
@@ -1692,7 +1754,7 @@ func (bc *Blockchain) MystTokenApprove(req MystApproveReq) (*types.Transaction,
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	to, err := bc.makeTransactOpts(ctx, &req.WriteRequest)
@@ -1709,7 +1771,7 @@ func (bc *Blockchain) MystAllowance(mystTokenAddress, holder, spender common.Add
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	return caller.Allowance(&bind.CallOpts{
@@ -1717,6 +1779,21 @@ func (bc *Blockchain) MystAllowance(mystTokenAddress, holder, spender common.Add
 	}, holder, spender)
 }
 
+// TokenDecimals returns the number of decimals of the ERC-20 token.
+func (bc *Blockchain) TokenDecimals(tokenAddress common.Address) (uint8, error) {
+	caller, err := bindings.NewErc20Caller(tokenAddress, bc.ethClient.Client())
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
+	defer cancel()
+
+	return caller.Decimals(&bind.CallOpts{
+		Context: ctx,
+	})
+}
+
 type UniswapExactInputSingleReq struct {
 	WriteRequest
 	SwapRouterAddress common.Address
@@ -1738,7 +1815,7 @@ func (bc *Blockchain) UniswapV3ExactInputSingle(req UniswapExactInputSingleReq)
 		return nil, err
 	}
 
-	ctx1, cancel1 := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx1, cancel1 := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel1()
 
 	b, err := bc.ethClient.Client().BlockByNumber(ctx1, nil)
@@ -1746,7 +1823,7 @@ func (bc *Blockchain) UniswapV3ExactInputSingle(req UniswapExactInputSingleReq)
 		return nil, err
 	}
 
-	ctx2, cancel2 := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx2, cancel2 := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel2()
 
 	to, err := bc.makeTransactOpts(ctx2, &req.WriteRequest)
@@ -1782,7 +1859,7 @@ func (bc *Blockchain) UniswapV3TokenPair(poolAddress common.Address) (*SwapToken
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	res := &SwapTokenPair{}
@@ -1810,7 +1887,7 @@ func (bc *Blockchain) UniswapV3PoolFee(poolAddress common.Address) (*big.Int, er
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	return caller.Fee(&bind.CallOpts{
@@ -1824,7 +1901,7 @@ func (bc *Blockchain) WMaticBalance(holder, wmaticAddress common.Address) (*big.
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	return caller.BalanceOf(&bind.CallOpts{
@@ -1857,7 +1934,7 @@ func (bc *Blockchain) WMaticWithdraw(req WMaticWithdrawReq) (*types.Transaction,
 		amount = all
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 
 	to, err := bc.makeTransactOpts(ctx, &req.WriteRequest)
@@ -1873,7 +1950,7 @@ func (bc *Blockchain) FilterHermesRegistered(from uint64, to *uint64, registryID
 	if err != nil {
 		return nil, errors.Wrap(err, "could not create registry filterer")
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 	iter, err := caller.FilterRegisteredHermes(&bind.FilterOpts{
 		Start:   from,
@@ -1897,7 +1974,7 @@ func (bc *Blockchain) FilterHermesURLUpdated(from uint64, to *uint64, registryID
 	if err != nil {
 		return nil, errors.Wrap(err, "could not create registry filterer")
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 	iter, err := caller.FilterHermesURLUpdated(&bind.FilterOpts{
 		Start:   from,
@@ -1917,7 +1994,7 @@ func (bc *Blockchain) FilterHermesURLUpdated(from uint64, to *uint64, registryID
 }
 
 func (bc *Blockchain) EstimateGas(msg ethereum.CallMsg) (uint64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel()
 	return bc.ethClient.Client().EstimateGas(ctx, msg)
 }
@@ -1943,7 +2020,7 @@ func (bc *Blockchain) SwapExactTokensForETH(req SwapExactTokensForETHReq) (*type
 		return nil, err
 	}
 
-	ctx1, cancel1 := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx1, cancel1 := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel1()
 
 	b, err := bc.ethClient.Client().BlockByNumber(ctx1, nil)
@@ -1951,7 +2028,7 @@ func (bc *Blockchain) SwapExactTokensForETH(req SwapExactTokensForETHReq) (*type
 		return nil, err
 	}
 
-	ctx2, cancel2 := context.WithTimeout(context.Background(), bc.bcTimeout)
+	ctx2, cancel2 := context.WithTimeout(bc.context(), bc.bcTimeout)
 	defer cancel2()
 
 	to, err := bc.makeTransactOpts(ctx2, &req.WriteRequest)
@@ -76,6 +76,32 @@ func TestClient(t *testing.T) {
 		}
 	})
 
+	t.Run("with context", func(t *testing.T) {
+		type ctxKey struct{}
+		var got context.Context
+		cl := &mocks.EtherClientMock{BlockNumberFunc: func(ctx context.Context) (uint64, error) {
+			got = ctx
+			return 1, ctx.Err()
+		}}
+		bc := NewBlockchain(NewDefaultEthClientGetter(cl), time.Second)
+
+		ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "trace"))
+		withCtx := bc.WithContext(ctx)
+		_, err := withCtx.BlockNumber()
+		assert.NoError(t, err)
+		assert.Equal(t, "trace", got.Value(ctxKey{}))
+		_, hasDeadline := got.Deadline()
+		assert.True(t, hasDeadline)
+
+		cancel()
+		_, err = withCtx.BlockNumber()
+		assert.ErrorIs(t, err, context.Canceled)
+
+		_, err = bc.BlockNumber()
+		assert.NoError(t, err)
+		assert.Nil(t, got.Value(ctxKey{}))
+	})
+
 	t.Run("get channel id", func(t *testing.T) {
 		bc := NewBlockchain(NewDefaultEthClientGetter(&mocks.EtherClientMock{}), time.Second)
 		hermesId := common.HexToAddress("0x80Ed28d84792d8b153bf2F25F0C4B7a1381dE4ab")
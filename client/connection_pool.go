@@ -0,0 +1,194 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ChainDialer connects to the RPC endpoints of a chain, e.g. with `DialEthMultiClientForChain`.
+type ChainDialer func(chainID int64) (EthClientGetter, error)
+
+// ConnectionPool connects to chains lazily on their first use, so a service can start while
+// some of its chains are unreachable. Connections which fail with connection errors, such as
+// a closed websocket, are dialed again on the next use. With a max number of connections set,
+// the least recently used chain is disconnected to make room for a new one, once its calls
+// in flight are done. Clients of the pool should get the chain client for every use
+// instead of keeping it, `MultichainBlockchainClient` does so.
+type ConnectionPool struct {
+	chains   []int64
+	dial     ChainDialer
+	maxConns int
+	timeout  time.Duration
+
+	mu      sync.Mutex
+	conns   map[int64]*poolConn
+	dialing map[int64]*poolDial
+	used    uint64
+}
+
+type poolConn struct {
+	getter   EthClientGetter
+	bc       *Blockchain
+	lastUsed uint64
+	inFlight int
+	dead     bool
+	retired  bool
+	closed   bool
+}
+
+type poolDial struct {
+	done chan struct{}
+	conn *poolConn
+	err  error
+}
+
+// NewConnectionPool returns a new pool of connections to the given chains. Zero max connections means no limit,
+// the call timeout is the one of the `Blockchain` of every chain.
+func NewConnectionPool(chains []int64, dial ChainDialer, maxConnections int, callTimeout time.Duration) *ConnectionPool {
+	return &ConnectionPool{
+		chains:   slices.Clone(chains),
+		dial:     dial,
+		maxConns: maxConnections,
+		timeout:  callTimeout,
+		conns:    make(map[int64]*poolConn),
+		dialing:  make(map[int64]*poolDial),
+	}
+}
+
+// Chains returns the chains served by the pool.
+func (p *ConnectionPool) Chains() []int64 {
+	return slices.Clone(p.chains)
+}
+
+// Get returns the client of the chain, dialing the chain if it is not connected yet
+// or its connection died. Concurrent calls for the same chain share a single dial.
+func (p *ConnectionPool) Get(chainID int64) (*Blockchain, error) {
+	if !slices.Contains(p.chains, chainID) {
+		return nil, ErrUnknownChain
+	}
+
+	p.mu.Lock()
+	if c, ok := p.conns[chainID]; ok && !c.dead {
+		p.touch(c)
+		p.mu.Unlock()
+		return c.bc, nil
+	}
+	if d, ok := p.dialing[chainID]; ok {
+		p.mu.Unlock()
+		<-d.done
+		if d.err != nil {
+			return nil, d.err
+		}
+		return d.conn.bc, nil
+	}
+	d := &poolDial{done: make(chan struct{})}
+	p.dialing[chainID] = d
+	p.mu.Unlock()
+
+	getter, err := p.dial(chainID)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	defer close(d.done)
+
+	delete(p.dialing, chainID)
+	if err != nil {
+		d.err = fmt.Errorf("failed to connect to chain %d: %w", chainID, err)
+		return nil, d.err
+	}
+
+	c := &poolConn{getter: getter}
+	c.bc = NewBlockchain(&poolGetter{pool: p, conn: c}, p.timeout)
+	if old, ok := p.conns[chainID]; ok {
+		p.retire(old)
+	}
+	p.conns[chainID] = c
+	p.touch(c)
+	p.evict()
+
+	d.conn = c
+	return c.bc, nil
+}
+
+// Close disconnects every chain, chains are dialed again on their next use.
+func (p *ConnectionPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for chainID, c := range p.conns {
+		p.retire(c)
+		delete(p.conns, chainID)
+	}
+}
+
+func (p *ConnectionPool) touch(c *poolConn) {
+	p.used++
+	c.lastUsed = p.used
+}
+
+// evict disconnects the least recently used chains over the max number of connections.
+func (p *ConnectionPool) evict() {
+	for p.maxConns > 0 && len(p.conns) > p.maxConns {
+		var lru int64
+		var oldest *poolConn
+		for chainID, c := range p.conns {
+			if oldest == nil || c.lastUsed < oldest.lastUsed {
+				lru, oldest = chainID, c
+			}
+		}
+		p.retire(oldest)
+		delete(p.conns, lru)
+	}
+}
+
+// retire closes the connection once its calls in flight are done.
+func (p *ConnectionPool) retire(c *poolConn) {
+	c.retired = true
+	p.closeIdle(c)
+}
+
+func (p *ConnectionPool) closeIdle(c *poolConn) {
+	if c.retired && c.inFlight == 0 && !c.closed {
+		c.closed = true
+		c.getter.Client().Close()
+	}
+}
+
+// isDeadConnection returns true for errors after which the connection has to be dialed again.
+// Timeouts are not, as they are caused by slow endpoints as well.
+func isDeadConnection(err error) bool {
+	return errors.Is(err, rpc.ErrClientQuit) || (isConnectionError(err) && !IsErrConnectionFailed(err))
+}
+
+// poolGetter counts the calls in flight of a pooled connection and marks it dead on connection errors.
+type poolGetter struct {
+	pool *ConnectionPool
+	conn *poolConn
+}
+
+func (g *poolGetter) Client() EtherClient {
+	return &interceptedClient{EtherClient: g.conn.getter.Client(), intercept: g.intercept}
+}
+
+func (g *poolGetter) intercept(ctx context.Context, _ string, call func(context.Context) error) error {
+	g.pool.mu.Lock()
+	g.conn.inFlight++
+	g.pool.mu.Unlock()
+
+	err := call(ctx)
+
+	g.pool.mu.Lock()
+	defer g.pool.mu.Unlock()
+	g.conn.inFlight--
+	if err != nil && isDeadConnection(err) {
+		g.conn.dead = true
+	}
+	g.pool.closeIdle(g.conn)
+	return err
+}
@@ -0,0 +1,162 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/payments/client/mocks"
+)
+
+type poolDialerMock struct {
+	mu      sync.Mutex
+	dials   map[int64]int
+	closed  map[int64]int
+	err     error
+	balance func(chainID int64) (*big.Int, error)
+}
+
+func newPoolDialerMock() *poolDialerMock {
+	return &poolDialerMock{
+		dials:  make(map[int64]int),
+		closed: make(map[int64]int),
+		balance: func(int64) (*big.Int, error) {
+			return big.NewInt(1), nil
+		},
+	}
+}
+
+func (m *poolDialerMock) dial(chainID int64) (EthClientGetter, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.dials[chainID]++
+	return NewDefaultEthClientGetter(&mocks.EtherClientMock{
+		BalanceAtFunc: func(_ context.Context, _ common.Address, _ *big.Int) (*big.Int, error) {
+			return m.balance(chainID)
+		},
+		CloseFunc: func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			m.closed[chainID]++
+		},
+	}), nil
+}
+
+func (m *poolDialerMock) counts(chainID int64) (dials, closed int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.dials[chainID], m.closed[chainID]
+}
+
+func TestConnectionPool_DialsLazily(t *testing.T) {
+	m := newPoolDialerMock()
+	m.err = errors.New("connection refused")
+	mbc := NewLazyMultichainBlockchainClient(NewConnectionPool([]int64{1, 137}, m.dial, 0, time.Second))
+	assert.ElementsMatch(t, []int64{1, 137}, mbc.GetSupportedChains())
+
+	_, err := mbc.GetEthBalance(137, common.Address{})
+	assert.ErrorContains(t, err, "failed to connect to chain 137")
+
+	m.err = nil
+	balance, err := mbc.GetEthBalance(137, common.Address{})
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(1), balance)
+	_, err = mbc.WithContext(context.Background()).GetEthBalance(137, common.Address{})
+	assert.NoError(t, err)
+
+	dials, _ := m.counts(137)
+	assert.Equal(t, 1, dials)
+	dials, _ = m.counts(1)
+	assert.Equal(t, 0, dials, "unused chains are not dialed")
+
+	_, err = mbc.GetClientByChain(5)
+	assert.ErrorIs(t, err, ErrUnknownChain)
+}
+
+func TestConnectionPool_RedialsDeadConnections(t *testing.T) {
+	m := newPoolDialerMock()
+	pool := NewConnectionPool([]int64{1}, m.dial, 0, time.Second)
+
+	m.balance = func(int64) (*big.Int, error) {
+		return nil, errors.New("dial tcp: connection refused")
+	}
+	bc, err := pool.Get(1)
+	require.NoError(t, err)
+	_, err = bc.GetEthBalance(common.Address{})
+	assert.Error(t, err)
+
+	m.balance = func(int64) (*big.Int, error) {
+		return nil, context.DeadlineExceeded
+	}
+	bc, err = pool.Get(1)
+	require.NoError(t, err)
+	dials, closed := m.counts(1)
+	assert.Equal(t, 2, dials)
+	assert.Equal(t, 1, closed, "the dead connection is closed")
+
+	_, err = bc.GetEthBalance(common.Address{})
+	assert.Error(t, err)
+	_, err = pool.Get(1)
+	require.NoError(t, err)
+	dials, _ = m.counts(1)
+	assert.Equal(t, 2, dials, "timeouts don't kill connections")
+}
+
+func TestConnectionPool_MaxConnections(t *testing.T) {
+	m := newPoolDialerMock()
+	release := make(chan struct{})
+	m.balance = func(chainID int64) (*big.Int, error) {
+		if chainID == 1 {
+			<-release
+		}
+		return big.NewInt(1), nil
+	}
+	pool := NewConnectionPool([]int64{1, 56, 137}, m.dial, 2, time.Second)
+
+	bc, err := pool.Get(1)
+	require.NoError(t, err)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = bc.GetEthBalance(common.Address{})
+	}()
+
+	_, err = pool.Get(56)
+	require.NoError(t, err)
+	// Used last, so the oldest connection is the one of chain 1.
+	_, err = pool.Get(56)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		pool.mu.Lock()
+		defer pool.mu.Unlock()
+		return pool.conns[1].inFlight == 1
+	}, time.Second, time.Millisecond)
+
+	_, err = pool.Get(137)
+	require.NoError(t, err)
+	_, closed := m.counts(1)
+	assert.Equal(t, 0, closed, "calls in flight are finished first")
+
+	close(release)
+	<-done
+	_, closed = m.counts(1)
+	assert.Equal(t, 1, closed)
+	_, closed = m.counts(56)
+	assert.Equal(t, 0, closed)
+
+	pool.Close()
+	_, closed = m.counts(56)
+	assert.Equal(t, 1, closed)
+	_, closed = m.counts(137)
+	assert.Equal(t, 1, closed)
+}
@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ENSRegistryAddress is the address of the ENS registry on Ethereum mainnet and its testnets.
+var ENSRegistryAddress = common.HexToAddress("0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e")
+
+// DefaultENSCacheTTL is the default time resolved names are cached for.
+const DefaultENSCacheTTL = 10 * time.Minute
+
+var (
+	// ErrENSNameNotFound is returned for names which have no resolver or no address set.
+	ErrENSNameNotFound = errors.New("ens name not found")
+	// ErrInvalidENSName is returned for names which can't be resolved.
+	ErrInvalidENSName = errors.New("invalid ens name")
+)
+
+var (
+	ensResolverSelector = crypto.Keccak256([]byte("resolver(bytes32)"))[:4]
+	ensAddrSelector     = crypto.Keccak256([]byte("addr(bytes32)"))[:4]
+)
+
+// ENSResolver resolves ENS names such as "payout.mynode.eth" to addresses, so beneficiaries
+// and destinations can be configured by name. It fails closed: if a name can't be resolved
+// an error is returned, never a zero or stale address.
+type ENSResolver struct {
+	caller   ethereum.ContractCaller
+	registry common.Address
+	ttl      time.Duration
+
+	mu    sync.Mutex
+	cache map[string]ensEntry
+	now   func() time.Time
+}
+
+type ensEntry struct {
+	address common.Address
+	expires time.Time
+}
+
+// NewENSResolver returns a new resolver using the given registry which caches resolved names for the ttl.
+func NewENSResolver(caller ethereum.ContractCaller, registry common.Address, ttl time.Duration) *ENSResolver {
+	if ttl <= 0 {
+		ttl = DefaultENSCacheTTL
+	}
+	return &ENSResolver{
+		caller:   caller,
+		registry: registry,
+		ttl:      ttl,
+		cache:    make(map[string]ensEntry),
+		now:      time.Now,
+	}
+}
+
+// ResolveAddress returns the address of a hex address or of an ENS name.
+func (r *ENSResolver) ResolveAddress(ctx context.Context, nameOrAddress string) (common.Address, error) {
+	if common.IsHexAddress(nameOrAddress) {
+		return common.HexToAddress(nameOrAddress), nil
+	}
+	return r.Resolve(ctx, nameOrAddress)
+}
+
+// Resolve returns the address the ENS name points to.
+func (r *ENSResolver) Resolve(ctx context.Context, name string) (common.Address, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	node, err := NameHash(name)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	r.mu.Lock()
+	e, ok := r.cache[name]
+	r.mu.Unlock()
+	if ok && r.now().Before(e.expires) {
+		return e.address, nil
+	}
+
+	resolver, err := r.call(ctx, r.registry, ensResolverSelector, node)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("could not get resolver of %q: %w", name, err)
+	}
+	if resolver == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("%w: %q has no resolver", ErrENSNameNotFound, name)
+	}
+	address, err := r.call(ctx, resolver, ensAddrSelector, node)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("could not resolve %q: %w", name, err)
+	}
+	if address == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("%w: %q has no address", ErrENSNameNotFound, name)
+	}
+
+	r.mu.Lock()
+	r.cache[name] = ensEntry{address: address, expires: r.now().Add(r.ttl)}
+	r.mu.Unlock()
+	return address, nil
+}
+
+func (r *ENSResolver) call(ctx context.Context, contract common.Address, selector []byte, node common.Hash) (common.Address, error) {
+	res, err := r.caller.CallContract(ctx, ethereum.CallMsg{
+		To:   &contract,
+		Data: append(append([]byte{}, selector...), node.Bytes()...),
+	}, nil)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(res) != common.HashLength {
+		return common.Address{}, fmt.Errorf("unexpected result length %d", len(res))
+	}
+	return common.BytesToAddress(res), nil
+}
+
+// NameHash returns the ENS namehash of a lowercase name. Only ASCII names are supported
+// as the full name normalization is not implemented.
+func NameHash(name string) (common.Hash, error) {
+	if name == "" {
+		return common.Hash{}, fmt.Errorf("%w: empty name", ErrInvalidENSName)
+	}
+
+	var node common.Hash
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		label := labels[i]
+		if label == "" {
+			return common.Hash{}, fmt.Errorf("%w: %q has an empty label", ErrInvalidENSName, name)
+		}
+		for _, c := range label {
+			if c > 0x7f || (c >= 'A' && c <= 'Z') {
+				return common.Hash{}, fmt.Errorf("%w: %q is not a normalized ascii name", ErrInvalidENSName, name)
+			}
+		}
+		node = crypto.Keccak256Hash(node.Bytes(), crypto.Keccak256([]byte(label)))
+	}
+	return node, nil
+}
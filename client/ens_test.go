@@ -0,0 +1,79 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/client/mocks"
+)
+
+func TestNameHash(t *testing.T) {
+	node, err := NameHash("eth")
+	assert.NoError(t, err)
+	assert.Equal(t, common.HexToHash("0x93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae"), node)
+
+	node, err = NameHash("foo.eth")
+	assert.NoError(t, err)
+	assert.Equal(t, common.HexToHash("0xde9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f"), node)
+
+	for _, name := range []string{"", "foo..eth", "Foo.eth", "föo.eth"} {
+		_, err = NameHash(name)
+		assert.ErrorIs(t, err, ErrInvalidENSName, name)
+	}
+}
+
+func TestENSResolver(t *testing.T) {
+	resolver, payout := common.HexToAddress("0x1"), common.HexToAddress("0x2")
+	var calls int
+	var callErr error
+	cl := &mocks.EtherClientMock{
+		CallContractFunc: func(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+			calls++
+			if callErr != nil {
+				return nil, callErr
+			}
+			switch {
+			case *msg.To == ENSRegistryAddress && bytes.Equal(msg.Data[:4], ensResolverSelector):
+				return common.LeftPadBytes(resolver.Bytes(), 32), nil
+			case *msg.To == resolver && bytes.Equal(msg.Data[:4], ensAddrSelector):
+				node, _ := NameHash("payout.mynode.eth")
+				if common.BytesToHash(msg.Data[4:]) == node {
+					return common.LeftPadBytes(payout.Bytes(), 32), nil
+				}
+				return make([]byte, 32), nil
+			}
+			return nil, errors.New("unexpected call")
+		},
+	}
+	r := NewENSResolver(cl, ENSRegistryAddress, time.Minute)
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	addr, err := r.ResolveAddress(context.Background(), "payout.mynode.eth")
+	assert.NoError(t, err)
+	assert.Equal(t, payout, addr)
+	_, _ = r.Resolve(context.Background(), "payout.mynode.eth")
+	assert.Equal(t, 2, calls, "resolved names are cached")
+
+	addr, err = r.ResolveAddress(context.Background(), payout.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, payout, addr)
+	assert.Equal(t, 2, calls)
+
+	_, err = r.Resolve(context.Background(), "other.mynode.eth")
+	assert.ErrorIs(t, err, ErrENSNameNotFound)
+
+	now = now.Add(2 * time.Minute)
+	callErr = errors.New("connection refused")
+	addr, err = r.Resolve(context.Background(), "payout.mynode.eth")
+	assert.ErrorIs(t, err, callErr, "expired names are not served when the lookup fails")
+	assert.Equal(t, common.Address{}, addr)
+}
@@ -0,0 +1,157 @@
+package client
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+
+	"github.com/mysteriumnetwork/payments/bindings"
+)
+
+// GasPriceFunc returns the tip and base fee to pay for transactions, the tip is used
+// as the legacy gas price if the base fee is nil. A `gas.Station` can be adapted using:
+//
+//	func(ctx context.Context) (*big.Int, *big.Int, error) {
+//		prices, err := station.GetGasPrices(chainID)
+//		if err != nil {
+//			return nil, nil, err
+//		}
+//		return prices.Average, prices.BaseFee, nil
+//	}
+type GasPriceFunc func(ctx context.Context) (tip, baseFee *big.Int, err error)
+
+// SetGasPriceFunc sets the func used to price write requests which set neither a gas tip
+// nor a gas price. Without it they are priced by the eth client suggestions.
+//
+// This method is not thread safe and should be called before the client is used.
+func (bc *Blockchain) SetGasPriceFunc(fn GasPriceFunc) {
+	bc.gasPriceFunc = fn
+}
+
+func (bc *Blockchain) fillGasPrices(ctx context.Context, wr *WriteRequest) error {
+	if bc.gasPriceFunc == nil || wr.GasTip != nil || wr.GasPrice != nil {
+		return nil
+	}
+
+	tip, baseFee, err := bc.gasPriceFunc(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not get gas prices")
+	}
+	if baseFee == nil {
+		wr.GasPrice = tip
+		return nil
+	}
+	wr.GasTip, wr.BaseFee = tip, baseFee
+	return nil
+}
+
+// TokenBalanceOf returns the ERC-20 token balance of the holder.
+func (bc *Blockchain) TokenBalanceOf(tokenAddress, holder common.Address) (*big.Int, error) {
+	caller, err := bindings.NewErc20Caller(tokenAddress, bc.ethClient.Client())
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
+	defer cancel()
+
+	return caller.BalanceOf(&bind.CallOpts{
+		Context: ctx,
+	}, holder)
+}
+
+// TokenAllowance returns the amount of ERC-20 tokens of the holder the spender is allowed to transfer.
+func (bc *Blockchain) TokenAllowance(tokenAddress, holder, spender common.Address) (*big.Int, error) {
+	caller, err := bindings.NewErc20Caller(tokenAddress, bc.ethClient.Client())
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
+	defer cancel()
+
+	return caller.Allowance(&bind.CallOpts{
+		Context: ctx,
+	}, holder, spender)
+}
+
+// TokenApproveReq contains all the parameters for an ERC-20 approval.
+type TokenApproveReq struct {
+	WriteRequest
+	TokenAddress common.Address
+	Spender      common.Address
+	Amount       *big.Int
+}
+
+func (r TokenApproveReq) toEstimator(ethClient EthClientGetter) (*bindings.ContractEstimator, error) {
+	return bindings.NewContractEstimator(r.TokenAddress, bindings.Erc20ABI, ethClient.Client())
+}
+
+func (r TokenApproveReq) toEstimateOps() *bindings.EstimateOpts {
+	return &bindings.EstimateOpts{
+		From:   r.Identity,
+		Method: "approve",
+		Params: []interface{}{r.Spender, r.Amount},
+	}
+}
+
+// TokenApprove allows the spender to transfer the given amount of ERC-20 tokens of the identity.
+func (bc *Blockchain) TokenApprove(req TokenApproveReq) (*types.Transaction, error) {
+	txer, err := bindings.NewErc20Transactor(req.TokenAddress, bc.ethClient.Client())
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
+	defer cancel()
+
+	to, err := bc.makeTransactOpts(ctx, &req.WriteRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	return txer.Approve(to, req.Spender, req.Amount)
+}
+
+// TokenTransferFromReq contains all the parameters for an ERC-20 transfer on behalf of another holder.
+type TokenTransferFromReq struct {
+	WriteRequest
+	TokenAddress common.Address
+	From         common.Address
+	Recipient    common.Address
+	Amount       *big.Int
+}
+
+func (r TokenTransferFromReq) toEstimator(ethClient EthClientGetter) (*bindings.ContractEstimator, error) {
+	return bindings.NewContractEstimator(r.TokenAddress, bindings.Erc20ABI, ethClient.Client())
+}
+
+func (r TokenTransferFromReq) toEstimateOps() *bindings.EstimateOpts {
+	return &bindings.EstimateOpts{
+		From:   r.Identity,
+		Method: "transferFrom",
+		Params: []interface{}{r.From, r.Recipient, r.Amount},
+	}
+}
+
+// TokenTransferFrom transfers ERC-20 tokens of the holder the identity was approved to spend.
+func (bc *Blockchain) TokenTransferFrom(req TokenTransferFromReq) (*types.Transaction, error) {
+	txer, err := bindings.NewErc20Transactor(req.TokenAddress, bc.ethClient.Client())
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(bc.context(), bc.bcTimeout)
+	defer cancel()
+
+	to, err := bc.makeTransactOpts(ctx, &req.WriteRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	return txer.TransferFrom(to, req.From, req.Recipient, req.Amount)
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/bindings"
+	"github.com/mysteriumnetwork/payments/client/mocks"
+)
+
+func TestERC20Helpers(t *testing.T) {
+	erc20ABI, err := bindings.Erc20MetaData.GetAbi()
+	assert.NoError(t, err)
+
+	var sent []*types.Transaction
+	cl := &mocks.EtherClientMock{
+		CallContractFunc: func(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+			method, err := erc20ABI.MethodById(msg.Data[:4])
+			if err != nil {
+				return nil, err
+			}
+			return method.Outputs.Pack(big.NewInt(42))
+		},
+		CodeAtFunc: func(_ context.Context, _ common.Address, _ *big.Int) ([]byte, error) {
+			return []byte{1}, nil
+		},
+		SendTransactionFunc: func(_ context.Context, tx *types.Transaction) error {
+			sent = append(sent, tx)
+			return nil
+		},
+	}
+	nonceFunc := func(_ context.Context, _ common.Address) (uint64, error) {
+		return 7, nil
+	}
+	bc := NewBlockchainWithCustomNonceTracker(NewDefaultEthClientGetter(cl), time.Second, nonceFunc)
+
+	token, holder, spender := common.HexToAddress("0x1"), common.HexToAddress("0x2"), common.HexToAddress("0x3")
+	balance, err := bc.TokenBalanceOf(token, holder)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(42), balance)
+	allowance, err := bc.TokenAllowance(token, holder, spender)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(42), allowance)
+
+	baseFee := big.NewInt(100)
+	bc.SetGasPriceFunc(func(_ context.Context) (*big.Int, *big.Int, error) {
+		return big.NewInt(2), baseFee, nil
+	})
+	wr := WriteRequest{
+		Identity: spender,
+		GasLimit: 60000,
+		Signer: func(_ common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			return tx, nil
+		},
+	}
+
+	tx, err := bc.TokenApprove(TokenApproveReq{WriteRequest: wr, TokenAddress: token, Spender: spender, Amount: big.NewInt(5)})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), tx.Nonce())
+	assert.Equal(t, big.NewInt(2), tx.GasTipCap())
+	assert.Equal(t, big.NewInt(102), tx.GasFeeCap())
+	assert.Equal(t, token, *tx.To())
+
+	baseFee = nil
+	tx, err = bc.TokenTransferFrom(TokenTransferFromReq{WriteRequest: wr, TokenAddress: token, From: holder, Recipient: spender, Amount: big.NewInt(5)})
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(types.LegacyTxType), tx.Type())
+	assert.Equal(t, big.NewInt(2), tx.GasPrice())
+	args, err := erc20ABI.Methods["transferFrom"].Inputs.Unpack(tx.Data()[4:])
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{holder, spender, big.NewInt(5)}, args)
+	assert.Len(t, sent, 2)
+}
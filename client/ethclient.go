@@ -19,36 +19,133 @@ package client
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
+// EndpointAuth holds the credentials sent to an RPC endpoint with every HTTP request
+// and websocket handshake, for private providers and nodes behind auth proxies.
+type EndpointAuth struct {
+	// Headers are extra HTTP headers, e.g. an API key header of the provider.
+	Headers map[string]string
+	// Username and Password are sent with basic auth if the username is set.
+	Username string
+	Password string
+	// BearerToken is sent in the authorization header if set.
+	BearerToken string
+}
+
+func (a EndpointAuth) dialOptions() ([]rpc.ClientOption, error) {
+	if a.Username != "" && a.BearerToken != "" {
+		return nil, errors.New("can't use both basic auth and a bearer token")
+	}
+
+	var options []rpc.ClientOption
+	for k, v := range a.Headers {
+		options = append(options, rpc.WithHeader(k, v))
+	}
+	switch {
+	case a.Username != "":
+		credentials := base64.StdEncoding.EncodeToString([]byte(a.Username + ":" + a.Password))
+		options = append(options, rpc.WithHeader("Authorization", "Basic "+credentials))
+	case a.BearerToken != "":
+		options = append(options, rpc.WithHeader("Authorization", "Bearer "+a.BearerToken))
+	}
+	return options, nil
+}
+
+// ErrChainIDMismatch is returned when an endpoint serves another chain than the configured one.
+var ErrChainIDMismatch = errors.New("chain ID mismatch")
+
+// ChainIDMismatchError is returned when the chain ID of an endpoint is not the expected one,
+// for example when the endpoint of another chain was configured by mistake.
+type ChainIDMismatchError struct {
+	Address  string
+	Expected int64
+	Actual   int64
+}
+
+func (e *ChainIDMismatchError) Error() string {
+	return fmt.Sprintf("%v: endpoint %q serves chain %d instead of %d", ErrChainIDMismatch, e.Address, e.Actual, e.Expected)
+}
+
+func (e *ChainIDMismatchError) Unwrap() error {
+	return ErrChainIDMismatch
+}
+
 // NewReconnectableEthClient creates new ethereum client that can reconnect.
 func NewReconnectableEthClient(address string, connectTimeout time.Duration) (*ReconnectableEthClient, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
-	defer cancel()
+	return NewReconnectableEthClientWithAuth(address, connectTimeout, EndpointAuth{})
+}
 
-	ec, err := ethclient.DialContext(ctx, address)
+// NewReconnectableEthClientWithAuth creates new ethereum client that can reconnect
+// which authenticates to the endpoint with the given credentials.
+func NewReconnectableEthClientWithAuth(address string, connectTimeout time.Duration, auth EndpointAuth) (*ReconnectableEthClient, error) {
+	return NewReconnectableEthClientForChain(address, 0, connectTimeout, auth)
+}
+
+// NewReconnectableEthClientForChain creates new ethereum client that can reconnect like
+// `NewReconnectableEthClientWithAuth`, which verifies the endpoint serves the given chain.
+// The chain ID is checked on every connection, a `ChainIDMismatchError` is returned
+// if it differs. A zero chain ID skips the check.
+func NewReconnectableEthClientForChain(address string, chainID int64, connectTimeout time.Duration, auth EndpointAuth) (*ReconnectableEthClient, error) {
+	options, err := auth.dialOptions()
 	if err != nil {
-		return nil, fmt.Errorf("ethereum client failed to connect: %w", err)
+		return nil, fmt.Errorf("invalid endpoint auth: %w", err)
 	}
 
-	return &ReconnectableEthClient{
+	c := &ReconnectableEthClient{
 		address: address,
-		client:  ec,
-	}, nil
+		chainID: chainID,
+		options: options,
+	}
+	c.client, err = c.dial(connectTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("ethereum client failed to connect: %w", err)
+	}
+	return c, nil
 }
 
 // ReconnectableEthClient is a ethereum client that can reconnect.
 type ReconnectableEthClient struct {
 	address string
+	chainID int64
+	options []rpc.ClientOption
 	mu      sync.Mutex
 	client  *ethclient.Client
 }
 
+func (c *ReconnectableEthClient) dial(connectTimeout time.Duration) (*ethclient.Client, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+	defer cancel()
+
+	rc, err := rpc.DialOptions(ctx, c.address, c.options...)
+	if err != nil {
+		return nil, err
+	}
+	client := ethclient.NewClient(rc)
+	if c.chainID == 0 {
+		return client, nil
+	}
+
+	id, err := client.ChainID(ctx)
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+	if !id.IsInt64() || id.Int64() != c.chainID {
+		client.Close()
+		return nil, &ChainIDMismatchError{Address: c.address, Expected: c.chainID, Actual: id.Int64()}
+	}
+	return client, nil
+}
+
 // Client returns the currently connected ethereum client.
 func (c *ReconnectableEthClient) Client() EtherClient {
 	c.mu.Lock()
@@ -57,6 +154,15 @@ func (c *ReconnectableEthClient) Client() EtherClient {
 	return c.client
 }
 
+// RPCClient returns the raw RPC client of the currently connected ethereum client,
+// it can be used for batch requests with `NewBatchCall`.
+func (c *ReconnectableEthClient) RPCClient() *rpc.Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.client.Client()
+}
+
 // Address returns the current address which was used to create the client.
 func (c *ReconnectableEthClient) Address() string {
 	c.mu.Lock()
@@ -66,14 +172,12 @@ func (c *ReconnectableEthClient) Address() string {
 }
 
 // Reconnect creates new ethereum client and replaces the current one.
+// The current client is kept if the new one fails to connect or serves another chain.
 func (c *ReconnectableEthClient) Reconnect(connectTimeout time.Duration) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
-	defer cancel()
-
-	client, err := ethclient.DialContext(ctx, c.address)
+	client, err := c.dial(connectTimeout)
 	if err != nil {
 		return fmt.Errorf("ethereum client failed to dial: %w", err)
 	}
@@ -18,6 +18,10 @@
 package client
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -47,3 +51,63 @@ func TestReconnectableEthClientCreatesNewClient(t *testing.T) {
 	assert.Equal(t, a1, a3)
 	assert.Equal(t, a2, a3)
 }
+
+func TestReconnectableEthClientWithAuth(t *testing.T) {
+	var headers []http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Clone())
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x89"}`))
+	}))
+	defer srv.Close()
+
+	client, err := NewReconnectableEthClientWithAuth(srv.URL, time.Second, EndpointAuth{
+		Headers:  map[string]string{"X-Api-Key": "key"},
+		Username: "user",
+		Password: "pass",
+	})
+	assert.NoError(t, err)
+	_, err = client.Client().ChainID(context.Background())
+	assert.NoError(t, err)
+
+	assert.NoError(t, client.Reconnect(time.Second))
+	_, err = client.Client().ChainID(context.Background())
+	assert.NoError(t, err)
+
+	assert.Len(t, headers, 2)
+	for _, h := range headers {
+		assert.Equal(t, "key", h.Get("X-Api-Key"))
+		assert.Equal(t, "Basic dXNlcjpwYXNz", h.Get("Authorization"))
+	}
+
+	_, err = NewReconnectableEthClientWithAuth(srv.URL, time.Second, EndpointAuth{Username: "user", BearerToken: "token"})
+	assert.Error(t, err)
+}
+
+func TestReconnectableEthClientForChain(t *testing.T) {
+	var chainID atomic.Value
+	chainID.Store("0x89")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"` + chainID.Load().(string) + `"}`))
+	}))
+	defer srv.Close()
+
+	client, err := NewReconnectableEthClientForChain(srv.URL, 137, time.Second, EndpointAuth{})
+	assert.NoError(t, err)
+	current := client.Client()
+
+	chainID.Store("0x1")
+	err = client.Reconnect(time.Second)
+	assert.ErrorIs(t, err, ErrChainIDMismatch)
+	var mismatch *ChainIDMismatchError
+	assert.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, &ChainIDMismatchError{Address: srv.URL, Expected: 137, Actual: 1}, mismatch)
+	assert.Equal(t, current, client.Client(), "the client of the right chain is kept")
+
+	_, err = NewReconnectableEthClientForChain(srv.URL, 137, time.Second, EndpointAuth{})
+	assert.ErrorIs(t, err, ErrChainIDMismatch)
+
+	_, err = DialEthMultiClientForChain(137, []string{srv.URL}, nil, time.Second, time.Second)
+	assert.ErrorIs(t, err, ErrChainIDMismatch)
+}
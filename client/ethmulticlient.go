@@ -21,9 +21,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"math/big"
+	"net"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
@@ -46,9 +51,32 @@ type EthMultiClient struct {
 	// when a certain client goes down.
 	notifyDown *safeChannel
 
+	// maxBlockLag is the number of blocks a client can be behind the
+	// highest block seen before its results are considered stale.
+	maxBlockLag  uint64
+	highestBlock atomic.Uint64
+
+	// strategy selects the client serving read calls first.
+	strategy  BalanceStrategy
+	next      atomic.Uint64
+	inFlight  sync.Map
+	unhealthy map[string]bool
+
 	mu sync.Mutex
 }
 
+// BalanceStrategy selects which endpoint of an `EthMultiClient` serves a read call first.
+type BalanceStrategy string
+
+const (
+	// BalanceFailover sends every call to the first endpoint and only fails over to the next ones.
+	BalanceFailover BalanceStrategy = "failover"
+	// BalanceRoundRobin spreads read calls over the healthy endpoints in turns.
+	BalanceRoundRobin BalanceStrategy = "round-robin"
+	// BalanceLeastLoaded sends read calls to the healthy endpoint with the fewest calls in flight.
+	BalanceLeastLoaded BalanceStrategy = "least-loaded"
+)
+
 type Notification struct {
 	Address string
 	Error   error
@@ -62,8 +90,19 @@ type safeChannel struct {
 var (
 	ErrClientNoConnection    = errors.New("failed to connect to the eth client with a given address")
 	ErrClientTooManyRequests = errors.New("failed to call eth client, too many requests")
+	ErrClientStale           = errors.New("eth client returned a stale result")
 )
 
+// connectionErrors are messages of errors returned by unreachable or failing endpoints.
+var connectionErrors = []string{
+	"connection refused",
+	"connection reset",
+	"no such host",
+	"502 bad gateway",
+	"503 service unavailable",
+	"504 gateway timeout",
+}
+
 // NewEthMultiClient creates a new multi clients eth client.
 func NewEthMultiClient(defaulTimeout time.Duration, clients []AddressableEthClientGetter) (*EthMultiClient, error) {
 	if len(clients) == 0 {
@@ -79,6 +118,67 @@ func NewEthMultiClient(defaulTimeout time.Duration, clients []AddressableEthClie
 	}, nil
 }
 
+// DialEthMultiClient connects to the given RPC endpoints of a chain and returns a client
+// which calls them in the given order, failing over to the next endpoint on connection
+// errors, timeouts, rate limits and stale results.
+func DialEthMultiClient(endpoints []string, connectTimeout, callTimeout time.Duration) (*EthMultiClient, error) {
+	return DialEthMultiClientWithAuth(endpoints, nil, connectTimeout, callTimeout)
+}
+
+// DialEthMultiClientWithAuth connects to the given RPC endpoints of a chain like `DialEthMultiClient`,
+// authenticating to every endpoint with the credentials given for its address, if any.
+func DialEthMultiClientWithAuth(endpoints []string, auth map[string]EndpointAuth, connectTimeout, callTimeout time.Duration) (*EthMultiClient, error) {
+	return DialEthMultiClientForChain(0, endpoints, auth, connectTimeout, callTimeout)
+}
+
+// DialEthMultiClientForChain connects to the given RPC endpoints like `DialEthMultiClientWithAuth`
+// and verifies every endpoint serves the given chain, see `NewReconnectableEthClientForChain`.
+func DialEthMultiClientForChain(chainID int64, endpoints []string, auth map[string]EndpointAuth, connectTimeout, callTimeout time.Duration) (*EthMultiClient, error) {
+	getters := make([]AddressableEthClientGetter, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		ec, err := NewReconnectableEthClientForChain(endpoint, chainID, connectTimeout, auth[endpoint])
+		if err != nil {
+			for _, g := range getters {
+				g.Client().Close()
+			}
+			return nil, fmt.Errorf("failed to connect to %q: %w", endpoint, err)
+		}
+		getters = append(getters, ec)
+	}
+
+	return NewEthMultiClient(callTimeout, getters)
+}
+
+// SetBalanceStrategy sets how read calls are spread over the endpoints, `BalanceFailover` by default.
+// Sends and pending state queries are always pinned to the first endpoint which answers them,
+// so the nonce views of the senders stay consistent.
+//
+// This method is not thread safe and should be called before the client is used.
+func (c *EthMultiClient) SetBalanceStrategy(strategy BalanceStrategy) {
+	c.strategy = strategy
+}
+
+// SetUnhealthy marks the endpoints with the given addresses unhealthy, replacing the previous ones.
+// Unhealthy endpoints don't get read calls spread to them, they are only failed over to.
+func (c *EthMultiClient) SetUnhealthy(addresses []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.unhealthy = make(map[string]bool, len(addresses))
+	for _, a := range addresses {
+		c.unhealthy[a] = true
+	}
+}
+
+// SetMaxBlockLag makes the client fail over to the next endpoint if the latest block
+// an endpoint returns is more than the given number of blocks behind the highest block
+// returned by any of the endpoints. Zero disables the check.
+//
+// This method is not thread safe and should be called before the client is used.
+func (c *EthMultiClient) SetMaxBlockLag(lag uint64) {
+	c.maxBlockLag = lag
+}
+
 // Client implements the EthClientGetter interface and returns itself as a EtherClient.
 func (c *EthMultiClient) Client() EtherClient {
 	return c
@@ -163,11 +263,14 @@ func (c *EthMultiClient) BlockByNumber(ctx context.Context, number *big.Int) (*t
 // BlockNumber returns the most recent block number
 func (c *EthMultiClient) BlockNumber(ctx context.Context) (uint64, error) {
 	var res uint64
-	return res, c.doWithMultipleClients(ctx, func(ctx context.Context, c EtherClient) error {
-		val, err := c.BlockNumber(ctx)
+	return res, c.doWithMultipleClients(ctx, func(ctx context.Context, ec EtherClient) error {
+		val, err := ec.BlockNumber(ctx)
 		if err != nil {
 			return err
 		}
+		if err := c.checkFresh(val); err != nil {
+			return err
+		}
 
 		res = val
 		return nil
@@ -192,11 +295,16 @@ func (c *EthMultiClient) HeaderByHash(ctx context.Context, hash common.Hash) (*t
 // nil, the latest known header is returned.
 func (c *EthMultiClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
 	var res *types.Header
-	return res, c.doWithMultipleClients(ctx, func(ctx context.Context, c EtherClient) error {
-		val, err := c.HeaderByNumber(ctx, number)
+	return res, c.doWithMultipleClients(ctx, func(ctx context.Context, ec EtherClient) error {
+		val, err := ec.HeaderByNumber(ctx, number)
 		if err != nil {
 			return err
 		}
+		if number == nil && val != nil && val.Number != nil {
+			if err := c.checkFresh(val.Number.Uint64()); err != nil {
+				return err
+			}
+		}
 
 		res = val
 		return nil
@@ -416,7 +524,7 @@ func (c *EthMultiClient) SubscribeFilterLogs(ctx context.Context, q ethereum.Fil
 // PendingBalanceAt returns the wei balance of the given account in the pending state.
 func (c *EthMultiClient) PendingBalanceAt(ctx context.Context, account common.Address) (*big.Int, error) {
 	var res *big.Int
-	return res, c.doWithMultipleClients(ctx, func(ctx context.Context, c EtherClient) error {
+	return res, c.doWithPinnedClients(ctx, func(ctx context.Context, c EtherClient) error {
 		val, err := c.PendingBalanceAt(ctx, account)
 		if err != nil {
 			return err
@@ -430,7 +538,7 @@ func (c *EthMultiClient) PendingBalanceAt(ctx context.Context, account common.Ad
 // PendingStorageAt returns the value of key in the contract storage of the given account in the pending state.
 func (c *EthMultiClient) PendingStorageAt(ctx context.Context, account common.Address, key common.Hash) ([]byte, error) {
 	var res []byte
-	return res, c.doWithMultipleClients(ctx, func(ctx context.Context, c EtherClient) error {
+	return res, c.doWithPinnedClients(ctx, func(ctx context.Context, c EtherClient) error {
 		val, err := c.PendingStorageAt(ctx, account, key)
 		if err != nil {
 			return err
@@ -444,7 +552,7 @@ func (c *EthMultiClient) PendingStorageAt(ctx context.Context, account common.Ad
 // PendingCodeAt returns the contract code of the given account in the pending state.
 func (c *EthMultiClient) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
 	var res []byte
-	return res, c.doWithMultipleClients(ctx, func(ctx context.Context, c EtherClient) error {
+	return res, c.doWithPinnedClients(ctx, func(ctx context.Context, c EtherClient) error {
 		val, err := c.PendingCodeAt(ctx, account)
 		if err != nil {
 			return err
@@ -459,7 +567,7 @@ func (c *EthMultiClient) PendingCodeAt(ctx context.Context, account common.Addre
 // This is the nonce that should be used for the next transaction.
 func (c *EthMultiClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
 	var res uint64
-	return res, c.doWithMultipleClients(ctx, func(ctx context.Context, c EtherClient) error {
+	return res, c.doWithPinnedClients(ctx, func(ctx context.Context, c EtherClient) error {
 		val, err := c.PendingNonceAt(ctx, account)
 		if err != nil {
 			return err
@@ -473,7 +581,7 @@ func (c *EthMultiClient) PendingNonceAt(ctx context.Context, account common.Addr
 // PendingTransactionCount returns the total number of transactions in the pending state.
 func (c *EthMultiClient) PendingTransactionCount(ctx context.Context) (uint, error) {
 	var res uint
-	return res, c.doWithMultipleClients(ctx, func(ctx context.Context, c EtherClient) error {
+	return res, c.doWithPinnedClients(ctx, func(ctx context.Context, c EtherClient) error {
 		val, err := c.PendingTransactionCount(ctx)
 		if err != nil {
 			return err
@@ -507,7 +615,7 @@ func (c *EthMultiClient) CallContract(ctx context.Context, msg ethereum.CallMsg,
 // The state seen by the contract call is the pending state.
 func (c *EthMultiClient) PendingCallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
 	var res []byte
-	return res, c.doWithMultipleClients(ctx, func(ctx context.Context, c EtherClient) error {
+	return res, c.doWithPinnedClients(ctx, func(ctx context.Context, c EtherClient) error {
 		val, err := c.PendingCallContract(ctx, msg)
 		if err != nil {
 			return err
@@ -565,12 +673,27 @@ func (c *EthMultiClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error)
 	})
 }
 
+// FeeHistory retrieves the fee market history of the given number of blocks up to the last block,
+// with the priority fees paid at the given percentiles of each block.
+func (c *EthMultiClient) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	var res *ethereum.FeeHistory
+	return res, c.doWithMultipleClients(ctx, func(ctx context.Context, c EtherClient) error {
+		val, err := c.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+		if err != nil {
+			return err
+		}
+
+		res = val
+		return nil
+	})
+}
+
 // SendTransaction injects a signed transaction into the pending pool for execution.
 //
 // If the transaction was a contract creation use the TransactionReceipt method to get the
 // contract address after the transaction has been mined.
 func (c *EthMultiClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
-	return c.doWithMultipleClients(ctx, func(ctx context.Context, c EtherClient) error {
+	return c.doWithPinnedClients(ctx, func(ctx context.Context, c EtherClient) error {
 		return c.SendTransaction(ctx, tx)
 	})
 }
@@ -618,6 +741,14 @@ func (c *EthMultiClient) CurrentClientOrder() []string {
 	return result
 }
 
+// endpoints returns the clients in their current order.
+func (c *EthMultiClient) endpoints() []AddressableEthClientGetter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]AddressableEthClientGetter(nil), c.clients...)
+}
+
 // CallSpecificClient allows to call a spefific client by a given address.
 func (c *EthMultiClient) CallSpecificClient(address string, call func(c EtherClient) error) error {
 	c.mu.Lock()
@@ -638,23 +769,32 @@ func (c *EthMultiClient) CallSpecificClient(address string, call func(c EtherCli
 	return call(client)
 }
 
-// doWithMultipleClientsCtx will execute a given function with all clients until one succeds.
+// doWithMultipleClients will execute a given function with all clients until one succeds,
+// starting with the client selected by the balance strategy.
 //
 // If parent context is cancel or receives a timeout, all calls will be also cancels and the
 // function will return.
 func (c *EthMultiClient) doWithMultipleClients(ctx context.Context, do func(ctx context.Context, c EtherClient) error) error {
-	return c.doWithAllClients(ctx, do, true)
+	return c.doWithClients(ctx, c.readOrder(), do, true)
+}
+
+// doWithPinnedClients will execute a given function with all clients until one succeds
+// in their current order regardless of the balance strategy.
+func (c *EthMultiClient) doWithPinnedClients(ctx context.Context, do func(ctx context.Context, c EtherClient) error) error {
+	return c.doWithClients(ctx, c.endpoints(), do, true)
 }
 
 // doWithAllClients will execute a given function with all clients with option to return on first successful call.
+func (c *EthMultiClient) doWithAllClients(ctx context.Context, do func(ctx context.Context, c EtherClient) error, returnOnFirstSuccess bool) error {
+	return c.doWithClients(ctx, c.endpoints(), do, returnOnFirstSuccess)
+}
+
+// doWithClients will execute a given function with the given clients with option to return on first successful call.
 //
 // If parent context is cancel or receives a timeout, all calls will be also cancels and the
 // function will return.
-func (c *EthMultiClient) doWithAllClients(ctx context.Context, do func(ctx context.Context, c EtherClient) error, returnOnFirstSuccess bool) error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	ctxs, cancel := c.produceCtxs(ctx)
+func (c *EthMultiClient) doWithClients(ctx context.Context, clients []AddressableEthClientGetter, do func(ctx context.Context, c EtherClient) error, returnOnFirstSuccess bool) error {
+	ctxs, cancel := c.produceCtxs(ctx, len(clients))
 	defer cancel()
 
 	done := make(chan struct{})
@@ -669,7 +809,8 @@ func (c *EthMultiClient) doWithAllClients(ctx context.Context, do func(ctx conte
 		}
 	}()
 
-	for i, cl := range c.clients {
+	var lastErr error
+	for i, cl := range clients {
 		select {
 		case <-ctx.Done():
 			return context.DeadlineExceeded
@@ -679,9 +820,10 @@ func (c *EthMultiClient) doWithAllClients(ctx context.Context, do func(ctx conte
 				return ctx.Err()
 			}
 
-			err := do(childCtx, cl.Client())
+			err := c.call(childCtx, cl, do)
 			if err != nil {
 				if c.tryNotify(ctx, cl.Address(), err) {
+					lastErr = err
 					continue
 				}
 				return err
@@ -692,9 +834,104 @@ func (c *EthMultiClient) doWithAllClients(ctx context.Context, do func(ctx conte
 			}
 		}
 	}
+
+	// Every client failed.
+	if returnOnFirstSuccess {
+		return lastErr
+	}
+	return nil
+}
+
+// call executes the function with the client counting it as in flight meanwhile.
+func (c *EthMultiClient) call(ctx context.Context, cl AddressableEthClientGetter, do func(ctx context.Context, c EtherClient) error) error {
+	load := c.load(cl.Address())
+	load.Add(1)
+	defer load.Add(-1)
+
+	return do(ctx, cl.Client())
+}
+
+func (c *EthMultiClient) load(address string) *atomic.Int64 {
+	v, _ := c.inFlight.LoadOrStore(address, new(atomic.Int64))
+	return v.(*atomic.Int64)
+}
+
+// readOrder returns the clients in the order to try them in for a read call. The healthy clients
+// come first, starting with the one selected by the balance strategy, the unhealthy ones last.
+func (c *EthMultiClient) readOrder() []AddressableEthClientGetter {
+	c.mu.Lock()
+	clients := append([]AddressableEthClientGetter(nil), c.clients...)
+	unhealthy := c.unhealthy
+	c.mu.Unlock()
+
+	if c.strategy == "" || c.strategy == BalanceFailover {
+		return clients
+	}
+
+	healthy := make([]AddressableEthClientGetter, 0, len(clients))
+	var flagged []AddressableEthClientGetter
+	for _, cl := range clients {
+		if unhealthy[cl.Address()] {
+			flagged = append(flagged, cl)
+		} else {
+			healthy = append(healthy, cl)
+		}
+	}
+	if len(healthy) == 0 {
+		return clients
+	}
+
+	switch c.strategy {
+	case BalanceRoundRobin:
+		start := int((c.next.Add(1) - 1) % uint64(len(healthy)))
+		healthy = append(healthy[start:], healthy[:start]...)
+	case BalanceLeastLoaded:
+		loads := make(map[string]int64, len(healthy))
+		for _, cl := range healthy {
+			loads[cl.Address()] = c.load(cl.Address()).Load()
+		}
+		sort.SliceStable(healthy, func(i, j int) bool {
+			return loads[healthy[i].Address()] < loads[healthy[j].Address()]
+		})
+	}
+	return append(healthy, flagged...)
+}
+
+// checkFresh records the latest block returned by a client and
+// returns `ErrClientStale` if it lags too far behind the highest one.
+func (c *EthMultiClient) checkFresh(block uint64) error {
+	for {
+		highest := c.highestBlock.Load()
+		if block <= highest {
+			break
+		}
+		if c.highestBlock.CompareAndSwap(highest, block) {
+			return nil
+		}
+	}
+
+	highest := c.highestBlock.Load()
+	if c.maxBlockLag > 0 && highest-block > c.maxBlockLag {
+		return fmt.Errorf("%w: latest block %d is behind %d", ErrClientStale, block, highest)
+	}
 	return nil
 }
 
+func isConnectionError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, m := range connectionErrors {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *EthMultiClient) tryNotify(parentCtx context.Context, clientAddress string, err error) bool {
 	switch {
 	case errors.Is(err, context.DeadlineExceeded):
@@ -702,8 +939,12 @@ func (c *EthMultiClient) tryNotify(parentCtx context.Context, clientAddress stri
 		if parentCtx.Err() == nil {
 			c.notify(clientAddress, ErrClientNoConnection)
 		}
-	case strings.Contains(strings.ToLower(err.Error()), "429 too many requests"):
+	case errors.Is(ClassifyError(err), ErrRateLimited):
 		c.notify(clientAddress, ErrClientTooManyRequests)
+	case errors.Is(err, ErrClientStale):
+		c.notify(clientAddress, ErrClientStale)
+	case isConnectionError(err):
+		c.notify(clientAddress, ErrClientNoConnection)
 	default:
 		return false
 	}
@@ -744,10 +985,10 @@ func (c *EthMultiClient) closeNotify() {
 }
 
 // produceCtxs produces contexts for each client.
-func (c *EthMultiClient) produceCtxs(ctx context.Context) ([]context.Context, func()) {
-	singleCtxDuration := c.childTimeout(ctx)
-	ctxs := make([]context.Context, len(c.clients))
-	cancels := make([]func(), len(c.clients))
+func (c *EthMultiClient) produceCtxs(ctx context.Context, n int) ([]context.Context, func()) {
+	singleCtxDuration := c.childTimeout(ctx, n)
+	ctxs := make([]context.Context, n)
+	cancels := make([]func(), n)
 
 	for i := range ctxs {
 		ctxs[i], cancels[i] = context.WithTimeout(context.Background(), singleCtxDuration*time.Duration((i+1)))
@@ -766,12 +1007,12 @@ func (c *EthMultiClient) produceCtxs(ctx context.Context) ([]context.Context, fu
 //
 // It rounds the extracted duration down to miliseconds elimiating
 // the posiblity to get context duration like: 1.99999999999s
-func (c *EthMultiClient) childTimeout(ctx context.Context) time.Duration {
+func (c *EthMultiClient) childTimeout(ctx context.Context, n int) time.Duration {
 	deadline, ok := ctx.Deadline()
 	if !ok {
 		deadline = time.Now().Add(c.timeout)
 	}
-	res := time.Until(deadline) / time.Duration(len(c.clients))
+	res := time.Until(deadline) / time.Duration(n)
 
 	// avoid errors if time is actually less
 	if res > time.Millisecond {
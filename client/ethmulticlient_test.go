@@ -19,6 +19,7 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"sync"
@@ -405,6 +406,69 @@ func Test_EthMultiClient(t *testing.T) {
 		}, time.Second/2, time.Second/200)
 	})
 
+	t.Run("two clients passed first is unreachable, second is used", func(t *testing.T) {
+		cl := &mocks.EtherClientMock{
+			ChainIDFunc: func(ctx context.Context) (*big.Int, error) {
+				return nil, errors.New("dial tcp 127.0.0.1:8545: connect: connection refused")
+			},
+		}
+		cl2 := &mocks.EtherClientMock{
+			ChainIDFunc: func(ctx context.Context) (*big.Int, error) {
+				return big.NewInt(2), nil
+			},
+		}
+		getter := NewDefaultAddressableEthClientGetter("first", cl)
+		getter2 := NewDefaultAddressableEthClientGetter("second", cl2)
+
+		multi, err := NewEthMultiClient(time.Second, []AddressableEthClientGetter{getter, getter2})
+		assert.NoError(t, err)
+
+		chainID, err := multi.ChainID(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(2), chainID)
+
+		// Fails if every client is unreachable.
+		single, err := NewEthMultiClient(time.Second, []AddressableEthClientGetter{getter})
+		assert.NoError(t, err)
+		_, err = single.ChainID(context.Background())
+		assert.Error(t, err)
+	})
+
+	t.Run("stale client is skipped", func(t *testing.T) {
+		cl := &mocks.EtherClientMock{
+			BlockNumberFunc: func(ctx context.Context) (uint64, error) {
+				return 100, nil
+			},
+		}
+		cl2 := &mocks.EtherClientMock{
+			BlockNumberFunc: func(ctx context.Context) (uint64, error) {
+				return 120, nil
+			},
+		}
+		getter := NewDefaultAddressableEthClientGetter("first", cl)
+		getter2 := NewDefaultAddressableEthClientGetter("second", cl2)
+
+		multi, err := NewEthMultiClient(time.Second, []AddressableEthClientGetter{getter, getter2})
+		assert.NoError(t, err)
+		multi.SetMaxBlockLag(10)
+
+		block, err := multi.BlockNumber(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(100), block)
+
+		assert.NoError(t, multi.ReorderClients([]string{"second", "first"}))
+		block, err = multi.BlockNumber(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(120), block)
+
+		// The first client stopped following the chain.
+		assert.NoError(t, multi.ReorderClients([]string{"first", "second"}))
+		block, err = multi.BlockNumber(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(120), block)
+		assert.Len(t, cl2.BlockNumberCalls(), 2)
+	})
+
 	t.Run("two clients, callin specific one works", func(t *testing.T) {
 		cl := &mocks.EtherClientMock{
 			BlockNumberFunc: func(ctx context.Context) (uint64, error) {
@@ -613,6 +677,73 @@ func getMockClientWithFunctionCallCounter(counterMap map[string]int, mapLock *sy
 	}
 }
 
+func Test_EthMultiClientBalancing(t *testing.T) {
+	var mu sync.Mutex
+	calls := make(map[string][]string)
+	release := make(chan struct{})
+	endpoint := func(addr string) AddressableEthClientGetter {
+		record := func(method string) {
+			mu.Lock()
+			defer mu.Unlock()
+			calls[method] = append(calls[method], addr)
+		}
+		return NewDefaultAddressableEthClientGetter(addr, &mocks.EtherClientMock{
+			ChainIDFunc: func(_ context.Context) (*big.Int, error) {
+				record("ChainID")
+				return big.NewInt(1), nil
+			},
+			BlockNumberFunc: func(_ context.Context) (uint64, error) {
+				record("BlockNumber")
+				<-release
+				return 1, nil
+			},
+			PendingNonceAtFunc: func(_ context.Context, _ common.Address) (uint64, error) {
+				record("PendingNonceAt")
+				return 0, nil
+			},
+			SendTransactionFunc: func(_ context.Context, _ *types.Transaction) error {
+				record("SendTransaction")
+				return nil
+			},
+		})
+	}
+	mc, err := NewEthMultiClient(time.Second, []AddressableEthClientGetter{endpoint("a"), endpoint("b"), endpoint("c")})
+	assert.NoError(t, err)
+	mc.SetBalanceStrategy(BalanceRoundRobin)
+	mc.SetUnhealthy([]string{"c"})
+
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		_, err := mc.ChainID(ctx)
+		assert.NoError(t, err)
+		_, err = mc.PendingNonceAt(ctx, common.Address{})
+		assert.NoError(t, err)
+		assert.NoError(t, mc.SendTransaction(ctx, types.NewTx(&types.LegacyTx{})))
+	}
+	assert.Equal(t, []string{"a", "b", "a", "b"}, calls["ChainID"], "unhealthy endpoints are skipped")
+	assert.Equal(t, []string{"a", "a", "a", "a"}, calls["PendingNonceAt"])
+	assert.Equal(t, []string{"a", "a", "a", "a"}, calls["SendTransaction"], "sends are pinned")
+
+	mc.SetBalanceStrategy(BalanceLeastLoaded)
+	mc.SetUnhealthy(nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = mc.BlockNumber(ctx)
+		}()
+		assert.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(calls["BlockNumber"]) == i+1
+		}, time.Second, time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, calls["BlockNumber"], "calls go to the least loaded endpoint")
+}
+
 func lockAndIncrement(callsCounter map[string]int, lock *sync.Mutex, key string) {
 	lock.Lock()
 	defer lock.Unlock()
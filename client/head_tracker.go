@@ -0,0 +1,203 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DefaultHeadTrackerDepth is the default number of recent blocks tracked to detect reorgs.
+const DefaultHeadTrackerDepth = 128
+
+// HeadTrackerClient is used by the head tracker, `EtherClient` satisfies it.
+type HeadTrackerClient interface {
+	SubscriptionClient
+	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
+}
+
+// Reorg describes a chain reorganization detected by the head tracker.
+type Reorg struct {
+	// Depth is the number of previously seen blocks which are no longer canonical.
+	Depth uint64
+	// Ancestor is the number of the last block both chains have in common.
+	Ancestor uint64
+	// OldHead is the hash of the head before the reorg.
+	OldHead common.Hash
+	// NewHead is the head which replaced it.
+	NewHead *types.Header
+}
+
+// HeadTracker follows new heads and detects reorgs by a parent hash mismatch with the
+// previously seen blocks, so the users of blocks can avoid acting on orphaned ones.
+// Reorgs deeper than the tracked depth are reported with the tracked depth, while gaps
+// between heads longer than it reset the tracker without reporting anything.
+type HeadTracker struct {
+	client HeadTrackerClient
+	subs   *SubscriptionManager
+	depth  uint64
+
+	mu        sync.Mutex
+	hashes    map[uint64]common.Hash
+	head      *types.Header
+	tail      uint64
+	listeners []func(Reorg)
+
+	logFn func(error)
+}
+
+// NewHeadTracker returns a new head tracker which keeps track of the given number of recent blocks.
+func NewHeadTracker(client HeadTrackerClient, depth uint64, reconnectDelay time.Duration) *HeadTracker {
+	if depth == 0 {
+		depth = DefaultHeadTrackerDepth
+	}
+	return &HeadTracker{
+		client: client,
+		subs:   NewSubscriptionManager(client, reconnectDelay),
+		depth:  depth,
+		hashes: make(map[uint64]common.Hash),
+		logFn:  func(error) {},
+	}
+}
+
+// AttachLogger allows the caller to attach an optional logger
+// which will receive the errors of dropped subscriptions and header lookups.
+//
+// This method is not thread safe and should be called before `Run`.
+func (t *HeadTracker) AttachLogger(fn func(err error)) {
+	t.logFn = fn
+	t.subs.AttachLogger(fn)
+}
+
+// OnReorg registers a listener called with every detected reorg.
+func (t *HeadTracker) OnReorg(fn func(Reorg)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.listeners = append(t.listeners, fn)
+}
+
+// Head returns the latest head, nil until the first one is received.
+func (t *HeadTracker) Head() *types.Header {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.head
+}
+
+// Run follows new heads until the context is done.
+func (t *HeadTracker) Run(ctx context.Context) error {
+	heads := make(chan *types.Header)
+	done := make(chan error, 1)
+	go func() {
+		done <- t.subs.SubscribeNewHead(ctx, heads)
+	}()
+
+	for {
+		select {
+		case err := <-done:
+			return err
+		case h := <-heads:
+			if err := t.process(ctx, h); err != nil {
+				t.logFn(err)
+			}
+		}
+	}
+}
+
+func (t *HeadTracker) process(ctx context.Context, h *types.Header) error {
+	t.mu.Lock()
+	if hash, ok := t.hashes[h.Number.Uint64()]; ok && hash == h.Hash() {
+		t.mu.Unlock()
+		return nil
+	}
+	t.mu.Unlock()
+
+	// Walk back the new chain until it connects to a tracked block.
+	chain := []*types.Header{h}
+	for cur := h; cur.Number.Uint64() > 0; {
+		parent := cur.Number.Uint64() - 1
+		hash, known, tracked := t.tracked(parent)
+		if !tracked || (known && hash == cur.ParentHash) {
+			break
+		}
+		if uint64(len(chain)) > t.depth {
+			t.reset()
+			break
+		}
+
+		next, err := t.client.HeaderByHash(ctx, cur.ParentHash)
+		if err != nil {
+			return fmt.Errorf("failed to get the parent of block %s: %w", cur.Number, err)
+		}
+		chain = append(chain, next)
+		cur = next
+	}
+
+	t.mu.Lock()
+	var reorg *Reorg
+	ancestor := chain[len(chain)-1].Number.Uint64()
+	if ancestor > 0 {
+		ancestor--
+	}
+	if t.head != nil && t.head.Number.Uint64() > ancestor {
+		reorg = &Reorg{
+			Depth:    t.head.Number.Uint64() - ancestor,
+			Ancestor: ancestor,
+			OldHead:  t.head.Hash(),
+			NewHead:  h,
+		}
+		for n := ancestor + 1; n <= t.head.Number.Uint64(); n++ {
+			delete(t.hashes, n)
+		}
+	}
+
+	for _, b := range chain {
+		t.hashes[b.Number.Uint64()] = b.Hash()
+	}
+	if t.head == nil {
+		t.tail = chain[len(chain)-1].Number.Uint64()
+	}
+	t.head = h
+	if n := h.Number.Uint64(); n >= t.depth && t.tail <= n-t.depth {
+		t.tail = n - t.depth + 1
+		for old := range t.hashes {
+			if old < t.tail {
+				delete(t.hashes, old)
+			}
+		}
+	}
+	listeners := append([]func(Reorg){}, t.listeners...)
+	t.mu.Unlock()
+
+	if reorg != nil {
+		for _, fn := range listeners {
+			fn(*reorg)
+		}
+	}
+	return nil
+}
+
+// tracked returns the hash of the block if it is known and whether the block
+// is not older than the tracked blocks. Blocks past the head are never known.
+func (t *HeadTracker) tracked(number uint64) (hash common.Hash, known, tracked bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.head == nil || number < t.tail {
+		return common.Hash{}, false, false
+	}
+	hash, known = t.hashes[number]
+	return hash, known, true
+}
+
+func (t *HeadTracker) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.hashes = make(map[uint64]common.Hash)
+	t.head = nil
+}
@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ HeadTrackerClient = (EtherClient)(nil)
+
+type headTrackerClientMock struct {
+	*subscriptionClientMock
+	byHash map[common.Hash]*types.Header
+}
+
+func (m *headTrackerClientMock) HeaderByHash(_ context.Context, hash common.Hash) (*types.Header, error) {
+	if h, ok := m.byHash[hash]; ok {
+		return h, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+// extendChain returns the headers following the parent, the fork byte tells chains apart.
+func (m *headTrackerClientMock) extendChain(parent *types.Header, count int, fork byte) []*types.Header {
+	var headers []*types.Header
+	for i := 0; i < count; i++ {
+		h := &types.Header{Number: big.NewInt(0), Extra: []byte{fork}}
+		if parent != nil {
+			h.Number = new(big.Int).Add(parent.Number, big.NewInt(1))
+			h.ParentHash = parent.Hash()
+		}
+		m.byHash[h.Hash()] = h
+		headers = append(headers, h)
+		parent = h
+	}
+	return headers
+}
+
+func Test_HeadTrackerDetectsReorgs(t *testing.T) {
+	bc := &headTrackerClientMock{
+		subscriptionClientMock: &subscriptionClientMock{subs: make(chan *subscriptionMock, 1)},
+		byHash:                 make(map[common.Hash]*types.Header),
+	}
+	tracker := NewHeadTracker(bc, 0, time.Millisecond)
+	var reorgs []Reorg
+	tracker.OnReorg(func(r Reorg) {
+		reorgs = append(reorgs, r)
+	})
+
+	// Blocks 0 to 5, the node only announces some of them.
+	chain := bc.extendChain(nil, 6, 0)
+	for _, i := range []int{0, 1, 3, 5, 5} {
+		assert.NoError(t, tracker.process(context.Background(), chain[i]))
+	}
+	assert.Empty(t, reorgs)
+	assert.Equal(t, chain[5], tracker.Head())
+
+	// A fork of block 3 becomes canonical.
+	fork := bc.extendChain(chain[3], 3, 1)
+	assert.NoError(t, tracker.process(context.Background(), fork[2]))
+	assert.Equal(t, []Reorg{{Depth: 2, Ancestor: 3, OldHead: chain[5].Hash(), NewHead: fork[2]}}, reorgs)
+
+	// The head is replaced by a sibling.
+	sibling := bc.extendChain(fork[1], 1, 2)[0]
+	assert.NoError(t, tracker.process(context.Background(), sibling))
+	assert.Len(t, reorgs, 2)
+	assert.Equal(t, uint64(1), reorgs[1].Depth)
+	assert.Equal(t, uint64(5), reorgs[1].Ancestor)
+
+	orphan := &types.Header{Number: big.NewInt(8), ParentHash: common.HexToHash("0x1")}
+	assert.True(t, errors.Is(tracker.process(context.Background(), orphan), ethereum.NotFound))
+	assert.Equal(t, sibling, tracker.Head())
+}
+
+func Test_HeadTrackerRun(t *testing.T) {
+	bc := &headTrackerClientMock{
+		subscriptionClientMock: &subscriptionClientMock{subs: make(chan *subscriptionMock, 1)},
+		byHash:                 make(map[common.Hash]*types.Header),
+	}
+	chain := bc.extendChain(nil, 2, 0)
+	fork := bc.extendChain(chain[0], 1, 1)
+
+	tracker := NewHeadTracker(bc, 0, time.Millisecond)
+	reorgs := make(chan Reorg, 1)
+	tracker.OnReorg(func(r Reorg) {
+		reorgs <- r
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error)
+	go func() { done <- tracker.Run(ctx) }()
+
+	<-bc.subs
+	bc.headCh <- chain[1]
+	bc.headCh <- fork[0]
+	r := <-reorgs
+	assert.Equal(t, uint64(1), r.Depth)
+	assert.Equal(t, fork[0], r.NewHead)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
@@ -0,0 +1,182 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultHealthCheckTimeout is the default time an endpoint has to answer a health check.
+const DefaultHealthCheckTimeout = 5 * time.Second
+
+// EndpointHealth is the result of the last health check of an endpoint.
+type EndpointHealth struct {
+	Address string
+	// Block is the latest block of the endpoint.
+	Block uint64
+	// Lag is the number of blocks the endpoint is behind the highest one of its peers.
+	Lag uint64
+	// Syncing is true if the node reports it is still syncing.
+	Syncing bool
+	// Err is the error of the check, if the endpoint could not be reached.
+	Err     error
+	Healthy bool
+}
+
+// HealthChecker periodically compares the latest block of every endpoint of an `EthMultiClient`
+// against its peers and flags lagging, syncing and unreachable endpoints. Flagged endpoints are
+// moved behind the healthy ones, so calls go to a healthy endpoint first and only fail over to
+// the flagged ones. Healthy endpoints keep the order they were configured in and are the only
+// ones read calls are spread over with a balance strategy.
+type HealthChecker struct {
+	client  *EthMultiClient
+	order   []string
+	maxLag  uint64
+	timeout time.Duration
+
+	mu     sync.Mutex
+	status []EndpointHealth
+
+	logFn func(error)
+}
+
+// NewHealthChecker returns a new health checker flagging the endpoints of the client
+// more than maxLag blocks behind the highest block of their peers.
+func NewHealthChecker(client *EthMultiClient, maxLag uint64) *HealthChecker {
+	return &HealthChecker{
+		client:  client,
+		order:   client.CurrentClientOrder(),
+		maxLag:  maxLag,
+		timeout: DefaultHealthCheckTimeout,
+		logFn:   func(error) {},
+	}
+}
+
+// SetTimeout sets the time every endpoint has to answer a health check.
+//
+// This method is not thread safe and should be called before `Run`.
+func (h *HealthChecker) SetTimeout(timeout time.Duration) {
+	h.timeout = timeout
+}
+
+// AttachLogger allows the caller to attach an optional logger
+// which will receive the errors of unhealthy endpoints.
+//
+// This method is not thread safe and should be called before `Run`.
+func (h *HealthChecker) AttachLogger(fn func(err error)) {
+	h.logFn = fn
+}
+
+// Run checks the endpoints every interval until the context is done.
+func (h *HealthChecker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		h.Check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Status returns the results of the last check in the configured order of the endpoints.
+func (h *HealthChecker) Status() []EndpointHealth {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return append([]EndpointHealth(nil), h.status...)
+}
+
+// Check checks every endpoint once and reorders the endpoints of the client by their health.
+func (h *HealthChecker) Check(ctx context.Context) []EndpointHealth {
+	endpoints := h.client.endpoints()
+	byAddress := make(map[string]AddressableEthClientGetter, len(endpoints))
+	for _, e := range endpoints {
+		byAddress[e.Address()] = e
+	}
+
+	status := make([]EndpointHealth, len(h.order))
+	var wg sync.WaitGroup
+	for i, addr := range h.order {
+		status[i].Address = addr
+		e, ok := byAddress[addr]
+		if !ok {
+			status[i].Err = fmt.Errorf("endpoint %s is not used by the client", addr)
+			continue
+		}
+
+		wg.Add(1)
+		go func(s *EndpointHealth, ec EtherClient) {
+			defer wg.Done()
+			h.checkEndpoint(ctx, s, ec)
+		}(&status[i], e.Client())
+	}
+	wg.Wait()
+
+	var highest uint64
+	for _, s := range status {
+		if s.Err == nil && s.Block > highest {
+			highest = s.Block
+		}
+	}
+
+	healthy := make([]string, 0, len(status))
+	var flagged []string
+	for i := range status {
+		s := &status[i]
+		if s.Err == nil {
+			s.Lag = highest - s.Block
+		}
+		s.Healthy = s.Err == nil && !s.Syncing && s.Lag <= h.maxLag
+		switch {
+		case s.Err != nil:
+			h.logFn(fmt.Errorf("endpoint %s is unreachable: %w", s.Address, s.Err))
+		case s.Syncing:
+			h.logFn(fmt.Errorf("endpoint %s is syncing", s.Address))
+		case !s.Healthy:
+			h.logFn(fmt.Errorf("endpoint %s is %d blocks behind", s.Address, s.Lag))
+		}
+
+		if _, ok := byAddress[s.Address]; !ok {
+			continue
+		}
+		if s.Healthy {
+			healthy = append(healthy, s.Address)
+		} else {
+			flagged = append(flagged, s.Address)
+		}
+	}
+
+	if err := h.client.ReorderClients(append(healthy, flagged...)); err != nil {
+		h.logFn(fmt.Errorf("failed to reorder endpoints: %w", err))
+	}
+	h.client.SetUnhealthy(flagged)
+
+	h.mu.Lock()
+	h.status = status
+	h.mu.Unlock()
+	return append([]EndpointHealth(nil), status...)
+}
+
+func (h *HealthChecker) checkEndpoint(ctx context.Context, s *EndpointHealth, ec EtherClient) {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	block, err := ec.BlockNumber(ctx)
+	if err != nil {
+		s.Err = err
+		return
+	}
+	s.Block = block
+
+	progress, err := ec.SyncProgress(ctx)
+	if err != nil {
+		s.Err = err
+		return
+	}
+	s.Syncing = progress != nil
+}
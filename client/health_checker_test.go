@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/client/mocks"
+)
+
+func TestHealthChecker(t *testing.T) {
+	endpoint := func(addr string, block uint64, syncing bool, err error) AddressableEthClientGetter {
+		return NewDefaultAddressableEthClientGetter(addr, &mocks.EtherClientMock{
+			BlockNumberFunc: func(_ context.Context) (uint64, error) {
+				return block, err
+			},
+			SyncProgressFunc: func(_ context.Context) (*ethereum.SyncProgress, error) {
+				if syncing {
+					return &ethereum.SyncProgress{}, nil
+				}
+				return nil, nil
+			},
+		})
+	}
+	mc, err := NewEthMultiClient(time.Second, []AddressableEthClientGetter{
+		endpoint("lagging", 90, false, nil),
+		endpoint("down", 0, false, errors.New("connection refused")),
+		endpoint("syncing", 100, true, nil),
+		endpoint("first", 99, false, nil),
+		endpoint("second", 100, false, nil),
+	})
+	assert.NoError(t, err)
+
+	h := NewHealthChecker(mc, 2)
+	status := h.Check(context.Background())
+	assert.Equal(t, status, h.Status())
+	assert.Equal(t, uint64(10), status[0].Lag)
+	assert.False(t, status[0].Healthy)
+	assert.Error(t, status[1].Err)
+	assert.True(t, status[2].Syncing)
+	assert.True(t, status[3].Healthy)
+	assert.True(t, status[4].Healthy)
+	assert.Equal(t, []string{"first", "second", "lagging", "down", "syncing"}, mc.CurrentClientOrder())
+	assert.Equal(t, map[string]bool{"lagging": true, "down": true, "syncing": true}, mc.unhealthy)
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"time"
+)
+
+// ClientMetrics receives the RPC requests of an instrumented client for metric reporting.
+type ClientMetrics interface {
+	// RequestStarted is called before every request of the JSON-RPC method to the named endpoint.
+	RequestStarted(endpoint, method string)
+	// RequestFinished is called after every request with its latency and the error on failure.
+	RequestFinished(endpoint, method string, latency time.Duration, err error)
+}
+
+type clientMetricsNoop struct{}
+
+func (c *clientMetricsNoop) RequestStarted(_, _ string) {}
+
+func (c *clientMetricsNoop) RequestFinished(_, _ string, _ time.Duration, _ error) {}
+
+// InstrumentedClient wraps the eth client used by the `Blockchain`
+// and reports every RPC request to the metrics.
+type InstrumentedClient struct {
+	name    string
+	next    EthClientGetter
+	metrics ClientMetrics
+}
+
+// NewInstrumentedClient returns a new instrumented client reporting under the given endpoint name.
+// A nil metrics reporter reports nothing.
+func NewInstrumentedClient(name string, next EthClientGetter, metrics ClientMetrics) *InstrumentedClient {
+	if metrics == nil {
+		metrics = &clientMetricsNoop{}
+	}
+	return &InstrumentedClient{
+		name:    name,
+		next:    next,
+		metrics: metrics,
+	}
+}
+
+// Client returns the wrapped client which reports its requests.
+func (i *InstrumentedClient) Client() EtherClient {
+	return &interceptedClient{EtherClient: i.next.Client(), intercept: i.intercept}
+}
+
+func (i *InstrumentedClient) intercept(ctx context.Context, method string, call func(context.Context) error) error {
+	start := time.Now()
+	i.metrics.RequestStarted(i.name, method)
+	err := call(ctx)
+	i.metrics.RequestFinished(i.name, method, time.Since(start), err)
+	return err
+}
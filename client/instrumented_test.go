@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/client/mocks"
+)
+
+type clientMetricsMock struct {
+	started  []string
+	finished []string
+	errs     []error
+}
+
+func (m *clientMetricsMock) RequestStarted(_, method string) {
+	m.started = append(m.started, method)
+}
+
+func (m *clientMetricsMock) RequestFinished(_, method string, _ time.Duration, err error) {
+	m.finished = append(m.finished, method)
+	m.errs = append(m.errs, err)
+}
+
+func newInstrumentedClientMock() *mocks.EtherClientMock {
+	return &mocks.EtherClientMock{
+		BalanceAtFunc: func(_ context.Context, _ common.Address, _ *big.Int) (*big.Int, error) {
+			return big.NewInt(1), nil
+		},
+		TransactionReceiptFunc: func(_ context.Context, _ common.Hash) (*types.Receipt, error) {
+			return nil, ethereum.NotFound
+		},
+		SendTransactionFunc: func(_ context.Context, _ *types.Transaction) error {
+			return errors.New("nonce too low")
+		},
+	}
+}
+
+func TestInstrumentedClient(t *testing.T) {
+	m := &clientMetricsMock{}
+	bc := NewBlockchain(NewInstrumentedClient("infura", NewDefaultEthClientGetter(newInstrumentedClientMock()), m), time.Second)
+
+	balance, err := bc.GetEthBalance(common.Address{})
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(1), balance)
+	assert.Error(t, bc.SendTransaction(types.NewTx(&types.LegacyTx{})))
+
+	assert.Equal(t, []string{"eth_getBalance", "eth_sendRawTransaction"}, m.started)
+	assert.Equal(t, m.started, m.finished)
+	assert.NoError(t, m.errs[0])
+	assert.Error(t, m.errs[1])
+
+	_, err = NewInstrumentedClient("noop", NewDefaultEthClientGetter(newInstrumentedClientMock()), nil).Client().BalanceAt(context.Background(), common.Address{}, nil)
+	assert.NoError(t, err)
+}
+
+func TestPrometheusMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := NewPrometheusMetrics("payments", reg)
+	assert.NoError(t, err)
+
+	ec := NewInstrumentedClient("infura", NewDefaultEthClientGetter(newInstrumentedClientMock()), m).Client()
+	_, err = ec.BalanceAt(context.Background(), common.Address{}, nil)
+	assert.NoError(t, err)
+	_, err = ec.TransactionReceipt(context.Background(), common.Hash{})
+	assert.ErrorIs(t, err, ethereum.NotFound)
+	assert.Error(t, ec.SendTransaction(context.Background(), types.NewTx(&types.LegacyTx{})))
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.requests.WithLabelValues("infura", "eth_getBalance")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.failures.WithLabelValues("infura", "eth_getTransactionReceipt")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.failures.WithLabelValues("infura", "eth_sendRawTransaction")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.inFlight.WithLabelValues("infura", "eth_getBalance")))
+
+	_, err = NewPrometheusMetrics("payments", reg)
+	assert.Error(t, err, "metrics are registered only once")
+}
@@ -0,0 +1,271 @@
+package client
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// interceptedClient passes every request of the eth client with its
+// JSON-RPC method name through the intercept func of a wrapper.
+type interceptedClient struct {
+	EtherClient
+	intercept func(ctx context.Context, method string, call func(context.Context) error) error
+}
+
+func (c *interceptedClient) ChainID(ctx context.Context) (res *big.Int, err error) {
+	err = c.intercept(ctx, "eth_chainId", func(ctx context.Context) error {
+		res, err = c.EtherClient.ChainID(ctx)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) BlockByHash(ctx context.Context, hash common.Hash) (res *types.Block, err error) {
+	err = c.intercept(ctx, "eth_getBlockByHash", func(ctx context.Context) error {
+		res, err = c.EtherClient.BlockByHash(ctx, hash)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) BlockByNumber(ctx context.Context, number *big.Int) (res *types.Block, err error) {
+	err = c.intercept(ctx, "eth_getBlockByNumber", func(ctx context.Context) error {
+		res, err = c.EtherClient.BlockByNumber(ctx, number)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) BlockNumber(ctx context.Context) (res uint64, err error) {
+	err = c.intercept(ctx, "eth_blockNumber", func(ctx context.Context) error {
+		res, err = c.EtherClient.BlockNumber(ctx)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) HeaderByHash(ctx context.Context, hash common.Hash) (res *types.Header, err error) {
+	err = c.intercept(ctx, "eth_getBlockByHash", func(ctx context.Context) error {
+		res, err = c.EtherClient.HeaderByHash(ctx, hash)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) HeaderByNumber(ctx context.Context, number *big.Int) (res *types.Header, err error) {
+	err = c.intercept(ctx, "eth_getBlockByNumber", func(ctx context.Context) error {
+		res, err = c.EtherClient.HeaderByNumber(ctx, number)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) TransactionByHash(ctx context.Context, hash common.Hash) (res *types.Transaction, pending bool, err error) {
+	err = c.intercept(ctx, "eth_getTransactionByHash", func(ctx context.Context) error {
+		res, pending, err = c.EtherClient.TransactionByHash(ctx, hash)
+		return err
+	})
+	return res, pending, err
+}
+
+func (c *interceptedClient) TransactionSender(ctx context.Context, tx *types.Transaction, block common.Hash, index uint) (res common.Address, err error) {
+	err = c.intercept(ctx, "eth_getTransactionByBlockHashAndIndex", func(ctx context.Context) error {
+		res, err = c.EtherClient.TransactionSender(ctx, tx, block, index)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) TransactionCount(ctx context.Context, blockHash common.Hash) (res uint, err error) {
+	err = c.intercept(ctx, "eth_getBlockTransactionCountByHash", func(ctx context.Context) error {
+		res, err = c.EtherClient.TransactionCount(ctx, blockHash)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) TransactionInBlock(ctx context.Context, blockHash common.Hash, index uint) (res *types.Transaction, err error) {
+	err = c.intercept(ctx, "eth_getTransactionByBlockHashAndIndex", func(ctx context.Context) error {
+		res, err = c.EtherClient.TransactionInBlock(ctx, blockHash, index)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (res *types.Receipt, err error) {
+	err = c.intercept(ctx, "eth_getTransactionReceipt", func(ctx context.Context) error {
+		res, err = c.EtherClient.TransactionReceipt(ctx, txHash)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) SyncProgress(ctx context.Context) (res *ethereum.SyncProgress, err error) {
+	err = c.intercept(ctx, "eth_syncing", func(ctx context.Context) error {
+		res, err = c.EtherClient.SyncProgress(ctx)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (res ethereum.Subscription, err error) {
+	err = c.intercept(ctx, "eth_subscribe", func(ctx context.Context) error {
+		res, err = c.EtherClient.SubscribeNewHead(ctx, ch)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) NetworkID(ctx context.Context) (res *big.Int, err error) {
+	err = c.intercept(ctx, "net_version", func(ctx context.Context) error {
+		res, err = c.EtherClient.NetworkID(ctx)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (res *big.Int, err error) {
+	err = c.intercept(ctx, "eth_getBalance", func(ctx context.Context) error {
+		res, err = c.EtherClient.BalanceAt(ctx, account, blockNumber)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) (res []byte, err error) {
+	err = c.intercept(ctx, "eth_getStorageAt", func(ctx context.Context) error {
+		res, err = c.EtherClient.StorageAt(ctx, account, key, blockNumber)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) (res []byte, err error) {
+	err = c.intercept(ctx, "eth_getCode", func(ctx context.Context) error {
+		res, err = c.EtherClient.CodeAt(ctx, account, blockNumber)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (res uint64, err error) {
+	err = c.intercept(ctx, "eth_getTransactionCount", func(ctx context.Context) error {
+		res, err = c.EtherClient.NonceAt(ctx, account, blockNumber)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) (res []types.Log, err error) {
+	err = c.intercept(ctx, "eth_getLogs", func(ctx context.Context) error {
+		res, err = c.EtherClient.FilterLogs(ctx, q)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (res ethereum.Subscription, err error) {
+	err = c.intercept(ctx, "eth_subscribe", func(ctx context.Context) error {
+		res, err = c.EtherClient.SubscribeFilterLogs(ctx, q, ch)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) PendingBalanceAt(ctx context.Context, account common.Address) (res *big.Int, err error) {
+	err = c.intercept(ctx, "eth_getBalance", func(ctx context.Context) error {
+		res, err = c.EtherClient.PendingBalanceAt(ctx, account)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) PendingStorageAt(ctx context.Context, account common.Address, key common.Hash) (res []byte, err error) {
+	err = c.intercept(ctx, "eth_getStorageAt", func(ctx context.Context) error {
+		res, err = c.EtherClient.PendingStorageAt(ctx, account, key)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) PendingCodeAt(ctx context.Context, account common.Address) (res []byte, err error) {
+	err = c.intercept(ctx, "eth_getCode", func(ctx context.Context) error {
+		res, err = c.EtherClient.PendingCodeAt(ctx, account)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) PendingNonceAt(ctx context.Context, account common.Address) (res uint64, err error) {
+	err = c.intercept(ctx, "eth_getTransactionCount", func(ctx context.Context) error {
+		res, err = c.EtherClient.PendingNonceAt(ctx, account)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) PendingTransactionCount(ctx context.Context) (res uint, err error) {
+	err = c.intercept(ctx, "eth_getBlockTransactionCountByNumber", func(ctx context.Context) error {
+		res, err = c.EtherClient.PendingTransactionCount(ctx)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) (res []byte, err error) {
+	err = c.intercept(ctx, "eth_call", func(ctx context.Context) error {
+		res, err = c.EtherClient.CallContract(ctx, msg, blockNumber)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) PendingCallContract(ctx context.Context, msg ethereum.CallMsg) (res []byte, err error) {
+	err = c.intercept(ctx, "eth_call", func(ctx context.Context) error {
+		res, err = c.EtherClient.PendingCallContract(ctx, msg)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) SuggestGasPrice(ctx context.Context) (res *big.Int, err error) {
+	err = c.intercept(ctx, "eth_gasPrice", func(ctx context.Context) error {
+		res, err = c.EtherClient.SuggestGasPrice(ctx)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) SuggestGasTipCap(ctx context.Context) (res *big.Int, err error) {
+	err = c.intercept(ctx, "eth_maxPriorityFeePerGas", func(ctx context.Context) error {
+		res, err = c.EtherClient.SuggestGasTipCap(ctx)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (res *ethereum.FeeHistory, err error) {
+	err = c.intercept(ctx, "eth_feeHistory", func(ctx context.Context) error {
+		res, err = c.EtherClient.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (res uint64, err error) {
+	err = c.intercept(ctx, "eth_estimateGas", func(ctx context.Context) error {
+		res, err = c.EtherClient.EstimateGas(ctx, msg)
+		return err
+	})
+	return res, err
+}
+
+func (c *interceptedClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return c.intercept(ctx, "eth_sendRawTransaction", func(ctx context.Context) error {
+		return c.EtherClient.SendTransaction(ctx, tx)
+	})
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Predeployed contracts used to estimate the L1 data fee on rollups.
+var (
+	OPStackGasPriceOracle = common.HexToAddress("0x420000000000000000000000000000000000000F")
+	ArbitrumNodeInterface = common.HexToAddress("0x00000000000000000000000000000000000000C8")
+)
+
+const opStackGasPriceOracleABI = `[{"inputs":[{"internalType":"bytes","name":"_data","type":"bytes"}],"name":"getL1Fee","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+
+const arbitrumNodeInterfaceABI = `[{"inputs":[{"internalType":"address","name":"to","type":"address"},{"internalType":"bool","name":"contractCreation","type":"bool"},{"internalType":"bytes","name":"data","type":"bytes"}],"name":"gasEstimateL1Component","outputs":[{"internalType":"uint64","name":"gasEstimateForL1","type":"uint64"},{"internalType":"uint256","name":"baseFee","type":"uint256"},{"internalType":"uint256","name":"l1BaseFeeEstimate","type":"uint256"}],"stateMutability":"payable","type":"function"}]`
+
+var (
+	opStackOracle  = mustParseABI(opStackGasPriceOracleABI)
+	arbitrumNodeIf = mustParseABI(arbitrumNodeInterfaceABI)
+)
+
+// ArbitrumL1Component is the L1 part of an Arbitrum transaction cost expressed in L2 gas.
+type ArbitrumL1Component struct {
+	// GasForL1 is the amount of L2 gas the transaction additionally uses to pay for L1 data.
+	GasForL1 uint64
+	// BaseFee is the current L2 base fee.
+	BaseFee *big.Int
+	// L1BaseFeeEstimate is the L1 base fee estimated by ArbOS.
+	L1BaseFeeEstimate *big.Int
+}
+
+// Fee returns the L1 component of the transaction fee in wei.
+func (c ArbitrumL1Component) Fee() *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(c.GasForL1), c.BaseFee)
+}
+
+// EstimateOPStackL1Fee returns the L1 data fee in wei that an OP stack chain (Optimism, Base)
+// charges on top of the execution fee for the given RLP encoded signed transaction.
+func EstimateOPStackL1Fee(ctx context.Context, caller ethereum.ContractCaller, rawTx []byte) (*big.Int, error) {
+	out, err := callPredeploy(ctx, caller, opStackOracle, OPStackGasPriceOracle, "getL1Fee", rawTx)
+	if err != nil {
+		return nil, err
+	}
+	return abi.ConvertType(out[0], new(big.Int)).(*big.Int), nil
+}
+
+// EstimateArbitrumL1Component returns the L1 component of the cost of a transaction with the given data on Arbitrum.
+func EstimateArbitrumL1Component(ctx context.Context, caller ethereum.ContractCaller, to common.Address, data []byte) (ArbitrumL1Component, error) {
+	out, err := callPredeploy(ctx, caller, arbitrumNodeIf, ArbitrumNodeInterface, "gasEstimateL1Component", to, false, data)
+	if err != nil {
+		return ArbitrumL1Component{}, err
+	}
+	return ArbitrumL1Component{
+		GasForL1:          *abi.ConvertType(out[0], new(uint64)).(*uint64),
+		BaseFee:           abi.ConvertType(out[1], new(big.Int)).(*big.Int),
+		L1BaseFeeEstimate: abi.ConvertType(out[2], new(big.Int)).(*big.Int),
+	}, nil
+}
+
+func callPredeploy(ctx context.Context, caller ethereum.ContractCaller, contract abi.ABI, address common.Address, method string, args ...interface{}) ([]interface{}, error) {
+	input, err := contract.Pack(method, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack %s: %w", method, err)
+	}
+
+	res, err := caller.CallContract(ctx, ethereum.CallMsg{To: &address, Data: input}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", method, err)
+	}
+
+	out, err := contract.Unpack(method, res)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack %s: %w", method, err)
+	}
+	return out, nil
+}
+
+func mustParseABI(def string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(def))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeCaller struct {
+	msg ethereum.CallMsg
+	res []byte
+}
+
+func (f *fakeCaller) CallContract(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	f.msg = msg
+	return f.res, nil
+}
+
+func TestL1Fees(t *testing.T) {
+	t.Run("op stack", func(t *testing.T) {
+		res, err := opStackOracle.Methods["getL1Fee"].Outputs.Pack(big.NewInt(12345))
+		assert.NoError(t, err)
+		caller := &fakeCaller{res: res}
+
+		fee, err := EstimateOPStackL1Fee(context.Background(), caller, []byte{1, 2, 3})
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(12345), fee)
+		assert.Equal(t, OPStackGasPriceOracle, *caller.msg.To)
+		assert.Equal(t, opStackOracle.Methods["getL1Fee"].ID, caller.msg.Data[:4])
+	})
+
+	t.Run("arbitrum", func(t *testing.T) {
+		res, err := arbitrumNodeIf.Methods["gasEstimateL1Component"].Outputs.Pack(uint64(1000), big.NewInt(100), big.NewInt(30))
+		assert.NoError(t, err)
+		caller := &fakeCaller{res: res}
+
+		c, err := EstimateArbitrumL1Component(context.Background(), caller, common.HexToAddress("0x1"), []byte{1})
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1000), c.GasForL1)
+		assert.Equal(t, big.NewInt(100000), c.Fee())
+		assert.Equal(t, big.NewInt(30), c.L1BaseFeeEstimate)
+		assert.Equal(t, ArbitrumNodeInterface, *caller.msg.To)
+	})
+
+	t.Run("bad response", func(t *testing.T) {
+		_, err := EstimateOPStackL1Fee(context.Background(), &fakeCaller{}, nil)
+		assert.Error(t, err)
+	})
+}
@@ -45,6 +45,9 @@ import (
 // 			EstimateGasFunc: func(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
 // 				panic("mock out the EstimateGas method")
 // 			},
+// 			FeeHistoryFunc: func(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+// 				panic("mock out the FeeHistory method")
+// 			},
 // 			FilterLogsFunc: func(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
 // 				panic("mock out the FilterLogs method")
 // 			},
@@ -148,6 +151,9 @@ type EtherClientMock struct {
 	// EstimateGasFunc mocks the EstimateGas method.
 	EstimateGasFunc func(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
 
+	// FeeHistoryFunc mocks the FeeHistory method.
+	FeeHistoryFunc func(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+
 	// FilterLogsFunc mocks the FilterLogs method.
 	FilterLogsFunc func(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
 
@@ -280,6 +286,17 @@ type EtherClientMock struct {
 			// Msg is the msg argument value.
 			Msg ethereum.CallMsg
 		}
+		// FeeHistory holds details about calls to the FeeHistory method.
+		FeeHistory []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// BlockCount is the blockCount argument value.
+			BlockCount uint64
+			// LastBlock is the lastBlock argument value.
+			LastBlock *big.Int
+			// RewardPercentiles is the rewardPercentiles argument value.
+			RewardPercentiles []float64
+		}
 		// FilterLogs holds details about calls to the FilterLogs method.
 		FilterLogs []struct {
 			// Ctx is the ctx argument value.
@@ -457,6 +474,7 @@ type EtherClientMock struct {
 	lockClose                   sync.RWMutex
 	lockCodeAt                  sync.RWMutex
 	lockEstimateGas             sync.RWMutex
+	lockFeeHistory              sync.RWMutex
 	lockFilterLogs              sync.RWMutex
 	lockHeaderByHash            sync.RWMutex
 	lockHeaderByNumber          sync.RWMutex
@@ -792,6 +810,49 @@ func (mock *EtherClientMock) EstimateGasCalls() []struct {
 	return calls
 }
 
+// FeeHistory calls FeeHistoryFunc.
+func (mock *EtherClientMock) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	if mock.FeeHistoryFunc == nil {
+		panic("EtherClientMock.FeeHistoryFunc: method is nil but EtherClient.FeeHistory was just called")
+	}
+	callInfo := struct {
+		Ctx               context.Context
+		BlockCount        uint64
+		LastBlock         *big.Int
+		RewardPercentiles []float64
+	}{
+		Ctx:               ctx,
+		BlockCount:        blockCount,
+		LastBlock:         lastBlock,
+		RewardPercentiles: rewardPercentiles,
+	}
+	mock.lockFeeHistory.Lock()
+	mock.calls.FeeHistory = append(mock.calls.FeeHistory, callInfo)
+	mock.lockFeeHistory.Unlock()
+	return mock.FeeHistoryFunc(ctx, blockCount, lastBlock, rewardPercentiles)
+}
+
+// FeeHistoryCalls gets all the calls that were made to FeeHistory.
+// Check the length with:
+//     len(mockedEtherClient.FeeHistoryCalls())
+func (mock *EtherClientMock) FeeHistoryCalls() []struct {
+	Ctx               context.Context
+	BlockCount        uint64
+	LastBlock         *big.Int
+	RewardPercentiles []float64
+} {
+	var calls []struct {
+		Ctx               context.Context
+		BlockCount        uint64
+		LastBlock         *big.Int
+		RewardPercentiles []float64
+	}
+	mock.lockFeeHistory.RLock()
+	calls = mock.calls.FeeHistory
+	mock.lockFeeHistory.RUnlock()
+	return calls
+}
+
 // FilterLogs calls FilterLogsFunc.
 func (mock *EtherClientMock) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
 	if mock.FilterLogsFunc == nil {
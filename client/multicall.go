@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/mysteriumnetwork/payments/bindings/multicall"
+)
+
+// Multicall3Address is the address Multicall3 is deployed at on most EVM chains.
+var Multicall3Address = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+
+// DefaultMaxMulticallSize is the default max number of calls aggregated into a single
+// Multicall3 call, larger aggregates would run into the gas limit of `eth_call`.
+const DefaultMaxMulticallSize = 500
+
+// ErrMulticallFailed is returned for calls of an aggregate which reverted.
+var ErrMulticallFailed = errors.New("multicall call reverted")
+
+// MulticallResult holds the unpacked outputs of a single call once the aggregate was executed.
+type MulticallResult struct {
+	Values []interface{}
+	Err    error
+}
+
+// Multicall packs many contract view calls, such as channel balances, stakes and
+// registration statuses, into Multicall3 `aggregate3` calls executed in a single request.
+// A reverting call does not fail the others, its result holds `ErrMulticallFailed`.
+type Multicall struct {
+	caller  *multicall.Multicall3Caller
+	maxSize int
+
+	calls  []multicall.Multicall3Call3
+	decode []func(res multicall.Multicall3Result, err error)
+}
+
+// NewMulticall returns a new empty aggregate of calls sent to the Multicall3 contract at the given address.
+func NewMulticall(caller bind.ContractCaller, address common.Address) (*Multicall, error) {
+	mc, err := multicall.NewMulticall3Caller(address, caller)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind multicall: %w", err)
+	}
+	return &Multicall{
+		caller:  mc,
+		maxSize: DefaultMaxMulticallSize,
+	}, nil
+}
+
+// SetMaxCalls sets the max number of calls aggregated into a single Multicall3 call,
+// larger aggregates are split into several calls.
+//
+// This method is not thread safe and should be called before `Execute`.
+func (m *Multicall) SetMaxCalls(size int) {
+	m.maxSize = size
+}
+
+// Len returns the number of calls in the aggregate.
+func (m *Multicall) Len() int {
+	return len(m.calls)
+}
+
+// Add adds a call of the contract method, the ABI is usually taken from
+// the bindings, e.g. `bindings.HermesImplementationMetaData.GetAbi()`.
+func (m *Multicall) Add(target common.Address, contractABI *abi.ABI, method string, args ...interface{}) (*MulticallResult, error) {
+	data, err := contractABI.Pack(method, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack %q call: %w", method, err)
+	}
+
+	res := &MulticallResult{}
+	m.calls = append(m.calls, multicall.Multicall3Call3{
+		Target:       target,
+		AllowFailure: true,
+		CallData:     data,
+	})
+	m.decode = append(m.decode, func(out multicall.Multicall3Result, err error) {
+		switch {
+		case err != nil:
+			res.Err = err
+		case !out.Success:
+			res.Err = fmt.Errorf("%w: %q on %s", ErrMulticallFailed, method, target.Hex())
+		default:
+			res.Values, res.Err = contractABI.Unpack(method, out.ReturnData)
+		}
+	})
+	return res, nil
+}
+
+// Execute executes the aggregated calls at the given block, nil being the latest one,
+// and sets the results of every call. An error is only returned if an aggregate call
+// failed as a whole, in which case the results of its calls hold the same error.
+func (m *Multicall) Execute(ctx context.Context, blockNumber *big.Int) error {
+	size := m.maxSize
+	if size <= 0 {
+		size = len(m.calls)
+	}
+
+	var lastErr error
+	for start := 0; start < len(m.calls); start += size {
+		end := start + size
+		if end > len(m.calls) {
+			end = len(m.calls)
+		}
+
+		out, err := m.caller.Aggregate3(&bind.CallOpts{Context: ctx, BlockNumber: blockNumber}, m.calls[start:end])
+		if err == nil && len(out) != end-start {
+			err = fmt.Errorf("got %d results for %d calls", len(out), end-start)
+		}
+		if err != nil {
+			err = fmt.Errorf("failed to execute multicall of %d calls: %w", end-start, err)
+			lastErr = err
+		}
+		for i := start; i < end; i++ {
+			if err != nil {
+				m.decode[i](multicall.Multicall3Result{}, err)
+				continue
+			}
+			m.decode[i](out[i-start], nil)
+		}
+	}
+	return lastErr
+}
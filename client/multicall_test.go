@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/bindings"
+	"github.com/mysteriumnetwork/payments/bindings/multicall"
+)
+
+type multicallCallerMock struct {
+	balances  map[common.Address]*big.Int
+	aggregate int
+	err       error
+}
+
+func (m *multicallCallerMock) CodeAt(_ context.Context, _ common.Address, _ *big.Int) ([]byte, error) {
+	return []byte{1}, nil
+}
+
+func (m *multicallCallerMock) CallContract(_ context.Context, call ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.aggregate++
+
+	mcABI, _ := multicall.Multicall3MetaData.GetAbi()
+	erc20ABI, _ := bindings.Erc20MetaData.GetAbi()
+	method := mcABI.Methods["aggregate3"]
+	in, err := method.Inputs.Unpack(call.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+
+	calls := in[0].([]struct {
+		Target       common.Address `json:"target"`
+		AllowFailure bool           `json:"allowFailure"`
+		CallData     []byte         `json:"callData"`
+	})
+	out := make([]multicall.Multicall3Result, 0, len(calls))
+	for _, c := range calls {
+		args, err := erc20ABI.Methods["balanceOf"].Inputs.Unpack(c.CallData[4:])
+		if err != nil {
+			return nil, err
+		}
+		balance, ok := m.balances[args[0].(common.Address)]
+		if !ok {
+			out = append(out, multicall.Multicall3Result{})
+			continue
+		}
+		data, _ := erc20ABI.Methods["balanceOf"].Outputs.Pack(balance)
+		out = append(out, multicall.Multicall3Result{Success: true, ReturnData: data})
+	}
+	return method.Outputs.Pack(out)
+}
+
+func Test_Multicall(t *testing.T) {
+	a, b, unknown := common.HexToAddress("0x1"), common.HexToAddress("0x2"), common.HexToAddress("0x3")
+	bc := &multicallCallerMock{balances: map[common.Address]*big.Int{
+		a: big.NewInt(10),
+		b: big.NewInt(20),
+	}}
+	erc20ABI, err := bindings.Erc20MetaData.GetAbi()
+	assert.NoError(t, err)
+	token := common.HexToAddress("0x4")
+
+	mc, err := NewMulticall(bc, Multicall3Address)
+	assert.NoError(t, err)
+	mc.SetMaxCalls(2)
+
+	var results []*MulticallResult
+	for _, acc := range []common.Address{a, b, unknown} {
+		res, err := mc.Add(token, erc20ABI, "balanceOf", acc)
+		assert.NoError(t, err)
+		results = append(results, res)
+	}
+	_, err = mc.Add(token, erc20ABI, "balanceOf")
+	assert.Error(t, err)
+	assert.Equal(t, 3, mc.Len())
+
+	assert.NoError(t, mc.Execute(context.Background(), nil))
+	assert.Equal(t, 2, bc.aggregate)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, []interface{}{big.NewInt(10)}, results[0].Values)
+	assert.Equal(t, []interface{}{big.NewInt(20)}, results[1].Values)
+	assert.ErrorIs(t, results[2].Err, ErrMulticallFailed)
+
+	bc.err = errors.New("execution timeout")
+	assert.ErrorIs(t, mc.Execute(context.Background(), nil), bc.err)
+	assert.ErrorIs(t, results[0].Err, bc.err)
+}
@@ -17,6 +17,7 @@
 package client
 
 import (
+	"context"
 	"math/big"
 	"time"
 
@@ -30,6 +31,8 @@ import (
 
 type MultichainBlockchainClient struct {
 	clients map[int64]BC
+	pool    *ConnectionPool
+	ctx     context.Context
 }
 
 func NewMultichainBlockchainClient(clients map[int64]BC) *MultichainBlockchainClient {
@@ -38,10 +41,48 @@ func NewMultichainBlockchainClient(clients map[int64]BC) *MultichainBlockchainCl
 	}
 }
 
+// NewLazyMultichainBlockchainClient returns a multichain client getting the chain clients
+// from the connection pool, which connects to every chain on its first use.
+func NewLazyMultichainBlockchainClient(pool *ConnectionPool) *MultichainBlockchainClient {
+	return &MultichainBlockchainClient{
+		pool: pool,
+	}
+}
+
 var ErrUnknownChain = errors.New("unknown chain")
 
+// WithContext returns a copy of the client in which every chain client
+// supporting it derives its call contexts from the given one.
+func (mbc *MultichainBlockchainClient) WithContext(ctx context.Context) *MultichainBlockchainClient {
+	if mbc.pool != nil {
+		return &MultichainBlockchainClient{pool: mbc.pool, ctx: ctx}
+	}
+
+	clients := make(map[int64]BC, len(mbc.clients))
+	for chainID, bc := range mbc.clients {
+		if cbc, ok := bc.(interface {
+			WithContext(context.Context) *Blockchain
+		}); ok {
+			bc = cbc.WithContext(ctx)
+		}
+		clients[chainID] = bc
+	}
+	return NewMultichainBlockchainClient(clients)
+}
+
 // GetClientByChain returns blockchain client for given chain id
 func (mbc *MultichainBlockchainClient) GetClientByChain(chainID int64) (BC, error) {
+	if mbc.pool != nil {
+		bc, err := mbc.pool.Get(chainID)
+		if err != nil {
+			return nil, err
+		}
+		if mbc.ctx != nil {
+			return bc.WithContext(mbc.ctx), nil
+		}
+		return bc, nil
+	}
+
 	if v, ok := mbc.clients[chainID]; ok {
 		return v, nil
 	}
@@ -49,6 +90,10 @@ func (mbc *MultichainBlockchainClient) GetClientByChain(chainID int64) (BC, erro
 }
 
 func (mbc *MultichainBlockchainClient) GetSupportedChains() []int64 {
+	if mbc.pool != nil {
+		return mbc.pool.Chains()
+	}
+
 	res := make([]int64, 0)
 	for k := range mbc.clients {
 		res = append(res, k)
@@ -359,6 +404,24 @@ func (mbc *MultichainBlockchainClient) NonceAt(chainID int64, account common.Add
 	return bc.NonceAt(account, blockNum)
 }
 
+// BalanceAt returns the wei balance of the account at the given block on the given chain.
+func (mbc *MultichainBlockchainClient) BalanceAt(chainID int64, account common.Address, blockNum *big.Int) (*big.Int, error) {
+	bc, err := mbc.GetClientByChain(chainID)
+	if err != nil {
+		return nil, err
+	}
+	return bc.BalanceAt(account, blockNum)
+}
+
+// StorageAt returns the value of the key in the contract storage of the account at the given block on the given chain.
+func (mbc *MultichainBlockchainClient) StorageAt(chainID int64, account common.Address, key common.Hash, blockNum *big.Int) ([]byte, error) {
+	bc, err := mbc.GetClientByChain(chainID)
+	if err != nil {
+		return nil, err
+	}
+	return bc.StorageAt(account, key, blockNum)
+}
+
 func (mbc *MultichainBlockchainClient) TransferEth(chainID int64, etr EthTransferRequest) (*types.Transaction, error) {
 	bc, err := mbc.GetClientByChain(chainID)
 	if err != nil {
@@ -459,6 +522,15 @@ func (mbc *MultichainBlockchainClient) FilterLogs(chainID int64, q ethereum.Filt
 	return bc.FilterLogs(q)
 }
 
+// FilterLogsPaged executes a filter query over a large block range in pages of at most maxRange blocks.
+func (mbc *MultichainBlockchainClient) FilterLogsPaged(chainID int64, q ethereum.FilterQuery, maxRange uint64) ([]types.Log, error) {
+	bc, err := mbc.GetClientByChain(chainID)
+	if err != nil {
+		return nil, err
+	}
+	return bc.FilterLogsPaged(q, maxRange)
+}
+
 // HeaderByNumber returns a block header from the current canonical chain. If number is
 // nil, the latest known header is returned.
 func (mbc *MultichainBlockchainClient) HeaderByNumber(chainID int64, number *big.Int) (*types.Header, error) {
@@ -469,6 +541,16 @@ func (mbc *MultichainBlockchainClient) HeaderByNumber(chainID int64, number *big
 	return bc.HeaderByNumber(number)
 }
 
+// FeeHistory returns the fee market history of the given number of blocks up to the last block.
+// If the last block is nil, the history ends with the latest known block.
+func (mbc *MultichainBlockchainClient) FeeHistory(chainID int64, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	bc, err := mbc.GetClientByChain(chainID)
+	if err != nil {
+		return nil, err
+	}
+	return bc.FeeHistory(blockCount, lastBlock, rewardPercentiles)
+}
+
 func (mbc *MultichainBlockchainClient) SendTransaction(chainID int64, tx *types.Transaction) error {
 	bc, err := mbc.GetClientByChain(chainID)
 	if err != nil {
@@ -646,6 +728,55 @@ func (mbc *MultichainBlockchainClient) MystAllowance(chainID int64, mystTokenAdd
 	return bc.MystAllowance(mystTokenAddress, holder, spender)
 }
 
+func (mbc *MultichainBlockchainClient) TokenDecimals(chainID int64, tokenAddress common.Address) (uint8, error) {
+	bc, err := mbc.GetClientByChain(chainID)
+	if err != nil {
+		return 0, err
+	}
+	return bc.TokenDecimals(tokenAddress)
+}
+
+func (mbc *MultichainBlockchainClient) TokenBalanceOf(chainID int64, tokenAddress, holder common.Address) (*big.Int, error) {
+	bc, err := mbc.GetClientByChain(chainID)
+	if err != nil {
+		return nil, err
+	}
+	return bc.TokenBalanceOf(tokenAddress, holder)
+}
+
+func (mbc *MultichainBlockchainClient) TokenAllowance(chainID int64, tokenAddress, holder, spender common.Address) (*big.Int, error) {
+	bc, err := mbc.GetClientByChain(chainID)
+	if err != nil {
+		return nil, err
+	}
+	return bc.TokenAllowance(tokenAddress, holder, spender)
+}
+
+func (mbc *MultichainBlockchainClient) TokenApprove(chainID int64, req TokenApproveReq) (*types.Transaction, error) {
+	bc, err := mbc.GetClientByChain(chainID)
+	if err != nil {
+		return nil, err
+	}
+	return bc.TokenApprove(req)
+}
+
+func (mbc *MultichainBlockchainClient) TokenTransferFrom(chainID int64, req TokenTransferFromReq) (*types.Transaction, error) {
+	bc, err := mbc.GetClientByChain(chainID)
+	if err != nil {
+		return nil, err
+	}
+	return bc.TokenTransferFrom(req)
+}
+
+// Multicall returns a new empty aggregate of calls on the given chain sent to `Multicall3Address`.
+func (mbc *MultichainBlockchainClient) Multicall(chainID int64) (*Multicall, error) {
+	bc, err := mbc.GetClientByChain(chainID)
+	if err != nil {
+		return nil, err
+	}
+	return NewMulticall(bc.Client(), Multicall3Address)
+}
+
 func (mbc *MultichainBlockchainClient) UniswapV3ExactInputSingle(chainID int64, req UniswapExactInputSingleReq) (*types.Transaction, error) {
 	bc, err := mbc.GetClientByChain(chainID)
 	if err != nil {
@@ -728,3 +859,13 @@ func (mbc *MultichainBlockchainClient) SwapExactTokensForETH(chainID int64, req
 	}
 	return bc.SwapExactTokensForETH(req)
 }
+
+// WaitMined waits for the transaction on the given chain to be mined with the given number of confirmations.
+// See `WaitMined` for the details.
+func (mbc *MultichainBlockchainClient) WaitMined(ctx context.Context, chainID int64, hash common.Hash, confirmations uint64) (*types.Receipt, error) {
+	bc, err := mbc.GetClientByChain(chainID)
+	if err != nil {
+		return nil, err
+	}
+	return WaitMined(ctx, bc.Client(), chainID, hash, confirmations)
+}
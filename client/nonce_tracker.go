@@ -23,48 +23,247 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 )
 
-// NonceTracker keeps track of nonces atomically.
+// NonceTracker keeps track of nonces atomically. Nonces are tracked per chain,
+// as the same account can send transactions on several chains concurrently.
 type NonceTracker struct {
-	client    client
-	nonces    map[common.Address]uint64
+	client   nonceClient
+	nonces   map[nonceKey]uint64
+	released map[nonceKey][]uint64
+	// epochs counts the reloads of every account, reservations taken before a reload are not released.
+	epochs map[nonceKey]uint64
+	// floors holds the pending nonce read by the last reload, released nonces below it are not issued.
+	floors    map[nonceKey]uint64
 	nonceLock sync.Mutex
+
+	resync bool
+}
+
+// nonceClient is satisfied by `MultichainBlockchainClient`.
+type nonceClient interface {
+	PendingNonceAt(chainID int64, account common.Address) (uint64, error)
 }
 
-type client interface {
-	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+type nonceKey struct {
+	chainID int64
+	account common.Address
 }
 
 // NewNonceTracker returns a new nonce tracker.
-func NewNonceTracker(client client) *NonceTracker {
+func NewNonceTracker(client nonceClient) *NonceTracker {
 	return &NonceTracker{
-		client: client,
-		nonces: make(map[common.Address]uint64),
+		client:   client,
+		nonces:   make(map[nonceKey]uint64),
+		released: make(map[nonceKey][]uint64),
+		epochs:   make(map[nonceKey]uint64),
+		floors:   make(map[nonceKey]uint64),
 	}
 }
 
+// SetResyncOnMismatch makes the tracker read the pending nonce of the account for every
+// nonce it issues, moving the cached value forward if the chain is ahead of it, for example
+// because the account also sends transactions elsewhere.
+//
+// This method is not thread safe and should be called before the tracker is used.
+func (nt *NonceTracker) SetResyncOnMismatch(enabled bool) {
+	nt.resync = enabled
+}
+
 // GetNonce returns an atomically increasing nonce for the account.
-func (nt *NonceTracker) GetNonce(ctx context.Context, account common.Address) (uint64, error) {
+func (nt *NonceTracker) GetNonce(chainID int64, account common.Address) (uint64, error) {
+	return nt.GetNonceContext(context.Background(), chainID, account)
+}
+
+// GetNonceContext is like `GetNonce`, the pending nonce is read from the chain
+// using the given context if the client supports it.
+func (nt *NonceTracker) GetNonceContext(ctx context.Context, chainID int64, account common.Address) (uint64, error) {
 	nt.nonceLock.Lock()
 	defer nt.nonceLock.Unlock()
 
-	if v, ok := nt.nonces[account]; ok {
+	return nt.nextNonce(ctx, nonceKey{chainID: chainID, account: account})
+}
+
+func (nt *NonceTracker) nextNonce(ctx context.Context, key nonceKey) (uint64, error) {
+	if nonce, ok := nt.takeReleased(key); ok {
+		return nonce, nil
+	}
+
+	v, ok := nt.nonces[key]
+	if ok && !nt.resync {
 		v++
-		nt.nonces[account] = v
+		nt.nonces[key] = v
 		return v, nil
 	}
 
-	nonce, err := nt.client.PendingNonceAt(ctx, account)
+	nonce, err := nt.clientFor(ctx).PendingNonceAt(key.chainID, key.account)
 	if err != nil {
 		return nonce, err
 	}
+	if ok && v+1 > nonce {
+		nonce = v + 1
+	}
 
-	nt.nonces[account] = nonce
+	nt.nonces[key] = nonce
+	return nonce, nil
+}
+
+// clientFor returns the client deriving its call contexts from ctx, if it supports it.
+func (nt *NonceTracker) clientFor(ctx context.Context) nonceClient {
+	if cc, ok := nt.client.(interface {
+		WithContext(context.Context) *MultichainBlockchainClient
+	}); ok {
+		return cc.WithContext(ctx)
+	}
+	return nt.client
+}
+
+// ForceReload re-reads the pending nonce of the account and resets the cached value to it,
+// returning the next nonce which will be issued. It fixes a cache which drifted ahead
+// because a transaction failed after its nonce was issued.
+func (nt *NonceTracker) ForceReload(chainID int64, account common.Address) (uint64, error) {
+	nt.nonceLock.Lock()
+	defer nt.nonceLock.Unlock()
+
+	key := nonceKey{chainID: chainID, account: account}
+	nonce, err := nt.client.PendingNonceAt(chainID, account)
+	if err != nil {
+		return 0, err
+	}
+
+	delete(nt.released, key)
+	nt.epochs[key]++
+	nt.floors[key] = nonce
+	if nonce == 0 {
+		delete(nt.nonces, key)
+	} else {
+		nt.nonces[key] = nonce - 1
+	}
 	return nonce, nil
 }
 
 // ForceReloadNonce clears the nonce cache. This will force loading from BC next time.
-func (nt *NonceTracker) ForceReloadNonce(account common.Address) {
+func (nt *NonceTracker) ForceReloadNonce(chainID int64, account common.Address) {
 	nt.nonceLock.Lock()
 	defer nt.nonceLock.Unlock()
-	delete(nt.nonces, account)
+	key := nonceKey{chainID: chainID, account: account}
+	delete(nt.nonces, key)
+	delete(nt.released, key)
+	delete(nt.floors, key)
+	nt.epochs[key]++
+}
+
+// NonceReservation is a reserved nonce. It must be confirmed once its transaction is
+// broadcast, or released if it is aborted, for example because signing failed.
+type NonceReservation struct {
+	nt    *NonceTracker
+	key   nonceKey
+	nonce uint64
+	epoch uint64
+	once  sync.Once
+}
+
+// Reserve reserves the next nonce of the account. Unlike `GetNonce` the nonce is not
+// burnt if the transaction is aborted, as releasing the reservation issues it again.
+func (nt *NonceTracker) Reserve(chainID int64, account common.Address) (*NonceReservation, error) {
+	return nt.ReserveContext(context.Background(), chainID, account)
+}
+
+// ReserveContext is like `Reserve`, the pending nonce is read from the chain
+// using the given context if the client supports it.
+func (nt *NonceTracker) ReserveContext(ctx context.Context, chainID int64, account common.Address) (*NonceReservation, error) {
+	nt.nonceLock.Lock()
+	defer nt.nonceLock.Unlock()
+
+	key := nonceKey{chainID: chainID, account: account}
+	nonce, err := nt.nextNonce(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return &NonceReservation{nt: nt, key: key, nonce: nonce, epoch: nt.epochs[key]}, nil
+}
+
+// Nonce returns the reserved nonce.
+func (r *NonceReservation) Nonce() uint64 {
+	return r.nonce
+}
+
+// Confirm marks the nonce as used by a broadcast transaction.
+func (r *NonceReservation) Confirm() {
+	r.once.Do(func() {})
+}
+
+// Release returns the nonce so it is issued again. Does nothing if the reservation was confirmed.
+func (r *NonceReservation) Release() {
+	r.once.Do(func() {
+		r.nt.release(r.key, r.nonce, r.epoch)
+	})
+}
+
+// release rolls the cached nonce back if the released nonce is the last issued one,
+// otherwise it is kept to be issued before any new nonce. Nonces reserved before the
+// last reload are dropped, the reload already read the nonce from the chain.
+func (nt *NonceTracker) release(key nonceKey, nonce, epoch uint64) {
+	nt.nonceLock.Lock()
+	defer nt.nonceLock.Unlock()
+
+	if epoch != nt.epochs[key] {
+		return
+	}
+
+	last, ok := nt.nonces[key]
+	if !ok || last != nonce {
+		nt.released[key] = append(nt.released[key], nonce)
+		return
+	}
+
+	for {
+		if last == 0 {
+			delete(nt.nonces, key)
+			return
+		}
+		last--
+		nt.nonces[key] = last
+		if !nt.dropReleased(key, last) {
+			return
+		}
+	}
+}
+
+// takeReleased removes and returns the lowest released nonce of the account
+// which is not below the pending nonce read by the last reload.
+func (nt *NonceTracker) takeReleased(key nonceKey) (uint64, bool) {
+	released := nt.released[key]
+	if floor, ok := nt.floors[key]; ok {
+		kept := released[:0]
+		for _, n := range released {
+			if n >= floor {
+				kept = append(kept, n)
+			}
+		}
+		released = kept
+		nt.released[key] = released
+	}
+	if len(released) == 0 {
+		return 0, false
+	}
+
+	lowest := 0
+	for i, n := range released {
+		if n < released[lowest] {
+			lowest = i
+		}
+	}
+	nonce := released[lowest]
+	nt.dropReleased(key, nonce)
+	return nonce, true
+}
+
+func (nt *NonceTracker) dropReleased(key nonceKey, nonce uint64) bool {
+	released := nt.released[key]
+	for i, n := range released {
+		if n == nonce {
+			nt.released[key] = append(released[:i], released[i+1:]...)
+			return true
+		}
+	}
+	return false
 }
@@ -5,13 +5,16 @@ import (
 	"fmt"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/client/mocks"
 )
 
 func Test_NonceTracker(t *testing.T) {
-	trck := NewNonceTracker(&mockClient{})
+	trck := NewNonceTracker(&mockClient{pending: 1})
 	addr := common.HexToAddress("0x0")
 	wg := sync.WaitGroup{}
 
@@ -22,7 +25,7 @@ func Test_NonceTracker(t *testing.T) {
 		go func() {
 			defer wg.Done()
 
-			nonce, err := trck.GetNonce(context.Background(), addr)
+			nonce, err := trck.GetNonce(1, addr)
 			assert.NoError(t, err)
 			nonces <- nonce
 		}()
@@ -46,9 +49,140 @@ func Test_NonceTracker(t *testing.T) {
 
 }
 
+func Test_NonceTrackerForceReload(t *testing.T) {
+	mc := &mockClient{pending: 5}
+	trck := NewNonceTracker(mc)
+	addr := common.HexToAddress("0x0")
+
+	for i := 0; i < 3; i++ {
+		_, err := trck.GetNonce(1, addr)
+		assert.NoError(t, err)
+	}
+
+	// The transactions using nonces 6 and 7 failed.
+	mc.pending = 6
+	next, err := trck.ForceReload(1, addr)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(6), next)
+
+	nonce, err := trck.GetNonce(1, addr)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(6), nonce)
+}
+
+func Test_NonceTrackerResyncOnMismatch(t *testing.T) {
+	mc := &mockClient{pending: 5}
+	trck := NewNonceTracker(mc)
+	trck.SetResyncOnMismatch(true)
+	addr := common.HexToAddress("0x0")
+
+	nonce, err := trck.GetNonce(1, addr)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), nonce)
+
+	// Not broadcast yet, the cache is ahead of the chain.
+	nonce, err = trck.GetNonce(1, addr)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(6), nonce)
+
+	// Sent elsewhere, the chain is ahead of the cache.
+	mc.pending = 10
+	nonce, err = trck.GetNonce(1, addr)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), nonce)
+}
+
+func Test_NonceTrackerReservations(t *testing.T) {
+	trck := NewNonceTracker(&mockClient{pending: 0})
+	addr := common.HexToAddress("0x0")
+
+	reservations := make([]*NonceReservation, 3)
+	for i := range reservations {
+		r, err := trck.Reserve(1, addr)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(i), r.Nonce())
+		reservations[i] = r
+	}
+	reservations[0].Confirm()
+	reservations[0].Release()
+
+	// A released nonce in the middle is issued before new ones.
+	reservations[1].Release()
+	nonce, err := trck.GetNonce(1, addr)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), nonce)
+
+	// Releasing the last nonce rolls the counter back.
+	reservations[2].Release()
+	r, err := trck.Reserve(1, addr)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), r.Nonce())
+}
+
+func Test_NonceTrackerReleaseAfterReload(t *testing.T) {
+	mc := &mockClient{pending: 5}
+	trck := NewNonceTracker(mc)
+	addr := common.HexToAddress("0x0")
+
+	stale, err := trck.Reserve(1, addr)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), stale.Nonce())
+	_, err = trck.Reserve(1, addr)
+	assert.NoError(t, err)
+
+	// Nonce 5 was confirmed meanwhile, releasing its reservation must not issue it again.
+	mc.pending = 7
+	_, err = trck.ForceReload(1, addr)
+	assert.NoError(t, err)
+	stale.Release()
+
+	nonce, err := trck.GetNonce(1, addr)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), nonce)
+}
+
+func Test_NonceTrackerContext(t *testing.T) {
+	type ctxKey struct{}
+	var got context.Context
+	cl := &mocks.EtherClientMock{PendingNonceAtFunc: func(ctx context.Context, account common.Address) (uint64, error) {
+		got = ctx
+		return 3, ctx.Err()
+	}}
+	mbc := NewMultichainBlockchainClient(map[int64]BC{137: NewBlockchain(NewDefaultEthClientGetter(cl), time.Second)})
+	trck := NewNonceTracker(mbc)
+	addr := common.HexToAddress("0x0")
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "trace"))
+	nonce, err := trck.GetNonceContext(ctx, 137, addr)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), nonce)
+	assert.Equal(t, "trace", got.Value(ctxKey{}))
+
+	cancel()
+	trck.ForceReloadNonce(137, addr)
+	_, err = trck.ReserveContext(ctx, 137, addr)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+var _ nonceClient = (*MultichainBlockchainClient)(nil)
+
+func Test_NonceTrackerPerChain(t *testing.T) {
+	trck := NewNonceTracker(&mockClient{pending: 3})
+	addr := common.HexToAddress("0x0")
+
+	for _, chainID := range []int64{1, 137} {
+		for i := 0; i < 2; i++ {
+			nonce, err := trck.GetNonce(chainID, addr)
+			assert.NoError(t, err)
+			assert.Equal(t, uint64(3+i), nonce)
+		}
+	}
+}
+
 type mockClient struct {
+	pending uint64
 }
 
-func (mc *mockClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
-	return 1, nil
+func (mc *mockClient) PendingNonceAt(chainID int64, account common.Address) (uint64, error) {
+	return mc.pending, nil
 }
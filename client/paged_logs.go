@@ -0,0 +1,148 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// DefaultMaxLogsRange is the default max number of blocks queried at once, the limit of Infura.
+const DefaultMaxLogsRange = 10000
+
+// limitExceededCode is the JSON-RPC error code providers return for queries over their limits.
+const limitExceededCode = -32005
+
+var (
+	// filterLogsRetries is the number of times a failed page is retried.
+	filterLogsRetries = 3
+	// filterLogsBackoff is the time waited before retrying a failed page.
+	filterLogsBackoff = time.Second
+)
+
+// PagedLogFilterer is used to filter logs page by page, `EtherClient` satisfies it.
+type PagedLogFilterer interface {
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// FilterLogsPaged executes a filter query over a large block range by splitting it into
+// pages of at most maxRange blocks. A page for which the provider returns too many results
+// is split in half, other failures are retried. If a page still fails, the logs of the
+// pages before it are returned together with the error.
+func FilterLogsPaged(ctx context.Context, filterer PagedLogFilterer, q ethereum.FilterQuery, maxRange uint64) ([]types.Log, error) {
+	if q.BlockHash != nil {
+		return filterer.FilterLogs(ctx, q)
+	}
+	if maxRange == 0 {
+		maxRange = DefaultMaxLogsRange
+	}
+
+	var from, to uint64
+	if q.FromBlock != nil {
+		from = q.FromBlock.Uint64()
+	}
+	if q.ToBlock != nil {
+		to = q.ToBlock.Uint64()
+	} else {
+		latest, err := filterer.BlockNumber(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the latest block: %w", err)
+		}
+		to = latest
+	}
+
+	var res []types.Log
+	for from <= to {
+		end := to
+		if to-from >= maxRange {
+			end = from + maxRange - 1
+		}
+
+		logs, err := filterPage(ctx, filterer, q, from, end)
+		res = append(res, logs...)
+		if err != nil {
+			return res, err
+		}
+		from = end + 1
+	}
+	return res, nil
+}
+
+func filterPage(ctx context.Context, filterer PagedLogFilterer, q ethereum.FilterQuery, from, to uint64) ([]types.Log, error) {
+	page := q
+	page.FromBlock = new(big.Int).SetUint64(from)
+	page.ToBlock = new(big.Int).SetUint64(to)
+
+	var err error
+	for attempt := 0; attempt <= filterLogsRetries; attempt++ {
+		var logs []types.Log
+		logs, err = filterer.FilterLogs(ctx, page)
+		if err == nil {
+			return logs, nil
+		}
+
+		if isLimitExceeded(err) && from < to {
+			mid := from + (to-from)/2
+			first, err := filterPage(ctx, filterer, q, from, mid)
+			if err != nil {
+				return first, err
+			}
+			second, err := filterPage(ctx, filterer, q, mid+1, to)
+			return append(first, second...), err
+		}
+
+		if attempt < filterLogsRetries {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(filterLogsBackoff):
+			}
+		}
+	}
+	return nil, fmt.Errorf("failed to filter logs of blocks %d to %d: %w", from, to, err)
+}
+
+func isLimitExceeded(err error) bool {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == limitExceededCode {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"more than 10000 results", "query returned more than", "block range", "range is too large", "too many results"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterLogsPaged executes a filter query over a large block range in pages of at most maxRange blocks.
+// Every page gets the call timeout of the blockchain, see `FilterLogsPaged` for the details.
+func (bc *Blockchain) FilterLogsPaged(q ethereum.FilterQuery, maxRange uint64) ([]types.Log, error) {
+	return FilterLogsPaged(bc.context(), &timeoutLogFilterer{ec: bc.ethClient.Client(), timeout: bc.bcTimeout}, q, maxRange)
+}
+
+type timeoutLogFilterer struct {
+	ec      EtherClient
+	timeout time.Duration
+}
+
+func (f *timeoutLogFilterer) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
+	defer cancel()
+	return f.ec.FilterLogs(ctx, q)
+}
+
+func (f *timeoutLogFilterer) BlockNumber(ctx context.Context) (uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
+	defer cancel()
+	return f.ec.BlockNumber(ctx)
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ PagedLogFilterer = (EtherClient)(nil)
+
+type pagedLogFiltererMock struct {
+	latest   uint64
+	maxLogs  int
+	failures int
+	pages    [][2]uint64
+}
+
+func (m *pagedLogFiltererMock) FilterLogs(_ context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	from, to := q.FromBlock.Uint64(), q.ToBlock.Uint64()
+	m.pages = append(m.pages, [2]uint64{from, to})
+	if m.failures > 0 {
+		m.failures--
+		return nil, errors.New("502 bad gateway")
+	}
+
+	// Every block has a single log.
+	if int(to-from+1) > m.maxLogs {
+		return nil, errors.New("query returned more than 10000 results")
+	}
+	var logs []types.Log
+	for n := from; n <= to; n++ {
+		logs = append(logs, types.Log{BlockNumber: n})
+	}
+	return logs, nil
+}
+
+func (m *pagedLogFiltererMock) BlockNumber(_ context.Context) (uint64, error) {
+	return m.latest, nil
+}
+
+func Test_FilterLogsPaged(t *testing.T) {
+	defer func(backoff time.Duration) { filterLogsBackoff = backoff }(filterLogsBackoff)
+	filterLogsBackoff = 0
+
+	f := &pagedLogFiltererMock{latest: 24, maxLogs: 100}
+	logs, err := FilterLogsPaged(context.Background(), f, ethereum.FilterQuery{FromBlock: big.NewInt(5)}, 10)
+	assert.NoError(t, err)
+	assert.Len(t, logs, 20)
+	assert.Equal(t, uint64(5), logs[0].BlockNumber)
+	assert.Equal(t, uint64(24), logs[19].BlockNumber)
+	assert.Equal(t, [][2]uint64{{5, 14}, {15, 24}}, f.pages)
+
+	// Pages with too many results are split, failures are retried.
+	f = &pagedLogFiltererMock{latest: 24, maxLogs: 5, failures: 1}
+	logs, err = FilterLogsPaged(context.Background(), f, ethereum.FilterQuery{FromBlock: big.NewInt(5), ToBlock: big.NewInt(14)}, 10)
+	assert.NoError(t, err)
+	assert.Len(t, logs, 10)
+	assert.Equal(t, [][2]uint64{{5, 14}, {5, 14}, {5, 9}, {10, 14}}, f.pages)
+
+	f = &pagedLogFiltererMock{latest: 24, maxLogs: 100, failures: filterLogsRetries + 1}
+	_, err = FilterLogsPaged(context.Background(), f, ethereum.FilterQuery{}, 0)
+	assert.ErrorContains(t, err, "bad gateway")
+	assert.Len(t, f.pages, filterLogsRetries+1)
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PrometheusMetrics reports the requests of instrumented clients as prometheus metrics:
+// requests, failures, latency and in-flight requests per endpoint and JSON-RPC method.
+// Lookups of missing data, such as receipts of pending transactions, are not failures.
+type PrometheusMetrics struct {
+	requests *prometheus.CounterVec
+	failures *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	inFlight *prometheus.GaugeVec
+}
+
+// NewPrometheusMetrics returns new prometheus metrics of the clients registered with the registerer.
+func NewPrometheusMetrics(namespace string, reg prometheus.Registerer) (*PrometheusMetrics, error) {
+	m := &PrometheusMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "rpc_client",
+			Name:      "requests_total",
+			Help:      "Number of RPC requests.",
+		}, []string{"endpoint", "method"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "rpc_client",
+			Name:      "failures_total",
+			Help:      "Number of failed RPC requests.",
+		}, []string{"endpoint", "method"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "rpc_client",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of RPC requests.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"endpoint", "method"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "rpc_client",
+			Name:      "requests_in_flight",
+			Help:      "Number of RPC requests waiting for a response.",
+		}, []string{"endpoint", "method"}),
+	}
+
+	for _, c := range []prometheus.Collector{m.requests, m.failures, m.latency, m.inFlight} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// RequestStarted records the request as in flight.
+func (m *PrometheusMetrics) RequestStarted(endpoint, method string) {
+	m.inFlight.WithLabelValues(endpoint, method).Inc()
+}
+
+// RequestFinished records the request.
+func (m *PrometheusMetrics) RequestFinished(endpoint, method string, latency time.Duration, err error) {
+	m.inFlight.WithLabelValues(endpoint, method).Dec()
+	m.requests.WithLabelValues(endpoint, method).Inc()
+	m.latency.WithLabelValues(endpoint, method).Observe(latency.Seconds())
+	if err != nil && !errors.Is(err, ethereum.NotFound) {
+		m.failures.WithLabelValues(endpoint, method).Inc()
+	}
+}
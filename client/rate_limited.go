@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimitedClient wraps the eth client of an endpoint and keeps its requests within
+// a requests per second budget, so bursty jobs do not get the API key banned.
+// Requests over the budget wait for their turn and are served in arrival order.
+type RateLimitedClient struct {
+	client   EthClientGetter
+	interval time.Duration
+	burst    int
+
+	mu   sync.Mutex
+	next time.Time
+	now  func() time.Time
+}
+
+// NewRateLimitedClient returns a new client allowing the given number of requests per second,
+// of which up to burst requests can be sent at once after a quiet period.
+func NewRateLimitedClient(next EthClientGetter, requestsPerSecond float64, burst int) *RateLimitedClient {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimitedClient{
+		client:   next,
+		interval: time.Duration(float64(time.Second) / requestsPerSecond),
+		burst:    burst,
+		now:      time.Now,
+	}
+}
+
+// Client returns the wrapped client which waits for the budget before every request.
+func (r *RateLimitedClient) Client() EtherClient {
+	return &interceptedClient{EtherClient: r.client.Client(), intercept: r.intercept}
+}
+
+// Address returns the address of the wrapped client, if it has one,
+// so endpoints can be rate limited before they are given to the `EthMultiClient`.
+func (r *RateLimitedClient) Address() string {
+	if a, ok := r.client.(AddressableEthClientGetter); ok {
+		return a.Address()
+	}
+	return ""
+}
+
+func (r *RateLimitedClient) intercept(ctx context.Context, _ string, call func(context.Context) error) error {
+	if wait := r.reserve(); wait > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+	return call(ctx)
+}
+
+// reserve reserves the next free slot of the budget and returns the time until it.
+func (r *RateLimitedClient) reserve() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	// Slots which were not used during a quiet period are kept for a burst.
+	if earliest := now.Add(-time.Duration(r.burst-1) * r.interval); r.next.Before(earliest) {
+		r.next = earliest
+	}
+
+	wait := r.next.Sub(now)
+	r.next = r.next.Add(r.interval)
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/client/mocks"
+)
+
+var _ AddressableEthClientGetter = (*RateLimitedClient)(nil)
+
+func TestRateLimitedClient(t *testing.T) {
+	cl := &mocks.EtherClientMock{BalanceAtFunc: func(_ context.Context, _ common.Address, _ *big.Int) (*big.Int, error) {
+		return big.NewInt(1), nil
+	}}
+	r := NewRateLimitedClient(NewDefaultAddressableEthClientGetter("http://node", cl), 10, 2)
+	assert.Equal(t, "http://node", r.Address())
+
+	now := time.Now()
+	r.now = func() time.Time { return now }
+	assert.Equal(t, time.Duration(0), r.reserve())
+	assert.Equal(t, time.Duration(0), r.reserve(), "burst")
+	assert.Equal(t, 100*time.Millisecond, r.reserve())
+	assert.Equal(t, 200*time.Millisecond, r.reserve(), "requests queue up in order")
+
+	now = now.Add(time.Second)
+	assert.Equal(t, time.Duration(0), r.reserve(), "the budget refills once quiet")
+	assert.Equal(t, time.Duration(0), r.reserve())
+	assert.Equal(t, 100*time.Millisecond, r.reserve())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r = NewRateLimitedClient(NewDefaultEthClientGetter(cl), 0.001, 1)
+	_, err := r.Client().BalanceAt(ctx, common.Address{}, nil)
+	assert.NoError(t, err, "the first request is within the budget")
+	_, err = r.Client().BalanceAt(ctx, common.Address{}, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, cl.BalanceAtCalls(), 1)
+}
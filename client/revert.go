@@ -0,0 +1,172 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrRevertNotReproduced is returned when a reverted transaction does not revert when replayed.
+var ErrRevertNotReproduced = errors.New("revert not reproduced")
+
+var (
+	errorStringSelector = common.FromHex("0x08c379a0")
+	panicSelector       = common.FromHex("0x4e487b71")
+)
+
+// defaultRevertDecoder decodes the revert data of the builtin errors only.
+var defaultRevertDecoder = NewRevertDecoder()
+
+// RevertDecoder turns the revert data of contract calls into human readable messages.
+// Custom errors are decoded using the ABIs of the known contracts.
+type RevertDecoder struct {
+	errors map[[4]byte]abi.Error
+}
+
+// NewRevertDecoder returns a new decoder of the custom errors of the given contract ABIs,
+// e.g. the one returned by `bindings.HermesImplementationMetaData.GetAbi()`.
+func NewRevertDecoder(abis ...*abi.ABI) *RevertDecoder {
+	d := &RevertDecoder{errors: make(map[[4]byte]abi.Error)}
+	for _, a := range abis {
+		for _, e := range a.Errors {
+			var id [4]byte
+			copy(id[:], e.ID[:4])
+			d.errors[id] = e
+		}
+	}
+	return d
+}
+
+// DecodeRevert decodes revert data given by an `Error(string)` or a `Panic(uint256)`, see `RevertDecoder.Decode`.
+func DecodeRevert(data []byte) (string, bool) {
+	return defaultRevertDecoder.Decode(data)
+}
+
+// Decode returns the message of the revert data: the reason given to an `Error(string)`,
+// the reason of a `Panic(uint256)` prefixed with "panic: " or a known custom error with its
+// arguments, e.g. "InsufficientBalance(available: 1, required: 2)". It returns false if
+// the data is empty or can't be decoded.
+func (d *RevertDecoder) Decode(data []byte) (string, bool) {
+	if len(data) < 4 {
+		return "", false
+	}
+
+	if bytes.Equal(data[:4], errorStringSelector) || bytes.Equal(data[:4], panicSelector) {
+		reason, err := abi.UnpackRevert(data)
+		if err != nil {
+			return "", false
+		}
+		if bytes.Equal(data[:4], panicSelector) {
+			return "panic: " + reason, true
+		}
+		return reason, true
+	}
+
+	var id [4]byte
+	copy(id[:], data[:4])
+	e, ok := d.errors[id]
+	if !ok {
+		return "", false
+	}
+	values, err := e.Inputs.Unpack(data[4:])
+	if err != nil {
+		return "", false
+	}
+
+	args := make([]string, len(values))
+	for i, v := range values {
+		args[i] = fmt.Sprint(v)
+		if name := e.Inputs[i].Name; name != "" {
+			args[i] = name + ": " + args[i]
+		}
+	}
+	return fmt.Sprintf("%s(%s)", e.Name, strings.Join(args, ", ")), true
+}
+
+// RevertData returns the revert data of a call error if the node returned any.
+func RevertData(err error) ([]byte, bool) {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return nil, false
+	}
+	data, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return nil, false
+	}
+	b := common.FromHex(data)
+	return b, len(b) > 0
+}
+
+// DecodeError returns the revert message of a call error. It is decoded from the revert data
+// if the node returned any, taken from the error message otherwise. It is empty if the
+// revert has no reason.
+func (d *RevertDecoder) DecodeError(err error) string {
+	if data, ok := RevertData(err); ok {
+		if reason, ok := d.Decode(data); ok {
+			return reason
+		}
+	}
+
+	msg := err.Error()
+	for _, prefix := range []string{"execution reverted", "VM Exception while processing transaction: revert"} {
+		if i := strings.Index(msg, prefix); i >= 0 {
+			return strings.TrimLeft(msg[i+len(prefix):], ": ")
+		}
+	}
+	return ""
+}
+
+// RevertReplayClient is used to replay reverted transactions, `EtherClient` satisfies it.
+type RevertReplayClient interface {
+	TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error)
+	CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+// DecodeReceipt returns the revert message of the transaction of a failed receipt, which holds
+// no revert data itself. The transaction is replayed with eth_call on top of the block before
+// the one it was mined in, so the transactions mined before it in the same block are not applied.
+// If the replay does not revert, `ErrRevertNotReproduced` is returned. Successful receipts
+// have an empty message.
+func (d *RevertDecoder) DecodeReceipt(ctx context.Context, client RevertReplayClient, receipt *types.Receipt) (string, error) {
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		return "", nil
+	}
+
+	tx, _, err := client.TransactionByHash(ctx, receipt.TxHash)
+	if err != nil {
+		return "", fmt.Errorf("could not get transaction %v: %w", receipt.TxHash.Hex(), err)
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return "", fmt.Errorf("could not get sender of transaction %v: %w", receipt.TxHash.Hex(), err)
+	}
+
+	var block *big.Int
+	if receipt.BlockNumber != nil && receipt.BlockNumber.Sign() > 0 {
+		block = new(big.Int).Sub(receipt.BlockNumber, common.Big1)
+	}
+	_, err = client.CallContract(ctx, ethereum.CallMsg{
+		From:       from,
+		To:         tx.To(),
+		Gas:        tx.Gas(),
+		Value:      tx.Value(),
+		Data:       tx.Data(),
+		AccessList: tx.AccessList(),
+	}, block)
+	if err == nil {
+		return "", fmt.Errorf("%w: transaction %v", ErrRevertNotReproduced, receipt.TxHash.Hex())
+	}
+	if !errors.Is(ClassifyError(err), ErrExecutionReverted) {
+		return "", fmt.Errorf("could not replay transaction %v: %w", receipt.TxHash.Hex(), err)
+	}
+	return d.DecodeError(err), nil
+}
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/payments/client/mocks"
+)
+
+const customErrorsABI = `[{"type":"error","name":"InsufficientBalance","inputs":[{"name":"available","type":"uint256"},{"name":"required","type":"uint256"}]}]`
+
+func TestRevertDecoder(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(customErrorsABI))
+	require.NoError(t, err)
+	d := NewRevertDecoder(&parsed)
+
+	customErr := parsed.Errors["InsufficientBalance"]
+	args, err := customErr.Inputs.Pack(big.NewInt(1), big.NewInt(2))
+	require.NoError(t, err)
+	custom := append(customErr.ID[:4:4], args...)
+
+	uint256, err := abi.NewType("uint256", "", nil)
+	require.NoError(t, err)
+	code, err := abi.Arguments{{Type: uint256}}.Pack(big.NewInt(0x11))
+	require.NoError(t, err)
+	panicData := append(common.FromHex("0x4e487b71"), code...)
+
+	str, err := abi.NewType("string", "", nil)
+	require.NoError(t, err)
+	reason, err := abi.Arguments{{Type: str}}.Pack("not owner")
+	require.NoError(t, err)
+	errorData := append(common.FromHex("0x08c379a0"), reason...)
+
+	for name, tc := range map[string]struct {
+		data []byte
+		want string
+		ok   bool
+	}{
+		"error string": {data: errorData, want: "not owner", ok: true},
+		"panic":        {data: panicData, want: "panic: arithmetic underflow or overflow", ok: true},
+		"custom error": {data: custom, want: "InsufficientBalance(available: 1, required: 2)", ok: true},
+		"unknown":      {data: common.FromHex("0xdeadbeef")},
+		"empty":        {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, ok := d.Decode(tc.data)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	_, ok := DecodeRevert(custom)
+	assert.False(t, ok, "custom errors need their ABI")
+
+	assert.Equal(t, "InsufficientBalance(available: 1, required: 2)", d.DecodeError(revertErrorMock{data: hexutil.Encode(custom)}))
+	assert.Equal(t, "not owner", d.DecodeError(errors.New("VM Exception while processing transaction: revert not owner")))
+	assert.Equal(t, "", d.DecodeError(errors.New("execution reverted")))
+}
+
+func TestRevertDecoder_DecodeReceipt(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	to := common.HexToAddress("0x2")
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	tx, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{ChainID: big.NewInt(1), Nonce: 3, To: &to, Gas: 50000, Data: []byte{1}})
+	require.NoError(t, err)
+
+	var callMsg ethereum.CallMsg
+	var callBlock *big.Int
+	var callErr error
+	cl := &mocks.EtherClientMock{
+		TransactionByHashFunc: func(_ context.Context, _ common.Hash) (*types.Transaction, bool, error) {
+			return tx, false, nil
+		},
+		CallContractFunc: func(_ context.Context, msg ethereum.CallMsg, block *big.Int) ([]byte, error) {
+			callMsg, callBlock = msg, block
+			return nil, callErr
+		},
+	}
+
+	receipt := &types.Receipt{TxHash: tx.Hash(), Status: types.ReceiptStatusFailed, BlockNumber: big.NewInt(100)}
+	callErr = errors.New("execution reverted: not owner")
+	msg, err := defaultRevertDecoder.DecodeReceipt(context.Background(), cl, receipt)
+	assert.NoError(t, err)
+	assert.Equal(t, "not owner", msg)
+	assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey), callMsg.From)
+	assert.Equal(t, uint64(50000), callMsg.Gas)
+	assert.Equal(t, big.NewInt(99), callBlock)
+
+	callErr = nil
+	_, err = defaultRevertDecoder.DecodeReceipt(context.Background(), cl, receipt)
+	assert.ErrorIs(t, err, ErrRevertNotReproduced)
+
+	callErr = errors.New("connection refused")
+	_, err = defaultRevertDecoder.DecodeReceipt(context.Background(), cl, receipt)
+	assert.ErrorContains(t, err, "connection refused")
+
+	msg, err = defaultRevertDecoder.DecodeReceipt(context.Background(), cl, &types.Receipt{Status: types.ReceiptStatusSuccessful})
+	assert.NoError(t, err)
+	assert.Empty(t, msg)
+}
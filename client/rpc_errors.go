@@ -0,0 +1,100 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	// ErrInsufficientFunds is returned by nodes when the sender cannot pay for a transaction.
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrNonceTooLow is returned by nodes when the nonce of a transaction was already used.
+	ErrNonceTooLow = errors.New("nonce too low")
+	// ErrReplacementUnderpriced is returned by nodes when a transaction does not pay enough
+	// to replace the pending one with the same nonce or to enter the mempool at all.
+	ErrReplacementUnderpriced = errors.New("replacement transaction underpriced")
+	// ErrAlreadyKnown is returned by nodes when the transaction is already in their mempool.
+	ErrAlreadyKnown = errors.New("already known")
+	// ErrExecutionReverted is returned when a call or a gas estimation reverts.
+	ErrExecutionReverted = errors.New("execution reverted")
+	// ErrGasTooLow is returned when the gas limit does not cover the execution of a transaction.
+	ErrGasTooLow = errors.New("gas too low")
+	// ErrMissingState is returned by pruned nodes for state queries at blocks they no longer keep the state of.
+	ErrMissingState = errors.New("missing trie node")
+	// ErrRateLimited is returned when the provider rejects a request over the rate limit of the API key.
+	ErrRateLimited = errors.New("rate limited")
+)
+
+// revertedCode is the JSON-RPC error code geth returns for reverted executions.
+const revertedCode = 3
+
+// rpcErrorClasses maps lowercase error messages returned by the providers to the typed errors.
+// Reverts come first as their reasons can contain any other message.
+var rpcErrorClasses = []struct {
+	msg string
+	err error
+}{
+	{msg: "execution reverted", err: ErrExecutionReverted},
+	{msg: "vm exception while processing transaction: revert", err: ErrExecutionReverted},
+	{msg: "nonce too low", err: ErrNonceTooLow},
+	{msg: "nonce is too low", err: ErrNonceTooLow},
+	{msg: "nonce has already been used", err: ErrNonceTooLow},
+	{msg: "oldnonce", err: ErrNonceTooLow},
+	{msg: "replacement transaction underpriced", err: ErrReplacementUnderpriced},
+	{msg: "transaction underpriced", err: ErrReplacementUnderpriced},
+	{msg: "replacement fee too low", err: ErrReplacementUnderpriced},
+	{msg: "insufficient funds", err: ErrInsufficientFunds},
+	{msg: "insufficient balance for transfer", err: ErrInsufficientFunds},
+	{msg: "already known", err: ErrAlreadyKnown},
+	{msg: "known transaction", err: ErrAlreadyKnown},
+	{msg: "intrinsic gas too low", err: ErrGasTooLow},
+	{msg: "gas too low", err: ErrGasTooLow},
+	{msg: "out of gas", err: ErrGasTooLow},
+	{msg: "missing trie node", err: ErrMissingState},
+	{msg: "required historical state unavailable", err: ErrMissingState},
+	{msg: "historical state not available", err: ErrMissingState},
+	{msg: "state histories haven't been fully indexed", err: ErrMissingState},
+	{msg: "state is not available", err: ErrMissingState},
+	{msg: "429 too many requests", err: ErrRateLimited},
+	{msg: "too many requests", err: ErrRateLimited},
+	{msg: "rate limit", err: ErrRateLimited},
+	{msg: "request rate limited", err: ErrRateLimited},
+	{msg: "exceeded its compute units per second capacity", err: ErrRateLimited},
+	{msg: "daily request count exceeded", err: ErrRateLimited},
+}
+
+// ClassifyError wraps an error returned by a provider with the typed error matching it,
+// so the callers do not depend on the wording of Infura, Alchemy or the node in use.
+// Unknown errors are returned as is.
+func ClassifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	for _, c := range rpcErrorClasses {
+		if errors.Is(err, c.err) {
+			return err
+		}
+	}
+
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("%w: %w", ErrRateLimited, err)
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == revertedCode {
+		return fmt.Errorf("%w: %w", ErrExecutionReverted, err)
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, c := range rpcErrorClasses {
+		if strings.Contains(msg, c.msg) {
+			return fmt.Errorf("%w: %w", c.err, err)
+		}
+	}
+	return err
+}
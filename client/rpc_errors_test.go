@@ -0,0 +1,50 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+type rpcErrorMock struct {
+	code int
+	msg  string
+}
+
+func (e *rpcErrorMock) Error() string  { return e.msg }
+func (e *rpcErrorMock) ErrorCode() int { return e.code }
+
+func TestClassifyError(t *testing.T) {
+	for _, test := range []struct {
+		err  error
+		want error
+	}{
+		{err: errors.New("insufficient funds for gas * price + value"), want: ErrInsufficientFunds},
+		{err: errors.New("nonce too low: next nonce 5, tx nonce 4"), want: ErrNonceTooLow},
+		{err: errors.New("Nonce has already been used"), want: ErrNonceTooLow},
+		{err: errors.New("replacement transaction underpriced"), want: ErrReplacementUnderpriced},
+		{err: errors.New("transaction underpriced: tip needed 30, tip permitted 1"), want: ErrReplacementUnderpriced},
+		{err: errors.New("execution reverted: ERC20: transfer amount exceeds balance"), want: ErrExecutionReverted},
+		{err: &rpcErrorMock{code: 3, msg: "reverted"}, want: ErrExecutionReverted},
+		{err: errors.New("intrinsic gas too low: have 21000, want 53000"), want: ErrGasTooLow},
+		{err: errors.New("missing trie node 5f3f1fb5de2f7b0b5a2cba1d4fd3d0c5e0bb3a1a2b8e0c6a4e8b1e0aa7dc8b21 (path ) state 0x5f3f is not available"), want: ErrMissingState},
+		{err: errors.New("429 Too Many Requests: {\"jsonrpc\":\"2.0\"}"), want: ErrRateLimited},
+		{err: errors.New("Your app has exceeded its compute units per second capacity"), want: ErrRateLimited},
+		{err: fmt.Errorf("call failed: %w", rpc.HTTPError{StatusCode: 429, Status: "429"}), want: ErrRateLimited},
+		{err: errors.New("already known"), want: ErrAlreadyKnown},
+	} {
+		t.Run(test.err.Error(), func(t *testing.T) {
+			err := ClassifyError(test.err)
+			assert.ErrorIs(t, err, test.want)
+			assert.ErrorIs(t, err, test.err)
+		})
+	}
+
+	unknown := errors.New("header not found")
+	assert.Equal(t, unknown, ClassifyError(unknown))
+	assert.Equal(t, ErrGasTooLow, ClassifyError(ErrGasTooLow))
+	assert.NoError(t, ClassifyError(nil))
+}
@@ -0,0 +1,228 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/mysteriumnetwork/payments/bindings"
+)
+
+// ErrNotBroadcast is wrapped by the error returned for transactions intercepted by the sandbox.
+var ErrNotBroadcast = errors.New("transaction not broadcast in dry-run mode")
+
+// DecodedCall is the contract call decoded from the transaction calldata.
+type DecodedCall struct {
+	Contract string                 `json:"contract"`
+	Method   string                 `json:"method"`
+	Args     map[string]interface{} `json:"args"`
+}
+
+// SandboxReport describes a transaction that would have been broadcast.
+type SandboxReport struct {
+	ChainID  *big.Int        `json:"chainID"`
+	Hash     common.Hash     `json:"hash"`
+	From     common.Address  `json:"from"`
+	To       *common.Address `json:"to"`
+	Nonce    uint64          `json:"nonce"`
+	Value    *big.Int        `json:"value"`
+	Data     []byte          `json:"data"`
+	GasLimit uint64          `json:"gasLimit"`
+
+	// Call is the decoded calldata, nil if it does not match any known contract.
+	Call *DecodedCall `json:"call,omitempty"`
+
+	// MaxFee is the gas limit times the fee cap, the most the transaction can cost in gas.
+	MaxFee *big.Int `json:"maxFee"`
+	// EstimatedFee is the gas limit times the price the transaction would pay at the latest base fee.
+	EstimatedFee *big.Int `json:"estimatedFee,omitempty"`
+
+	// SimulationErr holds the error of simulating the transaction at the latest block, empty if it succeeded.
+	SimulationErr string `json:"simulationError,omitempty"`
+}
+
+// SandboxError is returned instead of broadcasting a transaction in dry-run mode.
+type SandboxError struct {
+	Report SandboxReport
+}
+
+// Error returns the error message.
+func (e *SandboxError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrNotBroadcast, e.Report.Hash.Hex())
+}
+
+// Unwrap returns `ErrNotBroadcast`.
+func (e *SandboxError) Unwrap() error {
+	return ErrNotBroadcast
+}
+
+type namedABI struct {
+	name string
+	abi  *abi.ABI
+}
+
+// Sandbox is a package wide dry-run mode. It wraps the eth client used by
+// the `Blockchain`, so every flow still runs its validation, gas estimation
+// and signing, while the signed transactions are simulated and reported
+// instead of being broadcast.
+type Sandbox struct {
+	next    EthClientGetter
+	enabled atomic.Bool
+
+	abis      []namedABI
+	reports   []SandboxReport
+	listeners []func(SandboxReport)
+	mu        sync.Mutex
+}
+
+// NewSandbox returns a sandbox wrapping the given client, starting in the given mode.
+// Calldata of the registry, hermes, channel and token contracts is decoded out of the box.
+func NewSandbox(next EthClientGetter, enabled bool) *Sandbox {
+	s := &Sandbox{next: next}
+	s.enabled.Store(enabled)
+	for _, c := range []struct {
+		name string
+		md   *bind.MetaData
+	}{
+		{"Registry", bindings.RegistryMetaData},
+		{"HermesImplementation", bindings.HermesImplementationMetaData},
+		{"ChannelImplementation", bindings.ChannelImplementationMetaData},
+		{"MystToken", bindings.MystTokenMetaData},
+	} {
+		if parsed, err := c.md.GetAbi(); err == nil {
+			s.abis = append(s.abis, namedABI{name: c.name, abi: parsed})
+		}
+	}
+	return s
+}
+
+// AddABI adds a contract ABI used to decode calldata.
+//
+// This method is not thread safe and should be called before any transaction is sent.
+func (s *Sandbox) AddABI(name string, contract abi.ABI) {
+	s.abis = append(s.abis, namedABI{name: name, abi: &contract})
+}
+
+// OnReport registers a listener which is called for every intercepted transaction.
+//
+// This method is not thread safe and should be called before any transaction is sent.
+func (s *Sandbox) OnReport(fn func(SandboxReport)) {
+	s.listeners = append(s.listeners, fn)
+}
+
+// SetEnabled toggles the dry-run mode at runtime.
+func (s *Sandbox) SetEnabled(enabled bool) {
+	s.enabled.Store(enabled)
+}
+
+// Enabled returns true while transactions are not broadcast.
+func (s *Sandbox) Enabled() bool {
+	return s.enabled.Load()
+}
+
+// Reports returns the reports of all intercepted transactions.
+func (s *Sandbox) Reports() []SandboxReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SandboxReport(nil), s.reports...)
+}
+
+// Client returns the wrapped client which intercepts sending while the dry-run mode is enabled.
+func (s *Sandbox) Client() EtherClient {
+	return &sandboxClient{EtherClient: s.next.Client(), s: s}
+}
+
+type sandboxClient struct {
+	EtherClient
+	s *Sandbox
+}
+
+// SendTransaction sends the transaction, or simulates and reports it in dry-run mode.
+func (c *sandboxClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if !c.s.Enabled() {
+		return c.EtherClient.SendTransaction(ctx, tx)
+	}
+
+	report, err := c.s.report(ctx, c.EtherClient, tx)
+	if err != nil {
+		return err
+	}
+
+	c.s.mu.Lock()
+	c.s.reports = append(c.s.reports, report)
+	c.s.mu.Unlock()
+	for _, fn := range c.s.listeners {
+		fn(report)
+	}
+	return &SandboxError{Report: report}
+}
+
+func (s *Sandbox) report(ctx context.Context, ec EtherClient, tx *types.Transaction) (SandboxReport, error) {
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return SandboxReport{}, fmt.Errorf("failed to recover transaction sender: %w", err)
+	}
+
+	report := SandboxReport{
+		ChainID:  tx.ChainId(),
+		Hash:     tx.Hash(),
+		From:     from,
+		To:       tx.To(),
+		Nonce:    tx.Nonce(),
+		Value:    tx.Value(),
+		Data:     tx.Data(),
+		GasLimit: tx.Gas(),
+		Call:     s.decode(tx.Data()),
+		MaxFee:   new(big.Int).Mul(tx.GasFeeCap(), new(big.Int).SetUint64(tx.Gas())),
+	}
+
+	if header, err := ec.HeaderByNumber(ctx, nil); err == nil && header.BaseFee != nil {
+		report.EstimatedFee = new(big.Int).Mul(tx.EffectiveGasTipValue(header.BaseFee), new(big.Int).SetUint64(tx.Gas()))
+		report.EstimatedFee.Add(report.EstimatedFee, new(big.Int).Mul(header.BaseFee, new(big.Int).SetUint64(tx.Gas())))
+	}
+
+	msg := ethereum.CallMsg{
+		From:      from,
+		To:        tx.To(),
+		Gas:       tx.Gas(),
+		GasFeeCap: tx.GasFeeCap(),
+		GasTipCap: tx.GasTipCap(),
+		Value:     tx.Value(),
+		Data:      tx.Data(),
+	}
+	if tx.Type() == types.LegacyTxType {
+		msg.GasPrice, msg.GasFeeCap, msg.GasTipCap = tx.GasPrice(), nil, nil
+	}
+	if _, err := ec.CallContract(ctx, msg, nil); err != nil {
+		report.SimulationErr = err.Error()
+	}
+
+	return report, nil
+}
+
+func (s *Sandbox) decode(data []byte) *DecodedCall {
+	if len(data) < 4 {
+		return nil
+	}
+	for _, c := range s.abis {
+		method, err := c.abi.MethodById(data[:4])
+		if err != nil {
+			continue
+		}
+		args := make(map[string]interface{})
+		if err := method.Inputs.UnpackIntoMap(args, data[4:]); err != nil {
+			continue
+		}
+		return &DecodedCall{Contract: c.name, Method: method.Name, Args: args}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/bindings"
+	"github.com/mysteriumnetwork/payments/client/mocks"
+)
+
+func TestSandbox(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	token, err := bindings.MystTokenMetaData.GetAbi()
+	assert.NoError(t, err)
+	recipient := common.HexToAddress("0x2")
+	data, err := token.Pack("transfer", recipient, big.NewInt(100))
+	assert.NoError(t, err)
+
+	to := common.HexToAddress("0x1")
+	chainID := big.NewInt(137)
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID), &types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     1,
+		GasTipCap: big.NewInt(2),
+		GasFeeCap: big.NewInt(20),
+		Gas:       50000,
+		To:        &to,
+		Data:      data,
+	})
+	assert.NoError(t, err)
+
+	sent := 0
+	var simulated ethereum.CallMsg
+	ec := &mocks.EtherClientMock{
+		SendTransactionFunc: func(context.Context, *types.Transaction) error {
+			sent++
+			return nil
+		},
+		HeaderByNumberFunc: func(context.Context, *big.Int) (*types.Header, error) {
+			return &types.Header{BaseFee: big.NewInt(8)}, nil
+		},
+		CallContractFunc: func(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+			simulated = msg
+			return nil, errors.New("execution reverted: insufficient balance")
+		},
+	}
+
+	s := NewSandbox(NewDefaultEthClientGetter(ec), true)
+	var listened []SandboxReport
+	s.OnReport(func(r SandboxReport) { listened = append(listened, r) })
+
+	t.Run("reports instead of sending", func(t *testing.T) {
+		err := s.Client().SendTransaction(context.Background(), tx)
+		assert.ErrorIs(t, err, ErrNotBroadcast)
+		assert.Equal(t, 0, sent)
+
+		var sbErr *SandboxError
+		assert.True(t, errors.As(err, &sbErr))
+		r := sbErr.Report
+		assert.Equal(t, from, r.From)
+		assert.Equal(t, &to, r.To)
+		assert.Equal(t, "1000000", r.MaxFee.String())
+		assert.Equal(t, "500000", r.EstimatedFee.String())
+		assert.Equal(t, "execution reverted: insufficient balance", r.SimulationErr)
+		assert.Equal(t, from, simulated.From)
+
+		assert.NotNil(t, r.Call)
+		assert.Equal(t, "MystToken", r.Call.Contract)
+		assert.Equal(t, "transfer", r.Call.Method)
+		assert.Equal(t, recipient, r.Call.Args["recipient"])
+
+		assert.Len(t, s.Reports(), 1)
+		assert.Len(t, listened, 1)
+	})
+
+	t.Run("sends when disabled", func(t *testing.T) {
+		s.SetEnabled(false)
+		assert.NoError(t, s.Client().SendTransaction(context.Background(), tx))
+		assert.Equal(t, 1, sent)
+		assert.Len(t, s.Reports(), 1)
+	})
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// SimulationRevertedError is returned when a transaction simulated before sending would revert.
+// It matches `ErrExecutionReverted`.
+type SimulationRevertedError struct {
+	// Reason is the decoded revert reason, empty if the contract did not give one.
+	Reason string
+	Err    error
+}
+
+func (e *SimulationRevertedError) Error() string {
+	if e.Reason == "" {
+		return "transaction would revert"
+	}
+	return fmt.Sprintf("transaction would revert: %s", e.Reason)
+}
+
+func (e *SimulationRevertedError) Unwrap() []error {
+	return []error{ErrExecutionReverted, e.Err}
+}
+
+// SimulateTransaction executes the transaction with eth_call using its exact payload at the
+// latest block. If it would revert, a `SimulationRevertedError` with the reason is returned.
+func SimulateTransaction(ctx context.Context, caller ethereum.ContractCaller, from common.Address, tx *types.Transaction) error {
+	_, err := caller.CallContract(ctx, ethereum.CallMsg{
+		From:  from,
+		To:    tx.To(),
+		Gas:   tx.Gas(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}, nil)
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(ClassifyError(err), ErrExecutionReverted) {
+		return &SimulationRevertedError{Reason: defaultRevertDecoder.DecodeError(err), Err: err}
+	}
+	return fmt.Errorf("could not simulate transaction: %w", err)
+}
+
+// simulatingSigner returns a signer which simulates every transaction before signing it,
+// so a transaction which would revert is never sent.
+func (bc *Blockchain) simulatingSigner(ctx context.Context, signer bind.SignerFn) bind.SignerFn {
+	return func(from common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		if err := SimulateTransaction(ctx, bc.ethClient.Client(), from, tx); err != nil {
+			return nil, err
+		}
+		return signer(from, tx)
+	}
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/client/mocks"
+)
+
+type revertErrorMock struct {
+	data string
+}
+
+func (e revertErrorMock) Error() string          { return "execution reverted" }
+func (e revertErrorMock) ErrorCode() int         { return 3 }
+func (e revertErrorMock) ErrorData() interface{} { return e.data }
+
+func TestSimulate(t *testing.T) {
+	typ, err := abi.NewType("string", "", nil)
+	assert.NoError(t, err)
+	reason, err := abi.Arguments{{Type: typ}}.Pack("insufficient stake")
+	assert.NoError(t, err)
+	revertData := hexutil.Encode(append([]byte{0x08, 0xc3, 0x79, 0xa0}, reason...))
+
+	var calls []ethereum.CallMsg
+	var callErr error
+	var sent int
+	cl := &mocks.EtherClientMock{
+		CallContractFunc: func(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+			calls = append(calls, msg)
+			return nil, callErr
+		},
+		SendTransactionFunc: func(_ context.Context, _ *types.Transaction) error {
+			sent++
+			return nil
+		},
+	}
+	nonceFunc := func(_ context.Context, _ common.Address) (uint64, error) {
+		return 1, nil
+	}
+	bc := NewBlockchainWithCustomNonceTracker(NewDefaultEthClientGetter(cl), time.Second, nonceFunc)
+
+	from, recipient := common.HexToAddress("0x1"), common.HexToAddress("0x2")
+	wr := WriteRequest{
+		Identity: from,
+		GasLimit: 21000,
+		GasPrice: big.NewInt(1),
+		Simulate: true,
+		Signer: func(_ common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			return tx, nil
+		},
+	}
+
+	_, err = bc.TransferEth(EthTransferRequest{WriteRequest: wr, To: recipient, Amount: big.NewInt(5)})
+	assert.NoError(t, err)
+	assert.Len(t, calls, 1)
+	assert.Equal(t, from, calls[0].From)
+	assert.Equal(t, recipient, *calls[0].To)
+	assert.Equal(t, big.NewInt(5), calls[0].Value)
+	assert.Equal(t, 1, sent)
+
+	callErr = revertErrorMock{data: revertData}
+	_, err = bc.TransferMyst(TransferRequest{WriteRequest: wr, MystAddress: common.HexToAddress("0x3"), Recipient: recipient, Amount: big.NewInt(5)})
+	var reverted *SimulationRevertedError
+	assert.True(t, errors.As(err, &reverted))
+	assert.Equal(t, "insufficient stake", reverted.Reason)
+	assert.ErrorIs(t, err, ErrExecutionReverted)
+	assert.Len(t, calls, 2)
+	assert.NotEmpty(t, calls[1].Data)
+	assert.Equal(t, 1, sent, "reverting transactions are not sent")
+
+	callErr = errors.New("VM Exception while processing transaction: revert not owner")
+	err = SimulateTransaction(context.Background(), cl, from, types.NewTransaction(0, recipient, nil, 0, nil, nil))
+	assert.True(t, errors.As(err, &reverted))
+	assert.Equal(t, "not owner", reverted.Reason)
+
+	callErr = errors.New("connection refused")
+	err = SimulateTransaction(context.Background(), cl, from, types.NewTransaction(0, recipient, nil, 0, nil, nil))
+	assert.False(t, errors.As(err, &reverted))
+	assert.ErrorIs(t, err, callErr)
+}
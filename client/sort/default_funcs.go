@@ -24,7 +24,7 @@ import (
 	"time"
 
 	"github.com/mysteriumnetwork/payments/client"
-	"github.com/rs/zerolog/log"
+	"github.com/mysteriumnetwork/payments/logging"
 )
 
 // DefaultByBlockSize will sort on ticker by block size.
@@ -61,7 +61,7 @@ func DefaultByBlockSize(cl SortableClient) {
 	}
 
 	if len(blockNumbers) != len(clients) {
-		log.Error().Msg("failed to reorder clients, some client block numbers are missing")
+		logging.Default().Error("failed to reorder clients, some client block numbers are missing")
 		return
 	}
 
@@ -75,7 +75,7 @@ func DefaultByBlockSize(cl SortableClient) {
 	}
 
 	if err := cl.ReorderClients(newOrder); err != nil {
-		log.Error().Err(err).Msg("failed to re-order the RPC client slice")
+		logging.Default().Error("failed to re-order the RPC client slice", "error", err)
 	}
 }
 
@@ -103,7 +103,7 @@ func DefaultByAvailability(not client.Notification, clients SortableClient) {
 
 		newOrder := append(currentOrder[:i], currentOrder[i+1:]...)
 		if err := clients.ReorderClients(append(newOrder, address)); err != nil {
-			log.Error().Err(err).Msg("failed to re-order the RPC client slice")
+			logging.Default().Error("failed to re-order the RPC client slice", "error", err)
 		}
 
 		return
@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+)
+
+// RPCCaller calls JSON-RPC methods, `*rpc.Client` satisfies it.
+type RPCCaller interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// StateOverride is a set of account states replacing the chain state during a call or gas estimation.
+type StateOverride map[common.Address]gethclient.OverrideAccount
+
+// SetBalance overrides the balance of the account.
+func (o StateOverride) SetBalance(account common.Address, balance *big.Int) StateOverride {
+	acc := o[account]
+	acc.Balance = balance
+	o[account] = acc
+	return o
+}
+
+// SetStorage overrides a single storage slot of the contract, keeping the rest of its storage.
+func (o StateOverride) SetStorage(contract common.Address, slot, value common.Hash) StateOverride {
+	acc := o[contract]
+	if acc.StateDiff == nil {
+		acc.StateDiff = make(map[common.Hash]common.Hash)
+	}
+	acc.StateDiff[slot] = value
+	o[contract] = acc
+	return o
+}
+
+// SetAllowance overrides the allowance of the spender in an ERC-20 token which keeps
+// its allowances mapping at the given storage slot, the slot depends on the storage layout of the token.
+func (o StateOverride) SetAllowance(token, owner, spender common.Address, allowancesSlot uint64, amount *big.Int) StateOverride {
+	return o.SetStorage(token, AllowanceSlot(owner, spender, allowancesSlot), common.BigToHash(amount))
+}
+
+// AllowanceSlot returns the storage slot of the allowance of the spender
+// in a `mapping(address => mapping(address => uint256))` kept at the given slot.
+func AllowanceSlot(owner, spender common.Address, allowancesSlot uint64) common.Hash {
+	inner := crypto.Keccak256Hash(common.LeftPadBytes(owner.Bytes(), 32), common.BigToHash(new(big.Int).SetUint64(allowancesSlot)).Bytes())
+	return crypto.Keccak256Hash(common.LeftPadBytes(spender.Bytes(), 32), inner.Bytes())
+}
+
+// CallContractWithOverrides executes eth_call at the given block, the latest if it is nil, with the state overrides applied.
+func CallContractWithOverrides(ctx context.Context, client RPCCaller, msg ethereum.CallMsg, blockNumber *big.Int, overrides StateOverride) ([]byte, error) {
+	var res hexutil.Bytes
+	err := client.CallContext(ctx, &res, "eth_call", toCallArg(msg), toBlockNumArg(blockNumber), overrides)
+	return res, err
+}
+
+// EstimateGasWithOverrides executes eth_estimateGas at the latest block with the state overrides applied,
+// so a settlement can be estimated before the approval it depends on is mined.
+// The node has to support overrides for gas estimations, as geth does since v1.13.
+func EstimateGasWithOverrides(ctx context.Context, client RPCCaller, msg ethereum.CallMsg, overrides StateOverride) (uint64, error) {
+	var res hexutil.Uint64
+	err := client.CallContext(ctx, &res, "eth_estimateGas", toCallArg(msg), "latest", overrides)
+	return uint64(res), err
+}
+
+func toCallArg(msg ethereum.CallMsg) interface{} {
+	arg := map[string]interface{}{
+		"from": msg.From,
+		"to":   msg.To,
+	}
+	if len(msg.Data) > 0 {
+		arg["input"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+	if msg.Gas != 0 {
+		arg["gas"] = hexutil.Uint64(msg.Gas)
+	}
+	if msg.GasPrice != nil {
+		arg["gasPrice"] = (*hexutil.Big)(msg.GasPrice)
+	}
+	if msg.GasFeeCap != nil {
+		arg["maxFeePerGas"] = (*hexutil.Big)(msg.GasFeeCap)
+	}
+	if msg.GasTipCap != nil {
+		arg["maxPriorityFeePerGas"] = (*hexutil.Big)(msg.GasTipCap)
+	}
+	return arg
+}
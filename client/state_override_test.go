@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+type rpcCallerMock struct {
+	method string
+	args   []interface{}
+	result string
+}
+
+func (m *rpcCallerMock) CallContext(_ context.Context, result interface{}, method string, args ...interface{}) error {
+	m.method, m.args = method, args
+	return json.Unmarshal([]byte(m.result), result)
+}
+
+func TestStateOverrides(t *testing.T) {
+	token, owner, spender := common.HexToAddress("0x1"), common.HexToAddress("0x2"), common.HexToAddress("0x3")
+	overrides := StateOverride{}.
+		SetBalance(owner, big.NewInt(1e18)).
+		SetAllowance(token, owner, spender, 1, big.NewInt(5))
+
+	m := &rpcCallerMock{result: `"0x5208"`}
+	gas, err := EstimateGasWithOverrides(context.Background(), m, ethereum.CallMsg{From: spender, To: &token, Data: []byte{1}}, overrides)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(21000), gas)
+	assert.Equal(t, "eth_estimateGas", m.method)
+	assert.Equal(t, "latest", m.args[1])
+
+	b, err := json.Marshal(m.args[2])
+	assert.NoError(t, err)
+	var decoded map[common.Address]struct {
+		Balance   string                      `json:"balance"`
+		StateDiff map[common.Hash]common.Hash `json:"stateDiff"`
+	}
+	assert.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, "0xde0b6b3a7640000", decoded[owner].Balance)
+	assert.Equal(t, common.BigToHash(big.NewInt(5)), decoded[token].StateDiff[AllowanceSlot(owner, spender, 1)])
+
+	m.result = `"0x2a"`
+	res, err := CallContractWithOverrides(context.Background(), m, ethereum.CallMsg{To: &token}, big.NewInt(10), overrides)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{42}, res)
+	assert.Equal(t, "eth_call", m.method)
+	assert.Equal(t, "0xa", m.args[1])
+}
@@ -0,0 +1,231 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// recentHeads is the number of delivered head hashes kept to tell a
+// duplicate head from a head replacing another one after a reorg.
+const recentHeads = 128
+
+// SubscriptionClient is used by the subscription manager, `EtherClient` satisfies it.
+type SubscriptionClient interface {
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+	SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+}
+
+// SubscriptionManager maintains new head and log subscriptions over a websocket connection.
+// Once the provider drops a subscription it re-subscribes and backfills the heads and logs
+// of the blocks mined in the meantime, so no contract events are lost.
+type SubscriptionManager struct {
+	client         SubscriptionClient
+	reconnectDelay time.Duration
+	logFn          func(error)
+}
+
+// NewSubscriptionManager returns a new subscription manager which waits
+// the given delay before re-subscribing after a failure.
+func NewSubscriptionManager(client SubscriptionClient, reconnectDelay time.Duration) *SubscriptionManager {
+	return &SubscriptionManager{
+		client:         client,
+		reconnectDelay: reconnectDelay,
+		logFn:          func(error) {},
+	}
+}
+
+// AttachLogger allows the caller to attach an optional logger
+// which will receive the errors of dropped subscriptions.
+//
+// This method is not thread safe and should be called before subscribing.
+func (m *SubscriptionManager) AttachLogger(fn func(err error)) {
+	m.logFn = fn
+}
+
+// SubscribeNewHead sends every new head to the sink until the context is done. Heads mined
+// while the subscription was down are sent once it is re-established, in block order.
+// A head replacing an already sent one after a reorg is sent again.
+func (m *SubscriptionManager) SubscribeNewHead(ctx context.Context, sink chan<- *types.Header) error {
+	var (
+		last   *big.Int
+		recent = make(map[uint64]common.Hash)
+	)
+	send := func(h *types.Header) bool {
+		n := h.Number.Uint64()
+		if hash, ok := recent[n]; ok && hash == h.Hash() {
+			return true
+		}
+		if last != nil && n+recentHeads <= last.Uint64() {
+			return true
+		}
+
+		select {
+		case sink <- h:
+		case <-ctx.Done():
+			return false
+		}
+
+		recent[n] = h.Hash()
+		delete(recent, n-recentHeads)
+		if last == nil || h.Number.Cmp(last) > 0 {
+			last = new(big.Int).Set(h.Number)
+		}
+		return true
+	}
+
+	for {
+		heads := make(chan *types.Header)
+		sub, err := m.client.SubscribeNewHead(ctx, heads)
+		if err != nil {
+			m.logFn(fmt.Errorf("failed to subscribe to new heads: %w", err))
+			if !m.wait(ctx) {
+				return ctx.Err()
+			}
+			continue
+		}
+
+		if last != nil {
+			if err := m.backfillHeads(ctx, last, send); err != nil {
+				m.logFn(err)
+			}
+		}
+
+		if err := forwardLoop(ctx, m, sub, heads, send); err != nil {
+			return err
+		}
+		if !m.wait(ctx) {
+			return ctx.Err()
+		}
+	}
+}
+
+func (m *SubscriptionManager) backfillHeads(ctx context.Context, last *big.Int, send func(*types.Header) bool) error {
+	head, err := m.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get the latest head to backfill: %w", err)
+	}
+
+	for n := new(big.Int).Add(last, big.NewInt(1)); n.Cmp(head.Number) < 0; n.Add(n, big.NewInt(1)) {
+		h, err := m.client.HeaderByNumber(ctx, n)
+		if err != nil {
+			return fmt.Errorf("failed to backfill head %s: %w", n, err)
+		}
+		if !send(h) {
+			return ctx.Err()
+		}
+	}
+	if !send(head) {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// SubscribeFilterLogs sends every log matching the query to the sink until the context is done.
+// Logs emitted while the subscription was down are fetched with `FilterLogs` once it is
+// re-established, logs which were already sent are skipped.
+func (m *SubscriptionManager) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, sink chan<- types.Log) error {
+	// The position of the last sent log, logs are emitted in this order.
+	var (
+		sent      bool
+		lastBlock uint64
+		lastIndex uint
+	)
+	send := func(l types.Log) bool {
+		if !l.Removed && sent && (l.BlockNumber < lastBlock || (l.BlockNumber == lastBlock && l.Index <= lastIndex)) {
+			return true
+		}
+
+		select {
+		case sink <- l:
+		case <-ctx.Done():
+			return false
+		}
+
+		if !l.Removed {
+			sent, lastBlock, lastIndex = true, l.BlockNumber, l.Index
+		}
+		return true
+	}
+
+	// Logs are backfilled from the requested block on the first subscription
+	// and from the block of the last sent log once re-subscribed.
+	from := q.FromBlock
+	for {
+		logs := make(chan types.Log)
+		sub, err := m.client.SubscribeFilterLogs(ctx, q, logs)
+		if err != nil {
+			m.logFn(fmt.Errorf("failed to subscribe to logs: %w", err))
+			if !m.wait(ctx) {
+				return ctx.Err()
+			}
+			continue
+		}
+
+		if sent {
+			from = new(big.Int).SetUint64(lastBlock)
+		}
+		if from != nil {
+			backfill := q
+			backfill.FromBlock = from
+			backfill.ToBlock = nil
+			missed, err := m.client.FilterLogs(ctx, backfill)
+			if err != nil {
+				m.logFn(fmt.Errorf("failed to backfill logs from block %s: %w", from, err))
+			}
+			for _, l := range missed {
+				if !send(l) {
+					return ctx.Err()
+				}
+			}
+		} else if head, err := m.client.HeaderByNumber(ctx, nil); err == nil {
+			from = head.Number
+		} else {
+			m.logFn(fmt.Errorf("failed to get the latest head to backfill logs from: %w", err))
+		}
+
+		if err := forwardLoop(ctx, m, sub, logs, send); err != nil {
+			return err
+		}
+		if !m.wait(ctx) {
+			return ctx.Err()
+		}
+	}
+}
+
+// forwardLoop sends the items of the subscription channel until the subscription
+// is dropped, which is logged, or the context is done, which is returned.
+func forwardLoop[T any](ctx context.Context, m *SubscriptionManager, sub ethereum.Subscription, ch <-chan T, send func(T) bool) error {
+	defer sub.Unsubscribe()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-sub.Err():
+			if err != nil {
+				m.logFn(fmt.Errorf("subscription dropped: %w", err))
+			}
+			return nil
+		case v := <-ch:
+			if !send(v) {
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+func (m *SubscriptionManager) wait(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(m.reconnectDelay):
+		return true
+	}
+}
@@ -0,0 +1,175 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ SubscriptionClient = (EtherClient)(nil)
+
+type subscriptionMock struct {
+	err  chan error
+	once sync.Once
+}
+
+func newSubscriptionMock() *subscriptionMock {
+	return &subscriptionMock{err: make(chan error, 1)}
+}
+
+func (s *subscriptionMock) Unsubscribe() {
+	s.once.Do(func() { close(s.err) })
+}
+
+func (s *subscriptionMock) Err() <-chan error {
+	return s.err
+}
+
+type subscriptionClientMock struct {
+	lock    sync.Mutex
+	heads   []*types.Header
+	logs    []types.Log
+	subs    chan *subscriptionMock
+	headCh  chan<- *types.Header
+	logCh   chan<- types.Log
+	filters []ethereum.FilterQuery
+}
+
+func (m *subscriptionClientMock) SubscribeNewHead(_ context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	m.lock.Lock()
+	m.headCh = ch
+	m.lock.Unlock()
+	sub := newSubscriptionMock()
+	m.subs <- sub
+	return sub, nil
+}
+
+func (m *subscriptionClientMock) SubscribeFilterLogs(_ context.Context, _ ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	m.lock.Lock()
+	m.logCh = ch
+	m.lock.Unlock()
+	sub := newSubscriptionMock()
+	m.subs <- sub
+	return sub, nil
+}
+
+func (m *subscriptionClientMock) HeaderByNumber(_ context.Context, number *big.Int) (*types.Header, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if number == nil {
+		return m.heads[len(m.heads)-1], nil
+	}
+	return m.heads[number.Int64()], nil
+}
+
+func (m *subscriptionClientMock) FilterLogs(_ context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.filters = append(m.filters, q)
+	var res []types.Log
+	for _, l := range m.logs {
+		if l.BlockNumber >= q.FromBlock.Uint64() {
+			res = append(res, l)
+		}
+	}
+	return res, nil
+}
+
+func (m *subscriptionClientMock) mine(n int64) *types.Header {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	h := &types.Header{Number: big.NewInt(n)}
+	m.heads = append(m.heads, h)
+	return h
+}
+
+func Test_SubscriptionManagerNewHeads(t *testing.T) {
+	bc := &subscriptionClientMock{subs: make(chan *subscriptionMock, 1)}
+	for i := int64(0); i <= 2; i++ {
+		bc.mine(i)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := NewSubscriptionManager(bc, time.Millisecond)
+	sink := make(chan *types.Header)
+	done := make(chan error)
+	go func() { done <- m.SubscribeNewHead(ctx, sink) }()
+
+	sub := <-bc.subs
+	bc.headCh <- bc.heads[2]
+	assert.Equal(t, int64(2), (<-sink).Number.Int64())
+
+	// Blocks 3 and 4 are mined while the subscription is down.
+	sub.err <- errors.New("connection reset")
+	bc.mine(3)
+	bc.mine(4)
+	<-bc.subs
+	assert.Equal(t, int64(3), (<-sink).Number.Int64())
+	assert.Equal(t, int64(4), (<-sink).Number.Int64())
+
+	// The new subscription repeats the latest head, it is not sent twice.
+	bc.lock.Lock()
+	headCh := bc.headCh
+	bc.lock.Unlock()
+	headCh <- bc.heads[4]
+	headCh <- bc.mine(5)
+	assert.Equal(t, int64(5), (<-sink).Number.Int64())
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func Test_SubscriptionManagerLogs(t *testing.T) {
+	bc := &subscriptionClientMock{subs: make(chan *subscriptionMock, 1)}
+	bc.mine(0)
+	bc.mine(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := NewSubscriptionManager(bc, time.Millisecond)
+	sink := make(chan types.Log)
+	done := make(chan error)
+	go func() { done <- m.SubscribeFilterLogs(ctx, ethereum.FilterQuery{}, sink) }()
+
+	sub := <-bc.subs
+	first := types.Log{BlockNumber: 1, Index: 0}
+	bc.lock.Lock()
+	bc.logs = append(bc.logs, first)
+	logCh := bc.logCh
+	bc.lock.Unlock()
+	logCh <- first
+	assert.Equal(t, first, <-sink)
+
+	// Logs of blocks 1 and 2 are emitted while the subscription is down.
+	sub.err <- errors.New("connection reset")
+	missed := []types.Log{{BlockNumber: 1, Index: 1}, {BlockNumber: 2, Index: 0}}
+	bc.lock.Lock()
+	bc.logs = append(bc.logs, missed...)
+	bc.lock.Unlock()
+	<-bc.subs
+	assert.Equal(t, missed[0], <-sink)
+	assert.Equal(t, missed[1], <-sink)
+
+	bc.lock.Lock()
+	assert.Len(t, bc.filters, 1)
+	assert.Equal(t, big.NewInt(1), bc.filters[0].FromBlock)
+	logCh = bc.logCh
+	bc.lock.Unlock()
+
+	// Already sent logs are skipped, new ones are not.
+	next := types.Log{BlockNumber: 3, Index: 0}
+	logCh <- missed[1]
+	logCh <- next
+	assert.Equal(t, next, <-sink)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
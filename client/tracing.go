@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the instrumentation name of the spans started by the client package.
+const TracerName = "github.com/mysteriumnetwork/payments/client"
+
+// Span attributes set by the traced operations.
+const (
+	AttrChainID  = attribute.Key("chain.id")
+	AttrTxHash   = attribute.Key("tx.hash")
+	AttrEndpoint = attribute.Key("rpc.endpoint")
+	AttrMethod   = attribute.Key("rpc.method")
+)
+
+// TracedClient wraps the eth client used by the `Blockchain` and starts
+// an OpenTelemetry span for every RPC request. Requests for a transaction
+// carry its hash, so a settlement can be followed across services.
+type TracedClient struct {
+	chainID int64
+	name    string
+	next    EthClientGetter
+	tracer  trace.Tracer
+}
+
+// NewTracedClient returns a new traced client of the named endpoint of the chain.
+// A nil tracer provider uses the global one, which does nothing until it is set with `otel.SetTracerProvider`.
+func NewTracedClient(chainID int64, name string, next EthClientGetter, tp trace.TracerProvider) *TracedClient {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return &TracedClient{
+		chainID: chainID,
+		name:    name,
+		next:    next,
+		tracer:  tp.Tracer(TracerName),
+	}
+}
+
+// Client returns the wrapped client which traces its requests.
+func (t *TracedClient) Client() EtherClient {
+	return &tracedClient{interceptedClient: &interceptedClient{EtherClient: t.next.Client(), intercept: t.intercept}}
+}
+
+// Address returns the address of the wrapped client, if it has one.
+func (t *TracedClient) Address() string {
+	if a, ok := t.next.(AddressableEthClientGetter); ok {
+		return a.Address()
+	}
+	return ""
+}
+
+func (t *TracedClient) intercept(ctx context.Context, method string, call func(context.Context) error) error {
+	attrs := []attribute.KeyValue{
+		attribute.String("rpc.system", "jsonrpc"),
+		AttrMethod.String(method),
+		AttrChainID.Int64(t.chainID),
+		AttrEndpoint.String(t.name),
+	}
+	if hash, ok := ctx.Value(txHashKey{}).(common.Hash); ok {
+		attrs = append(attrs, AttrTxHash.String(hash.Hex()))
+	}
+
+	ctx, span := t.tracer.Start(ctx, method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	defer span.End()
+
+	err := call(ctx)
+	// Receipts and transactions which are not found yet are part of waiting for them, not failures.
+	if err != nil && !errors.Is(err, ethereum.NotFound) {
+		RecordSpanError(span, err)
+	}
+	return err
+}
+
+// RecordSpanError records the error on the span and marks the span as failed.
+func RecordSpanError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+type txHashKey struct{}
+
+// tracedClient passes the hash of the transaction of a request to the span.
+type tracedClient struct {
+	*interceptedClient
+}
+
+func (c *tracedClient) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	return c.interceptedClient.TransactionByHash(context.WithValue(ctx, txHashKey{}, hash), hash)
+}
+
+func (c *tracedClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return c.interceptedClient.TransactionReceipt(context.WithValue(ctx, txHashKey{}, txHash), txHash)
+}
+
+func (c *tracedClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return c.interceptedClient.SendTransaction(context.WithValue(ctx, txHashKey{}, tx.Hash()), tx)
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func spanAttrs(s sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range s.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestTracedClient(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1})
+	ec := NewTracedClient(137, "infura", NewDefaultEthClientGetter(newInstrumentedClientMock()), tp).Client()
+
+	_, err := ec.BalanceAt(context.Background(), common.Address{}, nil)
+	assert.NoError(t, err)
+	_, err = ec.TransactionReceipt(context.Background(), tx.Hash())
+	assert.ErrorIs(t, err, ethereum.NotFound)
+	assert.Error(t, ec.SendTransaction(context.Background(), tx))
+
+	spans := rec.Ended()
+	require.Len(t, spans, 3)
+
+	assert.Equal(t, "eth_getBalance", spans[0].Name())
+	assert.Equal(t, int64(137), spanAttrs(spans[0])[AttrChainID].AsInt64())
+	assert.Equal(t, "infura", spanAttrs(spans[0])[AttrEndpoint].AsString())
+	assert.NotContains(t, spanAttrs(spans[0]), AttrTxHash)
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+
+	assert.Equal(t, "eth_getTransactionReceipt", spans[1].Name())
+	assert.Equal(t, tx.Hash().Hex(), spanAttrs(spans[1])[AttrTxHash].AsString())
+	assert.Equal(t, codes.Unset, spans[1].Status().Code, "not found receipts are not failures")
+
+	assert.Equal(t, "eth_sendRawTransaction", spans[2].Name())
+	assert.Equal(t, tx.Hash().Hex(), spanAttrs(spans[2])[AttrTxHash].AsString())
+	assert.Equal(t, codes.Error, spans[2].Status().Code)
+	assert.Equal(t, "nonce too low", spans[2].Status().Description)
+}
+
+func TestTracedClient_ChildSpans(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "settle")
+	ec := NewTracedClient(1, "node", NewDefaultEthClientGetter(newInstrumentedClientMock()), tp).Client()
+	_, err := ec.BalanceAt(ctx, common.Address{}, big.NewInt(1))
+	assert.NoError(t, err)
+	parent.End()
+
+	spans := rec.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, parent.SpanContext().TraceID(), spans[0].SpanContext().TraceID())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+}
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// methodNotFoundCode is the JSON-RPC error code of methods a node does not support.
+const methodNotFoundCode = -32601
+
+// TxPoolRPCClient calls the txpool JSON-RPC methods, `*rpc.Client` satisfies it.
+type TxPoolRPCClient = RPCCaller
+
+// TxPoolContent holds the transactions of an account waiting in the mempool of a node by nonce.
+type TxPoolContent struct {
+	// Pending are the transactions executable in the next blocks.
+	Pending map[uint64]*types.Transaction
+	// Queued are the transactions which can't be executed until a nonce gap before them is filled.
+	Queued map[uint64]*types.Transaction
+}
+
+// txPoolAccount is the content of the pool of a single account keyed by decimal nonces.
+type txPoolAccount map[string]map[string]*types.Transaction
+
+// TxPoolContentFrom returns the pending and queued transactions of the account in the mempool
+// of the node using txpool_contentFrom. Nodes which don't support it fall back to txpool_content.
+func TxPoolContentFrom(ctx context.Context, client TxPoolRPCClient, account common.Address) (*TxPoolContent, error) {
+	var res txPoolAccount
+	err := client.CallContext(ctx, &res, "txpool_contentFrom", account)
+	if isMethodNotFound(err) {
+		var all map[string]map[string]map[string]*types.Transaction
+		if err := client.CallContext(ctx, &all, "txpool_content"); err != nil {
+			return nil, fmt.Errorf("could not get txpool content: %w", err)
+		}
+		res = make(txPoolAccount)
+		for status, accounts := range all {
+			for addr, txs := range accounts {
+				if common.HexToAddress(addr) == account {
+					res[status] = txs
+				}
+			}
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("could not get txpool content of %v: %w", account.Hex(), err)
+	}
+
+	pending, err := byNonce(res["pending"])
+	if err != nil {
+		return nil, err
+	}
+	queued, err := byNonce(res["queued"])
+	if err != nil {
+		return nil, err
+	}
+	return &TxPoolContent{Pending: pending, Queued: queued}, nil
+}
+
+func byNonce(txs map[string]*types.Transaction) (map[uint64]*types.Transaction, error) {
+	res := make(map[uint64]*types.Transaction, len(txs))
+	for n, tx := range txs {
+		nonce, err := strconv.ParseUint(n, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid txpool nonce %q: %w", n, err)
+		}
+		res[nonce] = tx
+	}
+	return res, nil
+}
+
+func isMethodNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == methodNotFoundCode {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "does not exist") || strings.Contains(msg, "not available") || strings.Contains(msg, "not supported")
+}
+
+// NextNonce returns the nonce following the pending transactions which continue the confirmed nonce,
+// the nonce of the next transaction of the account.
+func (c *TxPoolContent) NextNonce(confirmed uint64) uint64 {
+	next := confirmed
+	for {
+		if _, ok := c.Pending[next]; !ok {
+			return next
+		}
+		next++
+	}
+}
+
+// Gaps returns the missing nonces from the confirmed nonce up to the highest queued transaction.
+// Queued transactions are stuck until every gap before them is filled.
+func (c *TxPoolContent) Gaps(confirmed uint64) []uint64 {
+	var highest uint64
+	for n := range c.Queued {
+		if n > highest {
+			highest = n
+		}
+	}
+
+	var gaps []uint64
+	for n := confirmed; n < highest; n++ {
+		_, pending := c.Pending[n]
+		_, queued := c.Queued[n]
+		if !pending && !queued {
+			gaps = append(gaps, n)
+		}
+	}
+	return gaps
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+type txPoolRPCMock struct {
+	responses map[string]interface{}
+	methods   []string
+}
+
+func (m *txPoolRPCMock) CallContext(_ context.Context, result interface{}, method string, _ ...interface{}) error {
+	m.methods = append(m.methods, method)
+	res, ok := m.responses[method]
+	if !ok {
+		return errors.New("the method " + method + " does not exist/is not available")
+	}
+	b, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, result)
+}
+
+func TestTxPoolContentFrom(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	txs := map[string]*types.Transaction{}
+	for _, n := range []uint64{5, 6, 9} {
+		tx, err := types.SignNewTx(key, signer, &types.LegacyTx{Nonce: n, Gas: 21000, GasPrice: big.NewInt(1), To: &account})
+		assert.NoError(t, err)
+		txs[fmt.Sprint(n)] = tx
+	}
+	content := map[string]map[string]*types.Transaction{
+		"pending": {"5": txs["5"], "6": txs["6"]},
+		"queued":  {"9": txs["9"]},
+	}
+
+	m := &txPoolRPCMock{responses: map[string]interface{}{"txpool_contentFrom": content}}
+	pool, err := TxPoolContentFrom(context.Background(), m, account)
+	assert.NoError(t, err)
+	assert.Len(t, pool.Pending, 2)
+	assert.Equal(t, txs["9"].Hash(), pool.Queued[9].Hash())
+	assert.Equal(t, uint64(7), pool.NextNonce(5))
+	assert.Equal(t, uint64(4), pool.NextNonce(4))
+	assert.Equal(t, []uint64{7, 8}, pool.Gaps(5))
+
+	m = &txPoolRPCMock{responses: map[string]interface{}{"txpool_content": map[string]interface{}{
+		"pending": map[string]interface{}{account.Hex(): content["pending"], common.HexToAddress("0x1").Hex(): content["queued"]},
+		"queued":  map[string]interface{}{},
+	}}}
+	pool, err = TxPoolContentFrom(context.Background(), m, account)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"txpool_contentFrom", "txpool_content"}, m.methods)
+	assert.Len(t, pool.Pending, 2)
+	assert.Empty(t, pool.Queued)
+	assert.Nil(t, pool.Gaps(5))
+}
@@ -0,0 +1,161 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	// waitMinedBackoff is the time waited before the first receipt poll is repeated.
+	waitMinedBackoff = time.Second
+	// waitMinedMaxBackoff is the max time waited between receipt polls.
+	waitMinedMaxBackoff = 30 * time.Second
+)
+
+// ErrTransactionReplaced is returned when the nonce of a waited for transaction
+// was used up by another transaction.
+var ErrTransactionReplaced = errors.New("transaction replaced")
+
+// WaitMinedTimeoutError is returned when the context is done before the transaction got its confirmations.
+type WaitMinedTimeoutError struct {
+	Hash common.Hash
+	// Receipt is the last receipt seen, nil if the transaction was not mined.
+	Receipt *types.Receipt
+	Err     error
+}
+
+func (e *WaitMinedTimeoutError) Error() string {
+	if e.Receipt == nil {
+		return fmt.Sprintf("timed out waiting for transaction %v to be mined: %v", e.Hash.Hex(), e.Err)
+	}
+	return fmt.Sprintf("timed out waiting for confirmations of transaction %v: %v", e.Hash.Hex(), e.Err)
+}
+
+func (e *WaitMinedTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// WaitMinedClient is used to wait for transactions, `EtherClient` satisfies it.
+type WaitMinedClient interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// WaitMined polls for the receipt of the transaction with an exponential backoff until it
+// is mined and has the given number of confirmations, counting the block it was mined in.
+// Failed polls are retried. Once the transaction was seen, `ErrTransactionReplaced` is returned
+// if its nonce is used up without it being mined. When the context is done before,
+// a `WaitMinedTimeoutError` is returned.
+//
+// The wait is traced in a span of the global OpenTelemetry tracer provider.
+func WaitMined(ctx context.Context, client WaitMinedClient, chainID int64, hash common.Hash, confirmations uint64) (*types.Receipt, error) {
+	ctx, span := otel.Tracer(TracerName).Start(ctx, "WaitMined", trace.WithAttributes(
+		AttrChainID.Int64(chainID),
+		AttrTxHash.String(hash.Hex()),
+		attribute.Int64("tx.confirmations", int64(confirmations)),
+	))
+	defer span.End()
+
+	receipt, err := waitMined(ctx, client, chainID, hash, confirmations)
+	if err != nil {
+		RecordSpanError(span, err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int64("tx.block", receipt.BlockNumber.Int64()))
+	return receipt, nil
+}
+
+func waitMined(ctx context.Context, client WaitMinedClient, chainID int64, hash common.Hash, confirmations uint64) (*types.Receipt, error) {
+	w := &minedWaiter{client: client, chainID: chainID, hash: hash, confirmations: confirmations}
+	backoff := waitMinedBackoff
+	for {
+		done, err := w.poll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			return w.receipt, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, &WaitMinedTimeoutError{Hash: hash, Receipt: w.receipt, Err: ctx.Err()}
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > waitMinedMaxBackoff {
+			backoff = waitMinedMaxBackoff
+		}
+	}
+}
+
+type minedWaiter struct {
+	client        WaitMinedClient
+	chainID       int64
+	hash          common.Hash
+	confirmations uint64
+
+	receipt *types.Receipt
+	sender  *common.Address
+	nonce   uint64
+}
+
+// poll returns true once the transaction has its confirmations. Only replacements are returned
+// as errors, other failures are retried by the next poll.
+func (w *minedWaiter) poll(ctx context.Context) (bool, error) {
+	receipt, err := w.client.TransactionReceipt(ctx, w.hash)
+	if err == nil {
+		w.receipt = receipt
+		if w.confirmations <= 1 {
+			return true, nil
+		}
+		head, err := w.client.BlockNumber(ctx)
+		if err != nil {
+			return false, nil
+		}
+		mined := receipt.BlockNumber.Uint64()
+		return head >= mined && head-mined+1 >= w.confirmations, nil
+	}
+	if !errors.Is(err, ethereum.NotFound) {
+		return false, nil
+	}
+	// The block of the receipt seen before could have been reorged out.
+	w.receipt = nil
+
+	if w.sender == nil {
+		tx, _, err := w.client.TransactionByHash(ctx, w.hash)
+		if err != nil {
+			return false, nil
+		}
+		sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(w.chainID)), tx)
+		if err != nil {
+			return false, nil
+		}
+		w.sender, w.nonce = &sender, tx.Nonce()
+	}
+
+	confirmed, err := w.client.NonceAt(ctx, *w.sender, nil)
+	if err != nil {
+		return false, nil
+	}
+	if confirmed > w.nonce {
+		// The receipt could have been mined since it was polled.
+		if receipt, err := w.client.TransactionReceipt(ctx, w.hash); err == nil {
+			w.receipt = receipt
+			return w.confirmations <= 1, nil
+		}
+		return false, fmt.Errorf("%w: nonce %d of %v was used by another transaction", ErrTransactionReplaced, w.nonce, w.sender.Hex())
+	}
+	return false, nil
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/client/mocks"
+)
+
+func TestWaitMined(t *testing.T) {
+	defer func(b, m time.Duration) { waitMinedBackoff, waitMinedMaxBackoff = b, m }(waitMinedBackoff, waitMinedMaxBackoff)
+	waitMinedBackoff, waitMinedMaxBackoff = time.Millisecond, 2*time.Millisecond
+
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1)), &types.LegacyTx{Nonce: 3, Gas: 21000, GasPrice: big.NewInt(1)})
+	assert.NoError(t, err)
+
+	var polls int
+	var head, confirmedNonce uint64 = 10, 3
+	var mined bool
+	cl := &mocks.EtherClientMock{
+		TransactionReceiptFunc: func(_ context.Context, _ common.Hash) (*types.Receipt, error) {
+			polls++
+			if polls == 3 {
+				mined = true
+			}
+			if !mined {
+				return nil, ethereum.NotFound
+			}
+			head++
+			return &types.Receipt{Status: types.ReceiptStatusSuccessful, BlockNumber: big.NewInt(11)}, nil
+		},
+		TransactionByHashFunc: func(_ context.Context, _ common.Hash) (*types.Transaction, bool, error) {
+			return tx, true, nil
+		},
+		NonceAtFunc: func(_ context.Context, _ common.Address, _ *big.Int) (uint64, error) {
+			return confirmedNonce, nil
+		},
+		BlockNumberFunc: func(_ context.Context) (uint64, error) {
+			return head, nil
+		},
+	}
+
+	receipt, err := WaitMined(context.Background(), cl, 1, tx.Hash(), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(11), receipt.BlockNumber)
+	assert.Equal(t, uint64(13), head)
+
+	polls, mined, confirmedNonce = 0, false, 4
+	cl.TransactionReceiptFunc = func(_ context.Context, _ common.Hash) (*types.Receipt, error) {
+		return nil, ethereum.NotFound
+	}
+	_, err = WaitMined(context.Background(), cl, 1, tx.Hash(), 1)
+	assert.ErrorIs(t, err, ErrTransactionReplaced)
+
+	confirmedNonce = 3
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = WaitMined(ctx, cl, 1, tx.Hash(), 1)
+	var timeout *WaitMinedTimeoutError
+	assert.True(t, errors.As(err, &timeout))
+	assert.Nil(t, timeout.Receipt)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
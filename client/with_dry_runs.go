@@ -333,10 +333,18 @@ func (cwdr *WithDryRuns) FilterLogs(q ethereum.FilterQuery) ([]types.Log, error)
 	return cwdr.bc.FilterLogs(q)
 }
 
+func (cwdr *WithDryRuns) FilterLogsPaged(q ethereum.FilterQuery, maxRange uint64) ([]types.Log, error) {
+	return cwdr.bc.FilterLogsPaged(q, maxRange)
+}
+
 func (cwdr *WithDryRuns) HeaderByNumber(number *big.Int) (*types.Header, error) {
 	return cwdr.bc.HeaderByNumber(number)
 }
 
+func (cwdr *WithDryRuns) FeeHistory(blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	return cwdr.bc.FeeHistory(blockCount, lastBlock, rewardPercentiles)
+}
+
 func (cwdr *WithDryRuns) SendTransaction(tx *types.Transaction) error {
 	return cwdr.bc.SendTransaction(tx)
 }
@@ -437,6 +445,14 @@ func (cwdr *WithDryRuns) NonceAt(account common.Address, blockNum *big.Int) (uin
 	return cwdr.bc.NonceAt(account, blockNum)
 }
 
+func (cwdr *WithDryRuns) BalanceAt(account common.Address, blockNum *big.Int) (*big.Int, error) {
+	return cwdr.bc.BalanceAt(account, blockNum)
+}
+
+func (cwdr *WithDryRuns) StorageAt(account common.Address, key common.Hash, blockNum *big.Int) ([]byte, error) {
+	return cwdr.bc.StorageAt(account, key, blockNum)
+}
+
 func (cwdr *WithDryRuns) MystTokenApprove(req MystApproveReq) (*types.Transaction, error) {
 	return cwdr.bc.MystTokenApprove(req)
 }
@@ -445,6 +461,34 @@ func (cwdr *WithDryRuns) MystAllowance(mystTokenAddress, holder, spender common.
 	return cwdr.bc.MystAllowance(mystTokenAddress, holder, spender)
 }
 
+func (cwdr *WithDryRuns) TokenDecimals(tokenAddress common.Address) (uint8, error) {
+	return cwdr.bc.TokenDecimals(tokenAddress)
+}
+
+func (cwdr *WithDryRuns) TokenBalanceOf(tokenAddress, holder common.Address) (*big.Int, error) {
+	return cwdr.bc.TokenBalanceOf(tokenAddress, holder)
+}
+
+func (cwdr *WithDryRuns) TokenAllowance(tokenAddress, holder, spender common.Address) (*big.Int, error) {
+	return cwdr.bc.TokenAllowance(tokenAddress, holder, spender)
+}
+
+func (cwdr *WithDryRuns) TokenApprove(req TokenApproveReq) (*types.Transaction, error) {
+	if _, err := cwdr.Estimate(req); err != nil {
+		return nil, err
+	}
+
+	return cwdr.bc.TokenApprove(req)
+}
+
+func (cwdr *WithDryRuns) TokenTransferFrom(req TokenTransferFromReq) (*types.Transaction, error) {
+	if _, err := cwdr.Estimate(req); err != nil {
+		return nil, err
+	}
+
+	return cwdr.bc.TokenTransferFrom(req)
+}
+
 func (cwdr *WithDryRuns) UniswapV3ExactInputSingle(req UniswapExactInputSingleReq) (*types.Transaction, error) {
 	return cwdr.bc.UniswapV3ExactInputSingle(req)
 }
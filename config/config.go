@@ -0,0 +1,332 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gopkg.in/yaml.v3"
+
+	"github.com/mysteriumnetwork/payments/chains"
+	"github.com/mysteriumnetwork/payments/client"
+	"github.com/mysteriumnetwork/payments/transaction/gas"
+	"github.com/mysteriumnetwork/payments/units"
+)
+
+// Format is a format of a configuration file.
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+)
+
+// Config is the root configuration object.
+type Config struct {
+	Chains []Chain `json:"chains" yaml:"chains"`
+}
+
+// Chain is a configuration of a single chain.
+type Chain struct {
+	ID        int64             `json:"id" yaml:"id"`
+	Name      string            `json:"name" yaml:"name"`
+	RPC       []string          `json:"rpc" yaml:"rpc"`
+	Contracts Contracts         `json:"contracts" yaml:"contracts"`
+	Gas       Gas               `json:"gas" yaml:"gas"`
+	APIKeys   map[string]string `json:"api_keys" yaml:"api_keys"`
+	Explorer  Explorer          `json:"explorer" yaml:"explorer"`
+	// FeeModel overrides the fee model derived from the chain ID, see `gas.FeeModelForChain`.
+	FeeModel string `json:"fee_model" yaml:"fee_model"`
+	// RPCAuth holds the credentials of the RPC endpoints which need them by endpoint.
+	RPCAuth map[string]RPCAuth `json:"rpc_auth" yaml:"rpc_auth"`
+}
+
+// RPCAuth holds the credentials of an RPC endpoint, either basic auth or a bearer token
+// together with optional extra HTTP headers.
+type RPCAuth struct {
+	Headers     map[string]string `json:"headers" yaml:"headers"`
+	Username    string            `json:"username" yaml:"username"`
+	Password    string            `json:"password" yaml:"password"`
+	BearerToken string            `json:"bearer_token" yaml:"bearer_token"`
+}
+
+// Contracts holds mysterium smart contract addresses for a chain.
+type Contracts struct {
+	Registry                    string   `json:"registry" yaml:"registry"`
+	Myst                        string   `json:"myst" yaml:"myst"`
+	ActiveHermes                string   `json:"active_hermes" yaml:"active_hermes"`
+	ActiveChannelImplementation string   `json:"active_channel_implementation" yaml:"active_channel_implementation"`
+	KnownHermeses               []string `json:"known_hermeses" yaml:"known_hermeses"`
+}
+
+// Gas holds gas price bounds for a chain in gwei. Zero means no bound.
+type Gas struct {
+	MaxPriceGwei float64 `json:"max_price_gwei" yaml:"max_price_gwei"`
+	MinPriceGwei float64 `json:"min_price_gwei" yaml:"min_price_gwei"`
+}
+
+// Explorer holds a block explorer of a chain. If the URL is empty the default explorer of the chain is used.
+type Explorer struct {
+	Kind string `json:"kind" yaml:"kind"`
+	URL  string `json:"url" yaml:"url"`
+}
+
+// Load reads a configuration file, applies environment overrides
+// using the `DefaultEnvPrefix` and validates the result.
+//
+// The file format is determined by the file extension.
+func Load(path string) (*Config, error) {
+	format, err := formatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	cfg, err := Parse(data, format)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.ApplyEnv(DefaultEnvPrefix, os.Environ()); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Parse decodes the given data in the given format. The result is not validated.
+func Parse(data []byte, format Format) (*Config, error) {
+	var cfg Config
+	switch format {
+	case FormatJSON:
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse json config: %w", err)
+		}
+	case FormatYAML:
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse yaml config: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config format %q", format)
+	}
+
+	return &cfg, nil
+}
+
+// Chain returns a configuration for the given chain.
+func (c *Config) Chain(chainID int64) (Chain, bool) {
+	for _, ch := range c.Chains {
+		if ch.ID == chainID {
+			return ch, true
+		}
+	}
+	return Chain{}, false
+}
+
+// Validate checks the configuration returning all of the problems found.
+func (c *Config) Validate() error {
+	var errs []error
+	seen := make(map[int64]struct{})
+	for i, ch := range c.Chains {
+		if _, ok := seen[ch.ID]; ok {
+			errs = append(errs, fmt.Errorf("chains[%d]: duplicate chain id %d", i, ch.ID))
+		}
+		seen[ch.ID] = struct{}{}
+
+		if err := ch.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("chains[%d]: %w", i, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate checks a single chain configuration returning all of the problems found.
+func (c Chain) Validate() error {
+	var errs []error
+	if c.ID <= 0 {
+		errs = append(errs, fmt.Errorf("chain id must be positive, got %d", c.ID))
+	}
+
+	if len(c.RPC) == 0 {
+		errs = append(errs, errors.New("at least one rpc endpoint is required"))
+	}
+	for _, rpc := range c.RPC {
+		u, err := url.Parse(rpc)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("invalid rpc endpoint %q", rpc))
+		}
+	}
+	for endpoint, auth := range c.RPCAuth {
+		if auth.Username != "" && auth.BearerToken != "" {
+			errs = append(errs, fmt.Errorf("rpc auth of %q can't use both basic auth and a bearer token", endpoint))
+		}
+	}
+
+	addresses := map[string]string{
+		"registry":                      c.Contracts.Registry,
+		"myst":                          c.Contracts.Myst,
+		"active_hermes":                 c.Contracts.ActiveHermes,
+		"active_channel_implementation": c.Contracts.ActiveChannelImplementation,
+	}
+	for name, addr := range addresses {
+		if addr != "" && !common.IsHexAddress(addr) {
+			errs = append(errs, fmt.Errorf("contract %s is not a hex address: %q", name, addr))
+		}
+	}
+	for _, addr := range c.Contracts.KnownHermeses {
+		if !common.IsHexAddress(addr) {
+			errs = append(errs, fmt.Errorf("known hermes is not a hex address: %q", addr))
+		}
+	}
+
+	if c.Explorer.URL != "" {
+		u, err := url.Parse(c.Explorer.URL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("invalid explorer url %q", c.Explorer.URL))
+		}
+	}
+	switch chains.ExplorerKind(c.Explorer.Kind) {
+	case "", chains.ExplorerEtherscan, chains.ExplorerPolygonscan, chains.ExplorerBlockscout:
+	default:
+		errs = append(errs, fmt.Errorf("unknown explorer kind %q", c.Explorer.Kind))
+	}
+
+	switch gas.FeeModel(c.FeeModel) {
+	case "", gas.FeeModelEthereum, gas.FeeModelOPStack, gas.FeeModelArbitrum:
+	default:
+		errs = append(errs, fmt.Errorf("unknown fee model %q", c.FeeModel))
+	}
+
+	if c.Gas.MaxPriceGwei < 0 || c.Gas.MinPriceGwei < 0 {
+		errs = append(errs, errors.New("gas price bounds cannot be negative"))
+	}
+	if c.Gas.MaxPriceGwei > 0 && c.Gas.MinPriceGwei > c.Gas.MaxPriceGwei {
+		errs = append(errs, fmt.Errorf("min gas price %v is higher than max gas price %v", c.Gas.MinPriceGwei, c.Gas.MaxPriceGwei))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Definition converts the configuration to a chain definition used by the chain registry.
+func (c Chain) Definition() chains.Chain {
+	def := chains.Chain{
+		ID:   c.ID,
+		Name: c.Name,
+		RPC:  append([]string(nil), c.RPC...),
+		Addresses: client.SmartContractAddresses{
+			Registry:                    common.HexToAddress(c.Contracts.Registry),
+			Myst:                        common.HexToAddress(c.Contracts.Myst),
+			ActiveHermes:                common.HexToAddress(c.Contracts.ActiveHermes),
+			ActiveChannelImplementation: common.HexToAddress(c.Contracts.ActiveChannelImplementation),
+		},
+		APIKeys: make(map[string]string, len(c.APIKeys)),
+		Explorer: chains.Explorer{
+			Kind: chains.ExplorerKind(c.Explorer.Kind),
+			URL:  c.Explorer.URL,
+		},
+		FeeModel: gas.FeeModel(c.FeeModel),
+	}
+	for _, h := range c.Contracts.KnownHermeses {
+		def.Addresses.KnownHermeses = append(def.Addresses.KnownHermeses, common.HexToAddress(h))
+	}
+	for k, v := range c.APIKeys {
+		def.APIKeys[k] = v
+	}
+	if c.Gas.MaxPriceGwei > 0 {
+		def.MaxGasPrice = units.FloatGweiToBigIntWei(c.Gas.MaxPriceGwei)
+	}
+	if c.Gas.MinPriceGwei > 0 {
+		def.MinGasPrice = units.FloatGweiToBigIntWei(c.Gas.MinPriceGwei)
+	}
+
+	return def
+}
+
+// Definitions returns chain definitions for all of the configured chains.
+func (c *Config) Definitions() []chains.Chain {
+	res := make([]chains.Chain, 0, len(c.Chains))
+	for _, ch := range c.Chains {
+		res = append(res, ch.Definition())
+	}
+	return res
+}
+
+// Apply replaces the contents of the given registry with the configured chains.
+func (c *Config) Apply(reg *chains.Registry) {
+	reg.Replace(c.Definitions()...)
+}
+
+// NewMultichainClient dials all of the configured RPC endpoints and returns
+// a multichain blockchain client. Each chain is served by an `EthMultiClient`
+// which falls back to the next endpoint upon failure. Endpoints serving another
+// chain than the configured one fail with `client.ErrChainIDMismatch`.
+func (c *Config) NewMultichainClient(connectTimeout, callTimeout time.Duration) (*client.MultichainBlockchainClient, error) {
+	clients := make(map[int64]client.BC, len(c.Chains))
+	for _, ch := range c.Chains {
+		mc, err := client.DialEthMultiClientForChain(ch.ID, ch.RPC, ch.endpointAuth(), connectTimeout, callTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create client for chain %d: %w", ch.ID, err)
+		}
+		clients[ch.ID] = client.NewBlockchain(mc, callTimeout)
+	}
+
+	return client.NewMultichainBlockchainClient(clients), nil
+}
+
+// NewLazyMultichainClient returns a multichain blockchain client which connects
+// to the RPC endpoints of every configured chain on its first use, like the ones of
+// `NewMultichainClient`, so unreachable chains don't fail the construction.
+// At most maxConnections chains are kept connected, zero means no limit.
+func (c *Config) NewLazyMultichainClient(connectTimeout, callTimeout time.Duration, maxConnections int) *client.MultichainBlockchainClient {
+	chains := make(map[int64]Chain, len(c.Chains))
+	ids := make([]int64, 0, len(c.Chains))
+	for _, ch := range c.Chains {
+		chains[ch.ID] = ch
+		ids = append(ids, ch.ID)
+	}
+
+	dial := func(chainID int64) (client.EthClientGetter, error) {
+		ch := chains[chainID]
+		return client.DialEthMultiClientForChain(ch.ID, ch.RPC, ch.endpointAuth(), connectTimeout, callTimeout)
+	}
+	return client.NewLazyMultichainBlockchainClient(client.NewConnectionPool(ids, dial, maxConnections, callTimeout))
+}
+
+func (c Chain) endpointAuth() map[string]client.EndpointAuth {
+	res := make(map[string]client.EndpointAuth, len(c.RPCAuth))
+	for endpoint, auth := range c.RPCAuth {
+		res[endpoint] = client.EndpointAuth{
+			Headers:     auth.Headers,
+			Username:    auth.Username,
+			Password:    auth.Password,
+			BearerToken: auth.BearerToken,
+		}
+	}
+	return res
+}
+
+func formatFromPath(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON, nil
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	default:
+		return "", fmt.Errorf("cannot determine config format from file %q", path)
+	}
+}
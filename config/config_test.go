@@ -0,0 +1,206 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/payments/chains"
+	"github.com/mysteriumnetwork/payments/transaction/gas"
+	"github.com/mysteriumnetwork/payments/units"
+	"github.com/stretchr/testify/assert"
+)
+
+const yamlConfig = `
+chains:
+  - id: 137
+    name: polygon
+    rpc:
+      - https://polygon-rpc.com
+      - https://rpc.ankr.com/polygon
+    contracts:
+      registry: "0x87F0F4b7e0FAb14A565C87BAbbA6c40c92281b51"
+      myst: "0x1379E8886A944d2D9d440b3d88DF536Aea08d9F3"
+      active_hermes: "0x80ed28d84792d8b153bf2f25f0c4b7a1381de4ab"
+      known_hermeses:
+        - "0xa62a2A75949d25e17C6F08a7818e7bE97c18a8d2"
+    gas:
+      max_price_gwei: 500
+      min_price_gwei: 30
+    api_keys:
+      polygonscan: secret
+    explorer:
+      kind: polygonscan
+      url: https://polygonscan.com
+    rpc_auth:
+      https://rpc.ankr.com/polygon:
+        bearer_token: token
+        headers:
+          X-Api-Key: key
+`
+
+const jsonConfig = `{
+  "chains": [
+    {
+      "id": 1,
+      "name": "ethereum",
+      "rpc": ["https://mainnet.infura.io/v3/key"],
+      "contracts": {"myst": "0x4Cf89ca06ad997bC732Dc876ed2A7F26a9E7f361"}
+    }
+  ]
+}`
+
+func TestParse(t *testing.T) {
+	t.Run("yaml", func(t *testing.T) {
+		cfg, err := Parse([]byte(yamlConfig), FormatYAML)
+		assert.NoError(t, err)
+		assert.NoError(t, cfg.Validate())
+
+		ch, ok := cfg.Chain(137)
+		assert.True(t, ok)
+		assert.Equal(t, "polygon", ch.Name)
+		assert.Len(t, ch.RPC, 2)
+		assert.Equal(t, "secret", ch.APIKeys["polygonscan"])
+		assert.Equal(t, RPCAuth{BearerToken: "token", Headers: map[string]string{"X-Api-Key": "key"}}, ch.RPCAuth["https://rpc.ankr.com/polygon"])
+
+		def := ch.Definition()
+		assert.Equal(t, common.HexToAddress("0x87F0F4b7e0FAb14A565C87BAbbA6c40c92281b51"), def.Addresses.Registry)
+		assert.Equal(t, []common.Address{common.HexToAddress("0xa62a2A75949d25e17C6F08a7818e7bE97c18a8d2")}, def.Addresses.KnownHermeses)
+		assert.Equal(t, units.FloatGweiToBigIntWei(500), def.MaxGasPrice)
+		assert.Equal(t, units.FloatGweiToBigIntWei(30), def.MinGasPrice)
+		assert.Equal(t, chains.Explorer{Kind: chains.ExplorerPolygonscan, URL: "https://polygonscan.com"}, def.Explorer)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		cfg, err := Parse([]byte(jsonConfig), FormatJSON)
+		assert.NoError(t, err)
+		assert.NoError(t, cfg.Validate())
+
+		ch, ok := cfg.Chain(1)
+		assert.True(t, ok)
+		assert.Equal(t, "0x4Cf89ca06ad997bC732Dc876ed2A7F26a9E7f361", ch.Contracts.Myst)
+		assert.Nil(t, ch.Definition().MaxGasPrice)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := Parse([]byte(jsonConfig), "toml")
+		assert.Error(t, err)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	cfg := &Config{
+		Chains: []Chain{
+			{ID: 1, RPC: []string{"https://ok"}},
+			{ID: 1, RPC: []string{"not a url"}, Contracts: Contracts{Registry: "0x1"}, RPCAuth: map[string]RPCAuth{
+				"not a url": {Username: "user", BearerToken: "token"},
+			}},
+			{ID: 0, Gas: Gas{MaxPriceGwei: 10, MinPriceGwei: 20}, Explorer: Explorer{Kind: "unknown", URL: "nope"}, FeeModel: "zk"},
+		},
+	}
+
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "duplicate chain id 1")
+	assert.Contains(t, err.Error(), `invalid rpc endpoint "not a url"`)
+	assert.Contains(t, err.Error(), "contract registry is not a hex address")
+	assert.Contains(t, err.Error(), "chain id must be positive")
+	assert.Contains(t, err.Error(), "at least one rpc endpoint is required")
+	assert.Contains(t, err.Error(), "min gas price 20 is higher than max gas price 10")
+	assert.Contains(t, err.Error(), `unknown explorer kind "unknown"`)
+	assert.Contains(t, err.Error(), `invalid explorer url "nope"`)
+	assert.Contains(t, err.Error(), `unknown fee model "zk"`)
+	assert.Contains(t, err.Error(), `rpc auth of "not a url" can't use both basic auth and a bearer token`)
+}
+
+func TestApplyEnv(t *testing.T) {
+	cfg, err := Parse([]byte(yamlConfig), FormatYAML)
+	assert.NoError(t, err)
+
+	err = cfg.ApplyEnv("TEST", []string{
+		"TEST_CHAIN_137_RPC=https://one, https://two",
+		"TEST_CHAIN_137_GAS_MAX_PRICE_GWEI=250",
+		"TEST_CHAIN_137_API_KEY_ETHERSCAN=other",
+		"TEST_CHAIN_5_RPC=https://goerli",
+		"TEST_CHAIN_5_EXPLORER_URL=https://goerli.etherscan.io",
+		"TEST_CHAIN_5_FEE_MODEL=arbitrum",
+		"UNRELATED=1",
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, cfg.Validate())
+
+	polygon, _ := cfg.Chain(137)
+	assert.Equal(t, []string{"https://one", "https://two"}, polygon.RPC)
+	assert.Equal(t, 250.0, polygon.Gas.MaxPriceGwei)
+	assert.Equal(t, "other", polygon.APIKeys["etherscan"])
+	assert.Equal(t, "secret", polygon.APIKeys["polygonscan"])
+
+	goerli, ok := cfg.Chain(5)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"https://goerli"}, goerli.RPC)
+	assert.Equal(t, "https://goerli.etherscan.io", goerli.Explorer.URL)
+	assert.Equal(t, gas.FeeModelArbitrum, goerli.Definition().GasFeeModel())
+
+	assert.Error(t, cfg.ApplyEnv("TEST", []string{"TEST_CHAIN_abc_RPC=x"}))
+	assert.Error(t, cfg.ApplyEnv("TEST", []string{"TEST_CHAIN_1_UNKNOWN=x"}))
+	assert.Error(t, cfg.ApplyEnv("TEST", []string{"TEST_CHAIN_1_GAS_MIN_PRICE_GWEI=x"}))
+}
+
+func TestApplyRegistry(t *testing.T) {
+	cfg, err := Parse([]byte(yamlConfig), FormatYAML)
+	assert.NoError(t, err)
+
+	reg := chains.NewRegistry(chains.Chain{ID: 80001})
+	cfg.Apply(reg)
+	assert.Equal(t, []int64{137}, reg.IDs())
+
+	myst, err := reg.AddressKeeper().GetMystAddress(137)
+	assert.NoError(t, err)
+	assert.Equal(t, common.HexToAddress("0x1379E8886A944d2D9d440b3d88DF536Aea08d9F3"), myst)
+}
+
+func TestWatcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(yamlConfig), 0o600))
+
+	w, err := NewWatcher(path, 10*time.Millisecond)
+	assert.NoError(t, err)
+	defer w.Stop()
+
+	reg := chains.NewRegistry()
+	w.OnChange(func(c *Config) { c.Apply(reg) })
+	assert.Equal(t, []int64{137}, reg.IDs())
+
+	var logged []error
+	w.AttachLogger(func(err error) { logged = append(logged, err) })
+	w.Run()
+
+	t.Run("reloads on change", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(path, []byte(`chains: [{id: 1, rpc: ["https://eth"]}]`), 0o600))
+		touch(t, path, time.Now().Add(time.Second))
+
+		assert.Eventually(t, func() bool {
+			_, err := reg.Get(1)
+			return err == nil
+		}, 2*time.Second, 10*time.Millisecond)
+		_, ok := w.Current().Chain(137)
+		assert.False(t, ok)
+	})
+
+	t.Run("keeps previous config if invalid", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(path, []byte(`chains: [{id: -1}]`), 0o600))
+		touch(t, path, time.Now().Add(2*time.Second))
+
+		time.Sleep(100 * time.Millisecond)
+		_, ok := w.Current().Chain(1)
+		assert.True(t, ok)
+		_, err := reg.Get(1)
+		assert.NoError(t, err)
+	})
+}
+
+func touch(t *testing.T, path string, at time.Time) {
+	assert.NoError(t, os.Chtimes(path, at, at))
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultEnvPrefix is the prefix used for environment overrides when loading configuration.
+const DefaultEnvPrefix = "PAYMENTS"
+
+// ApplyEnv applies overrides from the given environment (in `os.Environ` form)
+// to the configuration. Variables are expected in the following format:
+//
+//	<PREFIX>_CHAIN_<ID>_RPC=https://first,https://second
+//	<PREFIX>_CHAIN_<ID>_NAME=polygon
+//	<PREFIX>_CHAIN_<ID>_REGISTRY=0x...
+//	<PREFIX>_CHAIN_<ID>_MYST=0x...
+//	<PREFIX>_CHAIN_<ID>_ACTIVE_HERMES=0x...
+//	<PREFIX>_CHAIN_<ID>_ACTIVE_CHANNEL_IMPLEMENTATION=0x...
+//	<PREFIX>_CHAIN_<ID>_KNOWN_HERMESES=0x...,0x...
+//	<PREFIX>_CHAIN_<ID>_GAS_MAX_PRICE_GWEI=500
+//	<PREFIX>_CHAIN_<ID>_GAS_MIN_PRICE_GWEI=30
+//	<PREFIX>_CHAIN_<ID>_EXPLORER_KIND=blockscout
+//	<PREFIX>_CHAIN_<ID>_EXPLORER_URL=https://...
+//	<PREFIX>_CHAIN_<ID>_FEE_MODEL=op-stack
+//	<PREFIX>_CHAIN_<ID>_API_KEY_<PROVIDER>=secret
+//
+// Chains which are not yet configured are created.
+func (c *Config) ApplyEnv(prefix string, environ []string) error {
+	chainPrefix := prefix + "_CHAIN_"
+	for _, kv := range environ {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(key, chainPrefix) {
+			continue
+		}
+
+		idStr, field, ok := strings.Cut(strings.TrimPrefix(key, chainPrefix), "_")
+		if !ok {
+			return fmt.Errorf("malformed environment variable %q", key)
+		}
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			return fmt.Errorf("malformed chain id in environment variable %q: %w", key, err)
+		}
+
+		ch := c.chainRef(id)
+		if err := ch.applyEnvField(field, value); err != nil {
+			return fmt.Errorf("failed to apply environment variable %q: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) chainRef(id int64) *Chain {
+	for i := range c.Chains {
+		if c.Chains[i].ID == id {
+			return &c.Chains[i]
+		}
+	}
+
+	c.Chains = append(c.Chains, Chain{ID: id})
+	return &c.Chains[len(c.Chains)-1]
+}
+
+func (c *Chain) applyEnvField(field, value string) error {
+	const apiKeyPrefix = "API_KEY_"
+	if strings.HasPrefix(field, apiKeyPrefix) {
+		if c.APIKeys == nil {
+			c.APIKeys = make(map[string]string)
+		}
+		c.APIKeys[strings.ToLower(strings.TrimPrefix(field, apiKeyPrefix))] = value
+		return nil
+	}
+
+	switch field {
+	case "NAME":
+		c.Name = value
+	case "RPC":
+		c.RPC = splitList(value)
+	case "REGISTRY":
+		c.Contracts.Registry = value
+	case "MYST":
+		c.Contracts.Myst = value
+	case "ACTIVE_HERMES":
+		c.Contracts.ActiveHermes = value
+	case "ACTIVE_CHANNEL_IMPLEMENTATION":
+		c.Contracts.ActiveChannelImplementation = value
+	case "KNOWN_HERMESES":
+		c.Contracts.KnownHermeses = splitList(value)
+	case "EXPLORER_KIND":
+		c.Explorer.Kind = value
+	case "EXPLORER_URL":
+		c.Explorer.URL = value
+	case "FEE_MODEL":
+		c.FeeModel = value
+	case "GAS_MAX_PRICE_GWEI":
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		c.Gas.MaxPriceGwei = v
+	case "GAS_MIN_PRICE_GWEI":
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		c.Gas.MinPriceGwei = v
+	default:
+		return fmt.Errorf("unknown field %q", field)
+	}
+
+	return nil
+}
+
+func splitList(value string) []string {
+	res := make([]string, 0)
+	for _, v := range strings.Split(value, ",") {
+		v = strings.TrimSpace(v)
+		if v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Watcher keeps a configuration file loaded and reloads it once the file changes.
+//
+// A reloaded configuration is only used if it is valid, otherwise the
+// previous configuration is kept and the error is logged.
+type Watcher struct {
+	path     string
+	interval time.Duration
+
+	current *Config
+	modTime time.Time
+	mu      sync.RWMutex
+
+	listeners []func(*Config)
+	logFn     func(error)
+
+	once sync.Once
+	stop chan struct{}
+}
+
+// NewWatcher loads the configuration from the given path and returns a watcher
+// which will check the file for changes every interval once `Run` is called.
+func NewWatcher(path string, interval time.Duration) (*Watcher, error) {
+	w := &Watcher{
+		path:     path,
+		interval: interval,
+		logFn:    func(error) {},
+		stop:     make(chan struct{}),
+	}
+
+	if err := w.Reload(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Current returns the currently loaded configuration.
+func (w *Watcher) Current() *Config {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return w.current
+}
+
+// OnChange registers a listener which is called with every newly loaded configuration.
+// The listener is also called immediately with the current configuration.
+//
+// This method is not thread safe and should be called before `Run`.
+func (w *Watcher) OnChange(fn func(*Config)) {
+	w.listeners = append(w.listeners, fn)
+	fn(w.Current())
+}
+
+// AttachLogger allows the caller to attach an optional logger
+// which will receive errors that happen while reloading the configuration.
+//
+// This method is not thread safe and should be called before `Run`.
+func (w *Watcher) AttachLogger(fn func(err error)) {
+	w.logFn = fn
+}
+
+// Reload forces the configuration to be loaded from the file.
+func (w *Watcher) Reload() error {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return fmt.Errorf("failed to stat config file: %w", err)
+	}
+
+	cfg, err := Load(w.path)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	w.current = cfg
+	w.modTime = info.ModTime()
+	listeners := w.listeners
+	w.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(cfg)
+	}
+
+	return nil
+}
+
+// Run will spawn a goroutine which checks the configuration file for changes.
+func (w *Watcher) Run() {
+	go w.watch()
+}
+
+// Stop will stop watching the configuration file.
+func (w *Watcher) Stop() {
+	w.once.Do(func() {
+		close(w.stop)
+	})
+}
+
+func (w *Watcher) watch() {
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-time.After(w.interval):
+			changed, err := w.changed()
+			if err != nil {
+				w.logFn(err)
+				break
+			}
+			if !changed {
+				break
+			}
+
+			if err := w.Reload(); err != nil {
+				w.logFn(fmt.Errorf("failed to reload config, keeping the previous one: %w", err))
+				// Remember the failed version so that we do not retry
+				// loading the same broken file every interval.
+				w.updateModTime()
+			}
+		}
+	}
+}
+
+func (w *Watcher) changed() (bool, error) {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return false, fmt.Errorf("failed to stat config file: %w", err)
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return !info.ModTime().Equal(w.modTime), nil
+}
+
+func (w *Watcher) updateModTime() {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.modTime = info.ModTime()
+}
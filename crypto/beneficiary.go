@@ -27,6 +27,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mysteriumnetwork/payments/crypto/signatures"
 )
 
 // SetBeneficiaryRequest represents a request for setting new beneficiary.
@@ -102,14 +103,7 @@ func (r SetBeneficiaryRequest) GetMessage() []byte {
 
 // RecoverSigner recovers the signer identity from the given request.
 func (r SetBeneficiaryRequest) RecoverSigner() (common.Address, error) {
-	signature := r.GetSignatureBytesRaw()
-
-	err := ReformatSignatureVForRecovery(signature)
-	if err != nil {
-		return common.Address{}, err
-	}
-
-	recoveredAddress, err := RecoverAddress(r.GetMessage(), signature)
+	recoveredAddress, err := signatures.RecoverMessage(r.GetMessage(), r.GetSignatureBytesRaw())
 	if err != nil {
 		return common.Address{}, err
 	}
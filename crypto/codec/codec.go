@@ -0,0 +1,207 @@
+// Package codec holds strict encoders and decoders for the byte layouts of
+// the signed payloads. Every field is checked against its slot, so malformed
+// input is reported as an error instead of silently shifting the layout.
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// WordLength is the length of a single ABI word.
+const WordLength = 32
+
+// ErrMalformed is wrapped by every encoding and decoding error.
+var ErrMalformed = errors.New("malformed payload")
+
+var maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// Encoder packs fields into a payload. The first error stops encoding and is returned by `Bytes`.
+type Encoder struct {
+	buf []byte
+	err error
+}
+
+// NewEncoder returns a new encoder for a payload of the given size.
+func NewEncoder(size int) *Encoder {
+	return &Encoder{buf: make([]byte, 0, size)}
+}
+
+func (e *Encoder) fail(format string, args ...interface{}) {
+	if e.err == nil {
+		e.err = fmt.Errorf("%w: %s", ErrMalformed, fmt.Sprintf(format, args...))
+	}
+}
+
+// Uint64 appends the value as a word.
+func (e *Encoder) Uint64(v uint64) {
+	e.Uint256("uint64", new(big.Int).SetUint64(v))
+}
+
+// ChainID appends the chain ID as a word, negative IDs are rejected.
+func (e *Encoder) ChainID(id int64) {
+	if id < 0 {
+		e.fail("chain id %d is negative", id)
+		return
+	}
+	e.Uint64(uint64(id))
+}
+
+// Uint256 appends the value as a word, nil, negative and values above 256 bits are rejected.
+func (e *Encoder) Uint256(name string, v *big.Int) {
+	if e.err != nil {
+		return
+	}
+	switch {
+	case v == nil:
+		e.fail("%s is missing", name)
+	case v.Sign() < 0:
+		e.fail("%s %s is negative", name, v)
+	case v.Cmp(maxUint256) > 0:
+		e.fail("%s does not fit into 256 bits", name)
+	default:
+		e.buf = append(e.buf, common.LeftPadBytes(v.Bytes(), WordLength)...)
+	}
+}
+
+// Bytes32 appends the value left padded to a word, values longer than a word are rejected.
+func (e *Encoder) Bytes32(name string, b []byte) {
+	if e.err != nil {
+		return
+	}
+	if len(b) > WordLength {
+		e.fail("%s is %d bytes long, at most %d are allowed", name, len(b), WordLength)
+		return
+	}
+	e.buf = append(e.buf, common.LeftPadBytes(b, WordLength)...)
+}
+
+// Fixed appends the value which must be exactly the given size.
+func (e *Encoder) Fixed(name string, b []byte, size int) {
+	if e.err != nil {
+		return
+	}
+	if len(b) != size {
+		e.fail("%s is %d bytes long, %d are required", name, len(b), size)
+		return
+	}
+	e.buf = append(e.buf, b...)
+}
+
+// Address appends the packed 20 byte address.
+func (e *Encoder) Address(a common.Address) {
+	if e.err != nil {
+		return
+	}
+	e.buf = append(e.buf, a.Bytes()...)
+}
+
+// Bytes returns the payload or the first encoding error.
+func (e *Encoder) Bytes() ([]byte, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.buf, nil
+}
+
+// Decoder reads fields from a payload. The first error stops decoding and is returned by `Finish`.
+type Decoder struct {
+	buf []byte
+	err error
+}
+
+// NewDecoder returns a new decoder of the payload.
+func NewDecoder(b []byte) *Decoder {
+	return &Decoder{buf: b}
+}
+
+func (d *Decoder) fail(format string, args ...interface{}) {
+	if d.err == nil {
+		d.err = fmt.Errorf("%w: %s", ErrMalformed, fmt.Sprintf(format, args...))
+	}
+}
+
+func (d *Decoder) take(name string, size int) []byte {
+	if d.err != nil {
+		return make([]byte, size)
+	}
+	if len(d.buf) < size {
+		d.fail("%s is truncated", name)
+		return make([]byte, size)
+	}
+	res := make([]byte, size)
+	copy(res, d.buf[:size])
+	d.buf = d.buf[size:]
+	return res
+}
+
+// Uint64 reads a word holding a value which must fit into 64 bits.
+func (d *Decoder) Uint64(name string) uint64 {
+	v := d.Uint256(name)
+	if !v.IsUint64() {
+		d.fail("%s does not fit into 64 bits", name)
+		return 0
+	}
+	return v.Uint64()
+}
+
+// ChainID reads a word holding a chain ID which must fit into a positive int64.
+func (d *Decoder) ChainID() int64 {
+	v := d.Uint64("chain id")
+	if v > uint64(1<<63-1) {
+		d.fail("chain id %d does not fit into int64", v)
+		return 0
+	}
+	return int64(v)
+}
+
+// Uint256 reads a word as an unsigned integer.
+func (d *Decoder) Uint256(name string) *big.Int {
+	return new(big.Int).SetBytes(d.take(name, WordLength))
+}
+
+// Bytes32 reads a word.
+func (d *Decoder) Bytes32(name string) []byte {
+	return d.take(name, WordLength)
+}
+
+// Fixed reads a value of the given size.
+func (d *Decoder) Fixed(name string, size int) []byte {
+	return d.take(name, size)
+}
+
+// Address reads a packed 20 byte address.
+func (d *Decoder) Address(name string) common.Address {
+	return common.BytesToAddress(d.take(name, common.AddressLength))
+}
+
+// Remaining returns the number of bytes which were not read yet.
+func (d *Decoder) Remaining() int {
+	return len(d.buf)
+}
+
+// Finish returns the first decoding error, or an error if there are unread trailing bytes.
+func (d *Decoder) Finish() error {
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.buf) > 0 {
+		return fmt.Errorf("%w: %d trailing bytes", ErrMalformed, len(d.buf))
+	}
+	return nil
+}
+
+// ParseAddress parses a hex address. An empty string is the zero address,
+// any other value must be a valid hex address.
+func ParseAddress(name, s string) (common.Address, error) {
+	if s == "" {
+		return common.Address{}, nil
+	}
+	if !common.IsHexAddress(s) {
+		return common.Address{}, fmt.Errorf("%w: %s %q is not a hex address", ErrMalformed, name, s)
+	}
+	return common.HexToAddress(s), nil
+}
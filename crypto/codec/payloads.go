@@ -0,0 +1,142 @@
+package codec
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Payload lengths of the fixed layouts.
+const (
+	PromiseLength                   = 5 * WordLength
+	ExchangeMessageLength           = 4*WordLength + common.AddressLength
+	ExchangeMessageWithHermesLength = ExchangeMessageLength + common.AddressLength
+	RegistrationLength              = 3*WordLength + 3*common.AddressLength
+)
+
+// PromisePayload holds the fields of a payment promise message.
+type PromisePayload struct {
+	ChainID   int64
+	ChannelID []byte
+	Amount    *big.Int
+	Fee       *big.Int
+	Hashlock  []byte
+}
+
+// EncodePromise encodes the promise message: chain ID, channel ID, amount, fee and hashlock, each as a word.
+func EncodePromise(p PromisePayload) ([]byte, error) {
+	e := NewEncoder(PromiseLength)
+	e.ChainID(p.ChainID)
+	e.Bytes32("channel id", p.ChannelID)
+	e.Uint256("amount", p.Amount)
+	e.Uint256("fee", p.Fee)
+	e.Bytes32("hashlock", p.Hashlock)
+	return e.Bytes()
+}
+
+// DecodePromise decodes a promise message.
+func DecodePromise(b []byte) (PromisePayload, error) {
+	d := NewDecoder(b)
+	p := PromisePayload{
+		ChainID:   d.ChainID(),
+		ChannelID: d.Bytes32("channel id"),
+		Amount:    d.Uint256("amount"),
+		Fee:       d.Uint256("fee"),
+		Hashlock:  d.Bytes32("hashlock"),
+	}
+	if err := d.Finish(); err != nil {
+		return PromisePayload{}, err
+	}
+	return p, nil
+}
+
+// ExchangeMessagePayload holds the fields of a promise exchange message.
+type ExchangeMessagePayload struct {
+	ChainID        int64
+	PromiseHash    []byte
+	AgreementID    *big.Int
+	AgreementTotal *big.Int
+	Provider       common.Address
+	// HermesID is omitted from the message of older consumers.
+	HermesID *common.Address
+}
+
+// EncodeExchangeMessage encodes the exchange message: chain ID, promise hash, agreement ID and
+// agreement total as words, followed by the packed provider and hermes addresses.
+func EncodeExchangeMessage(m ExchangeMessagePayload) ([]byte, error) {
+	e := NewEncoder(ExchangeMessageWithHermesLength)
+	e.ChainID(m.ChainID)
+	e.Fixed("promise hash", m.PromiseHash, WordLength)
+	e.Uint256("agreement id", m.AgreementID)
+	e.Uint256("agreement total", m.AgreementTotal)
+	e.Address(m.Provider)
+	if m.HermesID != nil {
+		e.Address(*m.HermesID)
+	}
+	return e.Bytes()
+}
+
+// DecodeExchangeMessage decodes an exchange message with or without the hermes address.
+func DecodeExchangeMessage(b []byte) (ExchangeMessagePayload, error) {
+	if len(b) != ExchangeMessageLength && len(b) != ExchangeMessageWithHermesLength {
+		return ExchangeMessagePayload{}, fmt.Errorf("%w: exchange message is %d bytes long", ErrMalformed, len(b))
+	}
+
+	d := NewDecoder(b)
+	m := ExchangeMessagePayload{
+		ChainID:        d.ChainID(),
+		PromiseHash:    d.Fixed("promise hash", WordLength),
+		AgreementID:    d.Uint256("agreement id"),
+		AgreementTotal: d.Uint256("agreement total"),
+		Provider:       d.Address("provider"),
+	}
+	if d.Remaining() > 0 {
+		hermes := d.Address("hermes id")
+		m.HermesID = &hermes
+	}
+	if err := d.Finish(); err != nil {
+		return ExchangeMessagePayload{}, err
+	}
+	return m, nil
+}
+
+// RegistrationPayload holds the fields of an identity registration message.
+type RegistrationPayload struct {
+	ChainID     int64
+	Registry    common.Address
+	HermesID    common.Address
+	Stake       *big.Int
+	Fee         *big.Int
+	Beneficiary common.Address
+}
+
+// EncodeRegistration encodes the registration message: chain ID as a word, packed registry and hermes
+// addresses, stake and fee as words and the packed beneficiary address.
+func EncodeRegistration(r RegistrationPayload) ([]byte, error) {
+	e := NewEncoder(RegistrationLength)
+	e.ChainID(r.ChainID)
+	e.Address(r.Registry)
+	e.Address(r.HermesID)
+	e.Uint256("stake", r.Stake)
+	e.Uint256("fee", r.Fee)
+	e.Address(r.Beneficiary)
+	return e.Bytes()
+}
+
+// DecodeRegistration decodes a registration message.
+func DecodeRegistration(b []byte) (RegistrationPayload, error) {
+	d := NewDecoder(b)
+	r := RegistrationPayload{
+		ChainID:     d.ChainID(),
+		Registry:    d.Address("registry"),
+		HermesID:    d.Address("hermes id"),
+		Stake:       d.Uint256("stake"),
+		Fee:         d.Uint256("fee"),
+		Beneficiary: d.Address("beneficiary"),
+	}
+	if err := d.Finish(); err != nil {
+		return RegistrationPayload{}, err
+	}
+	return r, nil
+}
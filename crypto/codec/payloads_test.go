@@ -0,0 +1,150 @@
+package codec
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodePromise(t *testing.T) {
+	p := PromisePayload{ChainID: 137, ChannelID: []byte{1}, Amount: big.NewInt(10), Fee: big.NewInt(1), Hashlock: []byte{2}}
+	b, err := EncodePromise(p)
+	assert.NoError(t, err)
+	assert.Len(t, b, PromiseLength)
+
+	t.Run("rejects malformed fields", func(t *testing.T) {
+		for name, fn := range map[string]func(p *PromisePayload){
+			"negative chain":  func(p *PromisePayload) { p.ChainID = -1 },
+			"long channel":    func(p *PromisePayload) { p.ChannelID = make([]byte, 33) },
+			"missing amount":  func(p *PromisePayload) { p.Amount = nil },
+			"negative fee":    func(p *PromisePayload) { p.Fee = big.NewInt(-1) },
+			"overflow amount": func(p *PromisePayload) { p.Amount = new(big.Int).Lsh(big.NewInt(1), 256) },
+			"long hashlock":   func(p *PromisePayload) { p.Hashlock = make([]byte, 64) },
+		} {
+			malformed := p
+			fn(&malformed)
+			_, err := EncodePromise(malformed)
+			assert.ErrorIs(t, err, ErrMalformed, name)
+		}
+	})
+
+	t.Run("rejects wrong length", func(t *testing.T) {
+		_, err := DecodePromise(b[:PromiseLength-1])
+		assert.ErrorIs(t, err, ErrMalformed)
+		_, err = DecodePromise(append(b, 0))
+		assert.ErrorIs(t, err, ErrMalformed)
+	})
+}
+
+func TestExchangeMessage(t *testing.T) {
+	hermes := common.HexToAddress("0x2")
+	m := ExchangeMessagePayload{ChainID: 1, PromiseHash: make([]byte, 32), AgreementID: big.NewInt(1), AgreementTotal: big.NewInt(2), Provider: common.HexToAddress("0x1")}
+
+	b, err := EncodeExchangeMessage(m)
+	assert.NoError(t, err)
+	assert.Len(t, b, ExchangeMessageLength)
+	decoded, err := DecodeExchangeMessage(b)
+	assert.NoError(t, err)
+	assert.Nil(t, decoded.HermesID)
+
+	m.HermesID = &hermes
+	b, err = EncodeExchangeMessage(m)
+	assert.NoError(t, err)
+	decoded, err = DecodeExchangeMessage(b)
+	assert.NoError(t, err)
+	assert.Equal(t, &hermes, decoded.HermesID)
+
+	m.PromiseHash = []byte{1}
+	_, err = EncodeExchangeMessage(m)
+	assert.ErrorIs(t, err, ErrMalformed)
+}
+
+func TestParseAddress(t *testing.T) {
+	a, err := ParseAddress("provider", "")
+	assert.NoError(t, err)
+	assert.Equal(t, common.Address{}, a)
+
+	_, err = ParseAddress("provider", "0x12")
+	assert.ErrorIs(t, err, ErrMalformed)
+}
+
+func FuzzPromise(f *testing.F) {
+	f.Add(int64(137), []byte{1}, []byte{10}, []byte{1}, []byte{2})
+	f.Add(int64(-1), make([]byte, 33), []byte{}, []byte{}, make([]byte, 32))
+	f.Fuzz(func(t *testing.T, chainID int64, channelID, amount, fee, hashlock []byte) {
+		p := PromisePayload{ChainID: chainID, ChannelID: channelID, Amount: new(big.Int).SetBytes(amount), Fee: new(big.Int).SetBytes(fee), Hashlock: hashlock}
+		b, err := EncodePromise(p)
+		valid := chainID >= 0 && len(channelID) <= 32 && len(hashlock) <= 32 && len(amount) <= 32 && len(fee) <= 32
+		if !valid {
+			if err == nil {
+				t.Fatalf("malformed promise encoded: %+v", p)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("valid promise not encoded: %v", err)
+		}
+
+		decoded, err := DecodePromise(b)
+		if err != nil {
+			t.Fatalf("encoded promise not decoded: %v", err)
+		}
+		if decoded.ChainID != chainID || decoded.Amount.Cmp(p.Amount) != 0 || decoded.Fee.Cmp(p.Fee) != 0 ||
+			!bytes.Equal(decoded.ChannelID, common.LeftPadBytes(channelID, 32)) || !bytes.Equal(decoded.Hashlock, common.LeftPadBytes(hashlock, 32)) {
+			t.Fatalf("round trip mismatch: %+v != %+v", decoded, p)
+		}
+	})
+}
+
+func FuzzDecodePromise(f *testing.F) {
+	valid, _ := EncodePromise(PromisePayload{ChainID: 1, Amount: big.NewInt(1), Fee: big.NewInt(0)})
+	f.Add(valid)
+	f.Add([]byte{})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		p, err := DecodePromise(b)
+		if err != nil {
+			return
+		}
+		encoded, err := EncodePromise(p)
+		if err != nil || !bytes.Equal(encoded, b) {
+			t.Fatalf("decoded promise does not encode back: %v", err)
+		}
+	})
+}
+
+func FuzzDecodeExchangeMessage(f *testing.F) {
+	hermes := common.HexToAddress("0x2")
+	valid, _ := EncodeExchangeMessage(ExchangeMessagePayload{ChainID: 1, PromiseHash: make([]byte, 32), AgreementID: big.NewInt(1), AgreementTotal: big.NewInt(1), HermesID: &hermes})
+	f.Add(valid)
+	f.Add(valid[:ExchangeMessageLength])
+	f.Add(valid[:ExchangeMessageLength-1])
+	f.Fuzz(func(t *testing.T, b []byte) {
+		m, err := DecodeExchangeMessage(b)
+		if err != nil {
+			return
+		}
+		encoded, err := EncodeExchangeMessage(m)
+		if err != nil || !bytes.Equal(encoded, b) {
+			t.Fatalf("decoded exchange message does not encode back: %v", err)
+		}
+	})
+}
+
+func FuzzDecodeRegistration(f *testing.F) {
+	valid, _ := EncodeRegistration(RegistrationPayload{ChainID: 137, Stake: big.NewInt(0), Fee: big.NewInt(1)})
+	f.Add(valid)
+	f.Add(valid[1:])
+	f.Fuzz(func(t *testing.T, b []byte) {
+		r, err := DecodeRegistration(b)
+		if err != nil {
+			return
+		}
+		encoded, err := EncodeRegistration(r)
+		if err != nil || !bytes.Equal(encoded, b) {
+			t.Fatalf("decoded registration does not encode back: %v", err)
+		}
+	})
+}
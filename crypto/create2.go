@@ -0,0 +1,50 @@
+package crypto
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// CreateAddress computes the address of a contract deployed with CREATE by the given deployer at the given nonce.
+func CreateAddress(deployer common.Address, nonce uint64) common.Address {
+	return crypto.CreateAddress(deployer, nonce)
+}
+
+// Create2Address computes the address of a contract deployed with CREATE2:
+// keccak("0xff++deployer++salt++keccak(initCode)").
+func Create2Address(deployer common.Address, salt [32]byte, initCode []byte) common.Address {
+	return crypto.CreateAddress2(deployer, salt, crypto.Keccak256(initCode))
+}
+
+// ProxyInitCode returns the bytecode of a minimal proxy contract (EIP 1167) pointing to the given implementation.
+func ProxyInitCode(implementation common.Address) []byte {
+	code := common.FromHex("3d602d80600a3d3981f3363d3d373d3d3d363d73")
+	code = append(code, implementation.Bytes()...)
+	return append(code, common.FromHex("5af43d82803e903d91602b57fd5bf3")...)
+}
+
+// ProxyCreate2Address computes the address of a minimal proxy contract
+// pointing to the given implementation deployed by the given factory using CREATE2.
+func ProxyCreate2Address(factory common.Address, salt [32]byte, implementation common.Address) common.Address {
+	return Create2Address(factory, salt, ProxyInitCode(implementation))
+}
+
+// ChannelSalt returns the CREATE2 salt used by the registry when deploying a consumer channel.
+func ChannelSalt(identity, hermes common.Address) [32]byte {
+	return crypto.Keccak256Hash(identity.Bytes(), hermes.Bytes())
+}
+
+// ChannelAddress computes the address of a consumer channel deployed by the registry.
+func ChannelAddress(identity, hermes, registry, channelImplementation common.Address) common.Address {
+	return ProxyCreate2Address(registry, ChannelSalt(identity, hermes), channelImplementation)
+}
+
+// HermesSalt returns the CREATE2 salt used by the registry when deploying a hermes.
+func HermesSalt(operator common.Address) [32]byte {
+	return common.BytesToHash(operator.Bytes())
+}
+
+// HermesAddress computes the address of a hermes deployed by the registry.
+func HermesAddress(operator, registry, hermesImplementation common.Address) common.Address {
+	return ProxyCreate2Address(registry, HermesSalt(operator), hermesImplementation)
+}
@@ -0,0 +1,86 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAddress(t *testing.T) {
+	deployer := common.HexToAddress("0x6ac7ea33f8831ea9dcc53393aaa88b25a785dbf0")
+	assert.Equal(t, common.HexToAddress("0xcd234a471b72ba2f1ccf0a70fcaba648a5eecd8d"), CreateAddress(deployer, 0))
+	assert.Equal(t, common.HexToAddress("0x343c43a37d37dff08ae8c4a11544c718abb4fcf8"), CreateAddress(deployer, 1))
+	assert.Equal(t, common.HexToAddress("0xf778b86fa74e846c4f0a1fbd1335fe81c00a0c91"), CreateAddress(deployer, 2))
+}
+
+func TestCreate2Address(t *testing.T) {
+	// Examples from EIP-1014.
+	for _, tc := range []struct {
+		deployer, salt, initCode, expected string
+	}{
+		{
+			deployer: "0x0000000000000000000000000000000000000000",
+			salt:     "0x0000000000000000000000000000000000000000000000000000000000000000",
+			initCode: "0x00",
+			expected: "0x4D1A2e2bB4F88F0250f26Ffff098B0b30B26BF38",
+		},
+		{
+			deployer: "0xdeadbeef00000000000000000000000000000000",
+			salt:     "0x000000000000000000000000feed000000000000000000000000000000000000",
+			initCode: "0x00",
+			expected: "0xD04116cDd17beBE565EB2422F2497E06cC1C9833",
+		},
+		{
+			deployer: "0x00000000000000000000000000000000deadbeef",
+			salt:     "0x00000000000000000000000000000000000000000000000000000000cafebabe",
+			initCode: "0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef",
+			expected: "0x1d8bfDC5D46DC4f61D6b6115972536eBE6A8854C",
+		},
+		{
+			deployer: "0x0000000000000000000000000000000000000000",
+			salt:     "0x0000000000000000000000000000000000000000000000000000000000000000",
+			initCode: "0x",
+			expected: "0xE33C0C7F7df4809055C3ebA6c09CFe4BaF1BD9e0",
+		},
+	} {
+		addr := Create2Address(common.HexToAddress(tc.deployer), common.HexToHash(tc.salt), common.FromHex(tc.initCode))
+		assert.Equal(t, common.HexToAddress(tc.expected), addr)
+	}
+}
+
+func TestProxyInitCode(t *testing.T) {
+	implementation := "99a73d53959a8fcbe6e67631d39de3cffd3ac9a2"
+	expected, err := GetProxyCode(implementation)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, ProxyInitCode(common.HexToAddress(implementation)))
+}
+
+func TestFactoryAddresses(t *testing.T) {
+	t.Run("channel", func(t *testing.T) {
+		identity := common.HexToAddress("0x265B4A774A5CE7A975CA8401A43440EFEE58EB15")
+		hermes := common.HexToAddress("0x676b9a084aC11CEeF680AF6FFbE99b24106F47e7")
+		registry := common.HexToAddress("0x6bb8345c9d996be4fab652f4a15813303d630b66")
+		implementation := common.HexToAddress("0x99a73d53959a8fcbe6e67631d39de3cffd3ac9a2")
+
+		expected := common.HexToAddress("0x75bc5ea5f48949032278179132d367f06ab7570e")
+		assert.Equal(t, expected, ChannelAddress(identity, hermes, registry, implementation))
+
+		legacy, err := GenerateChannelAddress(identity.Hex(), hermes.Hex(), registry.Hex(), implementation.Hex())
+		assert.NoError(t, err)
+		assert.Equal(t, expected, common.HexToAddress(legacy))
+	})
+
+	t.Run("hermes", func(t *testing.T) {
+		operator := common.HexToAddress("0x76259c949bee90c3ef6f1d04a3cb50ed0de7763c")
+		registry := common.HexToAddress("0x1ba2df26371e83d87afee2f27a42f5a7fe9e5219")
+		implementation := common.HexToAddress("0xac69e0c98a688e35698630eb0c741eb2a2fc5ef1")
+
+		expected := common.HexToAddress("0xcAeF9A6E9C2d9C0Ee3333529922c280580365b51")
+		assert.Equal(t, expected, HermesAddress(operator, registry, implementation))
+
+		legacy, err := GenerateHermesAddress(operator.Hex(), registry.Hex(), implementation.Hex())
+		assert.NoError(t, err)
+		assert.Equal(t, expected, common.HexToAddress(legacy))
+	})
+}
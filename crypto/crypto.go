@@ -17,26 +17,18 @@
 package crypto
 
 import (
-	"encoding/binary"
 	"encoding/hex"
 	"errors"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mysteriumnetwork/payments/crypto/signatures"
 )
 
 // RecoverAddress recovers the address from message and signature
 func RecoverAddress(message []byte, signature []byte) (common.Address, error) {
-	publicKey, err := crypto.Ecrecover(crypto.Keccak256(message), signature)
-	if err != nil {
-		return common.Address{}, err
-	}
-	pubKey, err := crypto.UnmarshalPubkey(publicKey)
-	if err != nil {
-		return common.Address{}, err
-	}
-	recoveredAddress := crypto.PubkeyToAddress(*pubKey)
-	return recoveredAddress, nil
+	return signatures.RecoverMessage(message, signature)
 }
 
 // GetProxyCode generates bytecode of minimal proxy contract (EIP 1167)
@@ -50,10 +42,8 @@ func deriveCreate2Address(salt string, msgSender string, implementation string)
 		return "", errors.New("msgSender and implementation have to be hex addresses")
 	}
 
-	bytecode, _ := GetProxyCode(ensureNoPrefix(implementation))
-
-	input, _ := hex.DecodeString("ff" + ensureNoPrefix(msgSender) + ensureNoPrefix(salt) + common.Bytes2Hex(crypto.Keccak256(bytecode)))
-	return "0x" + common.Bytes2Hex(crypto.Keccak256(input))[24:], nil
+	addr := ProxyCreate2Address(common.HexToAddress(msgSender), common.HexToHash(salt), common.HexToAddress(implementation))
+	return strings.ToLower(addr.Hex()), nil
 }
 
 // GenerateChannelAddress generate channel address from given identity hash
@@ -121,28 +111,10 @@ func GenerateHermesAddress(operator string, registry string, hermesImplementatio
 
 // ReformatSignatureVForBC takes in the signature and modifies its last byte to correspond to the format required for SC
 func ReformatSignatureVForBC(signature []byte) error {
-	if len(signature) != 65 {
-		return errors.New("the signature must be 65 bytes long")
-	}
-
-	var v = 27 + (uint64(signature[len(signature)-1]) % 2)
-	buffer := make([]byte, 1)
-	_ = binary.PutUvarint(buffer, v)
-	signature[64] = buffer[0]
-
-	return nil
+	return signatures.NormalizeVForBC(signature)
 }
 
 // ReformatSignatureVForRecovery takes in  the signature and modifies its last byte to normalize V to either 0 or 1
 func ReformatSignatureVForRecovery(signature []byte) error {
-	if len(signature) != 65 {
-		return errors.New("the signature must be 65 bytes long")
-	}
-
-	v := uint64(signature[64]) % 27
-	buffer := make([]byte, 1)
-	_ = binary.PutUvarint(buffer, v)
-	signature[64] = buffer[0]
-
-	return nil
+	return signatures.NormalizeVForRecovery(signature)
 }
@@ -24,6 +24,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mysteriumnetwork/payments/crypto/signatures"
 )
 
 const ExitPrefix = "Exit request:"
@@ -54,15 +55,7 @@ func (er *ExitRequest) GetMessage() []byte {
 }
 
 func (er *ExitRequest) RecoverSigner() (common.Address, error) {
-	sig := make([]byte, 65)
-	copy(sig, er.Signature)
-
-	err := ReformatSignatureVForRecovery(sig)
-	if err != nil {
-		return common.Address{}, err
-	}
-
-	return RecoverAddress(er.GetMessage(), sig)
+	return signatures.RecoverMessage(er.GetMessage(), er.Signature)
 }
 
 func (er *ExitRequest) CreateSignature(ks hashSigner, signer common.Address) ([]byte, error) {
@@ -17,7 +17,6 @@
 package crypto
 
 import (
-	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"math/big"
@@ -25,8 +24,9 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mysteriumnetwork/payments/crypto/codec"
+	"github.com/mysteriumnetwork/payments/crypto/signatures"
 )
 
 // ExchangeMessage represent a promise exchange message
@@ -40,9 +40,7 @@ type ExchangeMessage struct {
 	ChainID        int64
 }
 
-type hashSigner interface {
-	SignHash(a accounts.Account, hash []byte) ([]byte, error)
-}
+type hashSigner = signatures.HashSigner
 
 func CreateExchangeMessageWithPromise(chainID int64, invoice Invoice, promise *Promise, hermesID string, ks hashSigner, signer common.Address) (*ExchangeMessage, error) {
 	message := ExchangeMessage{
@@ -115,22 +113,43 @@ func (m ExchangeMessage) GetSignatureBytesRaw() []byte {
 	return signBytes
 }
 
-// GetMessage forms the message of promise exchange request
-func (m ExchangeMessage) GetMessage() []byte {
-	message := []byte{}
-	b := make([]byte, 8)
-	binary.BigEndian.PutUint64(b, uint64(m.ChainID))
-	message = append(message, Pad(b, 32)...)
-	message = append(message, m.Promise.GetHash()...)
-	message = append(message, Pad(math.U256(m.AgreementID).Bytes(), 32)...)
-	message = append(message, Pad(math.U256(m.AgreementTotal).Bytes(), 32)...)
-	message = append(message, common.HexToAddress(m.Provider).Bytes()...)
+// EncodeMessage forms the message of promise exchange request, returning an error if any of the fields is malformed.
+func (m ExchangeMessage) EncodeMessage() ([]byte, error) {
+	promise, err := m.Promise.EncodeMessage()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode promise: %w", err)
+	}
+	provider, err := codec.ParseAddress("provider", m.Provider)
+	if err != nil {
+		return nil, err
+	}
 
+	payload := codec.ExchangeMessagePayload{
+		ChainID:        m.ChainID,
+		PromiseHash:    crypto.Keccak256(promise),
+		AgreementID:    m.AgreementID,
+		AgreementTotal: m.AgreementTotal,
+		Provider:       provider,
+	}
 	// TODO: once all the consumers upgrade, this check needs to go to
 	if m.HermesID != "" {
-		message = append(message, common.HexToAddress(m.HermesID).Bytes()...)
+		hermesID, err := codec.ParseAddress("hermes id", m.HermesID)
+		if err != nil {
+			return nil, err
+		}
+		payload.HermesID = &hermesID
 	}
 
+	return codec.EncodeExchangeMessage(payload)
+}
+
+// GetMessage forms the message of promise exchange request.
+// It returns nil if the message is malformed, use `EncodeMessage` to get the error.
+func (m ExchangeMessage) GetMessage() []byte {
+	message, err := m.EncodeMessage()
+	if err != nil {
+		return nil
+	}
 	return message
 }
 
@@ -141,22 +160,23 @@ func (m ExchangeMessage) GetMessageHash() []byte {
 
 // CreateSignature signs promise using keystore
 func (m ExchangeMessage) CreateSignature(ks hashSigner, signer common.Address) ([]byte, error) {
+	message, err := m.EncodeMessage()
+	if err != nil {
+		return nil, err
+	}
 	return ks.SignHash(
 		accounts.Account{Address: signer},
-		m.GetMessageHash(),
+		crypto.Keccak256(message),
 	)
 }
 
 // RecoverConsumerIdentity recovers the identity from the given request
 func (m ExchangeMessage) RecoverConsumerIdentity() (common.Address, error) {
-	signature := m.GetSignatureBytesRaw()
-
-	err := ReformatSignatureVForRecovery(signature)
+	message, err := m.EncodeMessage()
 	if err != nil {
 		return common.Address{}, err
 	}
-
-	return RecoverAddress(m.GetMessage(), signature)
+	return signatures.RecoverMessage(message, m.GetSignatureBytesRaw())
 }
 
 // IsMessageValid validates if given exchange message was signed by expected identity
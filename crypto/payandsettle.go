@@ -4,10 +4,9 @@ import (
 	"encoding/binary"
 	"math/big"
 
-	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
-	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mysteriumnetwork/payments/crypto/signatures"
 )
 
 type PayAndSettleBeneficiaryPayload struct {
@@ -42,32 +41,16 @@ func (pasp *PayAndSettleBeneficiaryPayload) getMessage() []byte {
 }
 
 func (pasp *PayAndSettleBeneficiaryPayload) Sign(ks hashSigner, signer common.Address) error {
-	message := pasp.getMessage()
-	hash := crypto.Keccak256(message)
-
-	signature, err := ks.SignHash(
-		accounts.Account{Address: signer},
-		hash,
-	)
+	signature, err := signatures.SignMessage(ks, signer, pasp.getMessage())
 	if err != nil {
 		return err
 	}
 
-	ReformatSignatureVForBC(signature)
-
 	pasp.Signature = signature
 	return nil
 }
 
 // RecoverSigner recovers signer address out of promise signature
 func (pasp *PayAndSettleBeneficiaryPayload) RecoverSigner() (common.Address, error) {
-	sig := make([]byte, 65)
-	copy(sig, pasp.Signature)
-
-	err := ReformatSignatureVForRecovery(sig)
-	if err != nil {
-		return common.Address{}, err
-	}
-
-	return RecoverAddress(pasp.getMessage(), sig)
+	return signatures.RecoverMessage(pasp.getMessage(), pasp.Signature)
 }
@@ -17,7 +17,6 @@
 package crypto
 
 import (
-	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"math/big"
@@ -25,8 +24,9 @@ import (
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mysteriumnetwork/payments/crypto/codec"
+	"github.com/mysteriumnetwork/payments/crypto/signatures"
 	"github.com/pkg/errors"
 )
 
@@ -166,16 +166,24 @@ func (p *Promise) Sign(ks *keystore.KeyStore, signer common.Address) error {
 	return nil
 }
 
-// GetMessage forms the message of payment promise
+// EncodeMessage forms the message of payment promise, returning an error if any of the fields is malformed.
+func (p Promise) EncodeMessage() ([]byte, error) {
+	return codec.EncodePromise(codec.PromisePayload{
+		ChainID:   p.ChainID,
+		ChannelID: p.ChannelID,
+		Amount:    p.Amount,
+		Fee:       p.Fee,
+		Hashlock:  p.Hashlock,
+	})
+}
+
+// GetMessage forms the message of payment promise.
+// It returns nil if the promise is malformed, use `EncodeMessage` to get the error.
 func (p Promise) GetMessage() []byte {
-	message := []byte{}
-	b := make([]byte, 8)
-	binary.BigEndian.PutUint64(b, uint64(p.ChainID))
-	message = append(message, Pad(b, 32)...)
-	message = append(message, Pad(p.ChannelID, 32)...)
-	message = append(message, Pad(math.U256(p.Amount).Bytes(), 32)...)
-	message = append(message, Pad(math.U256(p.Fee).Bytes(), 32)...)
-	message = append(message, Pad(p.Hashlock, 32)...)
+	message, err := p.EncodeMessage()
+	if err != nil {
+		return nil
+	}
 	return message
 }
 
@@ -186,7 +194,10 @@ func (p Promise) GetHash() []byte {
 
 // CreateSignature signs promise using keystore
 func (p Promise) CreateSignature(ks hashSigner, signer common.Address) ([]byte, error) {
-	message := p.GetMessage()
+	message, err := p.EncodeMessage()
+	if err != nil {
+		return nil, err
+	}
 	hash := crypto.Keccak256(message)
 	return ks.SignHash(
 		accounts.Account{Address: signer},
@@ -201,15 +212,7 @@ func (p Promise) GetSignatureHexString() string {
 
 // IsPromiseValid validates if given promise params are properly signed
 func (p Promise) IsPromiseValid(expectedSigner common.Address) bool {
-	sig := make([]byte, 65)
-	copy(sig, p.Signature)
-
-	err := ReformatSignatureVForRecovery(sig)
-	if err != nil {
-		return false
-	}
-
-	recoveredSigner, err := RecoverAddress(p.GetMessage(), sig)
+	recoveredSigner, err := p.RecoverSigner()
 	if err != nil {
 		return false
 	}
@@ -219,12 +222,9 @@ func (p Promise) IsPromiseValid(expectedSigner common.Address) bool {
 
 // RecoverSigner recovers signer address out of promise signature
 func (p Promise) RecoverSigner() (common.Address, error) {
-	sig := make([]byte, 65)
-	copy(sig, p.Signature)
-
-	err := ReformatSignatureVForRecovery(sig)
+	message, err := p.EncodeMessage()
 	if err != nil {
 		return common.Address{}, err
 	}
-	return RecoverAddress(p.GetMessage(), sig)
+	return signatures.RecoverMessage(message, p.Signature)
 }
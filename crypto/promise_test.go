@@ -310,3 +310,14 @@ func getParams(userType string) Params {
 		Provider:                 provider,
 	}
 }
+
+func TestEncodeMalformedPromise(t *testing.T) {
+	p := Promise{ChannelID: make([]byte, 33), ChainID: 1, Amount: big.NewInt(1), Fee: big.NewInt(0)}
+	_, err := p.EncodeMessage()
+	assert.Error(t, err)
+	assert.Nil(t, p.GetMessage())
+
+	_, err = p.RecoverSigner()
+	assert.Error(t, err)
+	assert.False(t, p.IsPromiseValid(common.Address{}))
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mysteriumnetwork/payments/crypto/signatures"
 )
 
 type ReferralTokenRequest struct {
@@ -55,16 +56,10 @@ func (rtr ReferralTokenRequest) IsValid() error {
 		return err
 	}
 
-	k, err := crypto.Ecrecover(crypto.Keccak256(rtr.Identity.Bytes()), b)
+	recoveredAddress, err := signatures.RecoverMessage(rtr.Identity.Bytes(), b)
 	if err != nil {
 		return err
 	}
-	pubKey, err := crypto.UnmarshalPubkey(k)
-	if err != nil {
-		return err
-	}
-
-	recoveredAddress := crypto.PubkeyToAddress(*pubKey)
 
 	if !bytes.Equal(rtr.Identity.Bytes(), recoveredAddress.Bytes()) {
 		return errors.New("identities do not match")
@@ -0,0 +1,140 @@
+// Package signatures holds the hashing, signing and recovery conventions
+// used by all of the signed payloads in this library.
+//
+// Signatures produced by go-ethereum signers have V set to 0 or 1, while the
+// smart contracts expect 27 or 28. Signing helpers in this package return
+// signatures in the smart contract format and recovery helpers accept both.
+package signatures
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// SignatureLength is the length of an ECDSA signature in [R || S || V] format.
+const SignatureLength = crypto.SignatureLength
+
+// ErrInvalidLength is returned if the given signature is not 65 bytes long.
+var ErrInvalidLength = errors.New("the signature must be 65 bytes long")
+
+// HashSigner signs the given hash using the given account, keystore satisfies it.
+type HashSigner interface {
+	SignHash(a accounts.Account, hash []byte) ([]byte, error)
+}
+
+// Keccak returns a keccak256 hash of the given message.
+// This is the hashing convention used by all of the raw payloads.
+func Keccak(message []byte) []byte {
+	return crypto.Keccak256(message)
+}
+
+// PersonalHash returns an EIP-191 personal_sign hash of the given message.
+func PersonalHash(message []byte) []byte {
+	return accounts.TextHash(message)
+}
+
+// TypedDataHash returns an EIP-712 hash of the given typed data.
+func TypedDataHash(data apitypes.TypedData) ([]byte, error) {
+	hash, _, err := apitypes.TypedDataAndHash(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash typed data: %w", err)
+	}
+	return hash, nil
+}
+
+// NormalizeVForBC modifies the last byte of the signature so that V is 27 or 28 as expected by smart contracts.
+func NormalizeVForBC(signature []byte) error {
+	if len(signature) != SignatureLength {
+		return ErrInvalidLength
+	}
+
+	if signature[64] < 27 {
+		signature[64] += 27
+	}
+	return nil
+}
+
+// NormalizeVForRecovery modifies the last byte of the signature so that V is 0 or 1 as expected by ecrecover.
+func NormalizeVForRecovery(signature []byte) error {
+	if len(signature) != SignatureLength {
+		return ErrInvalidLength
+	}
+
+	signature[64] %= 27
+	return nil
+}
+
+// SignHash signs the given hash and returns the signature in the smart contract format.
+func SignHash(ks HashSigner, signer common.Address, hash []byte) ([]byte, error) {
+	signature, err := ks.SignHash(accounts.Account{Address: signer}, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := NormalizeVForBC(signature); err != nil {
+		return nil, err
+	}
+	return signature, nil
+}
+
+// SignMessage signs a keccak256 hash of the given message.
+func SignMessage(ks HashSigner, signer common.Address, message []byte) ([]byte, error) {
+	return SignHash(ks, signer, Keccak(message))
+}
+
+// SignPersonal signs an EIP-191 personal_sign hash of the given message.
+func SignPersonal(ks HashSigner, signer common.Address, message []byte) ([]byte, error) {
+	return SignHash(ks, signer, PersonalHash(message))
+}
+
+// SignTypedData signs an EIP-712 hash of the given typed data.
+func SignTypedData(ks HashSigner, signer common.Address, data apitypes.TypedData) ([]byte, error) {
+	hash, err := TypedDataHash(data)
+	if err != nil {
+		return nil, err
+	}
+	return SignHash(ks, signer, hash)
+}
+
+// Recover recovers the signer of the given hash. The given signature is not modified.
+func Recover(hash, signature []byte) (common.Address, error) {
+	if len(signature) != SignatureLength {
+		return common.Address{}, ErrInvalidLength
+	}
+
+	sig := make([]byte, SignatureLength)
+	copy(sig, signature)
+	if err := NormalizeVForRecovery(sig); err != nil {
+		return common.Address{}, err
+	}
+
+	publicKey, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*publicKey), nil
+}
+
+// RecoverMessage recovers the signer of a keccak256 hash of the given message.
+func RecoverMessage(message, signature []byte) (common.Address, error) {
+	return Recover(Keccak(message), signature)
+}
+
+// RecoverPersonal recovers the signer of an EIP-191 personal_sign hash of the given message.
+func RecoverPersonal(message, signature []byte) (common.Address, error) {
+	return Recover(PersonalHash(message), signature)
+}
+
+// RecoverTypedData recovers the signer of an EIP-712 hash of the given typed data.
+func RecoverTypedData(data apitypes.TypedData, signature []byte) (common.Address, error) {
+	hash, err := TypedDataHash(data)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return Recover(hash, signature)
+}
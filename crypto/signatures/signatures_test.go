@@ -0,0 +1,120 @@
+package signatures
+
+import (
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/assert"
+)
+
+type pkHashSigner struct {
+	pk *ecdsa.PrivateKey
+}
+
+func (phs *pkHashSigner) SignHash(a accounts.Account, hash []byte) ([]byte, error) {
+	return crypto.Sign(hash, phs.pk)
+}
+
+func newSigner(t *testing.T) (*pkHashSigner, common.Address) {
+	pk, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	return &pkHashSigner{pk: pk}, crypto.PubkeyToAddress(pk.PublicKey)
+}
+
+func TestNormalizeV(t *testing.T) {
+	for _, tc := range []struct {
+		in, bc, recovery byte
+	}{
+		{in: 0, bc: 27, recovery: 0},
+		{in: 1, bc: 28, recovery: 1},
+		{in: 27, bc: 27, recovery: 0},
+		{in: 28, bc: 28, recovery: 1},
+	} {
+		sig := make([]byte, SignatureLength)
+		sig[64] = tc.in
+		assert.NoError(t, NormalizeVForBC(sig))
+		assert.Equal(t, tc.bc, sig[64])
+
+		sig[64] = tc.in
+		assert.NoError(t, NormalizeVForRecovery(sig))
+		assert.Equal(t, tc.recovery, sig[64])
+	}
+
+	assert.ErrorIs(t, NormalizeVForBC(make([]byte, 64)), ErrInvalidLength)
+	assert.ErrorIs(t, NormalizeVForRecovery(make([]byte, 66)), ErrInvalidLength)
+}
+
+func TestSignAndRecover(t *testing.T) {
+	ks, signer := newSigner(t)
+	message := []byte("mysterium")
+
+	t.Run("raw keccak", func(t *testing.T) {
+		sig, err := SignMessage(ks, signer, message)
+		assert.NoError(t, err)
+		assert.Contains(t, []byte{27, 28}, sig[64])
+
+		recovered, err := RecoverMessage(message, sig)
+		assert.NoError(t, err)
+		assert.Equal(t, signer, recovered)
+		assert.Contains(t, []byte{27, 28}, sig[64], "recovery must not modify the signature")
+
+		other, err := RecoverPersonal(message, sig)
+		assert.NoError(t, err)
+		assert.NotEqual(t, signer, other)
+	})
+
+	t.Run("personal", func(t *testing.T) {
+		sig, err := SignPersonal(ks, signer, message)
+		assert.NoError(t, err)
+
+		recovered, err := RecoverPersonal(message, sig)
+		assert.NoError(t, err)
+		assert.Equal(t, signer, recovered)
+	})
+
+	t.Run("typed data", func(t *testing.T) {
+		data := apitypes.TypedData{
+			Types: apitypes.Types{
+				"EIP712Domain": {
+					{Name: "name", Type: "string"},
+					{Name: "chainId", Type: "uint256"},
+				},
+				"Transfer": {
+					{Name: "to", Type: "address"},
+					{Name: "amount", Type: "uint256"},
+				},
+			},
+			PrimaryType: "Transfer",
+			Domain: apitypes.TypedDataDomain{
+				Name:    "payments",
+				ChainId: math.NewHexOrDecimal256(137),
+			},
+			Message: apitypes.TypedDataMessage{
+				"to":     "0x0000000000000000000000000000000000000001",
+				"amount": "100",
+			},
+		}
+
+		sig, err := SignTypedData(ks, signer, data)
+		assert.NoError(t, err)
+
+		recovered, err := RecoverTypedData(data, sig)
+		assert.NoError(t, err)
+		assert.Equal(t, signer, recovered)
+
+		data.Message["amount"] = "101"
+		tampered, err := RecoverTypedData(data, sig)
+		assert.NoError(t, err)
+		assert.NotEqual(t, signer, tampered)
+	})
+
+	t.Run("invalid length", func(t *testing.T) {
+		_, err := RecoverMessage(message, make([]byte, 10))
+		assert.ErrorIs(t, err, ErrInvalidLength)
+	})
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mysteriumnetwork/payments/crypto/signatures"
 )
 
 type SignedIdentityRequest struct {
@@ -53,16 +54,5 @@ func (sir SignedIdentityRequest) RecoverSigner() (common.Address, error) {
 		return common.Address{}, err
 	}
 
-	h := crypto.Keccak256(sir.Identity.Bytes())
-	k, err := crypto.Ecrecover(h, b)
-	if err != nil {
-		return common.Address{}, err
-	}
-	pubKey, err := crypto.UnmarshalPubkey(k)
-	if err != nil {
-		return common.Address{}, err
-	}
-
-	recoveredAddress := crypto.PubkeyToAddress(*pubKey)
-	return recoveredAddress, nil
+	return signatures.RecoverMessage(sir.Identity.Bytes(), b)
 }
@@ -25,6 +25,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mysteriumnetwork/payments/crypto/signatures"
 )
 
 const stakeReturnPrefix = "Stake return request"
@@ -92,13 +93,5 @@ func (dpsr DecreaseProviderStakeRequest) IsValid(expectedSigner common.Address)
 
 // RecoverSigner recovers signer address out of request signature.
 func (dpsr DecreaseProviderStakeRequest) RecoverSigner() (common.Address, error) {
-	sig := make([]byte, 65)
-	copy(sig, dpsr.Signature)
-
-	err := ReformatSignatureVForRecovery(sig)
-	if err != nil {
-		return common.Address{}, err
-	}
-
-	return RecoverAddress(dpsr.GetMessage(), sig)
+	return signatures.RecoverMessage(dpsr.GetMessage(), dpsr.Signature)
 }
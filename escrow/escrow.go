@@ -0,0 +1,210 @@
+package escrow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/mysteriumnetwork/payments/bindings/escrow"
+)
+
+// State is the on-chain state of an escrow.
+type State uint8
+
+const (
+	StateNone State = iota
+	StateFunded
+	StateReleased
+	StateRefunded
+)
+
+func (s State) String() string {
+	switch s {
+	case StateFunded:
+		return "funded"
+	case StateReleased:
+		return "released"
+	case StateRefunded:
+		return "refunded"
+	default:
+		return "none"
+	}
+}
+
+var (
+	// ErrUnknownEvent is returned when parsing a log which is not an escrow event.
+	ErrUnknownEvent = errors.New("unknown escrow event")
+	// ErrNoBytecode is returned when deploying without the compiled contract.
+	ErrNoBytecode = errors.New("escrow bytecode is required")
+)
+
+// Escrow is a single conditional payment held by the escrow contract.
+type Escrow struct {
+	ID      [32]byte
+	Payer   common.Address
+	Payee   common.Address
+	Arbiter common.Address
+	Token   common.Address
+	Amount  *big.Int
+	// Deadline is the unix time after which the payer can refund without the arbiter.
+	Deadline *big.Int
+	State    State
+}
+
+// Client reads and writes to a deployed escrow contract.
+type Client struct {
+	address  common.Address
+	contract *escrow.Escrow
+}
+
+// NewClient returns a client of the escrow contract deployed at the given address.
+func NewClient(address common.Address, backend bind.ContractBackend) (*Client, error) {
+	contract, err := escrow.NewEscrow(address, backend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind escrow contract: %w", err)
+	}
+	return &Client{address: address, contract: contract}, nil
+}
+
+// Deploy deploys the escrow contract using the given compiled bytecode.
+// The bytecode must implement the `escrow.EscrowMetaData` ABI.
+func Deploy(opts *bind.TransactOpts, backend bind.ContractBackend, bytecode []byte) (common.Address, *types.Transaction, *Client, error) {
+	if len(bytecode) == 0 {
+		return common.Address{}, nil, nil, ErrNoBytecode
+	}
+
+	parsed, err := escrow.EscrowMetaData.GetAbi()
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+
+	address, tx, _, err := bind.DeployContract(opts, *parsed, bytecode, backend)
+	if err != nil {
+		return common.Address{}, nil, nil, fmt.Errorf("failed to deploy escrow contract: %w", err)
+	}
+
+	c, err := NewClient(address, backend)
+	return address, tx, c, err
+}
+
+// Address returns the address of the escrow contract.
+func (c *Client) Address() common.Address {
+	return c.address
+}
+
+// Get returns the escrow with the given ID.
+func (c *Client) Get(ctx context.Context, id [32]byte) (Escrow, error) {
+	res, err := c.contract.GetEscrow(&bind.CallOpts{Context: ctx}, id)
+	if err != nil {
+		return Escrow{}, fmt.Errorf("failed to get escrow: %w", err)
+	}
+
+	return Escrow{
+		ID:       id,
+		Payer:    res.Payer,
+		Payee:    res.Payee,
+		Arbiter:  res.Arbiter,
+		Token:    res.Token,
+		Amount:   res.Amount,
+		Deadline: res.Deadline,
+		State:    State(res.State),
+	}, nil
+}
+
+// Fund locks the amount of tokens from the sender for the payee. The token allowance must be set beforehand.
+func (c *Client) Fund(opts *bind.TransactOpts, req FundRequest) (*types.Transaction, error) {
+	return c.contract.Fund(opts, req.ID, req.Payee, req.Arbiter, req.Token, req.Amount, big.NewInt(req.Deadline))
+}
+
+// Release pays out the escrow to the payee. Can be called by the payer or the arbiter.
+func (c *Client) Release(opts *bind.TransactOpts, id [32]byte) (*types.Transaction, error) {
+	return c.contract.Release(opts, id)
+}
+
+// Refund returns the escrow to the payer. Can be called by the payee or the arbiter, or by anyone after the deadline.
+func (c *Client) Refund(opts *bind.TransactOpts, id [32]byte) (*types.Transaction, error) {
+	return c.contract.Refund(opts, id)
+}
+
+// EventType is a type of escrow event.
+type EventType string
+
+const (
+	EventFunded   EventType = "funded"
+	EventReleased EventType = "released"
+	EventRefunded EventType = "refunded"
+)
+
+// Event is a typed escrow event.
+type Event struct {
+	Type EventType
+	ID   [32]byte
+	// Account is the payer for funded and refunded events and the payee for released events.
+	Account common.Address
+	Amount  *big.Int
+	// Funded is set for funded events.
+	Funded *escrow.EscrowFunded
+	Log    types.Log
+}
+
+var escrowABI = mustParseABI()
+
+func mustParseABI() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(escrow.EscrowMetaData.ABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// Topics returns the topics of all escrow events, useful for log filters.
+func Topics() []common.Hash {
+	return []common.Hash{
+		escrowABI.Events["Funded"].ID,
+		escrowABI.Events["Released"].ID,
+		escrowABI.Events["Refunded"].ID,
+	}
+}
+
+// ParseEvent parses an escrow contract log into a typed event.
+func ParseEvent(log types.Log) (Event, error) {
+	if len(log.Topics) == 0 {
+		return Event{}, ErrUnknownEvent
+	}
+
+	// The filterer is only used for parsing which does not touch the backend.
+	filterer, err := escrow.NewEscrowFilterer(log.Address, nil)
+	if err != nil {
+		return Event{}, err
+	}
+
+	switch log.Topics[0] {
+	case escrowABI.Events["Funded"].ID:
+		ev, err := filterer.ParseFunded(log)
+		if err != nil {
+			return Event{}, err
+		}
+		return Event{Type: EventFunded, ID: ev.Id, Account: ev.Payer, Amount: ev.Amount, Funded: ev, Log: log}, nil
+	case escrowABI.Events["Released"].ID:
+		ev, err := filterer.ParseReleased(log)
+		if err != nil {
+			return Event{}, err
+		}
+		return Event{Type: EventReleased, ID: ev.Id, Account: ev.Payee, Amount: ev.Amount, Log: log}, nil
+	case escrowABI.Events["Refunded"].ID:
+		ev, err := filterer.ParseRefunded(log)
+		if err != nil {
+			return Event{}, err
+		}
+		return Event{Type: EventRefunded, ID: ev.Id, Account: ev.Payer, Amount: ev.Amount, Log: log}, nil
+	default:
+		return Event{}, ErrUnknownEvent
+	}
+}
@@ -0,0 +1,90 @@
+package escrow
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/transaction"
+)
+
+type queueMock struct {
+	requests []transaction.DeliveryRequest
+}
+
+func (q *queueMock) EnqueueDelivery(req transaction.DeliveryRequest, _ bool) (string, error) {
+	q.requests = append(q.requests, req)
+	return "id", nil
+}
+
+func TestParseEvent(t *testing.T) {
+	id := common.HexToHash("0x1")
+	payer := common.HexToAddress("0x2")
+
+	data, err := escrowABI.Events["Refunded"].Inputs.NonIndexed().Pack(big.NewInt(10))
+	assert.NoError(t, err)
+
+	ev, err := ParseEvent(types.Log{
+		Topics: []common.Hash{escrowABI.Events["Refunded"].ID, id, common.BytesToHash(payer.Bytes())},
+		Data:   data,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, EventRefunded, ev.Type)
+	assert.Equal(t, [32]byte(id), ev.ID)
+	assert.Equal(t, payer, ev.Account)
+	assert.Equal(t, big.NewInt(10), ev.Amount)
+
+	_, err = ParseEvent(types.Log{Topics: []common.Hash{common.HexToHash("0x3")}})
+	assert.ErrorIs(t, err, ErrUnknownEvent)
+	assert.Len(t, Topics(), 3)
+}
+
+func TestFlows(t *testing.T) {
+	q := &queueMock{}
+	f := NewFlows(q, 137, common.HexToAddress("0x1"))
+	req := FundRequest{Escrow: common.HexToAddress("0x2"), ID: common.HexToHash("0x3"), Token: common.HexToAddress("0x4"), Amount: big.NewInt(5), Deadline: 100}
+
+	_, err := f.Fund(FundRequest{})
+	assert.Error(t, err)
+
+	id, err := f.Fund(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "id", id)
+	_, err = f.Release(SettleRequest{Escrow: req.Escrow, ID: req.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, DeliverableFund, q.requests[0].Type)
+	assert.Equal(t, DeliverableRelease, q.requests[1].Type)
+	assert.True(t, CanDeliver(q.requests[0].Type))
+	assert.False(t, CanDeliver("other"))
+
+	t.Run("deliver", func(t *testing.T) {
+		blob, err := json.Marshal(q.requests[0].Data)
+		assert.NoError(t, err)
+
+		opts := &bind.TransactOpts{
+			From:     common.HexToAddress("0x1"),
+			Nonce:    big.NewInt(1),
+			GasPrice: big.NewInt(1),
+			GasLimit: 100000,
+			NoSend:   true,
+			Signer:   func(_ common.Address, tx *types.Transaction) (*types.Transaction, error) { return tx, nil },
+		}
+		tx, err := Deliver(opts, nil, DeliverableFund, blob)
+		assert.NoError(t, err)
+		assert.Equal(t, req.Escrow, *tx.To())
+		assert.Equal(t, escrowABI.Methods["fund"].ID, tx.Data()[:4])
+
+		_, err = Deliver(opts, nil, "other", blob)
+		assert.Error(t, err)
+	})
+
+	t.Run("deploy requires bytecode", func(t *testing.T) {
+		_, _, _, err := Deploy(&bind.TransactOpts{}, nil, nil)
+		assert.ErrorIs(t, err, ErrNoBytecode)
+	})
+}
@@ -0,0 +1,122 @@
+package escrow
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/mysteriumnetwork/payments/transaction"
+)
+
+// Delivery types used when enqueueing escrow transactions.
+const (
+	DeliverableFund    transaction.DeliverableType = "escrow_fund"
+	DeliverableRelease transaction.DeliverableType = "escrow_release"
+	DeliverableRefund  transaction.DeliverableType = "escrow_refund"
+)
+
+// FundRequest locks tokens in a new escrow.
+type FundRequest struct {
+	Escrow   common.Address `json:"escrow"`
+	ID       [32]byte       `json:"id"`
+	Payee    common.Address `json:"payee"`
+	Arbiter  common.Address `json:"arbiter"`
+	Token    common.Address `json:"token"`
+	Amount   *big.Int       `json:"amount"`
+	Deadline int64          `json:"deadline"`
+}
+
+// SettleRequest releases or refunds an existing escrow.
+type SettleRequest struct {
+	Escrow common.Address `json:"escrow"`
+	ID     [32]byte       `json:"id"`
+}
+
+// Queue enqueues transactions to be sent, implemented by `transaction.Depot`.
+type Queue interface {
+	EnqueueDelivery(req transaction.DeliveryRequest, force bool) (string, error)
+}
+
+// Flows enqueues escrow transactions sent from the given account.
+type Flows struct {
+	queue   Queue
+	chainID int64
+	sender  common.Address
+}
+
+// NewFlows returns escrow flows sending transactions on the given chain.
+func NewFlows(queue Queue, chainID int64, sender common.Address) *Flows {
+	return &Flows{queue: queue, chainID: chainID, sender: sender}
+}
+
+// Fund enqueues funding of a new escrow returning the tracking ID.
+func (f *Flows) Fund(req FundRequest) (string, error) {
+	if req.Amount == nil || req.Amount.Sign() <= 0 {
+		return "", fmt.Errorf("escrow amount must be positive")
+	}
+	return f.enqueue(DeliverableFund, req)
+}
+
+// Release enqueues the release of an escrow to the payee.
+func (f *Flows) Release(req SettleRequest) (string, error) {
+	return f.enqueue(DeliverableRelease, req)
+}
+
+// Refund enqueues the refund of an escrow to the payer.
+func (f *Flows) Refund(req SettleRequest) (string, error) {
+	return f.enqueue(DeliverableRefund, req)
+}
+
+func (f *Flows) enqueue(typ transaction.DeliverableType, data interface{}) (string, error) {
+	return f.queue.EnqueueDelivery(transaction.DeliveryRequest{
+		ChainID: f.chainID,
+		Sender:  f.sender,
+		Type:    typ,
+		Data:    data,
+	}, false)
+}
+
+// CanDeliver returns true for delivery types created by `Flows`, useful when implementing couriers.
+func CanDeliver(typ transaction.DeliverableType) bool {
+	switch typ {
+	case DeliverableFund, DeliverableRelease, DeliverableRefund:
+		return true
+	default:
+		return false
+	}
+}
+
+// Deliver creates the transaction for a delivery created by `Flows`, useful when implementing couriers.
+func Deliver(opts *bind.TransactOpts, backend bind.ContractBackend, typ transaction.DeliverableType, data []byte) (*types.Transaction, error) {
+	switch typ {
+	case DeliverableFund:
+		var req FundRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			return nil, fmt.Errorf("failed to decode fund request: %w", err)
+		}
+		c, err := NewClient(req.Escrow, backend)
+		if err != nil {
+			return nil, err
+		}
+		return c.Fund(opts, req)
+	case DeliverableRelease, DeliverableRefund:
+		var req SettleRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			return nil, fmt.Errorf("failed to decode escrow request: %w", err)
+		}
+		c, err := NewClient(req.Escrow, backend)
+		if err != nil {
+			return nil, err
+		}
+		if typ == DeliverableRelease {
+			return c.Release(opts, req.ID)
+		}
+		return c.Refund(opts, req.ID)
+	default:
+		return nil, fmt.Errorf("unsupported delivery type %q", typ)
+	}
+}
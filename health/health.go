@@ -0,0 +1,289 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/payments/exchange"
+	"github.com/mysteriumnetwork/payments/transaction/gas"
+)
+
+// Kind is the kind of external dependency.
+type Kind string
+
+const (
+	KindRPC    Kind = "rpc"
+	KindGas    Kind = "gas"
+	KindHermes Kind = "hermes"
+	KindRates  Kind = "rates"
+)
+
+// Status is the health status of a dependency or of all of them.
+type Status string
+
+const (
+	// StatusUnknown means the dependency was not checked yet.
+	StatusUnknown Status = "unknown"
+	// StatusUp means the last check succeeded in time.
+	StatusUp Status = "up"
+	// StatusDegraded means the last check succeeded but was slower than the configured threshold.
+	StatusDegraded Status = "degraded"
+	// StatusDown means the last check failed.
+	StatusDown Status = "down"
+)
+
+func (s Status) severity() int {
+	switch s {
+	case StatusUp:
+		return 0
+	case StatusDegraded:
+		return 1
+	case StatusUnknown:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// Checker checks a single dependency.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc allows using a function as a `Checker`.
+type CheckerFunc func(ctx context.Context) error
+
+// Check calls the function.
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Dependency identifies an external dependency.
+type Dependency struct {
+	Name    string `json:"name"`
+	Kind    Kind   `json:"kind"`
+	ChainID int64  `json:"chainID,omitempty"`
+}
+
+// DependencyHealth is the health of a single dependency.
+type DependencyHealth struct {
+	Dependency
+	Status      Status        `json:"status"`
+	Latency     time.Duration `json:"latency"`
+	LastCheck   time.Time     `json:"lastCheck"`
+	LastSuccess time.Time     `json:"lastSuccess"`
+	LastError   string        `json:"lastError,omitempty"`
+}
+
+// Report is the aggregated health of all dependencies.
+type Report struct {
+	Status       Status             `json:"status"`
+	Dependencies []DependencyHealth `json:"dependencies"`
+}
+
+// Config configures the aggregator.
+type Config struct {
+	// Interval between checks once `Run` is called.
+	Interval time.Duration
+	// Timeout of a single check.
+	Timeout time.Duration
+	// SlowThreshold is the latency above which a successful check reports the dependency as degraded.
+	// Zero disables it.
+	SlowThreshold time.Duration
+}
+
+type registered struct {
+	checker Checker
+	health  DependencyHealth
+}
+
+// Aggregator periodically checks registered dependencies and aggregates their health.
+type Aggregator struct {
+	cfg  Config
+	deps map[Dependency]*registered
+	mu   sync.Mutex
+
+	now func() time.Time
+
+	once sync.Once
+	stop chan struct{}
+}
+
+// NewAggregator returns a new health aggregator.
+func NewAggregator(cfg Config) *Aggregator {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	return &Aggregator{
+		cfg:  cfg,
+		deps: make(map[Dependency]*registered),
+		now:  time.Now,
+		stop: make(chan struct{}),
+	}
+}
+
+// Register adds a dependency to be checked, replacing a previously registered one with the same identity.
+func (a *Aggregator) Register(dep Dependency, c Checker) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.deps[dep] = &registered{
+		checker: c,
+		health:  DependencyHealth{Dependency: dep, Status: StatusUnknown},
+	}
+}
+
+// Run will spawn a goroutine which checks all of the dependencies every interval.
+func (a *Aggregator) Run() {
+	go func() {
+		for {
+			a.CheckAll(context.Background())
+			select {
+			case <-a.stop:
+				return
+			case <-time.After(a.cfg.Interval):
+			}
+		}
+	}()
+}
+
+// Stop stops the check loop.
+func (a *Aggregator) Stop() {
+	a.once.Do(func() {
+		close(a.stop)
+	})
+}
+
+// CheckAll checks every registered dependency concurrently.
+func (a *Aggregator) CheckAll(ctx context.Context) {
+	a.mu.Lock()
+	checks := make(map[Dependency]Checker, len(a.deps))
+	for dep, r := range a.deps {
+		checks[dep] = r.checker
+	}
+	a.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for dep, c := range checks {
+		wg.Add(1)
+		go func(dep Dependency, c Checker) {
+			defer wg.Done()
+			a.check(ctx, dep, c)
+		}(dep, c)
+	}
+	wg.Wait()
+}
+
+func (a *Aggregator) check(ctx context.Context, dep Dependency, c Checker) {
+	ctx, cancel := context.WithTimeout(ctx, a.cfg.Timeout)
+	defer cancel()
+
+	start := a.now()
+	err := c.Check(ctx)
+	end := a.now()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	r, ok := a.deps[dep]
+	if !ok {
+		return
+	}
+
+	h := &r.health
+	h.Latency = end.Sub(start)
+	h.LastCheck = end
+	switch {
+	case err != nil:
+		h.Status = StatusDown
+		h.LastError = err.Error()
+	case a.cfg.SlowThreshold > 0 && h.Latency > a.cfg.SlowThreshold:
+		h.Status = StatusDegraded
+		h.LastSuccess = end
+		h.LastError = ""
+	default:
+		h.Status = StatusUp
+		h.LastSuccess = end
+		h.LastError = ""
+	}
+}
+
+// Health returns the health of every dependency as of the last check.
+// The overall status is the worst status of any dependency.
+func (a *Aggregator) Health() Report {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	rep := Report{Status: StatusUp, Dependencies: make([]DependencyHealth, 0, len(a.deps))}
+	for _, r := range a.deps {
+		rep.Dependencies = append(rep.Dependencies, r.health)
+		if r.health.Status.severity() > rep.Status.severity() {
+			rep.Status = r.health.Status
+		}
+	}
+	sort.Slice(rep.Dependencies, func(i, j int) bool {
+		di, dj := rep.Dependencies[i], rep.Dependencies[j]
+		if di.Kind != dj.Kind {
+			return di.Kind < dj.Kind
+		}
+		if di.ChainID != dj.ChainID {
+			return di.ChainID < dj.ChainID
+		}
+		return di.Name < dj.Name
+	})
+	return rep
+}
+
+// BlockNumberReader reads the latest block number, `client.EthMultiClient` and `ethclient.Client` satisfy it.
+type BlockNumberReader interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// RPC returns a checker which reads the latest block number from the endpoint.
+func RPC(c BlockNumberReader) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		_, err := c.BlockNumber(ctx)
+		return err
+	})
+}
+
+// GasStation returns a checker which fetches gas prices from the station.
+func GasStation(s gas.Station) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		_, err := gas.GetGasPricesContext(ctx, s)
+		return err
+	})
+}
+
+// Rates returns a checker which fetches a rate from the exchange API skipping its cache.
+func Rates(api exchange.API, coin exchange.Coin, currency exchange.Currency) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		_, err := api.GetRate([]exchange.Coin{coin}, []exchange.Currency{currency})
+		return err
+	})
+}
+
+// HTTP returns a checker which expects a successful response to a GET request of the url,
+// for example the hermes API status endpoint.
+func HTTP(c *http.Client, url string) Checker {
+	if c == nil {
+		c = http.DefaultClient
+	}
+	return CheckerFunc(func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := c.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+		}
+		return nil
+	})
+}
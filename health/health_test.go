@@ -0,0 +1,94 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/transaction/gas"
+)
+
+type blockReaderMock struct {
+	err error
+}
+
+func (b *blockReaderMock) BlockNumber(context.Context) (uint64, error) {
+	return 1, b.err
+}
+
+func TestAggregator(t *testing.T) {
+	now := time.Unix(1000, 0)
+	a := NewAggregator(Config{SlowThreshold: time.Second})
+	a.now = func() time.Time { return now }
+
+	rpc := &blockReaderMock{}
+	a.Register(Dependency{Name: "https://polygon-rpc.com", Kind: KindRPC, ChainID: 137}, RPC(rpc))
+	a.Register(Dependency{Name: "static", Kind: KindGas, ChainID: 137}, GasStation(gas.NewStaticStation(big.NewInt(1), big.NewInt(1))))
+
+	t.Run("unknown before first check", func(t *testing.T) {
+		rep := a.Health()
+		assert.Equal(t, StatusUnknown, rep.Status)
+		assert.Len(t, rep.Dependencies, 2)
+		assert.Equal(t, KindGas, rep.Dependencies[0].Kind)
+	})
+
+	t.Run("up", func(t *testing.T) {
+		a.CheckAll(context.Background())
+		rep := a.Health()
+		assert.Equal(t, StatusUp, rep.Status)
+		for _, d := range rep.Dependencies {
+			assert.Equal(t, now, d.LastSuccess)
+		}
+	})
+
+	t.Run("down keeps last success", func(t *testing.T) {
+		rpc.err = errors.New("connection refused")
+		now = now.Add(time.Minute)
+		a.CheckAll(context.Background())
+
+		rep := a.Health()
+		assert.Equal(t, StatusDown, rep.Status)
+		d := rep.Dependencies[1]
+		assert.Equal(t, StatusDown, d.Status)
+		assert.Equal(t, "connection refused", d.LastError)
+		assert.Equal(t, now, d.LastCheck)
+		assert.Equal(t, now.Add(-time.Minute), d.LastSuccess)
+	})
+
+	t.Run("degraded when slow", func(t *testing.T) {
+		slow := NewAggregator(Config{SlowThreshold: time.Second})
+		calls := 0
+		slow.now = func() time.Time {
+			calls++
+			return time.Unix(int64(calls)*2, 0)
+		}
+		slow.Register(Dependency{Name: "hermes", Kind: KindHermes}, CheckerFunc(func(context.Context) error { return nil }))
+		slow.CheckAll(context.Background())
+
+		rep := slow.Health()
+		assert.Equal(t, StatusDegraded, rep.Status)
+		assert.Equal(t, 2*time.Second, rep.Dependencies[0].Latency)
+	})
+}
+
+func TestHandler(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	a := NewAggregator(Config{})
+	a.Register(Dependency{Name: "hermes", Kind: KindHermes}, HTTP(srv.Client(), srv.URL))
+	a.CheckAll(context.Background())
+
+	rec := httptest.NewRecorder()
+	NewHandler(a).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), "unexpected status code 502")
+}
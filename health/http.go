@@ -0,0 +1,19 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// NewHandler returns an http handler serving the aggregated health report as JSON.
+// It responds with 503 while any dependency is down.
+func NewHandler(a *Aggregator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rep := a.Health()
+		w.Header().Set("Content-Type", "application/json")
+		if rep.Status == StatusDown {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(rep)
+	})
+}
@@ -0,0 +1,186 @@
+package hermesfee
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// BasisPointsDenominator is the denominator of fees expressed in basis points, 2000 is 20%.
+const BasisPointsDenominator = 10000
+
+// Rounding decides how fractions of the smallest token unit are rounded.
+type Rounding string
+
+const (
+	// RoundHalfUp rounds to the nearest unit, halves up. This is what the hermes contract does.
+	RoundHalfUp Rounding = "half_up"
+	RoundDown   Rounding = "down"
+	RoundUp     Rounding = "up"
+)
+
+// ErrNoModel is returned when no fee model is known for a hermes.
+var ErrNoModel = errors.New("no fee model for hermes")
+
+// Model describes how a hermes charges fees for settled amounts.
+type Model struct {
+	// Version identifies the model, it must increase with each change.
+	Version int
+	// BasisPoints is the percentage charged, 2000 is 20%.
+	BasisPoints uint16
+	// Minimum is the minimal fee charged for a non zero amount. Can be nil.
+	Minimum *big.Int
+	// Rounding used when calculating the percentage. Empty means `RoundHalfUp`.
+	Rounding Rounding
+	// ValidFrom is the block from which the model applies.
+	ValidFrom uint64
+}
+
+// ContractModel returns the model implemented by the hermes contract for the given fee.
+func ContractModel(basisPoints uint16, validFrom uint64) Model {
+	return Model{BasisPoints: basisPoints, Rounding: RoundHalfUp, ValidFrom: validFrom}
+}
+
+// Fee returns the fee charged for the given amount. The fee never exceeds the amount.
+func (m Model) Fee(amount *big.Int) *big.Int {
+	if amount == nil || amount.Sign() <= 0 {
+		return new(big.Int)
+	}
+
+	num := new(big.Int).Mul(amount, big.NewInt(int64(m.BasisPoints)))
+	fee, rem := new(big.Int).QuoRem(num, big.NewInt(BasisPointsDenominator), new(big.Int))
+	switch m.Rounding {
+	case RoundDown:
+	case RoundUp:
+		if rem.Sign() > 0 {
+			fee.Add(fee, big.NewInt(1))
+		}
+	default:
+		if rem.Mul(rem, big.NewInt(2)).Cmp(big.NewInt(BasisPointsDenominator)) >= 0 {
+			fee.Add(fee, big.NewInt(1))
+		}
+	}
+
+	if m.Minimum != nil && fee.Cmp(m.Minimum) < 0 {
+		fee.Set(m.Minimum)
+	}
+	if fee.Cmp(amount) > 0 {
+		fee.Set(amount)
+	}
+	return fee
+}
+
+// Net returns the amount received after the fee is deducted.
+func (m Model) Net(amount *big.Int) *big.Int {
+	if amount == nil || amount.Sign() <= 0 {
+		return new(big.Int)
+	}
+	return new(big.Int).Sub(amount, m.Fee(amount))
+}
+
+// FeeSource returns the fee currently set in the hermes contract, implemented by `client.MultichainBlockchainClient`.
+type FeeSource interface {
+	GetHermesFee(chainID int64, hermesAddress common.Address) (uint16, error)
+}
+
+type hermesKey struct {
+	chainID int64
+	hermes  common.Address
+}
+
+// Registry holds the versioned fee models of every known hermes.
+// It is safe for concurrent use.
+type Registry struct {
+	models map[hermesKey][]Model
+	mu     sync.RWMutex
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{models: make(map[hermesKey][]Model)}
+}
+
+// Set adds a new version of the fee model for the given hermes.
+// Versions are immutable, so the version must be newer than the latest one.
+func (r *Registry) Set(chainID int64, hermesID common.Address, m Model) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := hermesKey{chainID: chainID, hermes: hermesID}
+	models := r.models[key]
+	if len(models) > 0 {
+		latest := models[len(models)-1]
+		if latest.Version >= m.Version {
+			return fmt.Errorf("fee model version %d is not newer than the latest version %d", m.Version, latest.Version)
+		}
+		if latest.ValidFrom > m.ValidFrom {
+			return fmt.Errorf("fee model version %d is valid from block %d which is before the latest version", m.Version, m.ValidFrom)
+		}
+	}
+
+	r.models[key] = append(models, m)
+	return nil
+}
+
+// Latest returns the newest fee model of the given hermes.
+func (r *Registry) Latest(chainID int64, hermesID common.Address) (Model, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	models := r.models[hermesKey{chainID: chainID, hermes: hermesID}]
+	if len(models) == 0 {
+		return Model{}, fmt.Errorf("%w %s on chain %d", ErrNoModel, hermesID.Hex(), chainID)
+	}
+	return models[len(models)-1], nil
+}
+
+// Version returns the given version of the fee model of the hermes.
+func (r *Registry) Version(chainID int64, hermesID common.Address, version int) (Model, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, m := range r.models[hermesKey{chainID: chainID, hermes: hermesID}] {
+		if m.Version == version {
+			return m, nil
+		}
+	}
+	return Model{}, fmt.Errorf("%w %s on chain %d with version %d", ErrNoModel, hermesID.Hex(), chainID, version)
+}
+
+// AtBlock returns the fee model applying at the given block.
+func (r *Registry) AtBlock(chainID int64, hermesID common.Address, block uint64) (Model, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	models := r.models[hermesKey{chainID: chainID, hermes: hermesID}]
+	i := sort.Search(len(models), func(i int) bool { return models[i].ValidFrom > block })
+	if i == 0 {
+		return Model{}, fmt.Errorf("%w %s on chain %d at block %d", ErrNoModel, hermesID.Hex(), chainID, block)
+	}
+	return models[i-1], nil
+}
+
+// Sync fetches the current fee of the hermes and records it as a new version if it changed.
+func (r *Registry) Sync(src FeeSource, chainID int64, hermesID common.Address, block uint64) (Model, error) {
+	bps, err := src.GetHermesFee(chainID, hermesID)
+	if err != nil {
+		return Model{}, fmt.Errorf("failed to get hermes fee: %w", err)
+	}
+
+	latest, err := r.Latest(chainID, hermesID)
+	if err == nil && latest.BasisPoints == bps {
+		return latest, nil
+	}
+
+	m := ContractModel(bps, block)
+	if err == nil {
+		m.Version = latest.Version + 1
+		m.Minimum = latest.Minimum
+		m.Rounding = latest.Rounding
+	}
+	return m, r.Set(chainID, hermesID, m)
+}
@@ -0,0 +1,87 @@
+package hermesfee
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+type feeSourceMock struct {
+	fee uint16
+}
+
+func (m *feeSourceMock) GetHermesFee(int64, common.Address) (uint16, error) {
+	return m.fee, nil
+}
+
+func TestModel(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		model  Model
+		amount int64
+		fee    int64
+	}{
+		{name: "contract rounds half up", model: ContractModel(2000, 0), amount: 12, fee: 2},
+		{name: "contract rounds down below half", model: ContractModel(2000, 0), amount: 11, fee: 2},
+		{name: "exact", model: ContractModel(2000, 0), amount: 100, fee: 20},
+		{name: "rounds down", model: Model{BasisPoints: 2000, Rounding: RoundDown}, amount: 14, fee: 2},
+		{name: "rounds up", model: Model{BasisPoints: 2000, Rounding: RoundUp}, amount: 11, fee: 3},
+		{name: "minimum", model: Model{BasisPoints: 100, Minimum: big.NewInt(5)}, amount: 100, fee: 5},
+		{name: "capped at amount", model: Model{BasisPoints: 100, Minimum: big.NewInt(5)}, amount: 3, fee: 3},
+		{name: "zero amount", model: Model{BasisPoints: 100, Minimum: big.NewInt(5)}, amount: 0, fee: 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, big.NewInt(tt.fee).String(), tt.model.Fee(big.NewInt(tt.amount)).String())
+			assert.Equal(t, big.NewInt(tt.amount-tt.fee).String(), tt.model.Net(big.NewInt(tt.amount)).String())
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	hermes := common.HexToAddress("0x1")
+	reg := NewRegistry()
+
+	_, err := reg.Latest(137, hermes)
+	assert.ErrorIs(t, err, ErrNoModel)
+
+	src := &feeSourceMock{fee: 2000}
+	m, err := reg.Sync(src, 137, hermes, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, m.Version)
+
+	m, err = reg.Sync(src, 137, hermes, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), m.ValidFrom)
+
+	src.fee = 1500
+	m, err = reg.Sync(src, 137, hermes, 30)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, m.Version)
+
+	t.Run("at block", func(t *testing.T) {
+		m, err := reg.AtBlock(137, hermes, 29)
+		assert.NoError(t, err)
+		assert.Equal(t, uint16(2000), m.BasisPoints)
+
+		m, err = reg.AtBlock(137, hermes, 30)
+		assert.NoError(t, err)
+		assert.Equal(t, uint16(1500), m.BasisPoints)
+
+		_, err = reg.AtBlock(137, hermes, 5)
+		assert.ErrorIs(t, err, ErrNoModel)
+	})
+
+	t.Run("versions", func(t *testing.T) {
+		m, err := reg.Version(137, hermes, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, uint16(2000), m.BasisPoints)
+
+		assert.Error(t, reg.Set(137, hermes, Model{Version: 1, BasisPoints: 1, ValidFrom: 40}))
+		assert.Error(t, reg.Set(137, hermes, Model{Version: 2, BasisPoints: 1, ValidFrom: 20}))
+		assert.NoError(t, reg.Set(137, hermes, Model{Version: 2, BasisPoints: 1, ValidFrom: 40}))
+		_, err = reg.Latest(1, hermes)
+		assert.ErrorIs(t, err, ErrNoModel)
+	})
+}
@@ -0,0 +1,141 @@
+// Package idempotency makes operations such as settlements, payouts and
+// registrations safe to retry. Every operation is identified by a key and its
+// outcome is recorded in a store, repeated invocations with the same key
+// return the recorded result instead of running the operation again.
+package idempotency
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Operation names used to build keys for the operations performed by the payments packages.
+const (
+	OperationRefund   = "refund"
+	OperationDelivery = "delivery"
+	OperationRelay    = "relay"
+)
+
+var (
+	// ErrInProgress is returned if an operation with the same key was started but has not finished.
+	// The operation might have been interrupted, in which case it has to be verified and released manually.
+	ErrInProgress = errors.New("operation is already in progress")
+	// ErrNotFound is returned by stores if a record does not exist.
+	ErrNotFound = errors.New("record not found")
+)
+
+// Status is the status of a recorded operation.
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusCompleted Status = "completed"
+)
+
+// Record is a recorded operation.
+type Record struct {
+	Key       string
+	Status    Status
+	Result    []byte
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Store persists operation records.
+type Store interface {
+	// Reserve atomically creates a pending record for the key.
+	// If a record already exists it is returned with reserved set to false.
+	Reserve(key string) (rec Record, reserved bool, err error)
+	// Complete marks the record as completed with the given result.
+	Complete(key string, result []byte) error
+	// Release removes a pending record, allowing the operation to be retried.
+	Release(key string) error
+	// Get returns a record for the key or `ErrNotFound`.
+	Get(key string) (Record, error)
+}
+
+// Key builds an operation key out of the operation name and values identifying it.
+func Key(operation string, parts ...any) string {
+	res := make([]string, 0, len(parts)+1)
+	res = append(res, operation)
+	for _, p := range parts {
+		res = append(res, strings.ToLower(fmt.Sprint(p)))
+	}
+	return strings.Join(res, ":")
+}
+
+// Layer runs operations at most once per key.
+type Layer struct {
+	store Store
+	logFn func(error)
+}
+
+// New returns a new idempotency layer backed by the given store.
+func New(store Store) *Layer {
+	return &Layer{
+		store: store,
+		logFn: func(error) {},
+	}
+}
+
+// AttachLogger allows the caller to attach an optional logger
+// which will receive errors that could not be returned to the caller.
+func (l *Layer) AttachLogger(fn func(err error)) {
+	l.logFn = fn
+}
+
+// Do runs the operation unless an operation with the same key has already completed,
+// in which case the recorded result is returned.
+//
+// If the operation fails, the key is released so that it can be retried.
+// If an operation with the same key is still in progress `ErrInProgress` is returned.
+func (l *Layer) Do(key string, op func() ([]byte, error)) ([]byte, error) {
+	rec, reserved, err := l.store.Reserve(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve operation %q: %w", key, err)
+	}
+	if !reserved {
+		if rec.Status == StatusCompleted {
+			return rec.Result, nil
+		}
+		return nil, fmt.Errorf("operation %q: %w", key, ErrInProgress)
+	}
+
+	result, err := op()
+	if err != nil {
+		if rerr := l.store.Release(key); rerr != nil {
+			l.logFn(fmt.Errorf("failed to release operation %q: %w", key, rerr))
+		}
+		return nil, err
+	}
+
+	if err := l.store.Complete(key, result); err != nil {
+		// The operation was performed so the result is returned, but the record
+		// stays pending which prevents it from running again.
+		l.logFn(fmt.Errorf("failed to record result of operation %q: %w", key, err))
+	}
+	return result, nil
+}
+
+// Do runs a typed operation using the given layer. Results are recorded as JSON.
+func Do[T any](l *Layer, key string, op func() (T, error)) (T, error) {
+	var res T
+	raw, err := l.Do(key, func() ([]byte, error) {
+		v, err := op()
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(v)
+	})
+	if err != nil {
+		return res, err
+	}
+
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return res, fmt.Errorf("failed to decode result of operation %q: %w", key, err)
+	}
+	return res, nil
+}
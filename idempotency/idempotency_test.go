@@ -0,0 +1,133 @@
+package idempotency
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, "refund:137:0xabcd:1", Key(OperationRefund, 137, "0xABCD", 1))
+}
+
+func TestLayer(t *testing.T) {
+	t.Run("returns recorded result", func(t *testing.T) {
+		l := New(NewMemoryStore())
+		var calls int
+		op := func() ([]byte, error) {
+			calls++
+			return []byte("0xhash"), nil
+		}
+
+		res, err := l.Do("payout:1", op)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("0xhash"), res)
+
+		res, err = l.Do("payout:1", op)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("0xhash"), res)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("failed operation can be retried", func(t *testing.T) {
+		l := New(NewMemoryStore())
+		fail := errors.New("broadcast failed")
+
+		_, err := l.Do("payout:2", func() ([]byte, error) { return nil, fail })
+		assert.ErrorIs(t, err, fail)
+
+		res, err := l.Do("payout:2", func() ([]byte, error) { return []byte("ok"), nil })
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("ok"), res)
+	})
+
+	t.Run("concurrent invocations run once", func(t *testing.T) {
+		l := New(NewMemoryStore())
+		var calls int32
+		started := make(chan struct{})
+		release := make(chan struct{})
+
+		go func() {
+			_, _ = l.Do("settlement:1", func() ([]byte, error) {
+				atomic.AddInt32(&calls, 1)
+				close(started)
+				<-release
+				return []byte("done"), nil
+			})
+		}()
+		<-started
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := l.Do("settlement:1", func() ([]byte, error) {
+					atomic.AddInt32(&calls, 1)
+					return nil, nil
+				})
+				assert.ErrorIs(t, err, ErrInProgress)
+			}()
+		}
+		wg.Wait()
+		close(release)
+
+		assert.Eventually(t, func() bool {
+			res, err := l.Do("settlement:1", func() ([]byte, error) { return nil, errors.New("should not run") })
+			return err == nil && string(res) == "done"
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("typed", func(t *testing.T) {
+		type result struct {
+			Hash  string
+			Nonce uint64
+		}
+		l := New(NewMemoryStore())
+		var calls int
+		op := func() (result, error) {
+			calls++
+			return result{Hash: "0x1", Nonce: 5}, nil
+		}
+
+		first, err := Do(l, "registration:0x1", op)
+		assert.NoError(t, err)
+		second, err := Do(l, "registration:0x1", op)
+		assert.NoError(t, err)
+		assert.Equal(t, first, second)
+		assert.Equal(t, result{Hash: "0x1", Nonce: 5}, second)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestMemoryStoreTTL(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryStoreWithTTL(time.Hour)
+	store.now = func() time.Time { return now }
+
+	_, reserved, err := store.Reserve("delivery:1")
+	assert.NoError(t, err)
+	assert.True(t, reserved)
+	_, reserved, err = store.Reserve("delivery:2")
+	assert.NoError(t, err)
+	assert.True(t, reserved)
+	assert.NoError(t, store.Complete("delivery:1", []byte("id")))
+
+	// Completed records expire, pending ones are kept.
+	now = now.Add(time.Hour)
+	_, err = store.Get("delivery:1")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, reserved, err = store.Reserve("delivery:2")
+	assert.NoError(t, err)
+	assert.False(t, reserved)
+	assert.Len(t, store.records, 1)
+
+	_, reserved, err = store.Reserve("delivery:1")
+	assert.NoError(t, err)
+	assert.True(t, reserved)
+}
@@ -0,0 +1,123 @@
+package idempotency
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultMemoryTTL is how long the stores of components which record their
+// operations in memory by default keep completed records.
+const DefaultMemoryTTL = 24 * time.Hour
+
+// MemoryStore is an in memory store. Records are lost on restart,
+// use a persistent store to protect against retries across restarts.
+type MemoryStore struct {
+	records map[string]Record
+	ttl     time.Duration
+	sweptAt time.Time
+	now     func() time.Time
+	mu      sync.Mutex
+}
+
+// NewMemoryStore returns a new in memory store which keeps every record.
+func NewMemoryStore() *MemoryStore {
+	return NewMemoryStoreWithTTL(0)
+}
+
+// NewMemoryStoreWithTTL returns a new in memory store which forgets completed records
+// once they are older than the ttl, so their operations can run again. Pending records
+// are kept until they are completed or released. Zero ttl keeps every record.
+func NewMemoryStoreWithTTL(ttl time.Duration) *MemoryStore {
+	return &MemoryStore{
+		records: make(map[string]Record),
+		ttl:     ttl,
+		now:     time.Now,
+	}
+}
+
+func (m *MemoryStore) Reserve(key string) (Record, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sweep()
+	if rec, ok := m.get(key); ok {
+		return rec, false, nil
+	}
+
+	now := m.now().UTC()
+	rec := Record{
+		Key:       key,
+		Status:    StatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	m.records[key] = rec
+	return rec, true, nil
+}
+
+func (m *MemoryStore) Complete(key string, result []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rec, ok := m.records[key]
+	if !ok {
+		return ErrNotFound
+	}
+
+	rec.Status = StatusCompleted
+	rec.Result = append([]byte(nil), result...)
+	rec.UpdatedAt = m.now().UTC()
+	m.records[key] = rec
+	return nil
+}
+
+func (m *MemoryStore) Release(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rec, ok := m.records[key]
+	if !ok {
+		return ErrNotFound
+	}
+	if rec.Status == StatusPending {
+		delete(m.records, key)
+	}
+	return nil
+}
+
+func (m *MemoryStore) Get(key string) (Record, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rec, ok := m.get(key)
+	if !ok {
+		return Record{}, ErrNotFound
+	}
+	return rec, nil
+}
+
+// get returns the record unless it expired.
+func (m *MemoryStore) get(key string) (Record, bool) {
+	rec, ok := m.records[key]
+	if !ok || m.expired(rec) {
+		return Record{}, false
+	}
+	return rec, true
+}
+
+func (m *MemoryStore) expired(rec Record) bool {
+	return m.ttl > 0 && rec.Status == StatusCompleted && m.now().Sub(rec.UpdatedAt) >= m.ttl
+}
+
+// sweep removes the expired records, at most once per ttl.
+func (m *MemoryStore) sweep() {
+	if m.ttl <= 0 || m.now().Sub(m.sweptAt) < m.ttl {
+		return
+	}
+	for key, rec := range m.records {
+		if m.expired(rec) {
+			delete(m.records, key)
+		}
+	}
+	m.sweptAt = m.now()
+}
@@ -0,0 +1,470 @@
+package keyrotation
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/mysteriumnetwork/payments/beneficiary"
+	"github.com/mysteriumnetwork/payments/crypto/signatures"
+)
+
+// Phase is the phase of a key rotation.
+type Phase string
+
+const (
+	// PhaseBinding means the operational permissions are being re-bound to the new key.
+	PhaseBinding Phase = "binding"
+	// PhaseGrace means the permissions are bound and both keys are valid until the compatibility window ends.
+	PhaseGrace Phase = "grace"
+	// PhaseRetirable means the compatibility window ended and only the new key is valid.
+	PhaseRetirable Phase = "retirable"
+	// PhaseRetired means the old key was deleted.
+	PhaseRetired Phase = "retired"
+)
+
+var (
+	// ErrRotationInProgress is returned when starting a rotation for an identity which is already rotating.
+	ErrRotationInProgress = errors.New("key rotation already in progress")
+	// ErrNoRotation is returned for identities which have no rotation.
+	ErrNoRotation = errors.New("no key rotation for identity")
+	// ErrNotRetirable is returned when retiring a key before the compatibility window ended.
+	ErrNotRetirable = errors.New("old key can not be retired yet")
+)
+
+// KeyStore creates and deletes keys, `*keystore.KeyStore` satisfies it.
+type KeyStore interface {
+	NewAccount(passphrase string) (accounts.Account, error)
+	Delete(a accounts.Account, passphrase string) error
+}
+
+// BeneficiaryBinder re-binds the identity beneficiary, implemented by `beneficiary.Manager`.
+type BeneficiaryBinder interface {
+	SetDesired(chainID int64, identity, beneficiary common.Address) error
+	Status(chainID int64, identity common.Address) (beneficiary.State, error)
+	Forget(chainID int64, identity common.Address)
+}
+
+// OperatorBinder re-binds the channel operator of an identity to a new key.
+// The current hermes and channel contracts fix the operator to the identity,
+// so it is only used with contracts that support changing it.
+type OperatorBinder interface {
+	SetOperator(chainID int64, identity, operator common.Address) error
+	OperatorBound(chainID int64, identity, operator common.Address) (bool, error)
+}
+
+// Config configures the rotator.
+type Config struct {
+	// Grace is the compatibility window after the permissions are bound during which both keys validate.
+	Grace time.Duration
+}
+
+// Rotation is the state of a single identity key rotation.
+type Rotation struct {
+	Identity common.Address
+	Chains   []int64
+	OldKey   common.Address
+	NewKey   common.Address
+	// Beneficiary is the beneficiary re-bound together with the key, nil if it was left untouched.
+	Beneficiary *common.Address
+	Phase       Phase
+	// OldKeyDeleted is set once the old key is deleted by `Retire`. It stays false for
+	// rotations away from the identity key, which is never deleted.
+	OldKeyDeleted bool
+
+	StartedAt  time.Time
+	BoundAt    time.Time
+	GraceUntil time.Time
+	RetiredAt  time.Time
+}
+
+// Rotator rotates identity keys. Until a rotation ends both the old and the new key validate.
+type Rotator struct {
+	ks          KeyStore
+	beneficiary BeneficiaryBinder
+	operator    OperatorBinder
+	store       Store
+	cfg         Config
+
+	active    map[common.Address]common.Address
+	rotations map[common.Address]*Rotation
+	starting  map[common.Address]struct{}
+	mu        sync.Mutex
+
+	listeners []func(Rotation)
+	logFn     func(error)
+	now       func() time.Time
+}
+
+// NewRotator returns a new key rotator.
+func NewRotator(ks KeyStore, beneficiary BeneficiaryBinder, cfg Config) *Rotator {
+	return &Rotator{
+		ks:          ks,
+		beneficiary: beneficiary,
+		store:       NewMemoryStore(),
+		cfg:         cfg,
+		active:      make(map[common.Address]common.Address),
+		rotations:   make(map[common.Address]*Rotation),
+		starting:    make(map[common.Address]struct{}),
+		logFn:       func(error) {},
+		now:         time.Now,
+	}
+}
+
+// AttachOperatorBinder enables re-binding of the channel operator on contracts that support it.
+func (r *Rotator) AttachOperatorBinder(b OperatorBinder) {
+	r.operator = b
+}
+
+// AttachStore replaces the in memory store with the given one and loads the rotations
+// stored in it, so the active keys are known again after a restart.
+//
+// This method is not thread safe and should be called before any rotation is started.
+func (r *Rotator) AttachStore(store Store) error {
+	rotations, err := store.Rotations()
+	if err != nil {
+		return fmt.Errorf("failed to load key rotations: %w", err)
+	}
+
+	r.store = store
+	for _, rot := range rotations {
+		rot := rot
+		r.rotations[rot.Identity] = &rot
+		r.active[rot.Identity] = rot.NewKey
+	}
+	return nil
+}
+
+// AttachLogger allows the caller to attach an optional logger
+// which will receive errors that happen during syncs.
+func (r *Rotator) AttachLogger(fn func(err error)) {
+	r.logFn = fn
+}
+
+// OnChange registers a listener which is called every time a rotation changes phase.
+//
+// This method is not thread safe and should be called before any rotation is started.
+func (r *Rotator) OnChange(fn func(Rotation)) {
+	r.listeners = append(r.listeners, fn)
+}
+
+// ActiveKey returns the key the identity should sign with. Identities that were never rotated sign with their own key.
+func (r *Rotator) ActiveKey(identity common.Address) common.Address {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.activeKey(identity)
+}
+
+func (r *Rotator) activeKey(identity common.Address) common.Address {
+	if key, ok := r.active[identity]; ok {
+		return key
+	}
+	return identity
+}
+
+// Start generates a new key for the identity and starts re-binding its permissions on the given chains.
+// The beneficiary receives the earnings of the identity and is not tied to its key, it is only set
+// on the given chains if a new one is given. If re-binding fails on any chain, the chains re-bound
+// before are restored and the new key is deleted.
+func (r *Rotator) Start(identity common.Address, chains []int64, passphrase string, newBeneficiary *common.Address) (Rotation, error) {
+	r.mu.Lock()
+	_, starting := r.starting[identity]
+	if rot, ok := r.rotations[identity]; starting || (ok && rot.Phase != PhaseRetired) {
+		r.mu.Unlock()
+		return Rotation{}, ErrRotationInProgress
+	}
+	r.starting[identity] = struct{}{}
+	oldKey := r.activeKey(identity)
+	var prev *Rotation
+	if rot, ok := r.rotations[identity]; ok {
+		cp := *rot
+		prev = &cp
+	}
+	r.mu.Unlock()
+
+	defer func() {
+		r.mu.Lock()
+		delete(r.starting, identity)
+		r.mu.Unlock()
+	}()
+
+	acc, err := r.ks.NewAccount(passphrase)
+	if err != nil {
+		return Rotation{}, fmt.Errorf("failed to create new key: %w", err)
+	}
+
+	deleteKey := func() {
+		if derr := r.ks.Delete(acc, passphrase); derr != nil {
+			r.logFn(fmt.Errorf("failed to delete new key %s of a failed rotation: %w", acc.Address.Hex(), derr))
+		}
+	}
+
+	rot := Rotation{
+		Identity:  identity,
+		Chains:    append([]int64(nil), chains...),
+		OldKey:    oldKey,
+		NewKey:    acc.Address,
+		Phase:     PhaseBinding,
+		StartedAt: r.now(),
+	}
+	if newBeneficiary != nil {
+		b := *newBeneficiary
+		rot.Beneficiary = &b
+	}
+
+	// The rotation is stored before binding, so the new key is known if the process stops while binding.
+	if err := r.store.Save(rot); err != nil {
+		deleteKey()
+		return Rotation{}, fmt.Errorf("failed to store key rotation: %w", err)
+	}
+
+	if err := r.bind(identity, chains, oldKey, acc.Address, newBeneficiary); err != nil {
+		r.restore(identity, prev)
+		deleteKey()
+		return Rotation{}, err
+	}
+
+	r.mu.Lock()
+	r.rotations[identity] = &rot
+	r.active[identity] = acc.Address
+	r.mu.Unlock()
+
+	r.emit(rot)
+	return rot, nil
+}
+
+// restore puts the previous rotation of the identity back into the store after a failed start.
+func (r *Rotator) restore(identity common.Address, prev *Rotation) {
+	var err error
+	if prev != nil {
+		err = r.store.Save(*prev)
+	} else {
+		err = r.store.Delete(identity)
+	}
+	if err != nil {
+		r.logFn(fmt.Errorf("failed to restore key rotation of %s: %w", identity.Hex(), err))
+	}
+}
+
+// bind re-binds the permissions on every chain, undoing the chains bound before if one fails.
+func (r *Rotator) bind(identity common.Address, chains []int64, oldKey, newKey common.Address, newBeneficiary *common.Address) (err error) {
+	var undo []func() error
+	defer func() {
+		if err == nil {
+			return
+		}
+		for i := len(undo) - 1; i >= 0; i-- {
+			if uerr := undo[i](); uerr != nil {
+				r.logFn(fmt.Errorf("failed to restore permissions of %s: %w", identity.Hex(), uerr))
+			}
+		}
+	}()
+
+	for _, chainID := range chains {
+		chainID := chainID
+		if newBeneficiary != nil {
+			prev, err := r.beneficiary.Status(chainID, identity)
+			if err != nil && !errors.Is(err, beneficiary.ErrNotTracked) {
+				return fmt.Errorf("failed to get beneficiary status of %s on chain %d: %w", identity.Hex(), chainID, err)
+			}
+			tracked := err == nil
+			if err := r.beneficiary.SetDesired(chainID, identity, *newBeneficiary); err != nil {
+				return fmt.Errorf("failed to re-bind beneficiary on chain %d: %w", chainID, err)
+			}
+			undo = append(undo, func() error {
+				if !tracked {
+					r.beneficiary.Forget(chainID, identity)
+					return nil
+				}
+				return r.beneficiary.SetDesired(chainID, identity, prev.Desired)
+			})
+		}
+		if r.operator == nil {
+			continue
+		}
+		if err := r.operator.SetOperator(chainID, identity, newKey); err != nil {
+			return fmt.Errorf("failed to re-bind operator on chain %d: %w", chainID, err)
+		}
+		undo = append(undo, func() error {
+			return r.operator.SetOperator(chainID, identity, oldKey)
+		})
+	}
+	return nil
+}
+
+// Get returns the latest rotation of the identity.
+func (r *Rotator) Get(identity common.Address) (Rotation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rot, ok := r.rotations[identity]
+	if !ok {
+		return Rotation{}, ErrNoRotation
+	}
+	return *rot, nil
+}
+
+// Valid reports whether the key may sign for the identity. The old key
+// stays valid while permissions are being bound and during the compatibility window.
+func (r *Rotator) Valid(identity, key common.Address) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if key == r.activeKey(identity) {
+		return true
+	}
+	rot, ok := r.rotations[identity]
+	if !ok || key != rot.OldKey {
+		return false
+	}
+	switch rot.Phase {
+	case PhaseBinding:
+		return true
+	case PhaseGrace:
+		return r.now().Before(rot.GraceUntil)
+	default:
+		return false
+	}
+}
+
+// Verify recovers the signer of a keccak256 hash of the message and reports whether it may sign for the identity.
+func (r *Rotator) Verify(identity common.Address, message, signature []byte) (bool, error) {
+	signer, err := signatures.RecoverMessage(message, signature)
+	if err != nil {
+		return false, err
+	}
+	return r.Valid(identity, signer), nil
+}
+
+// Sync moves rotations forward once their permissions are bound and their compatibility window is over.
+func (r *Rotator) Sync() {
+	r.mu.Lock()
+	pending := make([]Rotation, 0)
+	for _, rot := range r.rotations {
+		if rot.Phase == PhaseBinding || rot.Phase == PhaseGrace {
+			pending = append(pending, *rot)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, rot := range pending {
+		r.sync(rot)
+	}
+}
+
+func (r *Rotator) sync(rot Rotation) {
+	switch rot.Phase {
+	case PhaseBinding:
+		bound, err := r.bound(rot)
+		if err != nil {
+			r.logFn(err)
+			return
+		}
+		if !bound {
+			return
+		}
+		rot.Phase = PhaseGrace
+		rot.BoundAt = r.now()
+		rot.GraceUntil = rot.BoundAt.Add(r.cfg.Grace)
+	case PhaseGrace:
+		if r.now().Before(rot.GraceUntil) {
+			return
+		}
+		rot.Phase = PhaseRetirable
+	}
+
+	if err := r.update(rot); err != nil {
+		r.logFn(err)
+	}
+}
+
+func (r *Rotator) bound(rot Rotation) (bool, error) {
+	for _, chainID := range rot.Chains {
+		if rot.Beneficiary != nil {
+			st, err := r.beneficiary.Status(chainID, rot.Identity)
+			if err != nil {
+				return false, fmt.Errorf("failed to get beneficiary status of %s on chain %d: %w", rot.Identity.Hex(), chainID, err)
+			}
+			if st.Status != beneficiary.StatusConverged || st.OnChain != *rot.Beneficiary {
+				return false, nil
+			}
+		}
+		if r.operator == nil {
+			continue
+		}
+		ok, err := r.operator.OperatorBound(chainID, rot.Identity, rot.NewKey)
+		if err != nil {
+			return false, fmt.Errorf("failed to get operator of %s on chain %d: %w", rot.Identity.Hex(), chainID, err)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Retire deletes the old key of the identity once its compatibility window is over.
+// Identities which were never rotated before sign with the identity key itself,
+// which is never deleted, their retired rotations have `OldKeyDeleted` unset.
+func (r *Rotator) Retire(identity common.Address, passphrase string) (Rotation, error) {
+	r.mu.Lock()
+	cur, ok := r.rotations[identity]
+	if !ok {
+		r.mu.Unlock()
+		return Rotation{}, ErrNoRotation
+	}
+	rot := *cur
+	r.mu.Unlock()
+
+	if rot.Phase == PhaseGrace && !r.now().Before(rot.GraceUntil) {
+		rot.Phase = PhaseRetirable
+	}
+	if rot.Phase != PhaseRetirable {
+		return Rotation{}, fmt.Errorf("%w: rotation is in phase %s", ErrNotRetirable, rot.Phase)
+	}
+
+	if rot.OldKey != rot.Identity {
+		if err := r.ks.Delete(accounts.Account{Address: rot.OldKey}, passphrase); err != nil {
+			return Rotation{}, fmt.Errorf("failed to delete old key: %w", err)
+		}
+		rot.OldKeyDeleted = true
+	}
+
+	rot.Phase = PhaseRetired
+	rot.RetiredAt = r.now()
+	if err := r.update(rot); err != nil {
+		return Rotation{}, err
+	}
+	return rot, nil
+}
+
+func (r *Rotator) update(rot Rotation) error {
+	r.mu.Lock()
+	cur, ok := r.rotations[rot.Identity]
+	if !ok || cur.NewKey != rot.NewKey {
+		r.mu.Unlock()
+		return nil
+	}
+	if err := r.store.Save(rot); err != nil {
+		r.mu.Unlock()
+		return fmt.Errorf("failed to store key rotation of %s: %w", rot.Identity.Hex(), err)
+	}
+	changed := cur.Phase != rot.Phase
+	*cur = rot
+	r.mu.Unlock()
+
+	if changed {
+		r.emit(rot)
+	}
+	return nil
+}
+
+func (r *Rotator) emit(rot Rotation) {
+	for _, fn := range r.listeners {
+		fn(rot)
+	}
+}
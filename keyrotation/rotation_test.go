@@ -0,0 +1,271 @@
+package keyrotation
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/beneficiary"
+	"github.com/mysteriumnetwork/payments/crypto/signatures"
+)
+
+type keyStoreMock struct {
+	keys    map[common.Address]*ecdsa.PrivateKey
+	deleted []common.Address
+}
+
+func newKeyStoreMock() *keyStoreMock {
+	return &keyStoreMock{keys: make(map[common.Address]*ecdsa.PrivateKey)}
+}
+
+func (k *keyStoreMock) NewAccount(string) (accounts.Account, error) {
+	key, err := ethcrypto.GenerateKey()
+	if err != nil {
+		return accounts.Account{}, err
+	}
+	addr := ethcrypto.PubkeyToAddress(key.PublicKey)
+	k.keys[addr] = key
+	return accounts.Account{Address: addr}, nil
+}
+
+func (k *keyStoreMock) Delete(a accounts.Account, _ string) error {
+	delete(k.keys, a.Address)
+	k.deleted = append(k.deleted, a.Address)
+	return nil
+}
+
+func (k *keyStoreMock) SignHash(a accounts.Account, hash []byte) ([]byte, error) {
+	key, ok := k.keys[a.Address]
+	if !ok {
+		return nil, errors.New("unknown key")
+	}
+	return ethcrypto.Sign(hash, key)
+}
+
+type binderMock struct {
+	desired map[int64]common.Address
+	onChain map[int64]common.Address
+	failOn  map[int64]error
+	entered chan struct{}
+	release chan struct{}
+}
+
+func newBinderMock() *binderMock {
+	return &binderMock{desired: make(map[int64]common.Address), onChain: make(map[int64]common.Address), failOn: make(map[int64]error)}
+}
+
+func (b *binderMock) SetDesired(chainID int64, _, beneficiary common.Address) error {
+	if b.entered != nil {
+		b.entered <- struct{}{}
+		<-b.release
+	}
+	if err := b.failOn[chainID]; err != nil {
+		return err
+	}
+	b.desired[chainID] = beneficiary
+	return nil
+}
+
+func (b *binderMock) Forget(chainID int64, _ common.Address) {
+	delete(b.desired, chainID)
+}
+
+func (b *binderMock) Status(chainID int64, identity common.Address) (beneficiary.State, error) {
+	if _, ok := b.desired[chainID]; !ok {
+		return beneficiary.State{}, beneficiary.ErrNotTracked
+	}
+	st := beneficiary.State{ChainID: chainID, Identity: identity, Desired: b.desired[chainID], OnChain: b.onChain[chainID], Status: beneficiary.StatusSubmitted}
+	if st.OnChain == st.Desired {
+		st.Status = beneficiary.StatusConverged
+	}
+	return st, nil
+}
+
+func TestRotator(t *testing.T) {
+	ks := newKeyStoreMock()
+	identity, err := ks.NewAccount("")
+	assert.NoError(t, err)
+	binder := newBinderMock()
+
+	now := time.Unix(1000, 0)
+	r := NewRotator(ks, binder, Config{Grace: time.Hour})
+	r.now = func() time.Time { return now }
+	var phases []Phase
+	r.OnChange(func(rot Rotation) { phases = append(phases, rot.Phase) })
+
+	id := identity.Address
+	assert.Equal(t, id, r.ActiveKey(id))
+	_, err = r.Retire(id, "")
+	assert.ErrorIs(t, err, ErrNoRotation)
+
+	payout := common.HexToAddress("0x2")
+	rot, err := r.Start(id, []int64{137, 1}, "pass", &payout)
+	assert.NoError(t, err)
+	assert.Equal(t, id, rot.OldKey)
+	assert.Equal(t, rot.NewKey, r.ActiveKey(id))
+	assert.Equal(t, payout, binder.desired[137])
+	assert.Equal(t, payout, binder.desired[1])
+
+	_, err = r.Start(id, []int64{137}, "pass", nil)
+	assert.ErrorIs(t, err, ErrRotationInProgress)
+
+	t.Run("both keys validate while binding", func(t *testing.T) {
+		msg := []byte("hello")
+		for _, key := range []common.Address{rot.OldKey, rot.NewKey} {
+			sig, err := signatures.SignMessage(ks, key, msg)
+			assert.NoError(t, err)
+			ok, err := r.Verify(id, msg, sig)
+			assert.NoError(t, err)
+			assert.True(t, ok)
+		}
+		assert.False(t, r.Valid(id, common.HexToAddress("0x1")))
+	})
+
+	t.Run("waits for all chains to bind", func(t *testing.T) {
+		binder.onChain[137] = payout
+		r.Sync()
+		got, _ := r.Get(id)
+		assert.Equal(t, PhaseBinding, got.Phase)
+
+		binder.onChain[1] = payout
+		r.Sync()
+		got, _ = r.Get(id)
+		assert.Equal(t, PhaseGrace, got.Phase)
+		assert.Equal(t, now.Add(time.Hour), got.GraceUntil)
+		assert.True(t, r.Valid(id, rot.OldKey))
+
+		_, err := r.Retire(id, "")
+		assert.ErrorIs(t, err, ErrNotRetirable)
+	})
+
+	t.Run("old key expires after grace", func(t *testing.T) {
+		now = now.Add(time.Hour)
+		assert.False(t, r.Valid(id, rot.OldKey))
+		assert.True(t, r.Valid(id, rot.NewKey))
+
+		r.Sync()
+		got, _ := r.Get(id)
+		assert.Equal(t, PhaseRetirable, got.Phase)
+	})
+
+	t.Run("identity key is not deleted", func(t *testing.T) {
+		got, err := r.Retire(id, "")
+		assert.NoError(t, err)
+		assert.Equal(t, PhaseRetired, got.Phase)
+		assert.False(t, got.OldKeyDeleted)
+		assert.Empty(t, ks.deleted)
+	})
+
+	t.Run("second rotation deletes previous key", func(t *testing.T) {
+		second, err := r.Start(id, []int64{137}, "pass", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, rot.NewKey, second.OldKey)
+		assert.Nil(t, second.Beneficiary)
+		assert.Equal(t, payout, binder.desired[137], "beneficiary is left untouched")
+
+		r.Sync()
+		now = now.Add(time.Hour)
+
+		got, err := r.Retire(id, "pass")
+		assert.NoError(t, err)
+		assert.True(t, got.OldKeyDeleted)
+		assert.Equal(t, []common.Address{rot.NewKey}, ks.deleted)
+		assert.False(t, r.Valid(id, rot.NewKey))
+	})
+
+	assert.Equal(t, []Phase{PhaseBinding, PhaseGrace, PhaseRetirable, PhaseRetired, PhaseBinding, PhaseGrace, PhaseRetired}, phases)
+}
+
+func TestRotatorRestoresChainsOnFailure(t *testing.T) {
+	ks := newKeyStoreMock()
+	identity, err := ks.NewAccount("")
+	assert.NoError(t, err)
+	id := identity.Address
+
+	binder := newBinderMock()
+	previous := common.HexToAddress("0x3")
+	assert.NoError(t, binder.SetDesired(137, id, previous))
+	binder.failOn[1] = errors.New("no registry")
+
+	r := NewRotator(ks, binder, Config{Grace: time.Hour})
+	store := NewMemoryStore()
+	assert.NoError(t, r.AttachStore(store))
+	payout := common.HexToAddress("0x2")
+	_, err = r.Start(id, []int64{137, 56, 1}, "pass", &payout)
+	assert.ErrorContains(t, err, "no registry")
+
+	stored, err := store.Rotations()
+	assert.NoError(t, err)
+	assert.Empty(t, stored)
+
+	assert.Equal(t, map[int64]common.Address{137: previous}, binder.desired)
+	assert.Len(t, ks.keys, 1, "new key is deleted")
+	assert.Equal(t, id, r.ActiveKey(id))
+	_, err = r.Get(id)
+	assert.ErrorIs(t, err, ErrNoRotation)
+
+	delete(binder.failOn, 1)
+	_, err = r.Start(id, []int64{137, 56, 1}, "pass", &payout)
+	assert.NoError(t, err)
+}
+
+func TestRotatorStore(t *testing.T) {
+	ks := newKeyStoreMock()
+	identity, err := ks.NewAccount("")
+	assert.NoError(t, err)
+	id := identity.Address
+	binder := newBinderMock()
+	store := NewMemoryStore()
+
+	r := NewRotator(ks, binder, Config{Grace: time.Hour})
+	assert.NoError(t, r.AttachStore(store))
+	rot, err := r.Start(id, []int64{137}, "pass", nil)
+	assert.NoError(t, err)
+	r.Sync()
+
+	// A restarted rotator knows the active key and the progress of the rotation.
+	restarted := NewRotator(ks, binder, Config{Grace: time.Hour})
+	assert.NoError(t, restarted.AttachStore(store))
+	assert.Equal(t, rot.NewKey, restarted.ActiveKey(id))
+	assert.True(t, restarted.Valid(id, rot.OldKey))
+	got, err := restarted.Get(id)
+	assert.NoError(t, err)
+	assert.Equal(t, PhaseGrace, got.Phase)
+
+	_, err = restarted.Start(id, []int64{137}, "pass", nil)
+	assert.ErrorIs(t, err, ErrRotationInProgress)
+}
+
+func TestRotatorConcurrentStart(t *testing.T) {
+	ks := newKeyStoreMock()
+	identity, err := ks.NewAccount("")
+	assert.NoError(t, err)
+	id := identity.Address
+
+	binder := newBinderMock()
+	binder.entered = make(chan struct{})
+	binder.release = make(chan struct{})
+	r := NewRotator(ks, binder, Config{})
+
+	payout := common.HexToAddress("0x2")
+	done := make(chan error)
+	go func() {
+		_, err := r.Start(id, []int64{137}, "pass", &payout)
+		done <- err
+	}()
+	<-binder.entered
+
+	_, err = r.Start(id, []int64{137}, "pass", &payout)
+	assert.ErrorIs(t, err, ErrRotationInProgress)
+
+	close(binder.release)
+	assert.NoError(t, <-done)
+	_, err = r.Start(id, []int64{137}, "pass", &payout)
+	assert.ErrorIs(t, err, ErrRotationInProgress)
+}
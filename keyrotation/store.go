@@ -0,0 +1,60 @@
+package keyrotation
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Store persists the latest rotation of every identity, so the active key
+// and the progress of rotations survive restarts.
+type Store interface {
+	// Save stores the rotation, replacing the previous rotation of the identity.
+	Save(rot Rotation) error
+	// Delete removes the rotation of the identity.
+	Delete(identity common.Address) error
+	// Rotations returns the latest rotation of every identity.
+	Rotations() ([]Rotation, error)
+}
+
+// MemoryStore is an in memory rotation store. Rotations are lost on restart,
+// use a persistent store to keep track of the active keys across restarts.
+type MemoryStore struct {
+	rotations map[common.Address]Rotation
+	mu        sync.Mutex
+}
+
+// NewMemoryStore returns a new in memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		rotations: make(map[common.Address]Rotation),
+	}
+}
+
+// Save stores the rotation.
+func (m *MemoryStore) Save(rot Rotation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rotations[rot.Identity] = rot
+	return nil
+}
+
+// Delete removes the rotation of the identity.
+func (m *MemoryStore) Delete(identity common.Address) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.rotations, identity)
+	return nil
+}
+
+// Rotations returns the stored rotations.
+func (m *MemoryStore) Rotations() ([]Rotation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	res := make([]Rotation, 0, len(m.rotations))
+	for _, rot := range m.rotations {
+		res = append(res, rot)
+	}
+	return res, nil
+}
@@ -0,0 +1,56 @@
+// Package logging defines a minimal leveled logger used by the payments packages.
+//
+// Embedding applications can route the logs to their own sinks by
+// calling `SetDefault` with an adapter for their logging library.
+// Adapters for zerolog and slog are provided.
+package logging
+
+import (
+	"sync/atomic"
+)
+
+// Logger is a leveled logger accepting a message and alternating key-value pairs.
+type Logger interface {
+	Debug(msg string, keysAndValues ...any)
+	Info(msg string, keysAndValues ...any)
+	Warn(msg string, keysAndValues ...any)
+	Error(msg string, keysAndValues ...any)
+}
+
+var defaultLogger atomic.Value
+
+func init() {
+	defaultLogger.Store(holder{NewZerologGlobal()})
+}
+
+// holder allows storing loggers of different concrete types in atomic.Value.
+type holder struct {
+	Logger
+}
+
+// Default returns the logger used by the payments packages.
+// Unless changed by `SetDefault` it writes to the global zerolog logger.
+func Default() Logger {
+	return defaultLogger.Load().(holder).Logger
+}
+
+// SetDefault sets the logger used by the payments packages.
+// Passing nil disables logging.
+func SetDefault(l Logger) {
+	if l == nil {
+		l = Nop()
+	}
+	defaultLogger.Store(holder{l})
+}
+
+// Nop returns a logger which discards everything.
+func Nop() Logger {
+	return nop{}
+}
+
+type nop struct{}
+
+func (nop) Debug(string, ...any) {}
+func (nop) Info(string, ...any)  {}
+func (nop) Warn(string, ...any)  {}
+func (nop) Error(string, ...any) {}
@@ -0,0 +1,51 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZerolog(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewZerolog(zerolog.New(&buf).Level(zerolog.InfoLevel))
+
+	l.Debug("hidden")
+	assert.Zero(t, buf.Len())
+
+	l.Error("failed", "error", errors.New("boom"), "chainID", int64(137))
+	var entry map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "error", entry["level"])
+	assert.Equal(t, "failed", entry["message"])
+	assert.Equal(t, "boom", entry["error"])
+	assert.Equal(t, 137.0, entry["chainID"])
+}
+
+func TestSlog(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewSlog(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	l.Warn("no API key set", "provider", "etherscan")
+	var entry map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "WARN", entry["level"])
+	assert.Equal(t, "etherscan", entry["provider"])
+}
+
+func TestDefault(t *testing.T) {
+	defer SetDefault(NewZerologGlobal())
+
+	var buf bytes.Buffer
+	SetDefault(NewSlog(slog.New(slog.NewTextHandler(&buf, nil))))
+	Default().Info("hello")
+	assert.Contains(t, buf.String(), "hello")
+
+	SetDefault(nil)
+	assert.Equal(t, Nop(), Default())
+}
@@ -0,0 +1,31 @@
+package logging
+
+import (
+	"log/slog"
+)
+
+// Slog adapts a slog logger to the `Logger` interface.
+type Slog struct {
+	logger *slog.Logger
+}
+
+// NewSlog returns a logger writing to the given slog logger.
+func NewSlog(l *slog.Logger) *Slog {
+	return &Slog{logger: l}
+}
+
+func (s *Slog) Debug(msg string, keysAndValues ...any) {
+	s.logger.Debug(msg, keysAndValues...)
+}
+
+func (s *Slog) Info(msg string, keysAndValues ...any) {
+	s.logger.Info(msg, keysAndValues...)
+}
+
+func (s *Slog) Warn(msg string, keysAndValues ...any) {
+	s.logger.Warn(msg, keysAndValues...)
+}
+
+func (s *Slog) Error(msg string, keysAndValues ...any) {
+	s.logger.Error(msg, keysAndValues...)
+}
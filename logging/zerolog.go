@@ -0,0 +1,52 @@
+package logging
+
+import (
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// Zerolog adapts a zerolog logger to the `Logger` interface.
+type Zerolog struct {
+	logger *zerolog.Logger
+}
+
+// NewZerolog returns a logger writing to the given zerolog logger.
+func NewZerolog(l zerolog.Logger) *Zerolog {
+	return &Zerolog{logger: &l}
+}
+
+// NewZerologGlobal returns a logger writing to the global zerolog logger.
+// Changes to the global logger are picked up.
+func NewZerologGlobal() *Zerolog {
+	return &Zerolog{}
+}
+
+func (z *Zerolog) Debug(msg string, keysAndValues ...any) {
+	z.log(z.get().Debug(), msg, keysAndValues)
+}
+
+func (z *Zerolog) Info(msg string, keysAndValues ...any) {
+	z.log(z.get().Info(), msg, keysAndValues)
+}
+
+func (z *Zerolog) Warn(msg string, keysAndValues ...any) {
+	z.log(z.get().Warn(), msg, keysAndValues)
+}
+
+func (z *Zerolog) Error(msg string, keysAndValues ...any) {
+	z.log(z.get().Error(), msg, keysAndValues)
+}
+
+func (z *Zerolog) get() *zerolog.Logger {
+	if z.logger == nil {
+		return &log.Logger
+	}
+	return z.logger
+}
+
+func (z *Zerolog) log(e *zerolog.Event, msg string, keysAndValues []any) {
+	if len(keysAndValues) > 0 {
+		e = e.Fields(keysAndValues)
+	}
+	e.Msg(msg)
+}
@@ -24,6 +24,16 @@ func GenerateMatic() error {
 	return sh.RunV("go", strings.Split(command, " ")...)
 }
 
+func GenerateEscrow() error {
+	command := `run bindings/abi/abigen.go --localdir=./bindings/escrow/abi --contracts=Escrow.json --out=bindings/escrow --pkg=escrow`
+	return sh.RunV("go", strings.Split(command, " ")...)
+}
+
+func GenerateMulticall() error {
+	command := `run bindings/abi/abigen.go --localdir=./bindings/multicall/abi --contracts=Multicall3.json --out=bindings/multicall --pkg=multicall`
+	return sh.RunV("go", strings.Split(command, " ")...)
+}
+
 func Test() error {
 	return sh.RunV("go", "test", "--short", "-race", "-cover", "./...")
 }
@@ -0,0 +1,93 @@
+package merkle
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// CheckpointLeaf returns the leaf of a Polygon checkpoint tree for the given block header:
+// keccak256(abi.encodePacked(number, timestamp, transactionsRoot, receiptsRoot)).
+func CheckpointLeaf(header *types.Header) common.Hash {
+	return crypto.Keccak256Hash(
+		math.U256Bytes(new(big.Int).Set(header.Number)),
+		math.U256Bytes(new(big.Int).SetUint64(header.Time)),
+		header.TxHash.Bytes(),
+		header.ReceiptHash.Bytes(),
+	)
+}
+
+// CheckpointRoot computes the root of a Polygon checkpoint tree over the given
+// consecutive headers. Leaves are padded with zero hashes up to a power of two.
+func CheckpointRoot(headers []*types.Header) (common.Hash, error) {
+	levels, err := checkpointLevels(headers)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return levels[len(levels)-1][0], nil
+}
+
+// CheckpointProof returns the sibling path proving the header at the given index of the checkpoint.
+func CheckpointProof(headers []*types.Header, index int) ([]common.Hash, error) {
+	if index < 0 || index >= len(headers) {
+		return nil, fmt.Errorf("header index %d out of range of %d headers", index, len(headers))
+	}
+
+	levels, err := checkpointLevels(headers)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := make([]common.Hash, 0, len(levels)-1)
+	for _, level := range levels[:len(levels)-1] {
+		proof = append(proof, level[index^1])
+		index /= 2
+	}
+	return proof, nil
+}
+
+// VerifyCheckpointProof verifies that the header is the index-th block of a checkpoint with the given root.
+// The index is the position of the block in the checkpoint, i.e. its number minus the checkpoint start block.
+func VerifyCheckpointProof(header *types.Header, index uint64, proof []common.Hash, root common.Hash) bool {
+	if len(proof) < 64 && index >= uint64(1)<<len(proof) {
+		return false
+	}
+
+	node := CheckpointLeaf(header)
+	for _, sibling := range proof {
+		if index%2 == 0 {
+			node = crypto.Keccak256Hash(node.Bytes(), sibling.Bytes())
+		} else {
+			node = crypto.Keccak256Hash(sibling.Bytes(), node.Bytes())
+		}
+		index /= 2
+	}
+	return node == root
+}
+
+func checkpointLevels(headers []*types.Header) ([][]common.Hash, error) {
+	if len(headers) == 0 {
+		return nil, errors.New("no headers given")
+	}
+
+	leaves := make([]common.Hash, nextPowerOfTwo(uint64(len(headers))))
+	for i, h := range headers {
+		leaves[i] = CheckpointLeaf(h)
+	}
+
+	levels := [][]common.Hash{leaves}
+	for current := leaves; len(current) > 1; {
+		next := make([]common.Hash, len(current)/2)
+		for i := range next {
+			next[i] = crypto.Keccak256Hash(current[2*i].Bytes(), current[2*i+1].Bytes())
+		}
+		levels = append(levels, next)
+		current = next
+	}
+	return levels, nil
+}
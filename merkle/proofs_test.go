@@ -0,0 +1,129 @@
+package merkle
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+)
+
+func testReceipts(n int) types.Receipts {
+	receipts := make(types.Receipts, 0, n)
+	for i := 0; i < n; i++ {
+		r := &types.Receipt{
+			Status:            types.ReceiptStatusSuccessful,
+			CumulativeGasUsed: uint64(21000 * (i + 1)),
+			Logs: []*types.Log{{
+				Address: common.BigToAddress(big.NewInt(int64(i))),
+				Topics:  []common.Hash{common.BigToHash(big.NewInt(int64(i)))},
+				Data:    []byte{byte(i)},
+			}},
+		}
+		if i%2 == 1 {
+			r.Type = types.DynamicFeeTxType
+		}
+		r.Bloom = types.CreateBloom(types.Receipts{r})
+		receipts = append(receipts, r)
+	}
+	return receipts
+}
+
+func TestReceiptProof(t *testing.T) {
+	receipts := testReceipts(130)
+	expectedRoot := types.DeriveSha(receipts, trie.NewStackTrie(nil))
+
+	for _, index := range []uint64{0, 1, 2, 127, 129} {
+		proof, root, err := BuildReceiptProof(receipts, index)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedRoot, root)
+
+		receipt, err := VerifyReceiptInHeader(&types.Header{ReceiptHash: root}, proof)
+		assert.NoError(t, err)
+		assert.Equal(t, receipts[index].CumulativeGasUsed, receipt.CumulativeGasUsed)
+		assert.Equal(t, receipts[index].Type, receipt.Type)
+		assert.Equal(t, receipts[index].Logs[0].Data, receipt.Logs[0].Data)
+	}
+
+	t.Run("wrong root", func(t *testing.T) {
+		proof, _, err := BuildReceiptProof(receipts, 5)
+		assert.NoError(t, err)
+		_, err = VerifyReceiptProof(common.HexToHash("0x1"), proof)
+		assert.Error(t, err)
+	})
+
+	t.Run("wrong index", func(t *testing.T) {
+		proof, root, err := BuildReceiptProof(receipts, 5)
+		assert.NoError(t, err)
+		proof.Index = 6
+		_, err = VerifyReceiptProof(root, proof)
+		assert.Error(t, err)
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		_, _, err := BuildReceiptProof(receipts, 130)
+		assert.Error(t, err)
+	})
+}
+
+type headerSourceMock struct {
+	header *types.Header
+	err    error
+}
+
+func (m headerSourceMock) HeaderByNumber(number *big.Int) (*types.Header, error) {
+	return m.header, m.err
+}
+
+func TestFetchHeader(t *testing.T) {
+	honest := &types.Header{Number: big.NewInt(10), ReceiptHash: common.HexToHash("0x1")}
+	lying := &types.Header{Number: big.NewInt(10), ReceiptHash: common.HexToHash("0x2")}
+	failing := headerSourceMock{err: errors.New("down")}
+
+	h, err := FetchHeader(big.NewInt(10), 2, headerSourceMock{header: lying}, failing, headerSourceMock{header: honest}, headerSourceMock{header: honest})
+	assert.NoError(t, err)
+	assert.Equal(t, honest.Hash(), h.Hash())
+
+	_, err = FetchHeader(big.NewInt(10), 2, headerSourceMock{header: lying}, failing, headerSourceMock{header: honest})
+	assert.ErrorIs(t, err, ErrNoQuorum)
+
+	_, err = FetchHeader(big.NewInt(10), 3, headerSourceMock{header: honest})
+	assert.Error(t, err)
+}
+
+func TestCheckpointProof(t *testing.T) {
+	headers := make([]*types.Header, 0, 5)
+	for i := 0; i < 5; i++ {
+		headers = append(headers, &types.Header{
+			Number:      big.NewInt(int64(1000 + i)),
+			Time:        uint64(1700000000 + 2*i),
+			TxHash:      common.BigToHash(big.NewInt(int64(i))),
+			ReceiptHash: common.BigToHash(big.NewInt(int64(100 + i))),
+		})
+	}
+
+	root, err := CheckpointRoot(headers)
+	assert.NoError(t, err)
+
+	for i, h := range headers {
+		proof, err := CheckpointProof(headers, i)
+		assert.NoError(t, err)
+		assert.Len(t, proof, 3)
+		assert.True(t, VerifyCheckpointProof(h, uint64(i), proof, root))
+		assert.False(t, VerifyCheckpointProof(h, uint64((i+1)%len(headers)), proof, root))
+	}
+
+	proof, err := CheckpointProof(headers, 0)
+	assert.NoError(t, err)
+	assert.False(t, VerifyCheckpointProof(headers[0], 8, proof, root), "index outside of the tree")
+
+	single, err := CheckpointRoot(headers[:1])
+	assert.NoError(t, err)
+	assert.Equal(t, CheckpointLeaf(headers[0]), single)
+
+	_, err = CheckpointRoot(nil)
+	assert.Error(t, err)
+}
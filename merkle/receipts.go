@@ -0,0 +1,132 @@
+package merkle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// ErrNoQuorum is returned if not enough header sources agree on a header.
+var ErrNoQuorum = errors.New("header sources do not agree")
+
+// ReceiptProof proves the inclusion of a receipt in a block's receipts trie.
+type ReceiptProof struct {
+	// Index is the index of the receipt in the block.
+	Index uint64
+	// Nodes are the RLP encoded trie nodes on the path from the root to the receipt.
+	Nodes [][]byte
+}
+
+// BuildReceiptProof builds an inclusion proof for the receipt at the given index
+// out of all of the block receipts. It also returns the receipts root, which
+// matches the `ReceiptHash` of the block header.
+func BuildReceiptProof(receipts types.Receipts, index uint64) (ReceiptProof, common.Hash, error) {
+	if index >= uint64(len(receipts)) {
+		return ReceiptProof{}, common.Hash{}, fmt.Errorf("receipt index %d out of range of %d receipts", index, len(receipts))
+	}
+
+	tr := trie.NewEmpty(trie.NewDatabase(rawdb.NewMemoryDatabase(), nil))
+	var buf bytes.Buffer
+	for i := range receipts {
+		buf.Reset()
+		receipts.EncodeIndex(i, &buf)
+		if err := tr.Update(receiptKey(uint64(i)), common.CopyBytes(buf.Bytes())); err != nil {
+			return ReceiptProof{}, common.Hash{}, fmt.Errorf("failed to insert receipt %d: %w", i, err)
+		}
+	}
+
+	nodes := &proofNodes{}
+	if err := tr.Prove(receiptKey(index), nodes); err != nil {
+		return ReceiptProof{}, common.Hash{}, fmt.Errorf("failed to prove receipt %d: %w", index, err)
+	}
+
+	return ReceiptProof{Index: index, Nodes: nodes.nodes}, tr.Hash(), nil
+}
+
+// VerifyReceiptProof verifies the proof against the given receipts root and returns the proven receipt.
+func VerifyReceiptProof(receiptsRoot common.Hash, proof ReceiptProof) (*types.Receipt, error) {
+	db := memorydb.New()
+	for _, node := range proof.Nodes {
+		if err := db.Put(crypto.Keccak256(node), node); err != nil {
+			return nil, err
+		}
+	}
+
+	value, err := trie.VerifyProof(receiptsRoot, receiptKey(proof.Index), db)
+	if err != nil {
+		return nil, fmt.Errorf("invalid receipt proof: %w", err)
+	}
+	if value == nil {
+		return nil, errors.New("invalid receipt proof: receipt is not included")
+	}
+
+	receipt := new(types.Receipt)
+	if err := receipt.UnmarshalBinary(value); err != nil {
+		return nil, fmt.Errorf("failed to decode proven receipt: %w", err)
+	}
+	return receipt, nil
+}
+
+// VerifyReceiptInHeader verifies the proof against the receipts root of the given header.
+func VerifyReceiptInHeader(header *types.Header, proof ReceiptProof) (*types.Receipt, error) {
+	return VerifyReceiptProof(header.ReceiptHash, proof)
+}
+
+// HeaderSource returns block headers, `client.BC` satisfies it.
+type HeaderSource interface {
+	HeaderByNumber(number *big.Int) (*types.Header, error)
+}
+
+// FetchHeader fetches the header from all of the given sources and returns it
+// only if at least quorum of them agree on its hash. This avoids trusting a single RPC provider.
+func FetchHeader(number *big.Int, quorum int, sources ...HeaderSource) (*types.Header, error) {
+	if quorum <= 0 || quorum > len(sources) {
+		return nil, fmt.Errorf("quorum %d is impossible with %d sources", quorum, len(sources))
+	}
+
+	votes := make(map[common.Hash]int)
+	headers := make(map[common.Hash]*types.Header)
+	var errs []error
+	for i, s := range sources {
+		h, err := s.HeaderByNumber(number)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("source %d: %w", i, err))
+			continue
+		}
+		hash := h.Hash()
+		votes[hash]++
+		headers[hash] = h
+		if votes[hash] >= quorum {
+			return h, nil
+		}
+	}
+
+	return nil, errors.Join(append([]error{fmt.Errorf("%w on header %v", ErrNoQuorum, number)}, errs...)...)
+}
+
+func receiptKey(index uint64) []byte {
+	return rlp.AppendUint64(nil, index)
+}
+
+// proofNodes collects proof nodes in order.
+type proofNodes struct {
+	nodes [][]byte
+}
+
+func (p *proofNodes) Put(key []byte, value []byte) error {
+	p.nodes = append(p.nodes, common.CopyBytes(value))
+	return nil
+}
+
+func (p *proofNodes) Delete(key []byte) error {
+	return errors.New("not supported")
+}
@@ -0,0 +1,195 @@
+package migration
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/mysteriumnetwork/payments/settlement"
+	"github.com/mysteriumnetwork/payments/transaction"
+)
+
+// Version is the current snapshot format version.
+const Version = 1
+
+// DefaultMaxDeliveries is the maximum amount of deliveries exported per sender.
+const DefaultMaxDeliveries = 100000
+
+// ErrUnsupportedVersion is returned when importing a snapshot of an unknown version.
+var ErrUnsupportedVersion = errors.New("unsupported snapshot version")
+
+// Snapshot is the exported state of the payments packages.
+type Snapshot struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+
+	Nonces     []Nonce                     `json:"nonces"`
+	Deliveries []transaction.Delivery      `json:"deliveries"`
+	Promises   []settlement.PendingPromise `json:"promises"`
+	Ledger     []settlement.LedgerEntry    `json:"ledger"`
+	Cursors    []Cursor                    `json:"cursors"`
+}
+
+// Nonce is the last issued nonce of a sender.
+type Nonce struct {
+	ChainID int64          `json:"chainID"`
+	Account common.Address `json:"account"`
+	Nonce   uint64         `json:"nonce"`
+}
+
+// Cursor is the position of a log scanner, e.g. the watchtower.
+type Cursor struct {
+	Name  string `json:"name"`
+	Block uint64 `json:"block"`
+}
+
+// NonceState is implemented by `transaction.NonceTracker`.
+type NonceState interface {
+	Nonces() map[transaction.Sender]uint64
+	RestoreNonces(nonces map[transaction.Sender]uint64)
+}
+
+// DeliveryState is implemented by depot storages.
+type DeliveryState interface {
+	GetOrderedDeliveryRequests(count uint, chainID int64, sender common.Address) ([]transaction.Delivery, error)
+	UpsertDeliveryRequest(tx transaction.Delivery) error
+}
+
+// PromiseState is implemented by `settlement.AutoSettler`.
+type PromiseState interface {
+	PendingPromises() []settlement.PendingPromise
+	Feed(hermesID common.Address, promise crypto.Promise)
+}
+
+// LedgerState is implemented by `settlement.MemoryLedger`.
+type LedgerState interface {
+	Entries() []settlement.LedgerEntry
+	Record(chainID int64, channelID common.Hash, amount *big.Int)
+}
+
+// CursorState is implemented by `watchtower.Watchtower`.
+type CursorState interface {
+	Cursor() uint64
+	SetCursor(block uint64)
+}
+
+// Components are the stateful components to export from or import into. Nil components are skipped.
+type Components struct {
+	Nonces NonceState
+
+	Deliveries DeliveryState
+	// Senders are the depot workers whose deliveries are exported.
+	Senders []transaction.Sender
+	// MaxDeliveries per sender, defaults to `DefaultMaxDeliveries`.
+	MaxDeliveries uint
+
+	Promises PromiseState
+	Ledger   LedgerState
+	Cursors  map[string]CursorState
+}
+
+// Export captures the state of all of the components.
+//
+// Components should be stopped before exporting so that no state changes are missed.
+func (c Components) Export() (*Snapshot, error) {
+	s := &Snapshot{Version: Version, CreatedAt: time.Now().UTC()}
+
+	if c.Nonces != nil {
+		for sender, nonce := range c.Nonces.Nonces() {
+			s.Nonces = append(s.Nonces, Nonce{ChainID: sender.ChainID, Account: sender.Address, Nonce: nonce})
+		}
+	}
+
+	if c.Deliveries != nil {
+		max := c.MaxDeliveries
+		if max == 0 {
+			max = DefaultMaxDeliveries
+		}
+		for _, sender := range c.Senders {
+			deliveries, err := c.Deliveries.GetOrderedDeliveryRequests(max, sender.ChainID, sender.Address)
+			if err != nil {
+				return nil, fmt.Errorf("failed to export deliveries of %s on chain %d: %w", sender.Address.Hex(), sender.ChainID, err)
+			}
+			s.Deliveries = append(s.Deliveries, deliveries...)
+		}
+	}
+
+	if c.Promises != nil {
+		s.Promises = c.Promises.PendingPromises()
+	}
+	if c.Ledger != nil {
+		s.Ledger = c.Ledger.Entries()
+	}
+	for name, cur := range c.Cursors {
+		s.Cursors = append(s.Cursors, Cursor{Name: name, Block: cur.Cursor()})
+	}
+
+	return s, nil
+}
+
+// Import restores the snapshot into the components. Components missing
+// in the snapshot are left untouched, state for missing components is skipped.
+func (c Components) Import(s *Snapshot) error {
+	if s.Version < 1 || s.Version > Version {
+		return fmt.Errorf("%w: %d", ErrUnsupportedVersion, s.Version)
+	}
+
+	if c.Nonces != nil && len(s.Nonces) > 0 {
+		nonces := make(map[transaction.Sender]uint64, len(s.Nonces))
+		for _, n := range s.Nonces {
+			nonces[transaction.NewSender(n.Account, n.ChainID)] = n.Nonce
+		}
+		c.Nonces.RestoreNonces(nonces)
+	}
+
+	if c.Deliveries != nil {
+		for _, d := range s.Deliveries {
+			if err := c.Deliveries.UpsertDeliveryRequest(d); err != nil {
+				return fmt.Errorf("failed to import delivery %s: %w", d.UniqueID, err)
+			}
+		}
+	}
+
+	if c.Promises != nil {
+		for _, p := range s.Promises {
+			c.Promises.Feed(p.HermesID, p.Promise)
+		}
+	}
+	if c.Ledger != nil {
+		for _, e := range s.Ledger {
+			c.Ledger.Record(e.ChainID, e.ChannelID, e.Settled)
+		}
+	}
+	for _, cur := range s.Cursors {
+		if target, ok := c.Cursors[cur.Name]; ok {
+			target.SetCursor(cur.Block)
+		}
+	}
+
+	return nil
+}
+
+// Encode writes the snapshot as JSON.
+func Encode(w io.Writer, s *Snapshot) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// Decode reads a JSON encoded snapshot and checks its version.
+func Decode(r io.Reader) (*Snapshot, error) {
+	var s Snapshot
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	if s.Version < 1 || s.Version > Version {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, s.Version)
+	}
+	return &s, nil
+}
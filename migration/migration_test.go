@@ -0,0 +1,98 @@
+package migration
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/mysteriumnetwork/payments/settlement"
+	"github.com/mysteriumnetwork/payments/transaction"
+)
+
+type deliveriesMock struct {
+	deliveries []transaction.Delivery
+}
+
+func (m *deliveriesMock) GetOrderedDeliveryRequests(_ uint, chainID int64, sender common.Address) ([]transaction.Delivery, error) {
+	var res []transaction.Delivery
+	for _, d := range m.deliveries {
+		if d.ChainID == chainID && d.Sender == sender {
+			res = append(res, d)
+		}
+	}
+	return res, nil
+}
+
+func (m *deliveriesMock) UpsertDeliveryRequest(tx transaction.Delivery) error {
+	m.deliveries = append(m.deliveries, tx)
+	return nil
+}
+
+type cursorMock struct {
+	block uint64
+}
+
+func (c *cursorMock) Cursor() uint64         { return c.block }
+func (c *cursorMock) SetCursor(block uint64) { c.block = block }
+
+func TestExportImport(t *testing.T) {
+	sender := common.HexToAddress("0x1")
+	channelID := common.HexToHash("0xabc")
+
+	nonces := transaction.NewNonceTracker(nil, nil)
+	nonces.RestoreNonces(map[transaction.Sender]uint64{transaction.NewSender(sender, 137): 7})
+	ledger := settlement.NewMemoryLedger()
+	ledger.Record(137, channelID, big.NewInt(10))
+	settler := settlement.NewAutoSettler(nil, nil, nil, settlement.AutoSettlerConfig{})
+	settler.Feed(common.HexToAddress("0x2"), crypto.Promise{ChainID: 137, ChannelID: channelID.Bytes(), Amount: big.NewInt(20), Fee: big.NewInt(0)})
+
+	src := Components{
+		Nonces:     nonces,
+		Deliveries: &deliveriesMock{deliveries: []transaction.Delivery{{UniqueID: "a", ChainID: 137, Sender: sender, Nonce: 7, GasPrice: big.NewInt(1)}}},
+		Senders:    []transaction.Sender{transaction.NewSender(sender, 137)},
+		Promises:   settler,
+		Ledger:     ledger,
+		Cursors:    map[string]CursorState{"watchtower": &cursorMock{block: 100}},
+	}
+
+	snapshot, err := src.Export()
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, Encode(&buf, snapshot))
+	decoded, err := Decode(&buf)
+	assert.NoError(t, err)
+
+	dstNonces := transaction.NewNonceTracker(nil, nil)
+	dstLedger := settlement.NewMemoryLedger()
+	dstSettler := settlement.NewAutoSettler(nil, nil, nil, settlement.AutoSettlerConfig{})
+	dstDeliveries := &deliveriesMock{}
+	dstCursor := &cursorMock{}
+	dst := Components{
+		Nonces:     dstNonces,
+		Deliveries: dstDeliveries,
+		Promises:   dstSettler,
+		Ledger:     dstLedger,
+		Cursors:    map[string]CursorState{"watchtower": dstCursor},
+	}
+	assert.NoError(t, dst.Import(decoded))
+
+	assert.Equal(t, uint64(7), dstNonces.Nonces()[transaction.NewSender(sender, 137)])
+	assert.Len(t, dstDeliveries.deliveries, 1)
+	assert.Equal(t, "a", dstDeliveries.deliveries[0].UniqueID)
+	assert.Equal(t, 1, dstSettler.Pending())
+	settled, _ := dstLedger.SettledAmount(137, channelID)
+	assert.Equal(t, big.NewInt(10), settled)
+	assert.Equal(t, uint64(100), dstCursor.block)
+
+	t.Run("unsupported version", func(t *testing.T) {
+		_, err := Decode(strings.NewReader(`{"version": 2}`))
+		assert.ErrorIs(t, err, ErrUnsupportedVersion)
+		assert.ErrorIs(t, Components{}.Import(&Snapshot{}), ErrUnsupportedVersion)
+	})
+}
@@ -0,0 +1,292 @@
+package recurring
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/mysteriumnetwork/payments/transaction"
+)
+
+// DeliverableRecurringPayment is the delivery type of payments enqueued by the scheduler.
+const DeliverableRecurringPayment transaction.DeliverableType = "recurring_payment"
+
+// CatchUp decides what happens with runs that were missed, e.g. while the scheduler was not running.
+type CatchUp string
+
+const (
+	// CatchUpSkip drops missed runs, only the latest due run is executed.
+	CatchUpSkip CatchUp = "skip"
+	// CatchUpAll executes every missed run.
+	CatchUpAll CatchUp = "all"
+	// CatchUpMerge executes a single payment for the sum of all missed runs.
+	CatchUpMerge CatchUp = "merge"
+)
+
+var (
+	// ErrNotFound is returned for unknown subscriptions.
+	ErrNotFound = errors.New("subscription not found")
+	// ErrInvalidSubscription is returned when adding a malformed subscription.
+	ErrInvalidSubscription = errors.New("invalid subscription")
+)
+
+// Subscription is a payment repeated every interval.
+type Subscription struct {
+	ID      string
+	ChainID int64
+	// Token is the ERC20 token being paid, usually MYST.
+	Token       common.Address
+	Amount      *big.Int
+	Interval    time.Duration
+	Destination common.Address
+	// Funding is the account paying and sending the transactions.
+	Funding common.Address
+	// Start is the time of the first run.
+	Start time.Time
+	// MaxRuns limits the amount of runs. Zero means unlimited.
+	MaxRuns int
+	CatchUp CatchUp
+
+	// Runs is the amount of runs executed so far, including skipped ones.
+	Runs     int
+	Canceled bool
+}
+
+// Next returns the time of the next run.
+func (s Subscription) Next() time.Time {
+	return s.Start.Add(time.Duration(s.Runs) * s.Interval)
+}
+
+// Done returns true if the subscription will not run anymore.
+func (s Subscription) Done() bool {
+	return s.Canceled || (s.MaxRuns > 0 && s.Runs >= s.MaxRuns)
+}
+
+// Payment is the delivery data of a single recurring payment.
+type Payment struct {
+	SubscriptionID string `json:"subscriptionID"`
+	// Run is the index of the run being paid, merged payments have the index of the last run.
+	Run       int            `json:"run"`
+	Runs      int            `json:"runs"`
+	Token     common.Address `json:"token"`
+	Recipient common.Address `json:"recipient"`
+	Amount    *big.Int       `json:"amount"`
+}
+
+// Queue enqueues transactions to be sent, implemented by `transaction.Depot`.
+type Queue interface {
+	EnqueueDelivery(req transaction.DeliveryRequest, force bool) (string, error)
+}
+
+// Execution is emitted each time a payment is enqueued or fails to enqueue.
+type Execution struct {
+	Subscription Subscription
+	Payment      Payment
+	TrackingID   string
+	Err          error
+}
+
+// Scheduler executes subscriptions by enqueueing their payments.
+type Scheduler struct {
+	queue    Queue
+	interval time.Duration
+
+	subs map[string]*Subscription
+	mu   sync.Mutex
+
+	listeners []func(Execution)
+	logFn     func(error)
+	now       func() time.Time
+
+	once sync.Once
+	stop chan struct{}
+}
+
+// NewScheduler returns a new scheduler which checks for due runs every interval once `Run` is called.
+func NewScheduler(queue Queue, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		queue:    queue,
+		interval: interval,
+		subs:     make(map[string]*Subscription),
+		logFn:    func(error) {},
+		now:      time.Now,
+		stop:     make(chan struct{}),
+	}
+}
+
+// AttachLogger allows the caller to attach an optional logger
+// which will receive errors that happen while enqueueing payments.
+func (s *Scheduler) AttachLogger(fn func(err error)) {
+	s.logFn = fn
+}
+
+// OnExecution registers a listener which is called for every executed payment.
+//
+// This method is not thread safe and should be called before `Run`.
+func (s *Scheduler) OnExecution(fn func(Execution)) {
+	s.listeners = append(s.listeners, fn)
+}
+
+// Add adds or replaces a subscription.
+func (s *Scheduler) Add(sub Subscription) error {
+	if sub.ID == "" || sub.Amount == nil || sub.Amount.Sign() <= 0 || sub.Interval <= 0 {
+		return fmt.Errorf("%w: id, positive amount and interval are required", ErrInvalidSubscription)
+	}
+	if sub.CatchUp == "" {
+		sub.CatchUp = CatchUpSkip
+	}
+	if sub.Start.IsZero() {
+		sub.Start = s.now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subs[sub.ID] = &sub
+	return nil
+}
+
+// Cancel stops any further runs of the subscription.
+func (s *Scheduler) Cancel(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.subs[id]
+	if !ok {
+		return ErrNotFound
+	}
+	sub.Canceled = true
+	return nil
+}
+
+// Get returns the subscription with the given ID.
+func (s *Scheduler) Get(id string) (Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.subs[id]
+	if !ok {
+		return Subscription{}, ErrNotFound
+	}
+	return *sub, nil
+}
+
+// Run will spawn a goroutine which executes due subscriptions every interval.
+func (s *Scheduler) Run() {
+	go func() {
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-time.After(s.interval):
+				s.Tick()
+			}
+		}
+	}()
+}
+
+// Stop stops the scheduler.
+func (s *Scheduler) Stop() {
+	s.once.Do(func() {
+		close(s.stop)
+	})
+}
+
+// Tick executes all of the due runs.
+func (s *Scheduler) Tick() {
+	s.mu.Lock()
+	ids := make([]string, 0, len(s.subs))
+	for id := range s.subs {
+		ids = append(ids, id)
+	}
+	s.mu.Unlock()
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		for _, e := range s.execute(id) {
+			s.emit(e)
+		}
+	}
+}
+
+func (s *Scheduler) execute(id string) []Execution {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.subs[id]
+	if !ok {
+		return nil
+	}
+
+	var res []Execution
+	for _, p := range s.due(sub) {
+		trackingID, err := s.queue.EnqueueDelivery(transaction.DeliveryRequest{
+			ChainID: sub.ChainID,
+			Sender:  sub.Funding,
+			Type:    DeliverableRecurringPayment,
+			Data:    p,
+		}, false)
+		if err != nil {
+			// Retry on the next tick starting from the failed run.
+			err = fmt.Errorf("failed to enqueue payment %d of subscription %s: %w", p.Run, sub.ID, err)
+			s.logFn(err)
+			return append(res, Execution{Subscription: *sub, Payment: p, Err: err})
+		}
+
+		sub.Runs = p.Run + 1
+		res = append(res, Execution{Subscription: *sub, Payment: p, TrackingID: trackingID})
+	}
+	return res
+}
+
+// due returns the payments to make now according to the catch up policy.
+func (s *Scheduler) due(sub *Subscription) []Payment {
+	if sub.Done() {
+		return nil
+	}
+
+	now := s.now()
+	if now.Before(sub.Next()) {
+		return nil
+	}
+
+	last := int(now.Sub(sub.Start) / sub.Interval)
+	if sub.MaxRuns > 0 && last >= sub.MaxRuns {
+		last = sub.MaxRuns - 1
+	}
+	missed := last - sub.Runs + 1
+
+	payment := func(run, runs int) Payment {
+		return Payment{
+			SubscriptionID: sub.ID,
+			Run:            run,
+			Runs:           runs,
+			Token:          sub.Token,
+			Recipient:      sub.Destination,
+			Amount:         new(big.Int).Mul(sub.Amount, big.NewInt(int64(runs))),
+		}
+	}
+
+	switch sub.CatchUp {
+	case CatchUpAll:
+		res := make([]Payment, 0, missed)
+		for run := sub.Runs; run <= last; run++ {
+			res = append(res, payment(run, 1))
+		}
+		return res
+	case CatchUpMerge:
+		return []Payment{payment(last, missed)}
+	default:
+		return []Payment{payment(last, 1)}
+	}
+}
+
+func (s *Scheduler) emit(e Execution) {
+	for _, fn := range s.listeners {
+		fn(e)
+	}
+}
@@ -0,0 +1,121 @@
+package recurring
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/transaction"
+)
+
+type queueMock struct {
+	payments []Payment
+	err      error
+}
+
+func (q *queueMock) EnqueueDelivery(req transaction.DeliveryRequest, _ bool) (string, error) {
+	if q.err != nil {
+		return "", q.err
+	}
+	q.payments = append(q.payments, req.Data.(Payment))
+	return "id", nil
+}
+
+func TestScheduler(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	sub := func(id string, catchUp CatchUp) Subscription {
+		return Subscription{
+			ID:          id,
+			ChainID:     137,
+			Amount:      big.NewInt(10),
+			Interval:    time.Hour,
+			Destination: common.HexToAddress("0x1"),
+			Funding:     common.HexToAddress("0x2"),
+			Start:       start,
+			CatchUp:     catchUp,
+		}
+	}
+	setup := func(s Subscription) (*Scheduler, *queueMock, *time.Time) {
+		q := &queueMock{}
+		now := start
+		sch := NewScheduler(q, time.Minute)
+		sch.now = func() time.Time { return now }
+		assert.NoError(t, sch.Add(s))
+		return sch, q, &now
+	}
+
+	t.Run("runs on schedule", func(t *testing.T) {
+		sch, q, now := setup(sub("a", CatchUpSkip))
+		var executions []Execution
+		sch.OnExecution(func(e Execution) { executions = append(executions, e) })
+
+		sch.Tick()
+		assert.Len(t, q.payments, 1)
+		sch.Tick()
+		assert.Len(t, q.payments, 1)
+
+		*now = start.Add(time.Hour)
+		sch.Tick()
+		assert.Len(t, q.payments, 2)
+		assert.Equal(t, 1, q.payments[1].Run)
+		assert.Len(t, executions, 2)
+	})
+
+	t.Run("catch up policies", func(t *testing.T) {
+		for _, tt := range []struct {
+			catchUp  CatchUp
+			payments int
+			amount   int64
+		}{
+			{catchUp: CatchUpSkip, payments: 1, amount: 10},
+			{catchUp: CatchUpAll, payments: 3, amount: 10},
+			{catchUp: CatchUpMerge, payments: 1, amount: 30},
+		} {
+			t.Run(string(tt.catchUp), func(t *testing.T) {
+				sch, q, now := setup(sub("a", tt.catchUp))
+				*now = start.Add(2*time.Hour + time.Minute)
+				sch.Tick()
+
+				assert.Len(t, q.payments, tt.payments)
+				assert.Equal(t, big.NewInt(tt.amount), q.payments[len(q.payments)-1].Amount)
+				s, _ := sch.Get("a")
+				assert.Equal(t, 3, s.Runs)
+			})
+		}
+	})
+
+	t.Run("max runs and cancel", func(t *testing.T) {
+		s := sub("a", CatchUpAll)
+		s.MaxRuns = 2
+		sch, q, now := setup(s)
+		*now = start.Add(5 * time.Hour)
+		sch.Tick()
+		assert.Len(t, q.payments, 2)
+
+		assert.NoError(t, sch.Add(sub("b", CatchUpAll)))
+		assert.NoError(t, sch.Cancel("b"))
+		sch.Tick()
+		assert.Len(t, q.payments, 2)
+		assert.ErrorIs(t, sch.Cancel("c"), ErrNotFound)
+	})
+
+	t.Run("retries failed runs", func(t *testing.T) {
+		sch, q, _ := setup(sub("a", CatchUpAll))
+		q.err = errors.New("boom")
+		sch.Tick()
+		s, _ := sch.Get("a")
+		assert.Equal(t, 0, s.Runs)
+
+		q.err = nil
+		sch.Tick()
+		assert.Len(t, q.payments, 1)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		assert.ErrorIs(t, NewScheduler(&queueMock{}, time.Minute).Add(Subscription{ID: "a"}), ErrInvalidSubscription)
+	})
+}
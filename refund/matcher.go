@@ -0,0 +1,87 @@
+package refund
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrPaymentNotFound is returned when no incoming payment matches.
+var ErrPaymentNotFound = errors.New("incoming payment not found")
+
+// TransferTopic is the topic of the ERC20 Transfer(address,address,uint256) event.
+var TransferTopic = common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+
+// Payment is an incoming token payment.
+type Payment struct {
+	ChainID  int64
+	TxHash   common.Hash
+	LogIndex uint
+	Token    common.Address
+	From     common.Address
+	To       common.Address
+	Amount   *big.Int
+	// Reference is an optional integrator reference, e.g. an order ID.
+	Reference string
+}
+
+// Matcher finds the incoming payments made in the given transaction.
+// Integrators with an indexer or reference matcher should implement it on top of those.
+type Matcher interface {
+	Match(chainID int64, txHash common.Hash) ([]Payment, error)
+}
+
+// ReceiptReader returns transaction receipts, implemented by `client.MultichainBlockchainClient`.
+type ReceiptReader interface {
+	TransactionReceipt(chainID int64, hash common.Hash) (*types.Receipt, error)
+}
+
+// ReceiptMatcher matches ERC20 transfers to the given recipients by reading the transaction receipt.
+type ReceiptMatcher struct {
+	receipts   ReceiptReader
+	recipients map[common.Address]struct{}
+}
+
+// NewReceiptMatcher returns a matcher of transfers received by any of the recipients.
+func NewReceiptMatcher(receipts ReceiptReader, recipients ...common.Address) *ReceiptMatcher {
+	m := &ReceiptMatcher{receipts: receipts, recipients: make(map[common.Address]struct{}, len(recipients))}
+	for _, r := range recipients {
+		m.recipients[r] = struct{}{}
+	}
+	return m
+}
+
+// Match returns the transfers to the recipients in the given transaction.
+func (m *ReceiptMatcher) Match(chainID int64, txHash common.Hash) ([]Payment, error) {
+	receipt, err := m.receipts.TransactionReceipt(chainID, txHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receipt: %w", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, fmt.Errorf("%w: transaction %s failed", ErrPaymentNotFound, txHash.Hex())
+	}
+
+	var res []Payment
+	for _, l := range receipt.Logs {
+		if len(l.Topics) != 3 || l.Topics[0] != TransferTopic {
+			continue
+		}
+		to := common.BytesToAddress(l.Topics[2].Bytes())
+		if _, ok := m.recipients[to]; !ok {
+			continue
+		}
+		res = append(res, Payment{
+			ChainID:  chainID,
+			TxHash:   txHash,
+			LogIndex: l.Index,
+			Token:    l.Address,
+			From:     common.BytesToAddress(l.Topics[1].Bytes()),
+			To:       to,
+			Amount:   new(big.Int).SetBytes(l.Data),
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,273 @@
+package refund
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/mysteriumnetwork/payments/idempotency"
+	"github.com/mysteriumnetwork/payments/transaction"
+)
+
+// DeliverableRefund is the delivery type of refunds enqueued by the refunder.
+const DeliverableRefund transaction.DeliverableType = "refund"
+
+var (
+	// ErrAlreadyRefunded is returned when the payment was already refunded.
+	ErrAlreadyRefunded = errors.New("payment already refunded")
+	// ErrInvalidAmount is returned when the refund amount is not positive or exceeds the payment.
+	ErrInvalidAmount = errors.New("invalid refund amount")
+)
+
+// Request asks to refund an incoming payment.
+type Request struct {
+	ChainID  int64
+	TxHash   common.Hash
+	LogIndex uint
+	// Amount to refund, nil refunds the whole payment.
+	Amount *big.Int
+	Reason string
+}
+
+// Refund is an enqueued refund linked to the original payment.
+type Refund struct {
+	ID         string    `json:"id"`
+	Payment    Payment   `json:"payment"`
+	Amount     *big.Int  `json:"amount"`
+	Reason     string    `json:"reason"`
+	TrackingID string    `json:"trackingID"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Transfer is the delivery data of a refund, it pays the amount of token back to the recipient.
+type Transfer struct {
+	RefundID  string         `json:"refundID"`
+	Token     common.Address `json:"token"`
+	Recipient common.Address `json:"recipient"`
+	Amount    *big.Int       `json:"amount"`
+}
+
+// Action is a step recorded in the audit trail.
+type Action string
+
+const (
+	ActionRequested Action = "requested"
+	ActionRejected  Action = "rejected"
+	ActionEnqueued  Action = "enqueued"
+	ActionFailed    Action = "failed"
+)
+
+// AuditEntry is a single entry of the refund audit trail.
+type AuditEntry struct {
+	Time     time.Time
+	RefundID string
+	Action   Action
+	Request  Request
+	Refund   *Refund
+	Err      error
+}
+
+// AuditLog records the refund audit trail.
+type AuditLog interface {
+	Record(e AuditEntry)
+}
+
+// Queue enqueues transactions to be sent, implemented by `transaction.Depot`.
+type Queue interface {
+	EnqueueDelivery(req transaction.DeliveryRequest, force bool) (string, error)
+}
+
+// Refunder refunds incoming payments, at most once per payment.
+type Refunder struct {
+	matcher Matcher
+	queue   Queue
+	store   idempotency.Store
+	// senders are the accounts the refunds are paid from per chain.
+	senders map[int64]common.Address
+
+	audit AuditLog
+	now   func() time.Time
+}
+
+// NewRefunder returns a new refunder. The store records completed refunds
+// and must be shared by all instances to guarantee refund-once semantics.
+func NewRefunder(matcher Matcher, queue Queue, store idempotency.Store, senders map[int64]common.Address) *Refunder {
+	return &Refunder{
+		matcher: matcher,
+		queue:   queue,
+		store:   store,
+		senders: senders,
+		audit:   NewMemoryAuditLog(),
+		now:     time.Now,
+	}
+}
+
+// AttachAuditLog replaces the default in memory audit log.
+func (r *Refunder) AttachAuditLog(l AuditLog) {
+	r.audit = l
+}
+
+// ID returns the refund ID of the given payment.
+func ID(chainID int64, txHash common.Hash, logIndex uint) string {
+	return idempotency.Key(idempotency.OperationRefund, chainID, txHash.Hex(), logIndex)
+}
+
+// Refund matches the request to the original payment and enqueues the refund to the payer.
+func (r *Refunder) Refund(req Request) (Refund, error) {
+	id := ID(req.ChainID, req.TxHash, req.LogIndex)
+	r.record(AuditEntry{RefundID: id, Action: ActionRequested, Request: req})
+
+	refund, err := r.refund(id, req)
+	if err != nil {
+		action := ActionFailed
+		if errors.Is(err, ErrAlreadyRefunded) || errors.Is(err, ErrPaymentNotFound) || errors.Is(err, ErrInvalidAmount) || errors.Is(err, idempotency.ErrInProgress) {
+			action = ActionRejected
+		}
+		r.record(AuditEntry{RefundID: id, Action: action, Request: req, Err: err})
+		return Refund{}, err
+	}
+
+	r.record(AuditEntry{RefundID: id, Action: ActionEnqueued, Request: req, Refund: &refund})
+	return refund, nil
+}
+
+// Get returns a completed refund of the payment.
+func (r *Refunder) Get(chainID int64, txHash common.Hash, logIndex uint) (Refund, error) {
+	rec, err := r.store.Get(ID(chainID, txHash, logIndex))
+	if err != nil {
+		return Refund{}, err
+	}
+	if rec.Status != idempotency.StatusCompleted {
+		return Refund{}, idempotency.ErrInProgress
+	}
+	var res Refund
+	return res, json.Unmarshal(rec.Result, &res)
+}
+
+func (r *Refunder) refund(id string, req Request) (Refund, error) {
+	sender, ok := r.senders[req.ChainID]
+	if !ok {
+		return Refund{}, fmt.Errorf("no refund sender configured for chain %d", req.ChainID)
+	}
+
+	rec, reserved, err := r.store.Reserve(id)
+	if err != nil {
+		return Refund{}, fmt.Errorf("failed to reserve refund: %w", err)
+	}
+	if !reserved {
+		if rec.Status == idempotency.StatusCompleted {
+			return Refund{}, fmt.Errorf("%w: %s", ErrAlreadyRefunded, id)
+		}
+		return Refund{}, fmt.Errorf("refund %s: %w", id, idempotency.ErrInProgress)
+	}
+
+	refund, err := r.enqueue(id, sender, req)
+	if err != nil {
+		if rerr := r.store.Release(id); rerr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to release refund: %w", rerr))
+		}
+		return Refund{}, err
+	}
+
+	blob, err := json.Marshal(refund)
+	if err != nil {
+		return refund, err
+	}
+	// The refund is enqueued, if recording fails the record stays pending
+	// which still prevents refunding again.
+	if err := r.store.Complete(id, blob); err != nil {
+		return refund, fmt.Errorf("refund enqueued but not recorded: %w", err)
+	}
+	return refund, nil
+}
+
+func (r *Refunder) enqueue(id string, sender common.Address, req Request) (Refund, error) {
+	payments, err := r.matcher.Match(req.ChainID, req.TxHash)
+	if err != nil {
+		return Refund{}, err
+	}
+
+	var payment *Payment
+	for i := range payments {
+		if payments[i].LogIndex == req.LogIndex {
+			payment = &payments[i]
+		}
+	}
+	if payment == nil {
+		return Refund{}, fmt.Errorf("%w: %s log %d", ErrPaymentNotFound, req.TxHash.Hex(), req.LogIndex)
+	}
+
+	amount := req.Amount
+	if amount == nil {
+		amount = payment.Amount
+	}
+	if amount.Sign() <= 0 || amount.Cmp(payment.Amount) > 0 {
+		return Refund{}, fmt.Errorf("%w: %s of %s paid", ErrInvalidAmount, amount, payment.Amount)
+	}
+
+	trackingID, err := r.queue.EnqueueDelivery(transaction.DeliveryRequest{
+		ChainID: req.ChainID,
+		Sender:  sender,
+		Type:    DeliverableRefund,
+		Data: Transfer{
+			RefundID:  id,
+			Token:     payment.Token,
+			Recipient: payment.From,
+			Amount:    amount,
+		},
+	}, false)
+	if err != nil {
+		return Refund{}, fmt.Errorf("failed to enqueue refund: %w", err)
+	}
+
+	return Refund{
+		ID:         id,
+		Payment:    *payment,
+		Amount:     amount,
+		Reason:     req.Reason,
+		TrackingID: trackingID,
+		CreatedAt:  r.now().UTC(),
+	}, nil
+}
+
+func (r *Refunder) record(e AuditEntry) {
+	e.Time = r.now().UTC()
+	r.audit.Record(e)
+}
+
+// MemoryAuditLog keeps the audit trail in memory.
+type MemoryAuditLog struct {
+	entries []AuditEntry
+	mu      sync.Mutex
+}
+
+// NewMemoryAuditLog returns an empty in memory audit log.
+func NewMemoryAuditLog() *MemoryAuditLog {
+	return &MemoryAuditLog{}
+}
+
+// Record appends the entry.
+func (l *MemoryAuditLog) Record(e AuditEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, e)
+}
+
+// Entries returns the audit trail of the given refund, or all entries if the ID is empty.
+func (l *MemoryAuditLog) Entries(refundID string) []AuditEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	res := make([]AuditEntry, 0)
+	for _, e := range l.entries {
+		if refundID == "" || e.RefundID == refundID {
+			res = append(res, e)
+		}
+	}
+	return res
+}
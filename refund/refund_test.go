@@ -0,0 +1,120 @@
+package refund
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/idempotency"
+	"github.com/mysteriumnetwork/payments/transaction"
+)
+
+type receiptsMock struct {
+	receipt *types.Receipt
+}
+
+func (m *receiptsMock) TransactionReceipt(int64, common.Hash) (*types.Receipt, error) {
+	return m.receipt, nil
+}
+
+type queueMock struct {
+	requests []transaction.DeliveryRequest
+	err      error
+}
+
+func (q *queueMock) EnqueueDelivery(req transaction.DeliveryRequest, _ bool) (string, error) {
+	if q.err != nil {
+		return "", q.err
+	}
+	q.requests = append(q.requests, req)
+	return "id", nil
+}
+
+func TestRefunder(t *testing.T) {
+	merchant := common.HexToAddress("0x1")
+	payer := common.HexToAddress("0x2")
+	token := common.HexToAddress("0x3")
+	txHash := common.HexToHash("0xaa")
+
+	receipts := &receiptsMock{receipt: &types.Receipt{
+		Status: types.ReceiptStatusSuccessful,
+		Logs: []*types.Log{
+			{Address: token, Index: 1, Topics: []common.Hash{TransferTopic, common.BytesToHash(payer.Bytes()), common.BytesToHash(common.HexToAddress("0x9").Bytes())}, Data: big.NewInt(5).Bytes()},
+			{Address: token, Index: 2, Topics: []common.Hash{TransferTopic, common.BytesToHash(payer.Bytes()), common.BytesToHash(merchant.Bytes())}, Data: big.NewInt(100).Bytes()},
+		},
+	}}
+
+	setup := func() (*Refunder, *queueMock, *MemoryAuditLog) {
+		q := &queueMock{}
+		audit := NewMemoryAuditLog()
+		r := NewRefunder(NewReceiptMatcher(receipts, merchant), q, idempotency.NewMemoryStore(), map[int64]common.Address{137: merchant})
+		r.AttachAuditLog(audit)
+		return r, q, audit
+	}
+
+	t.Run("matcher", func(t *testing.T) {
+		payments, err := NewReceiptMatcher(receipts, merchant).Match(137, txHash)
+		assert.NoError(t, err)
+		assert.Len(t, payments, 1)
+		assert.Equal(t, payer, payments[0].From)
+		assert.Equal(t, big.NewInt(100), payments[0].Amount)
+	})
+
+	t.Run("refunds once", func(t *testing.T) {
+		r, q, audit := setup()
+
+		refund, err := r.Refund(Request{ChainID: 137, TxHash: txHash, LogIndex: 2, Amount: big.NewInt(40), Reason: "cancelled"})
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(40), refund.Amount)
+		assert.Len(t, q.requests, 1)
+		transfer := q.requests[0].Data.(Transfer)
+		assert.Equal(t, payer, transfer.Recipient)
+		assert.Equal(t, token, transfer.Token)
+
+		_, err = r.Refund(Request{ChainID: 137, TxHash: txHash, LogIndex: 2})
+		assert.ErrorIs(t, err, ErrAlreadyRefunded)
+		assert.Len(t, q.requests, 1)
+
+		stored, err := r.Get(137, txHash, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, "cancelled", stored.Reason)
+
+		var actions []Action
+		for _, e := range audit.Entries(refund.ID) {
+			actions = append(actions, e.Action)
+		}
+		assert.Equal(t, []Action{ActionRequested, ActionEnqueued, ActionRequested, ActionRejected}, actions)
+	})
+
+	t.Run("rejects", func(t *testing.T) {
+		r, _, _ := setup()
+
+		_, err := r.Refund(Request{ChainID: 137, TxHash: txHash, LogIndex: 1})
+		assert.ErrorIs(t, err, ErrPaymentNotFound)
+
+		_, err = r.Refund(Request{ChainID: 137, TxHash: txHash, LogIndex: 2, Amount: big.NewInt(101)})
+		assert.ErrorIs(t, err, ErrInvalidAmount)
+
+		// Rejected refunds can be retried.
+		_, err = r.Refund(Request{ChainID: 137, TxHash: txHash, LogIndex: 2})
+		assert.NoError(t, err)
+	})
+
+	t.Run("enqueue failure releases the payment", func(t *testing.T) {
+		r, q, audit := setup()
+		q.err = errors.New("boom")
+
+		_, err := r.Refund(Request{ChainID: 137, TxHash: txHash, LogIndex: 2})
+		assert.Error(t, err)
+		entries := audit.Entries("")
+		assert.Equal(t, ActionFailed, entries[len(entries)-1].Action)
+
+		q.err = nil
+		_, err = r.Refund(Request{ChainID: 137, TxHash: txHash, LogIndex: 2})
+		assert.NoError(t, err)
+	})
+}
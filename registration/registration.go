@@ -24,6 +24,8 @@ import (
 	"github.com/ethereum/go-ethereum/common/math"
 
 	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/mysteriumnetwork/payments/crypto/codec"
+	"github.com/mysteriumnetwork/payments/crypto/signatures"
 )
 
 // Request represent a request to register
@@ -47,33 +49,49 @@ func (r Request) GetFee() *big.Int {
 	return r.Fee
 }
 
-// GetMessage forms the message payload given the registration request
+// EncodeMessage forms the message payload given the registration request,
+// returning an error if any of the fields is malformed.
+func (r Request) EncodeMessage() ([]byte, error) {
+	registry, err := codec.ParseAddress("registry", r.RegistryAddress)
+	if err != nil {
+		return nil, err
+	}
+	hermesID, err := codec.ParseAddress("hermes id", r.HermesID)
+	if err != nil {
+		return nil, err
+	}
+	beneficiary, err := codec.ParseAddress("beneficiary", r.Beneficiary)
+	if err != nil {
+		return nil, err
+	}
+
+	return codec.EncodeRegistration(codec.RegistrationPayload{
+		ChainID:     r.ChainID,
+		Registry:    registry,
+		HermesID:    hermesID,
+		Stake:       r.Stake,
+		Fee:         r.Fee,
+		Beneficiary: beneficiary,
+	})
+}
+
+// GetMessage forms the message payload given the registration request.
+// It returns nil if the request is malformed, use `EncodeMessage` to get the error.
 func (r Request) GetMessage() []byte {
-	message := []byte{}
-	message = append(message, crypto.Pad(math.U256Bytes(big.NewInt(r.ChainID)), 32)...)
-	message = append(message, common.HexToAddress(r.RegistryAddress).Bytes()...)
-	message = append(message, common.HexToAddress(r.HermesID).Bytes()...)
-	message = append(message, crypto.Pad(math.U256(r.Stake).Bytes(), 32)...)
-	message = append(message, crypto.Pad(math.U256(r.Fee).Bytes(), 32)...)
-	message = append(message, common.HexToAddress(r.Beneficiary).Bytes()...)
+	message, err := r.EncodeMessage()
+	if err != nil {
+		return nil
+	}
 	return message
 }
 
 // RecoverIdentity recovers the identity from the given request
 func (r Request) RecoverIdentity() (common.Address, error) {
-	signature := GetSignatureBytesRaw(r.Signature)
-
-	err := crypto.ReformatSignatureVForRecovery(signature)
-	if err != nil {
-		return common.Address{}, err
-	}
-
-	recoveredAddress, err := crypto.RecoverAddress(r.GetMessage(), signature)
+	message, err := r.EncodeMessage()
 	if err != nil {
 		return common.Address{}, err
 	}
-
-	return recoveredAddress, nil
+	return signatures.RecoverMessage(message, GetSignatureBytesRaw(r.Signature))
 }
 
 type OpenConsumerChannelRequest struct {
@@ -96,19 +114,7 @@ func (r *OpenConsumerChannelRequest) GetMessage() []byte {
 
 // RecoverIdentity recovers the identity from the given request
 func (r *OpenConsumerChannelRequest) RecoverIdentity() (common.Address, error) {
-	signature := common.Hex2Bytes(strings.TrimPrefix(r.Signature, "0x"))
-
-	err := crypto.ReformatSignatureVForRecovery(signature)
-	if err != nil {
-		return common.Address{}, err
-	}
-
-	recoveredAddress, err := crypto.RecoverAddress(r.GetMessage(), signature)
-	if err != nil {
-		return common.Address{}, err
-	}
-
-	return recoveredAddress, nil
+	return signatures.RecoverMessage(r.GetMessage(), common.Hex2Bytes(strings.TrimPrefix(r.Signature, "0x")))
 }
 
 // GetSignatureBytesRaw returns the unadulterated bytes of the signature
@@ -0,0 +1,62 @@
+package relayer
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/mysteriumnetwork/payments/idempotency"
+)
+
+// maxRequestSize limits the size of accepted request bodies.
+const maxRequestSize = 64 << 10
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewHandler returns an http handler which accepts json encoded `Request` objects
+// using POST and responds with a `Receipt` once the request is enqueued.
+func NewHandler(r *Relayer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+			return
+		}
+
+		var relayReq Request
+		if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxRequestSize)).Decode(&relayReq); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "malformed request body"})
+			return
+		}
+
+		receipt, err := r.Relay(relayReq)
+		if err != nil {
+			writeJSON(w, statusFor(err), errorResponse{Error: err.Error()})
+			return
+		}
+
+		writeJSON(w, http.StatusAccepted, receipt)
+	})
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidRequest), errors.Is(err, ErrUnsupportedChain):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrFeeTooLow):
+		return http.StatusPaymentRequired
+	case errors.Is(err, ErrQuotaExceeded):
+		return http.StatusTooManyRequests
+	case errors.Is(err, idempotency.ErrInProgress):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,330 @@
+package relayer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/mysteriumnetwork/payments/idempotency"
+	"github.com/mysteriumnetwork/payments/registration"
+	"github.com/mysteriumnetwork/payments/transaction"
+)
+
+// Kind is a kind of meta transaction the relayer accepts.
+type Kind string
+
+const (
+	KindRegistration      Kind = "registration"
+	KindSettlement        Kind = "settlement"
+	KindBeneficiaryChange Kind = "beneficiary_change"
+)
+
+// Delivery types used when enqueueing relayed transactions. Couriers
+// should use them to determine how to package the transaction.
+const (
+	DeliverableRegistration      transaction.DeliverableType = "relay_registration"
+	DeliverableSettlement        transaction.DeliverableType = "relay_settlement"
+	DeliverableBeneficiaryChange transaction.DeliverableType = "relay_beneficiary_change"
+)
+
+var (
+	// ErrInvalidRequest is returned when a request is malformed or its signature does not match.
+	ErrInvalidRequest = errors.New("invalid relay request")
+	// ErrFeeTooLow is returned when the fee paid to the relayer is lower than required.
+	ErrFeeTooLow = errors.New("transactor fee too low")
+	// ErrQuotaExceeded is returned when an identity has used up its quota.
+	ErrQuotaExceeded = errors.New("relay quota exceeded")
+	// ErrUnsupportedChain is returned for requests on chains the relayer does not serve.
+	ErrUnsupportedChain = errors.New("unsupported chain")
+)
+
+// Request is a signed meta transaction payload submitted to the relayer.
+// Exactly one of the payloads matching the kind must be set.
+type Request struct {
+	Kind    Kind  `json:"kind"`
+	ChainID int64 `json:"chainID"`
+
+	Registration *registration.Request `json:"registration,omitempty"`
+	Settlement   *SettlementPayload    `json:"settlement,omitempty"`
+	Beneficiary  *BeneficiaryPayload   `json:"beneficiary,omitempty"`
+}
+
+// SettlementPayload is a settlement of a hermes signed promise for the given provider.
+type SettlementPayload struct {
+	HermesID   common.Address `json:"hermesID"`
+	ProviderID common.Address `json:"providerID"`
+	Promise    crypto.Promise `json:"promise"`
+}
+
+// BeneficiaryPayload is a settlement into a new beneficiary signed by the identity.
+type BeneficiaryPayload struct {
+	HermesID common.Address               `json:"hermesID"`
+	Promise  crypto.Promise               `json:"promise"`
+	Request  crypto.SetBeneficiaryRequest `json:"request"`
+}
+
+// Queue enqueues transactions to be sent, implemented by `transaction.Depot`.
+type Queue interface {
+	EnqueueDelivery(req transaction.DeliveryRequest, force bool) (string, error)
+}
+
+// HermesResolver resolves the operator which signs promises for the given hermes.
+type HermesResolver interface {
+	GetHermesOperator(chainID int64, hermesID common.Address) (common.Address, error)
+}
+
+// Policy configures the anti-abuse rules of the relayer.
+type Policy struct {
+	// MinFee is the minimal transactor fee per chain and kind. Missing entries allow any fee.
+	MinFee map[int64]map[Kind]*big.Int
+	// Quota is the amount of requests a single identity can relay within the window. Zero means unlimited.
+	Quota       int
+	QuotaWindow time.Duration
+}
+
+// Receipt is returned for an accepted request.
+type Receipt struct {
+	TrackingID string         `json:"trackingID"`
+	Identity   common.Address `json:"identity"`
+}
+
+// Relayer validates signed meta transactions and submits them using its own funds.
+type Relayer struct {
+	queue  Queue
+	hermes HermesResolver
+	policy Policy
+
+	// senders holds the relayer account used for every chain it serves.
+	senders map[int64]common.Address
+
+	// relayed records the receipts of relayed requests by payload hash, so replays are not relayed again.
+	relayed *idempotency.Layer
+
+	usage   map[common.Address][]time.Time
+	sweptAt time.Time
+	mu      sync.Mutex
+	now     func() time.Time
+}
+
+// New returns a new relayer which sends transactions on the chains present in senders.
+func New(queue Queue, hermes HermesResolver, senders map[int64]common.Address, policy Policy) *Relayer {
+	return &Relayer{
+		queue:   queue,
+		hermes:  hermes,
+		policy:  policy,
+		senders: senders,
+		relayed: idempotency.New(idempotency.NewMemoryStoreWithTTL(idempotency.DefaultMemoryTTL)),
+		usage:   make(map[common.Address][]time.Time),
+		now:     time.Now,
+	}
+}
+
+// AttachIdempotencyStore allows the caller to replace the in memory store which records
+// the receipts of relayed requests. A persistent store keeps replays of a request from
+// being relayed again after a restart.
+//
+// This method is not thread safe and should be called before the relayer is used.
+func (r *Relayer) AttachIdempotencyStore(store idempotency.Store) {
+	r.relayed = idempotency.New(store)
+}
+
+// Relay validates the request, charges it against the identity quota and enqueues it.
+// Replays of a relayed request get its receipt back without being charged or enqueued again,
+// replays racing with the request fail with `idempotency.ErrInProgress`.
+func (r *Relayer) Relay(req Request) (Receipt, error) {
+	sender, ok := r.senders[req.ChainID]
+	if !ok {
+		return Receipt{}, fmt.Errorf("%w: %d", ErrUnsupportedChain, req.ChainID)
+	}
+
+	identity, data, txType, err := r.validate(req)
+	if err != nil {
+		return Receipt{}, err
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return Receipt{}, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	key := idempotency.Key(idempotency.OperationRelay, req.ChainID, ethcrypto.Keccak256Hash(payload).Hex())
+
+	return idempotency.Do(r.relayed, key, func() (Receipt, error) {
+		if err := r.charge(identity); err != nil {
+			return Receipt{}, err
+		}
+
+		id, err := r.queue.EnqueueDelivery(transaction.DeliveryRequest{
+			ChainID: req.ChainID,
+			Sender:  sender,
+			Type:    txType,
+			Data:    data,
+		}, false)
+		if err != nil {
+			r.refund(identity)
+			return Receipt{}, fmt.Errorf("failed to enqueue relayed transaction: %w", err)
+		}
+
+		return Receipt{TrackingID: id, Identity: identity}, nil
+	})
+}
+
+func (r *Relayer) validate(req Request) (common.Address, interface{}, transaction.DeliverableType, error) {
+	switch req.Kind {
+	case KindRegistration:
+		p := req.Registration
+		if p == nil || p.ChainID != req.ChainID || p.Fee == nil || p.Stake == nil {
+			return common.Address{}, nil, "", fmt.Errorf("%w: malformed registration", ErrInvalidRequest)
+		}
+		identity, err := p.RecoverIdentity()
+		if err != nil {
+			return common.Address{}, nil, "", fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+		}
+		if err := r.checkFee(req.ChainID, req.Kind, p.Fee); err != nil {
+			return common.Address{}, nil, "", err
+		}
+		return identity, p, DeliverableRegistration, nil
+
+	case KindSettlement:
+		p := req.Settlement
+		if p == nil {
+			return common.Address{}, nil, "", fmt.Errorf("%w: malformed settlement", ErrInvalidRequest)
+		}
+		if err := r.checkPromise(req.ChainID, req.Kind, p.HermesID, p.Promise); err != nil {
+			return common.Address{}, nil, "", err
+		}
+		// The provider is not signed, it is only trusted as the owner of the promised channel.
+		if err := checkChannel(p.ProviderID, p.HermesID, p.Promise); err != nil {
+			return common.Address{}, nil, "", err
+		}
+		return p.ProviderID, p, DeliverableSettlement, nil
+
+	case KindBeneficiaryChange:
+		p := req.Beneficiary
+		if p == nil || p.Request.ChainID != req.ChainID || p.Request.Nonce == nil {
+			return common.Address{}, nil, "", fmt.Errorf("%w: malformed beneficiary change", ErrInvalidRequest)
+		}
+		identity, err := p.Request.RecoverSigner()
+		if err != nil {
+			return common.Address{}, nil, "", fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+		}
+		if !strings.EqualFold(identity.Hex(), common.HexToAddress(p.Request.Identity).Hex()) {
+			return common.Address{}, nil, "", fmt.Errorf("%w: beneficiary change not signed by identity %s", ErrInvalidRequest, p.Request.Identity)
+		}
+		if err := r.checkPromise(req.ChainID, req.Kind, p.HermesID, p.Promise); err != nil {
+			return common.Address{}, nil, "", err
+		}
+		if err := checkChannel(identity, p.HermesID, p.Promise); err != nil {
+			return common.Address{}, nil, "", err
+		}
+		return identity, p, DeliverableBeneficiaryChange, nil
+
+	default:
+		return common.Address{}, nil, "", fmt.Errorf("%w: unknown kind %q", ErrInvalidRequest, req.Kind)
+	}
+}
+
+func (r *Relayer) checkPromise(chainID int64, kind Kind, hermesID common.Address, p crypto.Promise) error {
+	if p.ChainID != chainID || p.Amount == nil || p.Fee == nil || len(p.ChannelID) != 32 {
+		return fmt.Errorf("%w: malformed promise", ErrInvalidRequest)
+	}
+
+	operator, err := r.hermes.GetHermesOperator(chainID, hermesID)
+	if err != nil {
+		return fmt.Errorf("failed to get hermes operator: %w", err)
+	}
+	signer, err := p.RecoverSigner()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	if !bytes.Equal(signer.Bytes(), operator.Bytes()) {
+		return fmt.Errorf("%w: promise not signed by hermes %s", ErrInvalidRequest, hermesID.Hex())
+	}
+
+	return r.checkFee(chainID, kind, p.Fee)
+}
+
+// checkChannel checks that the promise is for the provider channel of the identity with the hermes.
+func checkChannel(identity, hermesID common.Address, p crypto.Promise) error {
+	channelID, err := crypto.GenerateProviderChannelID(identity.Hex(), hermesID.Hex())
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	if !bytes.Equal(common.FromHex(channelID), p.ChannelID) {
+		return fmt.Errorf("%w: promise is not for the channel of %s", ErrInvalidRequest, identity.Hex())
+	}
+	return nil
+}
+
+func (r *Relayer) checkFee(chainID int64, kind Kind, fee *big.Int) error {
+	min, ok := r.policy.MinFee[chainID][kind]
+	if !ok || min == nil {
+		return nil
+	}
+	if fee.Cmp(min) < 0 {
+		return fmt.Errorf("%w: got %s, need at least %s", ErrFeeTooLow, fee, min)
+	}
+	return nil
+}
+
+func (r *Relayer) charge(identity common.Address) error {
+	if r.policy.Quota <= 0 {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	r.sweep(now)
+	used := r.usage[identity][:0]
+	for _, at := range r.usage[identity] {
+		if now.Sub(at) < r.policy.QuotaWindow {
+			used = append(used, at)
+		}
+	}
+	if len(used) >= r.policy.Quota {
+		r.usage[identity] = used
+		return fmt.Errorf("%w: identity %s", ErrQuotaExceeded, identity.Hex())
+	}
+
+	r.usage[identity] = append(used, now)
+	return nil
+}
+
+func (r *Relayer) refund(identity common.Address) {
+	if r.policy.Quota <= 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	used := r.usage[identity]
+	if len(used) <= 1 {
+		delete(r.usage, identity)
+		return
+	}
+	r.usage[identity] = used[:len(used)-1]
+}
+
+// sweep removes the identities which did not relay anything within the quota window, once per window.
+func (r *Relayer) sweep(now time.Time) {
+	if now.Sub(r.sweptAt) < r.policy.QuotaWindow {
+		return
+	}
+	for identity, used := range r.usage {
+		if len(used) == 0 || now.Sub(used[len(used)-1]) >= r.policy.QuotaWindow {
+			delete(r.usage, identity)
+		}
+	}
+	r.sweptAt = now
+}
@@ -0,0 +1,241 @@
+package relayer
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/mysteriumnetwork/payments/crypto/signatures"
+	"github.com/mysteriumnetwork/payments/registration"
+	"github.com/mysteriumnetwork/payments/transaction"
+)
+
+type keySigner struct {
+	key *ecdsa.PrivateKey
+}
+
+func newKeySigner(t *testing.T) (*keySigner, common.Address) {
+	key, err := ethcrypto.GenerateKey()
+	assert.NoError(t, err)
+	return &keySigner{key: key}, ethcrypto.PubkeyToAddress(key.PublicKey)
+}
+
+func (k *keySigner) SignHash(_ accounts.Account, hash []byte) ([]byte, error) {
+	return ethcrypto.Sign(hash, k.key)
+}
+
+type queueMock struct {
+	requests []transaction.DeliveryRequest
+	err      error
+}
+
+func (q *queueMock) EnqueueDelivery(req transaction.DeliveryRequest, _ bool) (string, error) {
+	if q.err != nil {
+		return "", q.err
+	}
+	q.requests = append(q.requests, req)
+	return "id", nil
+}
+
+type hermesMock struct {
+	operator common.Address
+}
+
+func (h hermesMock) GetHermesOperator(int64, common.Address) (common.Address, error) {
+	return h.operator, nil
+}
+
+func TestRelayer(t *testing.T) {
+	relayerAcc := common.HexToAddress("0x5")
+	hermesID := common.HexToAddress("0x6")
+	hermesKs, hermesOperator := newKeySigner(t)
+	identityKs, identity := newKeySigner(t)
+
+	registrationReq := func(fee int64) Request {
+		r := registration.Request{
+			ChainID:         137,
+			HermesID:        hermesID.Hex(),
+			Stake:           big.NewInt(0),
+			Fee:             big.NewInt(fee),
+			Beneficiary:     identity.Hex(),
+			RegistryAddress: common.HexToAddress("0x7").Hex(),
+		}
+		sig, err := signatures.SignMessage(identityKs, identity, r.GetMessage())
+		assert.NoError(t, err)
+		r.Signature = hex.EncodeToString(sig)
+		return Request{Kind: KindRegistration, ChainID: 137, Registration: &r}
+	}
+
+	channelID, err := crypto.GenerateProviderChannelID(identity.Hex(), hermesID.Hex())
+	assert.NoError(t, err)
+
+	promise := func(ks signatures.HashSigner, signer common.Address) crypto.Promise {
+		p, err := crypto.CreatePromise(channelID, 137, big.NewInt(100), big.NewInt(10), common.HexToHash("0x1").Hex(), ks, signer)
+		assert.NoError(t, err)
+		return *p
+	}
+
+	newRelayer := func(q Queue, policy Policy) *Relayer {
+		return New(q, hermesMock{operator: hermesOperator}, map[int64]common.Address{137: relayerAcc}, policy)
+	}
+
+	t.Run("relays registration", func(t *testing.T) {
+		q := &queueMock{}
+		r := newRelayer(q, Policy{})
+
+		receipt, err := r.Relay(registrationReq(1))
+		assert.NoError(t, err)
+		assert.Equal(t, Receipt{TrackingID: "id", Identity: identity}, receipt)
+		assert.Len(t, q.requests, 1)
+		assert.Equal(t, relayerAcc, q.requests[0].Sender)
+		assert.Equal(t, DeliverableRegistration, q.requests[0].Type)
+	})
+
+	t.Run("relays settlement", func(t *testing.T) {
+		q := &queueMock{}
+		r := newRelayer(q, Policy{})
+
+		_, err := r.Relay(Request{Kind: KindSettlement, ChainID: 137, Settlement: &SettlementPayload{
+			HermesID:   hermesID,
+			ProviderID: identity,
+			Promise:    promise(hermesKs, hermesOperator),
+		}})
+		assert.NoError(t, err)
+		assert.Equal(t, DeliverableSettlement, q.requests[0].Type)
+	})
+
+	t.Run("rejects promise not signed by hermes", func(t *testing.T) {
+		r := newRelayer(&queueMock{}, Policy{})
+		_, err := r.Relay(Request{Kind: KindSettlement, ChainID: 137, Settlement: &SettlementPayload{
+			HermesID: hermesID,
+			Promise:  promise(identityKs, identity),
+		}})
+		assert.ErrorIs(t, err, ErrInvalidRequest)
+	})
+
+	t.Run("rejects promise for the channel of another provider", func(t *testing.T) {
+		q := &queueMock{}
+		r := newRelayer(q, Policy{})
+		_, err := r.Relay(Request{Kind: KindSettlement, ChainID: 137, Settlement: &SettlementPayload{
+			HermesID:   hermesID,
+			ProviderID: relayerAcc,
+			Promise:    promise(hermesKs, hermesOperator),
+		}})
+		assert.ErrorIs(t, err, ErrInvalidRequest)
+		assert.Empty(t, q.requests)
+	})
+
+	t.Run("beneficiary change", func(t *testing.T) {
+		req, err := crypto.CreateBeneficiaryRequest(137, identity.Hex(), "0x7", "0x8", big.NewInt(1), identityKs, identity)
+		assert.NoError(t, err)
+
+		q := &queueMock{}
+		receipt, err := newRelayer(q, Policy{}).Relay(Request{Kind: KindBeneficiaryChange, ChainID: 137, Beneficiary: &BeneficiaryPayload{
+			HermesID: hermesID,
+			Promise:  promise(hermesKs, hermesOperator),
+			Request:  *req,
+		}})
+		assert.NoError(t, err)
+		assert.Equal(t, identity, receipt.Identity)
+
+		req.Identity = strings.TrimPrefix(relayerAcc.Hex(), "0x")
+		_, err = newRelayer(q, Policy{}).Relay(Request{Kind: KindBeneficiaryChange, ChainID: 137, Beneficiary: &BeneficiaryPayload{
+			HermesID: hermesID,
+			Promise:  promise(hermesKs, hermesOperator),
+			Request:  *req,
+		}})
+		assert.ErrorIs(t, err, ErrInvalidRequest)
+	})
+
+	t.Run("policy", func(t *testing.T) {
+		now := time.Now()
+		q := &queueMock{}
+		r := newRelayer(q, Policy{
+			MinFee:      map[int64]map[Kind]*big.Int{137: {KindRegistration: big.NewInt(5)}},
+			Quota:       1,
+			QuotaWindow: time.Hour,
+		})
+		r.now = func() time.Time { return now }
+
+		_, err := r.Relay(registrationReq(1))
+		assert.ErrorIs(t, err, ErrFeeTooLow)
+
+		_, err = r.Relay(registrationReq(5))
+		assert.NoError(t, err)
+		_, err = r.Relay(registrationReq(6))
+		assert.ErrorIs(t, err, ErrQuotaExceeded)
+
+		now = now.Add(time.Hour)
+		_, err = r.Relay(registrationReq(6))
+		assert.NoError(t, err)
+		assert.Len(t, r.usage, 1)
+
+		// Identities which did not relay anything within the window are forgotten.
+		now = now.Add(time.Hour)
+		assert.NoError(t, r.charge(relayerAcc))
+		assert.Len(t, r.usage, 1)
+		assert.Contains(t, r.usage, relayerAcc)
+	})
+
+	t.Run("replays are not relayed again", func(t *testing.T) {
+		q := &queueMock{}
+		r := newRelayer(q, Policy{Quota: 2, QuotaWindow: time.Hour})
+
+		req := registrationReq(1)
+		first, err := r.Relay(req)
+		assert.NoError(t, err)
+		replay, err := r.Relay(req)
+		assert.NoError(t, err)
+		assert.Equal(t, first, replay)
+		assert.Len(t, q.requests, 1)
+
+		_, err = r.Relay(registrationReq(2))
+		assert.NoError(t, err)
+	})
+
+	t.Run("enqueue failure does not use quota", func(t *testing.T) {
+		q := &queueMock{err: errors.New("boom")}
+		r := newRelayer(q, Policy{Quota: 1, QuotaWindow: time.Hour})
+
+		_, err := r.Relay(registrationReq(1))
+		assert.Error(t, err)
+
+		assert.Empty(t, r.usage)
+
+		q.err = nil
+		_, err = r.Relay(registrationReq(1))
+		assert.NoError(t, err)
+	})
+
+	t.Run("unsupported chain", func(t *testing.T) {
+		_, err := newRelayer(&queueMock{}, Policy{}).Relay(Request{Kind: KindRegistration, ChainID: 1})
+		assert.ErrorIs(t, err, ErrUnsupportedChain)
+	})
+
+	t.Run("http", func(t *testing.T) {
+		srv := httptest.NewServer(NewHandler(newRelayer(&queueMock{}, Policy{})))
+		defer srv.Close()
+
+		resp, err := http.Post(srv.URL, "application/json", strings.NewReader(`{"kind":"unknown","chainID":137}`))
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp, err = http.Get(srv.URL)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+}
@@ -0,0 +1,472 @@
+package settlement
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/mysteriumnetwork/payments/transaction"
+	"github.com/mysteriumnetwork/payments/transaction/gas"
+)
+
+// Submitter submits a settlement of the given promise, for example by
+// enqueueing a delivery into the transaction depot, and returns its tracking ID.
+type Submitter interface {
+	SubmitSettlement(hermesID common.Address, promise crypto.Promise) (string, error)
+}
+
+// SubmitterFunc allows using a function as a `Submitter`.
+type SubmitterFunc func(hermesID common.Address, promise crypto.Promise) (string, error)
+
+// SubmitSettlement calls the function.
+func (f SubmitterFunc) SubmitSettlement(hermesID common.Address, promise crypto.Promise) (string, error) {
+	return f(hermesID, promise)
+}
+
+// GasStation returns gas prices for a chain, `gas.MultichainStation` satisfies it.
+type GasStation interface {
+	GetGasPrices(chainID int64) (*gas.GasPrices, error)
+}
+
+// AutoSettlerConfig configures the auto settler.
+type AutoSettlerConfig struct {
+	// Interval is how often the fed promises are checked.
+	Interval time.Duration
+	// Threshold is the minimal unsettled amount of a channel that triggers a settlement.
+	Threshold *big.Int
+	// Speed selects which gas price is compared against `MaxGasPrice`.
+	Speed transaction.GasTrackerSpeed
+	// MaxGasPrice is the highest total gas price (base fee and tip) per chain a settlement
+	// is submitted at. Settlements are postponed while gas is more expensive.
+	// Chains without a limit are not guarded.
+	MaxGasPrice map[int64]*big.Int
+	// PendingTimeout is how long a submitted settlement may stay unsettled on chain before
+	// it is considered lost, e.g. dropped or replaced, and is submitted again. Zero means
+	// submissions are only given up when reported with `SettlementFailed`.
+	PendingTimeout time.Duration
+}
+
+// EventType is a type of an auto settler event.
+type EventType string
+
+const (
+	EventSettlementSubmitted EventType = "settlement_submitted"
+	EventSettlementPostponed EventType = "settlement_postponed"
+	EventSettlementFailed    EventType = "settlement_failed"
+	EventChannelSettled      EventType = "channel_settled"
+)
+
+// Event describes something the auto settler did with a promise.
+type Event struct {
+	Type      EventType
+	ChainID   int64
+	HermesID  common.Address
+	ChannelID common.Hash
+	// Unsettled is the amount that is not yet settled on chain.
+	Unsettled *big.Int
+	// TrackingID is the ID returned by the `Submitter`.
+	TrackingID string
+	Err        error
+}
+
+// AutoSettlerMetrics receives events for metric reporting.
+type AutoSettlerMetrics interface {
+	SettlementEvent(ev Event)
+}
+
+type autoSettlerMetricsNoop struct{}
+
+func (a *autoSettlerMetricsNoop) SettlementEvent(_ Event) {}
+
+// ErrGasTooExpensive is returned as the event error if a settlement is postponed due to gas prices.
+var ErrGasTooExpensive = errors.New("gas price is above the configured maximum")
+
+type promiseKey struct {
+	chainID   int64
+	hermesID  common.Address
+	channelID common.Hash
+}
+
+type fedPromise struct {
+	hermesID common.Address
+	promise  crypto.Promise
+}
+
+type submission struct {
+	key    promiseKey
+	amount *big.Int
+	at     time.Time
+}
+
+// AutoSettler keeps provider channels settled. Promises fed to it are settled
+// once their unsettled amount reaches the threshold and gas is cheap enough.
+type AutoSettler struct {
+	channels  ChannelReader
+	gs        GasStation
+	submitter Submitter
+	guard     *Guard
+	ledger    *MemoryLedger
+	cfg       AutoSettlerConfig
+
+	promises    map[promiseKey]fedPromise
+	submissions map[string]submission
+	settling    map[promiseKey]struct{}
+	mu          sync.Mutex
+	now         func() time.Time
+
+	listeners []func(Event)
+	logFn     func(error)
+	metrics   AutoSettlerMetrics
+
+	once sync.Once
+	stop chan struct{}
+}
+
+// NewAutoSettler returns a new auto settler.
+func NewAutoSettler(channels ChannelReader, gs GasStation, submitter Submitter, cfg AutoSettlerConfig) *AutoSettler {
+	if cfg.Speed == "" {
+		cfg.Speed = transaction.GasTrackerSpeedMedium
+	}
+	if cfg.Threshold == nil {
+		cfg.Threshold = new(big.Int)
+	}
+
+	ledger := NewMemoryLedger()
+	return &AutoSettler{
+		channels:  channels,
+		gs:        gs,
+		submitter: submitter,
+		guard:     NewGuard(channels, ledger),
+		ledger:    ledger,
+		cfg:       cfg,
+
+		promises:    make(map[promiseKey]fedPromise),
+		submissions: make(map[string]submission),
+		settling:    make(map[promiseKey]struct{}),
+		now:         time.Now,
+		logFn:       func(error) {},
+		metrics:     &autoSettlerMetricsNoop{},
+
+		stop: make(chan struct{}),
+	}
+}
+
+// AttachLogger allows the caller to attach an optional logger.
+//
+// This method is not thread safe and should be called before `Run`.
+func (a *AutoSettler) AttachLogger(fn func(err error)) {
+	a.logFn = fn
+}
+
+// AttachMetricsReporter allows the caller to attach a custom metrics reporter.
+//
+// This method is not thread safe and should be called before `Run`.
+func (a *AutoSettler) AttachMetricsReporter(m AutoSettlerMetrics) {
+	a.metrics = m
+}
+
+// OnEvent registers a listener for auto settler events.
+//
+// This method is not thread safe and should be called before `Run`.
+func (a *AutoSettler) OnEvent(fn func(Event)) {
+	a.listeners = append(a.listeners, fn)
+}
+
+// Feed gives a promise to the auto settler. Only the promise with
+// the highest amount is kept for every channel.
+func (a *AutoSettler) Feed(hermesID common.Address, promise crypto.Promise) {
+	if promise.Amount == nil {
+		return
+	}
+
+	key := promiseKey{chainID: promise.ChainID, hermesID: hermesID, channelID: common.BytesToHash(promise.ChannelID)}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if current, ok := a.promises[key]; ok && current.promise.Amount.Cmp(promise.Amount) >= 0 {
+		return
+	}
+	a.promises[key] = fedPromise{hermesID: hermesID, promise: promise}
+}
+
+// Pending returns the number of channels with promises which are not yet settled on chain.
+func (a *AutoSettler) Pending() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.promises)
+}
+
+// PendingPromise is a fed promise which is not yet settled.
+type PendingPromise struct {
+	HermesID common.Address `json:"hermesID"`
+	Promise  crypto.Promise `json:"promise"`
+}
+
+// PendingPromises returns the fed promises which are not yet settled.
+func (a *AutoSettler) PendingPromises() []PendingPromise {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	res := make([]PendingPromise, 0, len(a.promises))
+	for _, fp := range a.promises {
+		res = append(res, PendingPromise{HermesID: fp.hermesID, Promise: fp.promise})
+	}
+	return res
+}
+
+// SettlementFailed reports that the settlement with the given tracking ID will not land on chain,
+// e.g. its transaction was dropped or reverted. Its amount is rolled back from the ledger,
+// so the promise is submitted again.
+func (a *AutoSettler) SettlementFailed(trackingID string, err error) {
+	a.mu.Lock()
+	sub, ok := a.submissions[trackingID]
+	if ok {
+		a.rollback(trackingID, sub)
+	}
+	a.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	a.emit(Event{
+		Type:       EventSettlementFailed,
+		ChainID:    sub.key.chainID,
+		HermesID:   sub.key.hermesID,
+		ChannelID:  sub.key.channelID,
+		TrackingID: trackingID,
+		Err:        err,
+	})
+}
+
+// Ledger returns the ledger of settlements submitted by the auto settler.
+func (a *AutoSettler) Ledger() *MemoryLedger {
+	return a.ledger
+}
+
+// Run will spawn a goroutine which settles fed promises every interval.
+func (a *AutoSettler) Run() {
+	go func() {
+		for {
+			select {
+			case <-a.stop:
+				return
+			case <-time.After(a.cfg.Interval):
+				a.SettleAll()
+			}
+		}
+	}()
+}
+
+// Stop will stop the auto settler.
+func (a *AutoSettler) Stop() {
+	a.once.Do(func() {
+		close(a.stop)
+	})
+}
+
+// SettleAll checks every fed promise once and submits settlements where needed.
+func (a *AutoSettler) SettleAll() {
+	a.mu.Lock()
+	fed := make(map[promiseKey]fedPromise, len(a.promises))
+	for k, v := range a.promises {
+		fed[k] = v
+	}
+	a.mu.Unlock()
+
+	prices := make(map[int64]*gas.GasPrices)
+	for key, fp := range fed {
+		a.settle(key, fp, prices)
+	}
+}
+
+func (a *AutoSettler) settle(key promiseKey, fp fedPromise, prices map[int64]*gas.GasPrices) {
+	ev := Event{
+		ChainID:   key.chainID,
+		HermesID:  key.hermesID,
+		ChannelID: key.channelID,
+	}
+
+	// The channel is locked from reading it until the settlement is recorded,
+	// so a concurrent settle can not pass the guard with the same state.
+	if !a.lock(key) {
+		return
+	}
+	defer a.unlock(key)
+
+	ch, err := a.channels.GetProviderChannelByID(fp.hermesID, fp.promise.ChannelID)
+	if err != nil {
+		a.logFn(fmt.Errorf("failed to get provider channel %s: %w", key.channelID.Hex(), err))
+		return
+	}
+	settled := ch.Settled
+	if settled == nil {
+		settled = new(big.Int)
+	}
+
+	a.reconcile(key, settled)
+
+	ev.Unsettled = new(big.Int).Sub(fp.promise.Amount, settled)
+	if ev.Unsettled.Sign() <= 0 {
+		a.remove(key, fp.promise.Amount)
+		ev.Type = EventChannelSettled
+		a.emit(ev)
+		return
+	}
+	if ev.Unsettled.Cmp(a.cfg.Threshold) < 0 {
+		return
+	}
+
+	if err := a.checkGas(key.chainID, prices); err != nil {
+		ev.Type = EventSettlementPostponed
+		ev.Err = err
+		a.emit(ev)
+		return
+	}
+
+	if err := a.guard.check(fp.promise, ch); err != nil {
+		if !errors.Is(err, ErrDuplicateSettlement) {
+			a.logFn(err)
+		}
+		// A settlement of this amount was already submitted, wait for it to land.
+		return
+	}
+
+	id, err := a.submitter.SubmitSettlement(fp.hermesID, fp.promise)
+	if err != nil {
+		ev.Type = EventSettlementFailed
+		ev.Err = err
+		a.emit(ev)
+		return
+	}
+
+	a.mu.Lock()
+	a.submissions[id] = submission{key: key, amount: new(big.Int).Set(fp.promise.Amount), at: a.now()}
+	a.ledger.Record(key.chainID, key.channelID, fp.promise.Amount)
+	a.mu.Unlock()
+	ev.Type = EventSettlementSubmitted
+	ev.TrackingID = id
+	a.emit(ev)
+}
+
+func (a *AutoSettler) checkGas(chainID int64, cache map[int64]*gas.GasPrices) error {
+	max, ok := a.cfg.MaxGasPrice[chainID]
+	if !ok || max == nil {
+		return nil
+	}
+
+	prices, ok := cache[chainID]
+	if !ok {
+		var err error
+		prices, err = a.gs.GetGasPrices(chainID)
+		if err != nil {
+			return fmt.Errorf("failed to get gas prices: %w", err)
+		}
+		cache[chainID] = prices
+	}
+
+	total := gasPriceForSpeed(prices, a.cfg.Speed)
+	if total.Cmp(max) > 0 {
+		return fmt.Errorf("%w: %s > %s", ErrGasTooExpensive, total, max)
+	}
+	return nil
+}
+
+// gasPriceForSpeed returns the total price per gas (base fee and tip) for the given speed.
+func gasPriceForSpeed(prices *gas.GasPrices, speed transaction.GasTrackerSpeed) *big.Int {
+	var tip *big.Int
+	switch speed {
+	case transaction.GasTrackerSpeedSlow:
+		tip = prices.SafeLow
+	case transaction.GasTrackerSpeedFast:
+		tip = prices.Fast
+	default:
+		tip = prices.Average
+	}
+
+	total := new(big.Int)
+	if tip != nil {
+		total.Add(total, tip)
+	}
+	if prices.BaseFee != nil {
+		total.Add(total, prices.BaseFee)
+	}
+	return total
+}
+
+// lock marks the channel as being settled. False is returned if it already is.
+func (a *AutoSettler) lock(key promiseKey) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.settling[key]; ok {
+		return false
+	}
+	a.settling[key] = struct{}{}
+	return true
+}
+
+func (a *AutoSettler) unlock(key promiseKey) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.settling, key)
+}
+
+// remove drops the promise unless a higher one was fed in the meantime.
+func (a *AutoSettler) remove(key promiseKey, amount *big.Int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if current, ok := a.promises[key]; ok && current.promise.Amount.Cmp(amount) <= 0 {
+		delete(a.promises, key)
+	}
+}
+
+// reconcile forgets the submissions of the channel which are settled on chain
+// and rolls back the ones which are pending for longer than the pending timeout.
+func (a *AutoSettler) reconcile(key promiseKey, settled *big.Int) {
+	var lost []error
+	defer func() {
+		for _, err := range lost {
+			a.logFn(err)
+		}
+	}()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for id, sub := range a.submissions {
+		if sub.key != key {
+			continue
+		}
+		if sub.amount.Cmp(settled) <= 0 {
+			delete(a.submissions, id)
+			continue
+		}
+		if a.cfg.PendingTimeout > 0 && a.now().Sub(sub.at) > a.cfg.PendingTimeout {
+			lost = append(lost, fmt.Errorf("settlement %q of channel %s is not settled after %s, submitting again", id, key.channelID.Hex(), a.cfg.PendingTimeout))
+			a.rollback(id, sub)
+		}
+	}
+}
+
+// rollback removes the submission and restores the ledger to the highest pending submission of its channel.
+// It must be called with the mutex held.
+func (a *AutoSettler) rollback(id string, sub submission) {
+	delete(a.submissions, id)
+	a.ledger.Rollback(sub.key.chainID, sub.key.channelID, sub.amount)
+	for _, other := range a.submissions {
+		if other.key == sub.key {
+			a.ledger.Record(sub.key.chainID, sub.key.channelID, other.amount)
+		}
+	}
+}
+
+func (a *AutoSettler) emit(ev Event) {
+	a.metrics.SettlementEvent(ev)
+	for _, fn := range a.listeners {
+		fn(ev)
+	}
+}
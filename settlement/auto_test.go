@@ -0,0 +1,185 @@
+package settlement
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/client"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/mysteriumnetwork/payments/transaction/gas"
+)
+
+type settledChannels struct {
+	settled map[common.Hash]*big.Int
+	mu      sync.Mutex
+}
+
+func (s *settledChannels) GetProviderChannelByID(hermesID common.Address, channelID []byte) (client.ProviderChannel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return client.ProviderChannel{Settled: s.settled[common.BytesToHash(channelID)]}, nil
+}
+
+func (s *settledChannels) set(channelID common.Hash, amount int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settled[channelID] = big.NewInt(amount)
+}
+
+func TestAutoSettler(t *testing.T) {
+	hermes := common.HexToAddress("0x1")
+	channelID := common.HexToHash("0xabc")
+	promise := func(amount int64) crypto.Promise {
+		return crypto.Promise{ChannelID: channelID.Bytes(), ChainID: 137, Amount: big.NewInt(amount)}
+	}
+
+	setup := func(tip int64) (*AutoSettler, *settledChannels, *[]string, *[]Event) {
+		channels := &settledChannels{settled: map[common.Hash]*big.Int{channelID: big.NewInt(0)}}
+		stations := gas.MultichainStation{137: {gas.NewStaticStation(big.NewInt(tip), big.NewInt(10))}}
+
+		var submitted []string
+		submitter := SubmitterFunc(func(hermesID common.Address, p crypto.Promise) (string, error) {
+			submitted = append(submitted, p.Amount.String())
+			return "id-" + p.Amount.String(), nil
+		})
+
+		as := NewAutoSettler(channels, stations, submitter, AutoSettlerConfig{
+			Interval:    time.Millisecond,
+			Threshold:   big.NewInt(100),
+			MaxGasPrice: map[int64]*big.Int{137: big.NewInt(50)},
+		})
+		var events []Event
+		as.OnEvent(func(ev Event) { events = append(events, ev) })
+		return as, channels, &submitted, &events
+	}
+
+	t.Run("settles above threshold once", func(t *testing.T) {
+		as, channels, submitted, events := setup(10)
+
+		as.Feed(hermes, promise(50))
+		as.SettleAll()
+		assert.Empty(t, *submitted)
+
+		as.Feed(hermes, promise(150))
+		as.Feed(hermes, promise(120))
+		as.SettleAll()
+		as.SettleAll()
+		assert.Equal(t, []string{"150"}, *submitted)
+		assert.Equal(t, EventSettlementSubmitted, (*events)[0].Type)
+		assert.Equal(t, "id-150", (*events)[0].TrackingID)
+		assert.Equal(t, 1, as.Pending())
+
+		channels.set(channelID, 150)
+		as.SettleAll()
+		assert.Equal(t, 0, as.Pending())
+		assert.Equal(t, EventChannelSettled, (*events)[len(*events)-1].Type)
+	})
+
+	t.Run("resubmits failed settlement", func(t *testing.T) {
+		as, _, submitted, events := setup(10)
+
+		as.Feed(hermes, promise(150))
+		as.SettleAll()
+		as.SettleAll()
+		assert.Equal(t, []string{"150"}, *submitted)
+
+		as.SettlementFailed("id-150", errors.New("dropped"))
+		assert.Equal(t, EventSettlementFailed, (*events)[1].Type)
+		assert.Equal(t, "id-150", (*events)[1].TrackingID)
+
+		as.SettleAll()
+		assert.Equal(t, []string{"150", "150"}, *submitted)
+	})
+
+	t.Run("resubmits after the pending timeout", func(t *testing.T) {
+		as, channels, submitted, _ := setup(10)
+		now := time.Now()
+		as.now = func() time.Time { return now }
+		as.cfg.PendingTimeout = time.Minute
+
+		as.Feed(hermes, promise(150))
+		as.SettleAll()
+		now = now.Add(time.Minute)
+		as.SettleAll()
+		assert.Equal(t, []string{"150"}, *submitted)
+
+		now = now.Add(time.Second)
+		as.SettleAll()
+		assert.Equal(t, []string{"150", "150"}, *submitted)
+
+		channels.set(channelID, 150)
+		as.SettleAll()
+		assert.Equal(t, 0, as.Pending())
+		assert.Empty(t, as.submissions)
+	})
+
+	t.Run("postpones while gas is expensive", func(t *testing.T) {
+		as, _, submitted, events := setup(100)
+
+		as.Feed(hermes, promise(150))
+		as.SettleAll()
+		assert.Empty(t, *submitted)
+		assert.Equal(t, EventSettlementPostponed, (*events)[0].Type)
+		assert.True(t, errors.Is((*events)[0].Err, ErrGasTooExpensive))
+	})
+
+	t.Run("concurrent settles submit once", func(t *testing.T) {
+		channels := &settledChannels{settled: map[common.Hash]*big.Int{channelID: big.NewInt(0)}}
+		entered := make(chan struct{})
+		release := make(chan struct{})
+		var mu sync.Mutex
+		var submitted int
+		submitter := SubmitterFunc(func(hermesID common.Address, p crypto.Promise) (string, error) {
+			mu.Lock()
+			submitted++
+			mu.Unlock()
+			close(entered)
+			<-release
+			return "id", nil
+		})
+		as := NewAutoSettler(channels, gas.MultichainStation{}, submitter, AutoSettlerConfig{Threshold: big.NewInt(100)})
+		as.Feed(hermes, promise(150))
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			as.SettleAll()
+		}()
+		<-entered
+
+		as.SettleAll()
+		close(release)
+		<-done
+		as.SettleAll()
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, 1, submitted)
+	})
+
+	t.Run("runs in background", func(t *testing.T) {
+		as, _, _, _ := setup(10)
+		submittedCh := make(chan Event, 1)
+		as.OnEvent(func(ev Event) {
+			if ev.Type == EventSettlementSubmitted {
+				submittedCh <- ev
+			}
+		})
+		as.Feed(hermes, promise(150))
+		as.Run()
+		defer as.Stop()
+
+		select {
+		case ev := <-submittedCh:
+			assert.Equal(t, big.NewInt(150), ev.Unsettled)
+		case <-time.After(time.Second):
+			t.Fatal("settlement not submitted")
+		}
+	})
+}
@@ -0,0 +1,172 @@
+package settlement
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/mysteriumnetwork/payments/hermesfee"
+	"github.com/mysteriumnetwork/payments/transaction"
+	"github.com/mysteriumnetwork/payments/transaction/gas"
+)
+
+// DefaultSettleGasLimit is a conservative gas limit of a promise settlement.
+const DefaultSettleGasLimit uint64 = 300_000
+
+// ErrInvalidEarningsRequest is returned when an earnings request is missing required fields.
+var ErrInvalidEarningsRequest = errors.New("invalid earnings request")
+
+// EarningsRequest holds everything needed to estimate the earnings of a provider channel on a chain.
+type EarningsRequest struct {
+	ChainID  int64
+	HermesID common.Address
+	// Promise is the latest promise issued for the provider channel.
+	Promise crypto.Promise
+	// Settled is the amount already settled on chain for the channel.
+	Settled *big.Int
+
+	// Stake is the current stake of the channel and MaxStake the stake goal
+	// of the hermes. While the stake is below MaxStake, settling into stake
+	// is not charged a hermes fee. Both can be nil.
+	Stake    *big.Int
+	MaxStake *big.Int
+
+	Fee       hermesfee.Model
+	GasPrices *gas.GasPrices
+	// Speed selects the gas price used. Defaults to `transaction.GasTrackerSpeedMedium`.
+	Speed transaction.GasTrackerSpeed
+	// GasLimit of the settlement. Defaults to `DefaultSettleGasLimit`.
+	GasLimit uint64
+	// MystPerNative is the price of the native chain token in MYST, used to express the gas cost in MYST.
+	MystPerNative float64
+}
+
+// EarningsConfig decides when settling is economical.
+type EarningsConfig struct {
+	// MaxCostShare is the maximum share of the unsettled amount the gas cost may take, e.g. 0.1 for 10%.
+	MaxCostShare float64
+	// MinNet is the minimal amount that must be received after all fees. Can be nil.
+	MinNet *big.Int
+}
+
+// EarningsEstimate is the outcome of settling the provider channel now. All amounts are in MYST wei.
+type EarningsEstimate struct {
+	ChainID   int64
+	Unsettled *big.Int
+	HermesFee *big.Int
+	// GasCost is the estimated settlement transaction cost expressed in MYST.
+	GasCost *big.Int
+	// Net is what the provider receives if settling now.
+	Net *big.Int
+
+	// IntoStake is the amount that can be settled into stake without a hermes fee.
+	IntoStake *big.Int
+
+	Economical bool
+	Reason     string
+}
+
+// EstimateEarnings estimates the settleable earnings net of fees and recommends whether settling now is economical.
+func EstimateEarnings(req EarningsRequest, cfg EarningsConfig) (EarningsEstimate, error) {
+	if req.Promise.Amount == nil || req.GasPrices == nil {
+		return EarningsEstimate{}, fmt.Errorf("%w: promise amount and gas prices are required", ErrInvalidEarningsRequest)
+	}
+	if req.MystPerNative <= 0 {
+		return EarningsEstimate{}, fmt.Errorf("%w: native token price must be positive", ErrInvalidEarningsRequest)
+	}
+
+	settled := req.Settled
+	if settled == nil {
+		settled = new(big.Int)
+	}
+	gasLimit := req.GasLimit
+	if gasLimit == 0 {
+		gasLimit = DefaultSettleGasLimit
+	}
+
+	est := EarningsEstimate{
+		ChainID:   req.ChainID,
+		Unsettled: new(big.Int).Sub(req.Promise.Amount, settled),
+		IntoStake: new(big.Int),
+	}
+	if est.Unsettled.Sign() < 0 {
+		est.Unsettled.SetInt64(0)
+	}
+
+	nativeCost := new(big.Int).Mul(gasPriceForSpeed(req.GasPrices, req.Speed), new(big.Int).SetUint64(gasLimit))
+	est.GasCost, _ = new(big.Float).Mul(new(big.Float).SetInt(nativeCost), big.NewFloat(req.MystPerNative)).Int(nil)
+	est.HermesFee = req.Fee.Fee(est.Unsettled)
+	est.Net = new(big.Int).Sub(est.Unsettled, est.HermesFee)
+	est.Net.Sub(est.Net, est.GasCost)
+
+	if req.MaxStake != nil {
+		stake := req.Stake
+		if stake == nil {
+			stake = new(big.Int)
+		}
+		if missing := new(big.Int).Sub(req.MaxStake, stake); missing.Sign() > 0 {
+			est.IntoStake = minBig(missing, est.Unsettled)
+		}
+	}
+
+	switch {
+	case est.Unsettled.Sign() == 0:
+		est.Reason = "nothing to settle"
+	case est.Net.Sign() <= 0:
+		est.Reason = "fees exceed the unsettled amount"
+	case cfg.MinNet != nil && est.Net.Cmp(cfg.MinNet) < 0:
+		est.Reason = fmt.Sprintf("net amount %s is below the minimum %s", est.Net, cfg.MinNet)
+	case cfg.MaxCostShare > 0 && costShare(est.GasCost, est.Unsettled) > cfg.MaxCostShare:
+		est.Reason = fmt.Sprintf("gas cost is more than %.2f%% of the unsettled amount", cfg.MaxCostShare*100)
+	default:
+		est.Economical = true
+	}
+
+	return est, nil
+}
+
+// EstimateEarningsPerChain estimates earnings for every request returning the estimates keyed by chain ID.
+// Requests on the same chain are summed.
+func EstimateEarningsPerChain(reqs []EarningsRequest, cfg EarningsConfig) (map[int64]EarningsEstimate, error) {
+	res := make(map[int64]EarningsEstimate)
+	for _, req := range reqs {
+		est, err := EstimateEarnings(req, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("chain %d hermes %s: %w", req.ChainID, req.HermesID.Hex(), err)
+		}
+
+		prev, ok := res[req.ChainID]
+		if !ok {
+			res[req.ChainID] = est
+			continue
+		}
+
+		// Each channel is settled separately, so it is economical if any of them is.
+		prev.Unsettled.Add(prev.Unsettled, est.Unsettled)
+		prev.HermesFee.Add(prev.HermesFee, est.HermesFee)
+		prev.GasCost.Add(prev.GasCost, est.GasCost)
+		prev.Net.Add(prev.Net, est.Net)
+		prev.IntoStake.Add(prev.IntoStake, est.IntoStake)
+		if est.Economical {
+			prev.Economical = true
+			prev.Reason = ""
+		}
+		res[req.ChainID] = prev
+	}
+	return res, nil
+}
+
+func costShare(cost, amount *big.Int) float64 {
+	share, _ := new(big.Float).Quo(new(big.Float).SetInt(cost), new(big.Float).SetInt(amount)).Float64()
+	return share
+}
+
+func minBig(a, b *big.Int) *big.Int {
+	if a.Cmp(b) < 0 {
+		return new(big.Int).Set(a)
+	}
+	return new(big.Int).Set(b)
+}
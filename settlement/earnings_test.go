@@ -0,0 +1,84 @@
+package settlement
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/mysteriumnetwork/payments/hermesfee"
+	"github.com/mysteriumnetwork/payments/transaction/gas"
+)
+
+func TestEstimateEarnings(t *testing.T) {
+	req := func(amount int64) EarningsRequest {
+		return EarningsRequest{
+			ChainID:       137,
+			Promise:       crypto.Promise{Amount: big.NewInt(amount)},
+			Settled:       big.NewInt(1000),
+			Fee:           hermesfee.ContractModel(2000, 0),
+			GasPrices:     &gas.GasPrices{Average: big.NewInt(1), BaseFee: big.NewInt(1)},
+			GasLimit:      100,
+			MystPerNative: 2,
+		}
+	}
+
+	t.Run("economical", func(t *testing.T) {
+		est, err := EstimateEarnings(req(11000), EarningsConfig{MaxCostShare: 0.1})
+		assert.NoError(t, err)
+		assert.Equal(t, "10000", est.Unsettled.String())
+		assert.Equal(t, "2000", est.HermesFee.String())
+		assert.Equal(t, "400", est.GasCost.String())
+		assert.Equal(t, "7600", est.Net.String())
+		assert.True(t, est.Economical)
+	})
+
+	t.Run("gas too expensive", func(t *testing.T) {
+		est, err := EstimateEarnings(req(3000), EarningsConfig{MaxCostShare: 0.1})
+		assert.NoError(t, err)
+		assert.False(t, est.Economical)
+		assert.Contains(t, est.Reason, "gas cost")
+	})
+
+	t.Run("below minimum", func(t *testing.T) {
+		est, err := EstimateEarnings(req(3000), EarningsConfig{MinNet: big.NewInt(5000)})
+		assert.NoError(t, err)
+		assert.False(t, est.Economical)
+		assert.Contains(t, est.Reason, "below the minimum")
+	})
+
+	t.Run("nothing to settle", func(t *testing.T) {
+		est, err := EstimateEarnings(req(500), EarningsConfig{})
+		assert.NoError(t, err)
+		assert.Equal(t, "0", est.Unsettled.String())
+		assert.Equal(t, "nothing to settle", est.Reason)
+	})
+
+	t.Run("into stake", func(t *testing.T) {
+		r := req(11000)
+		r.Stake = big.NewInt(5000)
+		r.MaxStake = big.NewInt(8000)
+		est, err := EstimateEarnings(r, EarningsConfig{})
+		assert.NoError(t, err)
+		assert.Equal(t, "3000", est.IntoStake.String())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		r := req(11000)
+		r.MystPerNative = 0
+		_, err := EstimateEarnings(r, EarningsConfig{})
+		assert.ErrorIs(t, err, ErrInvalidEarningsRequest)
+	})
+
+	t.Run("per chain", func(t *testing.T) {
+		other := req(11000)
+		other.ChainID = 1
+		res, err := EstimateEarningsPerChain([]EarningsRequest{req(11000), req(3000), other}, EarningsConfig{MaxCostShare: 0.1})
+		assert.NoError(t, err)
+		assert.Len(t, res, 2)
+		assert.Equal(t, "12000", res[137].Unsettled.String())
+		assert.True(t, res[137].Economical)
+		assert.Equal(t, "7600", res[1].Net.String())
+	})
+}
@@ -0,0 +1,131 @@
+// Package settlement holds components which decide when and how promises are settled.
+package settlement
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/mysteriumnetwork/payments/client"
+	"github.com/mysteriumnetwork/payments/crypto"
+)
+
+// ErrDuplicateSettlement is matched by `DuplicateSettlementError`.
+var ErrDuplicateSettlement = errors.New("duplicate settlement")
+
+// DuplicateSettlementError is returned if settling a promise would re-settle an already settled amount.
+type DuplicateSettlementError struct {
+	ChainID   int64
+	ChannelID common.Hash
+	// PromiseAmount is the cumulative amount of the promise that was about to be settled.
+	PromiseAmount *big.Int
+	// OnChainSettled is the amount already settled according to the hermes contract.
+	OnChainSettled *big.Int
+	// LedgerSettled is the amount already settled or being settled according to the local ledger.
+	// Nil if no ledger is used.
+	LedgerSettled *big.Int
+	// Reason explains the discrepancy.
+	Reason string
+}
+
+func (e *DuplicateSettlementError) Error() string {
+	return fmt.Sprintf("duplicate settlement of channel %s on chain %d: %s", e.ChannelID.Hex(), e.ChainID, e.Reason)
+}
+
+// Is allows matching the error with `ErrDuplicateSettlement`.
+func (e *DuplicateSettlementError) Is(target error) bool {
+	return target == ErrDuplicateSettlement
+}
+
+// ChannelReader reads provider channels from the hermes contract.
+type ChannelReader interface {
+	GetProviderChannelByID(hermesID common.Address, channelID []byte) (client.ProviderChannel, error)
+}
+
+// Ledger is a local record of settlements.
+type Ledger interface {
+	// SettledAmount returns the cumulative amount settled or being settled for the channel.
+	// Nil or zero is returned if nothing was recorded.
+	SettledAmount(chainID int64, channelID common.Hash) (*big.Int, error)
+}
+
+// Guard rejects settlements of promises which were already settled.
+type Guard struct {
+	channels ChannelReader
+	ledger   Ledger
+}
+
+// NewGuard returns a new duplicate settlement guard. The ledger is optional.
+func NewGuard(channels ChannelReader, ledger Ledger) *Guard {
+	return &Guard{
+		channels: channels,
+		ledger:   ledger,
+	}
+}
+
+// Check verifies that settling the given promise would settle a new amount.
+// A `*DuplicateSettlementError` is returned otherwise.
+func (g *Guard) Check(hermesID common.Address, promise crypto.Promise) error {
+	if promise.Amount == nil {
+		return errors.New("promise amount is not set")
+	}
+
+	ch, err := g.channels.GetProviderChannelByID(hermesID, promise.ChannelID)
+	if err != nil {
+		return fmt.Errorf("failed to get provider channel %s: %w", common.BytesToHash(promise.ChannelID).Hex(), err)
+	}
+	return g.check(promise, ch)
+}
+
+// check verifies the promise against an already read provider channel.
+func (g *Guard) check(promise crypto.Promise, ch client.ProviderChannel) error {
+	if promise.Amount == nil {
+		return errors.New("promise amount is not set")
+	}
+
+	channelID := common.BytesToHash(promise.ChannelID)
+	onChain := ch.Settled
+	if onChain == nil {
+		onChain = new(big.Int)
+	}
+
+	var ledger *big.Int
+	if g.ledger != nil {
+		var err error
+		ledger, err = g.ledger.SettledAmount(promise.ChainID, channelID)
+		if err != nil {
+			return fmt.Errorf("failed to get settled amount from ledger for channel %s: %w", channelID.Hex(), err)
+		}
+		if ledger == nil {
+			ledger = new(big.Int)
+		}
+	}
+
+	dupErr := &DuplicateSettlementError{
+		ChainID:        promise.ChainID,
+		ChannelID:      channelID,
+		PromiseAmount:  new(big.Int).Set(promise.Amount),
+		OnChainSettled: new(big.Int).Set(onChain),
+		LedgerSettled:  ledger,
+	}
+
+	if promise.Amount.Cmp(onChain) <= 0 {
+		dupErr.Reason = fmt.Sprintf("promise amount %s is not higher than the amount %s already settled on chain", promise.Amount, onChain)
+		if ledger != nil && ledger.Cmp(onChain) < 0 {
+			dupErr.Reason += fmt.Sprintf(", the ledger is behind with %s settled", ledger)
+		}
+		return dupErr
+	}
+
+	if ledger != nil && promise.Amount.Cmp(ledger) <= 0 {
+		dupErr.Reason = fmt.Sprintf(
+			"promise amount %s is not higher than the amount %s recorded in the ledger, only %s is settled on chain so a settlement is likely still pending",
+			promise.Amount, ledger, onChain,
+		)
+		return dupErr
+	}
+
+	return nil
+}
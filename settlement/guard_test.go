@@ -0,0 +1,75 @@
+package settlement
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/client"
+	"github.com/mysteriumnetwork/payments/crypto"
+)
+
+type channelReaderMock struct {
+	settled *big.Int
+	err     error
+}
+
+func (m *channelReaderMock) GetProviderChannelByID(hermesID common.Address, channelID []byte) (client.ProviderChannel, error) {
+	return client.ProviderChannel{Settled: m.settled}, m.err
+}
+
+func TestGuard(t *testing.T) {
+	hermes := common.HexToAddress("0x1")
+	channelID := common.HexToHash("0xabc")
+	promise := func(amount int64) crypto.Promise {
+		return crypto.Promise{ChannelID: channelID.Bytes(), ChainID: 137, Amount: big.NewInt(amount)}
+	}
+
+	t.Run("allows new amount", func(t *testing.T) {
+		ledger := NewMemoryLedger()
+		ledger.Record(137, channelID, big.NewInt(10))
+		g := NewGuard(&channelReaderMock{settled: big.NewInt(10)}, ledger)
+		assert.NoError(t, g.Check(hermes, promise(11)))
+	})
+
+	t.Run("rejects amount settled on chain", func(t *testing.T) {
+		g := NewGuard(&channelReaderMock{settled: big.NewInt(10)}, nil)
+		err := g.Check(hermes, promise(10))
+		assert.ErrorIs(t, err, ErrDuplicateSettlement)
+
+		var dupErr *DuplicateSettlementError
+		assert.True(t, errors.As(err, &dupErr))
+		assert.Equal(t, big.NewInt(10), dupErr.OnChainSettled)
+		assert.Nil(t, dupErr.LedgerSettled)
+		assert.Contains(t, dupErr.Error(), "already settled on chain")
+	})
+
+	t.Run("rejects amount pending in ledger", func(t *testing.T) {
+		ledger := NewMemoryLedger()
+		ledger.Record(137, channelID, big.NewInt(20))
+		g := NewGuard(&channelReaderMock{settled: big.NewInt(10)}, ledger)
+
+		err := g.Check(hermes, promise(15))
+		var dupErr *DuplicateSettlementError
+		assert.True(t, errors.As(err, &dupErr))
+		assert.Equal(t, big.NewInt(20), dupErr.LedgerSettled)
+		assert.Contains(t, dupErr.Reason, "likely still pending")
+	})
+
+	t.Run("explains ledger behind chain", func(t *testing.T) {
+		g := NewGuard(&channelReaderMock{settled: big.NewInt(10)}, NewMemoryLedger())
+		err := g.Check(hermes, promise(5))
+		assert.ErrorIs(t, err, ErrDuplicateSettlement)
+		assert.Contains(t, err.Error(), "the ledger is behind with 0 settled")
+	})
+
+	t.Run("channel read error", func(t *testing.T) {
+		g := NewGuard(&channelReaderMock{err: errors.New("rpc down")}, nil)
+		err := g.Check(hermes, promise(5))
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrDuplicateSettlement)
+	})
+}
@@ -0,0 +1,82 @@
+package settlement
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type ledgerKey struct {
+	chainID   int64
+	channelID common.Hash
+}
+
+// MemoryLedger is an in memory `Ledger`.
+type MemoryLedger struct {
+	settled map[ledgerKey]*big.Int
+	mu      sync.RWMutex
+}
+
+// NewMemoryLedger returns a new in memory ledger.
+func NewMemoryLedger() *MemoryLedger {
+	return &MemoryLedger{
+		settled: make(map[ledgerKey]*big.Int),
+	}
+}
+
+// Record records the cumulative amount settled for the channel.
+// Lower amounts than the one already recorded are ignored.
+func (m *MemoryLedger) Record(chainID int64, channelID common.Hash, amount *big.Int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := ledgerKey{chainID: chainID, channelID: channelID}
+	if current, ok := m.settled[key]; ok && current.Cmp(amount) >= 0 {
+		return
+	}
+	m.settled[key] = new(big.Int).Set(amount)
+}
+
+// Rollback removes the amount recorded for the channel if it is the given one,
+// e.g. when its settlement failed. Higher amounts recorded since are kept.
+func (m *MemoryLedger) Rollback(chainID int64, channelID common.Hash, amount *big.Int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := ledgerKey{chainID: chainID, channelID: channelID}
+	if current, ok := m.settled[key]; ok && current.Cmp(amount) == 0 {
+		delete(m.settled, key)
+	}
+}
+
+// LedgerEntry is a single recorded channel of the ledger.
+type LedgerEntry struct {
+	ChainID   int64       `json:"chainID"`
+	ChannelID common.Hash `json:"channelID"`
+	Settled   *big.Int    `json:"settled"`
+}
+
+// Entries returns all of the recorded channels.
+func (m *MemoryLedger) Entries() []LedgerEntry {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	res := make([]LedgerEntry, 0, len(m.settled))
+	for k, v := range m.settled {
+		res = append(res, LedgerEntry{ChainID: k.chainID, ChannelID: k.channelID, Settled: new(big.Int).Set(v)})
+	}
+	return res
+}
+
+// SettledAmount returns the recorded cumulative amount settled for the channel.
+func (m *MemoryLedger) SettledAmount(chainID int64, channelID common.Hash) (*big.Int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	amount, ok := m.settled[ledgerKey{chainID: chainID, channelID: channelID}]
+	if !ok {
+		return new(big.Int), nil
+	}
+	return new(big.Int).Set(amount), nil
+}
@@ -0,0 +1,85 @@
+package settlement
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/mysteriumnetwork/payments/crypto/signatures"
+)
+
+// ErrInvalidPartialAmount is returned when a partial amount is not positive or exceeds the unsettled amount.
+var ErrInvalidPartialAmount = errors.New("invalid partial settlement amount")
+
+// IssuePartialPromise creates an intermediate promise which settles only the given part of
+// the unsettled amount of the full promise.
+//
+// Hermes contracts settle the whole cumulative amount of a promise, so partial settlements
+// are done by settling an intermediate promise for settled+amount instead. The promise has
+// to be signed by the hermes operator and is returned with a fresh preimage so it can be
+// settled right away. The full promise stays valid for the rest of the amount.
+func IssuePartialPromise(full crypto.Promise, settled, amount, fee *big.Int, ks signatures.HashSigner, operator common.Address) (*crypto.Promise, error) {
+	if settled == nil {
+		settled = new(big.Int)
+	}
+	if amount == nil || amount.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: amount must be positive", ErrInvalidPartialAmount)
+	}
+
+	cumulative := new(big.Int).Add(settled, amount)
+	if cumulative.Cmp(full.Amount) > 0 {
+		return nil, fmt.Errorf("%w: %s settled plus %s exceeds the promise amount %s", ErrInvalidPartialAmount, settled, amount, full.Amount)
+	}
+	if fee == nil {
+		fee = new(big.Int)
+	}
+
+	r := make([]byte, 32)
+	if _, err := rand.Read(r); err != nil {
+		return nil, fmt.Errorf("failed to generate preimage: %w", err)
+	}
+
+	p := crypto.Promise{
+		ChannelID: append([]byte(nil), full.ChannelID...),
+		ChainID:   full.ChainID,
+		Amount:    cumulative,
+		Fee:       new(big.Int).Set(fee),
+		Hashlock:  ethcrypto.Keccak256(r),
+		R:         r,
+	}
+
+	message, err := p.EncodeMessage()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode partial promise: %w", err)
+	}
+	sig, err := signatures.SignMessage(ks, operator, message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign partial promise: %w", err)
+	}
+	p.Signature = sig
+	return &p, nil
+}
+
+// ValidatePartialPromise checks that the partial promise belongs to the same channel as the full
+// promise, is signed by the hermes operator and settles a positive part of the unsettled amount.
+func ValidatePartialPromise(full, partial crypto.Promise, settled *big.Int, operator common.Address) error {
+	if settled == nil {
+		settled = new(big.Int)
+	}
+	if partial.ChainID != full.ChainID || !bytes.Equal(partial.ChannelID, full.ChannelID) {
+		return fmt.Errorf("%w: partial promise is for a different channel", ErrInvalidPartialAmount)
+	}
+	if partial.Amount == nil || partial.Amount.Cmp(settled) <= 0 || partial.Amount.Cmp(full.Amount) > 0 {
+		return fmt.Errorf("%w: partial promise amount must be between %s and %s", ErrInvalidPartialAmount, settled, full.Amount)
+	}
+	if !partial.IsPromiseValid(operator) {
+		return errors.New("partial promise is not signed by the hermes operator")
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package settlement
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/crypto"
+)
+
+type keySigner func(hash []byte) ([]byte, error)
+
+func (k keySigner) SignHash(_ accounts.Account, hash []byte) ([]byte, error) {
+	return k(hash)
+}
+
+func TestPartialPromise(t *testing.T) {
+	key, err := ethcrypto.GenerateKey()
+	assert.NoError(t, err)
+	operator := ethcrypto.PubkeyToAddress(key.PublicKey)
+	ks := keySigner(func(hash []byte) ([]byte, error) { return ethcrypto.Sign(hash, key) })
+
+	full := crypto.Promise{ChannelID: common.HexToHash("0xabc").Bytes(), ChainID: 137, Amount: big.NewInt(100), Fee: big.NewInt(1)}
+
+	p, err := IssuePartialPromise(full, big.NewInt(20), big.NewInt(50), nil, ks, operator)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(70), p.Amount)
+	assert.Equal(t, ethcrypto.Keccak256(p.R), p.Hashlock)
+	assert.NoError(t, ValidatePartialPromise(full, *p, big.NewInt(20), operator))
+
+	t.Run("invalid amounts", func(t *testing.T) {
+		_, err := IssuePartialPromise(full, big.NewInt(20), big.NewInt(81), nil, ks, operator)
+		assert.ErrorIs(t, err, ErrInvalidPartialAmount)
+		_, err = IssuePartialPromise(full, nil, big.NewInt(0), nil, ks, operator)
+		assert.ErrorIs(t, err, ErrInvalidPartialAmount)
+
+		assert.ErrorIs(t, ValidatePartialPromise(full, *p, big.NewInt(70), operator), ErrInvalidPartialAmount)
+	})
+
+	t.Run("wrong signer", func(t *testing.T) {
+		assert.Error(t, ValidatePartialPromise(full, *p, nil, common.HexToAddress("0x1")))
+	})
+}
@@ -0,0 +1,234 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/mysteriumnetwork/payments/transaction"
+)
+
+// Outcome is the outcome of an audited send.
+type Outcome string
+
+const (
+	// OutcomeSigned means the transaction was signed and handed to the network.
+	OutcomeSigned Outcome = "signed"
+	// OutcomeFailed means the courier failed to sign or send the transaction.
+	OutcomeFailed Outcome = "failed"
+	// OutcomeDelivered means the depot saw the transaction nonce confirmed on chain.
+	OutcomeDelivered Outcome = "delivered"
+)
+
+// ErrTampered is returned if a chain of entries does not verify.
+var ErrTampered = errors.New("audit log was tampered with")
+
+// Entry is a single append-only audit log entry. Every entry holds the hash
+// of the previous one, so modifying, removing or reordering entries breaks the chain.
+type Entry struct {
+	Seq  uint64    `json:"seq"`
+	Time time.Time `json:"time"`
+
+	// Requester is the delivery type, every component enqueues its own types.
+	Requester   transaction.DeliverableType `json:"requester"`
+	DeliveryID  string                      `json:"deliveryID"`
+	ChainID     int64                       `json:"chainID"`
+	Sender      common.Address              `json:"sender"`
+	Nonce       uint64                      `json:"nonce"`
+	PayloadHash common.Hash                 `json:"payloadHash"`
+
+	TxHash      common.Hash     `json:"txHash"`
+	Destination *common.Address `json:"destination,omitempty"`
+	Value       *big.Int        `json:"value,omitempty"`
+
+	Outcome Outcome `json:"outcome"`
+	Error   string  `json:"error,omitempty"`
+
+	PrevHash common.Hash `json:"prevHash"`
+	Hash     common.Hash `json:"hash"`
+}
+
+// ComputeHash returns the hash of the entry, which covers every field except `Hash` itself.
+func (e Entry) ComputeHash() (common.Hash, error) {
+	e.Hash = common.Hash{}
+	blob, err := json.Marshal(e)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(blob), nil
+}
+
+// Store persists audit log entries. Entries are only ever appended.
+type Store interface {
+	Append(e Entry) error
+	Entries() ([]Entry, error)
+}
+
+// MemoryStore is an in memory audit log store.
+type MemoryStore struct {
+	entries []Entry
+	mu      sync.Mutex
+}
+
+// NewMemoryStore returns a new in memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Append appends the entry.
+func (m *MemoryStore) Append(e Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = append(m.entries, e)
+	return nil
+}
+
+// Entries returns all of the entries in the order they were appended.
+func (m *MemoryStore) Entries() ([]Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Entry(nil), m.entries...), nil
+}
+
+// Log is a hash chained append-only log of sends.
+type Log struct {
+	store Store
+
+	seq  uint64
+	last common.Hash
+	mu   sync.Mutex
+
+	now func() time.Time
+}
+
+// NewLog returns a new audit log continuing the chain of entries already in the store.
+func NewLog(store Store) (*Log, error) {
+	entries, err := store.Entries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load audit log: %w", err)
+	}
+	if err := Verify(entries); err != nil {
+		return nil, err
+	}
+
+	l := &Log{store: store, now: time.Now}
+	if len(entries) > 0 {
+		last := entries[len(entries)-1]
+		l.seq = last.Seq + 1
+		l.last = last.Hash
+	}
+	return l, nil
+}
+
+// Append chains the entry to the log and stores it. Sequence, time and hashes are set by the log.
+func (l *Log) Append(e Entry) (Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e.Seq = l.seq
+	e.Time = l.now().UTC()
+	e.PrevHash = l.last
+
+	hash, err := e.ComputeHash()
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to hash audit entry: %w", err)
+	}
+	e.Hash = hash
+
+	if err := l.store.Append(e); err != nil {
+		return Entry{}, fmt.Errorf("failed to store audit entry: %w", err)
+	}
+	l.seq++
+	l.last = hash
+	return e, nil
+}
+
+// Record appends an entry describing the delivery and the transaction sent for it, if any.
+func (l *Log) Record(td transaction.Delivery, tx *types.Transaction, outcome Outcome, sendErr error) (Entry, error) {
+	e := Entry{
+		Requester:   td.Type,
+		DeliveryID:  td.UniqueID,
+		ChainID:     td.ChainID,
+		Sender:      td.Sender,
+		Nonce:       td.Nonce,
+		PayloadHash: crypto.Keccak256Hash(td.ShipmentData),
+		Outcome:     outcome,
+	}
+	if tx != nil {
+		e.TxHash = tx.Hash()
+		e.Destination = tx.To()
+		e.Value = tx.Value()
+	}
+	if sendErr != nil {
+		e.Error = sendErr.Error()
+	}
+	return l.Append(e)
+}
+
+// Export verifies the log and writes every entry as a JSON line.
+func (l *Log) Export(w io.Writer) error {
+	entries, err := l.store.Entries()
+	if err != nil {
+		return fmt.Errorf("failed to load audit log: %w", err)
+	}
+	if err := Verify(entries); err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("failed to export audit entry %d: %w", e.Seq, err)
+		}
+	}
+	return nil
+}
+
+// Read reads entries exported using `Export`. The entries are not verified.
+func Read(r io.Reader) ([]Entry, error) {
+	res := make([]Entry, 0)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("failed to read audit entry %d: %w", len(res), err)
+		}
+		res = append(res, e)
+	}
+	return res, scanner.Err()
+}
+
+// Verify checks that the entries form an unbroken hash chain starting at the first entry of the log.
+func Verify(entries []Entry) error {
+	var prev common.Hash
+	for i, e := range entries {
+		if e.Seq != uint64(i) {
+			return fmt.Errorf("%w: entry %d has sequence %d", ErrTampered, i, e.Seq)
+		}
+		if e.PrevHash != prev {
+			return fmt.Errorf("%w: entry %d does not follow the previous entry", ErrTampered, i)
+		}
+		hash, err := e.ComputeHash()
+		if err != nil {
+			return fmt.Errorf("failed to hash audit entry %d: %w", i, err)
+		}
+		if hash != e.Hash {
+			return fmt.Errorf("%w: entry %d hash mismatch", ErrTampered, i)
+		}
+		prev = e.Hash
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package audit
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/transaction"
+)
+
+type courierMock struct {
+	tx  *types.Transaction
+	err error
+}
+
+func (c *courierMock) DeliverTransaction(transaction.Delivery) (*types.Transaction, error) {
+	return c.tx, c.err
+}
+
+func (c *courierMock) CanDeliver(transaction.DeliverableType) bool {
+	return true
+}
+
+func TestLog(t *testing.T) {
+	to := common.HexToAddress("0x2")
+	tx := types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(137), Nonce: 3, To: &to, Value: big.NewInt(100)})
+	td := transaction.Delivery{
+		UniqueID:     "0x1|3|137",
+		Sender:       common.HexToAddress("0x1"),
+		Nonce:        3,
+		ChainID:      137,
+		Type:         "myst-transfer",
+		ShipmentData: []byte(`{"amount":100}`),
+	}
+
+	store := NewMemoryStore()
+	log, err := NewLog(store)
+	assert.NoError(t, err)
+
+	courier := NewCourier(&courierMock{tx: tx}, log)
+	_, err = courier.DeliverTransaction(td)
+	assert.NoError(t, err)
+
+	_, err = NewCourier(&courierMock{err: errors.New("nonce too low")}, log).DeliverTransaction(td)
+	assert.Error(t, err)
+
+	raw, err := tx.MarshalJSON()
+	assert.NoError(t, err)
+	td.SentTransaction = raw
+	NewMetricsExporter(nil, log).DeliveryReceived(td)
+
+	entries, err := store.Entries()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+	assert.NoError(t, Verify(entries))
+
+	t.Run("records sends", func(t *testing.T) {
+		assert.Equal(t, OutcomeSigned, entries[0].Outcome)
+		assert.Equal(t, tx.Hash(), entries[0].TxHash)
+		assert.Equal(t, &to, entries[0].Destination)
+		assert.Equal(t, "100", entries[0].Value.String())
+		assert.Equal(t, transaction.DeliverableType("myst-transfer"), entries[0].Requester)
+		assert.NotEqual(t, common.Hash{}, entries[0].PayloadHash)
+
+		assert.Equal(t, OutcomeFailed, entries[1].Outcome)
+		assert.Equal(t, "nonce too low", entries[1].Error)
+		assert.Nil(t, entries[1].Destination)
+		assert.Equal(t, entries[0].Hash, entries[1].PrevHash)
+
+		assert.Equal(t, OutcomeDelivered, entries[2].Outcome)
+		assert.Equal(t, tx.Hash(), entries[2].TxHash)
+	})
+
+	t.Run("export round trip", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, log.Export(&buf))
+
+		read, err := Read(&buf)
+		assert.NoError(t, err)
+		assert.NoError(t, Verify(read))
+		assert.Len(t, read, 3)
+	})
+
+	t.Run("detects tampering", func(t *testing.T) {
+		modified := append([]Entry(nil), entries...)
+		modified[1].Error = ""
+		assert.ErrorIs(t, Verify(modified), ErrTampered)
+
+		assert.ErrorIs(t, Verify([]Entry{entries[0], entries[2]}), ErrTampered)
+		assert.ErrorIs(t, Verify(entries[1:]), ErrTampered)
+	})
+
+	t.Run("continues existing chain", func(t *testing.T) {
+		reopened, err := NewLog(store)
+		assert.NoError(t, err)
+		e, err := reopened.Append(Entry{Requester: "network-transfer"})
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(3), e.Seq)
+		assert.Equal(t, entries[2].Hash, e.PrevHash)
+
+		broken := NewMemoryStore()
+		assert.NoError(t, broken.Append(entries[1]))
+		_, err = NewLog(broken)
+		assert.ErrorIs(t, err, ErrTampered)
+	})
+}
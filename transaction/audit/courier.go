@@ -0,0 +1,103 @@
+package audit
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/mysteriumnetwork/payments/transaction"
+)
+
+// Courier wraps a `transaction.DeliveryCourier`, recording every transaction it signs and every failed attempt.
+type Courier struct {
+	next  transaction.DeliveryCourier
+	log   *Log
+	logFn func(error)
+}
+
+// NewCourier returns a courier which audits the given courier.
+func NewCourier(next transaction.DeliveryCourier, log *Log) *Courier {
+	return &Courier{
+		next:  next,
+		log:   log,
+		logFn: func(error) {},
+	}
+}
+
+// AttachLogger allows the caller to attach an optional logger
+// which will receive errors that happen while recording entries.
+func (c *Courier) AttachLogger(fn func(err error)) {
+	c.logFn = fn
+}
+
+// DeliverTransaction delivers the transaction using the wrapped courier and records the outcome.
+func (c *Courier) DeliverTransaction(td transaction.Delivery) (*types.Transaction, error) {
+	tx, err := c.next.DeliverTransaction(td)
+	outcome := OutcomeSigned
+	if err != nil {
+		outcome = OutcomeFailed
+	}
+	if _, auditErr := c.log.Record(td, tx, outcome, err); auditErr != nil {
+		c.logFn(fmt.Errorf("failed to audit delivery %q: %w", td.UniqueID, auditErr))
+	}
+	return tx, err
+}
+
+// CanDeliver calls the wrapped courier.
+func (c *Courier) CanDeliver(typ transaction.DeliverableType) bool {
+	return c.next.CanDeliver(typ)
+}
+
+// MetricsExporter wraps a `transaction.DepotMetricsExporter`, recording confirmed deliveries.
+type MetricsExporter struct {
+	next  transaction.DepotMetricsExporter
+	log   *Log
+	logFn func(error)
+}
+
+// NewMetricsExporter returns a depot metrics exporter which audits deliveries and forwards every event to next, if given.
+func NewMetricsExporter(next transaction.DepotMetricsExporter, log *Log) *MetricsExporter {
+	return &MetricsExporter{
+		next:  next,
+		log:   log,
+		logFn: func(error) {},
+	}
+}
+
+// AttachLogger allows the caller to attach an optional logger
+// which will receive errors that happen while recording entries.
+func (m *MetricsExporter) AttachLogger(fn func(err error)) {
+	m.logFn = fn
+}
+
+// DeliveryReceived records the delivery as delivered.
+func (m *MetricsExporter) DeliveryReceived(td transaction.Delivery) {
+	tx, _ := td.GetLastTransaction()
+	if _, err := m.log.Record(td, tx, OutcomeDelivered, nil); err != nil {
+		m.logFn(fmt.Errorf("failed to audit delivery %q: %w", td.UniqueID, err))
+	}
+	if m.next != nil {
+		m.next.DeliveryReceived(td)
+	}
+}
+
+// DeliveryQueued forwards the event.
+func (m *MetricsExporter) DeliveryQueued(td transaction.Delivery) {
+	if m.next != nil {
+		m.next.DeliveryQueued(td)
+	}
+}
+
+// DeliverySent forwards the event.
+func (m *MetricsExporter) DeliverySent(td transaction.Delivery) {
+	if m.next != nil {
+		m.next.DeliverySent(td)
+	}
+}
+
+// DeliveryFailed forwards the event if the wrapped reporter is a `transaction.DepotFailureExporter`.
+func (m *MetricsExporter) DeliveryFailed(td transaction.Delivery, err error) {
+	if fe, ok := m.next.(transaction.DepotFailureExporter); ok {
+		fe.DeliveryFailed(td, err)
+	}
+}
@@ -0,0 +1,102 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// MinCancelBumpPercent is the minimum fee bump nodes accept to replace a pending transaction.
+const MinCancelBumpPercent = 10
+
+// cancelGasLimit is the gas used by a plain value transfer.
+const cancelGasLimit = 21000
+
+// ErrTransactionNotPending is returned when trying to cancel a transaction which is not pending.
+var ErrTransactionNotPending = errors.New("transaction is not pending")
+
+// CancellerClient is used to find and replace pending transactions,
+// `client.MultichainBlockchainClient` satisfies it.
+type CancellerClient interface {
+	TransactionByHash(chainID int64, hash common.Hash) (*types.Transaction, bool, error)
+	SendTransaction(chainID int64, tx *types.Transaction) error
+}
+
+// Canceller unsticks an account by replacing a pending transaction with a
+// zero value transfer to the sender itself, using the same nonce and a bumped fee.
+type Canceller struct {
+	bc          CancellerClient
+	bumpPercent uint64
+}
+
+// NewCanceller returns a new canceller bumping the fees by the given percent.
+// Bumps below `MinCancelBumpPercent` are raised to it.
+func NewCanceller(bc CancellerClient, bumpPercent uint64) *Canceller {
+	if bumpPercent < MinCancelBumpPercent {
+		bumpPercent = MinCancelBumpPercent
+	}
+
+	return &Canceller{
+		bc:          bc,
+		bumpPercent: bumpPercent,
+	}
+}
+
+// Cancel replaces the pending transaction with the given hash and returns the replacing transaction.
+// The signer must be able to sign for the sender of the pending transaction.
+func (c *Canceller) Cancel(chainID int64, hash common.Hash, signer SignFunc) (*types.Transaction, error) {
+	tx, pending, err := c.bc.TransactionByHash(chainID, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction %q: %w", hash.Hex(), err)
+	}
+	if !pending {
+		return nil, fmt.Errorf("%w: %q", ErrTransactionNotPending, hash.Hex())
+	}
+
+	sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(chainID)), tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover sender of %q: %w", hash.Hex(), err)
+	}
+
+	signed, err := signer(sender, c.cancellation(chainID, sender, tx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign cancellation of %q: %w", hash.Hex(), err)
+	}
+
+	if err := c.bc.SendTransaction(chainID, signed); err != nil {
+		return nil, fmt.Errorf("failed to send cancellation of %q: %w", hash.Hex(), err)
+	}
+	return signed, nil
+}
+
+func (c *Canceller) cancellation(chainID int64, sender common.Address, tx *types.Transaction) *types.Transaction {
+	if tx.Type() == types.LegacyTxType {
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    tx.Nonce(),
+			GasPrice: c.bump(tx.GasPrice()),
+			Gas:      cancelGasLimit,
+			To:       &sender,
+			Value:    big.NewInt(0),
+		})
+	}
+
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(chainID),
+		Nonce:     tx.Nonce(),
+		GasTipCap: c.bump(tx.GasTipCap()),
+		GasFeeCap: c.bump(tx.GasFeeCap()),
+		Gas:       cancelGasLimit,
+		To:        &sender,
+		Value:     big.NewInt(0),
+	})
+}
+
+// bump increases the value by the bump percent, rounding up.
+func (c *Canceller) bump(v *big.Int) *big.Int {
+	res := new(big.Int).Mul(v, new(big.Int).SetUint64(100+c.bumpPercent))
+	res.Add(res, big.NewInt(99))
+	return res.Div(res, big.NewInt(100))
+}
@@ -0,0 +1,77 @@
+package transaction
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/client"
+)
+
+var _ CancellerClient = (*client.MultichainBlockchainClient)(nil)
+
+type cancellerClientMock struct {
+	tx      *types.Transaction
+	pending bool
+	sent    *types.Transaction
+}
+
+func (m *cancellerClientMock) TransactionByHash(_ int64, hash common.Hash) (*types.Transaction, bool, error) {
+	if m.tx == nil || m.tx.Hash() != hash {
+		return nil, false, errors.New("not found")
+	}
+	return m.tx, m.pending, nil
+}
+
+func (m *cancellerClientMock) SendTransaction(_ int64, tx *types.Transaction) error {
+	m.sent = tx
+	return nil
+}
+
+func TestCanceller(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	chainSigner := types.LatestSignerForChainID(big.NewInt(chainId))
+	signer := func(_ common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		return types.SignTx(tx, chainSigner, key)
+	}
+
+	recipient := common.HexToAddress("0x2")
+	stuck, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(chainId),
+		Nonce:     7,
+		GasTipCap: big.NewInt(100),
+		GasFeeCap: big.NewInt(1001),
+		Gas:       50000,
+		To:        &recipient,
+		Value:     big.NewInt(5),
+	}), chainSigner, key)
+	assert.NoError(t, err)
+
+	bc := &cancellerClientMock{tx: stuck, pending: true}
+	c := NewCanceller(bc, 0)
+
+	replacing, err := c.Cancel(chainId, stuck.Hash(), signer)
+	assert.NoError(t, err)
+	assert.Equal(t, replacing, bc.sent)
+	assert.Equal(t, uint64(7), replacing.Nonce())
+	assert.Equal(t, sender, *replacing.To())
+	assert.Equal(t, big.NewInt(0), replacing.Value())
+	assert.Equal(t, uint64(21000), replacing.Gas())
+	assert.Equal(t, big.NewInt(110), replacing.GasTipCap())
+	assert.Equal(t, big.NewInt(1102), replacing.GasFeeCap())
+
+	from, err := types.Sender(chainSigner, replacing)
+	assert.NoError(t, err)
+	assert.Equal(t, sender, from)
+
+	bc.pending = false
+	_, err = c.Cancel(chainId, stuck.Hash(), signer)
+	assert.ErrorIs(t, err, ErrTransactionNotPending)
+}
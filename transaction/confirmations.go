@@ -0,0 +1,249 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ConfirmationStatus is the final status of a tracked confirmed transaction.
+type ConfirmationStatus string
+
+const (
+	// ConfirmationStatusFinal means the block of the transaction got the configured number of confirmations.
+	ConfirmationStatusFinal ConfirmationStatus = "final"
+	// ConfirmationStatusReorged means a reorg removed the block the transaction was confirmed in.
+	ConfirmationStatusReorged ConfirmationStatus = "reorged"
+)
+
+// ErrAlreadyTracked is returned when tracking a transaction which is already tracked.
+var ErrAlreadyTracked = errors.New("transaction is already tracked")
+
+// ConfirmationEvent is sent once a tracked transaction is final or was reorged out of its block.
+type ConfirmationEvent struct {
+	ChainID int64
+	Hash    common.Hash
+	Status  ConfirmationStatus
+	// BlockHash and BlockNumber are the block the transaction was tracked in.
+	BlockHash   common.Hash
+	BlockNumber uint64
+	// Receipt is the receipt of the transaction in the new chain, nil if a reorged transaction
+	// is not mined anymore.
+	Receipt *DeliveryReceipt
+}
+
+// ConfirmationClient is used to track confirmations, `client.MultichainBlockchainClient` satisfies it.
+type ConfirmationClient interface {
+	TransactionReceipt(chainID int64, hash common.Hash) (*types.Receipt, error)
+	BlockNumber(chainID int64) (uint64, error)
+	HeaderByNumber(chainID int64, number *big.Int) (*types.Header, error)
+}
+
+// ConfirmationTrackerConfig configures the confirmation tracker.
+type ConfirmationTrackerConfig struct {
+	// Interval is the interval between checks of the tracked transactions.
+	Interval time.Duration
+	// Confirmations is the number of blocks after the block of a transaction
+	// for which it is tracked before it is considered final.
+	Confirmations uint64
+}
+
+// ConfirmationTracker keeps tracking transactions which were already confirmed for a number
+// of additional blocks and reports if a reorg removes them from their block, so accounting
+// entries made on the confirmation can be reversed instead of silently corrupting the books.
+type ConfirmationTracker struct {
+	bc  ConfirmationClient
+	cfg ConfirmationTrackerConfig
+
+	tracked map[watchKey]*trackedTx
+	mu      sync.Mutex
+
+	logFn func(error)
+
+	once sync.Once
+	stop chan struct{}
+}
+
+type trackedTx struct {
+	sink        chan ConfirmationEvent
+	blockHash   common.Hash
+	blockNumber uint64
+}
+
+// NewConfirmationTracker returns a new confirmation tracker.
+func NewConfirmationTracker(bc ConfirmationClient, cfg ConfirmationTrackerConfig) *ConfirmationTracker {
+	return &ConfirmationTracker{
+		bc:      bc,
+		cfg:     cfg,
+		tracked: make(map[watchKey]*trackedTx),
+		logFn:   func(error) {},
+		stop:    make(chan struct{}),
+	}
+}
+
+// AttachLogger allows the caller to attach an optional logger
+// which will receive errors that happen while checking transactions.
+//
+// This method is not thread safe and should be called before `Run`.
+func (ct *ConfirmationTracker) AttachLogger(fn func(err error)) {
+	ct.logFn = fn
+}
+
+// Track starts tracking the transaction confirmed with the given receipt. The returned channel
+// receives a single event once the transaction is final or was reorged and is then closed.
+// A reorged transaction which was mined again needs to be tracked again with its new receipt.
+func (ct *ConfirmationTracker) Track(chainID int64, receipt *types.Receipt) (<-chan ConfirmationEvent, error) {
+	if receipt.BlockNumber == nil {
+		return nil, fmt.Errorf("receipt of %q has no block", receipt.TxHash.Hex())
+	}
+
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	key := watchKey{chainID: chainID, hash: receipt.TxHash}
+	if _, ok := ct.tracked[key]; ok {
+		return nil, fmt.Errorf("%w: %q", ErrAlreadyTracked, receipt.TxHash.Hex())
+	}
+
+	sink := make(chan ConfirmationEvent, 1)
+	ct.tracked[key] = &trackedTx{sink: sink, blockHash: receipt.BlockHash, blockNumber: receipt.BlockNumber.Uint64()}
+	return sink, nil
+}
+
+// Untrack stops tracking the transaction and closes its channel without an event.
+func (ct *ConfirmationTracker) Untrack(chainID int64, hash common.Hash) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	key := watchKey{chainID: chainID, hash: hash}
+	if tx, ok := ct.tracked[key]; ok {
+		close(tx.sink)
+		delete(ct.tracked, key)
+	}
+}
+
+// Run starts checking the tracked transactions periodically.
+func (ct *ConfirmationTracker) Run() {
+	go func() {
+		for {
+			select {
+			case <-ct.stop:
+				return
+			case <-time.After(ct.cfg.Interval):
+				ct.Check()
+			}
+		}
+	}()
+}
+
+// Stop stops the confirmation tracker.
+func (ct *ConfirmationTracker) Stop() {
+	ct.once.Do(func() {
+		close(ct.stop)
+	})
+}
+
+// Check checks every tracked transaction once and sends the events of the ones which are final or reorged.
+func (ct *ConfirmationTracker) Check() {
+	ct.mu.Lock()
+	keys := make([]watchKey, 0, len(ct.tracked))
+	for k := range ct.tracked {
+		keys = append(keys, k)
+	}
+	ct.mu.Unlock()
+
+	heads := make(map[int64]uint64)
+	for _, k := range keys {
+		ct.mu.Lock()
+		tx, ok := ct.tracked[k]
+		ct.mu.Unlock()
+		if !ok {
+			continue
+		}
+
+		head, ok := heads[k.chainID]
+		if !ok {
+			var err error
+			head, err = ct.bc.BlockNumber(k.chainID)
+			if err != nil {
+				ct.logFn(fmt.Errorf("failed to get the latest block of chain %d: %w", k.chainID, err))
+				continue
+			}
+			heads[k.chainID] = head
+		}
+
+		ev, done, err := ct.check(k, tx, head)
+		if err != nil {
+			ct.logFn(fmt.Errorf("failed to check confirmation of transaction %q: %w", k.hash.Hex(), err))
+			continue
+		}
+		if done {
+			ct.finish(k, ev)
+		}
+	}
+}
+
+func (ct *ConfirmationTracker) check(k watchKey, tx *trackedTx, head uint64) (ConfirmationEvent, bool, error) {
+	ev := ConfirmationEvent{ChainID: k.chainID, Hash: k.hash, BlockHash: tx.blockHash, BlockNumber: tx.blockNumber}
+
+	receipt, err := ct.bc.TransactionReceipt(k.chainID, k.hash)
+	switch {
+	case errors.Is(err, ethereum.NotFound):
+		reorged, err := ct.reorged(k, tx, head)
+		if err != nil || !reorged {
+			return ev, false, err
+		}
+		ev.Status = ConfirmationStatusReorged
+		return ev, true, nil
+	case err != nil:
+		return ev, false, err
+	case receipt.BlockHash != tx.blockHash:
+		ev.Status = ConfirmationStatusReorged
+		ev.Receipt = newDeliveryReceipt(receipt)
+		return ev, true, nil
+	}
+
+	if head >= tx.blockNumber+ct.cfg.Confirmations {
+		ev.Status = ConfirmationStatusFinal
+		ev.Receipt = newDeliveryReceipt(receipt)
+		return ev, true, nil
+	}
+	return ev, false, nil
+}
+
+// reorged checks that the block of a transaction without a receipt is not canonical anymore.
+// Endpoints behind the block or lagging behind a load balancer miss receipts as well,
+// the missing receipt is only a reorg if the endpoint has a different block at its height.
+func (ct *ConfirmationTracker) reorged(k watchKey, tx *trackedTx, head uint64) (bool, error) {
+	if head < tx.blockNumber {
+		return false, nil
+	}
+
+	header, err := ct.bc.HeaderByNumber(k.chainID, new(big.Int).SetUint64(tx.blockNumber))
+	if errors.Is(err, ethereum.NotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get block %d: %w", tx.blockNumber, err)
+	}
+	return header.Hash() != tx.blockHash, nil
+}
+
+func (ct *ConfirmationTracker) finish(k watchKey, ev ConfirmationEvent) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	tx, ok := ct.tracked[k]
+	if !ok {
+		return
+	}
+	tx.sink <- ev
+	close(tx.sink)
+	delete(ct.tracked, k)
+}
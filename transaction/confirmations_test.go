@@ -0,0 +1,128 @@
+package transaction
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/client"
+)
+
+var _ ConfirmationClient = (*client.MultichainBlockchainClient)(nil)
+
+type confirmationClientMock struct {
+	lock     sync.Mutex
+	receipts map[common.Hash]*types.Receipt
+	headers  map[uint64]*types.Header
+	head     uint64
+}
+
+func (m *confirmationClientMock) HeaderByNumber(_ int64, number *big.Int) (*types.Header, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if h, ok := m.headers[number.Uint64()]; ok {
+		return h, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (m *confirmationClientMock) TransactionReceipt(_ int64, hash common.Hash) (*types.Receipt, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if r, ok := m.receipts[hash]; ok {
+		return r, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (m *confirmationClientMock) BlockNumber(_ int64) (uint64, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.head, nil
+}
+
+func TestConfirmationTracker(t *testing.T) {
+	receipt := func(tx, block string, number int64) *types.Receipt {
+		return &types.Receipt{TxHash: common.HexToHash(tx), BlockHash: common.HexToHash(block), BlockNumber: big.NewInt(number), Status: types.ReceiptStatusSuccessful}
+	}
+	final, moved, dropped := receipt("0x1", "0xa", 10), receipt("0x2", "0xb", 11), receipt("0x3", "0xb", 11)
+	bc := &confirmationClientMock{
+		receipts: map[common.Hash]*types.Receipt{final.TxHash: final, moved.TxHash: moved, dropped.TxHash: dropped},
+		headers:  map[uint64]*types.Header{11: {Number: big.NewInt(11)}},
+		head:     11,
+	}
+
+	ct := NewConfirmationTracker(bc, ConfirmationTrackerConfig{Interval: time.Millisecond, Confirmations: 3})
+	finalCh, err := ct.Track(chainId, final)
+	assert.NoError(t, err)
+	movedCh, err := ct.Track(chainId, moved)
+	assert.NoError(t, err)
+	droppedCh, err := ct.Track(chainId, dropped)
+	assert.NoError(t, err)
+	_, err = ct.Track(chainId, dropped)
+	assert.ErrorIs(t, err, ErrAlreadyTracked)
+
+	ct.Check()
+	assert.Len(t, ct.tracked, 3, "nothing is final yet")
+
+	bc.lock.Lock()
+	bc.receipts[moved.TxHash] = receipt("0x2", "0xc", 12)
+	delete(bc.receipts, dropped.TxHash)
+	bc.head = 13
+	bc.lock.Unlock()
+
+	ct.Run()
+	defer ct.Stop()
+
+	ev := <-finalCh
+	assert.Equal(t, ConfirmationStatusFinal, ev.Status)
+	assert.Equal(t, uint64(10), ev.Receipt.BlockNumber)
+
+	ev = <-movedCh
+	assert.Equal(t, ConfirmationStatusReorged, ev.Status)
+	assert.Equal(t, common.HexToHash("0xb"), ev.BlockHash)
+	assert.Equal(t, uint64(12), ev.Receipt.BlockNumber)
+
+	ev = <-droppedCh
+	assert.Equal(t, ConfirmationStatusReorged, ev.Status)
+	assert.Nil(t, ev.Receipt)
+
+	ch, err := ct.Track(chainId, dropped)
+	assert.NoError(t, err)
+	ct.Untrack(chainId, dropped.TxHash)
+	_, open := <-ch
+	assert.False(t, open)
+}
+
+func TestConfirmationTrackerTransientNotFound(t *testing.T) {
+	canonical := &types.Header{Number: big.NewInt(10)}
+	receipt := &types.Receipt{TxHash: common.HexToHash("0x1"), BlockHash: canonical.Hash(), BlockNumber: big.NewInt(10)}
+	bc := &confirmationClientMock{receipts: map[common.Hash]*types.Receipt{}, headers: map[uint64]*types.Header{}, head: 9}
+
+	ct := NewConfirmationTracker(bc, ConfirmationTrackerConfig{Confirmations: 3})
+	ch, err := ct.Track(chainId, receipt)
+	assert.NoError(t, err)
+
+	ct.Check()
+	assert.Len(t, ct.tracked, 1, "endpoint is behind the block")
+
+	bc.head = 11
+	ct.Check()
+	assert.Len(t, ct.tracked, 1, "endpoint does not have the block")
+
+	bc.headers[10] = canonical
+	ct.Check()
+	assert.Len(t, ct.tracked, 1, "block is still canonical")
+
+	bc.headers[10] = &types.Header{Number: big.NewInt(10), Extra: []byte("reorg")}
+	ct.Check()
+	ev := <-ch
+	assert.Equal(t, ConfirmationStatusReorged, ev.Status)
+	assert.Nil(t, ev.Receipt)
+}
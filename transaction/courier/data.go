@@ -19,6 +19,12 @@ type mystTransferData struct {
 	MystAddress common.Address `json:"myst_address"`
 }
 
+type tokenTransferData struct {
+	transferData
+	Token    common.Address `json:"token"`
+	GasLimit uint64         `json:"gas_limit"`
+}
+
 func (d *transferData) validate() error {
 	if d.Amount == nil || d.Amount.Cmp(big.NewInt(0)) == 0 {
 		return fmt.Errorf("amount cannot be 0: %w", transaction.ErrImpossibleToDeliver)
@@ -40,3 +46,14 @@ func (d *mystTransferData) validate() error {
 
 	return nil
 }
+
+func (d *tokenTransferData) validate() error {
+	if err := d.transferData.validate(); err != nil {
+		return err
+	}
+	if d.Token.Hex() == common.HexToAddress("").Hex() {
+		return fmt.Errorf("token address cannot be empty: %w", transaction.ErrImpossibleToDeliver)
+	}
+
+	return nil
+}
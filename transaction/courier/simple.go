@@ -2,14 +2,19 @@ package courier
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 
+	"github.com/mysteriumnetwork/payments/bindings"
 	"github.com/mysteriumnetwork/payments/client"
 	"github.com/mysteriumnetwork/payments/transaction"
+	"github.com/mysteriumnetwork/payments/transaction/gas"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/shopspring/decimal"
 )
 
 // Simple is the default simple courier that implements
@@ -23,15 +28,44 @@ type Simple struct {
 type SimpleBCClient interface {
 	TransferMyst(chainID int64, req client.TransferRequest) (*types.Transaction, error)
 	TransferEth(chainID int64, etr client.EthTransferRequest) (*types.Transaction, error)
+	TokenDecimals(chainID int64, tokenAddress common.Address) (uint8, error)
+	EstimateGas(chainID int64, msg ethereum.CallMsg) (uint64, error)
+	GetEthBalance(chainID int64, address common.Address) (*big.Int, error)
 }
 
+// InsufficientBalanceError is returned when the balance of the sender
+// cannot cover the value of a transfer and its max fee.
+type InsufficientBalanceError struct {
+	ChainID  int64
+	Sender   common.Address
+	Balance  *big.Int
+	Required *big.Int
+}
+
+func (e *InsufficientBalanceError) Error() string {
+	return fmt.Sprintf("%s: %s has %s on chain %d, %s is required", transaction.ErrInsufficientFunds, e.Sender.Hex(), e.Balance, e.ChainID, e.Required)
+}
+
+// Unwrap returns `transaction.ErrInsufficientFunds`.
+func (e *InsufficientBalanceError) Unwrap() error {
+	return transaction.ErrInsufficientFunds
+}
+
+// ErrInvalidTokenAmount is returned when a token amount is not positive
+// or is more precise than the decimals of the token.
+var ErrInvalidTokenAmount = errors.New("invalid token amount")
+
 // SignerFactory given a sender and chain should produce a signature func
 // which can be used to sign transactions.
 type SignerFactory func(sender common.Address, chain int64) transaction.SignFunc
 
+// networkTransferGasLimit is the gas limit of native coin transfers.
+const networkTransferGasLimit = 50000
+
 const (
 	deliveryTypeNetworkTransfer transaction.DeliverableType = "network-transfer"
 	deliveryTypeMystTransfer    transaction.DeliverableType = "myst-transfer"
+	deliveryTypeTokenTransfer   transaction.DeliverableType = "token-transfer"
 )
 
 func NewSimpleCourier(bc SimpleBCClient, sf SignerFactory) *Simple {
@@ -41,13 +75,25 @@ func NewSimpleCourier(bc SimpleBCClient, sf SignerFactory) *Simple {
 	}
 }
 
+// DeliverTransaction signs and sends the delivery. If the node already knows
+// the transaction, the signed transaction is returned together with the error.
 func (s *Simple) DeliverTransaction(td transaction.Delivery) (*types.Transaction, error) {
 	fn := s.getDeliveryFunc(td.Type)
 	if fn == nil {
 		return nil, fmt.Errorf("type %q is impossible to handle", td.Type)
 	}
 
-	return fn(td)
+	var signed *types.Transaction
+	signer := s.sf(td.Sender, td.ChainID)
+	tx, err := fn(td, func(addr common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		stx, err := signer(addr, tx)
+		signed = stx
+		return stx, err
+	})
+	if err != nil && signed != nil && errors.Is(transaction.ClassifySendError(err), transaction.ErrAlreadyKnown) {
+		return signed, err
+	}
+	return tx, err
 }
 
 func (s *Simple) CanDeliver(typ transaction.DeliverableType) bool {
@@ -68,6 +114,37 @@ func (c *Simple) NewNetworkTransferDelivery(sender transaction.Sender, amount *b
 	}, nt.validate()
 }
 
+// NewCheckedNetworkTransferDelivery creates a native coin transfer like `NewNetworkTransferDelivery`,
+// but first checks that the balance of the sender covers the amount and the max fee of the transfer
+// at the given max fee per gas. An `InsufficientBalanceError` is returned if it does not.
+func (c *Simple) NewCheckedNetworkTransferDelivery(sender transaction.Sender, amount *big.Int, to common.Address, maxFeePerGas *big.Int) (transaction.DeliveryRequest, error) {
+	req, err := c.NewNetworkTransferDelivery(sender, amount, to)
+	if err != nil {
+		return req, err
+	}
+
+	balance, err := c.bc.GetEthBalance(sender.ChainID, sender.Address)
+	if err != nil {
+		return transaction.DeliveryRequest{}, fmt.Errorf("failed to get balance of %q: %w", sender.Address.Hex(), err)
+	}
+
+	required := new(big.Int).Set(amount)
+	if maxFeePerGas != nil {
+		maxFee := new(big.Int).Mul(maxFeePerGas, new(big.Int).SetUint64(networkTransferGasLimit))
+		required.Add(required, maxFee)
+	}
+
+	if balance.Cmp(required) < 0 {
+		return transaction.DeliveryRequest{}, &InsufficientBalanceError{
+			ChainID:  sender.ChainID,
+			Sender:   sender.Address,
+			Balance:  balance,
+			Required: required,
+		}
+	}
+	return req, nil
+}
+
 func (c *Simple) NewMystTransferDelivery(sender transaction.Sender, amount *big.Int, to common.Address, mystAddr common.Address) (transaction.DeliveryRequest, error) {
 	mt := mystTransferData{
 		transferData: transferData{
@@ -85,19 +162,78 @@ func (c *Simple) NewMystTransferDelivery(sender transaction.Sender, amount *big.
 	}, mt.validate()
 }
 
-func (s *Simple) getDeliveryFunc(typ transaction.DeliverableType) func(transaction.Delivery) (*types.Transaction, error) {
+// NewTokenTransferDelivery creates a transfer of an ERC-20 token. The amount is given in whole
+// tokens, it is converted to the smallest unit using the decimals of the token and the gas
+// limit is estimated with a buffer of `gas.DefaultGasBuffer`.
+func (c *Simple) NewTokenTransferDelivery(sender transaction.Sender, token, to common.Address, amount decimal.Decimal) (transaction.DeliveryRequest, error) {
+	decimals, err := c.bc.TokenDecimals(sender.ChainID, token)
+	if err != nil {
+		return transaction.DeliveryRequest{}, fmt.Errorf("failed to get decimals of token %q: %w", token.Hex(), err)
+	}
+
+	baseUnits := amount.Shift(int32(decimals))
+	if !baseUnits.IsInteger() || !baseUnits.IsPositive() {
+		return transaction.DeliveryRequest{}, fmt.Errorf("%w: %s with %d decimals", ErrInvalidTokenAmount, amount, decimals)
+	}
+
+	tt := tokenTransferData{
+		transferData: transferData{
+			Amount: baseUnits.BigInt(),
+			To:     to,
+		},
+		Token: token,
+	}
+	if err := tt.validate(); err != nil {
+		return transaction.DeliveryRequest{}, err
+	}
+
+	tokenABI, err := bindings.Erc20MetaData.GetAbi()
+	if err != nil {
+		return transaction.DeliveryRequest{}, err
+	}
+	data, err := tokenABI.Pack("transfer", to, tt.Amount)
+	if err != nil {
+		return transaction.DeliveryRequest{}, err
+	}
+
+	estimate, err := c.bc.EstimateGas(sender.ChainID, ethereum.CallMsg{
+		From: sender.Address,
+		To:   &token,
+		Data: data,
+	})
+	if err != nil {
+		return transaction.DeliveryRequest{}, fmt.Errorf("failed to estimate gas of token transfer: %w", err)
+	}
+	tt.GasLimit, err = gas.ApplyGasBuffer(estimate, gas.DefaultGasBuffer, 0)
+	if err != nil {
+		return transaction.DeliveryRequest{}, err
+	}
+
+	return transaction.DeliveryRequest{
+		ChainID: sender.ChainID,
+		Sender:  sender.Address,
+		Type:    deliveryTypeTokenTransfer,
+		Data:    tt,
+	}, nil
+}
+
+func (s *Simple) getDeliveryFunc(typ transaction.DeliverableType) func(transaction.Delivery, transaction.SignFunc) (*types.Transaction, error) {
 	switch typ {
 	case deliveryTypeNetworkTransfer:
 		return s.networkTransfer
 	case deliveryTypeMystTransfer:
 		return s.mystTransfer
+	case deliveryTypeTokenTransfer:
+		return s.tokenTransfer
+	case transaction.DeliverableCancelled:
+		return s.cancelled
 	default:
 		return nil
 	}
 }
 
-func (c *Simple) networkTransfer(td transaction.Delivery) (*types.Transaction, error) {
-	wr := td.ToWriteRequest(c.sf(td.Sender, td.ChainID), 50000)
+func (c *Simple) networkTransfer(td transaction.Delivery, sign transaction.SignFunc) (*types.Transaction, error) {
+	wr := td.ToWriteRequest(sign, networkTransferGasLimit)
 
 	var extra transferData
 	if err := json.Unmarshal(td.ShipmentData, &extra); err != nil {
@@ -117,8 +253,17 @@ func (c *Simple) networkTransfer(td transaction.Delivery) (*types.Transaction, e
 	return c.bc.TransferEth(td.ChainID, request)
 }
 
-func (c *Simple) mystTransfer(td transaction.Delivery) (*types.Transaction, error) {
-	wr := td.ToWriteRequest(c.sf(td.Sender, td.ChainID), 100000)
+// cancelled uses up the nonce of a cancelled delivery with a zero value transfer to the sender.
+func (c *Simple) cancelled(td transaction.Delivery, sign transaction.SignFunc) (*types.Transaction, error) {
+	return c.bc.TransferEth(td.ChainID, client.EthTransferRequest{
+		WriteRequest: td.ToWriteRequest(sign, networkTransferGasLimit),
+		Amount:       new(big.Int),
+		To:           td.Sender,
+	})
+}
+
+func (c *Simple) mystTransfer(td transaction.Delivery, sign transaction.SignFunc) (*types.Transaction, error) {
+	wr := td.ToWriteRequest(sign, 100000)
 
 	var extra mystTransferData
 	if err := json.Unmarshal(td.ShipmentData, &extra); err != nil {
@@ -138,3 +283,24 @@ func (c *Simple) mystTransfer(td transaction.Delivery) (*types.Transaction, erro
 
 	return c.bc.TransferMyst(td.ChainID, request)
 }
+
+func (c *Simple) tokenTransfer(td transaction.Delivery, sign transaction.SignFunc) (*types.Transaction, error) {
+	var extra tokenTransferData
+	if err := json.Unmarshal(td.ShipmentData, &extra); err != nil {
+		return nil, err
+	}
+
+	if err := extra.validate(); err != nil {
+		return nil, err
+	}
+
+	// MYST is a plain ERC-20 token, its transfer works for any other token.
+	request := client.TransferRequest{
+		WriteRequest: td.ToWriteRequest(sign, extra.GasLimit),
+		Amount:       extra.Amount,
+		Recipient:    extra.To,
+		MystAddress:  extra.Token,
+	}
+
+	return c.bc.TransferMyst(td.ChainID, request)
+}
@@ -9,11 +9,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/mysteriumnetwork/payments/client"
 	"github.com/mysteriumnetwork/payments/transaction"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -191,6 +193,86 @@ func TestSimpleCourier(t *testing.T) {
 		})
 	})
 
+	t.Run("checked native transfer", func(t *testing.T) {
+		defer resetFunc()
+
+		to := common.HexToAddress("0x742d13F0b2A19C823bdd362b16305e4704b97A31")
+		sender := transaction.NewSender(senderAddr, 137)
+		amount := big.NewInt(100)
+		maxFeePerGas := big.NewInt(2)
+
+		mockBCClient.balance = big.NewInt(100100)
+		deliveryRequest, err := courier.NewCheckedNetworkTransferDelivery(sender, amount, to, maxFeePerGas)
+		assert.NoError(t, err)
+		assert.Equal(t, deliveryTypeNetworkTransfer, deliveryRequest.Type)
+
+		mockBCClient.balance = big.NewInt(100099)
+		_, err = courier.NewCheckedNetworkTransferDelivery(sender, amount, to, maxFeePerGas)
+		assert.ErrorIs(t, err, transaction.ErrInsufficientFunds)
+		var balanceErr *InsufficientBalanceError
+		assert.ErrorAs(t, err, &balanceErr)
+		assert.Equal(t, big.NewInt(100099), balanceErr.Balance)
+		assert.Equal(t, big.NewInt(100100), balanceErr.Required)
+	})
+
+	t.Run("token transfer", func(t *testing.T) {
+		defer resetFunc()
+
+		to := common.HexToAddress("0x742d13F0b2A19C823bdd362b16305e4704b97A31")
+		token := common.HexToAddress("0x742d13F0b2A19C823bdd362b16305e4704b97A33")
+		var chainId int64 = 137
+
+		t.Run("rejects amounts below the token precision", func(t *testing.T) {
+			_, err := courier.NewTokenTransferDelivery(transaction.NewSender(senderAddr, chainId), token, to, decimal.RequireFromString("0.0000001"))
+			assert.ErrorIs(t, err, ErrInvalidTokenAmount)
+			_, err = courier.NewTokenTransferDelivery(transaction.NewSender(senderAddr, chainId), token, to, decimal.Zero)
+			assert.ErrorIs(t, err, ErrInvalidTokenAmount)
+		})
+
+		var deliveryRequest transaction.DeliveryRequest
+		t.Run("creates transfer", func(t *testing.T) {
+			deliveryRequest, err = courier.NewTokenTransferDelivery(transaction.NewSender(senderAddr, chainId), token, to, decimal.RequireFromString("1.5"))
+			assert.NoError(t, err)
+
+			assert.Equal(t, chainId, deliveryRequest.ChainID)
+			assert.Equal(t, senderAddr, deliveryRequest.Sender)
+			assert.Equal(t, deliveryTypeTokenTransfer, deliveryRequest.Type)
+			assert.Equal(t, tokenTransferData{
+				transferData: transferData{
+					Amount: big.NewInt(1500000),
+					To:     to,
+				},
+				Token:    token,
+				GasLimit: 60000,
+			}, deliveryRequest.Data)
+			assert.Equal(t, token, *mockBCClient.estimated.To)
+		})
+
+		t.Run("delivers it", func(t *testing.T) {
+			blob, err := json.Marshal(deliveryRequest.Data)
+			assert.NoError(t, err)
+
+			tx, err := courier.DeliverTransaction(transaction.Delivery{
+				Sender:  deliveryRequest.Sender,
+				Nonce:   2,
+				ChainID: deliveryRequest.ChainID,
+				GasTip:  big.NewInt(10),
+				BaseFee: big.NewInt(100),
+
+				Type:  deliveryRequest.Type,
+				State: transaction.DeliveryStateWaiting,
+
+				ShipmentData: blob,
+			})
+			assert.NoError(t, err)
+
+			assert.Equal(t, token, *tx.To())
+			assert.Equal(t, uint64(60000), tx.Gas())
+			assert.Equal(t, []byte(fmt.Sprintf("send 1500000 MYST to %s", to.Hex())), tx.Data())
+			assert.Equal(t, 1, mockBCClient.sentMystTransfers)
+		})
+	})
+
 	t.Run("errors", func(t *testing.T) {
 		defer resetFunc()
 
@@ -316,6 +398,47 @@ func TestSimpleCourier(t *testing.T) {
 				assert.Error(t, err)
 				assert.ErrorIs(t, err, blockchainError)
 			})
+
+			t.Run("delivers cancelled delivery as a self transfer", func(t *testing.T) {
+				mockBCClient.reset()
+				assert.True(t, courier.CanDeliver(transaction.DeliverableCancelled))
+
+				tx, err := courier.DeliverTransaction(transaction.Delivery{
+					Sender:  deliveryRequest.Sender,
+					Nonce:   4,
+					ChainID: deliveryRequest.ChainID,
+					GasTip:  big.NewInt(10),
+					BaseFee: big.NewInt(100),
+					Type:    transaction.DeliverableCancelled,
+					State:   transaction.DeliveryStatePacking,
+				})
+				assert.NoError(t, err)
+				assert.Equal(t, senderAddr, *tx.To())
+				assert.Equal(t, int64(0), tx.Value().Int64())
+				assert.Equal(t, uint64(4), tx.Nonce())
+			})
+
+			t.Run("returns the signed transaction if it is already known", func(t *testing.T) {
+				mockBCClient.reset()
+				mockBCClient.sendErrToReturn = fmt.Errorf("already known")
+
+				blob, err := json.Marshal(deliveryRequest.Data)
+				assert.NoError(t, err)
+
+				tx, err := courier.DeliverTransaction(transaction.Delivery{
+					Sender:       deliveryRequest.Sender,
+					Nonce:        3,
+					ChainID:      deliveryRequest.ChainID,
+					GasTip:       big.NewInt(10),
+					BaseFee:      big.NewInt(100),
+					Type:         deliveryRequest.Type,
+					State:        transaction.DeliveryStatePacking,
+					ShipmentData: blob,
+				})
+				assert.ErrorIs(t, transaction.ClassifySendError(err), transaction.ErrAlreadyKnown)
+				assert.NotNil(t, tx)
+				assert.Equal(t, uint64(3), tx.Nonce())
+			})
 		})
 
 		t.Run("missing data", func(t *testing.T) {
@@ -434,6 +557,28 @@ type mockBCClient struct {
 	sentMystTransfers int
 	lock              sync.Mutex
 	errToReturn       error
+	sendErrToReturn   error
+	estimated         ethereum.CallMsg
+	balance           *big.Int
+}
+
+func (m *mockBCClient) GetEthBalance(chainID int64, address common.Address) (*big.Int, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.balance, nil
+}
+
+func (m *mockBCClient) TokenDecimals(chainID int64, tokenAddress common.Address) (uint8, error) {
+	return 6, nil
+}
+
+func (m *mockBCClient) EstimateGas(chainID int64, msg ethereum.CallMsg) (uint64, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.estimated = msg
+	return 50000, nil
 }
 
 func (m *mockBCClient) TransferMyst(chainID int64, req client.TransferRequest) (*types.Transaction, error) {
@@ -485,6 +630,9 @@ func (m *mockBCClient) TransferEth(chainID int64, etr client.EthTransferRequest)
 	if err != nil {
 		return nil, fmt.Errorf("could not sign tx: %w", err)
 	}
+	if m.sendErrToReturn != nil {
+		return nil, m.sendErrToReturn
+	}
 
 	m.sentTransfers++
 	return signedTx, nil
@@ -497,4 +645,5 @@ func (m *mockBCClient) reset() {
 	m.sentTransfers = 0
 	m.sentMystTransfers = 0
 	m.errToReturn = nil
+	m.sendErrToReturn = nil
 }
@@ -42,21 +42,78 @@ type Delivery struct {
 	GasTip  *big.Int
 	BaseFee *big.Int
 
-	Type  DeliverableType
-	State DeliveryState
+	Type     DeliverableType
+	State    DeliveryState
+	Priority DeliveryPriority
 
 	ShipmentData    []byte
 	SentTransaction []byte
 
+	// Replacements is the number of times the sent transaction was
+	// replaced by one with the same nonce and a higher fee.
+	Replacements uint
+
+	// ConfirmedBlock is the block at which the nonce of the delivery was seen
+	// confirmed, taking the configured confirmations into account. It is only
+	// known if the nonce tracker implements `DepotConfirmationTracker`.
+	ConfirmedBlock uint64
+
+	// Receipt is the execution result of the delivered transaction. It is only
+	// known if a receipt client is attached to the depot.
+	Receipt *DeliveryReceipt
+
+	// NotBefore is the time before which the delivery is not sent, zero sends it right away.
+	NotBefore time.Time
+
 	CreatedUTC time.Time
 	UpdateUTC  time.Time
 }
 
+// DeliveryReceipt is the execution result of a delivered transaction.
+type DeliveryReceipt struct {
+	TxHash            common.Hash
+	Status            uint64
+	GasUsed           uint64
+	EffectiveGasPrice *big.Int
+	BlockNumber       uint64
+}
+
+// Reverted returns true if the transaction was mined but its execution failed.
+func (r *DeliveryReceipt) Reverted() bool {
+	return r.Status == types.ReceiptStatusFailed
+}
+
+func newDeliveryReceipt(r *types.Receipt) *DeliveryReceipt {
+	res := &DeliveryReceipt{
+		TxHash:            r.TxHash,
+		Status:            r.Status,
+		GasUsed:           r.GasUsed,
+		EffectiveGasPrice: r.EffectiveGasPrice,
+	}
+	if r.BlockNumber != nil {
+		res.BlockNumber = r.BlockNumber.Uint64()
+	}
+	return res
+}
+
 // DeliverableType is issued for the Courier to determine how to package a transaction.
 type DeliverableType string
 
 type DeliveryState string
 
+// DeliveryPriority is the urgency of a delivery, for example settlements
+// are more urgent than refills which are more urgent than maintenance.
+type DeliveryPriority int
+
+const (
+	// DeliveryPriorityLow is for maintenance deliveries which are sent using slow gas prices.
+	DeliveryPriorityLow DeliveryPriority = -1
+	// DeliveryPriorityNormal is the default priority, sent using the gas tracker speed.
+	DeliveryPriorityNormal DeliveryPriority = 0
+	// DeliveryPriorityHigh is for urgent deliveries which are sent using fast gas prices.
+	DeliveryPriorityHigh DeliveryPriority = 1
+)
+
 const (
 	// Waiting is state for transaction which were never sent
 	DeliveryStateWaiting = "waiting"
@@ -69,8 +126,16 @@ const (
 	// DeliveryStateDelivered is state for transaction that have been delivered
 	// and we will no longer track it.
 	DeliveryStateDelivered = "delivered"
+	// DeliveryStateCancelled is state for transaction that were cancelled before
+	// they were sent. Their nonce is used up by a `DeliverableCancelled` no-op,
+	// which is then sent and tracked as any other transaction.
+	DeliveryStateCancelled = "cancelled"
 )
 
+// DeliverableCancelled is the type of cancelled deliveries. Couriers should deliver it as
+// a transfer of zero native coins from the sender to itself, which only uses up the nonce.
+const DeliverableCancelled DeliverableType = "cancelled"
+
 var ErrNoTrasactionExists = errors.New("transaction doesn't exist")
 
 type SignFunc func(common.Address, *types.Transaction) (*types.Transaction, error)
@@ -104,6 +169,15 @@ func (t *Delivery) GetLastTransaction() (*types.Transaction, error) {
 	return tx, tx.UnmarshalJSON(t.SentTransaction)
 }
 
+// LastTransactionHash returns the hash of the last sent transaction or an empty hash if none was sent.
+func (t *Delivery) LastTransactionHash() common.Hash {
+	tx, err := t.GetLastTransaction()
+	if err != nil {
+		return common.Hash{}
+	}
+	return tx.Hash()
+}
+
 func (t *Delivery) applyFees(f *fees) *Delivery {
 	dd := *t
 	if t.GasPrice != nil && t.GasPrice.Cmp(big.NewInt(0)) > 0 {
@@ -116,6 +190,16 @@ func (t *Delivery) applyFees(f *fees) *Delivery {
 	return &dd
 }
 
+// cancelled returns the no-op using up the nonce of the delivery.
+func (t Delivery) cancelled() Delivery {
+	t.Type = DeliverableCancelled
+	t.State = DeliveryStateCancelled
+	t.ShipmentData = nil
+	t.NotBefore = time.Time{}
+	t.UpdateUTC = time.Now().UTC()
+	return t
+}
+
 func (t Delivery) Hash() string {
 	return fmt.Sprintf("%s:%d:%d", t.Sender, t.ChainID, t.Nonce)
 }
@@ -124,7 +208,17 @@ type DeliveryRequest struct {
 	ChainID int64
 	Sender  common.Address
 
-	Type DeliverableType
+	Type     DeliverableType
+	Priority DeliveryPriority
+
+	// IdempotencyKey identifies the request when it is retried. If set, requests with
+	// the same key are only queued once and get the tracking number of the first one.
+	IdempotencyKey string
+
+	// NotBefore schedules the delivery, it is not sent before the given time.
+	// Deliveries of the same sender queued after it wait for it too, as
+	// transactions are mined in nonce order.
+	NotBefore time.Time
 
 	// Data must always be a marshable struct or nil
 	Data interface{}
@@ -152,8 +246,11 @@ func (t *DeliveryRequest) toDelivery(nonce uint64) (Delivery, error) {
 		GasTip:   new(big.Int).SetInt64(0),
 		BaseFee:  new(big.Int).SetInt64(0),
 
-		Type:  t.Type,
-		State: DeliveryStateWaiting,
+		Type:     t.Type,
+		State:    DeliveryStateWaiting,
+		Priority: t.Priority,
+
+		NotBefore: t.NotBefore,
 
 		ShipmentData:    blob,
 		SentTransaction: []byte{},
@@ -17,14 +17,22 @@
 package transaction
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/big"
+	"math/rand"
 	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/mysteriumnetwork/payments/client"
+	"github.com/mysteriumnetwork/payments/idempotency"
 )
 
 // Depot is a transaction delivery depot. Using a given `DeliveryCourier`
@@ -46,6 +54,27 @@ type Depot struct {
 
 	logFn   func(error)
 	metrics DepotMetricsExporter
+	tracing *depotTracing
+
+	replacedListeners []ReplacedListener
+
+	receipts DepotReceiptClient
+	recovery DepotRecoveryClient
+	limiter  *rateLimiter
+
+	idempotency *idempotency.Layer
+
+	deadLetters DeadLetterSink
+	failures    map[string]*deliveryFailures
+	failuresMu  sync.Mutex
+
+	// unsent holds the deliveries queued since the start which were not sent yet,
+	// the cancelled ones are true.
+	unsent   map[string]bool
+	unsentMu sync.Mutex
+
+	workersMu sync.RWMutex
+	running   bool
 
 	once sync.Once
 	stop chan struct{}
@@ -55,8 +84,37 @@ type DepotConfig struct {
 	Workers         []DepotWorker
 	MaxNonDelivered uint
 	ForceResend     time.Duration
+	// Confirmations is the number of blocks which have to be mined on top of
+	// a transaction before it is considered delivered, zero means as soon as it is included.
+	// It requires the nonce tracker to implement `DepotConfirmationTracker`.
+	Confirmations uint64
+	// MaxFailures is the number of consecutive failures after which a delivery is
+	// handed to the dead letter sink, zero means only on `ErrImpossibleToDeliver`.
+	MaxFailures uint
+	// MaxReplacements is the max number of times a stuck transaction is
+	// replaced with a higher fee, zero means no limit.
+	MaxReplacements uint
 }
 
+// DeadLetterSink receives deliveries which failed permanently, returning
+// `ErrImpossibleToDeliver`, or failed the configured max number of times in a row.
+// The delivery holds the original payload in its `ShipmentData`.
+//
+// Dead lettered deliveries are kept in the depot and are still retried, as dropping
+// them would leave a nonce gap blocking every following transaction of the sender.
+type DeadLetterSink interface {
+	DeadLetter(td Delivery, err error) error
+}
+
+type deliveryFailures struct {
+	count        uint
+	deadLettered bool
+}
+
+// ReplacedListener is called after a stuck transaction was replaced by one with
+// the same nonce and a higher fee. The delivery holds the replacing transaction.
+type ReplacedListener func(td Delivery, replaced common.Hash)
+
 // DepotWorker is a worker that will spawn upon starting `Run`.
 // Each worker is reponsible for its own transactions only.
 type DepotWorker struct {
@@ -64,6 +122,48 @@ type DepotWorker struct {
 	ChainID         int64
 	ProcessInterval time.Duration
 	ProcessCount    uint
+	// MaxProcessInterval caps the exponential backoff of the process interval. While
+	// transactions are in flight but none of them is sent or delivered, the interval
+	// is doubled up to this value to spare the RPC provider. Zero disables the backoff.
+	MaxProcessInterval time.Duration
+	// ProcessJitter is the max fraction of the interval added at random to every
+	// wait, so multiple workers do not poll in lockstep. For example 0.1 adds up to 10%.
+	ProcessJitter float64
+}
+
+// WorkersForChains returns a worker of the sender for every chain, sharing the
+// settings of the template. A single depot runs the workers of every chain and
+// routes deliveries to them by their chain ID.
+func WorkersForChains(sender common.Address, chains []int64, template DepotWorker) []DepotWorker {
+	res := make([]DepotWorker, 0, len(chains))
+	for _, chainID := range chains {
+		w := template
+		w.Address = sender
+		w.ChainID = chainID
+		res = append(res, w)
+	}
+	return res
+}
+
+// nextInterval returns the interval to wait before the next processing round.
+func (w DepotWorker) nextInterval(current time.Duration, progressed bool) time.Duration {
+	if progressed || w.MaxProcessInterval <= w.ProcessInterval {
+		return w.ProcessInterval
+	}
+
+	next := current * 2
+	if next > w.MaxProcessInterval {
+		next = w.MaxProcessInterval
+	}
+	return next
+}
+
+// withJitter adds the random jitter to the interval.
+func (w DepotWorker) withJitter(interval time.Duration) time.Duration {
+	if w.ProcessJitter <= 0 || interval <= 0 {
+		return interval
+	}
+	return interval + time.Duration(rand.Int63n(int64(float64(interval)*w.ProcessJitter)+1))
 }
 
 type DepotCleanupConfig struct {
@@ -77,6 +177,8 @@ type DepotCleanupConfig struct {
 // If it cannot handle a transaction, it will remain in the depot.
 type DeliveryCourier interface {
 	// DeliverTransaction should determine the transaction type and try to delivery it.
+	// If the node already knows the transaction, the signed transaction should be
+	// returned together with the error, so the delivery is tracked as sent.
 	DeliverTransaction(tx Delivery) (*types.Transaction, error)
 	// CanDeliver should validate the given type and see if the courier will be able to delivery it.
 	// If returned false, transaction will not be queued.
@@ -121,11 +223,50 @@ type DepotNonceTracker interface {
 	GetConfirmedNonce(chainID int64, account common.Address) (uint64, error)
 }
 
+// DepotReceiptClient is used to get the receipts of delivered transactions,
+// `client.MultichainBlockchainClient` satisfies it.
+type DepotReceiptClient interface {
+	TransactionReceipt(chainID int64, hash common.Hash) (*types.Receipt, error)
+}
+
+// DepotRecoveryClient is used to rebroadcast transactions which were lost while the depot
+// was not running, `client.MultichainBlockchainClient` satisfies it.
+type DepotRecoveryClient interface {
+	TransactionByHash(chainID int64, hash common.Hash) (*types.Transaction, bool, error)
+	SendTransaction(chainID int64, tx *types.Transaction) error
+}
+
+// DepotConfirmationTracker is an optional extension of the `DepotNonceTracker` which
+// is used to wait for confirmations and to find the block a delivery was confirmed at.
+type DepotConfirmationTracker interface {
+	// GetConfirmedNonceAtDepth returns the nonce confirmed the given number of blocks
+	// below the latest block, together with the number of that block.
+	GetConfirmedNonceAtDepth(chainID int64, account common.Address, depth uint64) (uint64, uint64, error)
+}
+
 var ErrImpossibleToDeliver = errors.New("impossible to deliver")
 
+// ErrMaxReplacementsReached is returned when a stuck transaction was replaced the max number of times.
+var ErrMaxReplacementsReached = errors.New("max replacements reached")
+
+// errRateLimited is returned when a transaction is not sent to stay within the rate limit of the chain.
+var errRateLimited = errors.New("rate limited")
+
+// errScheduled is returned for deliveries which are scheduled for later.
+var errScheduled = errors.New("delivery is scheduled for later")
+
+// ErrQueueFull is returned when the max number of non delivered transactions of a sender is reached.
+var ErrQueueFull = errors.New("delivery queue is full")
+
+// ErrDeliveryCancelled is returned when a delivery was cancelled after it was issued a nonce.
+var ErrDeliveryCancelled = errors.New("delivery was cancelled")
+
+// ErrWorkerExists is returned when adding a worker for a sender and chain which already has one.
+var ErrWorkerExists = errors.New("worker already exists")
+
 // NewDepot will returns a new depot.
 func NewDepot(handler DeliveryCourier, storage DepotStorage, nonce DepotNonceTracker, gasStation *GasTracker, cfg DepotConfig) *Depot {
-	return &Depot{
+	d := &Depot{
 		handler:      handler,
 		storage:      storage,
 		nonceTracker: nonce,
@@ -133,22 +274,106 @@ func NewDepot(handler DeliveryCourier, storage DepotStorage, nonce DepotNonceTra
 
 		logFn:   func(error) {},
 		metrics: &depotMetricsExporterNoop{},
+		tracing: newDepotTracing(otel.GetTracerProvider()),
 
-		config: cfg,
-		stop:   make(chan struct{}),
+		config:   cfg,
+		failures: make(map[string]*deliveryFailures),
+		unsent:   make(map[string]bool),
+		stop:     make(chan struct{}),
 	}
+	d.AttachIdempotencyStore(idempotency.NewMemoryStoreWithTTL(idempotency.DefaultMemoryTTL))
+	return d
 }
 
 // Run will spawn a goroutine for each loaded `DepotWorker`.
+// Workers are independent, so a stuck sender does not block the others
+// while nonces stay ordered per sender.
 func (d *Depot) Run() {
+	d.workersMu.Lock()
+	defer d.workersMu.Unlock()
+
+	d.running = true
 	for _, s := range d.config.Workers {
 		go d.watchDeliveries(s)
 	}
 }
 
+// AddWorker adds a worker for a new sender account. If the depot is
+// already running, its goroutine is spawned right away.
+func (d *Depot) AddWorker(w DepotWorker) error {
+	d.workersMu.Lock()
+	defer d.workersMu.Unlock()
+
+	for _, s := range d.config.Workers {
+		if s.Address == w.Address && s.ChainID == w.ChainID {
+			return fmt.Errorf("%w for sender %q on chain %d", ErrWorkerExists, w.Address.Hex(), w.ChainID)
+		}
+	}
+
+	d.config.Workers = append(d.config.Workers, w)
+	if d.running {
+		go d.watchDeliveries(w)
+	}
+	return nil
+}
+
+// Workers returns the workers of the depot.
+func (d *Depot) Workers() []DepotWorker {
+	d.workersMu.RLock()
+	defer d.workersMu.RUnlock()
+
+	return append([]DepotWorker(nil), d.config.Workers...)
+}
+
 // EnqueueDelivery will submit a new transaction to the delivery queue.
 // It will return a unique tracking number which can be used to see the status of a transaction.
+// It never waits for room in the queue, `ErrQueueFull` is returned right away once the max
+// number of non delivered transactions of the sender is reached. It does wait for the nonce
+// of the sender to be issued, which may take an RPC call.
 func (d *Depot) EnqueueDelivery(req DeliveryRequest, force bool) (string, error) {
+	return d.EnqueueDeliveryCtx(context.Background(), req, force)
+}
+
+// EnqueueDeliveryCtx is like `EnqueueDelivery`, but stops waiting for a nonce to be issued
+// once the context is done. A cancelled delivery is never queued and does not use up a nonce.
+// The nonce of a delivery which was already issued one can not be left unused, as the
+// following transactions of the sender would be stuck behind it. If the delivery was not
+// sent yet, it is cancelled instead: a `DeliverableCancelled` no-op is sent at its nonce
+// and its tracking number is returned together with `ErrDeliveryCancelled`. This requires
+// the courier to deliver `DeliverableCancelled`, otherwise the delivery is queued as usual.
+//
+// Requests with an `IdempotencyKey` are only queued once, retries with the same key get
+// the tracking number of the first request. Retries racing with an unfinished request
+// fail with `idempotency.ErrInProgress`.
+//
+// The sends and the confirmation wait of the delivery are traced as children of the span of the context.
+func (d *Depot) EnqueueDeliveryCtx(ctx context.Context, req DeliveryRequest, force bool) (string, error) {
+	ctx, span := d.tracing.enqueue(ctx, req)
+	defer span.End()
+
+	id, err := d.enqueueDeliveryOnce(ctx, req, force)
+	if err != nil {
+		client.RecordSpanError(span, err)
+	}
+	return id, err
+}
+
+func (d *Depot) enqueueDeliveryOnce(ctx context.Context, req DeliveryRequest, force bool) (string, error) {
+	if req.IdempotencyKey == "" {
+		return d.enqueueDelivery(ctx, req, force)
+	}
+
+	key := idempotency.Key(idempotency.OperationDelivery, req.IdempotencyKey)
+	return idempotency.Do(d.idempotency, key, func() (string, error) {
+		return d.enqueueDelivery(ctx, req, force)
+	})
+}
+
+func (d *Depot) enqueueDelivery(ctx context.Context, req DeliveryRequest, force bool) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("delivery was not queued: %w", err)
+	}
+
 	if !d.workerExists(req) {
 		return "", fmt.Errorf("failed to enqueue for sender %q on chain %q: no worker found", req.Sender.Hex(), req.ChainID)
 	}
@@ -162,27 +387,70 @@ func (d *Depot) EnqueueDelivery(req DeliveryRequest, force bool) (string, error)
 		return "", fmt.Errorf("could not get non delivered count: %w", err)
 	}
 
-	if !force && d.config.MaxNonDelivered <= count {
-		return "", fmt.Errorf("cannot queue a new entry, max count of %d reached", d.config.MaxNonDelivered)
+	if !force && req.Priority < DeliveryPriorityHigh && d.config.MaxNonDelivered <= count {
+		return "", fmt.Errorf("%w: cannot queue a new entry, max count of %d reached", ErrQueueFull, d.config.MaxNonDelivered)
 	}
 
+	// Once a nonce is issued the delivery is either abandoned or queued, never both.
+	var (
+		mu        sync.Mutex
+		abandoned bool
+		issued    bool
+	)
+
 	unqID := ""
+	var queued Delivery
 	setFn := func(nonce uint64) error {
+		mu.Lock()
+		if abandoned {
+			mu.Unlock()
+			return ctx.Err()
+		}
+		issued = true
+		mu.Unlock()
+
 		td, err := req.toDelivery(nonce)
 		if err != nil {
 			return fmt.Errorf("failed to marshal queue entry: %w", err)
 		}
 		unqID = td.UniqueID
+		queued = td
 
+		// Tracked before it is stored, so the workers can not send it unnoticed.
+		d.unsentMu.Lock()
+		d.unsent[td.UniqueID] = false
+		d.unsentMu.Unlock()
 		if err := d.storage.UpsertDeliveryRequest(td); err != nil {
+			d.unsentMu.Lock()
+			delete(d.unsent, td.UniqueID)
+			d.unsentMu.Unlock()
 			return fmt.Errorf("failed to insert a delivery request: %w", err)
 		}
 
 		d.metrics.DeliveryQueued(td)
+		d.tracing.queued(ctx, td)
 		return nil
 	}
 
-	err = d.nonceTracker.SetNextNonce(req.ChainID, req.Sender, setFn)
+	done := make(chan error, 1)
+	go func() {
+		done <- d.nonceTracker.SetNextNonce(req.ChainID, req.Sender, setFn)
+	}()
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		mu.Lock()
+		abandoned = !issued
+		mu.Unlock()
+		if abandoned {
+			return "", fmt.Errorf("delivery was not queued: %w", ctx.Err())
+		}
+		err = <-done
+		if err == nil && d.cancelUnsent(queued) {
+			return unqID, fmt.Errorf("%w: %w", ErrDeliveryCancelled, ctx.Err())
+		}
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to issue a nonce for a transaction delivery: %w", err)
 	}
@@ -190,6 +458,98 @@ func (d *Depot) EnqueueDelivery(req DeliveryRequest, force bool) (string, error)
 	return unqID, nil
 }
 
+// cancelUnsent cancels the delivery if it was not sent yet, returning false otherwise.
+func (d *Depot) cancelUnsent(td Delivery) bool {
+	if !d.handler.CanDeliver(DeliverableCancelled) {
+		return false
+	}
+
+	d.unsentMu.Lock()
+	defer d.unsentMu.Unlock()
+
+	if _, ok := d.unsent[td.UniqueID]; !ok {
+		return false
+	}
+	if err := d.storage.UpsertDeliveryRequest(td.cancelled()); err != nil {
+		d.log(fmt.Errorf("failed to cancel delivery %q: %w", td.UniqueID, err))
+		return false
+	}
+	d.unsent[td.UniqueID] = true
+	return true
+}
+
+// TryEnqueueDelivery will submit a new transaction to the delivery queue unless the queue of the
+// sender is full, in which case `ErrQueueFull` is returned right away. Unlike `EnqueueDelivery`
+// the limit applies to every request, high priority ones included, so callers holding locks
+// always get an error instead of growing the queue.
+func (d *Depot) TryEnqueueDelivery(req DeliveryRequest) (string, error) {
+	queued, limit, err := d.FillLevel(req.ChainID, req.Sender)
+	if err != nil {
+		return "", err
+	}
+	if queued >= limit {
+		return "", fmt.Errorf("%w: cannot queue a new entry, max count of %d reached", ErrQueueFull, limit)
+	}
+	return d.EnqueueDelivery(req, false)
+}
+
+// FillLevel returns the number of non delivered transactions of the sender and
+// the max number of them which can be queued.
+func (d *Depot) FillLevel(chainID int64, sender common.Address) (queued uint, limit uint, err error) {
+	queued, err = d.storage.GetNonDeliveredCount(chainID, sender)
+	if err != nil {
+		return 0, 0, fmt.Errorf("could not get non delivered count: %w", err)
+	}
+	return queued, d.config.MaxNonDelivered, nil
+}
+
+// EnqueueDeliveries will submit several transactions to the delivery queue, for example the dependent
+// transactions of a settlement. Transactions of the same sender are issued increasing nonces in the given
+// order. All of them are validated before any is queued. If queueing fails midway, the tracking numbers
+// of the transactions queued until then are returned together with the error.
+func (d *Depot) EnqueueDeliveries(reqs []DeliveryRequest, force bool) ([]string, error) {
+	perSender := make(map[Sender]uint)
+	for i, req := range reqs {
+		if !d.workerExists(req) {
+			return nil, fmt.Errorf("failed to enqueue delivery %d for sender %q on chain %d: no worker found", i, req.Sender.Hex(), req.ChainID)
+		}
+		if !d.handler.CanDeliver(req.Type) {
+			return nil, fmt.Errorf("delivery %d will not set in queue, not possible to delivery type %q", i, req.Type)
+		}
+		if force || req.Priority >= DeliveryPriorityHigh {
+			continue
+		}
+		perSender[NewSender(req.Sender, req.ChainID)]++
+	}
+
+	for sender, n := range perSender {
+		count, err := d.storage.GetNonDeliveredCount(sender.ChainID, sender.Address)
+		if err != nil {
+			return nil, fmt.Errorf("could not get non delivered count: %w", err)
+		}
+		if d.config.MaxNonDelivered < count+n {
+			return nil, fmt.Errorf("%w: cannot queue %d new entries for sender %q, max count of %d reached", ErrQueueFull, n, sender.Address.Hex(), d.config.MaxNonDelivered)
+		}
+	}
+
+	ids := make([]string, 0, len(reqs))
+	for i, req := range reqs {
+		id, err := d.EnqueueDelivery(req, true)
+		if err != nil {
+			return ids, fmt.Errorf("failed to enqueue delivery %d: %w", i, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// EnqueueDeliveryWithPriority will submit a new transaction with the given priority to the delivery queue.
+// High priority deliveries are queued even if the max number of non delivered transactions is reached.
+func (d *Depot) EnqueueDeliveryWithPriority(req DeliveryRequest, priority DeliveryPriority, force bool) (string, error) {
+	req.Priority = priority
+	return d.EnqueueDelivery(req, force)
+}
+
 // Stop will stop the Deposit goroutines.
 func (d *Depot) Stop() {
 	d.once.Do(func() {
@@ -206,6 +566,67 @@ func (d *Depot) AttachLogger(fn func(err error)) {
 	d.logFn = fn
 }
 
+// AddReplacedListener adds a listener which is called every time
+// a stuck transaction is replaced with a higher fee.
+//
+// This method is not thread safe and should be called before `Run`.
+func (d *Depot) AddReplacedListener(fn ReplacedListener) {
+	d.replacedListeners = append(d.replacedListeners, fn)
+}
+
+// SetRateLimits sets the max number of transactions sent per minute per chain, including
+// resends of stuck transactions. Transactions over the limit are sent on a later round.
+//
+// This method is not thread safe and should be called before `Run`.
+func (d *Depot) SetRateLimits(limits ChainRateLimits) {
+	d.limiter = newRateLimiter(limits)
+}
+
+// AttachReceiptClient allows the caller to attach a client used to get the
+// receipt of every delivered transaction, which is kept in `Delivery.Receipt`.
+//
+// This method is not thread safe and should be called before `Run`.
+func (d *Depot) AttachReceiptClient(c DepotReceiptClient) {
+	d.receipts = c
+}
+
+// AttachIdempotencyStore allows the caller to replace the in memory store which records
+// the tracking numbers of requests with an `IdempotencyKey`. A persistent store keeps
+// retries from queueing a request twice across restarts.
+//
+// This method is not thread safe and should be called before `Run`.
+func (d *Depot) AttachIdempotencyStore(store idempotency.Store) {
+	d.idempotency = idempotency.New(store)
+	d.idempotency.AttachLogger(func(err error) {
+		d.log(err)
+	})
+}
+
+// AttachRecoveryClient allows the caller to attach a client used to re-check the deliveries sent
+// before the depot was started, such as the ones recovered from a `JournalStorage` after a crash.
+// Sent transactions the node does not know anymore are rebroadcast as they were signed.
+//
+// This method is not thread safe and should be called before `Run`.
+func (d *Depot) AttachRecoveryClient(c DepotRecoveryClient) {
+	d.recovery = c
+}
+
+// AttachDeadLetterSink allows the caller to attach a sink which
+// receives deliveries that failed permanently.
+//
+// This method is not thread safe and should be called before `Run`.
+func (d *Depot) AttachDeadLetterSink(sink DeadLetterSink) {
+	d.deadLetters = sink
+}
+
+// AttachTracerProvider allows the caller to set the OpenTelemetry tracer provider of
+// the depot spans, the global one is used by default.
+//
+// This method is not thread safe and should be called before `Run`.
+func (d *Depot) AttachTracerProvider(tp trace.TracerProvider) {
+	d.tracing = newDepotTracing(tp)
+}
+
 // AttachMetricsReporter allows the caller to attach a custom metrics reporter
 // for state changes in the depot.
 func (d *Depot) AttachMetricsReporter(m DepotMetricsExporter) {
@@ -213,7 +634,7 @@ func (d *Depot) AttachMetricsReporter(m DepotMetricsExporter) {
 }
 
 func (d *Depot) workerExists(req DeliveryRequest) bool {
-	for _, s := range d.config.Workers {
+	for _, s := range d.Workers() {
 		if s.Address.Hex() == req.Sender.Hex() && req.ChainID == s.ChainID {
 			return true
 		}
@@ -223,25 +644,58 @@ func (d *Depot) workerExists(req DeliveryRequest) bool {
 }
 
 func (d *Depot) watchDeliveries(s DepotWorker) {
+	d.recoverInFlight(s)
+
+	interval := s.ProcessInterval
 	for {
 		select {
 		case <-d.stop:
 			return
-		case <-time.After(s.ProcessInterval):
+		case <-time.After(s.withJitter(interval)):
 			tds, err := d.storage.GetOrderedDeliveryRequests(s.ProcessCount, s.ChainID, s.Address)
 			if err != nil {
 				d.log(err)
 				break
 			}
 
+			// Idle rounds do not call the blockchain, so only back off while
+			// transactions are in flight without anything getting through.
+			progressed := len(tds) == 0
+			inheritPriority(tds)
+			inheritSchedule(tds)
 			for _, td := range tds {
 				select {
 				case <-d.stop:
 					return
 				default:
-					d.handleDeliveryRequest(td)
+					if d.handleDeliveryRequest(td) {
+						progressed = true
+					}
 				}
 			}
+			interval = s.nextInterval(interval, progressed)
+		}
+	}
+}
+
+// inheritPriority raises the priority of deliveries to the highest priority of the
+// deliveries queued after them. Transactions are mined in nonce order, so an urgent
+// delivery is only as fast as the transactions before it. As a result deliveries
+// ordered by nonce are also ordered by priority and higher priority ones are drained first.
+func inheritPriority(tds []Delivery) {
+	for i := len(tds) - 2; i >= 0; i-- {
+		if tds[i+1].Priority > tds[i].Priority {
+			tds[i].Priority = tds[i+1].Priority
+		}
+	}
+}
+
+// inheritSchedule delays deliveries to the latest schedule of the deliveries queued
+// before them, as a transaction cannot be mined before the ones with lower nonces.
+func inheritSchedule(tds []Delivery) {
+	for i := 1; i < len(tds); i++ {
+		if tds[i-1].NotBefore.After(tds[i].NotBefore) {
+			tds[i].NotBefore = tds[i-1].NotBefore
 		}
 	}
 }
@@ -267,7 +721,7 @@ func (d *Depot) startCleanupThread() {
 		case <-d.stop:
 			return
 		case <-time.After(d.cleanupConfig.CleanupInterval):
-			for _, worker := range d.config.Workers {
+			for _, worker := range d.Workers() {
 				var hours int64 = 24 * d.cleanupConfig.CleanupDaysLimit
 				olderThan := time.Now().UTC().Add(-time.Duration(hours) * time.Hour)
 				tds, err := d.storageCleaner.GetDeliveredDeliveryRequests(worker.ChainID, worker.Address, olderThan, d.cleanupConfig.CleanupLimit)
@@ -286,88 +740,289 @@ func (d *Depot) startCleanupThread() {
 	}
 }
 
-func (d *Depot) handleDeliveryRequest(td Delivery) {
+// handleDeliveryRequest handles the delivery and returns true if it was sent, resent or delivered.
+func (d *Depot) handleDeliveryRequest(td Delivery) bool {
+	var err error
+	progressed := false
 	switch td.State {
-	case DeliveryStateWaiting:
-		if err := d.handleWaiting(td); err != nil {
-			d.log(err)
-		}
+	case DeliveryStateWaiting, DeliveryStateCancelled:
+		err = d.handleWaiting(td)
+		progressed = err == nil
 	case DeliveryStatePacking, DeliveryStateSent:
-		if err := d.handleTracking(td); err != nil {
-			d.log(err)
-		}
+		progressed, err = d.handleTracking(td)
+	}
+	if errors.Is(err, errRateLimited) || errors.Is(err, errScheduled) || errors.Is(err, ErrAlreadyKnown) {
+		return progressed
 	}
+	if err == nil {
+		d.resetFailures(td)
+		return progressed
+	}
+
+	d.log(err)
+	if fe, ok := d.metrics.(DepotFailureExporter); ok {
+		fe.DeliveryFailed(td, err)
+	}
+	d.recordFailure(td, err)
+	return progressed
+}
+
+func (d *Depot) resetFailures(td Delivery) {
+	d.failuresMu.Lock()
+	defer d.failuresMu.Unlock()
+	delete(d.failures, td.UniqueID)
 }
 
-func (d *Depot) handleTracking(td Delivery) error {
+func (d *Depot) recordFailure(td Delivery, err error) {
+	if d.deadLetters == nil {
+		return
+	}
+
+	d.failuresMu.Lock()
+	f, ok := d.failures[td.UniqueID]
+	if !ok {
+		f = &deliveryFailures{}
+		d.failures[td.UniqueID] = f
+	}
+	f.count++
+	permanent := errors.Is(err, ErrImpossibleToDeliver) || errors.Is(err, ErrInsufficientFunds) ||
+		(d.config.MaxFailures > 0 && f.count >= d.config.MaxFailures)
+	deadLetter := permanent && !f.deadLettered
+	if deadLetter {
+		f.deadLettered = true
+	}
+	d.failuresMu.Unlock()
+
+	if !deadLetter {
+		return
+	}
+	if err := d.deadLetters.DeadLetter(td, err); err != nil {
+		d.log(fmt.Errorf("failed to dead letter delivery %q: %w", td.UniqueID, err))
+
+		// Try again on the next failure.
+		d.failuresMu.Lock()
+		f.deadLettered = false
+		d.failuresMu.Unlock()
+	}
+}
+
+// handleTracking tracks a packing or sent delivery, it returns true if the delivery was delivered or resent.
+func (d *Depot) handleTracking(td Delivery) (bool, error) {
 	currentNonce, err := d.nonceTracker.GetConfirmedNonce(td.ChainID, td.Sender)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	// If our nonce increased that means someone is aware about this transaction
 	// and we can continue.
 	if currentNonce > td.Nonce {
 		if td.State == DeliveryStateWaiting {
-			return fmt.Errorf("refusing to confirm transaction as it was never sent from our side: %q", td.UniqueID)
+			return false, fmt.Errorf("refusing to confirm transaction as it was never sent from our side: %q", td.UniqueID)
+		}
+
+		confirmed, err := d.confirmAtDepth(&td)
+		if err != nil {
+			return false, fmt.Errorf("failed to check confirmations: %w", err)
+		}
+		// The transaction was included, but not deep enough yet. It must not be resent.
+		if !confirmed {
+			return false, nil
 		}
 
+		d.attachReceipt(&td)
 		td, err = d.markDeliveryAsDelivered(td)
 		if err != nil {
-			return fmt.Errorf("failed to mark delivery as sent: %w", err)
+			return false, fmt.Errorf("failed to mark delivery as sent: %w", err)
 		}
 
 		d.metrics.DeliveryReceived(td)
-		return nil
+		d.tracing.delivered(td)
+		return true, nil
 	}
 
 	// Only try to resubmit the earliest transaction sent.
 	// Other transactions might have enough gas and this
 	// might be the only blocking transaction.
 	if currentNonce != td.Nonce {
-		return nil
+		return false, nil
 	}
 
 	// If state is packing, reset the gas price and resend the transaction
 	// as we do not know if it was ever sent out.
 	if td.State == DeliveryStatePacking {
+		if !d.limiter.allow(td.ChainID) {
+			return false, errRateLimited
+		}
 		d.log(fmt.Errorf("got transaction in packing state %q, will retry", td.UniqueID))
 		updated, err := d.calculateNewGasPrice(td)
 		if err != nil {
-			return err
+			return false, err
 		}
 		_, err = d.sendOutTransaction(updated)
 		if err != nil {
-			return fmt.Errorf("failed to send packing tx: %w", err)
+			return false, fmt.Errorf("failed to send packing tx: %w", err)
 		}
-		return nil
+		return true, nil
+	}
+
+	if d.config.MaxReplacements > 0 && td.Replacements >= d.config.MaxReplacements {
+		return false, fmt.Errorf("%w for %q, waiting for it to be mined", ErrMaxReplacementsReached, td.UniqueID)
+	}
+
+	// Check before the new gas price is stored, so it is only stored if it will be sent.
+	if !d.limiter.available(td.ChainID) {
+		return false, errRateLimited
 	}
 
 	updated, err := d.calculateNewGasPrice(td)
 	if err != nil {
 		if errors.Is(err, errMaxPriceReached) && d.shouldForceResend(td) {
-			_, err = d.sendOutTransaction(updated)
+			err = d.replaceTransaction(updated)
 			if err != nil {
-				return fmt.Errorf("failed to force resend: %w", err)
+				return false, fmt.Errorf("failed to force resend: %w", err)
 			}
-			return nil
+			return true, nil
 		}
-		return err
+		return false, err
 	}
 
 	if updated.GasTip.Cmp(td.GasTip) > 0 {
-		_, err = d.sendOutTransaction(updated)
+		err = d.replaceTransaction(updated)
 		if err != nil {
-			return fmt.Errorf("failed to resend with new gas tip: %w", err)
+			return false, fmt.Errorf("failed to resend with new gas tip: %w", err)
 		}
-		return nil
+		return true, nil
+	}
+	return false, nil
+}
+
+func (d *Depot) replaceTransaction(td Delivery) error {
+	if !d.limiter.allow(td.ChainID) {
+		return errRateLimited
+	}
+
+	replaced := td.LastTransactionHash()
+	td.Replacements++
+
+	td, err := d.sendOutTransaction(td)
+	if err != nil {
+		return err
+	}
+
+	for _, fn := range d.replacedListeners {
+		fn(td, replaced)
 	}
 	return nil
 }
 
+// recoverInFlight rebroadcasts the sent transactions of the worker which are neither
+// confirmed nor known by the node, so they are not left waiting until a fee bump.
+// Packing deliveries are not checked, they are always resent by `handleTracking`.
+func (d *Depot) recoverInFlight(s DepotWorker) {
+	if d.recovery == nil {
+		return
+	}
+
+	tds, err := d.storage.GetOrderedDeliveryRequests(s.ProcessCount, s.ChainID, s.Address)
+	if err != nil {
+		d.log(fmt.Errorf("failed to get deliveries to recover: %w", err))
+		return
+	}
+	if len(tds) == 0 {
+		return
+	}
+
+	confirmed, err := d.nonceTracker.GetConfirmedNonce(s.ChainID, s.Address)
+	if err != nil {
+		d.log(fmt.Errorf("failed to get confirmed nonce to recover deliveries: %w", err))
+		return
+	}
+
+	for _, td := range tds {
+		if td.State != DeliveryStateSent || td.Nonce < confirmed {
+			continue
+		}
+
+		tx, err := td.GetLastTransaction()
+		if err != nil {
+			d.log(fmt.Errorf("failed to decode transaction of delivery %q: %w", td.UniqueID, err))
+			continue
+		}
+
+		_, _, err = d.recovery.TransactionByHash(td.ChainID, tx.Hash())
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			d.log(fmt.Errorf("failed to check transaction %q of delivery %q: %w", tx.Hash().Hex(), td.UniqueID, err))
+			continue
+		}
+
+		if !d.limiter.allow(td.ChainID) {
+			return
+		}
+		err = ClassifySendError(d.recovery.SendTransaction(td.ChainID, tx))
+		if err != nil && !errors.Is(err, ErrAlreadyKnown) && !errors.Is(err, ErrNonceTooLow) {
+			d.log(fmt.Errorf("failed to rebroadcast transaction %q of delivery %q: %w", tx.Hash().Hex(), td.UniqueID, err))
+		}
+	}
+}
+
+// attachReceipt sets the receipt of the last sent transaction. A receipt might not be found
+// if an earlier version of a replaced transaction was mined, the delivery is delivered anyway.
+func (d *Depot) attachReceipt(td *Delivery) {
+	if d.receipts == nil {
+		return
+	}
+
+	hash := td.LastTransactionHash()
+	receipt, err := d.receipts.TransactionReceipt(td.ChainID, hash)
+	if err != nil {
+		d.log(fmt.Errorf("failed to get receipt %q of delivery %q: %w", hash.Hex(), td.UniqueID, err))
+		return
+	}
+	td.Receipt = newDeliveryReceipt(receipt)
+}
+
+// confirmAtDepth checks that the delivery nonce is confirmed at the configured depth
+// and sets the block it was confirmed at.
+func (d *Depot) confirmAtDepth(td *Delivery) (bool, error) {
+	ct, ok := d.nonceTracker.(DepotConfirmationTracker)
+	if !ok {
+		if d.config.Confirmations > 0 {
+			return false, errors.New("nonce tracker does not support confirmations")
+		}
+		return true, nil
+	}
+
+	nonce, block, err := ct.GetConfirmedNonceAtDepth(td.ChainID, td.Sender, d.config.Confirmations)
+	if err != nil {
+		return false, err
+	}
+	if nonce <= td.Nonce {
+		return false, nil
+	}
+
+	td.ConfirmedBlock = block
+	return true, nil
+}
+
 func (d *Depot) handleWaiting(td Delivery) error {
-	var err error
-	td, err = d.markDeliveryAsPacking(td)
+	if time.Now().Before(td.NotBefore) {
+		return errScheduled
+	}
+	if !d.limiter.allow(td.ChainID) {
+		return errRateLimited
+	}
+
+	// The delivery read by the worker does not know about a cancellation which happened since.
+	d.unsentMu.Lock()
+	if d.unsent[td.UniqueID] {
+		td = td.cancelled()
+	}
+	delete(d.unsent, td.UniqueID)
+	td, err := d.markDeliveryAsPacking(td)
+	d.unsentMu.Unlock()
 	if err != nil {
 		return fmt.Errorf("failed to mark packing for sender %q reason: %w", td.Sender.Hex(), err)
 	}
@@ -386,25 +1041,71 @@ func (d *Depot) handleWaiting(td Delivery) error {
 	return nil
 }
 
+// nonceReloader is implemented by nonce trackers which cache nonces, such as `NonceTracker`.
+type nonceReloader interface {
+	ForceReloadNonce(chainID int64, account common.Address)
+}
+
 func (d *Depot) sendOutTransaction(td Delivery) (Delivery, error) {
+	span := d.tracing.send(td)
+	defer span.End()
+
+	td, tx, err := d.deliverTransaction(td)
+	if err != nil {
+		client.RecordSpanError(span, err)
+		return td, err
+	}
+	d.tracing.sent(td, span, tx)
+	return td, nil
+}
+
+func (d *Depot) deliverTransaction(td Delivery) (Delivery, *types.Transaction, error) {
 	tx, err := d.handler.DeliverTransaction(td)
+	err = ClassifySendError(err)
+	if errors.Is(err, ErrReplacementUnderpriced) {
+		var bumped Delivery
+		bumped, err = d.bumpGasPrice(td)
+		if err == nil {
+			td = bumped
+			tx, err = d.handler.DeliverTransaction(td)
+			err = ClassifySendError(err)
+		}
+	}
+	if errors.Is(err, ErrAlreadyKnown) && tx != nil {
+		// The node has the very same transaction in its mempool, it was sent.
+		err = nil
+	}
 	if err != nil {
-		return td, fmt.Errorf("attempted to delivery a transaction %q for account %q but failed: %w", td.UniqueID, td.Sender.Hex(), err)
+		if errors.Is(err, ErrNonceTooLow) {
+			if nr, ok := d.nonceTracker.(nonceReloader); ok {
+				nr.ForceReloadNonce(td.ChainID, td.Sender)
+			}
+		}
+		return td, nil, fmt.Errorf("attempted to delivery a transaction %q for account %q but failed: %w", td.UniqueID, td.Sender.Hex(), err)
 	}
 
 	td, err = d.markDeliveryAsSent(td, tx)
 	if err != nil {
-		return td, fmt.Errorf("failed to mark delivery as sent: %w", err)
+		return td, nil, fmt.Errorf("failed to mark delivery as sent: %w", err)
 	}
 
-	return td, nil
+	return td, tx, nil
+}
+
+// bumpGasPrice increases the gas price of the delivery right away, without waiting for the increase interval.
+func (d *Depot) bumpGasPrice(td Delivery) (Delivery, error) {
+	newPrice, err := d.gasStation.RecalculateDeliveryGasWithPriority(td.ChainID, td.GasTip, td.Type, td.Priority)
+	if err != nil {
+		return td, fmt.Errorf("failed to bump underpriced transaction: %w", err)
+	}
+	return d.deliveryUpdateGasPrice(td, newPrice)
 }
 
 func (d *Depot) calculateNewGasPrice(td Delivery) (Delivery, error) {
 	var newPrice *fees
 	switch td.State {
 	case DeliveryStatePacking, DeliveryStateWaiting:
-		gasPrice, err := d.gasStation.ReceiveInitialGas(td.ChainID, td.Type)
+		gasPrice, err := d.gasStation.ReceiveInitialGasWithPriority(td.ChainID, td.Type, td.Priority)
 		if err != nil {
 			return Delivery{}, err
 		}
@@ -430,7 +1131,7 @@ func (d *Depot) calculateNewGasPrice(td Delivery) (Delivery, error) {
 			return td, nil
 		}
 
-		gasPrice, err := d.gasStation.RecalculateDeliveryGas(td.ChainID, td.GasTip, td.Type)
+		gasPrice, err := d.gasStation.RecalculateDeliveryGasWithPriority(td.ChainID, td.GasTip, td.Type, td.Priority)
 		if err != nil {
 			if errors.Is(errMaxPriceReached, err) {
 				return td, err
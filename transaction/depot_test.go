@@ -1,17 +1,24 @@
 package transaction
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/mysteriumnetwork/payments/client"
 	"github.com/mysteriumnetwork/payments/transaction/gas"
 	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 const chainId = 1
@@ -439,6 +446,801 @@ func TestDepot(t *testing.T) {
 
 }
 
+func TestWorkersForChains(t *testing.T) {
+	sender := common.HexToAddress("0x1")
+	workers := WorkersForChains(sender, []int64{1, 137}, DepotWorker{ProcessInterval: time.Second, ProcessCount: 5})
+
+	assert.Equal(t, []DepotWorker{
+		{Address: sender, ChainID: 1, ProcessInterval: time.Second, ProcessCount: 5},
+		{Address: sender, ChainID: 137, ProcessInterval: time.Second, ProcessCount: 5},
+	}, workers)
+}
+
+func TestDepotAddWorker(t *testing.T) {
+	first := common.HexToAddress("0x1")
+	second := common.HexToAddress("0x2")
+
+	storage := &mockStorage{deliveries: []Delivery{}}
+	nonces := &mockNonceTracker{nonces: make(map[string]uint64), confirmAll: true}
+	price := big.NewInt(1)
+	gasTracker := NewGasTracker(&mockGasStation{defaultPrice: price, defaultBaseFee: price}, map[int64]GasIncreaseOpts{
+		chainId: {Multiplier: 1.1, PriceLimit: big.NewInt(1000), IncreaseInterval: time.Second},
+	}, GasTrackerSpeedMedium)
+	worker := func(addr common.Address) DepotWorker {
+		return DepotWorker{Address: addr, ChainID: chainId, ProcessInterval: 10 * time.Millisecond, ProcessCount: 3}
+	}
+
+	depot := NewDepot(&mockCourier{lastDeliveredNonce: -1}, storage, nonces, gasTracker, DepotConfig{
+		MaxNonDelivered: 5,
+		Workers:         []DepotWorker{worker(first)},
+	})
+	depot.Run()
+	defer depot.Stop()
+
+	req := DeliveryRequest{ChainID: chainId, Sender: second, Type: "test"}
+	_, err := depot.EnqueueDelivery(req, false)
+	assert.Error(t, err)
+
+	assert.ErrorIs(t, depot.AddWorker(worker(first)), ErrWorkerExists)
+	assert.NoError(t, depot.AddWorker(worker(second)))
+	assert.Len(t, depot.Workers(), 2)
+
+	_, err = depot.EnqueueDelivery(req, false)
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return storage.get(0).State == DeliveryStateDelivered
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestInheritPriority(t *testing.T) {
+	tds := []Delivery{
+		{Nonce: 0, Priority: DeliveryPriorityLow},
+		{Nonce: 1, Priority: DeliveryPriorityLow},
+		{Nonce: 2, Priority: DeliveryPriorityHigh},
+		{Nonce: 3, Priority: DeliveryPriorityLow},
+		{Nonce: 4, Priority: DeliveryPriorityNormal},
+	}
+	inheritPriority(tds)
+
+	got := make([]DeliveryPriority, 0, len(tds))
+	for _, td := range tds {
+		got = append(got, td.Priority)
+	}
+	assert.Equal(t, []DeliveryPriority{
+		DeliveryPriorityHigh,
+		DeliveryPriorityHigh,
+		DeliveryPriorityHigh,
+		DeliveryPriorityNormal,
+		DeliveryPriorityNormal,
+	}, got)
+}
+
+func TestDepotEnqueueDeliveryWithPriority(t *testing.T) {
+	sender := common.HexToAddress("0x1")
+	storage := &mockStorage{deliveries: []Delivery{}}
+	nonces := &mockNonceTracker{nonces: make(map[string]uint64)}
+	depot := NewDepot(&mockCourier{lastDeliveredNonce: -1}, storage, nonces, nil, DepotConfig{
+		MaxNonDelivered: 1,
+		Workers:         []DepotWorker{{Address: sender, ChainID: chainId}},
+	})
+
+	req := DeliveryRequest{ChainID: chainId, Sender: sender, Type: "test"}
+	_, err := depot.EnqueueDeliveryWithPriority(req, DeliveryPriorityLow, false)
+	assert.NoError(t, err)
+	_, err = depot.EnqueueDeliveryWithPriority(req, DeliveryPriorityNormal, false)
+	assert.Error(t, err)
+	_, err = depot.EnqueueDeliveryWithPriority(req, DeliveryPriorityHigh, false)
+	assert.NoError(t, err)
+
+	assert.Equal(t, DeliveryPriorityLow, storage.get(0).Priority)
+	assert.Equal(t, DeliveryPriorityHigh, storage.get(1).Priority)
+}
+
+func TestDepotMaxReplacements(t *testing.T) {
+	sender := common.HexToAddress("0x1")
+	storage := &mockStorage{deliveries: []Delivery{}}
+	nonces := &mockNonceTracker{nonces: make(map[string]uint64), confirmNone: true}
+	price := big.NewInt(100)
+	gasTracker := NewGasTracker(&mockGasStation{defaultPrice: price, defaultBaseFee: price}, map[int64]GasIncreaseOpts{
+		chainId: {Multiplier: 2, PriceLimit: big.NewInt(100000), IncreaseInterval: time.Millisecond},
+	}, GasTrackerSpeedMedium)
+	courier := &mockCourier{lastDeliveredNonce: -1}
+	depot := NewDepot(courier, storage, nonces, gasTracker, DepotConfig{
+		MaxNonDelivered: 5,
+		MaxReplacements: 2,
+		Workers:         []DepotWorker{{Address: sender, ChainID: chainId, ProcessInterval: 5 * time.Millisecond, ProcessCount: 1}},
+	})
+
+	var lock sync.Mutex
+	replaced := make([]common.Hash, 0)
+	depot.AddReplacedListener(func(td Delivery, old common.Hash) {
+		lock.Lock()
+		defer lock.Unlock()
+		assert.NotEqual(t, old, td.LastTransactionHash())
+		replaced = append(replaced, old)
+	})
+	depot.Run()
+	defer depot.Stop()
+
+	_, err := depot.EnqueueDelivery(DeliveryRequest{ChainID: chainId, Sender: sender, Type: "test"}, false)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return courier.getCalls() == 3
+	}, 2*time.Second, 5*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, uint64(3), courier.getCalls())
+
+	td := storage.get(0)
+	assert.Equal(t, uint(2), td.Replacements)
+	assert.Equal(t, big.NewInt(400), td.GasTip)
+
+	lock.Lock()
+	defer lock.Unlock()
+	assert.Len(t, replaced, 2)
+	assert.NotEqual(t, replaced[0], replaced[1])
+}
+
+type blockingNonceTracker struct {
+	mockNonceTracker
+	release chan struct{}
+}
+
+func (b *blockingNonceTracker) SetNextNonce(chainID int64, account common.Address, fn nonceSetFn) error {
+	<-b.release
+	return b.mockNonceTracker.SetNextNonce(chainID, account, fn)
+}
+
+func TestDepotEnqueueDeliveryCtx(t *testing.T) {
+	sender := common.HexToAddress("0x1")
+	storage := &mockStorage{deliveries: []Delivery{}}
+	nonces := &blockingNonceTracker{
+		mockNonceTracker: mockNonceTracker{nonces: make(map[string]uint64)},
+		release:          make(chan struct{}),
+	}
+	depot := NewDepot(&mockCourier{lastDeliveredNonce: -1}, storage, nonces, nil, DepotConfig{
+		MaxNonDelivered: 5,
+		Workers:         []DepotWorker{{Address: sender, ChainID: chainId}},
+	})
+	req := DeliveryRequest{ChainID: chainId, Sender: sender, Type: "test"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := depot.EnqueueDeliveryCtx(ctx, req, false)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = depot.EnqueueDeliveryCtx(ctx, req, false)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(nonces.release)
+	id, err := depot.EnqueueDeliveryCtx(context.Background(), req, false)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, storage.length())
+	assert.Equal(t, id, storage.get(0).UniqueID)
+	assert.Equal(t, uint64(0), storage.get(0).Nonce)
+}
+
+// slowNonceTracker issues the nonce right away but returns once released.
+type slowNonceTracker struct {
+	mockNonceTracker
+	issued  chan struct{}
+	release chan struct{}
+}
+
+func (s *slowNonceTracker) SetNextNonce(chainID int64, account common.Address, fn nonceSetFn) error {
+	err := s.mockNonceTracker.SetNextNonce(chainID, account, fn)
+	s.issued <- struct{}{}
+	<-s.release
+	return err
+}
+
+func TestDepotCancelsIssuedDelivery(t *testing.T) {
+	sender := common.HexToAddress("0x1")
+	storage := &mockStorage{deliveries: []Delivery{}}
+	nonces := &slowNonceTracker{
+		mockNonceTracker: mockNonceTracker{nonces: make(map[string]uint64)},
+		issued:           make(chan struct{}),
+		release:          make(chan struct{}),
+	}
+	price := big.NewInt(10)
+	gasTracker := NewGasTracker(&mockGasStation{defaultPrice: price, defaultBaseFee: price}, map[int64]GasIncreaseOpts{
+		chainId: {Multiplier: 2, PriceLimit: big.NewInt(1000), IncreaseInterval: time.Hour},
+	}, GasTrackerSpeedMedium)
+	depot := NewDepot(&mockCourier{lastDeliveredNonce: -1}, storage, nonces, gasTracker, DepotConfig{
+		MaxNonDelivered: 5,
+		Workers:         []DepotWorker{{Address: sender, ChainID: chainId}},
+	})
+	req := DeliveryRequest{ChainID: chainId, Sender: sender, Type: "test", Data: mockData{Data: "payout"}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	type result struct {
+		id  string
+		err error
+	}
+	done := make(chan result)
+	go func() {
+		id, err := depot.EnqueueDeliveryCtx(ctx, req, false)
+		done <- result{id, err}
+	}()
+	<-nonces.issued
+	// The worker read the delivery before it was cancelled.
+	stale := storage.get(0)
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	close(nonces.release)
+
+	res := <-done
+	assert.ErrorIs(t, res.err, ErrDeliveryCancelled)
+	assert.ErrorIs(t, res.err, context.Canceled)
+	assert.Equal(t, stale.UniqueID, res.id)
+	assert.Equal(t, DeliveryState(DeliveryStateCancelled), storage.get(0).State)
+	assert.Equal(t, DeliverableCancelled, storage.get(0).Type)
+	assert.Equal(t, uint64(0), storage.get(0).Nonce)
+
+	assert.True(t, depot.handleDeliveryRequest(stale))
+	assert.Equal(t, DeliveryState(DeliveryStateSent), storage.get(0).State)
+	assert.Equal(t, DeliverableCancelled, storage.get(0).Type)
+	assert.Empty(t, storage.get(0).ShipmentData)
+
+	t.Run("sent deliveries are not cancelled", func(t *testing.T) {
+		nonces.release = make(chan struct{})
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			id, err := depot.EnqueueDeliveryCtx(ctx, req, false)
+			done <- result{id, err}
+		}()
+		<-nonces.issued
+		assert.True(t, depot.handleDeliveryRequest(storage.get(1)))
+		cancel()
+		time.Sleep(10 * time.Millisecond)
+		close(nonces.release)
+
+		res := <-done
+		assert.NoError(t, res.err)
+		assert.Equal(t, storage.get(1).UniqueID, res.id)
+		assert.Equal(t, DeliverableType("test"), storage.get(1).Type)
+	})
+}
+
+type confirmationNonceTracker struct {
+	mockNonceTracker
+	head       uint64
+	includedAt uint64
+}
+
+func (c *confirmationNonceTracker) setHead(head uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.head = head
+}
+
+func (c *confirmationNonceTracker) GetConfirmedNonceAtDepth(_ int64, _ common.Address, depth uint64) (uint64, uint64, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	block := c.head - depth
+	if block < c.includedAt {
+		return 0, block, nil
+	}
+	return 1, block, nil
+}
+
+func TestDepotConfirmations(t *testing.T) {
+	sender := common.HexToAddress("0x1")
+	storage := &mockStorage{deliveries: []Delivery{}}
+	nonces := &confirmationNonceTracker{
+		mockNonceTracker: mockNonceTracker{nonces: make(map[string]uint64), confirmAll: true},
+		head:             11,
+		includedAt:       10,
+	}
+	price := big.NewInt(1)
+	gasTracker := NewGasTracker(&mockGasStation{defaultPrice: price, defaultBaseFee: price}, map[int64]GasIncreaseOpts{
+		chainId: {Multiplier: 2, PriceLimit: big.NewInt(1000), IncreaseInterval: time.Millisecond},
+	}, GasTrackerSpeedMedium)
+	courier := &mockCourier{lastDeliveredNonce: -1}
+	depot := NewDepot(courier, storage, nonces, gasTracker, DepotConfig{
+		MaxNonDelivered: 5,
+		Confirmations:   3,
+		Workers:         []DepotWorker{{Address: sender, ChainID: chainId, ProcessInterval: 5 * time.Millisecond, ProcessCount: 1}},
+	})
+	depot.Run()
+	defer depot.Stop()
+
+	_, err := depot.EnqueueDelivery(DeliveryRequest{ChainID: chainId, Sender: sender, Type: "test"}, false)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return storage.get(0).State == DeliveryStateSent
+	}, 2*time.Second, 5*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, DeliveryState(DeliveryStateSent), storage.get(0).State)
+	assert.Equal(t, uint64(1), courier.getCalls(), "included transactions are not resent")
+
+	nonces.setHead(13)
+	assert.Eventually(t, func() bool {
+		return storage.get(0).State == DeliveryStateDelivered
+	}, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, uint64(10), storage.get(0).ConfirmedBlock)
+}
+
+type failingCourier struct {
+	mockCourier
+}
+
+func (f *failingCourier) DeliverTransaction(Delivery) (*types.Transaction, error) {
+	return nil, errors.New("nonce too low")
+}
+
+type observerMock struct {
+	depotMetricsExporterNoop
+	lock   sync.Mutex
+	queued int
+	failed []error
+}
+
+func (o *observerMock) DeliveryQueued(Delivery) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.queued++
+}
+
+func (o *observerMock) DeliveryFailed(_ Delivery, err error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.failed = append(o.failed, err)
+}
+
+func (o *observerMock) failures() int {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return len(o.failed)
+}
+
+func TestDepotFailureExporter(t *testing.T) {
+	sender := common.HexToAddress("0x1")
+	storage := &mockStorage{deliveries: []Delivery{}}
+	nonces := &mockNonceTracker{nonces: make(map[string]uint64)}
+	price := big.NewInt(1)
+	gasTracker := NewGasTracker(&mockGasStation{defaultPrice: price, defaultBaseFee: price}, map[int64]GasIncreaseOpts{
+		chainId: {Multiplier: 2, PriceLimit: big.NewInt(1000), IncreaseInterval: time.Second},
+	}, GasTrackerSpeedMedium)
+	depot := NewDepot(&failingCourier{}, storage, nonces, gasTracker, DepotConfig{
+		MaxNonDelivered: 5,
+		Workers:         []DepotWorker{{Address: sender, ChainID: chainId, ProcessInterval: 5 * time.Millisecond, ProcessCount: 1}},
+	})
+	observer := &observerMock{}
+	depot.AttachMetricsReporter(observer)
+	depot.Run()
+	defer depot.Stop()
+
+	_, err := depot.EnqueueDelivery(DeliveryRequest{ChainID: chainId, Sender: sender, Type: "test"}, false)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return observer.failures() > 0
+	}, 2*time.Second, 5*time.Millisecond)
+
+	observer.lock.Lock()
+	defer observer.lock.Unlock()
+	assert.Equal(t, 1, observer.queued)
+	assert.ErrorContains(t, observer.failed[0], "nonce too low")
+}
+
+func TestDepotEnqueueDeliveries(t *testing.T) {
+	first := common.HexToAddress("0x1")
+	second := common.HexToAddress("0x2")
+	storage := &mockStorage{deliveries: []Delivery{}}
+	nonces := &mockNonceTracker{nonces: make(map[string]uint64)}
+	depot := NewDepot(&mockCourier{lastDeliveredNonce: -1}, storage, nonces, nil, DepotConfig{
+		MaxNonDelivered: 3,
+		Workers: []DepotWorker{
+			{Address: first, ChainID: chainId},
+			{Address: second, ChainID: chainId},
+		},
+	})
+
+	req := func(sender common.Address) DeliveryRequest {
+		return DeliveryRequest{ChainID: chainId, Sender: sender, Type: "test"}
+	}
+
+	ids, err := depot.EnqueueDeliveries([]DeliveryRequest{req(first), req(second), req(first)}, false)
+	assert.NoError(t, err)
+	assert.Len(t, ids, 3)
+	assert.Equal(t, 3, storage.length())
+	for i, nonce := range []uint64{0, 0, 1} {
+		assert.Equal(t, ids[i], storage.get(i).UniqueID)
+		assert.Equal(t, nonce, storage.get(i).Nonce)
+	}
+
+	_, err = depot.EnqueueDeliveries([]DeliveryRequest{req(second), req(first), req(first)}, false)
+	assert.Error(t, err)
+	assert.Equal(t, 3, storage.length(), "nothing is queued if any of the deliveries cannot be")
+
+	_, err = depot.EnqueueDeliveries([]DeliveryRequest{req(second), {ChainID: 2, Sender: first}}, false)
+	assert.Error(t, err)
+	assert.Equal(t, 3, storage.length())
+}
+
+type deadLetterMock struct {
+	lock  sync.Mutex
+	calls int
+	err   error
+}
+
+func (m *deadLetterMock) DeadLetter(_ Delivery, err error) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.calls++
+	m.err = err
+	return nil
+}
+
+func (m *deadLetterMock) get() (int, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.calls, m.err
+}
+
+func TestDepotDeadLetters(t *testing.T) {
+	sender := common.HexToAddress("0x1")
+	storage := &mockStorage{deliveries: []Delivery{}}
+	nonces := &mockNonceTracker{nonces: make(map[string]uint64)}
+	depot := NewDepot(&failingCourier{}, storage, nonces, nil, DepotConfig{
+		MaxFailures: 3,
+		Workers:     []DepotWorker{{Address: sender, ChainID: chainId}},
+	})
+	sink := &deadLetterMock{}
+	depot.AttachDeadLetterSink(sink)
+
+	td := Delivery{UniqueID: "1", Sender: sender, ChainID: chainId, State: DeliveryStatePacking, ShipmentData: []byte("{}")}
+	failure := errors.New("nonce too low")
+	for i := 0; i < 5; i++ {
+		depot.recordFailure(td, failure)
+	}
+	calls, err := sink.get()
+	assert.Equal(t, 1, calls, "dead lettered once after max failures")
+	assert.Equal(t, failure, err)
+
+	depot.resetFailures(td)
+	depot.recordFailure(td, fmt.Errorf("bad payload: %w", ErrImpossibleToDeliver))
+	calls, err = sink.get()
+	assert.Equal(t, 2, calls, "permanent errors are dead lettered right away")
+	assert.ErrorIs(t, err, ErrImpossibleToDeliver)
+}
+
+func TestDepotWorkerInterval(t *testing.T) {
+	w := DepotWorker{ProcessInterval: time.Second, MaxProcessInterval: 5 * time.Second}
+
+	interval := w.ProcessInterval
+	got := make([]time.Duration, 0)
+	for i := 0; i < 4; i++ {
+		interval = w.nextInterval(interval, false)
+		got = append(got, interval)
+	}
+	assert.Equal(t, []time.Duration{2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}, got)
+	assert.Equal(t, time.Second, w.nextInterval(interval, true))
+
+	w.MaxProcessInterval = 0
+	assert.Equal(t, time.Second, w.nextInterval(time.Second, false), "no backoff without a max")
+
+	assert.Equal(t, time.Second, w.withJitter(time.Second))
+	w.ProcessJitter = 0.1
+	for i := 0; i < 100; i++ {
+		j := w.withJitter(time.Second)
+		assert.GreaterOrEqual(t, j, time.Second)
+		assert.LessOrEqual(t, j, 1100*time.Millisecond)
+	}
+}
+
+var _ DepotReceiptClient = (*client.MultichainBlockchainClient)(nil)
+
+type receiptClientMock struct{}
+
+func (r *receiptClientMock) TransactionReceipt(_ int64, hash common.Hash) (*types.Receipt, error) {
+	return &types.Receipt{
+		TxHash:            hash,
+		Status:            types.ReceiptStatusFailed,
+		GasUsed:           21000,
+		EffectiveGasPrice: big.NewInt(2),
+		BlockNumber:       big.NewInt(10),
+	}, nil
+}
+
+func TestDepotReceipts(t *testing.T) {
+	sender := common.HexToAddress("0x1")
+	storage := &mockStorage{deliveries: []Delivery{}}
+	nonces := &mockNonceTracker{nonces: make(map[string]uint64), confirmAll: true}
+	price := big.NewInt(1)
+	gasTracker := NewGasTracker(&mockGasStation{defaultPrice: price, defaultBaseFee: price}, map[int64]GasIncreaseOpts{
+		chainId: {Multiplier: 2, PriceLimit: big.NewInt(1000), IncreaseInterval: time.Second},
+	}, GasTrackerSpeedMedium)
+	depot := NewDepot(&mockCourier{lastDeliveredNonce: -1}, storage, nonces, gasTracker, DepotConfig{
+		MaxNonDelivered: 5,
+		Workers:         []DepotWorker{{Address: sender, ChainID: chainId, ProcessInterval: 5 * time.Millisecond, ProcessCount: 1}},
+	})
+	depot.AttachReceiptClient(&receiptClientMock{})
+	depot.Run()
+	defer depot.Stop()
+
+	_, err := depot.EnqueueDelivery(DeliveryRequest{ChainID: chainId, Sender: sender, Type: "test"}, false)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return storage.get(0).State == DeliveryStateDelivered
+	}, 2*time.Second, 5*time.Millisecond)
+
+	td := storage.get(0)
+	assert.NotNil(t, td.Receipt)
+	assert.True(t, td.Receipt.Reverted())
+	assert.Equal(t, td.LastTransactionHash(), td.Receipt.TxHash)
+	assert.Equal(t, uint64(21000), td.Receipt.GasUsed)
+	assert.Equal(t, big.NewInt(2), td.Receipt.EffectiveGasPrice)
+	assert.Equal(t, uint64(10), td.Receipt.BlockNumber)
+}
+
+var _ DepotRecoveryClient = (*client.MultichainBlockchainClient)(nil)
+
+type recoveryClientMock struct {
+	known map[common.Hash]bool
+	sent  []*types.Transaction
+}
+
+func (r *recoveryClientMock) TransactionByHash(_ int64, hash common.Hash) (*types.Transaction, bool, error) {
+	if r.known[hash] {
+		return nil, true, nil
+	}
+	return nil, false, ethereum.NotFound
+}
+
+func (r *recoveryClientMock) SendTransaction(_ int64, tx *types.Transaction) error {
+	r.sent = append(r.sent, tx)
+	return nil
+}
+
+func TestDepotRecoversInFlight(t *testing.T) {
+	sender := common.HexToAddress("0x1")
+	sent := func(nonce uint64) Delivery {
+		blob, err := types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(chainId), Nonce: nonce}).MarshalJSON()
+		assert.NoError(t, err)
+		return Delivery{Sender: sender, ChainID: chainId, Nonce: nonce, State: DeliveryStateSent, SentTransaction: blob}
+	}
+	known, lost := sent(0), sent(1)
+	storage := &mockStorage{deliveries: []Delivery{known, lost}}
+	nonces := &mockNonceTracker{nonces: make(map[string]uint64), confirmNone: true}
+	worker := DepotWorker{Address: sender, ChainID: chainId, ProcessCount: 5}
+	depot := NewDepot(&mockCourier{lastDeliveredNonce: -1}, storage, nonces, nil, DepotConfig{
+		Workers: []DepotWorker{worker},
+	})
+
+	bc := &recoveryClientMock{known: map[common.Hash]bool{known.LastTransactionHash(): true}}
+	depot.AttachRecoveryClient(bc)
+	depot.recoverInFlight(worker)
+
+	if assert.Len(t, bc.sent, 1) {
+		assert.Equal(t, lost.LastTransactionHash(), bc.sent[0].Hash())
+	}
+}
+
+func TestDepotRateLimits(t *testing.T) {
+	sender := common.HexToAddress("0x1")
+	storage := &mockStorage{deliveries: []Delivery{}}
+	nonces := &mockNonceTracker{nonces: make(map[string]uint64), confirmNone: true}
+	price := big.NewInt(1)
+	gasTracker := NewGasTracker(&mockGasStation{defaultPrice: price, defaultBaseFee: price}, map[int64]GasIncreaseOpts{
+		chainId: {Multiplier: 2, PriceLimit: big.NewInt(1000), IncreaseInterval: time.Hour},
+	}, GasTrackerSpeedMedium)
+	courier := &mockCourier{lastDeliveredNonce: -1}
+	depot := NewDepot(courier, storage, nonces, gasTracker, DepotConfig{
+		MaxNonDelivered: 5,
+		Workers:         []DepotWorker{{Address: sender, ChainID: chainId, ProcessInterval: 5 * time.Millisecond, ProcessCount: 5}},
+	})
+	depot.SetRateLimits(ChainRateLimits{chainId: 2})
+	depot.Run()
+	defer depot.Stop()
+
+	for i := 0; i < 3; i++ {
+		_, err := depot.EnqueueDelivery(DeliveryRequest{ChainID: chainId, Sender: sender, Type: "test"}, false)
+		assert.NoError(t, err)
+	}
+
+	assert.Eventually(t, func() bool {
+		return courier.getCalls() == 2
+	}, 2*time.Second, 5*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, uint64(2), courier.getCalls())
+	assert.Equal(t, DeliveryState(DeliveryStateWaiting), storage.get(2).State)
+}
+
+func TestDepotScheduledDelivery(t *testing.T) {
+	sender := common.HexToAddress("0x1")
+	storage := &mockStorage{deliveries: []Delivery{}}
+	nonces := &mockNonceTracker{nonces: make(map[string]uint64), confirmNone: true}
+	price := big.NewInt(1)
+	gasTracker := NewGasTracker(&mockGasStation{defaultPrice: price, defaultBaseFee: price}, map[int64]GasIncreaseOpts{
+		chainId: {Multiplier: 2, PriceLimit: big.NewInt(1000), IncreaseInterval: time.Hour},
+	}, GasTrackerSpeedMedium)
+	courier := &mockCourier{lastDeliveredNonce: -1}
+	depot := NewDepot(courier, storage, nonces, gasTracker, DepotConfig{
+		MaxNonDelivered: 5,
+		Workers:         []DepotWorker{{Address: sender, ChainID: chainId, ProcessInterval: 5 * time.Millisecond, ProcessCount: 5}},
+	})
+
+	notBefore := time.Now().Add(200 * time.Millisecond)
+	_, err := depot.EnqueueDelivery(DeliveryRequest{ChainID: chainId, Sender: sender, Type: "test", NotBefore: notBefore}, false)
+	assert.NoError(t, err)
+	// Queued after the scheduled delivery, it has to wait for it.
+	_, err = depot.EnqueueDelivery(DeliveryRequest{ChainID: chainId, Sender: sender, Type: "test"}, false)
+	assert.NoError(t, err)
+
+	depot.Run()
+	defer depot.Stop()
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, uint64(0), courier.getCalls())
+
+	assert.Eventually(t, func() bool {
+		return courier.getCalls() == 2
+	}, 2*time.Second, 5*time.Millisecond)
+	assert.False(t, time.Now().Before(notBefore))
+}
+
+type underpricedCourier struct {
+	mockCourier
+	failed bool
+}
+
+func (u *underpricedCourier) DeliverTransaction(td Delivery) (*types.Transaction, error) {
+	u.lock.Lock()
+	if !u.failed {
+		u.failed = true
+		u.lock.Unlock()
+		return nil, errors.New("replacement transaction underpriced")
+	}
+	u.lock.Unlock()
+	return u.mockCourier.DeliverTransaction(td)
+}
+
+func TestDepotBumpsUnderpriced(t *testing.T) {
+	sender := common.HexToAddress("0x1")
+	storage := &mockStorage{deliveries: []Delivery{}}
+	nonces := &mockNonceTracker{nonces: make(map[string]uint64), confirmNone: true}
+	price := big.NewInt(10)
+	gasTracker := NewGasTracker(&mockGasStation{defaultPrice: price, defaultBaseFee: price}, map[int64]GasIncreaseOpts{
+		chainId: {Multiplier: 2, PriceLimit: big.NewInt(1000), IncreaseInterval: time.Hour},
+	}, GasTrackerSpeedMedium)
+	courier := &underpricedCourier{mockCourier: mockCourier{lastDeliveredNonce: -1}}
+	depot := NewDepot(courier, storage, nonces, gasTracker, DepotConfig{
+		MaxNonDelivered: 5,
+		Workers:         []DepotWorker{{Address: sender, ChainID: chainId, ProcessInterval: 5 * time.Millisecond, ProcessCount: 1}},
+	})
+	depot.Run()
+	defer depot.Stop()
+
+	_, err := depot.EnqueueDelivery(DeliveryRequest{ChainID: chainId, Sender: sender, Type: "test"}, false)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return storage.get(0).State == DeliveryStateSent
+	}, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, uint64(1), courier.getCalls())
+	assert.Equal(t, big.NewInt(20), storage.get(0).GasTip)
+}
+
+type alreadyKnownCourier struct {
+	mockCourier
+}
+
+func (a *alreadyKnownCourier) DeliverTransaction(td Delivery) (*types.Transaction, error) {
+	tx, _ := a.mockCourier.DeliverTransaction(td)
+	return tx, errors.New("already known")
+}
+
+func TestDepotMarksAlreadyKnownAsSent(t *testing.T) {
+	sender := common.HexToAddress("0x1")
+	storage := &mockStorage{deliveries: []Delivery{}}
+	nonces := &mockNonceTracker{nonces: make(map[string]uint64), confirmNone: true}
+	price := big.NewInt(10)
+	gasTracker := NewGasTracker(&mockGasStation{defaultPrice: price, defaultBaseFee: price}, map[int64]GasIncreaseOpts{
+		chainId: {Multiplier: 2, PriceLimit: big.NewInt(1000), IncreaseInterval: time.Hour},
+	}, GasTrackerSpeedMedium)
+	courier := &alreadyKnownCourier{mockCourier: mockCourier{lastDeliveredNonce: -1}}
+	depot := NewDepot(courier, storage, nonces, gasTracker, DepotConfig{
+		MaxNonDelivered: 5,
+		Workers:         []DepotWorker{{Address: sender, ChainID: chainId, ProcessInterval: 5 * time.Millisecond, ProcessCount: 1}},
+	})
+	depot.Run()
+	defer depot.Stop()
+
+	_, err := depot.EnqueueDelivery(DeliveryRequest{ChainID: chainId, Sender: sender, Type: "test"}, false)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return storage.get(0).State == DeliveryStateSent
+	}, 2*time.Second, 5*time.Millisecond)
+	assert.NotEmpty(t, storage.get(0).SentTransaction)
+	assert.Equal(t, uint64(1), courier.getCalls())
+}
+
+type reloadingNonceTracker struct {
+	mockNonceTracker
+	reloads int
+}
+
+func (r *reloadingNonceTracker) ForceReloadNonce(int64, common.Address) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.reloads++
+}
+
+func TestDepotReloadsNonceTooLow(t *testing.T) {
+	sender := common.HexToAddress("0x1")
+	nonces := &reloadingNonceTracker{mockNonceTracker: mockNonceTracker{nonces: make(map[string]uint64)}}
+	depot := NewDepot(&failingCourier{}, &mockStorage{deliveries: []Delivery{}}, nonces, nil, DepotConfig{})
+
+	_, err := depot.sendOutTransaction(Delivery{Sender: sender, ChainID: chainId})
+	assert.ErrorIs(t, err, ErrNonceTooLow)
+	assert.Equal(t, 1, nonces.reloads)
+}
+
+func TestDepotIdempotencyKey(t *testing.T) {
+	sender := common.HexToAddress("0x1")
+	storage := &mockStorage{deliveries: []Delivery{}}
+	nonces := &mockNonceTracker{nonces: make(map[string]uint64)}
+	depot := NewDepot(&mockCourier{lastDeliveredNonce: -1}, storage, nonces, nil, DepotConfig{
+		MaxNonDelivered: 10,
+		Workers:         []DepotWorker{{Address: sender, ChainID: chainId}},
+	})
+
+	req := DeliveryRequest{ChainID: chainId, Sender: sender, Type: "test", IdempotencyKey: "payout-1"}
+	first, err := depot.EnqueueDelivery(req, false)
+	assert.NoError(t, err)
+	retried, err := depot.EnqueueDelivery(req, false)
+	assert.NoError(t, err)
+	assert.Equal(t, first, retried)
+	assert.Len(t, storage.deliveries, 1)
+
+	req.IdempotencyKey = "payout-2"
+	other, err := depot.EnqueueDelivery(req, false)
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, other)
+
+	req.IdempotencyKey = ""
+	_, err = depot.EnqueueDelivery(req, false)
+	assert.NoError(t, err)
+	assert.Len(t, storage.deliveries, 3)
+}
+
+func TestDepotTryEnqueueDelivery(t *testing.T) {
+	sender := common.HexToAddress("0x1")
+	storage := &mockStorage{deliveries: []Delivery{}}
+	nonces := &mockNonceTracker{nonces: make(map[string]uint64)}
+	depot := NewDepot(&mockCourier{lastDeliveredNonce: -1}, storage, nonces, nil, DepotConfig{
+		MaxNonDelivered: 2,
+		Workers:         []DepotWorker{{Address: sender, ChainID: chainId}},
+	})
+
+	req := DeliveryRequest{ChainID: chainId, Sender: sender, Type: "test"}
+	for i := 0; i < 2; i++ {
+		_, err := depot.EnqueueDelivery(req, false)
+		assert.NoError(t, err)
+	}
+	_, err := depot.EnqueueDelivery(req, false)
+	assert.ErrorIs(t, err, ErrQueueFull)
+
+	// High priority requests bypass the limit, unless they are only tried.
+	req.Priority = DeliveryPriorityHigh
+	_, err = depot.TryEnqueueDelivery(req)
+	assert.ErrorIs(t, err, ErrQueueFull)
+	assert.Len(t, storage.deliveries, 2)
+
+	queued, limit, err := depot.FillLevel(chainId, sender)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), queued)
+	assert.Equal(t, uint(2), limit)
+}
+
 type mockStorage struct {
 	deliveries []Delivery
 	lock       sync.Mutex
@@ -747,3 +1549,60 @@ func (m *mockGasStation) reset(baseFee, gasPrice *big.Int) {
 type mockData struct {
 	Data string `json:"data"`
 }
+
+func TestDepotTracing(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+
+	sender := common.HexToAddress("0x1")
+	storage := &mockStorage{deliveries: []Delivery{}}
+	nonces := &mockNonceTracker{nonces: make(map[string]uint64), confirmAll: true}
+	price := big.NewInt(1)
+	gasTracker := NewGasTracker(&mockGasStation{defaultPrice: price, defaultBaseFee: price}, map[int64]GasIncreaseOpts{
+		chainId: {Multiplier: 2, PriceLimit: big.NewInt(1000), IncreaseInterval: time.Second},
+	}, GasTrackerSpeedMedium)
+	depot := NewDepot(&mockCourier{lastDeliveredNonce: -1}, storage, nonces, gasTracker, DepotConfig{
+		MaxNonDelivered: 5,
+		Workers:         []DepotWorker{{Address: sender, ChainID: chainId, ProcessInterval: 5 * time.Millisecond, ProcessCount: 1}},
+	})
+	depot.AttachTracerProvider(tp)
+	depot.Run()
+	defer depot.Stop()
+
+	ctx, settle := tp.Tracer("test").Start(context.Background(), "settle")
+	id, err := depot.EnqueueDeliveryCtx(ctx, DeliveryRequest{ChainID: chainId, Sender: sender, Type: "test"}, false)
+	assert.NoError(t, err)
+	settle.End()
+
+	assert.Eventually(t, func() bool {
+		return len(rec.Ended()) == 4
+	}, 2*time.Second, 5*time.Millisecond)
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, s := range rec.Ended() {
+		assert.Equal(t, settle.SpanContext().TraceID(), s.SpanContext().TraceID(), s.Name())
+		spans[s.Name()] = s
+	}
+	enqueue := spans["Depot.EnqueueDelivery"]
+	assert.Equal(t, settle.SpanContext().SpanID(), enqueue.Parent().SpanID())
+
+	td := storage.get(0)
+	hash := td.LastTransactionHash().Hex()
+	for _, name := range []string{"Depot.SendTransaction", "Depot.WaitConfirmation"} {
+		s := spans[name]
+		assert.Equal(t, enqueue.SpanContext().SpanID(), s.Parent().SpanID(), name)
+
+		attrs := make(map[attribute.Key]attribute.Value)
+		for _, kv := range s.Attributes() {
+			attrs[kv.Key] = kv.Value
+		}
+		assert.Equal(t, int64(chainId), attrs[client.AttrChainID].AsInt64(), name)
+		assert.Equal(t, hash, attrs[client.AttrTxHash].AsString(), name)
+		assert.Equal(t, id, attrs[AttrDeliveryID].AsString(), name)
+	}
+
+	depot.tracing.mu.Lock()
+	defer depot.tracing.mu.Unlock()
+	assert.Empty(t, depot.tracing.parents)
+	assert.Empty(t, depot.tracing.waits)
+}
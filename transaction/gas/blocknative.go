@@ -0,0 +1,154 @@
+package gas
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mysteriumnetwork/payments/units"
+)
+
+// DefaultBlocknativeEndpointURI the default blocknative gas platform api endpoint.
+const DefaultBlocknativeEndpointURI = "https://api.blocknative.com/"
+
+// Confidence levels of the blocknative estimations mapped to the tiers.
+const (
+	BlocknativeSafeLowConfidence = 70
+	BlocknativeAverageConfidence = 90
+	BlocknativeFastConfidence    = 99
+)
+
+// BlocknativeStation represents the blocknative gas platform api to retrieve gas prices.
+// The tiers are the max priority fees estimated for the next block with 70%, 90%
+// and 99% confidence of inclusion.
+type BlocknativeStation struct {
+	apiKey      string
+	endpointURI string
+	chainID     int64
+	upperBound  *big.Int
+
+	client *http.Client
+}
+
+// NewBlocknativeStation returns a new instance of blocknative api for gas price checks of the given chain.
+func NewBlocknativeStation(timeout time.Duration, apiKey, endpointURI string, chainID int64, upperBound *big.Int) *BlocknativeStation {
+	endpoint := endpointURI
+	if !strings.HasSuffix(endpoint, "/") {
+		endpoint += "/"
+	}
+
+	return &BlocknativeStation{
+		client: &http.Client{
+			Timeout: timeout,
+		},
+		endpointURI: endpoint,
+		chainID:     chainID,
+		upperBound:  upperBound,
+		apiKey:      apiKey,
+	}
+}
+
+func (bn *BlocknativeStation) GetGasPrices() (*GasPrices, error) {
+	return bn.GetGasPricesContext(context.Background())
+}
+
+// GetGasPricesContext returns gas prices using the given context for the request.
+func (bn *BlocknativeStation) GetGasPricesContext(ctx context.Context) (*GasPrices, error) {
+	res, err := bn.request(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.BlockPrices) == 0 {
+		return nil, fmt.Errorf("blocknative api returned no block prices")
+	}
+
+	block := res.BlockPrices[0]
+	tips := make(map[int]float64, len(block.EstimatedPrices))
+	for _, p := range block.EstimatedPrices {
+		tips[p.Confidence] = p.MaxPriorityFeePerGas
+	}
+
+	prices := GasPrices{
+		BaseFee: units.FloatGweiToBigIntWei(block.BaseFeePerGas),
+	}
+	for _, tier := range []struct {
+		confidence int
+		price      **big.Int
+	}{
+		{BlocknativeSafeLowConfidence, &prices.SafeLow},
+		{BlocknativeAverageConfidence, &prices.Average},
+		{BlocknativeFastConfidence, &prices.Fast},
+	} {
+		tip, ok := tips[tier.confidence]
+		if !ok {
+			return nil, fmt.Errorf("blocknative api returned no estimation with %d%% confidence", tier.confidence)
+		}
+		*tier.price = bn.result(tip)
+	}
+	return &prices, nil
+}
+
+func (bn *BlocknativeStation) request(ctx context.Context) (*blocknativeBlockPricesResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%vgasprices/blockprices?chainid=%d", bn.endpointURI, bn.chainID), nil)
+	if err != nil {
+		return nil, err
+	}
+	if bn.apiKey != "" {
+		req.Header.Set("Authorization", bn.apiKey)
+	}
+
+	response, err := bn.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	resp, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("blocknative api responded with status %d and body: %s", response.StatusCode, string(resp))
+	}
+
+	var res blocknativeBlockPricesResponse
+	err = json.Unmarshal(resp, &res)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body from blocknative with an error: %w and body: %s", err, string(resp))
+	}
+
+	return &res, nil
+}
+
+func (bn *BlocknativeStation) result(price float64) *big.Int {
+	bp := units.FloatGweiToBigIntWei(price)
+	return priceMaxUpperBound(bp, bn.upperBound)
+}
+
+// blocknativeBlockPricesResponse is the block prices response of the gas platform.
+type blocknativeBlockPricesResponse struct {
+	Unit               string                  `json:"unit"`
+	CurrentBlockNumber int64                   `json:"currentBlockNumber"`
+	BlockPrices        []blocknativeBlockPrice `json:"blockPrices"`
+}
+
+// blocknativeBlockPrice the estimations for a pending block.
+type blocknativeBlockPrice struct {
+	BlockNumber     int64                       `json:"blockNumber"`
+	BaseFeePerGas   float64                     `json:"baseFeePerGas"`
+	EstimatedPrices []blocknativeEstimatedPrice `json:"estimatedPrices"`
+}
+
+// blocknativeEstimatedPrice the estimation with a confidence level.
+type blocknativeEstimatedPrice struct {
+	Confidence           int     `json:"confidence"`
+	Price                float64 `json:"price"`
+	MaxPriorityFeePerGas float64 `json:"maxPriorityFeePerGas"`
+	MaxFeePerGas         float64 `json:"maxFeePerGas"`
+}
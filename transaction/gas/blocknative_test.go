@@ -0,0 +1,72 @@
+package gas
+
+import (
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlocknative(t *testing.T) {
+	var (
+		oneGwei  int64 = 1000000000
+		response blocknativeBlockPricesResponse
+		auth     string
+		chain    string
+	)
+	r := gin.Default()
+	r.GET("/gasprices/blockprices", func(c *gin.Context) {
+		auth = c.GetHeader("Authorization")
+		chain = c.Query("chainid")
+		c.JSON(http.StatusOK, response)
+	})
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	bn := NewBlocknativeStation(time.Second, "key", server.URL, 137, big.NewInt(200*oneGwei))
+
+	t.Run("get gas", func(t *testing.T) {
+		response = blocknativeBlockPricesResponse{
+			BlockPrices: []blocknativeBlockPrice{{
+				BaseFeePerGas: 30,
+				EstimatedPrices: []blocknativeEstimatedPrice{
+					{Confidence: 99, MaxPriorityFeePerGas: 250},
+					{Confidence: 95, MaxPriorityFeePerGas: 40},
+					{Confidence: 90, MaxPriorityFeePerGas: 35},
+					{Confidence: 80, MaxPriorityFeePerGas: 33},
+					{Confidence: 70, MaxPriorityFeePerGas: 31},
+				},
+			}},
+		}
+		gp, err := bn.GetGasPrices()
+		assert.NoError(t, err)
+
+		assert.Equal(t, "key", auth)
+		assert.Equal(t, "137", chain)
+		assert.Equal(t, big.NewInt(30*oneGwei), gp.BaseFee)
+		assert.Equal(t, big.NewInt(31*oneGwei), gp.SafeLow)
+		assert.Equal(t, big.NewInt(35*oneGwei), gp.Average)
+		//uppperbound
+		assert.Equal(t, big.NewInt(200*oneGwei), gp.Fast)
+	})
+
+	t.Run("missing confidence level", func(t *testing.T) {
+		response = blocknativeBlockPricesResponse{
+			BlockPrices: []blocknativeBlockPrice{{
+				EstimatedPrices: []blocknativeEstimatedPrice{{Confidence: 99, MaxPriorityFeePerGas: 2}},
+			}},
+		}
+		_, err := bn.GetGasPrices()
+		assert.Error(t, err)
+	})
+
+	t.Run("handle error", func(t *testing.T) {
+		bn := NewBlocknativeStation(time.Second, "", server.URL+"/missing", 1, big.NewInt(oneGwei))
+		_, err := bn.GetGasPrices()
+		assert.ErrorContains(t, err, "status 404")
+	})
+}
@@ -0,0 +1,57 @@
+package gas
+
+import (
+	"context"
+	"math/big"
+)
+
+// BoundedStation wraps a station and keeps the returned tips within the bounds.
+// Some providers occasionally return near zero prices, the lower bound keeps
+// transactions from sitting unmined. Wrap the stations of each chain with
+// their own bounds to configure them per chain.
+type BoundedStation struct {
+	station    Station
+	lowerBound *big.Int
+	upperBound *big.Int
+}
+
+// NewBoundedStation returns a new bounded station, a nil bound is not enforced.
+func NewBoundedStation(station Station, lowerBound, upperBound *big.Int) *BoundedStation {
+	return &BoundedStation{
+		station:    station,
+		lowerBound: lowerBound,
+		upperBound: upperBound,
+	}
+}
+
+func (b *BoundedStation) GetGasPrices() (*GasPrices, error) {
+	return b.GetGasPricesContext(context.Background())
+}
+
+// GetGasPricesContext returns the prices of the wrapped station with the tips bounded.
+func (b *BoundedStation) GetGasPricesContext(ctx context.Context) (*GasPrices, error) {
+	prices, err := GetGasPricesContext(ctx, b.station)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GasPrices{
+		SafeLow: b.bound(prices.SafeLow),
+		Average: b.bound(prices.Average),
+		Fast:    b.bound(prices.Fast),
+		BaseFee: prices.BaseFee,
+	}, nil
+}
+
+func (b *BoundedStation) bound(price *big.Int) *big.Int {
+	if price == nil {
+		price = new(big.Int)
+	}
+	if b.lowerBound != nil {
+		price = priceMinLowerBound(price, b.lowerBound)
+	}
+	if b.upperBound != nil {
+		price = priceMaxUpperBound(price, b.upperBound)
+	}
+	return new(big.Int).Set(price)
+}
@@ -0,0 +1,38 @@
+package gas
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoundedStation(t *testing.T) {
+	t.Run("raises to lower bound", func(t *testing.T) {
+		bs := NewBoundedStation(NewStaticStation(big.NewInt(1), big.NewInt(7)), big.NewInt(30), big.NewInt(100))
+		prices, err := bs.GetGasPrices()
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(30), prices.SafeLow)
+		assert.Equal(t, big.NewInt(30), prices.Fast)
+		assert.Equal(t, big.NewInt(7), prices.BaseFee)
+	})
+
+	t.Run("clamps to upper bound", func(t *testing.T) {
+		bs := NewBoundedStation(NewStaticStation(big.NewInt(500), big.NewInt(7)), big.NewInt(30), big.NewInt(100))
+		prices, err := bs.GetGasPrices()
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(100), prices.Average)
+	})
+
+	t.Run("nil bounds are not enforced", func(t *testing.T) {
+		bs := NewBoundedStation(NewStaticStation(big.NewInt(500), big.NewInt(7)), nil, nil)
+		prices, err := bs.GetGasPrices()
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(500), prices.Average)
+	})
+
+	t.Run("handle error", func(t *testing.T) {
+		_, err := NewBoundedStation(NewFailingStationMock(), big.NewInt(1), nil).GetGasPrices()
+		assert.Error(t, err)
+	})
+}
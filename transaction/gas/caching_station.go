@@ -0,0 +1,146 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/payments/logging"
+)
+
+// CachingStation wraps a station and caches its prices for the given TTL.
+// Once `Run` is called the prices are refreshed in the background, so
+// callers are served from the cache. When the wrapped station fails the
+// last known good prices are returned, no matter how old they are.
+type CachingStation struct {
+	station Station
+	ttl     time.Duration
+
+	mu        sync.Mutex
+	prices    *GasPrices
+	fetchedAt time.Time
+	// inflight is the refresh in progress, concurrent callers wait for it instead of calling the station.
+	inflight *refreshCall
+
+	now  func() time.Time
+	stop chan struct{}
+	once sync.Once
+}
+
+type refreshCall struct {
+	done   chan struct{}
+	prices *GasPrices
+	err    error
+}
+
+// ErrInvalidTTL is returned when creating a caching station with a TTL which is not positive.
+var ErrInvalidTTL = errors.New("caching station ttl must be positive")
+
+// NewCachingStation returns a new caching station, `ErrInvalidTTL` is returned if the ttl is not positive.
+func NewCachingStation(station Station, ttl time.Duration) (*CachingStation, error) {
+	if ttl <= 0 {
+		return nil, ErrInvalidTTL
+	}
+	return &CachingStation{
+		station: station,
+		ttl:     ttl,
+		now:     time.Now,
+		stop:    make(chan struct{}),
+	}, nil
+}
+
+// Run starts refreshing the prices every TTL in the background.
+func (c *CachingStation) Run() {
+	go func() {
+		for {
+			if _, err := c.refresh(context.Background()); err != nil {
+				logging.Default().Error("failed to refresh gas prices", "error", err)
+			}
+
+			select {
+			case <-c.stop:
+				return
+			case <-time.After(c.ttl):
+			}
+		}
+	}()
+}
+
+// Stop stops the background refresh.
+func (c *CachingStation) Stop() {
+	c.once.Do(func() {
+		close(c.stop)
+	})
+}
+
+func (c *CachingStation) GetGasPrices() (*GasPrices, error) {
+	return c.GetGasPricesContext(context.Background())
+}
+
+// GetGasPricesContext returns the cached prices if they are fresh,
+// otherwise asks the wrapped station for new ones.
+func (c *CachingStation) GetGasPricesContext(ctx context.Context) (*GasPrices, error) {
+	c.mu.Lock()
+	prices, fetchedAt := c.prices, c.fetchedAt
+	c.mu.Unlock()
+
+	if prices != nil && c.now().Sub(fetchedAt) < c.ttl {
+		return copyPrices(prices), nil
+	}
+
+	fresh, err := c.refresh(ctx)
+	if err != nil {
+		if prices == nil {
+			return nil, err
+		}
+		logging.Default().Warn("failed to get gas prices, using last known", "error", err, "fetchedAt", fetchedAt)
+		return copyPrices(prices), nil
+	}
+	return copyPrices(fresh), nil
+}
+
+// refresh gets new prices from the wrapped station. Callers refreshing at the same time share a single call.
+func (c *CachingStation) refresh(ctx context.Context) (*GasPrices, error) {
+	c.mu.Lock()
+	if call := c.inflight; call != nil {
+		c.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.prices, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	call := &refreshCall{done: make(chan struct{})}
+	c.inflight = call
+	c.mu.Unlock()
+
+	call.prices, call.err = GetGasPricesContext(ctx, c.station)
+
+	c.mu.Lock()
+	if call.err == nil {
+		c.prices, c.fetchedAt = call.prices, c.now()
+	}
+	c.inflight = nil
+	c.mu.Unlock()
+	close(call.done)
+	return call.prices, call.err
+}
+
+func copyPrices(p *GasPrices) *GasPrices {
+	return &GasPrices{
+		SafeLow: copyInt(p.SafeLow),
+		Average: copyInt(p.Average),
+		Fast:    copyInt(p.Fast),
+		BaseFee: copyInt(p.BaseFee),
+	}
+}
+
+func copyInt(i *big.Int) *big.Int {
+	if i == nil {
+		return nil
+	}
+	return new(big.Int).Set(i)
+}
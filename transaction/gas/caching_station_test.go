@@ -0,0 +1,133 @@
+package gas
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingStationMock struct {
+	mu      sync.Mutex
+	calls   int
+	err     error
+	release chan struct{}
+}
+
+func (s *countingStationMock) GetGasPrices() (*GasPrices, error) {
+	if s.release != nil {
+		<-s.release
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return NewStaticStation(big.NewInt(int64(s.calls)), big.NewInt(1)).GetGasPrices()
+}
+
+func (s *countingStationMock) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+func (s *countingStationMock) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+func TestCachingStation(t *testing.T) {
+	t.Run("caches for the ttl", func(t *testing.T) {
+		mock := &countingStationMock{}
+		now := time.Now()
+		cs, err := NewCachingStation(mock, time.Minute)
+		assert.NoError(t, err)
+		cs.now = func() time.Time { return now }
+
+		prices, err := cs.GetGasPrices()
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(1), prices.Average)
+
+		prices.Average.SetInt64(100)
+		prices, err = cs.GetGasPrices()
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(1), prices.Average)
+		assert.Equal(t, 1, mock.count())
+
+		now = now.Add(time.Minute)
+		prices, err = cs.GetGasPrices()
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(2), prices.Average)
+	})
+
+	t.Run("returns last known good prices", func(t *testing.T) {
+		mock := &countingStationMock{}
+		now := time.Now()
+		cs, err := NewCachingStation(mock, time.Minute)
+		assert.NoError(t, err)
+		cs.now = func() time.Time { return now }
+
+		_, err = cs.GetGasPrices()
+		assert.NoError(t, err)
+
+		mock.setErr(errors.New("rate limited"))
+		now = now.Add(time.Hour)
+		prices, err := cs.GetGasPrices()
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(1), prices.Average)
+	})
+
+	t.Run("fails without cached prices", func(t *testing.T) {
+		cs, err := NewCachingStation(NewFailingStationMock(), time.Minute)
+		assert.NoError(t, err)
+		_, err = cs.GetGasPrices()
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects non positive ttl", func(t *testing.T) {
+		_, err := NewCachingStation(&countingStationMock{}, 0)
+		assert.ErrorIs(t, err, ErrInvalidTTL)
+	})
+
+	t.Run("concurrent misses refresh once", func(t *testing.T) {
+		mock := &countingStationMock{release: make(chan struct{})}
+		cs, err := NewCachingStation(mock, time.Minute)
+		assert.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				prices, err := cs.GetGasPrices()
+				assert.NoError(t, err)
+				assert.Equal(t, big.NewInt(1), prices.Average)
+			}()
+		}
+		assert.Eventually(t, func() bool {
+			cs.mu.Lock()
+			defer cs.mu.Unlock()
+			return cs.inflight != nil
+		}, time.Second, time.Millisecond)
+		time.Sleep(10 * time.Millisecond)
+		close(mock.release)
+		wg.Wait()
+		assert.Equal(t, 1, mock.count())
+	})
+
+	t.Run("refreshes in background", func(t *testing.T) {
+		mock := &countingStationMock{}
+		cs, err := NewCachingStation(mock, 10*time.Millisecond)
+		assert.NoError(t, err)
+		cs.Run()
+		defer cs.Stop()
+
+		assert.Eventually(t, func() bool { return mock.count() >= 3 }, time.Second, 5*time.Millisecond)
+	})
+}
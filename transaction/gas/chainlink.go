@@ -0,0 +1,117 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ChainlinkFastGasMainnet is the Chainlink Fast Gas / Gwei aggregator on Ethereum mainnet.
+var ChainlinkFastGasMainnet = common.HexToAddress("0x169E633A2D1E6c10dD91238Ba11c4A708dfEF37C")
+
+const chainlinkAggregatorABI = `[{"inputs":[],"name":"latestRoundData","outputs":[{"internalType":"uint80","name":"roundId","type":"uint80"},{"internalType":"int256","name":"answer","type":"int256"},{"internalType":"uint256","name":"startedAt","type":"uint256"},{"internalType":"uint256","name":"updatedAt","type":"uint256"},{"internalType":"uint80","name":"answeredInRound","type":"uint80"}],"stateMutability":"view","type":"function"}]`
+
+var chainlinkAggregator = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(chainlinkAggregatorABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// ErrStaleAnswer is returned when the aggregator was not updated within the max age.
+var ErrStaleAnswer = errors.New("chainlink answer is stale")
+
+// ChainlinkClient reads the aggregator contract and the latest header.
+type ChainlinkClient interface {
+	ethereum.ContractCaller
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// ChainlinkStation reads the Chainlink Fast Gas aggregator directly through the
+// eth client. It is an on-chain fallback for when the HTTP oracles are down.
+// The aggregator answers a single fast gas price including the base fee,
+// so every tier is that price minus the base fee of the latest block.
+type ChainlinkStation struct {
+	client     ChainlinkClient
+	aggregator common.Address
+	maxAge     time.Duration
+	upperBound *big.Int
+
+	now func() time.Time
+}
+
+// NewChainlinkStation returns a new chainlink station reading the given aggregator.
+// Answers older than the max age are rejected, zero accepts any age.
+func NewChainlinkStation(client ChainlinkClient, aggregator common.Address, maxAge time.Duration, upperBound *big.Int) *ChainlinkStation {
+	return &ChainlinkStation{
+		client:     client,
+		aggregator: aggregator,
+		maxAge:     maxAge,
+		upperBound: upperBound,
+		now:        time.Now,
+	}
+}
+
+func (c *ChainlinkStation) GetGasPrices() (*GasPrices, error) {
+	return c.GetGasPricesContext(context.Background())
+}
+
+// GetGasPricesContext returns gas prices from the latest aggregator round.
+func (c *ChainlinkStation) GetGasPricesContext(ctx context.Context) (*GasPrices, error) {
+	input, err := chainlinkAggregator.Pack("latestRoundData")
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.client.CallContract(ctx, ethereum.CallMsg{To: &c.aggregator, Data: input}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call latestRoundData: %w", err)
+	}
+	out, err := chainlinkAggregator.Unpack("latestRoundData", res)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack latestRoundData: %w", err)
+	}
+
+	answer := abi.ConvertType(out[1], new(big.Int)).(*big.Int)
+	updatedAt := abi.ConvertType(out[3], new(big.Int)).(*big.Int)
+	if answer.Sign() <= 0 {
+		return nil, fmt.Errorf("chainlink answered an invalid gas price %s", answer)
+	}
+	if c.maxAge > 0 {
+		if age := c.now().Sub(time.Unix(updatedAt.Int64(), 0)); age > c.maxAge {
+			return nil, fmt.Errorf("%w: updated %s ago", ErrStaleAnswer, age.Round(time.Second))
+		}
+	}
+
+	header, err := c.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	baseFee := new(big.Int)
+	if header.BaseFee != nil {
+		baseFee.Set(header.BaseFee)
+	}
+
+	tip := new(big.Int).Sub(answer, baseFee)
+	if tip.Sign() <= 0 {
+		tip = big.NewInt(0)
+	}
+	if c.upperBound != nil {
+		tip = priceMaxUpperBound(tip, c.upperBound)
+	}
+
+	return &GasPrices{
+		SafeLow: new(big.Int).Set(tip),
+		Average: new(big.Int).Set(tip),
+		Fast:    new(big.Int).Set(tip),
+		BaseFee: baseFee,
+	}, nil
+}
@@ -0,0 +1,52 @@
+package gas
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/mysteriumnetwork/payments/client/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChainlinkStation(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	answer := big.NewInt(50)
+	cl := &mocks.EtherClientMock{
+		CallContractFunc: func(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+			assert.Equal(t, ChainlinkFastGasMainnet, *msg.To)
+			return chainlinkAggregator.Methods["latestRoundData"].Outputs.Pack(
+				big.NewInt(1), answer, big.NewInt(now.Unix()-60), big.NewInt(now.Unix()-60), big.NewInt(1),
+			)
+		},
+		HeaderByNumberFunc: func(ctx context.Context, number *big.Int) (*types.Header, error) {
+			return &types.Header{BaseFee: big.NewInt(30)}, nil
+		},
+	}
+	cs := NewChainlinkStation(cl, ChainlinkFastGasMainnet, time.Hour, big.NewInt(100))
+	cs.now = func() time.Time { return now }
+
+	t.Run("get gas", func(t *testing.T) {
+		gp, err := cs.GetGasPrices()
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(20), gp.Fast)
+		assert.Equal(t, big.NewInt(20), gp.SafeLow)
+		assert.Equal(t, big.NewInt(30), gp.BaseFee)
+	})
+
+	t.Run("upper bound", func(t *testing.T) {
+		answer = big.NewInt(1000)
+		gp, err := cs.GetGasPrices()
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(100), gp.Fast)
+	})
+
+	t.Run("stale answer", func(t *testing.T) {
+		cs.now = func() time.Time { return now.Add(2 * time.Hour) }
+		_, err := cs.GetGasPrices()
+		assert.ErrorIs(t, err, ErrStaleAnswer)
+	})
+}
@@ -0,0 +1,75 @@
+package gas
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/ethereum/go-ethereum"
+)
+
+// ErrGasAboveCeiling is returned when the estimated gas is above the ceiling of the chain.
+var ErrGasAboveCeiling = errors.New("estimated gas is above the ceiling")
+
+// DefaultGasBuffer is the default safety buffer added to gas estimates, 20%.
+const DefaultGasBuffer = 0.2
+
+// GasEstimator estimates the gas of a call, `client.MultichainBlockchainClient` satisfies it.
+type GasEstimator interface {
+	EstimateGas(chainID int64, msg ethereum.CallMsg) (uint64, error)
+}
+
+// BufferedEstimatorConfig configures the buffered estimator.
+type BufferedEstimatorConfig struct {
+	// Buffer is the fraction added to the estimate, 0.2 adds 20%.
+	Buffer float64
+	// Ceilings are the maximum gas limits per chain ID, chains without one are not capped.
+	Ceilings map[int64]uint64
+}
+
+// BufferedEstimator wraps `eth_estimateGas` and adds a safety buffer to the estimate,
+// since estimates are occasionally too low, for example on Polygon.
+type BufferedEstimator struct {
+	estimator GasEstimator
+	cfg       BufferedEstimatorConfig
+}
+
+// NewBufferedEstimator returns a new buffered estimator.
+func NewBufferedEstimator(estimator GasEstimator, cfg BufferedEstimatorConfig) *BufferedEstimator {
+	return &BufferedEstimator{
+		estimator: estimator,
+		cfg:       cfg,
+	}
+}
+
+// EstimateGasWithBuffer returns the estimated gas of the call with the buffer added,
+// capped at the ceiling of the chain. Returns `ErrGasAboveCeiling` if the estimate
+// itself is above the ceiling, as the call would then likely run out of gas.
+func (b *BufferedEstimator) EstimateGasWithBuffer(chainID int64, msg ethereum.CallMsg) (uint64, error) {
+	estimate, err := b.estimator.EstimateGas(chainID, msg)
+	if err != nil {
+		return 0, err
+	}
+	return ApplyGasBuffer(estimate, b.cfg.Buffer, b.cfg.Ceilings[chainID])
+}
+
+// ApplyGasBuffer adds the buffer fraction to the estimate and caps it at the ceiling, zero means no ceiling.
+func ApplyGasBuffer(estimate uint64, buffer float64, ceiling uint64) (uint64, error) {
+	if ceiling > 0 && estimate > ceiling {
+		return 0, fmt.Errorf("%w: %d > %d", ErrGasAboveCeiling, estimate, ceiling)
+	}
+
+	buffered := estimate
+	if buffer > 0 {
+		f := math.Ceil(float64(estimate) * (1 + buffer))
+		if f >= math.MaxUint64 {
+			buffered = math.MaxUint64
+		} else {
+			buffered = uint64(f)
+		}
+	}
+	if ceiling > 0 && buffered > ceiling {
+		buffered = ceiling
+	}
+	return buffered, nil
+}
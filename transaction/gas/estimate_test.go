@@ -0,0 +1,54 @@
+package gas
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/mysteriumnetwork/payments/client"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ GasEstimator = (*client.MultichainBlockchainClient)(nil)
+
+type estimatorMock struct {
+	gas uint64
+	err error
+}
+
+func (e *estimatorMock) EstimateGas(_ int64, _ ethereum.CallMsg) (uint64, error) {
+	return e.gas, e.err
+}
+
+func TestBufferedEstimator(t *testing.T) {
+	est := &estimatorMock{gas: 100000}
+	be := NewBufferedEstimator(est, BufferedEstimatorConfig{
+		Buffer:   DefaultGasBuffer,
+		Ceilings: map[int64]uint64{137: 110000, 1: 50000},
+	})
+
+	gas, err := be.EstimateGasWithBuffer(5, ethereum.CallMsg{})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(120000), gas)
+
+	gas, err = be.EstimateGasWithBuffer(137, ethereum.CallMsg{})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(110000), gas)
+
+	_, err = be.EstimateGasWithBuffer(1, ethereum.CallMsg{})
+	assert.ErrorIs(t, err, ErrGasAboveCeiling)
+
+	est.err = errors.New("execution reverted")
+	_, err = be.EstimateGasWithBuffer(5, ethereum.CallMsg{})
+	assert.Error(t, err)
+}
+
+func TestApplyGasBuffer(t *testing.T) {
+	gas, err := ApplyGasBuffer(21001, 0.1, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(23102), gas)
+
+	gas, err = ApplyGasBuffer(21000, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(21000), gas)
+}
@@ -1,6 +1,7 @@
 package gas
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,20 +11,47 @@ import (
 	"strings"
 	"time"
 
+	"github.com/mysteriumnetwork/payments/logging"
 	"github.com/mysteriumnetwork/payments/units"
-	"github.com/rs/zerolog/log"
 )
 
 // DefaultEtherscanEndpointURI the default etherscan api endpoint.
 const DefaultEtherscanEndpointURI = "https://api.etherscan.io/"
 
+// EtherscanEndpoints are the api endpoints of the etherscan family of explorers
+// which serve the same gas oracle api, by chain ID.
+var EtherscanEndpoints = map[int64]string{
+	1:     DefaultEtherscanEndpointURI,
+	10:    "https://api-optimistic.etherscan.io/",
+	56:    "https://api.bscscan.com/",
+	137:   DefaultPolygonscanEndpointURI,
+	250:   "https://api.ftmscan.com/",
+	8453:  "https://api.basescan.org/",
+	42161: "https://api.arbiscan.io/",
+	43114: "https://api.snowtrace.io/",
+}
+
 // EtherscanStation represents the etherscan api to retrive gas prices.
+// Explorers of other chains serving the same api, like bscscan or snowtrace,
+// are supported by passing their endpoint, see `NewEtherscanStationForChain`.
+// The returned tiers are tips, the upper bound limits the tip.
 type EtherscanStation struct {
 	apiKey      string
 	endpointURI string
 	upperBound  *big.Int
 
 	client *http.Client
+	retry  Retry
+}
+
+// NewEtherscanStationForChain returns a new instance of the etherscan family api for gas price checks
+// of the given chain, using its endpoint from `EtherscanEndpoints`.
+func NewEtherscanStationForChain(timeout time.Duration, apiKey string, chainID int64, upperBound *big.Int) (*EtherscanStation, error) {
+	endpoint, ok := EtherscanEndpoints[chainID]
+	if !ok {
+		return nil, fmt.Errorf("no etherscan api endpoint for chain %d", chainID)
+	}
+	return NewEtherscanStation(timeout, apiKey, endpoint, upperBound), nil
 }
 
 // NewEtherscanStation returns a new instance of etherscan api for gas price checks.
@@ -43,8 +71,27 @@ func NewEtherscanStation(timeout time.Duration, apiKey, endpointURI string, uppe
 	}
 }
 
+// SetHTTPClient replaces the HTTP client used for requests, for example to use a custom transport.
+//
+// This method is not thread safe and should be called before the station is used.
+func (esa *EtherscanStation) SetHTTPClient(client *http.Client) {
+	esa.client = client
+}
+
+// SetRetry enables retrying requests which failed with a server error or were rate limited.
+//
+// This method is not thread safe and should be called before the station is used.
+func (esa *EtherscanStation) SetRetry(retry Retry) {
+	esa.retry = retry
+}
+
 func (esa *EtherscanStation) GetGasPrices() (*GasPrices, error) {
-	res, err := esa.request()
+	return esa.GetGasPricesContext(context.Background())
+}
+
+// GetGasPricesContext returns gas prices using the given context for the request.
+func (esa *EtherscanStation) GetGasPricesContext(ctx context.Context) (*GasPrices, error) {
+	res, err := esa.request(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -64,22 +111,37 @@ func (esa *EtherscanStation) GetGasPrices() (*GasPrices, error) {
 	if err != nil {
 		return nil, err
 	}
+	baseFee := units.FloatGweiToBigIntWei(base)
 	prices := GasPrices{
-		SafeLow: esa.result(safeLow),
-		Average: esa.result(average),
-		Fast:    esa.result(fast),
+		SafeLow: esa.result(safeLow, baseFee),
+		Average: esa.result(average, baseFee),
+		Fast:    esa.result(fast, baseFee),
 
-		BaseFee: units.FloatGweiToBigIntWei(base),
+		BaseFee: baseFee,
 	}
 	return &prices, nil
 }
 
-func (esa *EtherscanStation) request() (*etherscanGasPriceResponse, error) {
+func (esa *EtherscanStation) request(ctx context.Context) (*etherscanGasPriceResponse, error) {
 	if esa.apiKey == "" {
-		log.Warn().Msg("no API key set, rate is limited")
+		logging.Default().Warn("no API key set, rate is limited", "provider", "etherscan", "endpoint", esa.endpointURI)
+	}
+
+	var res *etherscanGasPriceResponse
+	return res, esa.retry.do(ctx, func() error {
+		var err error
+		res, err = esa.requestOnce(ctx)
+		return err
+	})
+}
+
+func (esa *EtherscanStation) requestOnce(ctx context.Context) (*etherscanGasPriceResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%v%v%v", esa.endpointURI, "api?module=gastracker&action=gasoracle&apikey=", esa.apiKey), nil)
+	if err != nil {
+		return nil, err
 	}
 
-	response, err := esa.client.Get(fmt.Sprintf("%v%v%v", esa.endpointURI, "api?module=gastracker&action=gasoracle&apikey=", esa.apiKey))
+	response, err := esa.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -90,6 +152,15 @@ func (esa *EtherscanStation) request() (*etherscanGasPriceResponse, error) {
 		return nil, err
 	}
 
+	if response.StatusCode >= http.StatusInternalServerError || response.StatusCode == http.StatusTooManyRequests {
+		return nil, fmt.Errorf("%w: etherscan api responded with status %d", errRetryable, response.StatusCode)
+	}
+
+	var status etherscanStatusResponse
+	if err := json.Unmarshal(resp, &status); err == nil && status.Status == "0" && strings.Contains(strings.ToLower(status.Result), "rate limit") {
+		return nil, fmt.Errorf("%w: etherscan api rate limited: %s", errRetryable, status.Result)
+	}
+
 	var res etherscanGasPriceResponse
 	err = json.Unmarshal(resp, &res)
 	if err != nil {
@@ -103,9 +174,14 @@ func (esa *EtherscanStation) request() (*etherscanGasPriceResponse, error) {
 	return &res, nil
 }
 
-func (esa *EtherscanStation) result(price float64) *big.Int {
-	bp := units.FloatGweiToBigIntWei(price)
-	return priceMaxUpperBound(bp, esa.upperBound)
+// result returns the tip of the given gas price. The oracle prices include
+// the suggested base fee, so it is subtracted and the rest is bounded.
+func (esa *EtherscanStation) result(price float64, baseFee *big.Int) *big.Int {
+	tip := new(big.Int).Sub(units.FloatGweiToBigIntWei(price), baseFee)
+	if tip.Sign() <= 0 {
+		tip = big.NewInt(0)
+	}
+	return priceMaxUpperBound(tip, esa.upperBound)
 }
 
 // etherscanGasPriceResponse returns the gas station response.
@@ -115,6 +191,13 @@ type etherscanGasPriceResponse struct {
 	Result  gasPriceResult `json:"result"`
 }
 
+// etherscanStatusResponse is the response of a failed request, the result holds the error.
+type etherscanStatusResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Result  string `json:"result"`
+}
+
 // gasPriceResult the gas prices for the last block.
 type gasPriceResult struct {
 	LastBlock    string `json:"LastBlock"`
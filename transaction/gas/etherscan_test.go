@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math/big"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -23,9 +24,9 @@ func TestEtherscan(t *testing.T) {
 	t.Run("get gas", func(t *testing.T) {
 		m.setResponse(GasPrices{
 			BaseFee: big.NewInt(30 * oneGwei),
-			SafeLow: big.NewInt(10 * oneGwei),
-			Average: big.NewInt(20 * oneGwei),
-			Fast:    big.NewInt(25 * oneGwei),
+			SafeLow: big.NewInt(40 * oneGwei),
+			Average: big.NewInt(50 * oneGwei),
+			Fast:    big.NewInt(55 * oneGwei),
 		})
 		gp, err := es.GetGasPrices()
 		assert.NoError(t, err)
@@ -38,9 +39,9 @@ func TestEtherscan(t *testing.T) {
 
 		m.setResponse(GasPrices{
 			BaseFee: big.NewInt(50 * oneGwei),
-			SafeLow: big.NewInt(150 * oneGwei),
-			Average: big.NewInt(190 * oneGwei),
-			Fast:    big.NewInt(220 * oneGwei),
+			SafeLow: big.NewInt(200 * oneGwei),
+			Average: big.NewInt(240 * oneGwei),
+			Fast:    big.NewInt(270 * oneGwei),
 		})
 		gp, err = es.GetGasPrices()
 		assert.NoError(t, err)
@@ -55,6 +56,23 @@ func TestEtherscan(t *testing.T) {
 		m.reset()
 	})
 
+	t.Run("tip is never negative", func(t *testing.T) {
+		m.setResponse(GasPrices{
+			BaseFee: big.NewInt(30 * oneGwei),
+			SafeLow: big.NewInt(29 * oneGwei),
+			Average: big.NewInt(30 * oneGwei),
+			Fast:    big.NewInt(31 * oneGwei),
+		})
+		gp, err := es.GetGasPrices()
+		assert.NoError(t, err)
+
+		assert.Equal(t, big.NewInt(0), gp.SafeLow)
+		assert.Equal(t, big.NewInt(0), gp.Average)
+		assert.Equal(t, big.NewInt(oneGwei), gp.Fast)
+
+		m.reset()
+	})
+
 	t.Run("handle error", func(t *testing.T) {
 		m.setHandler(func(c *gin.Context) {
 			c.AbortWithStatus(http.StatusInternalServerError)
@@ -139,3 +157,90 @@ func (m *mockEtherscanApi) getGas(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, resp)
 }
+
+func TestEtherscanRetry(t *testing.T) {
+	var (
+		calls     int
+		responses []func(c *gin.Context)
+	)
+	r := gin.Default()
+	r.GET("/api", func(c *gin.Context) {
+		responses[calls](c)
+		calls++
+	})
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	ok := func(c *gin.Context) {
+		c.JSON(http.StatusOK, etherscanGasPriceResponse{Status: "1", Result: gasPriceResult{
+			SafeGasPrice: "2", ProposeGasPrice: "3", FastGasPrice: "4", SuggestBaseFee: "1",
+		}})
+	}
+	es := NewEtherscanStation(time.Second, "key", server.URL, units.FloatGweiToBigIntWei(100))
+	es.SetRetry(Retry{Attempts: 3, InitialBackoff: time.Millisecond})
+
+	t.Run("retries server errors and rate limits", func(t *testing.T) {
+		calls = 0
+		responses = []func(c *gin.Context){
+			func(c *gin.Context) { c.AbortWithStatus(http.StatusBadGateway) },
+			func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"status": "0", "message": "NOTOK", "result": "Max rate limit reached"})
+			},
+			ok,
+		}
+		gp, err := es.GetGasPrices()
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+		assert.Equal(t, units.FloatGweiToBigIntWei(2), gp.Average)
+	})
+
+	t.Run("gives up after attempts", func(t *testing.T) {
+		calls = 0
+		fail := func(c *gin.Context) { c.AbortWithStatus(http.StatusTooManyRequests) }
+		responses = []func(c *gin.Context){fail, fail, fail, ok}
+		_, err := es.GetGasPrices()
+		assert.ErrorContains(t, err, "status 429")
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("does not retry other errors", func(t *testing.T) {
+		calls = 0
+		responses = []func(c *gin.Context){
+			func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"status": "0", "message": "NOTOK", "result": "Invalid API Key"})
+			},
+			ok,
+		}
+		_, err := es.GetGasPrices()
+		assert.Error(t, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("custom http client", func(t *testing.T) {
+		calls = 0
+		responses = []func(c *gin.Context){ok}
+		var used bool
+		es.SetHTTPClient(&http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			used = true
+			return http.DefaultTransport.RoundTrip(req)
+		})})
+		_, err := es.GetGasPrices()
+		assert.NoError(t, err)
+		assert.True(t, used)
+	})
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestEtherscanForChain(t *testing.T) {
+	es, err := NewEtherscanStationForChain(time.Second, "", 56, big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api.bscscan.com/", es.endpointURI)
+
+	_, err = NewEtherscanStationForChain(time.Second, "", 999999, big.NewInt(1))
+	assert.Error(t, err)
+}
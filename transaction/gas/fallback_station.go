@@ -0,0 +1,141 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/mysteriumnetwork/payments/logging"
+)
+
+// ErrAllStationsFailed is returned when none of the stations returned gas prices.
+var ErrAllStationsFailed = errors.New("all gas stations failed")
+
+// FallbackStation is a station that asks the wrapped stations in order
+// and returns the prices of the first one that succeeds in time.
+type FallbackStation struct {
+	stations []Station
+	timeout  time.Duration
+
+	maxDivergence float64
+}
+
+// NewFallbackStation returns a new fallback station. Each station is given the timeout
+// to respond before the next one is tried, zero means no timeout.
+func NewFallbackStation(timeout time.Duration, stations ...Station) *FallbackStation {
+	return &FallbackStation{
+		stations: stations,
+		timeout:  timeout,
+	}
+}
+
+// CrossValidate enables cross validation of the results. The prices are then
+// compared with the ones of the next station that succeeds and if the total
+// average price of one is more than the given ratio of the other, the
+// cheaper of the two is returned. Zero disables cross validation.
+//
+// This method is not thread safe and should be called before the station is used.
+func (f *FallbackStation) CrossValidate(maxRatio float64) {
+	f.maxDivergence = maxRatio
+}
+
+func (f *FallbackStation) GetGasPrices() (*GasPrices, error) {
+	return f.GetGasPricesContext(context.Background())
+}
+
+// GetGasPricesContext returns gas prices from the first station that succeeds.
+// Stops trying other stations once the context is done.
+func (f *FallbackStation) GetGasPricesContext(ctx context.Context) (*GasPrices, error) {
+	var (
+		errs   []error
+		result *GasPrices
+	)
+	for i, station := range f.stations {
+		prices, err := f.get(ctx, station)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			logging.Default().Error("failed to get gas prices", "error", err, "stationIndex", i)
+			errs = append(errs, err)
+			continue
+		}
+
+		if result == nil {
+			result = prices
+			if f.maxDivergence <= 0 {
+				return result, nil
+			}
+			continue
+		}
+
+		if diverges(result, prices, f.maxDivergence) {
+			logging.Default().Warn("gas stations returned divergent prices", "stationIndex", i, "first", totalAverage(result), "second", totalAverage(prices))
+			if totalAverage(prices).Cmp(totalAverage(result)) < 0 {
+				return prices, nil
+			}
+		}
+		return result, nil
+	}
+
+	if result != nil {
+		return result, nil
+	}
+	return nil, fmt.Errorf("%w: %w", ErrAllStationsFailed, errors.Join(errs...))
+}
+
+// get returns the prices of the station, giving up once the timeout passes
+// even if the station does not accept a context.
+func (f *FallbackStation) get(ctx context.Context, station Station) (*GasPrices, error) {
+	if f.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.timeout)
+		defer cancel()
+	}
+
+	type result struct {
+		prices *GasPrices
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		prices, err := GetGasPricesContext(ctx, station)
+		done <- result{prices: prices, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		if res.err == nil && res.prices == nil {
+			return nil, errors.New("station returned no gas prices")
+		}
+		return res.prices, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func totalAverage(prices *GasPrices) *big.Int {
+	total := new(big.Int)
+	if prices.Average != nil {
+		total.Add(total, prices.Average)
+	}
+	if prices.BaseFee != nil {
+		total.Add(total, prices.BaseFee)
+	}
+	return total
+}
+
+// diverges returns true if the total average price of one is more than the given ratio of the other.
+func diverges(a, b *GasPrices, maxRatio float64) bool {
+	low, high := totalAverage(a), totalAverage(b)
+	if low.Cmp(high) > 0 {
+		low, high = high, low
+	}
+	if low.Sign() == 0 {
+		return high.Sign() != 0
+	}
+	limit, _ := new(big.Float).Mul(new(big.Float).SetInt(low), big.NewFloat(maxRatio)).Int(nil)
+	return high.Cmp(limit) > 0
+}
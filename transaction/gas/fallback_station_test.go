@@ -0,0 +1,69 @@
+package gas
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type slowStationMock struct {
+	delay time.Duration
+}
+
+func (s *slowStationMock) GetGasPrices() (*GasPrices, error) {
+	time.Sleep(s.delay)
+	return NewStaticStation(big.NewInt(1), big.NewInt(1)).GetGasPrices()
+}
+
+func TestFallbackStation(t *testing.T) {
+	t.Run("first station", func(t *testing.T) {
+		fs := NewFallbackStation(time.Second, NewStaticStation(big.NewInt(10), big.NewInt(1)), NewStaticStation(big.NewInt(20), big.NewInt(1)))
+		prices, err := fs.GetGasPrices()
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(10), prices.Average)
+	})
+
+	t.Run("falls back on error and timeout", func(t *testing.T) {
+		fs := NewFallbackStation(10*time.Millisecond, NewFailingStationMock(), &slowStationMock{delay: time.Second}, NewStaticStation(big.NewInt(20), big.NewInt(1)))
+		prices, err := fs.GetGasPrices()
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(20), prices.Average)
+	})
+
+	t.Run("all failed", func(t *testing.T) {
+		fs := NewFallbackStation(time.Second, NewFailingStationMock(), NewFailingStationMock())
+		_, err := fs.GetGasPrices()
+		assert.ErrorIs(t, err, ErrAllStationsFailed)
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		fs := NewFallbackStation(time.Second, NewFailingStationMock(), NewStaticStation(big.NewInt(10), big.NewInt(1)))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := fs.GetGasPricesContext(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("cross validation", func(t *testing.T) {
+		fs := NewFallbackStation(time.Second, NewStaticStation(big.NewInt(1000), big.NewInt(10)), NewFailingStationMock(), NewStaticStation(big.NewInt(20), big.NewInt(10)))
+		fs.CrossValidate(2)
+		prices, err := fs.GetGasPrices()
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(20), prices.Average)
+
+		fs = NewFallbackStation(time.Second, NewStaticStation(big.NewInt(25), big.NewInt(10)), NewStaticStation(big.NewInt(20), big.NewInt(10)))
+		fs.CrossValidate(2)
+		prices, err = fs.GetGasPrices()
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(25), prices.Average)
+
+		fs = NewFallbackStation(time.Second, NewStaticStation(big.NewInt(25), big.NewInt(10)), NewFailingStationMock())
+		fs.CrossValidate(2)
+		prices, err = fs.GetGasPrices()
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(25), prices.Average)
+	})
+}
@@ -0,0 +1,99 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+)
+
+// DefaultFeeHistoryBlocks is the default number of blocks the fee history station looks at.
+const DefaultFeeHistoryBlocks = 20
+
+// Reward percentiles of the fee history mapped to the tiers.
+const (
+	FeeHistorySafeLowPercentile = 10
+	FeeHistoryAveragePercentile = 50
+	FeeHistoryFastPercentile    = 90
+)
+
+// FeeHistoryClient returns the fee market history of a chain.
+type FeeHistoryClient interface {
+	FeeHistory(chainID int64, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+}
+
+// FeeHistoryStation estimates gas prices from the `eth_feeHistory` of the node,
+// so it needs no third party API. The tiers are the medians of the priority fees
+// paid at the 10th, 50th and 90th percentile of the recent blocks, the base fee
+// is the one the node reports for the next block.
+type FeeHistoryStation struct {
+	bc       FeeHistoryClient
+	chainID  int64
+	blocks   uint64
+	feeModel FeeModel
+}
+
+// NewFeeHistoryStation returns a new fee history station looking at the given number of blocks,
+// `DefaultFeeHistoryBlocks` if zero. Tips follow the fee model of the chain, see `FeeModelForChain`.
+func NewFeeHistoryStation(bc FeeHistoryClient, chainID int64, blocks uint64) *FeeHistoryStation {
+	if blocks == 0 {
+		blocks = DefaultFeeHistoryBlocks
+	}
+	return &FeeHistoryStation{bc: bc, chainID: chainID, blocks: blocks, feeModel: FeeModelForChain(chainID)}
+}
+
+func (f *FeeHistoryStation) GetGasPrices() (*GasPrices, error) {
+	return f.GetGasPricesContext(context.Background())
+}
+
+// GetGasPricesContext returns gas prices computed from the fee history.
+// The client call timeouts apply, the context is checked before the call.
+func (f *FeeHistoryStation) GetGasPricesContext(ctx context.Context) (*GasPrices, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	percentiles := []float64{FeeHistorySafeLowPercentile, FeeHistoryAveragePercentile, FeeHistoryFastPercentile}
+	history, err := f.bc.FeeHistory(f.chainID, f.blocks, nil, percentiles)
+	if err != nil {
+		return nil, err
+	}
+	if len(history.BaseFee) == 0 {
+		return nil, errors.New("fee history has no base fees")
+	}
+
+	tips := make([]*big.Int, len(percentiles))
+	for i := range percentiles {
+		tips[i] = f.feeModel.Tip(medianReward(history.Reward, i))
+	}
+
+	return &GasPrices{
+		SafeLow: tips[0],
+		Average: tips[1],
+		Fast:    tips[2],
+		BaseFee: new(big.Int).Set(history.BaseFee[len(history.BaseFee)-1]),
+	}, nil
+}
+
+// medianReward returns the median of the rewards at the given percentile index, zero if there are none.
+func medianReward(rewards [][]*big.Int, idx int) *big.Int {
+	values := make([]*big.Int, 0, len(rewards))
+	for _, r := range rewards {
+		if idx < len(r) && r[idx] != nil {
+			values = append(values, r[idx])
+		}
+	}
+	if len(values) == 0 {
+		return new(big.Int)
+	}
+
+	sort.Slice(values, func(i, j int) bool { return values[i].Cmp(values[j]) < 0 })
+	mid := len(values) / 2
+	if len(values)%2 == 1 {
+		return new(big.Int).Set(values[mid])
+	}
+	sum := new(big.Int).Add(values[mid-1], values[mid])
+	return sum.Div(sum, big.NewInt(2))
+}
@@ -0,0 +1,68 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/mysteriumnetwork/payments/client"
+	"github.com/mysteriumnetwork/payments/client/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeeHistoryStation(t *testing.T) {
+	var percentiles []float64
+	cl := &mocks.EtherClientMock{
+		FeeHistoryFunc: func(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+			percentiles = rewardPercentiles
+			return &ethereum.FeeHistory{
+				BaseFee: []*big.Int{big.NewInt(90), big.NewInt(100), big.NewInt(110), big.NewInt(120)},
+				Reward: [][]*big.Int{
+					{big.NewInt(1), big.NewInt(5), big.NewInt(30)},
+					{big.NewInt(3), big.NewInt(4), big.NewInt(10)},
+					{big.NewInt(2), big.NewInt(6), big.NewInt(20)},
+				},
+			}, nil
+		},
+	}
+	getter := client.NewDefaultAddressableEthClientGetter("", cl)
+	mbc := client.NewMultichainBlockchainClient(map[int64]client.BC{
+		1:     client.NewBlockchain(getter, time.Second),
+		42161: client.NewBlockchain(getter, time.Second),
+	})
+
+	t.Run("get gas", func(t *testing.T) {
+		gp, err := NewFeeHistoryStation(mbc, 1, 3).GetGasPrices()
+		assert.NoError(t, err)
+		assert.Equal(t, []float64{10, 50, 90}, percentiles)
+		assert.Equal(t, uint64(3), cl.FeeHistoryCalls()[0].BlockCount)
+		assert.Equal(t, big.NewInt(2), gp.SafeLow)
+		assert.Equal(t, big.NewInt(5), gp.Average)
+		assert.Equal(t, big.NewInt(20), gp.Fast)
+		assert.Equal(t, big.NewInt(120), gp.BaseFee)
+	})
+
+	t.Run("no tips on arbitrum", func(t *testing.T) {
+		gp, err := NewFeeHistoryStation(mbc, 42161, 3).GetGasPrices()
+		assert.NoError(t, err)
+		assert.Zero(t, gp.Fast.Sign())
+		assert.Equal(t, big.NewInt(120), gp.BaseFee)
+	})
+
+	t.Run("handle error", func(t *testing.T) {
+		cl.FeeHistoryFunc = func(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+			return nil, errors.New("error")
+		}
+		_, err := NewFeeHistoryStation(mbc, 1, 0).GetGasPrices()
+		assert.Error(t, err)
+	})
+}
+
+func TestMedianReward(t *testing.T) {
+	rewards := [][]*big.Int{{big.NewInt(4)}, {big.NewInt(2)}, {}, {big.NewInt(9)}, {big.NewInt(1)}}
+	assert.Equal(t, big.NewInt(3), medianReward(rewards, 0))
+	assert.Zero(t, medianReward(rewards, 1).Sign())
+}
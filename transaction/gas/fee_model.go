@@ -0,0 +1,100 @@
+package gas
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// FeeModel describes how the base fee of a chain evolves and whether tips are paid.
+type FeeModel string
+
+const (
+	// FeeModelEthereum is the standard EIP-1559 model used by Ethereum and Polygon.
+	FeeModelEthereum FeeModel = "ethereum"
+	// FeeModelOPStack is the EIP-1559 model of OP stack chains (Optimism, Base)
+	// which use a higher elasticity and a different base fee change denominator.
+	FeeModelOPStack FeeModel = "op-stack"
+	// FeeModelArbitrum is the model of Arbitrum chains where the base fee is set by ArbOS
+	// and priority fees are not paid to anyone, so tips are always zero.
+	FeeModelArbitrum FeeModel = "arbitrum"
+)
+
+const (
+	opStackElasticityMultiplier     = 6
+	opStackBaseFeeChangeDenominator = 250
+	arbitrumMinBaseFee              = 10_000_000 // 0.01 gwei
+)
+
+// FeeModelForChain returns the fee model of well known chains, defaulting to `FeeModelEthereum`.
+func FeeModelForChain(chainID int64) FeeModel {
+	switch chainID {
+	case 10, 11155420, 8453, 84532:
+		return FeeModelOPStack
+	case 42161, 42170, 421614:
+		return FeeModelArbitrum
+	default:
+		return FeeModelEthereum
+	}
+}
+
+// NextBaseFee estimates the base fee of the block following the given one.
+func (m FeeModel) NextBaseFee(parent *types.Header) *big.Int {
+	switch m {
+	case FeeModelOPStack:
+		if parent.BaseFee == nil {
+			return big.NewInt(params.InitialBaseFee)
+		}
+		return calcBaseFee(parent, opStackElasticityMultiplier, opStackBaseFeeChangeDenominator)
+	case FeeModelArbitrum:
+		if parent.BaseFee == nil || parent.BaseFee.Cmp(big.NewInt(arbitrumMinBaseFee)) < 0 {
+			return big.NewInt(arbitrumMinBaseFee)
+		}
+		return new(big.Int).Set(parent.BaseFee)
+	default:
+		return eip1559.CalcBaseFee(params.MainnetChainConfig, parent)
+	}
+}
+
+// Tip returns the tip to pay given a suggested one.
+func (m FeeModel) Tip(suggested *big.Int) *big.Int {
+	if m == FeeModelArbitrum {
+		return new(big.Int)
+	}
+	return suggested
+}
+
+// calcBaseFee implements the EIP-1559 base fee calculation with the given parameters.
+func calcBaseFee(parent *types.Header, elasticity, denominator uint64) *big.Int {
+	target := parent.GasLimit / elasticity
+	if target == 0 || parent.GasUsed == target {
+		return new(big.Int).Set(parent.BaseFee)
+	}
+
+	var (
+		num   = new(big.Int)
+		denom = new(big.Int)
+	)
+	if parent.GasUsed > target {
+		num.SetUint64(parent.GasUsed - target)
+		num.Mul(num, parent.BaseFee)
+		num.Div(num, denom.SetUint64(target))
+		num.Div(num, denom.SetUint64(denominator))
+		if num.Sign() == 0 {
+			num.SetUint64(1)
+		}
+		return num.Add(parent.BaseFee, num)
+	}
+
+	num.SetUint64(target - parent.GasUsed)
+	num.Mul(num, parent.BaseFee)
+	num.Div(num, denom.SetUint64(target))
+	num.Div(num, denom.SetUint64(denominator))
+	base := num.Sub(parent.BaseFee, num)
+	if base.Sign() < 0 {
+		base.SetUint64(0)
+	}
+	return base
+}
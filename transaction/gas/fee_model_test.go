@@ -0,0 +1,39 @@
+package gas
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeeModel(t *testing.T) {
+	assert.Equal(t, FeeModelEthereum, FeeModelForChain(137))
+	assert.Equal(t, FeeModelOPStack, FeeModelForChain(8453))
+	assert.Equal(t, FeeModelOPStack, FeeModelForChain(10))
+	assert.Equal(t, FeeModelArbitrum, FeeModelForChain(42161))
+
+	t.Run("op stack", func(t *testing.T) {
+		parent := &types.Header{GasLimit: 30_000_000, BaseFee: big.NewInt(1_000_000)}
+
+		parent.GasUsed = 5_000_000
+		assert.Equal(t, big.NewInt(1_000_000), FeeModelOPStack.NextBaseFee(parent))
+
+		parent.GasUsed = 30_000_000
+		assert.Equal(t, big.NewInt(1_020_000), FeeModelOPStack.NextBaseFee(parent))
+
+		parent.GasUsed = 0
+		assert.Equal(t, big.NewInt(996_000), FeeModelOPStack.NextBaseFee(parent))
+
+		assert.Equal(t, big.NewInt(params.InitialBaseFee), FeeModelOPStack.NextBaseFee(&types.Header{}))
+		assert.Equal(t, big.NewInt(5), FeeModelOPStack.Tip(big.NewInt(5)))
+	})
+
+	t.Run("arbitrum", func(t *testing.T) {
+		assert.Equal(t, big.NewInt(100_000_000), FeeModelArbitrum.NextBaseFee(&types.Header{BaseFee: big.NewInt(100_000_000)}))
+		assert.Equal(t, big.NewInt(arbitrumMinBaseFee), FeeModelArbitrum.NextBaseFee(&types.Header{}))
+		assert.Equal(t, new(big.Int), FeeModelArbitrum.Tip(big.NewInt(5)))
+	})
+}
@@ -0,0 +1,46 @@
+package gas
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// ErrMaxFeeExceeded is returned when the base fee alone is above the max fee per gas of the chain.
+var ErrMaxFeeExceeded = errors.New("base fee is above the max fee per gas")
+
+// FeeCaps are the fee limits of a chain, a nil limit is not enforced.
+type FeeCaps struct {
+	// MaxPriorityFee is the highest tip paid per gas.
+	MaxPriorityFee *big.Int
+	// MaxFeePerGas is the highest base fee and tip paid per gas.
+	MaxFeePerGas *big.Int
+}
+
+// ChainFeePolicy holds the fee limits by chain ID. It is consulted before a transaction
+// is signed, so a misbehaving gas station can not make us pay absurd tips.
+type ChainFeePolicy map[int64]FeeCaps
+
+// Tip returns the tip capped by the limits of the chain. The tip is lowered so the base fee
+// with the tip stays within the max fee per gas, `ErrMaxFeeExceeded` is returned if the base
+// fee alone is above it. Chains without limits return the tip as is.
+func (p ChainFeePolicy) Tip(chainID int64, tip, baseFee *big.Int) (*big.Int, error) {
+	caps, ok := p[chainID]
+	if !ok || tip == nil {
+		return tip, nil
+	}
+
+	res := new(big.Int).Set(tip)
+	if caps.MaxPriorityFee != nil && res.Cmp(caps.MaxPriorityFee) > 0 {
+		res.Set(caps.MaxPriorityFee)
+	}
+	if caps.MaxFeePerGas != nil && baseFee != nil {
+		if baseFee.Cmp(caps.MaxFeePerGas) > 0 {
+			return nil, fmt.Errorf("%w of chain %d: %s > %s", ErrMaxFeeExceeded, chainID, baseFee, caps.MaxFeePerGas)
+		}
+		if room := new(big.Int).Sub(caps.MaxFeePerGas, baseFee); res.Cmp(room) > 0 {
+			res = room
+		}
+	}
+	return res, nil
+}
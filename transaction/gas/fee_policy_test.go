@@ -0,0 +1,30 @@
+package gas
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChainFeePolicy(t *testing.T) {
+	p := ChainFeePolicy{
+		137: {MaxPriorityFee: big.NewInt(50), MaxFeePerGas: big.NewInt(200)},
+		1:   {MaxFeePerGas: big.NewInt(100)},
+	}
+
+	tip, err := p.Tip(137, big.NewInt(500), big.NewInt(100))
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(50), tip)
+
+	tip, err = p.Tip(137, big.NewInt(40), big.NewInt(170))
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(30), tip)
+
+	_, err = p.Tip(1, big.NewInt(1), big.NewInt(101))
+	assert.ErrorIs(t, err, ErrMaxFeeExceeded)
+
+	tip, err = p.Tip(5, big.NewInt(500), big.NewInt(100))
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(500), tip)
+}
@@ -0,0 +1,96 @@
+package gas
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/mysteriumnetwork/payments/client"
+)
+
+// L2Cost is the estimated cost of a rollup transaction in wei.
+type L2Cost struct {
+	// ExecutionFee is the L2 gas limit times the base fee with the average tip.
+	ExecutionFee *big.Int
+	// L1Fee is the fee paid for posting the transaction data to L1.
+	L1Fee *big.Int
+	// Total is the sum of both fees.
+	Total *big.Int
+}
+
+// L2Station is a gas station for rollups where the L1 data fee often dominates
+// the cost. Gas prices come from the wrapped station, `EstimateCost` adds the L1
+// data fee queried from the predeployed contracts of the chain.
+type L2Station struct {
+	station  Station
+	caller   ethereum.ContractCaller
+	chainID  int64
+	feeModel FeeModel
+}
+
+// NewL2Station returns a new L2 station using the fee model of the chain, see `FeeModelForChain`.
+func NewL2Station(station Station, caller ethereum.ContractCaller, chainID int64) *L2Station {
+	return &L2Station{
+		station:  station,
+		caller:   caller,
+		chainID:  chainID,
+		feeModel: FeeModelForChain(chainID),
+	}
+}
+
+func (l *L2Station) GetGasPrices() (*GasPrices, error) {
+	return l.GetGasPricesContext(context.Background())
+}
+
+// GetGasPricesContext returns the gas prices of the wrapped station.
+func (l *L2Station) GetGasPricesContext(ctx context.Context) (*GasPrices, error) {
+	return GetGasPricesContext(ctx, l.station)
+}
+
+// EstimateCost returns the total estimated cost of a transaction calling the given
+// contract with the data and gas limit. Chains using the Ethereum fee model have no L1 fee.
+func (l *L2Station) EstimateCost(ctx context.Context, to common.Address, data []byte, gasLimit uint64) (*L2Cost, error) {
+	prices, err := l.GetGasPricesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tip := l.feeModel.Tip(prices.Average)
+	price := new(big.Int).Add(tip, prices.BaseFee)
+	cost := &L2Cost{
+		ExecutionFee: new(big.Int).Mul(price, new(big.Int).SetUint64(gasLimit)),
+		L1Fee:        new(big.Int),
+	}
+
+	switch l.feeModel {
+	case FeeModelOPStack:
+		raw, err := types.NewTx(&types.DynamicFeeTx{
+			ChainID:   big.NewInt(l.chainID),
+			GasTipCap: tip,
+			GasFeeCap: price,
+			Gas:       gasLimit,
+			To:        &to,
+			Data:      data,
+		}).MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode transaction: %w", err)
+		}
+		cost.L1Fee, err = client.EstimateOPStackL1Fee(ctx, l.caller, raw)
+		if err != nil {
+			return nil, err
+		}
+	case FeeModelArbitrum:
+		component, err := client.EstimateArbitrumL1Component(ctx, l.caller, to, data)
+		if err != nil {
+			return nil, err
+		}
+		cost.L1Fee = component.Fee()
+	}
+
+	cost.Total = new(big.Int).Add(cost.ExecutionFee, cost.L1Fee)
+	return cost, nil
+}
@@ -0,0 +1,57 @@
+package gas
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/payments/client"
+	"github.com/mysteriumnetwork/payments/client/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestL2Station(t *testing.T) {
+	to := common.HexToAddress("0x1")
+	var called common.Address
+	cl := &mocks.EtherClientMock{
+		CallContractFunc: func(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+			called = *msg.To
+			if called == client.ArbitrumNodeInterface {
+				out := make([]byte, 96)
+				out[31] = 100 // gas for L1
+				out[63] = 5   // base fee
+				out[95] = 30  // L1 base fee
+				return out, nil
+			}
+			return common.LeftPadBytes(big.NewInt(5000).Bytes(), 32), nil
+		},
+	}
+	station := NewStaticStation(big.NewInt(2), big.NewInt(8))
+
+	t.Run("op stack", func(t *testing.T) {
+		cost, err := NewL2Station(station, cl, 10).EstimateCost(context.Background(), to, []byte{1, 2}, 1000)
+		assert.NoError(t, err)
+		assert.Equal(t, client.OPStackGasPriceOracle, called)
+		assert.Equal(t, big.NewInt(10000), cost.ExecutionFee)
+		assert.Equal(t, big.NewInt(5000), cost.L1Fee)
+		assert.Equal(t, big.NewInt(15000), cost.Total)
+	})
+
+	t.Run("arbitrum", func(t *testing.T) {
+		cost, err := NewL2Station(station, cl, 42161).EstimateCost(context.Background(), to, []byte{1, 2}, 1000)
+		assert.NoError(t, err)
+		assert.Equal(t, client.ArbitrumNodeInterface, called)
+		assert.Equal(t, big.NewInt(8000), cost.ExecutionFee)
+		assert.Equal(t, big.NewInt(500), cost.L1Fee)
+		assert.Equal(t, big.NewInt(8500), cost.Total)
+	})
+
+	t.Run("no l1 fee", func(t *testing.T) {
+		cost, err := NewL2Station(station, cl, 137).EstimateCost(context.Background(), to, nil, 1000)
+		assert.NoError(t, err)
+		assert.Zero(t, cost.L1Fee.Sign())
+		assert.Equal(t, big.NewInt(10000), cost.Total)
+	})
+}
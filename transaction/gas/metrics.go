@@ -0,0 +1,49 @@
+package gas
+
+import (
+	"context"
+	"time"
+)
+
+// StationMetrics receives the results of gas price requests for metric reporting.
+type StationMetrics interface {
+	// GasPricesRequested is called after every request of the named station with its
+	// latency, the returned prices on success or the error on failure.
+	GasPricesRequested(station string, latency time.Duration, prices *GasPrices, err error)
+}
+
+type stationMetricsNoop struct{}
+
+func (s *stationMetricsNoop) GasPricesRequested(_ string, _ time.Duration, _ *GasPrices, _ error) {}
+
+// InstrumentedStation wraps a station and reports every request to the metrics.
+type InstrumentedStation struct {
+	name    string
+	station Station
+	metrics StationMetrics
+}
+
+// NewInstrumentedStation returns a new instrumented station reporting under the given name.
+// A nil metrics reporter reports nothing.
+func NewInstrumentedStation(name string, station Station, metrics StationMetrics) *InstrumentedStation {
+	if metrics == nil {
+		metrics = &stationMetricsNoop{}
+	}
+	return &InstrumentedStation{
+		name:    name,
+		station: station,
+		metrics: metrics,
+	}
+}
+
+func (i *InstrumentedStation) GetGasPrices() (*GasPrices, error) {
+	return i.GetGasPricesContext(context.Background())
+}
+
+// GetGasPricesContext returns the prices of the wrapped station and reports the request.
+func (i *InstrumentedStation) GetGasPricesContext(ctx context.Context) (*GasPrices, error) {
+	start := time.Now()
+	prices, err := GetGasPricesContext(ctx, i.station)
+	i.metrics.GasPricesRequested(i.name, time.Since(start), prices, err)
+	return prices, err
+}
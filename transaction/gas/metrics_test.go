@@ -0,0 +1,56 @@
+package gas
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type metricsMock struct {
+	stations []string
+	errs     []error
+}
+
+func (m *metricsMock) GasPricesRequested(station string, _ time.Duration, _ *GasPrices, err error) {
+	m.stations = append(m.stations, station)
+	m.errs = append(m.errs, err)
+}
+
+func TestInstrumentedStation(t *testing.T) {
+	m := &metricsMock{}
+	_, err := NewInstrumentedStation("static", NewStaticStation(big.NewInt(1), big.NewInt(1)), m).GetGasPrices()
+	assert.NoError(t, err)
+	_, err = NewInstrumentedStation("failing", NewFailingStationMock(), m).GetGasPrices()
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{"static", "failing"}, m.stations)
+	assert.NoError(t, m.errs[0])
+	assert.Error(t, m.errs[1])
+
+	_, err = NewInstrumentedStation("noop", NewStaticStation(big.NewInt(1), big.NewInt(1)), nil).GetGasPrices()
+	assert.NoError(t, err)
+}
+
+func TestPrometheusMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := NewPrometheusMetrics("payments", reg)
+	assert.NoError(t, err)
+
+	oneGwei := big.NewInt(1000000000)
+	_, err = NewInstrumentedStation("static", NewStaticStation(oneGwei, oneGwei), m).GetGasPrices()
+	assert.NoError(t, err)
+	_, err = NewInstrumentedStation("failing", NewFailingStationMock(), m).GetGasPrices()
+	assert.Error(t, err)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.requests.WithLabelValues("static")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.failures.WithLabelValues("static")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.failures.WithLabelValues("failing")))
+	assert.Equal(t, 4, testutil.CollectAndCount(m.prices))
+
+	_, err = NewPrometheusMetrics("payments", reg)
+	assert.Error(t, err, "metrics are registered only once")
+}
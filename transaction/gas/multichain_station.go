@@ -1,25 +1,54 @@
 package gas
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sort"
 
-	"github.com/rs/zerolog/log"
+	"github.com/mysteriumnetwork/payments/logging"
 )
 
+// ErrNoStations is returned for chains which have no gas stations.
+var ErrNoStations = errors.New("no gas stations for chain")
+
 // MultichainStation is a station that can hold multiple station
-// and call them depending on the chain given.
+// and call them depending on the chain given. It satisfies the
+// `GasStation` of the transaction package, so gas prices of every
+// chain the depot sends to come from the stations of that chain.
 type MultichainStation map[int64][]Station
 
+// Chains returns the sorted IDs of the chains which have gas stations.
+func (m MultichainStation) Chains() []int64 {
+	chains := make([]int64, 0, len(m))
+	for chainID, stations := range m {
+		if len(stations) > 0 {
+			chains = append(chains, chainID)
+		}
+	}
+	sort.Slice(chains, func(i, j int) bool { return chains[i] < chains[j] })
+	return chains
+}
+
 func (m MultichainStation) GetGasPrices(chainID int64) (*GasPrices, error) {
-	stations, ok := m[chainID]
-	if !ok {
-		return nil, fmt.Errorf("no gas stations for chain %d", chainID)
+	return m.GetGasPricesContext(context.Background(), chainID)
+}
+
+// GetGasPricesContext returns gas prices from the first station of the chain that succeeds.
+// Stops trying other stations once the context is done.
+func (m MultichainStation) GetGasPricesContext(ctx context.Context, chainID int64) (*GasPrices, error) {
+	stations := m[chainID]
+	if len(stations) == 0 {
+		return nil, fmt.Errorf("%w %d", ErrNoStations, chainID)
 	}
 
 	for i, station := range stations {
-		prices, err := station.GetGasPrices()
+		prices, err := GetGasPricesContext(ctx, station)
 		if err != nil {
-			log.Error().Err(err).Int64("chainID", chainID).Int("stationIndex", i).Msg("failed to get gas prices")
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			logging.Default().Error("failed to get gas prices", "error", err, "chainID", chainID, "stationIndex", i)
 			continue
 		}
 		return prices, nil
@@ -1,6 +1,7 @@
 package gas
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/big"
@@ -42,6 +43,29 @@ func TestMultichain(t *testing.T) {
 		prices, err := mq.GetGasPrices(2)
 
 		assert.Equal(t, fmt.Sprint(err), "no gas stations for chain 2")
+		assert.ErrorIs(t, err, ErrNoStations)
+		assert.Nil(t, prices)
+	})
+	t.Run("chains", func(t *testing.T) {
+		mq := MultichainStation{
+			137: []Station{NewStaticStation(big.NewInt(10), big.NewInt(1))},
+			1:   []Station{NewStaticStation(big.NewInt(10), big.NewInt(1))},
+			5:   nil,
+		}
+		assert.Equal(t, []int64{1, 137}, mq.Chains())
+
+		_, err := mq.GetGasPrices(5)
+		assert.ErrorIs(t, err, ErrNoStations)
+	})
+	t.Run("context canceled", func(t *testing.T) {
+		mq := MultichainStation{
+			1: []Station{NewFailingStationMock(), NewStaticStation(big.NewInt(10), big.NewInt(1))},
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		prices, err := mq.GetGasPricesContext(ctx, 1)
+		assert.ErrorIs(t, err, context.Canceled)
 		assert.Nil(t, prices)
 	})
 }
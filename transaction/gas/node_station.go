@@ -1,20 +1,26 @@
 package gas
 
 import (
+	"context"
 	"math/big"
 
-	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/params"
 )
 
 type NodeStation struct {
-	bc      BCClient
-	chainID int64
+	bc       BCClient
+	chainID  int64
+	feeModel FeeModel
 }
 
+// NewNodeStation returns a new node station using the fee model of the chain, see `FeeModelForChain`.
 func NewNodeStation(bc BCClient, chainID int64) *NodeStation {
-	return &NodeStation{bc: bc, chainID: chainID}
+	return NewNodeStationWithFeeModel(bc, chainID, FeeModelForChain(chainID))
+}
+
+// NewNodeStationWithFeeModel returns a new node station using the given fee model.
+func NewNodeStationWithFeeModel(bc BCClient, chainID int64, model FeeModel) *NodeStation {
+	return &NodeStation{bc: bc, chainID: chainID, feeModel: model}
 }
 
 type BCClient interface {
@@ -24,22 +30,36 @@ type BCClient interface {
 }
 
 func (n *NodeStation) GetGasPrices() (*GasPrices, error) {
+	return n.GetGasPricesContext(context.Background())
+}
+
+// GetGasPricesContext returns gas prices suggested by the node.
+// The client call timeouts apply, the context is checked before every call.
+func (n *NodeStation) GetGasPricesContext(ctx context.Context) (*GasPrices, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	suggestGasPrice, err := n.bc.SuggestGasPrice(n.chainID)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	header, err := n.bc.HeaderByNumber(n.chainID, nil)
 	if err != nil {
 		return nil, err
 	}
-	config := params.MainnetChainConfig
-	baseFee := eip1559.CalcBaseFee(config, header)
+	baseFee := n.feeModel.NextBaseFee(header)
+	tip := n.feeModel.Tip(suggestGasPrice)
 
 	return &GasPrices{
-		SafeLow: suggestGasPrice,
-		Average: suggestGasPrice,
-		Fast:    suggestGasPrice,
+		SafeLow: tip,
+		Average: tip,
+		Fast:    tip,
 		BaseFee: baseFee,
 	}, nil
 }
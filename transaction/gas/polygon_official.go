@@ -1,20 +1,33 @@
 package gas
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"math/big"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/mysteriumnetwork/payments/units"
 )
 
+// Polygon gas station (v2) URLs.
+const (
+	// PolygonGasStationMainnetURI is the gas station of the Polygon PoS mainnet.
+	PolygonGasStationMainnetURI = "https://gasstation.polygon.technology/v2"
+	// PolygonGasStationAmoyURI is the gas station of the Polygon Amoy testnet.
+	PolygonGasStationAmoyURI = "https://gasstation.polygon.technology/amoy"
+)
+
 // DefaultMaticStationURI is the default gas station URL that can be used in matic gas station.
-// Default URL is for mainnet of matic gas station service.
-const DefaultMaticStationURI = "https://gasstation-mainnet.matic.network/v2"
+// Default URL is for mainnet of the polygon gas station service.
+const DefaultMaticStationURI = PolygonGasStationMainnetURI
 
-// MaticStation represents matic gas station api.
+// MaticStation represents the polygon gas station api. The tiers are the
+// max priority fees of the safe low, standard and fast levels. Responses
+// of both the v2 and the older v1 format are supported.
 type MaticStation struct {
 	apiURL     string
 	client     *http.Client
@@ -51,7 +64,12 @@ func NewMaticStation(apiURL string, upperBound *big.Int) *MaticStation {
 }
 
 func (m *MaticStation) GetGasPrices() (*GasPrices, error) {
-	resp, err := m.request()
+	return m.GetGasPricesContext(context.Background())
+}
+
+// GetGasPricesContext returns gas prices using the given context for the request.
+func (m *MaticStation) GetGasPricesContext(ctx context.Context) (*GasPrices, error) {
+	resp, err := m.request(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -74,8 +92,13 @@ func (m *MaticStation) result(price float64) *big.Int {
 	return priceMaxUpperBound(bp, m.upperBound)
 }
 
-func (m *MaticStation) request() (*maticGasPriceResp, error) {
-	resp, err := m.client.Get(m.apiURL)
+func (m *MaticStation) request(ctx context.Context) (*maticGasPriceResp, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := m.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -87,10 +110,52 @@ func (m *MaticStation) request() (*maticGasPriceResp, error) {
 		return nil, err
 	}
 
-	var price maticGasPriceResp
-	if err := json.Unmarshal(body, &price); err != nil {
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("polygon gas station responded with status %d and body: %s", resp.StatusCode, string(body))
+	}
+
+	return parseMaticGasPrices(body)
+}
+
+// maticGasPriceV1Resp is the response of the first version of the gas station
+// with plain gas prices in gwei per level.
+type maticGasPriceV1Resp struct {
+	BlockNumber int64   `json:"blockNumber"`
+	BlockTime   float64 `json:"blockTime"`
+	SafeLow     float64 `json:"safeLow"`
+	Standard    float64 `json:"standard"`
+	Fast        float64 `json:"fast"`
+	Fastest     float64 `json:"fastest"`
+}
+
+// parseMaticGasPrices parses both the v1 and the v2 response of the gas station.
+// The v1 prices are used as tips of a zero base fee, so the max fee per gas
+// equals the price.
+func parseMaticGasPrices(body []byte) (*maticGasPriceResp, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
 		return nil, err
 	}
 
+	standard := strings.TrimSpace(string(fields["standard"]))
+	if strings.HasPrefix(standard, "{") {
+		var price maticGasPriceResp
+		if err := json.Unmarshal(body, &price); err != nil {
+			return nil, err
+		}
+		return &price, nil
+	}
+	if standard == "" {
+		return nil, fmt.Errorf("unknown gas station response format: %s", string(body))
+	}
+
+	var v1 maticGasPriceV1Resp
+	if err := json.Unmarshal(body, &v1); err != nil {
+		return nil, err
+	}
+	price := maticGasPriceResp{BlockNumber: v1.BlockNumber, BlockTime: int64(v1.BlockTime)}
+	price.SafeLow.MaxPriorityFee, price.SafeLow.MaxFee = v1.SafeLow, v1.SafeLow
+	price.Standard.MaxPriorityFee, price.Standard.MaxFee = v1.Standard, v1.Standard
+	price.Fast.MaxPriorityFee, price.Fast.MaxFee = v1.Fast, v1.Fast
 	return &price, nil
 }
@@ -62,6 +62,16 @@ func TestPolygonOfficial(t *testing.T) {
 
 		m.reset()
 	})
+
+	t.Run("handle error status with json body", func(t *testing.T) {
+		m.setHandler(func(c *gin.Context) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "unavailable"})
+		})
+		_, err := es.GetGasPrices()
+		assert.ErrorContains(t, err, "status 503")
+
+		m.reset()
+	})
 }
 
 type mockPolygonOfficialApi struct {
@@ -141,3 +151,29 @@ func (m *mockPolygonOfficialApi) getGas(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, resp)
 }
+
+func TestParseMaticGasPrices(t *testing.T) {
+	t.Run("v2", func(t *testing.T) {
+		p, err := parseMaticGasPrices([]byte(`{"safeLow":{"maxPriorityFee":30.1,"maxFee":40},"standard":{"maxPriorityFee":32,"maxFee":42},"fast":{"maxPriorityFee":35,"maxFee":45},"estimatedBaseFee":10,"blockTime":2,"blockNumber":100}`))
+		assert.NoError(t, err)
+		assert.Equal(t, 32.0, p.Standard.MaxPriorityFee)
+		assert.Equal(t, 10.0, p.EstimatedBaseFee)
+	})
+
+	t.Run("v1", func(t *testing.T) {
+		p, err := parseMaticGasPrices([]byte(`{"safeLow":30,"standard":33.5,"fast":40,"fastest":50,"blockTime":2,"blockNumber":100}`))
+		assert.NoError(t, err)
+		assert.Equal(t, 30.0, p.SafeLow.MaxPriorityFee)
+		assert.Equal(t, 33.5, p.Standard.MaxPriorityFee)
+		assert.Equal(t, 40.0, p.Fast.MaxFee)
+		assert.Zero(t, p.EstimatedBaseFee)
+		assert.Equal(t, int64(100), p.BlockNumber)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		_, err := parseMaticGasPrices([]byte(`{"error":"unavailable"}`))
+		assert.Error(t, err)
+		_, err = parseMaticGasPrices([]byte(`[]`))
+		assert.Error(t, err)
+	})
+}
@@ -1,6 +1,7 @@
 package gas
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,8 +11,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/mysteriumnetwork/payments/logging"
 	"github.com/mysteriumnetwork/payments/units"
-	"github.com/rs/zerolog/log"
 )
 
 // DefaultPolygonscanEndpointURI the default polygonscan api endpoint.
@@ -44,7 +45,12 @@ func NewPolygonscanStation(timeout time.Duration, apiKey, endpointURI string, up
 }
 
 func (esa *PolygonscanStation) GetGasPrices() (*GasPrices, error) {
-	res, err := esa.request()
+	return esa.GetGasPricesContext(context.Background())
+}
+
+// GetGasPricesContext returns gas prices using the given context for the request.
+func (esa *PolygonscanStation) GetGasPricesContext(ctx context.Context) (*GasPrices, error) {
+	res, err := esa.request(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -74,12 +80,17 @@ func (esa *PolygonscanStation) GetGasPrices() (*GasPrices, error) {
 	return &prices, nil
 }
 
-func (esa *PolygonscanStation) request() (*polygonscanGasPriceResponse, error) {
+func (esa *PolygonscanStation) request(ctx context.Context) (*polygonscanGasPriceResponse, error) {
 	if esa.apiKey == "" {
-		log.Warn().Msg("no API key set, rate is limited")
+		logging.Default().Warn("no API key set, rate is limited", "provider", "polygonscan")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%v%v%v", esa.endpointURI, "api?module=gastracker&action=gasoracle&apikey=", esa.apiKey), nil)
+	if err != nil {
+		return nil, err
 	}
 
-	response, err := esa.client.Get(fmt.Sprintf("%v%v%v", esa.endpointURI, "api?module=gastracker&action=gasoracle&apikey=", esa.apiKey))
+	response, err := esa.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
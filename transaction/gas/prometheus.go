@@ -0,0 +1,83 @@
+package gas
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/mysteriumnetwork/payments/units"
+)
+
+// PrometheusMetrics reports the gas station requests as prometheus metrics:
+// request latency, failures and the returned prices in gwei per tier.
+type PrometheusMetrics struct {
+	requests *prometheus.CounterVec
+	failures *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	prices   *prometheus.HistogramVec
+}
+
+// NewPrometheusMetrics returns new prometheus metrics of the gas stations registered with the registerer.
+func NewPrometheusMetrics(namespace string, reg prometheus.Registerer) (*PrometheusMetrics, error) {
+	m := &PrometheusMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "gas_station",
+			Name:      "requests_total",
+			Help:      "Number of gas price requests.",
+		}, []string{"station"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "gas_station",
+			Name:      "failures_total",
+			Help:      "Number of failed gas price requests.",
+		}, []string{"station"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "gas_station",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of gas price requests.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"station"}),
+		prices: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "gas_station",
+			Name:      "price_gwei",
+			Help:      "Gas prices returned by the stations in gwei, the tier is safe_low, average, fast or base_fee.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 20),
+		}, []string{"station", "tier"}),
+	}
+
+	for _, c := range []prometheus.Collector{m.requests, m.failures, m.latency, m.prices} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// GasPricesRequested records the request.
+func (m *PrometheusMetrics) GasPricesRequested(station string, latency time.Duration, prices *GasPrices, err error) {
+	m.requests.WithLabelValues(station).Inc()
+	m.latency.WithLabelValues(station).Observe(latency.Seconds())
+	if err != nil {
+		m.failures.WithLabelValues(station).Inc()
+		return
+	}
+	if prices == nil {
+		return
+	}
+
+	m.observePrice(station, "safe_low", prices.SafeLow)
+	m.observePrice(station, "average", prices.Average)
+	m.observePrice(station, "fast", prices.Fast)
+	m.observePrice(station, "base_fee", prices.BaseFee)
+}
+
+func (m *PrometheusMetrics) observePrice(station, tier string, price *big.Int) {
+	if price == nil {
+		return
+	}
+	m.prices.WithLabelValues(station, tier).Observe(units.BigIntWeiToFloatGwei(price))
+}
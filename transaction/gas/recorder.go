@@ -0,0 +1,268 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/payments/logging"
+)
+
+// ErrNoSamples is returned by queries over a period without recorded samples.
+var ErrNoSamples = errors.New("no gas price samples")
+
+// ErrInvalidInterval is returned when creating a recorder with a sampling interval which is not positive.
+var ErrInvalidInterval = errors.New("recorder interval must be positive")
+
+// Sample is a gas price sample of a chain.
+type Sample struct {
+	ChainID int64     `json:"chainID"`
+	Time    time.Time `json:"time"`
+	Prices  GasPrices `json:"prices"`
+}
+
+// SampleStore persists gas price samples.
+type SampleStore interface {
+	// Append stores the sample.
+	Append(s Sample) error
+	// Samples returns the samples of the chain taken at or after the given time, oldest first.
+	Samples(chainID int64, since time.Time) ([]Sample, error)
+	// Prune removes the samples taken before the given time.
+	Prune(before time.Time) error
+}
+
+// MemoryStore is a sample store keeping the samples in memory.
+type MemoryStore struct {
+	samples []Sample
+	mu      sync.Mutex
+}
+
+// NewMemoryStore returns a new in memory sample store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Append stores the sample.
+func (m *MemoryStore) Append(s Sample) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.samples = append(m.samples, s)
+	return nil
+}
+
+// Samples returns the samples of the chain taken at or after the given time.
+func (m *MemoryStore) Samples(chainID int64, since time.Time) ([]Sample, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var res []Sample
+	for _, s := range m.samples {
+		if s.ChainID == chainID && !s.Time.Before(since) {
+			res = append(res, s)
+		}
+	}
+	return res, nil
+}
+
+// Prune removes the samples taken before the given time.
+func (m *MemoryStore) Prune(before time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kept := m.samples[:0]
+	for _, s := range m.samples {
+		if !s.Time.Before(before) {
+			kept = append(kept, s)
+		}
+	}
+	m.samples = kept
+	return nil
+}
+
+// RecorderConfig configures the recorder.
+type RecorderConfig struct {
+	// Interval between two samples.
+	Interval time.Duration
+	// Retention is how long samples are kept, zero keeps them forever.
+	Retention time.Duration
+}
+
+// Recorder samples the prices of a chain's station and answers historical queries,
+// like the 90th percentile of the fast price over the last 6 hours. It can be used
+// to defer non urgent transactions to cheaper periods.
+type Recorder struct {
+	station Station
+	chainID int64
+	store   SampleStore
+	cfg     RecorderConfig
+
+	now  func() time.Time
+	stop chan struct{}
+	once sync.Once
+}
+
+// NewRecorder returns a new recorder of the station of the given chain,
+// `ErrInvalidInterval` is returned if the interval is not positive.
+func NewRecorder(station Station, chainID int64, store SampleStore, cfg RecorderConfig) (*Recorder, error) {
+	if cfg.Interval <= 0 {
+		return nil, ErrInvalidInterval
+	}
+	return &Recorder{
+		station: station,
+		chainID: chainID,
+		store:   store,
+		cfg:     cfg,
+		now:     time.Now,
+		stop:    make(chan struct{}),
+	}, nil
+}
+
+// Run starts sampling every interval in the background.
+func (r *Recorder) Run() {
+	go func() {
+		for {
+			if err := r.Sample(context.Background()); err != nil {
+				logging.Default().Error("failed to sample gas prices", "chainID", r.chainID, "error", err)
+			}
+
+			select {
+			case <-r.stop:
+				return
+			case <-time.After(r.cfg.Interval):
+			}
+		}
+	}()
+}
+
+// Stop stops sampling.
+func (r *Recorder) Stop() {
+	r.once.Do(func() {
+		close(r.stop)
+	})
+}
+
+// Sample records the current prices of the station and prunes expired samples.
+func (r *Recorder) Sample(ctx context.Context) error {
+	prices, err := GetGasPricesContext(ctx, r.station)
+	if err != nil {
+		return fmt.Errorf("failed to get gas prices: %w", err)
+	}
+
+	now := r.now()
+	if err := r.store.Append(Sample{ChainID: r.chainID, Time: now, Prices: *copyPrices(prices)}); err != nil {
+		return fmt.Errorf("failed to store gas price sample: %w", err)
+	}
+	if r.cfg.Retention > 0 {
+		if err := r.store.Prune(now.Add(-r.cfg.Retention)); err != nil {
+			return fmt.Errorf("failed to prune gas price samples: %w", err)
+		}
+	}
+	return nil
+}
+
+// Percentile returns the given percentile (0-100) of the max fee per gas of the tier,
+// base fee and tip, sampled over the given period until now.
+func (r *Recorder) Percentile(tier Tier, percentile float64, period time.Duration) (*big.Int, error) {
+	if percentile < 0 || percentile > 100 {
+		return nil, fmt.Errorf("percentile %v is out of range", percentile)
+	}
+
+	samples, err := r.store.Samples(r.chainID, r.now().Add(-period))
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]*big.Int, 0, len(samples))
+	for _, s := range samples {
+		if price := s.Prices.MaxFeePerGas(tier, 1, nil); price != nil {
+			values = append(values, price)
+		}
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("%w for chain %d in the last %s", ErrNoSamples, r.chainID, period)
+	}
+
+	sort.Slice(values, func(i, j int) bool { return values[i].Cmp(values[j]) < 0 })
+	// Nearest rank percentile.
+	rank := int(math.Ceil(percentile / 100 * float64(len(values))))
+	if rank < 1 {
+		rank = 1
+	}
+	return values[rank-1], nil
+}
+
+// IsCheap returns true if the current max fee per gas of the tier is at or below
+// the given percentile of the period, so a non urgent transaction can be sent now.
+func (r *Recorder) IsCheap(ctx context.Context, tier Tier, percentile float64, period time.Duration) (bool, error) {
+	threshold, err := r.Percentile(tier, percentile, period)
+	if err != nil {
+		return false, err
+	}
+
+	prices, err := GetGasPricesContext(ctx, r.station)
+	if err != nil {
+		return false, err
+	}
+	current := prices.MaxFeePerGas(tier, 1, nil)
+	if current == nil {
+		return false, fmt.Errorf("unknown tier %q", tier)
+	}
+	return current.Cmp(threshold) <= 0, nil
+}
+
+// NextOffPeak returns the start of the next hour of the day, in UTC, with the lowest average max fee
+// per gas of the tier sampled over the given period, or now if the current hour is the cheapest one.
+// Non urgent transactions can be scheduled for it.
+func (r *Recorder) NextOffPeak(tier Tier, period time.Duration) (time.Time, error) {
+	now := r.now().UTC()
+	samples, err := r.store.Samples(r.chainID, now.Add(-period))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var (
+		sums   [24]*big.Int
+		counts [24]int64
+	)
+	for _, s := range samples {
+		price := s.Prices.MaxFeePerGas(tier, 1, nil)
+		if price == nil {
+			continue
+		}
+		h := s.Time.UTC().Hour()
+		if sums[h] == nil {
+			sums[h] = new(big.Int)
+		}
+		sums[h].Add(sums[h], price)
+		counts[h]++
+	}
+
+	cheapest := -1
+	var lowest *big.Int
+	for h := range sums {
+		if counts[h] == 0 {
+			continue
+		}
+		avg := new(big.Int).Div(sums[h], big.NewInt(counts[h]))
+		if lowest == nil || avg.Cmp(lowest) < 0 {
+			cheapest, lowest = h, avg
+		}
+	}
+	if cheapest < 0 {
+		return time.Time{}, fmt.Errorf("%w for chain %d in the last %s", ErrNoSamples, r.chainID, period)
+	}
+
+	if now.Hour() == cheapest {
+		return now, nil
+	}
+	start := time.Date(now.Year(), now.Month(), now.Day(), cheapest, 0, 0, 0, time.UTC)
+	if start.Before(now) {
+		start = start.AddDate(0, 0, 1)
+	}
+	return start, nil
+}
@@ -0,0 +1,78 @@
+package gas
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	station := &countingStationMock{}
+	store := NewMemoryStore()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	r, err := NewRecorder(station, 137, store, RecorderConfig{Interval: time.Hour, Retention: 24 * time.Hour})
+	assert.NoError(t, err)
+	r.now = func() time.Time { return now }
+
+	// Samples with tips 1..10 and base fee 1, one every hour.
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, r.Sample(context.Background()))
+		now = now.Add(time.Hour)
+	}
+
+	t.Run("percentile", func(t *testing.T) {
+		p, err := r.Percentile(TierFast, 90, 24*time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(10), p)
+
+		p, err = r.Percentile(TierAverage, 50, 24*time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(6), p)
+
+		// The last 6 hours hold the samples 5 to 10, the oldest one is too old.
+		p, err = r.Percentile(TierFast, 0, 6*time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(6), p)
+
+		_, err = r.Percentile(TierFast, 101, time.Hour)
+		assert.Error(t, err)
+	})
+
+	t.Run("is cheap", func(t *testing.T) {
+		// The next sample has tip 11, above every recorded price.
+		cheap, err := r.IsCheap(context.Background(), TierFast, 50, 24*time.Hour)
+		assert.NoError(t, err)
+		assert.False(t, cheap)
+	})
+
+	t.Run("next off peak", func(t *testing.T) {
+		// Samples were taken from 12:00 to 21:00 with rising prices.
+		offPeak, err := r.NextOffPeak(TierFast, 24*time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC), offPeak)
+	})
+
+	t.Run("no samples", func(t *testing.T) {
+		r, err := NewRecorder(station, 1, store, RecorderConfig{Interval: time.Hour})
+		assert.NoError(t, err)
+		_, err = r.Percentile(TierFast, 50, time.Hour)
+		assert.ErrorIs(t, err, ErrNoSamples)
+	})
+
+	t.Run("rejects non positive interval", func(t *testing.T) {
+		_, err := NewRecorder(station, 1, store, RecorderConfig{})
+		assert.ErrorIs(t, err, ErrInvalidInterval)
+	})
+
+	t.Run("prunes expired samples", func(t *testing.T) {
+		now = now.Add(20 * time.Hour)
+		assert.NoError(t, r.Sample(context.Background()))
+		samples, err := store.Samples(137, time.Time{})
+		assert.NoError(t, err)
+		// Only the samples of the last 24 hours, 18:00 to 21:00 and the new one.
+		assert.Len(t, samples, 5)
+	})
+}
@@ -0,0 +1,56 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// errRetryable is wrapped by errors of requests which should be retried,
+// server errors and rate limit responses.
+var errRetryable = errors.New("retryable error")
+
+// Retry configures retrying of failed station requests with exponential backoff and jitter.
+type Retry struct {
+	// Attempts is the maximum number of attempts, values below 2 disable retrying.
+	Attempts int
+	// InitialBackoff is the wait before the second attempt, it doubles with every attempt.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between attempts, zero means no cap.
+	MaxBackoff time.Duration
+}
+
+// backoff returns the wait before the given attempt. The wait is taken randomly
+// from the upper half of the exponential backoff, so clients spread their retries.
+func (r Retry) backoff(attempt int) time.Duration {
+	d := r.InitialBackoff
+	for i := 1; i < attempt && (r.MaxBackoff == 0 || d < r.MaxBackoff); i++ {
+		d *= 2
+	}
+	if r.MaxBackoff > 0 && d > r.MaxBackoff {
+		d = r.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+// do calls fn until it succeeds, fails with a non retryable error or runs out of attempts.
+func (r Retry) do(ctx context.Context, fn func() error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn()
+		if err == nil || !errors.Is(err, errRetryable) || attempt >= r.Attempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(r.backoff(attempt)):
+		}
+	}
+}
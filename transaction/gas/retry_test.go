@@ -0,0 +1,35 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetry(t *testing.T) {
+	t.Run("backoff", func(t *testing.T) {
+		r := Retry{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+		for attempt, max := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 5: 300 * time.Millisecond} {
+			d := r.backoff(attempt)
+			assert.GreaterOrEqual(t, d, max/2)
+			assert.LessOrEqual(t, d, max)
+		}
+		assert.Zero(t, Retry{}.backoff(3))
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		calls := 0
+		err := Retry{Attempts: 3, InitialBackoff: time.Second}.do(ctx, func() error {
+			calls++
+			return fmt.Errorf("%w: 503", errRetryable)
+		})
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.Equal(t, 1, calls)
+	})
+}
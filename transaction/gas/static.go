@@ -1,6 +1,7 @@
 package gas
 
 import (
+	"context"
 	"math/big"
 )
 
@@ -18,6 +19,15 @@ func NewStaticStation(tip, base *big.Int) *StaticStation {
 }
 
 func (s *StaticStation) GetGasPrices() (*GasPrices, error) {
+	return s.GetGasPricesContext(context.Background())
+}
+
+// GetGasPricesContext returns the static gas prices unless the context is already done.
+func (s *StaticStation) GetGasPricesContext(ctx context.Context) (*GasPrices, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	prices := GasPrices{
 		SafeLow: new(big.Int).Set(s.tip),
 		Average: new(big.Int).Set(s.tip),
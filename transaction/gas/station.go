@@ -1,6 +1,9 @@
 package gas
 
-import "math/big"
+import (
+	"context"
+	"math/big"
+)
 
 // Station is a gas station inteface that provides methods
 // to get gas prices in a network.
@@ -9,6 +12,28 @@ type Station interface {
 	GetGasPrices() (*GasPrices, error)
 }
 
+// ContextStation is a gas station which accepts a context for its requests.
+type ContextStation interface {
+	Station
+	GetGasPricesContext(ctx context.Context) (*GasPrices, error)
+}
+
+// GetGasPricesContext returns gas prices from the given station
+// passing the context if the station supports it.
+func GetGasPricesContext(ctx context.Context, s Station) (*GasPrices, error) {
+	if cs, ok := s.(ContextStation); ok {
+		return cs.GetGasPricesContext(ctx)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.GetGasPrices()
+}
+
+// GasPrices holds the fees of a dynamic fee (EIP-1559) transaction.
+// The SafeLow, Average and Fast tiers are priority fee tips paid on top
+// of the base fee, the max fee per gas of a transaction is the base fee
+// with the tip added.
 type GasPrices struct {
 	SafeLow *big.Int
 	Average *big.Int
@@ -23,3 +48,10 @@ func priceMaxUpperBound(price *big.Int, bound *big.Int) *big.Int {
 	}
 	return price
 }
+
+func priceMinLowerBound(price *big.Int, bound *big.Int) *big.Int {
+	if price.Cmp(bound) < 0 {
+		return bound
+	}
+	return price
+}
@@ -0,0 +1,70 @@
+package gas
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/payments/logging"
+)
+
+// Subscribe polls the station every interval and sends the prices to the sink
+// whenever they differ from the previously sent ones. The first prices are sent
+// right away. A consumer that is too slow only misses the stale prices, it
+// always receives the latest ones. Call cancel to stop polling, the sink
+// is closed afterwards.
+func Subscribe(s Station, interval time.Duration) (sink chan *GasPrices, cancel func()) {
+	sink = make(chan *GasPrices, 1)
+	ctx, stop := context.WithCancel(context.Background())
+	var once sync.Once
+	cancel = func() { once.Do(stop) }
+
+	go func() {
+		defer close(sink)
+
+		var last *GasPrices
+		for {
+			prices, err := GetGasPricesContext(ctx, s)
+			switch {
+			case err != nil:
+				if ctx.Err() == nil {
+					logging.Default().Error("failed to poll gas prices", "error", err)
+				}
+			case last == nil || !equalPrices(last, prices):
+				last = copyPrices(prices)
+				select {
+				case <-sink:
+				default:
+				}
+				sink <- copyPrices(prices)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+			}
+		}
+	}()
+
+	return sink, cancel
+}
+
+// Subscribe sends the prices to the sink whenever they change, polling the cache every TTL.
+// See `Subscribe` for the details of the subscription.
+func (c *CachingStation) Subscribe() (sink chan *GasPrices, cancel func()) {
+	return Subscribe(c, c.ttl)
+}
+
+func equalPrices(a, b *GasPrices) bool {
+	return equalInt(a.SafeLow, b.SafeLow) && equalInt(a.Average, b.Average) &&
+		equalInt(a.Fast, b.Fast) && equalInt(a.BaseFee, b.BaseFee)
+}
+
+func equalInt(a, b *big.Int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Cmp(b) == 0
+}
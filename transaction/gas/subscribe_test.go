@@ -0,0 +1,66 @@
+package gas
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribe(t *testing.T) {
+	t.Run("sends changed prices", func(t *testing.T) {
+		mock := &countingStationMock{}
+		sink, cancel := Subscribe(mock, 5*time.Millisecond)
+
+		for i := int64(1); i <= 3; i++ {
+			select {
+			case prices := <-sink:
+				assert.True(t, prices.Average.Int64() >= i)
+			case <-time.After(time.Second):
+				t.Fatal("no prices received")
+			}
+		}
+
+		cancel()
+		cancel()
+		assert.Eventually(t, func() bool {
+			for {
+				select {
+				case _, ok := <-sink:
+					if !ok {
+						return true
+					}
+				default:
+					return false
+				}
+			}
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("skips unchanged prices and errors", func(t *testing.T) {
+		mock := &countingStationMock{}
+		mock.setErr(errors.New("error"))
+		cs, err := NewCachingStation(NewStaticStation(big.NewInt(10), big.NewInt(1)), 5*time.Millisecond)
+		assert.NoError(t, err)
+		sink, cancel := cs.Subscribe()
+		defer cancel()
+
+		prices := <-sink
+		assert.Equal(t, big.NewInt(10), prices.Average)
+		select {
+		case <-sink:
+			t.Fatal("unchanged prices sent")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		failing, cancelFailing := Subscribe(mock, 5*time.Millisecond)
+		defer cancelFailing()
+		select {
+		case <-failing:
+			t.Fatal("prices sent on error")
+		case <-time.After(20 * time.Millisecond):
+		}
+	})
+}
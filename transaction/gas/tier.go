@@ -0,0 +1,62 @@
+package gas
+
+import "math/big"
+
+// Tier is a speed tier of the gas prices. The values match the speeds of the transaction package.
+type Tier string
+
+// Speed tiers of the gas prices.
+const (
+	TierSafeLow Tier = "slow"
+	TierAverage Tier = "medium"
+	TierFast    Tier = "fast"
+)
+
+// Tip returns the tip of the given tier, nil for unknown tiers.
+func (p *GasPrices) Tip(tier Tier) *big.Int {
+	switch tier {
+	case TierSafeLow:
+		return p.SafeLow
+	case TierAverage:
+		return p.Average
+	case TierFast:
+		return p.Fast
+	default:
+		return nil
+	}
+}
+
+// TipWithMultiplier returns the tip of the given tier multiplied by the multiplier,
+// for example `TierFast` times 1.2, clamped to the upper bound unless it is nil.
+// Returns nil for unknown tiers.
+func (p *GasPrices) TipWithMultiplier(tier Tier, multiplier float64, upperBound *big.Int) *big.Int {
+	tip := p.Tip(tier)
+	if tip == nil {
+		return nil
+	}
+
+	// Round to the nearest wei, multipliers like 1.2 are not exact floats.
+	product := new(big.Float).Mul(new(big.Float).SetInt(tip), big.NewFloat(multiplier))
+	res, _ := product.Add(product, big.NewFloat(0.5)).Int(nil)
+	if res == nil || res.Sign() < 0 {
+		res = new(big.Int)
+	}
+	if upperBound != nil {
+		res = priceMaxUpperBound(res, upperBound)
+	}
+	return new(big.Int).Set(res)
+}
+
+// MaxFeePerGas returns the max fee per gas of a dynamic fee transaction paying
+// the tip of the given tier with the multiplier, see `TipWithMultiplier`.
+// The upper bound applies to the tip, returns nil for unknown tiers.
+func (p *GasPrices) MaxFeePerGas(tier Tier, multiplier float64, upperBound *big.Int) *big.Int {
+	tip := p.TipWithMultiplier(tier, multiplier, upperBound)
+	if tip == nil {
+		return nil
+	}
+	if p.BaseFee != nil {
+		tip.Add(tip, p.BaseFee)
+	}
+	return tip
+}
@@ -0,0 +1,25 @@
+package gas
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTier(t *testing.T) {
+	prices := &GasPrices{SafeLow: big.NewInt(10), Average: big.NewInt(20), Fast: big.NewInt(30), BaseFee: big.NewInt(100)}
+
+	assert.Equal(t, big.NewInt(10), prices.Tip(TierSafeLow))
+	assert.Equal(t, big.NewInt(20), prices.Tip(TierAverage))
+	assert.Equal(t, big.NewInt(30), prices.Tip(TierFast))
+	assert.Nil(t, prices.Tip("urgent"))
+
+	assert.Equal(t, big.NewInt(36), prices.TipWithMultiplier(TierFast, 1.2, nil))
+	assert.Equal(t, big.NewInt(35), prices.TipWithMultiplier(TierFast, 1.2, big.NewInt(35)))
+	assert.Equal(t, big.NewInt(10), prices.TipWithMultiplier(TierAverage, 0.5, nil))
+	assert.Nil(t, prices.TipWithMultiplier("urgent", 2, nil))
+
+	assert.Equal(t, big.NewInt(135), prices.MaxFeePerGas(TierFast, 1.2, big.NewInt(35)))
+	assert.Equal(t, big.NewInt(30), prices.Fast, "prices are not modified")
+}
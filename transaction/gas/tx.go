@@ -0,0 +1,75 @@
+package gas
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DefaultBaseFeeMultiplier keeps dynamic fee transactions valid while the base fee doubles.
+const DefaultBaseFeeMultiplier = 2
+
+// ErrUnknownTier is returned when building a transaction for an unknown tier.
+var ErrUnknownTier = errors.New("unknown gas price tier")
+
+// TxParams are the fields of a transaction other than its fees.
+type TxParams struct {
+	ChainID int64
+	Nonce   uint64
+	To      *common.Address
+	Value   *big.Int
+	Gas     uint64
+	Data    []byte
+}
+
+// SupportsDynamicFees returns true if the prices have a base fee. Stations of chains
+// which do not support EIP-1559 return no base fee, their tiers are plain gas prices.
+func (p *GasPrices) SupportsDynamicFees() bool {
+	return p.BaseFee != nil && p.BaseFee.Sign() > 0
+}
+
+// NewTransaction builds an unsigned EIP-1559 dynamic fee transaction paying the tip of the
+// tier, with a max fee of the tip plus the base fee times the multiplier, so the transaction
+// stays valid while the base fee rises. Multipliers below 1 are raised to 1.
+//
+// If the prices have no base fee, a legacy transaction paying the tier as gas price is built.
+func (p *GasPrices) NewTransaction(params TxParams, tier Tier, baseFeeMultiplier float64) (*types.Transaction, error) {
+	tip := p.Tip(tier)
+	if tip == nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTier, tier)
+	}
+	value := params.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	if !p.SupportsDynamicFees() {
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    params.Nonce,
+			GasPrice: new(big.Int).Set(tip),
+			Gas:      params.Gas,
+			To:       params.To,
+			Value:    value,
+			Data:     params.Data,
+		}), nil
+	}
+
+	if baseFeeMultiplier < 1 {
+		baseFeeMultiplier = 1
+	}
+	base, _ := new(big.Float).Mul(new(big.Float).SetInt(p.BaseFee), big.NewFloat(baseFeeMultiplier)).Int(nil)
+
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(params.ChainID),
+		Nonce:     params.Nonce,
+		GasTipCap: new(big.Int).Set(tip),
+		GasFeeCap: base.Add(base, tip),
+		Gas:       params.Gas,
+		To:        params.To,
+		Value:     value,
+		Data:      params.Data,
+	}), nil
+}
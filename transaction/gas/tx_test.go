@@ -0,0 +1,48 @@
+package gas
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGasPricesNewTransaction(t *testing.T) {
+	to := common.HexToAddress("0x1")
+	params := TxParams{ChainID: 137, Nonce: 3, To: &to, Gas: 21000}
+
+	t.Run("dynamic fee transaction", func(t *testing.T) {
+		prices := GasPrices{SafeLow: big.NewInt(1), Average: big.NewInt(2), Fast: big.NewInt(3), BaseFee: big.NewInt(100)}
+		tx, err := prices.NewTransaction(params, TierFast, DefaultBaseFeeMultiplier)
+		assert.NoError(t, err)
+		assert.Equal(t, uint8(types.DynamicFeeTxType), tx.Type())
+		assert.Equal(t, big.NewInt(137), tx.ChainId())
+		assert.Equal(t, uint64(3), tx.Nonce())
+		assert.Equal(t, big.NewInt(3), tx.GasTipCap())
+		assert.Equal(t, big.NewInt(203), tx.GasFeeCap())
+		assert.Equal(t, big.NewInt(0), tx.Value())
+
+		tx, err = prices.NewTransaction(params, TierSafeLow, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(101), tx.GasFeeCap())
+	})
+
+	t.Run("falls back to legacy without a base fee", func(t *testing.T) {
+		prices := GasPrices{SafeLow: big.NewInt(10), Average: big.NewInt(20), Fast: big.NewInt(30), BaseFee: big.NewInt(0)}
+		assert.False(t, prices.SupportsDynamicFees())
+
+		tx, err := prices.NewTransaction(params, TierAverage, DefaultBaseFeeMultiplier)
+		assert.NoError(t, err)
+		assert.Equal(t, uint8(types.LegacyTxType), tx.Type())
+		assert.Equal(t, big.NewInt(20), tx.GasPrice())
+		assert.Equal(t, to, *tx.To())
+	})
+
+	t.Run("unknown tier", func(t *testing.T) {
+		prices := GasPrices{}
+		_, err := prices.NewTransaction(params, "instant", DefaultBaseFeeMultiplier)
+		assert.ErrorIs(t, err, ErrUnknownTier)
+	})
+}
@@ -11,8 +11,9 @@ import (
 )
 
 type GasTracker struct {
-	gs   GasStation
-	opts map[int64]GasIncreaseOpts
+	gs     GasStation
+	opts   map[int64]GasIncreaseOpts
+	policy gas.ChainFeePolicy
 
 	speed GasTrackerSpeed
 }
@@ -56,6 +57,13 @@ func NewGasTracker(gs GasStation, opts map[int64]GasIncreaseOpts, speed GasTrack
 	}
 }
 
+// SetFeePolicy sets the fee limits per chain which cap the tips before a delivery is signed.
+//
+// This method is not thread safe and should be called before the depot is started.
+func (g *GasTracker) SetFeePolicy(p gas.ChainFeePolicy) {
+	g.policy = p
+}
+
 func (g *GasTracker) CanReceiveMoreGas(chainID int64, lastFillUpUTC time.Time) (bool, error) {
 	opts, ok := g.opts[chainID]
 	if !ok {
@@ -67,6 +75,13 @@ func (g *GasTracker) CanReceiveMoreGas(chainID int64, lastFillUpUTC time.Time) (
 }
 
 func (g *GasTracker) ReceiveInitialGas(chainID int64, txType DeliverableType) (*fees, error) {
+	return g.ReceiveInitialGasWithPriority(chainID, txType, DeliveryPriorityNormal)
+}
+
+// ReceiveInitialGasWithPriority returns the initial gas for a delivery of the given priority.
+// High priority deliveries use fast and low priority deliveries use slow gas prices,
+// normal ones use the speed of the tracker.
+func (g *GasTracker) ReceiveInitialGasWithPriority(chainID int64, txType DeliverableType, priority DeliveryPriority) (*fees, error) {
 	prices, err := g.gs.GetGasPrices(chainID)
 	if err != nil {
 		return nil, err
@@ -76,7 +91,7 @@ func (g *GasTracker) ReceiveInitialGas(chainID int64, txType DeliverableType) (*
 		Base: prices.BaseFee,
 	}
 
-	switch g.speed {
+	switch g.speedFor(priority) {
 	case GasTrackerSpeedSlow:
 		fees.Tip = prices.SafeLow
 	case GasTrackerSpeedMedium:
@@ -88,12 +103,17 @@ func (g *GasTracker) ReceiveInitialGas(chainID int64, txType DeliverableType) (*
 	}
 
 	fees.Tip = g.calculateOverpay(chainID, txType, fees.Tip)
-	return fees, nil
+	return g.applyFeePolicy(chainID, fees)
 }
 
 func (g *GasTracker) RecalculateDeliveryGas(chainID int64, lastKnownTip *big.Int, txType DeliverableType) (*fees, error) {
+	return g.RecalculateDeliveryGasWithPriority(chainID, lastKnownTip, txType, DeliveryPriorityNormal)
+}
+
+// RecalculateDeliveryGasWithPriority returns the increased gas for a delivery of the given priority.
+func (g *GasTracker) RecalculateDeliveryGasWithPriority(chainID int64, lastKnownTip *big.Int, txType DeliverableType, priority DeliveryPriority) (*fees, error) {
 	if lastKnownTip == nil || lastKnownTip.Cmp(big.NewInt(0)) <= 0 {
-		return g.ReceiveInitialGas(chainID, txType)
+		return g.ReceiveInitialGasWithPriority(chainID, txType, priority)
 	}
 
 	opts, ok := g.opts[chainID]
@@ -103,7 +123,7 @@ func (g *GasTracker) RecalculateDeliveryGas(chainID int64, lastKnownTip *big.Int
 
 	newTip := g.calculateNewPrice(chainID, lastKnownTip, opts.Multiplier)
 
-	newFees, err := g.ReceiveInitialGas(chainID, txType)
+	newFees, err := g.ReceiveInitialGasWithPriority(chainID, txType, priority)
 	if err != nil {
 		return nil, fmt.Errorf("could not recalculate gas price: %w", err)
 	}
@@ -115,19 +135,39 @@ func (g *GasTracker) RecalculateDeliveryGas(chainID int64, lastKnownTip *big.Int
 	// Check that new tip is not exceeding our limits.
 	if newTip.Cmp(opts.PriceLimit) > 0 {
 		if lastKnownTip.Cmp(opts.PriceLimit) < 0 {
-			return &fees{
+			return g.applyFeePolicy(chainID, &fees{
 				Base: newFees.Base,
 				Tip:  g.calculateOverpay(chainID, txType, opts.PriceLimit),
-			}, nil
+			})
 		}
 
 		return nil, errMaxPriceReached
 	}
 
-	return &fees{
+	return g.applyFeePolicy(chainID, &fees{
 		Base: newFees.Base,
 		Tip:  g.calculateOverpay(chainID, txType, newTip),
-	}, nil
+	})
+}
+
+func (g *GasTracker) speedFor(priority DeliveryPriority) GasTrackerSpeed {
+	switch {
+	case priority >= DeliveryPriorityHigh:
+		return GasTrackerSpeedFast
+	case priority <= DeliveryPriorityLow:
+		return GasTrackerSpeedSlow
+	default:
+		return g.speed
+	}
+}
+
+func (g *GasTracker) applyFeePolicy(chainID int64, f *fees) (*fees, error) {
+	tip, err := g.policy.Tip(chainID, f.Tip, f.Base)
+	if err != nil {
+		return nil, err
+	}
+	f.Tip = tip
+	return f, nil
 }
 
 func (g *GasTracker) calculateOverpay(chainID int64, txType DeliverableType, price *big.Int) *big.Int {
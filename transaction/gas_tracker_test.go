@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/transaction/gas"
 )
 
 func Test_calculateOverpay(t *testing.T) {
@@ -117,3 +119,47 @@ func Test_calculateOverpay(t *testing.T) {
 		})
 	}
 }
+
+func TestGasTrackerFeePolicy(t *testing.T) {
+	gt := NewGasTracker(gas.MultichainStation{
+		137: {gas.NewStaticStation(big.NewInt(500), big.NewInt(100))},
+	}, map[int64]GasIncreaseOpts{
+		137: {Multiplier: 2, PriceLimit: big.NewInt(1000)},
+	}, GasTrackerSpeedFast)
+	gt.SetFeePolicy(gas.ChainFeePolicy{137: {MaxPriorityFee: big.NewInt(50), MaxFeePerGas: big.NewInt(120)}})
+
+	f, err := gt.ReceiveInitialGas(137, "")
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(20), f.Tip)
+	assert.Equal(t, big.NewInt(100), f.Base)
+
+	f, err = gt.RecalculateDeliveryGas(137, big.NewInt(40), "")
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(20), f.Tip)
+
+	gt.SetFeePolicy(gas.ChainFeePolicy{137: {MaxFeePerGas: big.NewInt(50)}})
+	_, err = gt.ReceiveInitialGas(137, "")
+	assert.ErrorIs(t, err, gas.ErrMaxFeeExceeded)
+}
+
+func TestGasTrackerPriority(t *testing.T) {
+	gt := NewGasTracker(gasStationFunc(func(int64) (*gas.GasPrices, error) {
+		return &gas.GasPrices{SafeLow: big.NewInt(1), Average: big.NewInt(2), Fast: big.NewInt(3), BaseFee: big.NewInt(10)}, nil
+	}), nil, GasTrackerSpeedMedium)
+
+	for priority, tip := range map[DeliveryPriority]int64{
+		DeliveryPriorityLow:    1,
+		DeliveryPriorityNormal: 2,
+		DeliveryPriorityHigh:   3,
+	} {
+		f, err := gt.ReceiveInitialGasWithPriority(1, "", priority)
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(tip), f.Tip)
+	}
+}
+
+type gasStationFunc func(chainID int64) (*gas.GasPrices, error)
+
+func (f gasStationFunc) GetGasPrices(chainID int64) (*gas.GasPrices, error) {
+	return f(chainID)
+}
@@ -0,0 +1,280 @@
+package transaction
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type journalOp string
+
+const (
+	journalOpUpsert journalOp = "upsert"
+	journalOpDelete journalOp = "delete"
+)
+
+type journalRecord struct {
+	Op       journalOp `json:"op"`
+	Delivery Delivery  `json:"delivery"`
+}
+
+// JournalStorage is a file backed `DepotStorage` and `DepotStorageCleaner`.
+// Every change is appended to an on-disk journal which is replayed on open,
+// so deliveries which were queued but not sent, or sent but not yet confirmed,
+// survive a restart and are picked up again by the depot workers.
+type JournalStorage struct {
+	path       string
+	file       *os.File
+	deliveries map[string]Delivery
+	mu         sync.Mutex
+}
+
+// NewJournalStorage opens the journal at the given path, creating it if it does not exist,
+// and replays it. A partially written last record, left by a crash, is truncated.
+func NewJournalStorage(path string) (*JournalStorage, error) {
+	deliveries, size, err := replayJournal(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	if err := f.Truncate(size); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to truncate journal: %w", err)
+	}
+
+	return &JournalStorage{
+		path:       path,
+		file:       f,
+		deliveries: deliveries,
+	}, nil
+}
+
+// replayJournal returns the deliveries in the journal and the size of its valid part.
+func replayJournal(path string) (map[string]Delivery, int64, error) {
+	deliveries := make(map[string]Delivery)
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return deliveries, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var decodeErr error
+	var size int64
+	line := 0
+	for scanner.Scan() {
+		line++
+		if decodeErr != nil {
+			return nil, 0, decodeErr
+		}
+
+		var rec journalRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			decodeErr = fmt.Errorf("corrupted journal record on line %d: %w", line, err)
+			continue
+		}
+		size += int64(len(scanner.Bytes())) + 1
+
+		switch rec.Op {
+		case journalOpUpsert:
+			deliveries[rec.Delivery.UniqueID] = rec.Delivery
+		case journalOpDelete:
+			delete(deliveries, rec.Delivery.UniqueID)
+		default:
+			return nil, 0, fmt.Errorf("unknown journal operation %q on line %d", rec.Op, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	return deliveries, size, nil
+}
+
+// Close closes the journal file.
+func (j *JournalStorage) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
+
+// Compact rewrites the journal so that it only holds the current state of every delivery.
+func (j *JournalStorage) Compact() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	tmp, err := os.CreateTemp(filepath.Dir(j.path), filepath.Base(j.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create compacted journal: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	for _, d := range j.deliveries {
+		if err := writeJournalRecord(w, journalRecord{Op: journalOpUpsert, Delivery: d}); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write compacted journal: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync compacted journal: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close compacted journal: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), j.path); err != nil {
+		return fmt.Errorf("failed to replace journal: %w", err)
+	}
+
+	f, err := os.OpenFile(j.path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to reopen journal: %w", err)
+	}
+	j.file.Close()
+	j.file = f
+	return nil
+}
+
+// UpsertDeliveryRequest journals the delivery and updates it, creating it if it does not exist.
+func (j *JournalStorage) UpsertDeliveryRequest(td Delivery) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.append(journalRecord{Op: journalOpUpsert, Delivery: td}); err != nil {
+		return err
+	}
+	j.deliveries[td.UniqueID] = td
+	return nil
+}
+
+// DeleteDelivery journals and deletes the given deliveries.
+func (j *JournalStorage) DeleteDelivery(deliveries ...Delivery) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, d := range deliveries {
+		if _, ok := j.deliveries[d.UniqueID]; !ok {
+			continue
+		}
+		if err := j.append(journalRecord{Op: journalOpDelete, Delivery: Delivery{UniqueID: d.UniqueID}}); err != nil {
+			return err
+		}
+		delete(j.deliveries, d.UniqueID)
+	}
+	return nil
+}
+
+// GetOrderedDeliveryRequests returns non delivered deliveries ordered by nonce, from lowest to highest.
+func (j *JournalStorage) GetOrderedDeliveryRequests(count uint, chainID int64, sender common.Address) ([]Delivery, error) {
+	res := j.filter(chainID, sender, func(d Delivery) bool {
+		return d.State != DeliveryStateDelivered
+	})
+	if uint(len(res)) > count {
+		res = res[:count]
+	}
+	return res, nil
+}
+
+// GetLastQueuedDelivery returns the delivery with the highest nonce or nil if there are none.
+func (j *JournalStorage) GetLastQueuedDelivery(chainID int64, sender common.Address) (*Delivery, error) {
+	res := j.filter(chainID, sender, func(Delivery) bool { return true })
+	if len(res) == 0 {
+		return nil, nil
+	}
+	return &res[len(res)-1], nil
+}
+
+// GetLastDelivered returns the most recently delivered delivery or nil if there are none.
+func (j *JournalStorage) GetLastDelivered(chainID int64, sender common.Address) (*Delivery, error) {
+	var last *Delivery
+	for _, d := range j.filter(chainID, sender, func(d Delivery) bool {
+		return d.State == DeliveryStateDelivered
+	}) {
+		if last == nil || d.UpdateUTC.After(last.UpdateUTC) {
+			d := d
+			last = &d
+		}
+	}
+	return last, nil
+}
+
+// GetNonDeliveredCount returns the number of non delivered deliveries.
+func (j *JournalStorage) GetNonDeliveredCount(chainID int64, sender common.Address) (uint, error) {
+	res := j.filter(chainID, sender, func(d Delivery) bool {
+		return d.State != DeliveryStateDelivered
+	})
+	return uint(len(res)), nil
+}
+
+// GetDeliveredDeliveryRequests returns up to limit deliveries delivered before the given time.
+func (j *JournalStorage) GetDeliveredDeliveryRequests(chainID int64, sender common.Address, olderThan time.Time, limit uint64) ([]Delivery, error) {
+	res := j.filter(chainID, sender, func(d Delivery) bool {
+		return d.State == DeliveryStateDelivered && d.UpdateUTC.Before(olderThan)
+	})
+	if uint64(len(res)) > limit {
+		res = res[:limit]
+	}
+	return res, nil
+}
+
+func (j *JournalStorage) filter(chainID int64, sender common.Address, keep func(Delivery) bool) []Delivery {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	res := make([]Delivery, 0)
+	for _, d := range j.deliveries {
+		if d.ChainID == chainID && d.Sender == sender && keep(d) {
+			res = append(res, d)
+		}
+	}
+	sort.Slice(res, func(a, b int) bool {
+		return res[a].Nonce < res[b].Nonce
+	})
+	return res
+}
+
+func (j *JournalStorage) append(rec journalRecord) error {
+	if err := writeJournalRecord(j.file, rec); err != nil {
+		return err
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync journal: %w", err)
+	}
+	return nil
+}
+
+func writeJournalRecord(w io.Writer, rec journalRecord) error {
+	blob, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal record: %w", err)
+	}
+	if _, err := w.Write(append(blob, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal record: %w", err)
+	}
+	return nil
+}
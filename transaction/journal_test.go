@@ -0,0 +1,118 @@
+package transaction
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournalStorage(t *testing.T) {
+	sender := common.HexToAddress("0x1")
+	path := filepath.Join(t.TempDir(), "depot.journal")
+
+	newDelivery := func(nonce uint64, state DeliveryState) Delivery {
+		req := DeliveryRequest{ChainID: chainId, Sender: sender, Type: "test", Data: mockData{"tx"}}
+		td, err := req.toDelivery(nonce)
+		assert.NoError(t, err)
+		td.State = state
+		return td
+	}
+
+	js, err := NewJournalStorage(path)
+	assert.NoError(t, err)
+
+	delivered := newDelivery(0, DeliveryStateDelivered)
+	delivered.UpdateUTC = time.Now().UTC().Add(-time.Hour)
+	assert.NoError(t, js.UpsertDeliveryRequest(delivered))
+	assert.NoError(t, js.UpsertDeliveryRequest(newDelivery(2, DeliveryStateWaiting)))
+	sent := newDelivery(1, DeliveryStateSent)
+	sent.GasTip = big.NewInt(5)
+	assert.NoError(t, js.UpsertDeliveryRequest(sent))
+	assert.NoError(t, js.Close())
+
+	t.Run("recovers deliveries after a restart", func(t *testing.T) {
+		js, err := NewJournalStorage(path)
+		assert.NoError(t, err)
+		defer js.Close()
+
+		tds, err := js.GetOrderedDeliveryRequests(10, chainId, sender)
+		assert.NoError(t, err)
+		assert.Len(t, tds, 2)
+		assert.Equal(t, uint64(1), tds[0].Nonce)
+		assert.Equal(t, DeliveryState(DeliveryStateSent), tds[0].State)
+		assert.Equal(t, big.NewInt(5), tds[0].GasTip)
+		assert.Equal(t, uint64(2), tds[1].Nonce)
+
+		count, err := js.GetNonDeliveredCount(chainId, sender)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(2), count)
+
+		last, err := js.GetLastQueuedDelivery(chainId, sender)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(2), last.Nonce)
+
+		ld, err := js.GetLastDelivered(chainId, sender)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(0), ld.Nonce)
+
+		none, err := js.GetLastQueuedDelivery(chainId, common.HexToAddress("0x2"))
+		assert.NoError(t, err)
+		assert.Nil(t, none)
+	})
+
+	t.Run("journals deletes and compacts", func(t *testing.T) {
+		js, err := NewJournalStorage(path)
+		assert.NoError(t, err)
+
+		old, err := js.GetDeliveredDeliveryRequests(chainId, sender, time.Now().UTC(), 10)
+		assert.NoError(t, err)
+		assert.Len(t, old, 1)
+		assert.NoError(t, js.DeleteDelivery(old...))
+
+		before, err := os.Stat(path)
+		assert.NoError(t, err)
+		assert.NoError(t, js.Compact())
+		after, err := os.Stat(path)
+		assert.NoError(t, err)
+		assert.Less(t, after.Size(), before.Size())
+
+		assert.NoError(t, js.UpsertDeliveryRequest(newDelivery(3, DeliveryStateWaiting)))
+		assert.NoError(t, js.Close())
+
+		js, err = NewJournalStorage(path)
+		assert.NoError(t, err)
+		defer js.Close()
+
+		ld, err := js.GetLastDelivered(chainId, sender)
+		assert.NoError(t, err)
+		assert.Nil(t, ld)
+		count, err := js.GetNonDeliveredCount(chainId, sender)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(3), count)
+	})
+
+	t.Run("truncates a partially written record", func(t *testing.T) {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+		assert.NoError(t, err)
+		_, err = f.WriteString(`{"op":"upsert","deliv`)
+		assert.NoError(t, err)
+		assert.NoError(t, f.Close())
+
+		js, err := NewJournalStorage(path)
+		assert.NoError(t, err)
+		assert.NoError(t, js.UpsertDeliveryRequest(newDelivery(4, DeliveryStateWaiting)))
+		assert.NoError(t, js.Close())
+
+		js, err = NewJournalStorage(path)
+		assert.NoError(t, err)
+		defer js.Close()
+		count, err := js.GetNonDeliveredCount(chainId, sender)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(4), count)
+	})
+}
@@ -6,6 +6,13 @@ type DepotMetricsExporter interface {
 	DeliverySent(td Delivery)
 }
 
+// DepotFailureExporter is an optional extension of the `DepotMetricsExporter`
+// which is notified about errors that happen while handling a delivery.
+// The depot keeps retrying failed deliveries.
+type DepotFailureExporter interface {
+	DeliveryFailed(td Delivery, err error)
+}
+
 type depotMetricsExporterNoop struct{}
 
 func (d *depotMetricsExporterNoop) DeliveryReceived(_ Delivery) {}
@@ -19,6 +19,7 @@ type NonceTracker struct {
 type nonceTrackerBC interface {
 	PendingNonceAt(chainID int64, account common.Address) (uint64, error)
 	NonceAt(chainID int64, account common.Address, blockNum *big.Int) (uint64, error)
+	BlockNumber(chainID int64) (uint64, error)
 }
 
 // NewNonceTracker returns a new nonce tracker.
@@ -86,6 +87,50 @@ func (nt *NonceTracker) GetConfirmedNonce(chainID int64, account common.Address)
 	return bcNonce, nil
 }
 
+// GetConfirmedNonceAtDepth returns the nonce confirmed the given number of blocks below
+// the latest block, together with the number of that block.
+func (nt *NonceTracker) GetConfirmedNonceAtDepth(chainID int64, account common.Address, depth uint64) (uint64, uint64, error) {
+	head, err := nt.nonceTrackerBC.BlockNumber(chainID)
+	if err != nil {
+		return 0, 0, err
+	}
+	if head < depth {
+		return 0, 0, nil
+	}
+
+	block := head - depth
+	nonce, err := nt.nonceTrackerBC.NonceAt(chainID, account, new(big.Int).SetUint64(block))
+	if err != nil {
+		return 0, 0, err
+	}
+	return nonce, block, nil
+}
+
+// Nonces returns the last issued nonce of every known sender.
+func (nt *NonceTracker) Nonces() map[Sender]uint64 {
+	nt.nonceLock.Lock()
+	defer nt.nonceLock.Unlock()
+
+	res := make(map[Sender]uint64, len(nt.nonces))
+	for k, v := range nt.nonces {
+		res[k] = v
+	}
+	return res
+}
+
+// RestoreNonces sets the last issued nonces, keeping known nonces which are higher.
+func (nt *NonceTracker) RestoreNonces(nonces map[Sender]uint64) {
+	nt.nonceLock.Lock()
+	defer nt.nonceLock.Unlock()
+
+	for k, v := range nonces {
+		if current, ok := nt.nonces[k]; ok && current >= v {
+			continue
+		}
+		nt.nonces[k] = v
+	}
+}
+
 // ForceReloadNonce clears the nonce cache. This will force loading from BC next time.
 func (nt *NonceTracker) ForceReloadNonce(chainID int64, account common.Address) {
 	nt.nonceLock.Lock()
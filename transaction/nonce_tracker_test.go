@@ -101,4 +101,23 @@ func TestNonceTracker(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, 23, int(nonce))
 	})
+
+	t.Run("confirmed at depth", func(t *testing.T) {
+		cl.BlockNumberFunc = func(ctx context.Context) (uint64, error) {
+			return 100, nil
+		}
+		cl.NonceAtFunc = func(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+			return blockNumber.Uint64(), nil
+		}
+
+		nonce, block, err := nt.GetConfirmedNonceAtDepth(1, common.HexToAddress("0x5"), 12)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(88), block)
+		assert.Equal(t, uint64(88), nonce)
+
+		nonce, block, err = nt.GetConfirmedNonceAtDepth(1, common.HexToAddress("0x5"), 101)
+		assert.NoError(t, err)
+		assert.Zero(t, block)
+		assert.Zero(t, nonce)
+	})
 }
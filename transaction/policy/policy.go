@@ -0,0 +1,327 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/mysteriumnetwork/payments/transaction"
+)
+
+// Rule is a policy rule a broadcast can be rejected by.
+type Rule string
+
+const (
+	RuleMaxGasPrice Rule = "max_gas_price"
+	RuleMaxFee      Rule = "max_fee"
+	RuleQuietHours  Rule = "quiet_hours"
+	RuleDailyBudget Rule = "daily_budget"
+)
+
+// ErrRejected is wrapped by every `Rejection`.
+var ErrRejected = errors.New("broadcast rejected by policy")
+
+// Rejection is returned when a broadcast violates the policy.
+type Rejection struct {
+	Rule       Rule
+	ChainID    int64
+	Type       transaction.DeliverableType
+	DeliveryID string
+	Reason     string
+}
+
+// Error returns the rejection reason.
+func (r *Rejection) Error() string {
+	return fmt.Sprintf("%s: %s: %s", ErrRejected, r.Rule, r.Reason)
+}
+
+// Unwrap returns `ErrRejected`.
+func (r *Rejection) Unwrap() error {
+	return ErrRejected
+}
+
+// QuietHours is a daily window during which broadcasts are not allowed.
+// The window may wrap around midnight, for example from 22:00 to 06:00.
+type QuietHours struct {
+	// Start and End are offsets from midnight.
+	Start time.Duration
+	End   time.Duration
+	// Location is the time zone of the window, UTC if nil.
+	Location *time.Location
+	// Exempt types are broadcast during quiet hours anyway.
+	Exempt []transaction.DeliverableType
+}
+
+func (q *QuietHours) contains(t time.Time) bool {
+	loc := q.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if q.Start <= q.End {
+		return offset >= q.Start && offset < q.End
+	}
+	return offset >= q.Start || offset < q.End
+}
+
+func (q *QuietHours) exempt(typ transaction.DeliverableType) bool {
+	for _, t := range q.Exempt {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// Policy holds the rules evaluated before every broadcast. Rules which are not set are not enforced.
+type Policy struct {
+	// MaxGasPrice is the highest price per gas (base fee and tip, or gas price for legacy transactions) per chain.
+	MaxGasPrice map[int64]*big.Int
+	// MaxFee is the highest total fee per operation type, computed as the price per gas times the gas limit.
+	MaxFee map[transaction.DeliverableType]*big.Int
+	// GasLimits is the gas limit per operation type used to compute fees
+	// when the courier can not estimate them.
+	GasLimits map[transaction.DeliverableType]uint64
+	// DailyBudget is the highest total fee spent per chain per UTC day.
+	DailyBudget map[int64]*big.Int
+	// QuietHours is the daily window during which nothing is broadcast.
+	QuietHours *QuietHours
+}
+
+// GasEstimator is optionally implemented by couriers which can estimate the gas limit of a delivery.
+type GasEstimator interface {
+	EstimateGas(td transaction.Delivery) (uint64, error)
+}
+
+type spend struct {
+	day   string
+	total *big.Int
+	// charged holds the highest fee charged for every delivery, resends only charge the difference.
+	charged map[string]*big.Int
+	// reserved holds the last reservation of every delivery until it is sent or released.
+	reserved map[string]reservation
+}
+
+type reservation struct {
+	amount *big.Int
+	// prev is the fee charged for the delivery before the reservation, nil if none.
+	prev *big.Int
+}
+
+// Engine evaluates the policy before every broadcast. It wraps the
+// `transaction.DeliveryCourier` the depot broadcasts through, rejected
+// deliveries stay in the depot and are retried on the next processing.
+type Engine struct {
+	next   transaction.DeliveryCourier
+	policy Policy
+
+	spent map[int64]*spend
+	mu    sync.Mutex
+
+	listeners []func(*Rejection)
+	now       func() time.Time
+}
+
+// NewEngine returns a new policy engine wrapping the courier.
+func NewEngine(next transaction.DeliveryCourier, p Policy) *Engine {
+	return &Engine{
+		next:   next,
+		policy: p,
+		spent:  make(map[int64]*spend),
+		now:    time.Now,
+	}
+}
+
+// OnReject registers a listener which is called for every rejected broadcast.
+//
+// This method is not thread safe and should be called before the depot is started.
+func (e *Engine) OnReject(fn func(*Rejection)) {
+	e.listeners = append(e.listeners, fn)
+}
+
+// SetPolicy replaces the policy, it is applied starting with the next broadcast.
+func (e *Engine) SetPolicy(p Policy) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.policy = p
+}
+
+// Policy returns the current policy.
+func (e *Engine) Policy() Policy {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.policy
+}
+
+// Spent returns the fees charged against the daily budget of the chain today.
+func (e *Engine) Spent(chainID int64) *big.Int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	s := e.spendFor(chainID)
+	return new(big.Int).Set(s.total)
+}
+
+// CanDeliver calls the wrapped courier.
+func (e *Engine) CanDeliver(typ transaction.DeliverableType) bool {
+	return e.next.CanDeliver(typ)
+}
+
+// DeliverTransaction evaluates the policy and, if it passes, delivers the transaction using the wrapped courier.
+func (e *Engine) DeliverTransaction(td transaction.Delivery) (*types.Transaction, error) {
+	_, err := e.Evaluate(td)
+	if err != nil {
+		var rej *Rejection
+		if errors.As(err, &rej) {
+			for _, fn := range e.listeners {
+				fn(rej)
+			}
+		}
+		return nil, err
+	}
+
+	tx, err := e.next.DeliverTransaction(td)
+	// The transaction is passed on if the node already knows it, it was sent before.
+	if err != nil && tx == nil {
+		e.Release(td)
+		return nil, err
+	}
+
+	e.commit(td)
+	return tx, err
+}
+
+// Evaluate checks the delivery against the policy and returns its estimated total fee.
+// A `*Rejection` is returned if the delivery violates the policy, or if its gas limit
+// is unknown while a fee or budget limit applies to it.
+// The fee is reserved against the daily budget right away, so concurrent deliveries can not
+// overspend it. Use `Release` to give it back if the delivery is not sent.
+func (e *Engine) Evaluate(td transaction.Delivery) (*big.Int, error) {
+	p := e.Policy()
+	reject := func(rule Rule, format string, args ...interface{}) error {
+		return &Rejection{Rule: rule, ChainID: td.ChainID, Type: td.Type, DeliveryID: td.UniqueID, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	if q := p.QuietHours; q != nil && q.contains(e.now()) && !q.exempt(td.Type) {
+		return nil, reject(RuleQuietHours, "broadcasts are paused during quiet hours")
+	}
+
+	price := gasPrice(td)
+	if max, ok := p.MaxGasPrice[td.ChainID]; ok && max != nil && price.Cmp(max) > 0 {
+		return nil, reject(RuleMaxGasPrice, "gas price %s is above %s", price, max)
+	}
+
+	limit, err := e.gasLimit(p, td)
+	if err != nil {
+		return nil, err
+	}
+	if limit == 0 {
+		// Without a gas limit the fee is unknown, it can not be checked against the fee rules.
+		if max, ok := p.MaxFee[td.Type]; ok && max != nil {
+			return nil, reject(RuleMaxFee, "the gas limit of %q is unknown", td.Type)
+		}
+		if budget, ok := p.DailyBudget[td.ChainID]; ok && budget != nil {
+			return nil, reject(RuleDailyBudget, "the gas limit of %q is unknown", td.Type)
+		}
+	}
+	fee := new(big.Int).Mul(price, new(big.Int).SetUint64(limit))
+
+	if max, ok := p.MaxFee[td.Type]; ok && max != nil && fee.Cmp(max) > 0 {
+		return nil, reject(RuleMaxFee, "fee %s is above %s", fee, max)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	s := e.spendFor(td.ChainID)
+	charge := chargeFor(s, td.UniqueID, fee)
+	total := new(big.Int).Add(s.total, charge)
+	if budget, ok := p.DailyBudget[td.ChainID]; ok && budget != nil && total.Cmp(budget) > 0 {
+		return nil, reject(RuleDailyBudget, "spending %s today is above the budget of %s", total, budget)
+	}
+
+	s.reserved[td.UniqueID] = reservation{amount: charge, prev: s.charged[td.UniqueID]}
+	s.total = total
+	if prev, ok := s.charged[td.UniqueID]; !ok || fee.Cmp(prev) > 0 {
+		s.charged[td.UniqueID] = fee
+	}
+	return fee, nil
+}
+
+// Release gives back the fee reserved by the last `Evaluate` of the delivery, for deliveries which were not sent.
+func (e *Engine) Release(td transaction.Delivery) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	s := e.spendFor(td.ChainID)
+	r, ok := s.reserved[td.UniqueID]
+	if !ok {
+		return
+	}
+	delete(s.reserved, td.UniqueID)
+	s.total.Sub(s.total, r.amount)
+	if r.prev == nil {
+		delete(s.charged, td.UniqueID)
+	} else {
+		s.charged[td.UniqueID] = r.prev
+	}
+}
+
+// commit keeps the fee reserved for the sent delivery.
+func (e *Engine) commit(td transaction.Delivery) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.spendFor(td.ChainID).reserved, td.UniqueID)
+}
+
+func (e *Engine) gasLimit(p Policy, td transaction.Delivery) (uint64, error) {
+	if ge, ok := e.next.(GasEstimator); ok {
+		limit, err := ge.EstimateGas(td)
+		if err != nil {
+			return 0, fmt.Errorf("failed to estimate gas of %q: %w", td.UniqueID, err)
+		}
+		return limit, nil
+	}
+	return p.GasLimits[td.Type], nil
+}
+
+// spendFor returns the spending of the chain today, resetting it on a new day.
+func (e *Engine) spendFor(chainID int64) *spend {
+	day := e.now().UTC().Format("2006-01-02")
+	s, ok := e.spent[chainID]
+	if !ok || s.day != day {
+		s = &spend{day: day, total: new(big.Int), charged: make(map[string]*big.Int), reserved: make(map[string]reservation)}
+		e.spent[chainID] = s
+	}
+	return s
+}
+
+func chargeFor(s *spend, id string, fee *big.Int) *big.Int {
+	prev, ok := s.charged[id]
+	if !ok {
+		return fee
+	}
+	if fee.Cmp(prev) <= 0 {
+		return new(big.Int)
+	}
+	return new(big.Int).Sub(fee, prev)
+}
+
+func gasPrice(td transaction.Delivery) *big.Int {
+	if td.GasPrice != nil && td.GasPrice.Sign() > 0 {
+		return td.GasPrice
+	}
+	price := new(big.Int)
+	if td.BaseFee != nil {
+		price.Add(price, td.BaseFee)
+	}
+	if td.GasTip != nil {
+		price.Add(price, td.GasTip)
+	}
+	return price
+}
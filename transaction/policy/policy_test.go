@@ -0,0 +1,206 @@
+package policy
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/transaction"
+)
+
+type courierMock struct {
+	delivered int
+	err       error
+	release   chan struct{}
+}
+
+func (c *courierMock) DeliverTransaction(transaction.Delivery) (*types.Transaction, error) {
+	if c.release != nil {
+		<-c.release
+		return types.NewTx(&types.DynamicFeeTx{}), nil
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	c.delivered++
+	return types.NewTx(&types.DynamicFeeTx{}), nil
+}
+
+func (c *courierMock) CanDeliver(transaction.DeliverableType) bool {
+	return true
+}
+
+type estimatingCourierMock struct {
+	courierMock
+	gas uint64
+}
+
+func (c *estimatingCourierMock) EstimateGas(transaction.Delivery) (uint64, error) {
+	return c.gas, nil
+}
+
+func TestEngine(t *testing.T) {
+	delivery := func(id string, tip int64) transaction.Delivery {
+		return transaction.Delivery{UniqueID: id, ChainID: 137, Type: "settle", BaseFee: big.NewInt(10), GasTip: big.NewInt(tip)}
+	}
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	newEngine := func(p Policy) (*Engine, *courierMock) {
+		c := &courierMock{}
+		e := NewEngine(c, p)
+		e.now = func() time.Time { return now }
+		return e, c
+	}
+	rule := func(err error) Rule {
+		var rej *Rejection
+		if !errors.As(err, &rej) {
+			return ""
+		}
+		return rej.Rule
+	}
+
+	t.Run("max gas price", func(t *testing.T) {
+		e, c := newEngine(Policy{MaxGasPrice: map[int64]*big.Int{137: big.NewInt(20)}})
+		_, err := e.DeliverTransaction(delivery("a", 10))
+		assert.NoError(t, err)
+
+		var rejected []*Rejection
+		e.OnReject(func(r *Rejection) { rejected = append(rejected, r) })
+		_, err = e.DeliverTransaction(delivery("b", 11))
+		assert.ErrorIs(t, err, ErrRejected)
+		assert.Equal(t, RuleMaxGasPrice, rule(err))
+		assert.Len(t, rejected, 1)
+		assert.Equal(t, 1, c.delivered)
+	})
+
+	t.Run("max fee per operation", func(t *testing.T) {
+		e, _ := newEngine(Policy{
+			GasLimits: map[transaction.DeliverableType]uint64{"settle": 100},
+			MaxFee:    map[transaction.DeliverableType]*big.Int{"settle": big.NewInt(2000)},
+		})
+		fee, err := e.Evaluate(delivery("a", 10))
+		assert.NoError(t, err)
+		assert.Equal(t, "2000", fee.String())
+
+		_, err = e.Evaluate(delivery("a", 11))
+		assert.Equal(t, RuleMaxFee, rule(err))
+	})
+
+	t.Run("quiet hours", func(t *testing.T) {
+		e, _ := newEngine(Policy{QuietHours: &QuietHours{Start: 22 * time.Hour, End: 6 * time.Hour, Exempt: []transaction.DeliverableType{"exit"}}})
+		_, err := e.Evaluate(delivery("a", 1))
+		assert.NoError(t, err)
+
+		now = time.Date(2026, 10, 14, 23, 0, 0, 0, time.UTC)
+		defer func() { now = time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC) }()
+		_, err = e.Evaluate(delivery("a", 1))
+		assert.Equal(t, RuleQuietHours, rule(err))
+
+		exit := delivery("b", 1)
+		exit.Type = "exit"
+		_, err = e.Evaluate(exit)
+		assert.NoError(t, err)
+	})
+
+	t.Run("daily budget", func(t *testing.T) {
+		e, c := newEngine(Policy{
+			GasLimits:   map[transaction.DeliverableType]uint64{"settle": 100},
+			DailyBudget: map[int64]*big.Int{137: big.NewInt(4000)},
+		})
+		_, err := e.DeliverTransaction(delivery("a", 10))
+		assert.NoError(t, err)
+		assert.Equal(t, "2000", e.Spent(137).String())
+
+		// A resend with more gas only charges the difference.
+		_, err = e.DeliverTransaction(delivery("a", 20))
+		assert.NoError(t, err)
+		assert.Equal(t, "3000", e.Spent(137).String())
+
+		_, err = e.DeliverTransaction(delivery("b", 10))
+		assert.Equal(t, RuleDailyBudget, rule(err))
+		assert.Equal(t, 2, c.delivered)
+
+		now = now.Add(24 * time.Hour)
+		_, err = e.DeliverTransaction(delivery("b", 10))
+		assert.NoError(t, err)
+		assert.Equal(t, "2000", e.Spent(137).String())
+	})
+
+	t.Run("daily budget is released if the send fails", func(t *testing.T) {
+		e, c := newEngine(Policy{
+			GasLimits:   map[transaction.DeliverableType]uint64{"settle": 100},
+			DailyBudget: map[int64]*big.Int{137: big.NewInt(4000)},
+		})
+		_, err := e.DeliverTransaction(delivery("a", 10))
+		assert.NoError(t, err)
+
+		c.err = errors.New("nonce too low")
+		_, err = e.DeliverTransaction(delivery("a", 20))
+		assert.Error(t, err)
+		_, err = e.DeliverTransaction(delivery("b", 10))
+		assert.Error(t, err)
+		assert.Equal(t, "2000", e.Spent(137).String())
+
+		c.err = nil
+		_, err = e.DeliverTransaction(delivery("b", 10))
+		assert.NoError(t, err)
+		assert.Equal(t, "4000", e.Spent(137).String())
+	})
+
+	t.Run("daily budget is reserved for concurrent sends", func(t *testing.T) {
+		e, c := newEngine(Policy{
+			GasLimits:   map[transaction.DeliverableType]uint64{"settle": 100},
+			DailyBudget: map[int64]*big.Int{137: big.NewInt(4000)},
+		})
+		c.release = make(chan struct{})
+
+		var wg sync.WaitGroup
+		var rejected atomic.Int32
+		for _, id := range []string{"a", "b", "c"} {
+			wg.Add(1)
+			go func(id string) {
+				defer wg.Done()
+				if _, err := e.DeliverTransaction(delivery(id, 10)); rule(err) == RuleDailyBudget {
+					rejected.Add(1)
+				}
+			}(id)
+		}
+		assert.Eventually(t, func() bool { return rejected.Load() == 1 }, time.Second, time.Millisecond)
+		close(c.release)
+		wg.Wait()
+		assert.Equal(t, "4000", e.Spent(137).String())
+	})
+
+	t.Run("unknown gas limit is rejected by fee limits", func(t *testing.T) {
+		c := &estimatingCourierMock{gas: 100}
+		e := NewEngine(c, Policy{DailyBudget: map[int64]*big.Int{137: big.NewInt(2000)}})
+		e.now = func() time.Time { return now }
+		_, err := e.DeliverTransaction(delivery("a", 10))
+		assert.NoError(t, err)
+
+		c.gas = 0
+		_, err = e.DeliverTransaction(delivery("b", 10))
+		assert.Equal(t, RuleDailyBudget, rule(err))
+
+		e.SetPolicy(Policy{MaxFee: map[transaction.DeliverableType]*big.Int{"settle": big.NewInt(2000)}})
+		_, err = e.DeliverTransaction(delivery("b", 10))
+		assert.Equal(t, RuleMaxFee, rule(err))
+		assert.Equal(t, 1, c.delivered)
+		assert.Equal(t, "2000", e.Spent(137).String())
+	})
+
+	t.Run("runtime update", func(t *testing.T) {
+		e, _ := newEngine(Policy{})
+		_, err := e.Evaluate(delivery("a", 100))
+		assert.NoError(t, err)
+
+		e.SetPolicy(Policy{MaxGasPrice: map[int64]*big.Int{137: big.NewInt(1)}})
+		_, err = e.Evaluate(delivery("a", 100))
+		assert.Equal(t, RuleMaxGasPrice, rule(err))
+	})
+}
@@ -0,0 +1,77 @@
+package transaction
+
+import (
+	"sync"
+	"time"
+)
+
+// ChainRateLimits are the max number of transactions sent per minute per chain ID.
+// Chains without a limit are not limited.
+type ChainRateLimits map[int64]uint
+
+// rateLimiter is a token bucket per chain. Every bucket holds up to a minute
+// worth of transactions and is refilled continuously.
+type rateLimiter struct {
+	limits  ChainRateLimits
+	buckets map[int64]*tokenBucket
+	mu      sync.Mutex
+
+	now func() time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(limits ChainRateLimits) *rateLimiter {
+	return &rateLimiter{
+		limits:  limits,
+		buckets: make(map[int64]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// allow takes a token of the chain and returns false if there are none left.
+func (r *rateLimiter) allow(chainID int64) bool {
+	return r.take(chainID, true)
+}
+
+// available returns true if the chain has a token left without taking it.
+func (r *rateLimiter) available(chainID int64) bool {
+	return r.take(chainID, false)
+}
+
+func (r *rateLimiter) take(chainID int64, consume bool) bool {
+	if r == nil {
+		return true
+	}
+	limit, ok := r.limits[chainID]
+	if !ok || limit == 0 {
+		return true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	b, ok := r.buckets[chainID]
+	if !ok {
+		b = &tokenBucket{tokens: float64(limit), last: now}
+		r.buckets[chainID] = b
+	}
+
+	b.tokens += now.Sub(b.last).Minutes() * float64(limit)
+	if b.tokens > float64(limit) {
+		b.tokens = float64(limit)
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	if consume {
+		b.tokens--
+	}
+	return true
+}
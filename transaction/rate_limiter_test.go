@@ -0,0 +1,33 @@
+package transaction
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Now()
+	rl := newRateLimiter(ChainRateLimits{1: 2})
+	rl.now = func() time.Time { return now }
+
+	assert.True(t, rl.available(1))
+	assert.True(t, rl.allow(1))
+	assert.True(t, rl.allow(1))
+	assert.False(t, rl.available(1))
+	assert.False(t, rl.allow(1))
+	assert.True(t, rl.allow(2), "chains without a limit are not limited")
+
+	now = now.Add(30 * time.Second)
+	assert.True(t, rl.allow(1))
+	assert.False(t, rl.allow(1))
+
+	now = now.Add(time.Hour)
+	assert.True(t, rl.allow(1))
+	assert.True(t, rl.allow(1))
+	assert.False(t, rl.allow(1), "bucket holds at most a minute worth of transactions")
+
+	var nilLimiter *rateLimiter
+	assert.True(t, nilLimiter.allow(1))
+}
@@ -0,0 +1,37 @@
+package transaction
+
+import (
+	"errors"
+
+	"github.com/mysteriumnetwork/payments/client"
+)
+
+var (
+	// ErrNonceTooLow is returned by nodes when the nonce of a transaction was already used.
+	// The depot reloads the nonces of the sender, if the nonce tracker supports it.
+	ErrNonceTooLow = client.ErrNonceTooLow
+	// ErrReplacementUnderpriced is returned by nodes when a transaction does not pay enough
+	// to replace the pending one with the same nonce. The depot bumps the fee and retries once.
+	ErrReplacementUnderpriced = client.ErrReplacementUnderpriced
+	// ErrInsufficientFunds is returned by nodes when the sender cannot pay for a transaction.
+	// The depot hands the delivery to the dead letter sink right away.
+	ErrInsufficientFunds = client.ErrInsufficientFunds
+	// ErrAlreadyKnown is returned by nodes when the transaction is already in their mempool.
+	// The depot keeps tracking the delivery without reporting a failure.
+	ErrAlreadyKnown = client.ErrAlreadyKnown
+)
+
+// sendErrors are the classes of `client.ClassifyError` the depot handles.
+var sendErrors = []error{ErrNonceTooLow, ErrReplacementUnderpriced, ErrInsufficientFunds, ErrAlreadyKnown}
+
+// ClassifySendError wraps an error returned while sending a transaction with
+// the typed error matching its message. Unknown errors are returned as is.
+func ClassifySendError(err error) error {
+	classified := client.ClassifyError(err)
+	for _, c := range sendErrors {
+		if errors.Is(classified, c) {
+			return classified
+		}
+	}
+	return err
+}
@@ -0,0 +1,34 @@
+package transaction
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifySendError(t *testing.T) {
+	for _, test := range []struct {
+		msg  string
+		want error
+	}{
+		{msg: "nonce too low: next nonce 5, tx nonce 4", want: ErrNonceTooLow},
+		{msg: "replacement transaction underpriced", want: ErrReplacementUnderpriced},
+		{msg: "transaction underpriced: tip needed 30, tip permitted 1", want: ErrReplacementUnderpriced},
+		{msg: "insufficient funds for gas * price + value", want: ErrInsufficientFunds},
+		{msg: "already known", want: ErrAlreadyKnown},
+		{msg: "Known transaction: 0x12", want: ErrAlreadyKnown},
+	} {
+		t.Run(test.msg, func(t *testing.T) {
+			orig := errors.New(test.msg)
+			err := ClassifySendError(orig)
+			assert.ErrorIs(t, err, test.want)
+			assert.ErrorIs(t, err, orig)
+		})
+	}
+
+	unknown := errors.New("execution reverted")
+	assert.Equal(t, unknown, ClassifySendError(unknown))
+	assert.Equal(t, ErrNonceTooLow, ClassifySendError(ErrNonceTooLow))
+	assert.NoError(t, ClassifySendError(nil))
+}
@@ -0,0 +1,129 @@
+package transaction
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/mysteriumnetwork/payments/client"
+)
+
+// TracerName is the instrumentation name of the spans started by the depot.
+const TracerName = "github.com/mysteriumnetwork/payments/transaction"
+
+// Span attributes set by the depot, next to the chain ID and tx hash of the client package.
+const (
+	AttrDeliveryID     = attribute.Key("delivery.id")
+	AttrDeliverySender = attribute.Key("delivery.sender")
+	AttrDeliveryNonce  = attribute.Key("delivery.nonce")
+)
+
+// depotTracing traces the deliveries of the depot. The sends and the confirmation wait of
+// a delivery are children of the span it was enqueued in, so a delivery is a single trace
+// together with the request which queued it. Deliveries recovered after a restart start new traces.
+type depotTracing struct {
+	tracer trace.Tracer
+
+	mu      sync.Mutex
+	parents map[string]trace.SpanContext
+	waits   map[string]trace.Span
+}
+
+func newDepotTracing(tp trace.TracerProvider) *depotTracing {
+	return &depotTracing{
+		tracer:  tp.Tracer(TracerName),
+		parents: make(map[string]trace.SpanContext),
+		waits:   make(map[string]trace.Span),
+	}
+}
+
+func (t *depotTracing) enqueue(ctx context.Context, req DeliveryRequest) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, "Depot.EnqueueDelivery", trace.WithAttributes(
+		client.AttrChainID.Int64(req.ChainID),
+		AttrDeliverySender.String(req.Sender.Hex()),
+		attribute.String("delivery.type", string(req.Type)),
+	))
+}
+
+// queued keeps the span the delivery was queued in as the parent of its later spans.
+func (t *depotTracing) queued(ctx context.Context, td Delivery) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(AttrDeliveryID.String(td.UniqueID), AttrDeliveryNonce.Int64(int64(td.Nonce)))
+	if !span.SpanContext().IsValid() {
+		return
+	}
+
+	t.mu.Lock()
+	t.parents[td.UniqueID] = span.SpanContext()
+	t.mu.Unlock()
+}
+
+func (t *depotTracing) context(td Delivery) context.Context {
+	t.mu.Lock()
+	parent, ok := t.parents[td.UniqueID]
+	t.mu.Unlock()
+	if !ok {
+		return context.Background()
+	}
+	return trace.ContextWithSpanContext(context.Background(), parent)
+}
+
+func (t *depotTracing) send(td Delivery) trace.Span {
+	_, span := t.tracer.Start(t.context(td), "Depot.SendTransaction", trace.WithAttributes(
+		client.AttrChainID.Int64(td.ChainID),
+		AttrDeliveryID.String(td.UniqueID),
+		AttrDeliveryNonce.Int64(int64(td.Nonce)),
+		attribute.Int64("delivery.replacements", int64(td.Replacements)),
+	))
+	return span
+}
+
+// sent starts the confirmation wait of the delivery on its first send,
+// replacements are recorded as events of the running wait.
+func (t *depotTracing) sent(td Delivery, send trace.Span, tx *types.Transaction) {
+	hash := client.AttrTxHash.String(tx.Hash().Hex())
+	send.SetAttributes(hash)
+
+	t.mu.Lock()
+	wait, ok := t.waits[td.UniqueID]
+	t.mu.Unlock()
+	if ok {
+		wait.AddEvent("replaced", trace.WithAttributes(hash))
+		wait.SetAttributes(hash)
+		return
+	}
+
+	_, wait = t.tracer.Start(t.context(td), "Depot.WaitConfirmation", trace.WithAttributes(
+		client.AttrChainID.Int64(td.ChainID),
+		AttrDeliveryID.String(td.UniqueID),
+		AttrDeliveryNonce.Int64(int64(td.Nonce)),
+		hash,
+	))
+	if !wait.IsRecording() {
+		return
+	}
+	t.mu.Lock()
+	t.waits[td.UniqueID] = wait
+	t.mu.Unlock()
+}
+
+// delivered ends the confirmation wait of the delivery.
+func (t *depotTracing) delivered(td Delivery) {
+	t.mu.Lock()
+	wait, ok := t.waits[td.UniqueID]
+	delete(t.waits, td.UniqueID)
+	delete(t.parents, td.UniqueID)
+	t.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	wait.SetAttributes(client.AttrTxHash.String(td.LastTransactionHash().Hex()))
+	if td.ConfirmedBlock > 0 {
+		wait.SetAttributes(attribute.Int64("tx.block", int64(td.ConfirmedBlock)))
+	}
+	wait.End()
+}
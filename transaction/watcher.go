@@ -0,0 +1,252 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// WatchStatus is the final status of a watched transaction.
+type WatchStatus string
+
+const (
+	// WatchStatusConfirmed means the transaction was mined, its receipt is in the event.
+	WatchStatusConfirmed WatchStatus = "confirmed"
+	// WatchStatusReplaced means another transaction with the same nonce was mined.
+	WatchStatusReplaced WatchStatus = "replaced"
+	// WatchStatusDropped means the transaction has not been seen for longer than the drop timeout.
+	WatchStatusDropped WatchStatus = "dropped"
+)
+
+// ErrAlreadyWatched is returned when watching a transaction which is already watched.
+var ErrAlreadyWatched = errors.New("transaction is already watched")
+
+// WatchEvent is sent once the final status of a watched transaction is known.
+type WatchEvent struct {
+	ChainID int64
+	Hash    common.Hash
+	Status  WatchStatus
+	// Receipt is only set for confirmed transactions.
+	Receipt *DeliveryReceipt
+}
+
+// WatcherClient is used to track transactions, `client.MultichainBlockchainClient` satisfies it.
+type WatcherClient interface {
+	TransactionReceipt(chainID int64, hash common.Hash) (*types.Receipt, error)
+	TransactionByHash(chainID int64, hash common.Hash) (*types.Transaction, bool, error)
+	NonceAt(chainID int64, account common.Address, blockNum *big.Int) (uint64, error)
+}
+
+// WatcherConfig configures the watcher.
+type WatcherConfig struct {
+	// Interval is the interval between checks of the watched transactions.
+	Interval time.Duration
+	// DropAfter is the time after which a transaction the node does not know about is considered dropped.
+	DropAfter time.Duration
+}
+
+// Watcher tracks arbitrary transactions, not only the ones sent by the depot,
+// until they are confirmed, replaced by another transaction with the same nonce or dropped.
+type Watcher struct {
+	bc  WatcherClient
+	cfg WatcherConfig
+
+	watched map[watchKey]*watchedTx
+	mu      sync.Mutex
+
+	logFn func(error)
+	now   func() time.Time
+
+	once sync.Once
+	stop chan struct{}
+}
+
+type watchKey struct {
+	chainID int64
+	hash    common.Hash
+}
+
+type watchedTx struct {
+	sink     chan WatchEvent
+	sender   *common.Address
+	nonce    uint64
+	lastSeen time.Time
+}
+
+// NewWatcher returns a new transaction watcher.
+func NewWatcher(bc WatcherClient, cfg WatcherConfig) *Watcher {
+	return &Watcher{
+		bc:      bc,
+		cfg:     cfg,
+		watched: make(map[watchKey]*watchedTx),
+		logFn:   func(error) {},
+		now:     time.Now,
+		stop:    make(chan struct{}),
+	}
+}
+
+// AttachLogger allows the caller to attach an optional logger
+// which will receive errors that happen while checking transactions.
+//
+// This method is not thread safe and should be called before `Run`.
+func (w *Watcher) AttachLogger(fn func(err error)) {
+	w.logFn = fn
+}
+
+// Watch starts watching the transaction. The returned channel receives a single
+// event once the final status of the transaction is known and is then closed.
+func (w *Watcher) Watch(chainID int64, hash common.Hash) (<-chan WatchEvent, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	key := watchKey{chainID: chainID, hash: hash}
+	if _, ok := w.watched[key]; ok {
+		return nil, fmt.Errorf("%w: %q", ErrAlreadyWatched, hash.Hex())
+	}
+
+	sink := make(chan WatchEvent, 1)
+	w.watched[key] = &watchedTx{sink: sink, lastSeen: w.now()}
+	return sink, nil
+}
+
+// Unwatch stops watching the transaction and closes its channel without an event.
+func (w *Watcher) Unwatch(chainID int64, hash common.Hash) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	key := watchKey{chainID: chainID, hash: hash}
+	if tx, ok := w.watched[key]; ok {
+		close(tx.sink)
+		delete(w.watched, key)
+	}
+}
+
+// Run starts checking the watched transactions periodically.
+func (w *Watcher) Run() {
+	go func() {
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-time.After(w.cfg.Interval):
+				w.Check()
+			}
+		}
+	}()
+}
+
+// Stop stops the watcher.
+func (w *Watcher) Stop() {
+	w.once.Do(func() {
+		close(w.stop)
+	})
+}
+
+// Check checks every watched transaction once and sends the events of the ones with a final status.
+func (w *Watcher) Check() {
+	w.mu.Lock()
+	keys := make([]watchKey, 0, len(w.watched))
+	for k := range w.watched {
+		keys = append(keys, k)
+	}
+	w.mu.Unlock()
+
+	for _, k := range keys {
+		w.mu.Lock()
+		tx, ok := w.watched[k]
+		w.mu.Unlock()
+		if !ok {
+			continue
+		}
+
+		ev, done, err := w.check(k, tx)
+		if err != nil {
+			w.logFn(fmt.Errorf("failed to check transaction %q: %w", k.hash.Hex(), err))
+			continue
+		}
+		if done {
+			w.finish(k, ev)
+		}
+	}
+}
+
+func (w *Watcher) check(k watchKey, wtx *watchedTx) (WatchEvent, bool, error) {
+	ev := WatchEvent{ChainID: k.chainID, Hash: k.hash}
+
+	receipt, err := w.bc.TransactionReceipt(k.chainID, k.hash)
+	if err == nil {
+		ev.Status = WatchStatusConfirmed
+		ev.Receipt = newDeliveryReceipt(receipt)
+		return ev, true, nil
+	}
+	if !errors.Is(err, ethereum.NotFound) {
+		return ev, false, err
+	}
+
+	tx, _, err := w.bc.TransactionByHash(k.chainID, k.hash)
+	switch {
+	case err == nil:
+		w.seen(k.chainID, wtx, tx)
+	case !errors.Is(err, ethereum.NotFound):
+		return ev, false, err
+	}
+
+	w.mu.Lock()
+	sender, nonce, lastSeen := wtx.sender, wtx.nonce, wtx.lastSeen
+	w.mu.Unlock()
+
+	if sender != nil {
+		confirmed, err := w.bc.NonceAt(k.chainID, *sender, nil)
+		if err != nil {
+			return ev, false, err
+		}
+		// The nonce was used up without our transaction being mined.
+		if confirmed > nonce {
+			ev.Status = WatchStatusReplaced
+			return ev, true, nil
+		}
+	}
+
+	if tx == nil && w.cfg.DropAfter > 0 && w.now().Sub(lastSeen) > w.cfg.DropAfter {
+		ev.Status = WatchStatusDropped
+		return ev, true, nil
+	}
+	return ev, false, nil
+}
+
+func (w *Watcher) seen(chainID int64, wtx *watchedTx, tx *types.Transaction) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	wtx.lastSeen = w.now()
+	if wtx.sender != nil {
+		return
+	}
+
+	sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(chainID)), tx)
+	if err != nil {
+		w.logFn(fmt.Errorf("failed to recover sender of %q: %w", tx.Hash().Hex(), err))
+		return
+	}
+	wtx.sender = &sender
+	wtx.nonce = tx.Nonce()
+}
+
+func (w *Watcher) finish(k watchKey, ev WatchEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	tx, ok := w.watched[k]
+	if !ok {
+		return
+	}
+	tx.sink <- ev
+	close(tx.sink)
+	delete(w.watched, k)
+}
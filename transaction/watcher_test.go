@@ -0,0 +1,117 @@
+package transaction
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/client"
+)
+
+var _ WatcherClient = (*client.MultichainBlockchainClient)(nil)
+
+type watcherClientMock struct {
+	lock     sync.Mutex
+	txs      map[common.Hash]*types.Transaction
+	receipts map[common.Hash]*types.Receipt
+	nonce    uint64
+}
+
+func (m *watcherClientMock) TransactionReceipt(_ int64, hash common.Hash) (*types.Receipt, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if r, ok := m.receipts[hash]; ok {
+		return r, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (m *watcherClientMock) TransactionByHash(_ int64, hash common.Hash) (*types.Transaction, bool, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if tx, ok := m.txs[hash]; ok {
+		return tx, true, nil
+	}
+	return nil, false, ethereum.NotFound
+}
+
+func (m *watcherClientMock) NonceAt(_ int64, _ common.Address, _ *big.Int) (uint64, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.nonce, nil
+}
+
+func TestWatcher(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	signTx := func(nonce uint64) *types.Transaction {
+		tx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+			ChainID:   big.NewInt(chainId),
+			Nonce:     nonce,
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: big.NewInt(2),
+			Gas:       21000,
+		}), types.LatestSignerForChainID(big.NewInt(chainId)), key)
+		assert.NoError(t, err)
+		return tx
+	}
+
+	confirmed, replaced := signTx(1), signTx(2)
+	dropped := common.HexToHash("0x3")
+	bc := &watcherClientMock{
+		txs: map[common.Hash]*types.Transaction{
+			confirmed.Hash(): confirmed,
+			replaced.Hash():  replaced,
+		},
+		receipts: map[common.Hash]*types.Receipt{},
+		nonce:    1,
+	}
+
+	now := time.Now()
+	w := NewWatcher(bc, WatcherConfig{Interval: time.Millisecond, DropAfter: time.Minute})
+	w.now = func() time.Time { return now }
+
+	confirmedCh, err := w.Watch(chainId, confirmed.Hash())
+	assert.NoError(t, err)
+	replacedCh, err := w.Watch(chainId, replaced.Hash())
+	assert.NoError(t, err)
+	droppedCh, err := w.Watch(chainId, dropped)
+	assert.NoError(t, err)
+	_, err = w.Watch(chainId, dropped)
+	assert.ErrorIs(t, err, ErrAlreadyWatched)
+
+	w.Check()
+	assert.Len(t, w.watched, 3, "everything is still pending")
+
+	bc.lock.Lock()
+	bc.receipts[confirmed.Hash()] = &types.Receipt{TxHash: confirmed.Hash(), Status: types.ReceiptStatusSuccessful, BlockNumber: big.NewInt(5)}
+	delete(bc.txs, replaced.Hash())
+	bc.nonce = 3
+	bc.lock.Unlock()
+	now = now.Add(2 * time.Minute)
+
+	w.Run()
+	defer w.Stop()
+
+	ev := <-confirmedCh
+	assert.Equal(t, WatchStatusConfirmed, ev.Status)
+	assert.Equal(t, uint64(5), ev.Receipt.BlockNumber)
+	assert.Equal(t, WatchStatusReplaced, (<-replacedCh).Status)
+	assert.Equal(t, WatchStatusDropped, (<-droppedCh).Status)
+
+	_, open := <-confirmedCh
+	assert.False(t, open)
+
+	ch, err := w.Watch(chainId, dropped)
+	assert.NoError(t, err)
+	w.Unwatch(chainId, dropped)
+	_, open = <-ch
+	assert.False(t, open)
+}
@@ -0,0 +1,407 @@
+// Package watchtower watches channels of identities for adversarial actions
+// and raises alerts, optionally responding with protective transactions while
+// the challenge window is still open.
+package watchtower
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/mysteriumnetwork/payments/bindings"
+	"github.com/mysteriumnetwork/payments/client"
+)
+
+// Event signatures of the watched contract events.
+var (
+	TopicBeneficiaryChanged = common.HexToHash("0x768099735d1c322a05a5b9d7b76d99682a1833d3f7055e5ede25e0f2eeaa8c6d")
+	TopicExitRequested      = common.HexToHash("0xe60f0366d8d61555184ea027447889648bae94ebfb1202a39544b6b6803969db")
+	TopicNewStake           = common.HexToHash("0xc5f0715c45dab2e8f14871936119e3c64fd5841d397130c2d1db743d142522cb")
+)
+
+// AlertType is a type of a detected action.
+type AlertType string
+
+const (
+	AlertExitRequested      AlertType = "exit_requested"
+	AlertBeneficiaryChanged AlertType = "beneficiary_changed"
+	AlertStakeDecreased     AlertType = "stake_decreased"
+)
+
+// Target is an identity whose channels are watched.
+type Target struct {
+	Identity common.Address
+	Registry common.Address
+	HermesID common.Address
+
+	// ConsumerChannel is the address of the identity's consumer channel. Exit requests from it are alerted.
+	ConsumerChannel common.Address
+	// ProviderChannelID is the ID of the identity's channel in hermes. Stake decreases are alerted.
+	ProviderChannelID common.Hash
+	// Beneficiary is the expected beneficiary. Changing it to any other address is alerted.
+	// If zero, every beneficiary change is alerted.
+	Beneficiary common.Address
+}
+
+// Alert describes a detected action.
+type Alert struct {
+	Type     AlertType
+	ChainID  int64
+	Target   Target
+	Log      types.Log
+	Details  string
+	Detected time.Time
+
+	// DeadlineBlock is the last block at which the action can still be challenged, zero if not applicable.
+	DeadlineBlock uint64
+}
+
+// Responder submits a protective transaction for an alert, for example
+// settling the latest promise before a requested exit goes through.
+type Responder interface {
+	Respond(alert Alert) (*types.Transaction, error)
+}
+
+// BCClient is the blockchain client used by the watchtower, `client.BC` satisfies it.
+type BCClient interface {
+	FilterLogs(q ethereum.FilterQuery) ([]types.Log, error)
+	BlockNumber() (uint64, error)
+}
+
+// StakeReader is optionally implemented by the `BCClient` to read the current stake of
+// provider channels when their targets are added, so the first decrease is detected.
+// `client.BC` satisfies it.
+type StakeReader interface {
+	GetProviderChannelByID(hermesID common.Address, channelID []byte) (client.ProviderChannel, error)
+}
+
+// Config configures the watchtower.
+type Config struct {
+	ChainID int64
+	// StartBlock is the first block scanned for events.
+	StartBlock uint64
+	// Confirmations is how many blocks behind the head are scanned to avoid reorgs.
+	Confirmations uint64
+	// MaxBlockRange limits the block range of a single log query. Zero means no limit.
+	MaxBlockRange uint64
+	// Interval is how often new blocks are scanned.
+	Interval time.Duration
+}
+
+// Watchtower monitors registry, hermes and channel contracts for actions against watched identities.
+type Watchtower struct {
+	bc  BCClient
+	cfg Config
+
+	targets   []Target
+	stakes    map[common.Hash]*big.Int
+	nextBlock uint64
+	mu        sync.Mutex
+	// scanMu keeps concurrent scans from processing the same blocks twice.
+	scanMu sync.Mutex
+
+	registry *bindings.RegistryFilterer
+	hermes   *bindings.HermesImplementationFilterer
+	channel  *bindings.ChannelImplementationFilterer
+
+	listeners []func(Alert)
+	responder Responder
+	logFn     func(error)
+
+	once sync.Once
+	stop chan struct{}
+}
+
+// New returns a new watchtower for the given targets.
+func New(bc BCClient, cfg Config, targets ...Target) (*Watchtower, error) {
+	registry, err := bindings.NewRegistryFilterer(common.Address{}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create registry filterer: %w", err)
+	}
+	hermes, err := bindings.NewHermesImplementationFilterer(common.Address{}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create hermes filterer: %w", err)
+	}
+	channel, err := bindings.NewChannelImplementationFilterer(common.Address{}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create channel filterer: %w", err)
+	}
+
+	w := &Watchtower{
+		bc:        bc,
+		cfg:       cfg,
+		stakes:    make(map[common.Hash]*big.Int),
+		nextBlock: cfg.StartBlock,
+		registry:  registry,
+		hermes:    hermes,
+		channel:   channel,
+		logFn:     func(error) {},
+		stop:      make(chan struct{}),
+	}
+	if err := w.Watch(targets...); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Watch adds targets to the watchtower. If the blockchain client implements `StakeReader`,
+// the current stake of their provider channels is read first.
+func (w *Watchtower) Watch(targets ...Target) error {
+	if sr, ok := w.bc.(StakeReader); ok {
+		for _, t := range targets {
+			if t.ProviderChannelID == (common.Hash{}) {
+				continue
+			}
+			ch, err := sr.GetProviderChannelByID(t.HermesID, t.ProviderChannelID.Bytes())
+			if err != nil {
+				return fmt.Errorf("failed to get stake of provider channel %s: %w", t.ProviderChannelID.Hex(), err)
+			}
+			if ch.Stake != nil {
+				w.SetKnownStake(t.ProviderChannelID, ch.Stake)
+			}
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.targets = append(w.targets, targets...)
+	return nil
+}
+
+// OnAlert registers a listener for alerts.
+//
+// This method is not thread safe and should be called before `Run`.
+func (w *Watchtower) OnAlert(fn func(Alert)) {
+	w.listeners = append(w.listeners, fn)
+}
+
+// AttachResponder attaches a responder which is called for
+// alerts that can still be challenged.
+//
+// This method is not thread safe and should be called before `Run`.
+func (w *Watchtower) AttachResponder(r Responder) {
+	w.responder = r
+}
+
+// AttachLogger allows the caller to attach an optional logger.
+//
+// This method is not thread safe and should be called before `Run`.
+func (w *Watchtower) AttachLogger(fn func(err error)) {
+	w.logFn = fn
+}
+
+// Run will spawn a goroutine which scans new blocks every interval.
+func (w *Watchtower) Run() {
+	go func() {
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-time.After(w.cfg.Interval):
+				if err := w.Scan(); err != nil {
+					w.logFn(err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop will stop the watchtower.
+func (w *Watchtower) Stop() {
+	w.once.Do(func() {
+		close(w.stop)
+	})
+}
+
+// Cursor returns the next block the watchtower will scan.
+func (w *Watchtower) Cursor() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.nextBlock
+}
+
+// SetCursor sets the next block the watchtower will scan, for example when restoring state.
+func (w *Watchtower) SetCursor(block uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.nextBlock = block
+}
+
+// Scan processes all of the confirmed blocks that were not yet scanned.
+func (w *Watchtower) Scan() error {
+	w.scanMu.Lock()
+	defer w.scanMu.Unlock()
+
+	head, err := w.bc.BlockNumber()
+	if err != nil {
+		return fmt.Errorf("failed to get block number: %w", err)
+	}
+	if head < w.cfg.Confirmations {
+		return nil
+	}
+	head -= w.cfg.Confirmations
+
+	w.mu.Lock()
+	from := w.nextBlock
+	targets := append([]Target(nil), w.targets...)
+	w.mu.Unlock()
+
+	for from <= head {
+		to := head
+		if w.cfg.MaxBlockRange > 0 && to-from+1 > w.cfg.MaxBlockRange {
+			to = from + w.cfg.MaxBlockRange - 1
+		}
+
+		logs, err := w.bc.FilterLogs(query(targets, from, to))
+		if err != nil {
+			return fmt.Errorf("failed to filter logs from %d to %d: %w", from, to, err)
+		}
+		for _, l := range logs {
+			w.process(targets, l, head+w.cfg.Confirmations)
+		}
+
+		from = to + 1
+		w.mu.Lock()
+		w.nextBlock = from
+		w.mu.Unlock()
+	}
+
+	return nil
+}
+
+func query(targets []Target, from, to uint64) ethereum.FilterQuery {
+	seen := make(map[common.Address]struct{})
+	var addresses []common.Address
+	add := func(a common.Address) {
+		if a == (common.Address{}) {
+			return
+		}
+		if _, ok := seen[a]; !ok {
+			seen[a] = struct{}{}
+			addresses = append(addresses, a)
+		}
+	}
+	for _, t := range targets {
+		add(t.Registry)
+		add(t.HermesID)
+		add(t.ConsumerChannel)
+	}
+
+	return ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: addresses,
+		Topics:    [][]common.Hash{{TopicBeneficiaryChanged, TopicExitRequested, TopicNewStake}},
+	}
+}
+
+func (w *Watchtower) process(targets []Target, l types.Log, head uint64) {
+	if len(l.Topics) == 0 {
+		return
+	}
+
+	for _, t := range targets {
+		alert, ok, err := w.match(t, l)
+		if err != nil {
+			w.logFn(fmt.Errorf("failed to parse log %s/%d: %w", l.TxHash.Hex(), l.Index, err))
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		alert.ChainID = w.cfg.ChainID
+		alert.Target = t
+		alert.Log = l
+		alert.Detected = time.Now().UTC()
+		w.raise(alert, head)
+	}
+}
+
+func (w *Watchtower) match(t Target, l types.Log) (Alert, bool, error) {
+	switch l.Topics[0] {
+	case TopicBeneficiaryChanged:
+		if l.Address != t.Registry {
+			return Alert{}, false, nil
+		}
+		ev, err := w.registry.ParseBeneficiaryChanged(l)
+		if err != nil {
+			return Alert{}, false, err
+		}
+		if ev.Identity != t.Identity || ev.NewBeneficiary == t.Beneficiary {
+			return Alert{}, false, nil
+		}
+		return Alert{
+			Type:    AlertBeneficiaryChanged,
+			Details: fmt.Sprintf("beneficiary changed to %s", ev.NewBeneficiary.Hex()),
+		}, true, nil
+	case TopicExitRequested:
+		if l.Address != t.ConsumerChannel {
+			return Alert{}, false, nil
+		}
+		ev, err := w.channel.ParseExitRequested(l)
+		if err != nil {
+			return Alert{}, false, err
+		}
+		return Alert{
+			Type:          AlertExitRequested,
+			Details:       fmt.Sprintf("exit requested with timelock until block %s", ev.Timelock),
+			DeadlineBlock: ev.Timelock.Uint64(),
+		}, true, nil
+	case TopicNewStake:
+		if l.Address != t.HermesID {
+			return Alert{}, false, nil
+		}
+		ev, err := w.hermes.ParseNewStake(l)
+		if err != nil {
+			return Alert{}, false, err
+		}
+		if common.Hash(ev.ChannelId) != t.ProviderChannelID {
+			return Alert{}, false, nil
+		}
+
+		w.mu.Lock()
+		prev, known := w.stakes[t.ProviderChannelID]
+		w.stakes[t.ProviderChannelID] = ev.StakeAmount
+		w.mu.Unlock()
+		if !known || ev.StakeAmount.Cmp(prev) >= 0 {
+			return Alert{}, false, nil
+		}
+		return Alert{
+			Type:    AlertStakeDecreased,
+			Details: fmt.Sprintf("stake decreased from %s to %s", prev, ev.StakeAmount),
+		}, true, nil
+	}
+
+	return Alert{}, false, nil
+}
+
+// SetKnownStake sets the current stake of a provider channel so that the first decrease is detected.
+// Use it when the blockchain client does not implement `StakeReader`.
+func (w *Watchtower) SetKnownStake(channelID common.Hash, stake *big.Int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stakes[channelID] = new(big.Int).Set(stake)
+}
+
+func (w *Watchtower) raise(alert Alert, head uint64) {
+	for _, fn := range w.listeners {
+		fn(alert)
+	}
+
+	if w.responder == nil {
+		return
+	}
+	if alert.DeadlineBlock != 0 && alert.DeadlineBlock <= head {
+		w.logFn(fmt.Errorf("challenge window for %s of %s closed at block %d", alert.Type, alert.Target.Identity.Hex(), alert.DeadlineBlock))
+		return
+	}
+	if _, err := w.responder.Respond(alert); err != nil {
+		w.logFn(fmt.Errorf("failed to respond to %s of %s: %w", alert.Type, alert.Target.Identity.Hex(), err))
+	}
+}
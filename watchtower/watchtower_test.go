@@ -0,0 +1,159 @@
+package watchtower
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/payments/client"
+)
+
+type bcMock struct {
+	head    uint64
+	logs    []types.Log
+	queries []ethereum.FilterQuery
+	mu      sync.Mutex
+}
+
+func (m *bcMock) BlockNumber() (uint64, error) {
+	return m.head, nil
+}
+
+func (m *bcMock) FilterLogs(q ethereum.FilterQuery) ([]types.Log, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queries = append(m.queries, q)
+	var res []types.Log
+	for _, l := range m.logs {
+		if l.BlockNumber >= q.FromBlock.Uint64() && l.BlockNumber <= q.ToBlock.Uint64() {
+			res = append(res, l)
+		}
+	}
+	return res, nil
+}
+
+type stakeBCMock struct {
+	bcMock
+	stake *big.Int
+}
+
+func (m *stakeBCMock) GetProviderChannelByID(common.Address, []byte) (client.ProviderChannel, error) {
+	return client.ProviderChannel{Stake: m.stake}, nil
+}
+
+type responderMock struct {
+	alerts []Alert
+}
+
+func (r *responderMock) Respond(alert Alert) (*types.Transaction, error) {
+	r.alerts = append(r.alerts, alert)
+	return nil, nil
+}
+
+func word(b []byte) []byte {
+	return common.LeftPadBytes(b, 32)
+}
+
+func TestWatchtower(t *testing.T) {
+	target := Target{
+		Identity:          common.HexToAddress("0x1"),
+		Registry:          common.HexToAddress("0x2"),
+		HermesID:          common.HexToAddress("0x3"),
+		ConsumerChannel:   common.HexToAddress("0x4"),
+		ProviderChannelID: common.HexToHash("0x5"),
+		Beneficiary:       common.HexToAddress("0x6"),
+	}
+	other := common.HexToAddress("0x99")
+
+	bc := &stakeBCMock{stake: big.NewInt(100), bcMock: bcMock{
+		head: 110,
+		logs: []types.Log{
+			// expected beneficiary, ignored
+			{Address: target.Registry, BlockNumber: 1, Topics: []common.Hash{TopicBeneficiaryChanged, common.BytesToHash(target.Identity.Bytes())}, Data: word(target.Beneficiary.Bytes())},
+			// another identity, ignored
+			{Address: target.Registry, BlockNumber: 2, Topics: []common.Hash{TopicBeneficiaryChanged, common.BytesToHash(other.Bytes())}, Data: word(other.Bytes())},
+			{Address: target.Registry, BlockNumber: 3, Topics: []common.Hash{TopicBeneficiaryChanged, common.BytesToHash(target.Identity.Bytes())}, Data: word(other.Bytes())},
+			{Address: target.HermesID, BlockNumber: 4, Topics: []common.Hash{TopicNewStake, target.ProviderChannelID}, Data: word(math.U256Bytes(big.NewInt(50)))},
+			{Address: target.ConsumerChannel, BlockNumber: 5, Topics: []common.Hash{TopicExitRequested}, Data: word(math.U256Bytes(big.NewInt(200)))},
+			{Address: target.ConsumerChannel, BlockNumber: 6, Topics: []common.Hash{TopicExitRequested}, Data: word(math.U256Bytes(big.NewInt(50)))},
+			// beyond confirmed head
+			{Address: target.HermesID, BlockNumber: 101, Topics: []common.Hash{TopicNewStake, target.ProviderChannelID}, Data: word(math.U256Bytes(big.NewInt(10)))},
+		},
+	}}
+
+	// The stake of the provider channel is read from the chain, so the first decrease is alerted.
+	w, err := New(bc, Config{ChainID: 137, Confirmations: 10, MaxBlockRange: 40}, target)
+	assert.NoError(t, err)
+
+	var alerts []Alert
+	w.OnAlert(func(a Alert) { alerts = append(alerts, a) })
+	responder := &responderMock{}
+	w.AttachResponder(responder)
+	var logged []error
+	w.AttachLogger(func(err error) { logged = append(logged, err) })
+
+	assert.NoError(t, w.Scan())
+	assert.Len(t, bc.queries, 3)
+	assert.Equal(t, []common.Address{target.Registry, target.HermesID, target.ConsumerChannel}, bc.queries[0].Addresses)
+
+	if assert.Len(t, alerts, 4) {
+		assert.Equal(t, AlertBeneficiaryChanged, alerts[0].Type)
+		assert.Equal(t, int64(137), alerts[0].ChainID)
+		assert.Equal(t, AlertStakeDecreased, alerts[1].Type)
+		assert.Equal(t, "stake decreased from 100 to 50", alerts[1].Details)
+		assert.Equal(t, AlertExitRequested, alerts[2].Type)
+		assert.Equal(t, uint64(200), alerts[2].DeadlineBlock)
+	}
+
+	// The closed exit challenge window is logged instead of being responded to.
+	assert.Len(t, responder.alerts, 3)
+	assert.Len(t, logged, 1)
+
+	bc.head = 120
+	assert.NoError(t, w.Scan())
+	assert.Len(t, alerts, 5)
+	assert.Equal(t, "stake decreased from 50 to 10", alerts[4].Details)
+}
+
+func TestWatchtowerUnparsableLog(t *testing.T) {
+	first := Target{Identity: common.HexToAddress("0x1"), Registry: common.HexToAddress("0x2")}
+	second := Target{Identity: common.HexToAddress("0x3"), Registry: common.HexToAddress("0x2")}
+	bc := &bcMock{
+		head: 10,
+		logs: []types.Log{
+			{Address: first.Registry, BlockNumber: 1, Topics: []common.Hash{TopicBeneficiaryChanged, common.BytesToHash(first.Identity.Bytes())}, Data: []byte{1, 2, 3}},
+			{Address: first.Registry, BlockNumber: 2, Topics: []common.Hash{TopicBeneficiaryChanged, common.BytesToHash(second.Identity.Bytes())}, Data: word(common.HexToAddress("0x99").Bytes())},
+		},
+	}
+
+	w, err := New(bc, Config{ChainID: 137}, first, second)
+	assert.NoError(t, err)
+	var alerts []Alert
+	w.OnAlert(func(a Alert) { alerts = append(alerts, a) })
+	var logged []error
+	w.AttachLogger(func(err error) { logged = append(logged, err) })
+
+	// Concurrent scans do not process the same blocks twice.
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, w.Scan())
+		}()
+	}
+	wg.Wait()
+
+	// The unparsable log is logged for every target it is matched against.
+	assert.Len(t, logged, 2)
+	if assert.Len(t, alerts, 1) {
+		assert.Equal(t, second.Identity, alerts[0].Target.Identity)
+	}
+	assert.Equal(t, uint64(11), w.Cursor())
+}